@@ -1,4 +1,4 @@
-// Package export exports TSM files into InfluxDB line protocol format.
+// Package export exports TSM files into InfluxDB line protocol or Parquet format.
 package export
 
 import (
@@ -7,6 +7,7 @@ import (
 	"flag"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"math"
 	"os"
 	"path/filepath"
@@ -36,6 +37,7 @@ type Command struct {
 	startTime       int64
 	endTime         int64
 	compress        bool
+	format          string
 
 	manifest map[string]struct{}
 	tsmFiles map[string][]string
@@ -60,16 +62,17 @@ func (cmd *Command) Run(args ...string) error {
 	fs := flag.NewFlagSet("export", flag.ExitOnError)
 	fs.StringVar(&cmd.dataDir, "datadir", os.Getenv("HOME")+"/.influxdb/data", "Data storage path")
 	fs.StringVar(&cmd.walDir, "waldir", os.Getenv("HOME")+"/.influxdb/wal", "WAL storage path")
-	fs.StringVar(&cmd.out, "out", os.Getenv("HOME")+"/.influxdb/export", "Destination file to export to")
+	fs.StringVar(&cmd.out, "out", os.Getenv("HOME")+"/.influxdb/export", "Destination file to export to, or directory when the format is parquet")
 	fs.StringVar(&cmd.database, "database", "", "Optional: the database to export")
 	fs.StringVar(&cmd.retentionPolicy, "retention", "", "Optional: the retention policy to export (requires -database)")
 	fs.StringVar(&start, "start", "", "Optional: the start time to export (RFC3339 format)")
 	fs.StringVar(&end, "end", "", "Optional: the end time to export (RFC3339 format)")
 	fs.BoolVar(&cmd.compress, "compress", false, "Compress the output")
+	fs.StringVar(&cmd.format, "format", "line", "Output format: line or parquet (one file per shard and measurement)")
 
 	fs.SetOutput(cmd.Stdout)
 	fs.Usage = func() {
-		fmt.Fprintf(cmd.Stdout, "Exports TSM files into InfluxDB line protocol format.\n\n")
+		fmt.Fprintf(cmd.Stdout, "Exports TSM files into InfluxDB line protocol or Parquet format.\n\n")
 		fmt.Fprintf(cmd.Stdout, "Usage: %s export [flags]\n\n", filepath.Base(os.Args[0]))
 		fs.PrintDefaults()
 	}
@@ -113,6 +116,9 @@ func (cmd *Command) validate() error {
 	if cmd.startTime != 0 && cmd.endTime != 0 && cmd.endTime < cmd.startTime {
 		return fmt.Errorf("end time before start time")
 	}
+	if cmd.format != "line" && cmd.format != "parquet" {
+		return fmt.Errorf("invalid format: %q", cmd.format)
+	}
 	return nil
 }
 
@@ -123,9 +129,49 @@ func (cmd *Command) export() error {
 	if err := cmd.walkWALFiles(); err != nil {
 		return err
 	}
+	if cmd.format == "parquet" {
+		return cmd.writeParquet()
+	}
 	return cmd.write()
 }
 
+// WriteLines writes the points of a database and retention policy between
+// start and end, read from the TSM and WAL files under dataDir and walDir, to
+// w in line protocol. The output contains comment lines starting with '#'.
+func WriteLines(w io.Writer, dataDir, walDir, database, retentionPolicy string, start, end int64) error {
+	cmd := NewCommand()
+	cmd.Stdout = ioutil.Discard
+	cmd.dataDir, cmd.walDir = dataDir, walDir
+	cmd.database, cmd.retentionPolicy = database, retentionPolicy
+	cmd.startTime, cmd.endTime = start, end
+
+	if err := cmd.walkTSMFiles(); err != nil {
+		return err
+	}
+	if err := cmd.walkWALFiles(); err != nil {
+		return err
+	}
+
+	keys := make([]string, 0, len(cmd.manifest))
+	for key := range cmd.manifest {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if files, ok := cmd.tsmFiles[key]; ok {
+			if err := cmd.writeTsmFiles(w, files); err != nil {
+				return err
+			}
+		}
+		if files, ok := cmd.walFiles[key]; ok {
+			if err := cmd.writeWALFiles(w, files, key); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
 func (cmd *Command) walkTSMFiles() error {
 	return filepath.Walk(cmd.dataDir, func(path string, f os.FileInfo, err error) error {
 		if err != nil {
@@ -7,12 +7,16 @@ import (
 	"math"
 	"math/rand"
 	"os"
+	"path/filepath"
+	"reflect"
 	"sort"
 	"strconv"
 	"strings"
 	"testing"
 
 	"github.com/golang/snappy"
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/pkg/parquet"
 	"github.com/influxdata/influxdb/tsdb/engine/tsm1"
 )
 
@@ -356,3 +360,60 @@ func writeCorpusToTSMFile(c corpus) *os.File {
 
 	return tsmFile
 }
+
+func Test_exportShardParquet(t *testing.T) {
+	tsmFile := writeCorpusToTSMFile(basicCorpus)
+	defer os.Remove(tsmFile.Name())
+	walFile := writeCorpusToWALFile(corpus{
+		tsm1.SeriesFieldKey("floats,k=g", "f"): []tsm1.Value{tsm1.NewValue(3, float64(4.5))},
+		tsm1.SeriesFieldKey(`wal\ only`, "v"):  []tsm1.Value{tsm1.NewValue(5, int64(1))},
+	})
+	defer os.Remove(walFile.Name())
+
+	dir, err := ioutil.TempDir("", "export_test_parquet")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	if err := newCommand().exportShardParquet(dir, []string{tsmFile.Name()}, []string{walFile.Name()}, "db/rp/1"); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{"floats", "ints", "bools", "strings", "uints", "wal%20only"} {
+		b, err := ioutil.ReadFile(filepath.Join(dir, name+".parquet"))
+		if err != nil {
+			t.Fatal(err)
+		} else if !bytes.HasPrefix(b, []byte("PAR1")) || !bytes.HasSuffix(b, []byte("PAR1")) {
+			t.Fatalf("%s is not a parquet file", name)
+		}
+	}
+}
+
+func Test_seriesRows(t *testing.T) {
+	m := &parquetMeasurement{
+		tags:   map[string]struct{}{"host": {}, "region": {}},
+		fields: map[string]parquet.Type{"host": parquet.String, "value": parquet.Double, "count": parquet.Int64},
+	}
+	m.init()
+
+	var names []string
+	for _, c := range m.columns {
+		names = append(names, c.Name)
+	}
+	if exp := []string{"time", "host", "region", "count", "host_1", "value"}; !reflect.DeepEqual(names, exp) {
+		t.Fatalf("unexpected columns: %v", names)
+	}
+
+	rows := seriesRows(m, models.NewTags(map[string]string{"host": "a"}), map[string][]tsm1.Value{
+		"value": {tsm1.NewValue(20, 2.5), tsm1.NewValue(10, 1.5), tsm1.NewValue(40, 4.5)},
+		"count": {tsm1.NewValue(10, int64(1)), tsm1.NewValue(30, "mismatched")},
+		"host":  {tsm1.NewValue(20, "b")},
+	}, 0, 30)
+	if exp := [][]interface{}{
+		{int64(10), "a", nil, int64(1), nil, 1.5},
+		{int64(20), "a", nil, nil, "b", 2.5},
+	}; !reflect.DeepEqual(rows, exp) {
+		t.Fatalf("unexpected rows: %v", rows)
+	}
+}
@@ -0,0 +1,411 @@
+package export
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/pkg/escape"
+	"github.com/influxdata/influxdb/pkg/parquet"
+	"github.com/influxdata/influxdb/tsdb/engine/tsm1"
+)
+
+// writeParquet exports every shard as a directory of Parquet files, one per
+// measurement, at <out>/<database>/<retention policy>/<shard id>.
+func (cmd *Command) writeParquet() error {
+	tsmFiles, walFiles := make(map[string][]string), make(map[string][]string)
+	for key, files := range cmd.tsmFiles {
+		for _, f := range files {
+			shard := filepath.Join(key, filepath.Base(filepath.Dir(f)))
+			tsmFiles[shard] = append(tsmFiles[shard], f)
+		}
+	}
+	for key, files := range cmd.walFiles {
+		for _, f := range files {
+			shard := filepath.Join(key, filepath.Base(filepath.Dir(f)))
+			walFiles[shard] = append(walFiles[shard], f)
+		}
+	}
+
+	shards := make([]string, 0, len(tsmFiles)+len(walFiles))
+	for shard := range tsmFiles {
+		shards = append(shards, shard)
+	}
+	for shard := range walFiles {
+		if _, ok := tsmFiles[shard]; !ok {
+			shards = append(shards, shard)
+		}
+	}
+	sort.Strings(shards)
+
+	for _, shard := range shards {
+		fmt.Fprintf(cmd.Stdout, "writing out parquet files for %s...", shard)
+		if err := cmd.exportShardParquet(filepath.Join(cmd.out, shard), tsmFiles[shard], walFiles[shard], shard); err != nil {
+			return err
+		}
+		fmt.Fprintln(cmd.Stdout, "complete.")
+	}
+	return nil
+}
+
+// exportShardParquet writes the data of a shard's TSM and WAL files to dir.
+//
+// A Parquet schema cannot change once rows have been written, so the schema
+// of every measurement is read from the TSM indexes and the WAL before any
+// row is written. Rows are written per TSM file and then for the WAL, so, as
+// in line protocol exports, a point overwritten in a later file appears once
+// for each file that holds it.
+func (cmd *Command) exportShardParquet(dir string, tsmFiles, walFiles []string, shard string) error {
+	e := &parquetExporter{
+		cmd:          cmd,
+		dir:          dir,
+		measurements: make(map[string]*parquetMeasurement),
+	}
+	defer e.close()
+
+	// WAL entries can only be read in full, so they are held in memory.
+	wal := make(map[string][]tsm1.Value)
+	sort.Strings(walFiles)
+	for _, f := range walFiles {
+		if err := cmd.readWALFile(f, wal, shard); err != nil {
+			return err
+		}
+	}
+
+	sort.Strings(tsmFiles)
+	var readers []*tsm1.TSMReader
+	defer func() {
+		for _, r := range readers {
+			r.Close()
+		}
+	}()
+	for _, path := range tsmFiles {
+		f, err := os.Open(path)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return err
+		}
+		r, err := tsm1.NewTSMReader(f)
+		if err != nil {
+			fmt.Fprintf(cmd.Stderr, "unable to read %s, skipping: %s\n", path, err.Error())
+			f.Close()
+			continue
+		}
+		if start, end := r.TimeRange(); start > cmd.endTime || end < cmd.startTime {
+			r.Close()
+			continue
+		}
+		readers = append(readers, r)
+	}
+
+	// Collect the schema of each measurement.
+	for _, r := range readers {
+		for i := 0; i < r.KeyCount(); i++ {
+			key, typ := r.KeyAt(i)
+			e.addField(key, blockTypes[typ])
+		}
+	}
+	walKeys := make([]string, 0, len(wal))
+	for key, values := range wal {
+		walKeys = append(walKeys, key)
+		e.addField([]byte(key), valueType(values[0].Value()))
+	}
+	sort.Strings(walKeys)
+
+	// Write the series of each TSM file and then of the WAL. Keys are sorted
+	// so that the fields of a series are adjacent.
+	for _, r := range readers {
+		if err := e.writeKeys(r.KeyCount(), func(i int) []byte {
+			key, _ := r.KeyAt(i)
+			return key
+		}, r.ReadAll); err != nil {
+			return err
+		}
+	}
+	if err := e.writeKeys(len(walKeys), func(i int) []byte {
+		return []byte(walKeys[i])
+	}, func(key []byte) ([]tsm1.Value, error) {
+		return wal[string(key)], nil
+	}); err != nil {
+		return err
+	}
+
+	return e.close()
+}
+
+// readWALFile adds the values of every write in a WAL file to values.
+func (cmd *Command) readWALFile(path string, values map[string][]tsm1.Value, shard string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	defer f.Close()
+
+	r := tsm1.NewWALSegmentReader(f)
+	defer r.Close()
+
+	var warned bool
+	for r.Next() {
+		entry, err := r.Read()
+		if err != nil {
+			fmt.Fprintf(cmd.Stderr, "file %s corrupt at position %d", path, r.Count())
+			break
+		}
+
+		switch t := entry.(type) {
+		case *tsm1.DeleteWALEntry, *tsm1.DeleteRangeWALEntry:
+			if !warned {
+				fmt.Fprintf(cmd.Stderr, "WARNING: detected deletes in wal file %s. Deleted series of %q may be exported.\n", path, shard)
+				warned = true
+			}
+		case *tsm1.WriteWALEntry:
+			for key, v := range t.Values {
+				values[key] = append(values[key], v...)
+			}
+		}
+	}
+	return nil
+}
+
+// blockTypes maps the block types of TSM files to column types.
+var blockTypes = map[byte]parquet.Type{
+	tsm1.BlockFloat64:  parquet.Double,
+	tsm1.BlockInteger:  parquet.Int64,
+	tsm1.BlockUnsigned: parquet.Uint64,
+	tsm1.BlockBoolean:  parquet.Boolean,
+	tsm1.BlockString:   parquet.String,
+}
+
+// valueType returns the column type of a field value.
+func valueType(v interface{}) parquet.Type {
+	switch v.(type) {
+	case float64:
+		return parquet.Double
+	case int64:
+		return parquet.Int64
+	case uint64:
+		return parquet.Uint64
+	case bool:
+		return parquet.Boolean
+	}
+	return parquet.String
+}
+
+// parquetExporter writes the series of a shard to a Parquet file per
+// measurement.
+type parquetExporter struct {
+	cmd          *Command
+	dir          string
+	measurements map[string]*parquetMeasurement
+}
+
+// parquetMeasurement is the schema and output file of a measurement. The
+// file is created when the first row is written.
+type parquetMeasurement struct {
+	tags   map[string]struct{}
+	fields map[string]parquet.Type
+
+	columns    []parquet.Column
+	tagIndex   map[string]int
+	fieldIndex map[string]int
+
+	f  *os.File
+	bw *bufio.Writer
+	w  *parquet.Writer
+}
+
+// addField adds the tags and field of a TSM key to its measurement schema.
+func (e *parquetExporter) addField(key []byte, typ parquet.Type) {
+	seriesKey, field := tsm1.SeriesAndFieldFromCompositeKey(key)
+	name, tags := models.ParseKey(seriesKey)
+	name = escape.UnescapeString(name)
+
+	m := e.measurements[name]
+	if m == nil {
+		m = &parquetMeasurement{tags: make(map[string]struct{}), fields: make(map[string]parquet.Type)}
+		e.measurements[name] = m
+	}
+	for _, t := range tags {
+		m.tags[string(t.Key)] = struct{}{}
+	}
+	if _, ok := m.fields[string(field)]; !ok {
+		m.fields[string(field)] = typ
+	}
+}
+
+// writeKeys writes the series of n sorted keys. The values of a key are read
+// with read.
+func (e *parquetExporter) writeKeys(n int, keyAt func(int) []byte, read func([]byte) ([]tsm1.Value, error)) error {
+	for i := 0; i < n; {
+		seriesKey, _ := tsm1.SeriesAndFieldFromCompositeKey(keyAt(i))
+		fields := make(map[string][]tsm1.Value)
+		for ; i < n; i++ {
+			key := keyAt(i)
+			sk, field := tsm1.SeriesAndFieldFromCompositeKey(key)
+			if !bytes.Equal(sk, seriesKey) {
+				break
+			}
+			values, err := read(key)
+			if err != nil {
+				fmt.Fprintf(e.cmd.Stderr, "unable to read key %q, skipping: %s\n", string(key), err.Error())
+				continue
+			}
+			fields[string(field)] = values
+		}
+
+		if err := e.writeSeries(seriesKey, fields); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeSeries writes a row for each timestamp of a series.
+func (e *parquetExporter) writeSeries(seriesKey []byte, fields map[string][]tsm1.Value) error {
+	name, tags := models.ParseKey(seriesKey)
+	m := e.measurements[escape.UnescapeString(name)]
+	m.init()
+
+	rows := seriesRows(m, tags, fields, e.cmd.startTime, e.cmd.endTime)
+	if len(rows) == 0 {
+		return nil
+	}
+	if m.w == nil {
+		if err := m.create(e.dir, escape.UnescapeString(name), e.cmd.compress); err != nil {
+			return err
+		}
+	}
+	for _, row := range rows {
+		if err := m.w.Write(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// seriesRows merges the field values of a series into rows ordered by time.
+// Values that do not match the type of their column are dropped.
+func seriesRows(m *parquetMeasurement, tags models.Tags, fields map[string][]tsm1.Value, start, end int64) [][]interface{} {
+	byTime := make(map[int64][]interface{})
+	var times []int64
+	for field, values := range fields {
+		idx, ok := m.fieldIndex[field]
+		if !ok {
+			continue
+		}
+		for _, v := range values {
+			ts := v.UnixNano()
+			if ts < start || ts > end || valueType(v.Value()) != m.columns[idx].Type {
+				continue
+			}
+
+			row := byTime[ts]
+			if row == nil {
+				row = make([]interface{}, len(m.columns))
+				row[0] = ts
+				for _, t := range tags {
+					row[m.tagIndex[string(t.Key)]] = string(t.Value)
+				}
+				byTime[ts] = row
+				times = append(times, ts)
+			}
+			row[idx] = v.Value()
+		}
+	}
+
+	sort.Slice(times, func(i, j int) bool { return times[i] < times[j] })
+	rows := make([][]interface{}, len(times))
+	for i, ts := range times {
+		rows[i] = byTime[ts]
+	}
+	return rows
+}
+
+// init sets the columns of the measurement: the time, the tags and the
+// fields, each in sorted order. A field named like a tag or time is given a
+// suffix, as it is in query results.
+func (m *parquetMeasurement) init() {
+	if m.columns != nil {
+		return
+	}
+
+	tagKeys := make([]string, 0, len(m.tags))
+	for k := range m.tags {
+		tagKeys = append(tagKeys, k)
+	}
+	sort.Strings(tagKeys)
+	fieldKeys := make([]string, 0, len(m.fields))
+	for k := range m.fields {
+		fieldKeys = append(fieldKeys, k)
+	}
+	sort.Strings(fieldKeys)
+
+	m.tagIndex, m.fieldIndex = make(map[string]int), make(map[string]int)
+	names := map[string]struct{}{"time": {}}
+	m.columns = []parquet.Column{{Name: "time", Type: parquet.Timestamp}}
+	for _, k := range tagKeys {
+		names[k] = struct{}{}
+		m.tagIndex[k] = len(m.columns)
+		m.columns = append(m.columns, parquet.Column{Name: k, Type: parquet.String})
+	}
+	for _, k := range fieldKeys {
+		name := k
+		for i := 1; ; i++ {
+			if _, ok := names[name]; !ok {
+				break
+			}
+			name = fmt.Sprintf("%s_%d", k, i)
+		}
+		names[name] = struct{}{}
+		m.fieldIndex[k] = len(m.columns)
+		m.columns = append(m.columns, parquet.Column{Name: name, Type: m.fields[k]})
+	}
+}
+
+// create creates the output file of the measurement.
+func (m *parquetMeasurement) create(dir, name string, compress bool) error {
+	if err := os.MkdirAll(dir, 0777); err != nil {
+		return err
+	}
+	f, err := os.Create(filepath.Join(dir, url.PathEscape(name)+".parquet"))
+	if err != nil {
+		return err
+	}
+	m.f = f
+	m.bw = bufio.NewWriterSize(f, 1024*1024)
+	m.w = parquet.NewWriter(m.bw, m.columns)
+	m.w.Compress = compress
+	return nil
+}
+
+// close completes the output files. It returns the first error and may be
+// called more than once.
+func (e *parquetExporter) close() error {
+	var err error
+	for _, m := range e.measurements {
+		if m.f == nil {
+			continue
+		}
+		if cerr := m.w.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+		if cerr := m.bw.Flush(); cerr != nil && err == nil {
+			err = cerr
+		}
+		if cerr := m.f.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+		m.f = nil
+	}
+	return err
+}
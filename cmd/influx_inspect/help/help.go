@@ -36,6 +36,7 @@ The commands are:
     export               exports raw data from a shard to line protocol
     inmem2tsi            generates a tsi1 index from an in-memory index shard
     help                 display this help message
+    replay               writes the data of a time range to another server
     report               displays a shard level report
     verify               verifies integrity of TSM files
 
@@ -13,6 +13,7 @@ import (
 	"github.com/influxdata/influxdb/cmd/influx_inspect/export"
 	"github.com/influxdata/influxdb/cmd/influx_inspect/help"
 	"github.com/influxdata/influxdb/cmd/influx_inspect/inmem2tsi"
+	"github.com/influxdata/influxdb/cmd/influx_inspect/replay"
 	"github.com/influxdata/influxdb/cmd/influx_inspect/report"
 	"github.com/influxdata/influxdb/cmd/influx_inspect/verify"
 	_ "github.com/influxdata/influxdb/tsdb/engine"
@@ -78,6 +79,11 @@ func (m *Main) Run(args ...string) error {
 		if err := name.Run(args...); err != nil {
 			return fmt.Errorf("inmem2tsi: %s", err)
 		}
+	case "replay":
+		name := replay.NewCommand()
+		if err := name.Run(args...); err != nil {
+			return fmt.Errorf("replay: %s", err)
+		}
 	case "report":
 		name := report.NewCommand()
 		if err := name.Run(args...); err != nil {
@@ -0,0 +1,276 @@
+// Package replay sends the data of a time range in local shards to another
+// server as line protocol writes.
+package replay
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/influxdata/influxdb/cmd/influx_inspect/export"
+)
+
+// Command represents the program execution for "influx_inspect replay".
+type Command struct {
+	// Standard input/output, overridden for testing.
+	Stderr io.Writer
+	Stdout io.Writer
+
+	dataDir         string
+	walDir          string
+	database        string
+	retentionPolicy string
+	startTime       int64
+	endTime         int64
+
+	host                  string
+	username              string
+	password              string
+	targetDatabase        string
+	targetRetentionPolicy string
+	batchSize             int
+	rate                  int
+	retries               int
+
+	client *http.Client
+	now    func() time.Time
+	sleep  func(time.Duration)
+}
+
+// NewCommand returns a new instance of Command.
+func NewCommand() *Command {
+	return &Command{
+		Stderr: os.Stderr,
+		Stdout: os.Stdout,
+
+		client: &http.Client{Timeout: 30 * time.Second},
+		now:    time.Now,
+		sleep:  time.Sleep,
+	}
+}
+
+// Run executes the command.
+func (cmd *Command) Run(args ...string) error {
+	var start, end string
+	fs := flag.NewFlagSet("replay", flag.ExitOnError)
+	fs.StringVar(&cmd.dataDir, "datadir", os.Getenv("HOME")+"/.influxdb/data", "Data storage path")
+	fs.StringVar(&cmd.walDir, "waldir", os.Getenv("HOME")+"/.influxdb/wal", "WAL storage path")
+	fs.StringVar(&cmd.database, "database", "", "The database to replay")
+	fs.StringVar(&cmd.retentionPolicy, "retention", "", "The retention policy to replay")
+	fs.StringVar(&start, "start", "", "Optional: the start time to replay (RFC3339 format)")
+	fs.StringVar(&end, "end", "", "Optional: the end time to replay (RFC3339 format)")
+	fs.StringVar(&cmd.host, "host", "http://localhost:8086", "The URL of the server to write to")
+	fs.StringVar(&cmd.username, "username", "", "Optional: the username to write with")
+	fs.StringVar(&cmd.password, "password", "", "Optional: the password to write with")
+	fs.StringVar(&cmd.targetDatabase, "target-database", "", "Optional: the database to write to, if not the replayed one")
+	fs.StringVar(&cmd.targetRetentionPolicy, "target-retention", "", "Optional: the retention policy to write to, if not the replayed one")
+	fs.IntVar(&cmd.batchSize, "batch-size", 5000, "The number of points in each write")
+	fs.IntVar(&cmd.rate, "rate", 0, "Optional: the maximum number of points written per second")
+	fs.IntVar(&cmd.retries, "retries", 5, "The number of times a failed write is retried")
+
+	fs.SetOutput(cmd.Stdout)
+	fs.Usage = func() {
+		fmt.Fprintf(cmd.Stdout, "Replays the data of a time range in TSM and WAL files to another server.\n\n")
+		fmt.Fprintf(cmd.Stdout, "Usage: %s replay [flags]\n\n", filepath.Base(os.Args[0]))
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	cmd.startTime, cmd.endTime = math.MinInt64, math.MaxInt64
+	if start != "" {
+		s, err := time.Parse(time.RFC3339, start)
+		if err != nil {
+			return err
+		}
+		cmd.startTime = s.UnixNano()
+	}
+	if end != "" {
+		e, err := time.Parse(time.RFC3339, end)
+		if err != nil {
+			return err
+		}
+		cmd.endTime = e.UnixNano()
+	}
+	if cmd.targetDatabase == "" {
+		cmd.targetDatabase = cmd.database
+	}
+	if cmd.targetRetentionPolicy == "" {
+		cmd.targetRetentionPolicy = cmd.retentionPolicy
+	}
+
+	if err := cmd.validate(); err != nil {
+		return err
+	}
+	return cmd.replay()
+}
+
+func (cmd *Command) validate() error {
+	if cmd.database == "" || cmd.retentionPolicy == "" {
+		return errors.New("must specify a database and retention policy")
+	}
+	if cmd.endTime < cmd.startTime {
+		return errors.New("end time before start time")
+	}
+	if cmd.batchSize <= 0 {
+		return errors.New("batch-size must be greater than 0")
+	}
+	if cmd.rate < 0 {
+		return errors.New("rate must not be negative")
+	}
+	if cmd.retries < 0 {
+		return errors.New("retries must not be negative")
+	}
+	if u, err := url.Parse(cmd.host); err != nil {
+		return err
+	} else if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("invalid host: %q", cmd.host)
+	}
+	return nil
+}
+
+func (cmd *Command) replay() error {
+	w := &batchWriter{cmd: cmd, started: cmd.now()}
+	if err := export.WriteLines(w, cmd.dataDir, cmd.walDir, cmd.database, cmd.retentionPolicy, cmd.startTime, cmd.endTime); err != nil {
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.Stdout, "replayed %d points to %s.%s\n", w.written, cmd.targetDatabase, cmd.targetRetentionPolicy)
+	return nil
+}
+
+// batchWriter collects the lines written to it into batches and writes
+// them to the server, pacing the writes to the rate of the command.
+type batchWriter struct {
+	cmd *Command
+
+	partial []byte // the start of a line without its newline
+	batch   bytes.Buffer
+	n       int // points in batch
+
+	started time.Time
+	written int
+}
+
+// Write adds the complete lines of p to the batch. Comment lines are skipped.
+func (w *batchWriter) Write(p []byte) (int, error) {
+	n := len(p)
+	for len(p) > 0 {
+		i := bytes.IndexByte(p, '\n')
+		if i < 0 {
+			w.partial = append(w.partial, p...)
+			break
+		}
+
+		line := p[:i+1]
+		if len(w.partial) > 0 {
+			line = append(w.partial, line...)
+			w.partial = w.partial[:0]
+		}
+		p = p[i+1:]
+
+		if len(bytes.TrimSpace(line)) == 0 || line[0] == '#' {
+			continue
+		}
+		w.batch.Write(line)
+		if w.n++; w.n >= w.cmd.batchSize {
+			if err := w.Flush(); err != nil {
+				return 0, err
+			}
+		}
+	}
+	return n, nil
+}
+
+// Flush writes the batch to the server.
+func (w *batchWriter) Flush() error {
+	if w.n == 0 {
+		return nil
+	}
+
+	// Wait until writing the batch keeps within the rate.
+	if rate := w.cmd.rate; rate > 0 {
+		due := w.started.Add(time.Duration(w.written+w.n) * time.Second / time.Duration(rate))
+		if d := due.Sub(w.cmd.now()); d > 0 {
+			w.cmd.sleep(d)
+		}
+	}
+
+	wait := time.Second
+	for i := 0; ; i++ {
+		err := w.cmd.write(w.batch.Bytes())
+		if err == nil {
+			break
+		} else if _, ok := err.(rejectedError); ok || i >= w.cmd.retries {
+			return err
+		}
+		fmt.Fprintf(w.cmd.Stderr, "write failed, retrying in %s: %s\n", wait, err)
+		w.cmd.sleep(wait)
+		if wait *= 2; wait > time.Minute {
+			wait = time.Minute
+		}
+	}
+
+	w.written += w.n
+	w.batch.Reset()
+	w.n = 0
+	return nil
+}
+
+// rejectedError is returned for a write that the server will never accept.
+type rejectedError string
+
+func (e rejectedError) Error() string { return string(e) }
+
+// write sends a batch of lines to the server.
+func (cmd *Command) write(body []byte) error {
+	u, err := url.Parse(cmd.host)
+	if err != nil {
+		return err
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/write"
+	u.RawQuery = url.Values{
+		"db":        {cmd.targetDatabase},
+		"rp":        {cmd.targetRetentionPolicy},
+		"precision": {"n"},
+	}.Encode()
+
+	req, err := http.NewRequest("POST", u.String(), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	if cmd.username != "" {
+		req.SetBasicAuth(cmd.username, cmd.password)
+	}
+
+	resp, err := cmd.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	msg, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+
+	switch {
+	case resp.StatusCode/100 == 2:
+		return nil
+	case resp.StatusCode/100 == 4:
+		return rejectedError(fmt.Sprintf("%s: %s", resp.Status, bytes.TrimSpace(msg)))
+	default:
+		return fmt.Errorf("%s: %s", resp.Status, bytes.TrimSpace(msg))
+	}
+}
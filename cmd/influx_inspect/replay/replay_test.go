@@ -0,0 +1,115 @@
+package replay
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/tsdb/engine/tsm1"
+)
+
+func TestCommand_Replay(t *testing.T) {
+	dir, err := ioutil.TempDir("", "replay")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// A shard with a TSM file and no WAL.
+	dataDir, walDir := filepath.Join(dir, "data"), filepath.Join(dir, "wal")
+	shardDir := filepath.Join(dataDir, "db0", "autogen", "1")
+	if err := os.MkdirAll(shardDir, 0777); err != nil {
+		t.Fatal(err)
+	} else if err := os.MkdirAll(filepath.Join(walDir, "db0", "autogen", "1"), 0777); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Create(filepath.Join(shardDir, "000000001-000000001.tsm"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, err := tsm1.NewTSMWriter(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	values := []tsm1.Value{
+		tsm1.NewValue(1000000000, 1.0),
+		tsm1.NewValue(2000000000, 2.0),
+		tsm1.NewValue(3000000000, 3.0),
+		tsm1.NewValue(4000000000, 4.0),
+	}
+	if err := w.Write([]byte(tsm1.SeriesFieldKey("cpu,host=a", "value")), values); err != nil {
+		t.Fatal(err)
+	} else if err := w.WriteIndex(); err != nil {
+		t.Fatal(err)
+	} else if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	var writes []string
+	var failed bool
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The first write fails and is retried.
+		if !failed {
+			failed = true
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if q := r.URL.Query(); q.Get("db") != "db1" || q.Get("rp") != "autogen" || q.Get("precision") != "n" {
+			t.Errorf("unexpected request: %s", r.URL)
+		}
+		body, _ := ioutil.ReadAll(r.Body)
+		writes = append(writes, string(body))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer ts.Close()
+
+	// Time passes only while the command sleeps.
+	now := time.Unix(0, 0)
+	var sleeps []time.Duration
+	cmd := NewCommand()
+	cmd.Stdout, cmd.Stderr = ioutil.Discard, ioutil.Discard
+	cmd.now = func() time.Time { return now }
+	cmd.sleep = func(d time.Duration) {
+		sleeps = append(sleeps, d)
+		now = now.Add(d)
+	}
+
+	if err := cmd.Run(
+		"-datadir", dataDir,
+		"-waldir", walDir,
+		"-database", "db0",
+		"-retention", "autogen",
+		"-start", "1970-01-01T00:00:02Z",
+		"-host", ts.URL,
+		"-target-database", "db1",
+		"-batch-size", "2",
+		"-rate", "1",
+	); err != nil {
+		t.Fatal(err)
+	}
+
+	if exp := []string{
+		"cpu,host=a value=2 2000000000\ncpu,host=a value=3 3000000000\n",
+		"cpu,host=a value=4 4000000000\n",
+	}; !reflect.DeepEqual(writes, exp) {
+		t.Fatalf("unexpected writes: %q", writes)
+	}
+	// The retry of the first batch takes the time the second one waits for.
+	if exp := []time.Duration{2 * time.Second, time.Second}; !reflect.DeepEqual(sleeps, exp) {
+		t.Fatalf("unexpected sleeps: %v", sleeps)
+	}
+}
+
+func TestCommand_Validate(t *testing.T) {
+	cmd := NewCommand()
+	cmd.Stdout = ioutil.Discard
+	if err := cmd.Run("-database", "db0"); err == nil || !strings.Contains(err.Error(), "retention policy") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
@@ -17,6 +17,8 @@ var (
 	config     = flag.String("config", "", "The stress test file")
 	cpuprofile = flag.String("cpuprofile", "", "Write the cpu profile to `filename`")
 	db         = flag.String("db", "", "target database within test system for write and query load")
+	dump       = flag.String("dump", "", "Write the generated points to `filename` as line protocol instead of running the test")
+	replay     = flag.String("replay", "", "Write the line protocol points of `filename` instead of generating them")
 )
 
 func main() {
@@ -53,7 +55,27 @@ func main() {
 			c.Read.QueryClients.Basic.Database = *db
 		}
 
-		w := stress.NewWriter(c.Write.PointGenerators.Basic, &c.Write.InfluxClients.Basic)
+		if *dump != "" {
+			f, err := os.Create(*dump)
+			if err != nil {
+				log.Fatal(err)
+			}
+			n, err := stress.Dump(f, c.Write.PointGenerators.Basic)
+			if err != nil {
+				log.Fatal(err)
+			} else if err := f.Close(); err != nil {
+				log.Fatal(err)
+			}
+			fmt.Printf("Wrote %d points to %s\n", n, *dump)
+			return
+		}
+
+		var pg stress.PointGenerator = c.Write.PointGenerators.Basic
+		if *replay != "" {
+			pg = &stress.ReplayPointGenerator{Path: *replay}
+		}
+
+		w := stress.NewWriter(pg, &c.Write.InfluxClients.Basic)
 		r := stress.NewQuerier(&c.Read.QueryGenerators.Basic, &c.Read.QueryClients.Basic)
 		s := stress.NewStressTest(&c.Provision.Basic, w, r)
 
@@ -2,6 +2,7 @@
 package backup
 
 import (
+	"bytes"
 	"encoding/binary"
 	"encoding/json"
 	"errors"
@@ -11,11 +12,13 @@ import (
 	"io/ioutil"
 	"log"
 	"os"
+	"path"
 	"path/filepath"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/influxdata/influxdb/pkg/s3"
 	"github.com/influxdata/influxdb/services/snapshotter"
 	"github.com/influxdata/influxdb/tcp"
 )
@@ -45,6 +48,10 @@ type Command struct {
 	host     string
 	path     string
 	database string
+
+	// s3 is set when backups are uploaded to an object store. The path is
+	// then the prefix of the object keys.
+	s3 *s3.Client
 }
 
 // NewCommand returns a new instance of Command with default settings.
@@ -100,8 +107,9 @@ func (cmd *Command) parseFlags(args []string) (retentionPolicy, shardID string,
 	fs.StringVar(&cmd.database, "database", "", "")
 	fs.StringVar(&retentionPolicy, "retention", "", "")
 	fs.StringVar(&shardID, "shard", "", "")
-	var sinceArg string
+	var sinceArg, s3Config string
 	fs.StringVar(&sinceArg, "since", "", "")
+	fs.StringVar(&s3Config, "s3-config", "", "")
 
 	fs.SetOutput(cmd.Stderr)
 	fs.Usage = cmd.printUsage
@@ -125,11 +133,30 @@ func (cmd *Command) parseFlags(args []string) (retentionPolicy, shardID string,
 	}
 	cmd.path = fs.Arg(0)
 
+	if s3Config != "" {
+		c, err := s3.LoadConfig(s3Config)
+		if err != nil {
+			return "", "", time.Unix(0, 0), fmt.Errorf("load s3 config: %s", err)
+		}
+		cmd.s3 = s3.NewClient(c)
+		cmd.path = strings.Trim(cmd.path, "/")
+		return retentionPolicy, shardID, since, nil
+	}
+
 	err = os.MkdirAll(cmd.path, 0700)
 
 	return
 }
 
+// backupPath returns the path of a backup file, or its key when backups are
+// uploaded to an object store.
+func (cmd *Command) backupPath(name string) string {
+	if cmd.s3 != nil {
+		return path.Join(cmd.path, name)
+	}
+	return filepath.Join(cmd.path, name)
+}
+
 // backupShard will write a tar archive of the passed in shard with any TSM files that have been
 // created since the time passed in
 func (cmd *Command) backupShard(retentionPolicy string, shardID string, since time.Time) error {
@@ -138,7 +165,7 @@ func (cmd *Command) backupShard(retentionPolicy string, shardID string, since ti
 		return err
 	}
 
-	shardArchivePath, err := cmd.nextPath(filepath.Join(cmd.path, fmt.Sprintf(BackupFilePattern, cmd.database, retentionPolicy, id)))
+	shardArchivePath, err := cmd.nextPath(cmd.backupPath(fmt.Sprintf(BackupFilePattern, cmd.database, retentionPolicy, id)))
 	if err != nil {
 		return err
 	}
@@ -155,6 +182,9 @@ func (cmd *Command) backupShard(retentionPolicy string, shardID string, since ti
 	}
 
 	// TODO: verify shard backup data
+	if cmd.s3 != nil {
+		return cmd.uploadAndVerify(req, shardArchivePath, nil)
+	}
 	return cmd.downloadAndVerify(req, shardArchivePath, nil)
 }
 
@@ -219,7 +249,7 @@ func (cmd *Command) backupResponsePaths(response *snapshotter.Response, since ti
 // backupMetastore will backup the metastore on the host to the passed in path. Database and retention policy backups
 // will force a backup of the metastore as well as requesting a specific shard backup from the command line
 func (cmd *Command) backupMetastore() error {
-	metastoreArchivePath, err := cmd.nextPath(filepath.Join(cmd.path, Metafile))
+	metastoreArchivePath, err := cmd.nextPath(cmd.backupPath(Metafile))
 	if err != nil {
 		return err
 	}
@@ -230,12 +260,7 @@ func (cmd *Command) backupMetastore() error {
 		Type: snapshotter.RequestMetastoreBackup,
 	}
 
-	return cmd.downloadAndVerify(req, metastoreArchivePath, func(file string) error {
-		binData, err := ioutil.ReadFile(file)
-		if err != nil {
-			return err
-		}
-
+	validate := func(binData []byte) error {
 		magic := binary.BigEndian.Uint64(binData[:8])
 		if magic != snapshotter.BackupMagicHeader {
 			cmd.StderrLogger.Println("Invalid metadata blob, ensure the metadata service is running (default port 8088)")
@@ -243,11 +268,25 @@ func (cmd *Command) backupMetastore() error {
 		}
 
 		return nil
+	}
+	if cmd.s3 != nil {
+		return cmd.uploadAndVerify(req, metastoreArchivePath, validate)
+	}
+	return cmd.downloadAndVerify(req, metastoreArchivePath, func(file string) error {
+		binData, err := ioutil.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		return validate(binData)
 	})
 }
 
 // nextPath returns the next file to write to.
 func (cmd *Command) nextPath(path string) (string, error) {
+	if cmd.s3 != nil {
+		return cmd.nextKey(path)
+	}
+
 	// Iterate through incremental files until one is available.
 	for i := 0; ; i++ {
 		s := fmt.Sprintf(path+".%02d", i)
@@ -303,6 +342,19 @@ func (cmd *Command) download(req *snapshotter.Request, path string) error {
 	}
 	defer f.Close()
 
+	return cmd.fetch(req, func(r io.Reader) error {
+		// Read snapshot from the connection
+		if n, err := io.Copy(f, r); err != nil || n == 0 {
+			return fmt.Errorf("copy backup to file: err=%v, n=%d", err, n)
+		}
+		return nil
+	})
+}
+
+// fetch requests a snapshot from the host and passes the connection it is
+// read from to fn. Failed attempts are retried.
+func (cmd *Command) fetch(req *snapshotter.Request, fn func(r io.Reader) error) error {
+	var err error
 	for i := 0; i < 10; i++ {
 		if err = func() error {
 			// Connect to snapshotter service.
@@ -317,11 +369,7 @@ func (cmd *Command) download(req *snapshotter.Request, path string) error {
 				return fmt.Errorf("encode snapshot request: %s", err)
 			}
 
-			// Read snapshot from the connection
-			if n, err := io.Copy(f, conn); err != nil || n == 0 {
-				return fmt.Errorf("copy backup to file: err=%v, n=%d", err, n)
-			}
-			return nil
+			return fn(conn)
 		}(); err == nil {
 			break
 		} else if err != nil {
@@ -333,6 +381,60 @@ func (cmd *Command) download(req *snapshotter.Request, path string) error {
 	return err
 }
 
+// nextKey returns the next object key to upload a backup to.
+func (cmd *Command) nextKey(key string) (string, error) {
+	objects, err := cmd.s3.List(key + ".")
+	if err != nil {
+		return "", err
+	}
+	exists := make(map[string]struct{}, len(objects))
+	for _, o := range objects {
+		exists[o.Key] = struct{}{}
+	}
+
+	for i := 0; ; i++ {
+		s := fmt.Sprintf(key+".%02d", i)
+		if _, ok := exists[s]; !ok {
+			return s, nil
+		}
+	}
+}
+
+// uploadAndVerify streams a snapshot of either the metastore or a shard to
+// an object in the object store. The object only exists once the upload is
+// complete, so no temporary object is needed. An interrupted upload is
+// resumed by the next attempt, which only sends the parts that were not
+// uploaded yet if the snapshot is unchanged.
+func (cmd *Command) uploadAndVerify(req *snapshotter.Request, key string, validator func([]byte) error) error {
+	if validator != nil {
+		// Snapshots that are validated are small enough to hold in memory
+		// so they are never uploaded when they are invalid.
+		var buf bytes.Buffer
+		if err := cmd.fetch(req, func(r io.Reader) error {
+			buf.Reset()
+			if n, err := io.Copy(&buf, r); err != nil || n == 0 {
+				return fmt.Errorf("copy backup to buffer: err=%v, n=%d", err, n)
+			}
+			return nil
+		}); err != nil {
+			return err
+		}
+
+		if err := validator(buf.Bytes()); err != nil {
+			return err
+		}
+		_, err := cmd.s3.Upload(key, &buf)
+		return err
+	}
+
+	return cmd.fetch(req, func(r io.Reader) error {
+		if n, err := cmd.s3.Upload(key, r); err != nil || n == 0 {
+			return fmt.Errorf("upload backup: err=%v, n=%d", err, n)
+		}
+		return nil
+	})
+}
+
 // requestInfo will request the database or retention policy information from the host
 func (cmd *Command) requestInfo(request *snapshotter.Request) (*snapshotter.Response, error) {
 	// Connect to snapshotter service.
@@ -373,6 +475,22 @@ Usage: influxd backup [flags] PATH
     -since <2015-12-24T08:12:23Z>
             Optional. Do an incremental backup since the passed in RFC3339
             formatted time.
+    -s3-config <path>
+            Optional. Upload the backup to the S3-compatible object store
+            configured in the given TOML file instead of writing it to disk.
+            PATH is then the prefix of the object keys in the bucket.
+
+The S3 config file sets the object store and bucket to use:
+
+    endpoint = "https://s3.us-west-2.amazonaws.com"
+    region = "us-west-2"
+    bucket = "influxdb-backups"
+    access-key-id = "..."       # Defaults to $AWS_ACCESS_KEY_ID.
+    secret-access-key = "..."   # Defaults to $AWS_SECRET_ACCESS_KEY.
+    part-size = "16m"
+
+Backups are streamed to the bucket with multipart uploads. An interrupted
+upload is resumed by the next backup of the same shard.
 
 `)
 }
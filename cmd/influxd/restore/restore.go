@@ -11,10 +11,13 @@ import (
 	"io"
 	"io/ioutil"
 	"os"
+	"path"
 	"path/filepath"
 	"strconv"
+	"strings"
 
 	"github.com/influxdata/influxdb/cmd/influxd/backup"
+	"github.com/influxdata/influxdb/pkg/s3"
 	"github.com/influxdata/influxdb/services/meta"
 	"github.com/influxdata/influxdb/services/snapshotter"
 )
@@ -31,6 +34,10 @@ type Command struct {
 	retention       string
 	shard           string
 
+	// s3 is set when backups are downloaded from an object store. The
+	// backup files path is then the prefix of the object keys.
+	s3 *s3.Client
+
 	// TODO: when the new meta stuff is done this should not be exported or be gone
 	MetaConfig *meta.Config
 }
@@ -74,6 +81,8 @@ func (cmd *Command) parseFlags(args []string) error {
 	fs.StringVar(&cmd.database, "database", "", "")
 	fs.StringVar(&cmd.retention, "retention", "", "")
 	fs.StringVar(&cmd.shard, "shard", "", "")
+	var s3Config string
+	fs.StringVar(&s3Config, "s3-config", "", "")
 	fs.SetOutput(cmd.Stdout)
 	fs.Usage = cmd.printUsage
 	if err := fs.Parse(args); err != nil {
@@ -89,6 +98,15 @@ func (cmd *Command) parseFlags(args []string) error {
 		return fmt.Errorf("path with backup files required")
 	}
 
+	if s3Config != "" {
+		c, err := s3.LoadConfig(s3Config)
+		if err != nil {
+			return fmt.Errorf("load s3 config: %s", err)
+		}
+		cmd.s3 = s3.NewClient(c)
+		cmd.backupFilesPath = strings.Trim(cmd.backupFilesPath, "/")
+	}
+
 	// validate the arguments
 	if cmd.metadir == "" && cmd.database == "" {
 		return fmt.Errorf("-metadir or -database are required to restore")
@@ -116,7 +134,7 @@ func (cmd *Command) parseFlags(args []string) error {
 // cluster and replaces the root metadata.
 func (cmd *Command) unpackMeta() error {
 	// find the meta file
-	metaFiles, err := filepath.Glob(filepath.Join(cmd.backupFilesPath, backup.Metafile+".*"))
+	metaFiles, err := cmd.glob(cmd.backupPath(backup.Metafile + ".*"))
 	if err != nil {
 		return err
 	}
@@ -129,10 +147,11 @@ func (cmd *Command) unpackMeta() error {
 
 	fmt.Fprintf(cmd.Stdout, "Using metastore snapshot: %v\n", latest)
 	// Read the metastore backup
-	f, err := os.Open(latest)
+	f, err := cmd.open(latest)
 	if err != nil {
 		return err
 	}
+	defer f.Close()
 
 	var buf bytes.Buffer
 	if _, err := io.Copy(&buf, f); err != nil {
@@ -227,7 +246,7 @@ func (cmd *Command) unpackShard(shardID string) error {
 	}
 
 	// find the shard backup files
-	pat := filepath.Join(cmd.backupFilesPath, fmt.Sprintf(backup.BackupFilePattern, cmd.database, cmd.retention, id))
+	pat := cmd.backupPath(fmt.Sprintf(backup.BackupFilePattern, cmd.database, cmd.retention, id))
 	return cmd.unpackFiles(pat + ".*")
 }
 
@@ -241,7 +260,7 @@ func (cmd *Command) unpackDatabase() error {
 	}
 
 	// find the database backup files
-	pat := filepath.Join(cmd.backupFilesPath, cmd.database)
+	pat := cmd.backupPath(cmd.database)
 	return cmd.unpackFiles(pat + ".*")
 }
 
@@ -255,7 +274,7 @@ func (cmd *Command) unpackRetention() error {
 	}
 
 	// find the retention backup files
-	pat := filepath.Join(cmd.backupFilesPath, cmd.database)
+	pat := cmd.backupPath(cmd.database)
 	return cmd.unpackFiles(fmt.Sprintf("%s.%s.*", pat, cmd.retention))
 }
 
@@ -263,7 +282,7 @@ func (cmd *Command) unpackRetention() error {
 func (cmd *Command) unpackFiles(pat string) error {
 	fmt.Printf("Restoring from backup %s\n", pat)
 
-	backupFiles, err := filepath.Glob(pat)
+	backupFiles, err := cmd.glob(pat)
 	if err != nil {
 		return err
 	}
@@ -281,9 +300,35 @@ func (cmd *Command) unpackFiles(pat string) error {
 	return nil
 }
 
+// backupPath returns the path of a backup file, or its key when backups are
+// downloaded from an object store.
+func (cmd *Command) backupPath(name string) string {
+	if cmd.s3 != nil {
+		return path.Join(cmd.backupFilesPath, name)
+	}
+	return filepath.Join(cmd.backupFilesPath, name)
+}
+
+// glob returns the backup files that match pat in sorted order.
+func (cmd *Command) glob(pat string) ([]string, error) {
+	if cmd.s3 != nil {
+		return cmd.s3.Glob(pat)
+	}
+	return filepath.Glob(pat)
+}
+
+// open opens a backup file. Objects are streamed from the object store
+// without being staged on disk.
+func (cmd *Command) open(name string) (io.ReadCloser, error) {
+	if cmd.s3 != nil {
+		return cmd.s3.Get(name)
+	}
+	return os.Open(name)
+}
+
 // unpackTar will restore a single tar archive to the data dir
 func (cmd *Command) unpackTar(tarFile string) error {
-	f, err := os.Open(tarFile)
+	f, err := cmd.open(tarFile)
 	if err != nil {
 		return err
 	}
@@ -350,6 +395,10 @@ Usage: influxd restore [flags] PATH
     -shard <id>
             Optional. If given, database and retention are required. Will restore the shard's
             TSM files.
+    -s3-config <path>
+            Optional. Download the backup from the S3-compatible object store
+            configured in the given TOML file. PATH is then the prefix of the
+            object keys in the bucket.
 
 `)
 }
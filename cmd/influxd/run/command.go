@@ -13,6 +13,7 @@ import (
 	"strconv"
 	"time"
 
+	"github.com/influxdata/influxdb/pkg/systemd"
 	"github.com/uber-go/zap"
 )
 
@@ -120,11 +121,23 @@ func (cmd *Command) Run(args ...string) error {
 	s.Logger = cmd.Logger
 	s.CPUProfile = options.CPUProfile
 	s.MemProfile = options.MemProfile
+
+	// Use the sockets passed by systemd socket activation, if any.
+	if s.SocketListeners, err = systemd.Listeners(); err != nil {
+		return fmt.Errorf("systemd listeners: %s", err)
+	}
+
 	if err := s.Open(); err != nil {
 		return fmt.Errorf("open server: %s", err)
 	}
 	cmd.Server = s
 
+	// Tell systemd the server is ready only now that the shards are loaded
+	// and the services accept connections.
+	if _, err := systemd.Notify("READY=1"); err != nil {
+		cmd.Logger.Info(fmt.Sprintf("systemd notify: %s", err))
+	}
+
 	// Begin monitoring the server's error channel.
 	go cmd.monitorServerErrors()
 
@@ -136,6 +149,7 @@ func (cmd *Command) Close() error {
 	defer close(cmd.Closed)
 	defer cmd.removePIDFile()
 	close(cmd.closing)
+	systemd.Notify("STOPPING=1")
 	if cmd.Server != nil {
 		return cmd.Server.Close()
 	}
@@ -17,16 +17,19 @@ import (
 	"github.com/influxdata/influxdb/coordinator"
 	"github.com/influxdata/influxdb/monitor"
 	"github.com/influxdata/influxdb/monitor/diagnostics"
+	"github.com/influxdata/influxdb/services/cdc"
 	"github.com/influxdata/influxdb/services/collectd"
 	"github.com/influxdata/influxdb/services/continuous_querier"
 	"github.com/influxdata/influxdb/services/graphite"
 	"github.com/influxdata/influxdb/services/httpd"
 	"github.com/influxdata/influxdb/services/meta"
+	"github.com/influxdata/influxdb/services/mirror"
 	"github.com/influxdata/influxdb/services/opentsdb"
 	"github.com/influxdata/influxdb/services/precreator"
 	"github.com/influxdata/influxdb/services/retention"
 	"github.com/influxdata/influxdb/services/storage"
 	"github.com/influxdata/influxdb/services/subscriber"
+	"github.com/influxdata/influxdb/services/tiering"
 	"github.com/influxdata/influxdb/services/udp"
 	"github.com/influxdata/influxdb/tsdb"
 	"golang.org/x/text/encoding/unicode"
@@ -45,9 +48,12 @@ type Config struct {
 	Coordinator coordinator.Config `toml:"coordinator"`
 	Retention   retention.Config   `toml:"retention"`
 	Precreator  precreator.Config  `toml:"shard-precreation"`
+	Tiering     tiering.Config     `toml:"tiering"`
 
 	Monitor        monitor.Config    `toml:"monitor"`
 	Subscriber     subscriber.Config `toml:"subscriber"`
+	CDC            cdc.Config        `toml:"cdc"`
+	Mirror         mirror.Config     `toml:"mirror"`
 	HTTPD          httpd.Config      `toml:"http"`
 	Storage        storage.Config    `toml:"storage"`
 	GraphiteInputs []graphite.Config `toml:"graphite"`
@@ -71,9 +77,12 @@ func NewConfig() *Config {
 	c.Data = tsdb.NewConfig()
 	c.Coordinator = coordinator.NewConfig()
 	c.Precreator = precreator.NewConfig()
+	c.Tiering = tiering.NewConfig()
 
 	c.Monitor = monitor.NewConfig()
 	c.Subscriber = subscriber.NewConfig()
+	c.CDC = cdc.NewConfig()
+	c.Mirror = mirror.NewConfig()
 	c.HTTPD = httpd.NewConfig()
 	c.Storage = storage.NewConfig()
 
@@ -107,6 +116,8 @@ func NewDemoConfig() (*Config, error) {
 	c.Meta.Dir = filepath.Join(homeDir, ".influxdb/meta")
 	c.Data.Dir = filepath.Join(homeDir, ".influxdb/data")
 	c.Data.WALDir = filepath.Join(homeDir, ".influxdb/wal")
+	c.Data.TrashDir = filepath.Join(homeDir, ".influxdb/trash")
+	c.Mirror.Dir = filepath.Join(homeDir, ".influxdb/mirror")
 
 	return c, nil
 }
@@ -171,22 +182,54 @@ func (c *Config) Validate() error {
 		return err
 	}
 
+	if err := c.Tiering.Validate(); err != nil {
+		return err
+	}
+
+	if err := c.Coordinator.Validate(); err != nil {
+		return fmt.Errorf("invalid coordinator config: %v", err)
+	}
+
 	if err := c.Subscriber.Validate(); err != nil {
 		return err
 	}
 
+	if err := c.CDC.Validate(); err != nil {
+		return fmt.Errorf("invalid cdc config: %v", err)
+	}
+
+	if err := c.Mirror.Validate(); err != nil {
+		return fmt.Errorf("invalid mirror config: %v", err)
+	}
+
+	if err := c.HTTPD.Validate(); err != nil {
+		return fmt.Errorf("invalid http config: %v", err)
+	}
+
 	for _, graphite := range c.GraphiteInputs {
 		if err := graphite.Validate(); err != nil {
 			return fmt.Errorf("invalid graphite config: %v", err)
 		}
 	}
 
+	for _, opentsdb := range c.OpenTSDBInputs {
+		if err := opentsdb.Validate(); err != nil {
+			return fmt.Errorf("invalid opentsdb config: %v", err)
+		}
+	}
+
 	for _, collectd := range c.CollectdInputs {
 		if err := collectd.Validate(); err != nil {
 			return fmt.Errorf("invalid collectd config: %v", err)
 		}
 	}
 
+	for _, udp := range c.UDPInputs {
+		if err := udp.Validate(); err != nil {
+			return fmt.Errorf("invalid udp config: %v", err)
+		}
+	}
+
 	return nil
 }
 
@@ -332,9 +375,12 @@ func (c *Config) diagnosticsClients() map[string]diagnostics.Client {
 		"config-coordinator": c.Coordinator,
 		"config-retention":   c.Retention,
 		"config-precreator":  c.Precreator,
+		"config-tiering":     c.Tiering,
 
 		"config-monitor":    c.Monitor,
 		"config-subscriber": c.Subscriber,
+		"config-cdc":        c.CDC,
+		"config-mirror":     c.Mirror,
 		"config-httpd":      c.HTTPD,
 
 		"config-cqs": c.ContinuousQuery,
@@ -15,17 +15,23 @@ import (
 	"github.com/influxdata/influxdb/coordinator"
 	"github.com/influxdata/influxdb/models"
 	"github.com/influxdata/influxdb/monitor"
+	"github.com/influxdata/influxdb/pkg/bindaddr"
+	"github.com/influxdata/influxdb/pkg/limiter"
+	"github.com/influxdata/influxdb/pkg/profiling"
 	"github.com/influxdata/influxdb/query"
+	"github.com/influxdata/influxdb/services/cdc"
 	"github.com/influxdata/influxdb/services/collectd"
 	"github.com/influxdata/influxdb/services/continuous_querier"
 	"github.com/influxdata/influxdb/services/graphite"
 	"github.com/influxdata/influxdb/services/httpd"
 	"github.com/influxdata/influxdb/services/meta"
+	"github.com/influxdata/influxdb/services/mirror"
 	"github.com/influxdata/influxdb/services/opentsdb"
 	"github.com/influxdata/influxdb/services/precreator"
 	"github.com/influxdata/influxdb/services/retention"
 	"github.com/influxdata/influxdb/services/snapshotter"
 	"github.com/influxdata/influxdb/services/subscriber"
+	"github.com/influxdata/influxdb/services/tiering"
 	"github.com/influxdata/influxdb/services/udp"
 	"github.com/influxdata/influxdb/tcp"
 	"github.com/influxdata/influxdb/tsdb"
@@ -64,6 +70,11 @@ type Server struct {
 	BindAddress string
 	Listener    net.Listener
 
+	// SocketListeners are the listeners passed by systemd socket activation,
+	// keyed by socket name. The "rpc" listener replaces the bind address and
+	// the "http" listener replaces the bind address of the HTTP service.
+	SocketListeners map[string]net.Listener
+
 	Logger zap.Logger
 
 	MetaClient *meta.Client
@@ -72,11 +83,15 @@ type Server struct {
 	QueryExecutor *query.QueryExecutor
 	PointsWriter  *coordinator.PointsWriter
 	Subscriber    *subscriber.Service
+	ChangeStream  *cdc.Service
+	Mirror        *mirror.Service
+	ObjectStore   *tiering.Store
 
 	Services []Service
 
 	// These references are required for the tcp muxer.
 	SnapshotterService *snapshotter.Service
+	CoordinatorService *coordinator.Service
 
 	Monitor *monitor.Monitor
 
@@ -171,33 +186,81 @@ func NewServer(c *Config, buildInfo *BuildInfo) (*Server, error) {
 	s.TSDBStore.EngineOptions.EngineVersion = c.Data.Engine
 	s.TSDBStore.EngineOptions.IndexVersion = c.Data.Index
 
+	// Share the background throughput limit between compactions and
+	// continuous queries.
+	if n := int(c.Data.BackgroundThroughput); n > 0 {
+		s.TSDBStore.EngineOptions.BackgroundRate = limiter.NewRate(n, 0)
+	}
+
+	// Read tiered shards from and move cold shards to object storage.
+	if c.Tiering.Configured() {
+		s.ObjectStore = tiering.NewStore(c.Tiering)
+		s.TSDBStore.EngineOptions.ObjectStore = s.ObjectStore
+	}
+
+	// Attach profiler labels to the write path stages if requested.
+	profiling.SetEnabled(c.Data.ProfileLabelsEnabled)
+
 	// Create the Subscriber service
 	s.Subscriber = subscriber.NewService(c.Subscriber)
 
+	// Create the change stream if it is enabled.
+	if c.CDC.Enabled {
+		s.ChangeStream = cdc.NewService(c.CDC)
+	}
+
+	// Create the mirror if it is enabled.
+	if c.Mirror.Enabled {
+		s.Mirror = mirror.NewService(c.Mirror)
+	}
+
 	// Initialize points writer.
 	s.PointsWriter = coordinator.NewPointsWriter()
 	s.PointsWriter.WriteTimeout = time.Duration(c.Coordinator.WriteTimeout)
+	s.PointsWriter.ShardWriteConcurrency = c.Coordinator.ShardWriteConcurrency
+	s.PointsWriter.ShardWriteQueueDepth = c.Coordinator.ShardWriteQueueDepth
 	s.PointsWriter.TSDBStore = s.TSDBStore
 
+	// Read the shards owned by other data nodes from those nodes, if any
+	// are configured.
+	var shardMapper query.ShardMapper = &coordinator.LocalShardMapper{
+		MetaClient: s.MetaClient,
+		TSDBStore:  coordinator.LocalTSDBStore{Store: s.TSDBStore},
+	}
+	if addrs, err := c.Coordinator.DataNodeAddrs(); err != nil {
+		return nil, err
+	} else if len(addrs) > 0 {
+		shardMapper = &coordinator.RemoteShardMapper{
+			MetaClient: s.MetaClient,
+			TSDBStore:  coordinator.LocalTSDBStore{Store: s.TSDBStore},
+			Dialer:     &coordinator.TCPNodeDialer{Addrs: addrs},
+		}
+	}
+
 	// Initialize query executor.
 	s.QueryExecutor = query.NewQueryExecutor()
 	s.QueryExecutor.StatementExecutor = &coordinator.StatementExecutor{
-		MetaClient:  s.MetaClient,
-		TaskManager: s.QueryExecutor.TaskManager,
-		TSDBStore:   coordinator.LocalTSDBStore{Store: s.TSDBStore},
-		ShardMapper: &coordinator.LocalShardMapper{
-			MetaClient: s.MetaClient,
-			TSDBStore:  coordinator.LocalTSDBStore{Store: s.TSDBStore},
-		},
+		MetaClient:        s.MetaClient,
+		TaskManager:       s.QueryExecutor.TaskManager,
+		TSDBStore:         coordinator.LocalTSDBStore{Store: s.TSDBStore},
+		ShardMapper:       shardMapper,
 		Monitor:           s.Monitor,
 		PointsWriter:      s.PointsWriter,
 		MaxSelectPointN:   c.Coordinator.MaxSelectPointN,
 		MaxSelectSeriesN:  c.Coordinator.MaxSelectSeriesN,
 		MaxSelectBucketsN: c.Coordinator.MaxSelectBucketsN,
+
+		MaxSelectMemory: int64(c.Coordinator.MaxSelectMemory),
+		SpillDir:        c.Coordinator.SpillDir,
+		BackgroundRate:  s.TSDBStore.EngineOptions.BackgroundRate,
 	}
 	s.QueryExecutor.TaskManager.QueryTimeout = time.Duration(c.Coordinator.QueryTimeout)
 	s.QueryExecutor.TaskManager.LogQueriesAfter = time.Duration(c.Coordinator.LogQueriesAfter)
 	s.QueryExecutor.TaskManager.MaxConcurrentQueries = c.Coordinator.MaxConcurrentQueries
+	s.QueryExecutor.TaskManager.MaxConcurrentBatchQueries = c.Coordinator.MaxConcurrentBatchQueries
+	s.QueryExecutor.TaskManager.MaxQueryMemory = int64(c.Coordinator.MaxQueryMemory)
+	s.QueryExecutor.DisabledStatements = c.Coordinator.DisabledStatements
+	s.QueryExecutor.DisabledStatementsExemptUsers = c.Coordinator.DisabledStatementsExemptUsers
 
 	// Initialize the monitor
 	s.Monitor.Version = s.buildInfo.Version
@@ -215,6 +278,12 @@ func (s *Server) Statistics(tags map[string]string) []models.Statistic {
 	statistics = append(statistics, s.TSDBStore.Statistics(tags)...)
 	statistics = append(statistics, s.PointsWriter.Statistics(tags)...)
 	statistics = append(statistics, s.Subscriber.Statistics(tags)...)
+	if s.ChangeStream != nil {
+		statistics = append(statistics, s.ChangeStream.Statistics(tags)...)
+	}
+	if s.Mirror != nil {
+		statistics = append(statistics, s.Mirror.Statistics(tags)...)
+	}
 	for _, srv := range s.Services {
 		if m, ok := srv.(monitor.Reporter); ok {
 			statistics = append(statistics, m.Statistics(tags)...)
@@ -231,6 +300,13 @@ func (s *Server) appendSnapshotterService() {
 	s.SnapshotterService = srv
 }
 
+func (s *Server) appendCoordinatorService() {
+	srv := coordinator.NewService()
+	srv.TSDBStore = s.TSDBStore
+	s.Services = append(s.Services, srv)
+	s.CoordinatorService = srv
+}
+
 // SetLogOutput sets the logger used for all messages. It must not be called
 // after the Open method has been called.
 func (s *Server) SetLogOutput(w io.Writer) {
@@ -251,6 +327,17 @@ func (s *Server) appendRetentionPolicyService(c retention.Config) {
 	s.Services = append(s.Services, srv)
 }
 
+func (s *Server) appendTieringService(c tiering.Config) {
+	if !c.Enabled {
+		return
+	}
+	srv := tiering.NewService(c)
+	srv.MetaClient = s.MetaClient
+	srv.TSDBStore = s.TSDBStore
+	srv.Store = s.ObjectStore
+	s.Services = append(s.Services, srv)
+}
+
 func (s *Server) appendHTTPDService(c httpd.Config) {
 	if !c.Enabled {
 		return
@@ -262,8 +349,23 @@ func (s *Server) appendHTTPDService(c httpd.Config) {
 	srv.Handler.QueryExecutor = s.QueryExecutor
 	srv.Handler.Monitor = s.Monitor
 	srv.Handler.PointsWriter = s.PointsWriter
+	srv.Handler.Store = s.TSDBStore
+	srv.Handler.DatabaseCloner = &coordinator.DatabaseCloner{
+		MetaClient: s.MetaClient,
+		TSDBStore:  s.TSDBStore,
+	}
+	if s.ChangeStream != nil {
+		srv.Handler.ChangeStream = s.ChangeStream
+	}
+	if s.config.Data.TrashRetention > 0 {
+		srv.Handler.DatabaseTrash = &coordinator.DatabaseTrash{
+			MetaClient: s.MetaClient,
+			TSDBStore:  s.TSDBStore,
+		}
+	}
 	srv.Handler.Version = s.buildInfo.Version
 	srv.Handler.BuildType = "OSS"
+	srv.Listener = s.SocketListeners["http"]
 
 	s.Services = append(s.Services, srv)
 }
@@ -279,27 +381,53 @@ func (s *Server) appendStorageService(c storage.Config) {
 	s.Services = append(s.Services, srv)
 }
 
-func (s *Server) appendCollectdService(c collectd.Config) {
+// bindAddresses returns the addresses of a bind address setting of an
+// input. An input listening on several addresses is run as one service per
+// address, so that each has its own statistics. An empty setting is
+// returned as is for the service to apply its default.
+func bindAddresses(s string) ([]string, error) {
+	if s == "" {
+		return []string{""}, nil
+	}
+	return bindaddr.Parse(s)
+}
+
+func (s *Server) appendCollectdService(c collectd.Config) error {
 	if !c.Enabled {
-		return
+		return nil
 	}
-	srv := collectd.NewService(c)
-	srv.MetaClient = s.MetaClient
-	srv.PointsWriter = s.PointsWriter
-	s.Services = append(s.Services, srv)
+	addrs, err := bindAddresses(c.BindAddress)
+	if err != nil {
+		return err
+	}
+	for _, addr := range addrs {
+		c.BindAddress = addr
+		srv := collectd.NewService(c)
+		srv.MetaClient = s.MetaClient
+		srv.PointsWriter = s.PointsWriter
+		s.Services = append(s.Services, srv)
+	}
+	return nil
 }
 
 func (s *Server) appendOpenTSDBService(c opentsdb.Config) error {
 	if !c.Enabled {
 		return nil
 	}
-	srv, err := opentsdb.NewService(c)
+	addrs, err := bindAddresses(c.BindAddress)
 	if err != nil {
 		return err
 	}
-	srv.PointsWriter = s.PointsWriter
-	srv.MetaClient = s.MetaClient
-	s.Services = append(s.Services, srv)
+	for _, addr := range addrs {
+		c.BindAddress = addr
+		srv, err := opentsdb.NewService(c)
+		if err != nil {
+			return err
+		}
+		srv.PointsWriter = s.PointsWriter
+		srv.MetaClient = s.MetaClient
+		s.Services = append(s.Services, srv)
+	}
 	return nil
 }
 
@@ -307,15 +435,22 @@ func (s *Server) appendGraphiteService(c graphite.Config) error {
 	if !c.Enabled {
 		return nil
 	}
-	srv, err := graphite.NewService(c)
+	addrs, err := bindAddresses(c.BindAddress)
 	if err != nil {
 		return err
 	}
+	for _, addr := range addrs {
+		c.BindAddress = addr
+		srv, err := graphite.NewService(c)
+		if err != nil {
+			return err
+		}
 
-	srv.PointsWriter = s.PointsWriter
-	srv.MetaClient = s.MetaClient
-	srv.Monitor = s.Monitor
-	s.Services = append(s.Services, srv)
+		srv.PointsWriter = s.PointsWriter
+		srv.MetaClient = s.MetaClient
+		srv.Monitor = s.Monitor
+		s.Services = append(s.Services, srv)
+	}
 	return nil
 }
 
@@ -333,14 +468,22 @@ func (s *Server) appendPrecreatorService(c precreator.Config) error {
 	return nil
 }
 
-func (s *Server) appendUDPService(c udp.Config) {
+func (s *Server) appendUDPService(c udp.Config) error {
 	if !c.Enabled {
-		return
+		return nil
 	}
-	srv := udp.NewService(c)
-	srv.PointsWriter = s.PointsWriter
-	srv.MetaClient = s.MetaClient
-	s.Services = append(s.Services, srv)
+	addrs, err := bindAddresses(c.BindAddress)
+	if err != nil {
+		return err
+	}
+	for _, addr := range addrs {
+		c.BindAddress = addr
+		srv := udp.NewService(c)
+		srv.PointsWriter = s.PointsWriter
+		srv.MetaClient = s.MetaClient
+		s.Services = append(s.Services, srv)
+	}
+	return nil
 }
 
 func (s *Server) appendContinuousQueryService(c continuous_querier.Config) {
@@ -351,6 +494,9 @@ func (s *Server) appendContinuousQueryService(c continuous_querier.Config) {
 	srv.MetaClient = s.MetaClient
 	srv.QueryExecutor = s.QueryExecutor
 	srv.Monitor = s.Monitor
+	if c.LateArrivalHorizon > 0 {
+		s.PointsWriter.AddCommitSubscriber(srv.Points())
+	}
 	s.Services = append(s.Services, srv)
 }
 
@@ -362,10 +508,13 @@ func (s *Server) Open() error {
 	// Start profiling, if set.
 	startProfile(s.CPUProfile, s.MemProfile)
 
-	// Open shared TCP connection.
-	ln, err := net.Listen("tcp", s.BindAddress)
-	if err != nil {
-		return fmt.Errorf("listen: %s", err)
+	// Open shared TCP connection, unless systemd passed one in.
+	ln := s.SocketListeners["rpc"]
+	if ln == nil {
+		var err error
+		if ln, err = bindaddr.Listen("tcp", s.BindAddress); err != nil {
+			return fmt.Errorf("listen: %s", err)
+		}
 	}
 	s.Listener = ln
 
@@ -377,17 +526,21 @@ func (s *Server) Open() error {
 	s.appendMonitorService()
 	s.appendPrecreatorService(s.config.Precreator)
 	s.appendSnapshotterService()
+	s.appendCoordinatorService()
 	s.appendContinuousQueryService(s.config.ContinuousQuery)
 	s.appendHTTPDService(s.config.HTTPD)
 	s.appendStorageService(s.config.Storage)
 	s.appendRetentionPolicyService(s.config.Retention)
+	s.appendTieringService(s.config.Tiering)
 	for _, i := range s.config.GraphiteInputs {
 		if err := s.appendGraphiteService(i); err != nil {
 			return err
 		}
 	}
 	for _, i := range s.config.CollectdInputs {
-		s.appendCollectdService(i)
+		if err := s.appendCollectdService(i); err != nil {
+			return err
+		}
 	}
 	for _, i := range s.config.OpenTSDBInputs {
 		if err := s.appendOpenTSDBService(i); err != nil {
@@ -395,7 +548,9 @@ func (s *Server) Open() error {
 		}
 	}
 	for _, i := range s.config.UDPInputs {
-		s.appendUDPService(i)
+		if err := s.appendUDPService(i); err != nil {
+			return err
+		}
 	}
 
 	s.Subscriber.MetaClient = s.MetaClient
@@ -403,6 +558,7 @@ func (s *Server) Open() error {
 	s.Monitor.MetaClient = s.MetaClient
 
 	s.SnapshotterService.Listener = mux.Listen(snapshotter.MuxHeader)
+	s.CoordinatorService.Listener = mux.Listen(coordinator.MuxHeader)
 
 	// Configure logging for all services and clients.
 	if s.config.Meta.LoggingEnabled {
@@ -414,12 +570,27 @@ func (s *Server) Open() error {
 	}
 	s.PointsWriter.WithLogger(s.Logger)
 	s.Subscriber.WithLogger(s.Logger)
+	if s.ChangeStream != nil {
+		s.ChangeStream.WithLogger(s.Logger)
+	}
+	if s.Mirror != nil {
+		s.Mirror.WithLogger(s.Logger)
+	}
 	for _, svc := range s.Services {
 		svc.WithLogger(s.Logger)
 	}
 	s.SnapshotterService.WithLogger(s.Logger)
+	s.CoordinatorService.WithLogger(s.Logger)
 	s.Monitor.WithLogger(s.Logger)
 
+	// Close the sockets passed by systemd that no service uses.
+	for name, ln := range s.SocketListeners {
+		if name != "rpc" && !(name == "http" && s.config.HTTPD.Enabled) {
+			s.Logger.Info(fmt.Sprintf("closing unused systemd socket %q", name))
+			ln.Close()
+		}
+	}
+
 	// Open TSDB store.
 	if err := s.TSDBStore.Open(); err != nil {
 		return fmt.Errorf("open tsdb store: %s", err)
@@ -437,6 +608,22 @@ func (s *Server) Open() error {
 
 	s.PointsWriter.AddWriteSubscriber(s.Subscriber.Points())
 
+	// Open the change stream and feed it committed writes.
+	if s.ChangeStream != nil {
+		if err := s.ChangeStream.Open(); err != nil {
+			return fmt.Errorf("open change stream: %s", err)
+		}
+		s.PointsWriter.AddCommitSubscriberWithDrop(s.ChangeStream.Points(), s.ChangeStream.Dropped)
+	}
+
+	// Open the mirror and feed it committed writes.
+	if s.Mirror != nil {
+		if err := s.Mirror.Open(); err != nil {
+			return fmt.Errorf("open mirror: %s", err)
+		}
+		s.PointsWriter.AddCommitSubscriber(s.Mirror.Points())
+	}
+
 	for _, service := range s.Services {
 		if err := service.Open(); err != nil {
 			return fmt.Errorf("open service: %s", err)
@@ -485,6 +672,14 @@ func (s *Server) Close() error {
 		s.Subscriber.Close()
 	}
 
+	if s.ChangeStream != nil {
+		s.ChangeStream.Close()
+	}
+
+	if s.Mirror != nil {
+		s.Mirror.Close()
+	}
+
 	if s.MetaClient != nil {
 		s.MetaClient.Close()
 	}
@@ -0,0 +1,116 @@
+package coordinator
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/services/meta"
+	"github.com/influxdata/influxdb/tsdb"
+)
+
+// DatabaseCloner creates copies of databases on the local node. The shards of
+// the copy are hard-linked to the shards of the original, so a database can
+// be cloned for a staging or test environment without a backup and restore.
+type DatabaseCloner struct {
+	MetaClient databaseCopier
+
+	TSDBStore interface {
+		CloneShard(srcID uint64, database, rp string, dstID uint64) error
+		DeleteDatabase(name string) error
+	}
+}
+
+// CloneDatabase creates the database dst with the retention policies, shard
+// groups and data of the database src. Continuous queries, subscriptions and
+// user privileges are not cloned. If cloning fails, dst is dropped.
+func (c *DatabaseCloner) CloneDatabase(src, dst string) error {
+	di := c.MetaClient.Database(src)
+	if di == nil {
+		return influxdb.ErrDatabaseNotFound(src)
+	} else if c.MetaClient.Database(dst) != nil {
+		return meta.ErrDatabaseExists
+	}
+
+	if err := c.cloneDatabase(di, dst); err != nil {
+		c.TSDBStore.DeleteDatabase(dst)
+		c.MetaClient.DropDatabase(dst)
+		return err
+	}
+	return nil
+}
+
+func (c *DatabaseCloner) cloneDatabase(di *meta.DatabaseInfo, dst string) error {
+	return copyDatabase(c.MetaClient, di, dst, func(srcID uint64, rp string, dstID uint64) error {
+		return c.TSDBStore.CloneShard(srcID, dst, rp, dstID)
+	})
+}
+
+// databaseCopier is the part of the meta client used to copy databases.
+type databaseCopier interface {
+	Database(name string) *meta.DatabaseInfo
+	CreateDatabase(name string) (*meta.DatabaseInfo, error)
+	CreateDatabaseWithRetentionPolicy(name string, spec *meta.RetentionPolicySpec) (*meta.DatabaseInfo, error)
+	CreateRetentionPolicy(database string, spec *meta.RetentionPolicySpec, makeDefault bool) (*meta.RetentionPolicyInfo, error)
+	CreateShardGroup(database, policy string, timestamp time.Time) (*meta.ShardGroupInfo, error)
+	DropDatabase(name string) error
+}
+
+// copyDatabase creates the database dst with the retention policies and
+// shard groups of di. copyShard is called with the ID of each shard of di
+// and the ID of the matching shard of dst, and returns tsdb.ErrShardNotFound
+// for shards that are not on this node.
+func copyDatabase(mc databaseCopier, di *meta.DatabaseInfo, dst string, copyShard func(srcID uint64, rp string, dstID uint64) error) error {
+	// Create the default retention policy with the database so the server
+	// does not create its own.
+	if rpi := di.RetentionPolicy(di.DefaultRetentionPolicy); rpi != nil {
+		if _, err := mc.CreateDatabaseWithRetentionPolicy(dst, retentionPolicySpec(rpi)); err != nil {
+			return err
+		}
+	} else if _, err := mc.CreateDatabase(dst); err != nil {
+		return err
+	}
+
+	for i := range di.RetentionPolicies {
+		rpi := &di.RetentionPolicies[i]
+		if rpi.Name != di.DefaultRetentionPolicy {
+			if _, err := mc.CreateRetentionPolicy(dst, retentionPolicySpec(rpi), false); err != nil {
+				return err
+			}
+		}
+
+		for _, sgi := range rpi.ShardGroups {
+			if sgi.Deleted() {
+				continue
+			}
+
+			g, err := mc.CreateShardGroup(dst, rpi.Name, sgi.StartTime)
+			if err != nil {
+				return err
+			} else if len(g.Shards) != len(sgi.Shards) {
+				return fmt.Errorf("shard group %d of %s has %d shards, copy has %d", sgi.ID, di.Name, len(sgi.Shards), len(g.Shards))
+			}
+
+			for j, sh := range sgi.Shards {
+				// Shards owned by other nodes are not on this node.
+				if err := copyShard(sh.ID, rpi.Name, g.Shards[j].ID); err == tsdb.ErrShardNotFound {
+					continue
+				} else if err != nil {
+					return fmt.Errorf("copy shard %d: %s", sh.ID, err)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// retentionPolicySpec returns the specification of a copy of rpi.
+func retentionPolicySpec(rpi *meta.RetentionPolicyInfo) *meta.RetentionPolicySpec {
+	replicaN, duration := rpi.ReplicaN, rpi.Duration
+	return &meta.RetentionPolicySpec{
+		Name:               rpi.Name,
+		ReplicaN:           &replicaN,
+		Duration:           &duration,
+		ShardGroupDuration: rpi.ShardGroupDuration,
+	}
+}
@@ -3,6 +3,9 @@
 package coordinator
 
 import (
+	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/influxdata/influxdb/monitor/diagnostics"
@@ -18,6 +21,10 @@ const (
 	// A value of zero will make the maximum query limit unlimited.
 	DefaultMaxConcurrentQueries = 0
 
+	// DefaultMaxConcurrentBatchQueries is the maximum number of running batch queries.
+	// A value of zero will make the maximum batch query limit unlimited.
+	DefaultMaxConcurrentBatchQueries = 0
+
 	// DefaultMaxSelectPointN is the maximum number of points a SELECT can process.
 	// A value of zero will make the maximum point count unlimited.
 	DefaultMaxSelectPointN = 0
@@ -25,39 +32,98 @@ const (
 	// DefaultMaxSelectSeriesN is the maximum number of series a SELECT can run.
 	// A value of zero will make the maximum series count unlimited.
 	DefaultMaxSelectSeriesN = 0
+
+	// DefaultShardWriteQueueDepth is the maximum number of writes waiting to
+	// be written to the shards.
+	DefaultShardWriteQueueDepth = 1000
 )
 
 // Config represents the configuration for the coordinator service.
 type Config struct {
-	WriteTimeout         toml.Duration `toml:"write-timeout"`
-	MaxConcurrentQueries int           `toml:"max-concurrent-queries"`
-	QueryTimeout         toml.Duration `toml:"query-timeout"`
-	LogQueriesAfter      toml.Duration `toml:"log-queries-after"`
-	MaxSelectPointN      int           `toml:"max-select-point"`
-	MaxSelectSeriesN     int           `toml:"max-select-series"`
-	MaxSelectBucketsN    int           `toml:"max-select-buckets"`
+	WriteTimeout              toml.Duration `toml:"write-timeout"`
+	MaxConcurrentQueries      int           `toml:"max-concurrent-queries"`
+	MaxConcurrentBatchQueries int           `toml:"max-concurrent-batch-queries"`
+	QueryTimeout              toml.Duration `toml:"query-timeout"`
+	LogQueriesAfter           toml.Duration `toml:"log-queries-after"`
+	MaxSelectPointN           int           `toml:"max-select-point"`
+	MaxSelectSeriesN          int           `toml:"max-select-series"`
+	MaxSelectBucketsN         int           `toml:"max-select-buckets"`
+	MaxSelectMemory           toml.Size     `toml:"max-select-memory"`
+	MaxQueryMemory            toml.Size     `toml:"max-query-memory"`
+	SpillDir                  string        `toml:"spill-dir"`
+	ShardWriteConcurrency     int           `toml:"shard-write-concurrency"`
+	ShardWriteQueueDepth      int           `toml:"shard-write-queue-depth"`
+
+	// DisabledStatements are the kinds of statements, such as "DROP DATABASE",
+	// that only the admin users in DisabledStatementsExemptUsers can execute.
+	DisabledStatements            []string `toml:"disabled-statements"`
+	DisabledStatementsExemptUsers []string `toml:"disabled-statements-exempt-users"`
+
+	// DataNodes are the bind addresses of the other data nodes that own
+	// shards, each given as "<node id>=<host>:<port>".
+	DataNodes []string `toml:"data-nodes"`
 }
 
 // NewConfig returns an instance of Config with defaults.
 func NewConfig() Config {
 	return Config{
-		WriteTimeout:         toml.Duration(DefaultWriteTimeout),
-		QueryTimeout:         toml.Duration(query.DefaultQueryTimeout),
-		MaxConcurrentQueries: DefaultMaxConcurrentQueries,
-		MaxSelectPointN:      DefaultMaxSelectPointN,
-		MaxSelectSeriesN:     DefaultMaxSelectSeriesN,
+		WriteTimeout:              toml.Duration(DefaultWriteTimeout),
+		QueryTimeout:              toml.Duration(query.DefaultQueryTimeout),
+		MaxConcurrentQueries:      DefaultMaxConcurrentQueries,
+		MaxConcurrentBatchQueries: DefaultMaxConcurrentBatchQueries,
+		MaxSelectPointN:           DefaultMaxSelectPointN,
+		MaxSelectSeriesN:          DefaultMaxSelectSeriesN,
+		ShardWriteQueueDepth:      DefaultShardWriteQueueDepth,
+	}
+}
+
+// Validate returns an error if the config is invalid.
+func (c Config) Validate() error {
+	if err := query.ValidateStatementKinds(c.DisabledStatements); err != nil {
+		return fmt.Errorf("invalid disabled-statements: %s", err)
+	}
+	_, err := c.DataNodeAddrs()
+	return err
+}
+
+// DataNodeAddrs returns the bind addresses of DataNodes by node ID.
+func (c Config) DataNodeAddrs() (map[uint64]string, error) {
+	if len(c.DataNodes) == 0 {
+		return nil, nil
+	}
+
+	addrs := make(map[uint64]string, len(c.DataNodes))
+	for _, s := range c.DataNodes {
+		i := strings.IndexByte(s, '=')
+		if i <= 0 || i == len(s)-1 {
+			return nil, fmt.Errorf("invalid data node %q: expected <node id>=<host>:<port>", s)
+		}
+		id, err := strconv.ParseUint(s[:i], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid data node id %q", s[:i])
+		}
+		addrs[id] = s[i+1:]
 	}
+	return addrs, nil
 }
 
 // Diagnostics returns a diagnostics representation of a subset of the Config.
 func (c Config) Diagnostics() (*diagnostics.Diagnostics, error) {
 	return diagnostics.RowFromMap(map[string]interface{}{
-		"write-timeout":          c.WriteTimeout,
-		"max-concurrent-queries": c.MaxConcurrentQueries,
-		"query-timeout":          c.QueryTimeout,
-		"log-queries-after":      c.LogQueriesAfter,
-		"max-select-point":       c.MaxSelectPointN,
-		"max-select-series":      c.MaxSelectSeriesN,
-		"max-select-buckets":     c.MaxSelectBucketsN,
+		"write-timeout":                c.WriteTimeout,
+		"max-concurrent-queries":       c.MaxConcurrentQueries,
+		"max-concurrent-batch-queries": c.MaxConcurrentBatchQueries,
+		"query-timeout":                c.QueryTimeout,
+		"log-queries-after":            c.LogQueriesAfter,
+		"max-select-point":             c.MaxSelectPointN,
+		"max-select-series":            c.MaxSelectSeriesN,
+		"max-select-buckets":           c.MaxSelectBucketsN,
+		"max-select-memory":            c.MaxSelectMemory,
+		"max-query-memory":             c.MaxQueryMemory,
+		"spill-dir":                    c.SpillDir,
+		"shard-write-concurrency":      c.ShardWriteConcurrency,
+		"shard-write-queue-depth":      c.ShardWriteQueueDepth,
+		"disabled-statements":          strings.Join(c.DisabledStatements, ", "),
+		"data-nodes":                   strings.Join(c.DataNodes, ", "),
 	}), nil
 }
@@ -1,6 +1,7 @@
 package coordinator_test
 
 import (
+	"reflect"
 	"testing"
 	"time"
 
@@ -13,6 +14,10 @@ func TestConfig_Parse(t *testing.T) {
 	var c coordinator.Config
 	if _, err := toml.Decode(`
 write-timeout = "20s"
+max-select-memory = "64m"
+spill-dir = "/var/tmp/influxdb"
+disabled-statements = ["DROP DATABASE", "DELETE"]
+disabled-statements-exempt-users = ["root"]
 `, &c); err != nil {
 		t.Fatal(err)
 	}
@@ -20,5 +25,33 @@ write-timeout = "20s"
 	// Validate configuration.
 	if time.Duration(c.WriteTimeout) != 20*time.Second {
 		t.Fatalf("unexpected write timeout s: %s", c.WriteTimeout)
+	} else if c.MaxSelectMemory != 64<<20 {
+		t.Fatalf("unexpected max select memory: %d", c.MaxSelectMemory)
+	} else if c.SpillDir != "/var/tmp/influxdb" {
+		t.Fatalf("unexpected spill dir: %s", c.SpillDir)
+	} else if !reflect.DeepEqual(c.DisabledStatements, []string{"DROP DATABASE", "DELETE"}) {
+		t.Fatalf("unexpected disabled statements: %v", c.DisabledStatements)
+	} else if !reflect.DeepEqual(c.DisabledStatementsExemptUsers, []string{"root"}) {
+		t.Fatalf("unexpected disabled statements exempt users: %v", c.DisabledStatementsExemptUsers)
+	}
+}
+
+// Ensure unknown kinds of disabled statements, such as misspelled ones, are rejected.
+func TestConfig_Validate_DisabledStatements(t *testing.T) {
+	c := coordinator.NewConfig()
+	c.DisabledStatements = []string{"drop  database", "DELETE", "show tag values"}
+	if err := c.Validate(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	c.DisabledStatements = []string{"DROP DATABSE"}
+	if err := c.Validate(); err == nil || err.Error() != `invalid disabled-statements: unknown statement kind: "DROP DATABSE"` {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Prefixes of several kinds of statements are not kinds themselves.
+	c.DisabledStatements = []string{"DROP"}
+	if err := c.Validate(); err == nil {
+		t.Fatal("expected error")
 	}
 }
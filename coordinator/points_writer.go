@@ -3,6 +3,7 @@ package coordinator
 import (
 	"errors"
 	"fmt"
+	"runtime"
 	"sort"
 	"sync"
 	"sync/atomic"
@@ -24,8 +25,12 @@ const (
 	statWriteDrop          = "writeDrop"
 	statWriteTimeout       = "writeTimeout"
 	statWriteErr           = "writeError"
+	statWriteQuotaExceeded = "writeQuotaExceeded"
+	statWriteQueueFull     = "writeQueueFull"
 	statSubWriteOK         = "subWriteOk"
 	statSubWriteDrop       = "subWriteDrop"
+	statCommitWriteOK      = "commitWriteOk"
+	statCommitWriteDrop    = "commitWriteDrop"
 )
 
 var (
@@ -38,6 +43,10 @@ var (
 
 	// ErrWriteFailed is returned when no writes succeeded.
 	ErrWriteFailed = errors.New("write failed")
+
+	// ErrShardWriteQueueFull is returned when a shard has too many writes
+	// waiting to be written.
+	ErrShardWriteQueueFull = errors.New("shard write queue full")
 )
 
 // PointsWriter handles writes across multiple local and remote data nodes.
@@ -58,13 +67,51 @@ type PointsWriter struct {
 	TSDBStore interface {
 		CreateShard(database, retentionPolicy string, shardID uint64, enabled bool) error
 		WriteToShard(shardID uint64, points []models.Point) error
+		EnforceQuota(database string, q tsdb.Quota, n int) error
 	}
 
-	subPoints []chan<- *WritePointsRequest
+	// The writes to each shard are queued and written by a pool of at most
+	// ShardWriteConcurrency workers, or runtime.GOMAXPROCS workers if it is
+	// 0, shared by all shards. The workers take turns between the shards
+	// with queued writes. At most ShardWriteQueueDepth writes wait in the
+	// queues of all shards. If ShardWriteQueueDepth is 0, each shard of a
+	// write is written in its own goroutine instead.
+	ShardWriteConcurrency int
+	ShardWriteQueueDepth  int
+
+	queueMu sync.Mutex
+	queues  map[uint64]*shardWriteQueue
+	queued  int64 // writes waiting in all queues
+
+	poolMu  sync.Mutex
+	ready   chan *shardWriteQueue // queues with writes, in turn order
+	workers int
+
+	subPoints    []chan<- *WritePointsRequest
+	commitPoints []commitSubscriber
 
 	stats *WriteStatistics
 }
 
+// shardWriteQueue holds the writes waiting to be written to a shard.
+type shardWriteQueue struct {
+	shardID uint64
+
+	mu        sync.Mutex
+	writes    []*shardWrite
+	scheduled bool // set while the queue is in the ready channel
+}
+
+// shardWrite is a write of points to a shard. The result of the write is sent
+// to errC, which must be buffered.
+type shardWrite struct {
+	shard           *meta.ShardInfo
+	database        string
+	retentionPolicy string
+	points          []models.Point
+	errC            chan<- error
+}
+
 // WritePointsRequest represents a request to write point data to the cluster.
 type WritePointsRequest struct {
 	Database        string
@@ -86,10 +133,12 @@ func (w *WritePointsRequest) AddPoint(name string, value interface{}, timestamp
 // NewPointsWriter returns a new instance of PointsWriter for a node.
 func NewPointsWriter() *PointsWriter {
 	return &PointsWriter{
-		closing:      make(chan struct{}),
-		WriteTimeout: DefaultWriteTimeout,
-		Logger:       zap.New(zap.NullEncoder()),
-		stats:        &WriteStatistics{},
+		closing:              make(chan struct{}),
+		WriteTimeout:         DefaultWriteTimeout,
+		ShardWriteQueueDepth: DefaultShardWriteQueueDepth,
+		Logger:               zap.New(zap.NullEncoder()),
+		queues:               make(map[uint64]*shardWriteQueue),
+		stats:                &WriteStatistics{},
 	}
 }
 
@@ -140,6 +189,7 @@ func (w *PointsWriter) Close() error {
 		// dropping any in-flight writes.
 		w.subPoints = nil
 	}
+	w.commitPoints = nil
 	return nil
 }
 
@@ -147,6 +197,27 @@ func (w *PointsWriter) AddWriteSubscriber(c chan<- *WritePointsRequest) {
 	w.subPoints = append(w.subPoints, c)
 }
 
+// AddCommitSubscriber adds a channel that receives each write once it has
+// been written to every shard. Unlike write subscribers, which receive
+// writes before they are stored, commit subscribers never see writes that
+// failed. Writes are dropped if the channel is full.
+func (w *PointsWriter) AddCommitSubscriber(c chan<- *WritePointsRequest) {
+	w.AddCommitSubscriberWithDrop(c, nil)
+}
+
+// AddCommitSubscriberWithDrop is like AddCommitSubscriber but calls dropped
+// with each write that is not sent because the channel is full, so that the
+// subscriber can tell that it missed writes. dropped must not block.
+func (w *PointsWriter) AddCommitSubscriberWithDrop(c chan<- *WritePointsRequest, dropped func(*WritePointsRequest)) {
+	w.commitPoints = append(w.commitPoints, commitSubscriber{c: c, dropped: dropped})
+}
+
+// commitSubscriber is a channel receiving committed writes.
+type commitSubscriber struct {
+	c       chan<- *WritePointsRequest
+	dropped func(*WritePointsRequest)
+}
+
 // WithLogger sets the Logger on w.
 func (w *PointsWriter) WithLogger(log zap.Logger) {
 	w.Logger = log.With(zap.String("service", "write"))
@@ -161,8 +232,12 @@ type WriteStatistics struct {
 	WriteDropped       int64
 	WriteTimeout       int64
 	WriteErr           int64
+	WriteQuotaExceeded int64
+	WriteQueueFull     int64
 	SubWriteOK         int64
 	SubWriteDrop       int64
+	CommitWriteOK      int64
+	CommitWriteDrop    int64
 }
 
 // Statistics returns statistics for periodic monitoring.
@@ -178,8 +253,12 @@ func (w *PointsWriter) Statistics(tags map[string]string) []models.Statistic {
 			statWriteDrop:          atomic.LoadInt64(&w.stats.WriteDropped),
 			statWriteTimeout:       atomic.LoadInt64(&w.stats.WriteTimeout),
 			statWriteErr:           atomic.LoadInt64(&w.stats.WriteErr),
+			statWriteQuotaExceeded: atomic.LoadInt64(&w.stats.WriteQuotaExceeded),
+			statWriteQueueFull:     atomic.LoadInt64(&w.stats.WriteQueueFull),
 			statSubWriteOK:         atomic.LoadInt64(&w.stats.SubWriteOK),
 			statSubWriteDrop:       atomic.LoadInt64(&w.stats.SubWriteDrop),
+			statCommitWriteOK:      atomic.LoadInt64(&w.stats.CommitWriteOK),
+			statCommitWriteDrop:    atomic.LoadInt64(&w.stats.CommitWriteDrop),
 		},
 	}}
 }
@@ -191,7 +270,13 @@ func (w *PointsWriter) MapShards(wp *WritePointsRequest) (*ShardMapping, error)
 	rp, err := w.MetaClient.RetentionPolicy(wp.Database, wp.RetentionPolicy)
 	if err != nil {
 		return nil, err
-	} else if rp == nil {
+	}
+	return w.mapShards(wp, rp)
+}
+
+// mapShards maps the points contained in wp to the shards of rp.
+func (w *PointsWriter) mapShards(wp *WritePointsRequest, rp *meta.RetentionPolicyInfo) (*ShardMapping, error) {
+	if rp == nil {
 		return nil, influxdb.ErrRetentionPolicyNotFound(wp.RetentionPolicy)
 	}
 
@@ -292,25 +377,47 @@ func (w *PointsWriter) WritePointsPrivileged(database, retentionPolicy string, c
 	atomic.AddInt64(&w.stats.WriteReq, 1)
 	atomic.AddInt64(&w.stats.PointWriteReq, int64(len(points)))
 
+	db := w.MetaClient.Database(database)
 	if retentionPolicy == "" {
-		db := w.MetaClient.Database(database)
 		if db == nil {
 			return influxdb.ErrDatabaseNotFound(database)
 		}
 		retentionPolicy = db.DefaultRetentionPolicy
 	}
 
-	shardMappings, err := w.MapShards(&WritePointsRequest{Database: database, RetentionPolicy: retentionPolicy, Points: points})
+	if db != nil && db.Quota != nil {
+		q := db.Quota
+		quota := tsdb.Quota{MaxBytes: q.MaxBytes, MaxSeries: q.MaxSeries, MaxWritesPerSecond: q.MaxWritesPerSecond}
+		if err := w.TSDBStore.EnforceQuota(database, quota, len(points)); err != nil {
+			atomic.AddInt64(&w.stats.WriteQuotaExceeded, 1)
+			return err
+		}
+	}
+
+	// Map the points with the retention policy of the database already looked
+	// up rather than asking the meta client for it again.
+	var shardMappings *ShardMapping
+	var err error
+	wp := &WritePointsRequest{Database: database, RetentionPolicy: retentionPolicy, Points: points}
+	if db != nil {
+		shardMappings, err = w.mapShards(wp, db.RetentionPolicy(retentionPolicy))
+	} else {
+		shardMappings, err = w.MapShards(wp)
+	}
 	if err != nil {
 		return err
 	}
 
-	// Write each shard in it's own goroutine and return as soon as one fails.
+	// Write each shard through its queue and return as soon as one fails.
 	ch := make(chan error, len(shardMappings.Points))
 	for shardID, points := range shardMappings.Points {
-		go func(shard *meta.ShardInfo, database, retentionPolicy string, points []models.Point) {
-			ch <- w.writeToShard(shard, database, retentionPolicy, points)
-		}(shardMappings.Shards[shardID], database, retentionPolicy, points)
+		w.enqueueShardWrite(&shardWrite{
+			shard:           shardMappings.Shards[shardID],
+			database:        database,
+			retentionPolicy: retentionPolicy,
+			points:          points,
+			errC:            ch,
+		})
 	}
 
 	// Send points to subscriptions if possible.
@@ -356,9 +463,153 @@ func (w *PointsWriter) WritePointsPrivileged(database, retentionPolicy string, c
 			}
 		}
 	}
+
+	w.sendCommitted(database, retentionPolicy, shardMappings)
 	return err
 }
 
+// sendCommitted sends the points written to shards to the commit subscribers.
+func (w *PointsWriter) sendCommitted(database, retentionPolicy string, shardMappings *ShardMapping) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if len(w.commitPoints) == 0 {
+		return
+	}
+
+	var points []models.Point
+	for _, p := range shardMappings.Points {
+		points = append(points, p...)
+	}
+	req := &WritePointsRequest{Database: database, RetentionPolicy: retentionPolicy, Points: points}
+
+	for _, sub := range w.commitPoints {
+		select {
+		case sub.c <- req:
+			atomic.AddInt64(&w.stats.CommitWriteOK, 1)
+		default:
+			atomic.AddInt64(&w.stats.CommitWriteDrop, 1)
+			if sub.dropped != nil {
+				sub.dropped(req)
+			}
+		}
+	}
+}
+
+// enqueueShardWrite adds sw to the queue of its shard, schedules the queue if
+// it was empty and starts a worker if the pool has fewer than the maximum. If
+// the queues of all shards are full, sw fails with ErrShardWriteQueueFull.
+func (w *PointsWriter) enqueueShardWrite(sw *shardWrite) {
+	if w.ShardWriteQueueDepth <= 0 {
+		go func() {
+			sw.errC <- w.writeToShard(sw.shard, sw.database, sw.retentionPolicy, sw.points)
+		}()
+		return
+	}
+
+	ready := w.readyQueues()
+	if atomic.AddInt64(&w.queued, 1) > int64(cap(ready)) {
+		atomic.AddInt64(&w.queued, -1)
+		atomic.AddInt64(&w.stats.WriteQueueFull, 1)
+		sw.errC <- ErrShardWriteQueueFull
+		return
+	}
+
+	w.queueMu.Lock()
+	if w.queues == nil {
+		w.queues = make(map[uint64]*shardWriteQueue)
+	}
+	q := w.queues[sw.shard.ID]
+	if q == nil {
+		q = &shardWriteQueue{shardID: sw.shard.ID}
+		w.queues[sw.shard.ID] = q
+	}
+	q.mu.Lock()
+	q.writes = append(q.writes, sw)
+	schedule := !q.scheduled
+	q.scheduled = true
+	q.mu.Unlock()
+	w.queueMu.Unlock()
+
+	// A queue is in the ready channel at most once and holds at least one
+	// write, so the channel never has more entries than queued writes.
+	if schedule {
+		ready <- q
+	}
+
+	concurrency := w.ShardWriteConcurrency
+	if concurrency <= 0 {
+		concurrency = runtime.GOMAXPROCS(0)
+	}
+	w.poolMu.Lock()
+	if w.workers < concurrency {
+		w.workers++
+		go w.processShardWrites(ready)
+	}
+	w.poolMu.Unlock()
+}
+
+// readyQueues returns the channel of queues waiting for a worker. Its capacity
+// is the maximum number of queued writes.
+func (w *PointsWriter) readyQueues() chan *shardWriteQueue {
+	w.poolMu.Lock()
+	defer w.poolMu.Unlock()
+	if w.ready == nil {
+		w.ready = make(chan *shardWriteQueue, w.ShardWriteQueueDepth)
+	}
+	return w.ready
+}
+
+// processShardWrites writes one queued write of each ready queue in turn
+// until no queue has writes. A queue with more writes goes to the back of
+// the ready channel so busy shards do not hold up the others.
+func (w *PointsWriter) processShardWrites(ready chan *shardWriteQueue) {
+	for {
+		var q *shardWriteQueue
+		select {
+		case q = <-ready:
+		default:
+			// Check again under the lock so a queue scheduled after the
+			// receive is not left without a worker.
+			w.poolMu.Lock()
+			if len(ready) > 0 {
+				w.poolMu.Unlock()
+				continue
+			}
+			w.workers--
+			w.poolMu.Unlock()
+			return
+		}
+
+		q.mu.Lock()
+		sw := q.writes[0]
+		q.writes[0] = nil
+		q.writes = q.writes[1:]
+		more := len(q.writes) > 0
+		q.scheduled = more
+		q.mu.Unlock()
+		atomic.AddInt64(&w.queued, -1)
+
+		if more {
+			ready <- q
+		} else {
+			w.removeQueue(q)
+		}
+
+		sw.errC <- w.writeToShard(sw.shard, sw.database, sw.retentionPolicy, sw.points)
+	}
+}
+
+// removeQueue removes q from the queues of the shards if it is still empty.
+func (w *PointsWriter) removeQueue(q *shardWriteQueue) {
+	w.queueMu.Lock()
+	defer w.queueMu.Unlock()
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if !q.scheduled && w.queues[q.shardID] == q {
+		delete(w.queues, q.shardID)
+	}
+}
+
 // writeToShards writes points to a shard.
 func (w *PointsWriter) writeToShard(shard *meta.ShardInfo, database, retentionPolicy string, points []models.Point) error {
 	atomic.AddInt64(&w.stats.PointWriteReqLocal, int64(len(points)))
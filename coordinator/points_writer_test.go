@@ -1,6 +1,7 @@
 package coordinator_test
 
 import (
+	"errors"
 	"fmt"
 	"reflect"
 	"sync"
@@ -428,6 +429,95 @@ func TestPointsWriter_WritePoints_Dropped(t *testing.T) {
 	}
 }
 
+// Ensure that commit subscribers only receive writes stored in every shard.
+func TestPointsWriter_WritePoints_CommitSubscriber(t *testing.T) {
+	for _, tt := range []struct {
+		name   string
+		err    error
+		commit bool
+	}{
+		{name: "stored", commit: true},
+		{name: "failed", err: errors.New("write failed")},
+	} {
+		ms := NewPointsWriterMetaClient()
+		pr := &coordinator.WritePointsRequest{
+			Database:        "mydb",
+			RetentionPolicy: "myrp",
+		}
+		pr.AddPoint("cpu", 1.0, time.Now(), nil)
+		pr.AddPoint("cpu", 2.0, time.Now().Add(time.Hour), nil)
+
+		store := &fakeStore{
+			WriteFn: func(shardID uint64, points []models.Point) error {
+				return tt.err
+			},
+		}
+
+		commitPoints := make(chan *coordinator.WritePointsRequest, 1)
+		c := coordinator.NewPointsWriter()
+		c.MetaClient = ms
+		c.TSDBStore = store
+		c.AddCommitSubscriber(commitPoints)
+		c.Node = &influxdb.Node{ID: 1}
+		c.Open()
+
+		err := c.WritePointsPrivileged(pr.Database, pr.RetentionPolicy, models.ConsistencyLevelOne, pr.Points)
+		c.Close()
+		if err != tt.err {
+			t.Fatalf("%s: unexpected error: %v", tt.name, err)
+		}
+
+		select {
+		case req := <-commitPoints:
+			if !tt.commit {
+				t.Fatalf("%s: unexpected commit", tt.name)
+			} else if req.Database != "mydb" || req.RetentionPolicy != "myrp" || len(req.Points) != 2 {
+				t.Fatalf("%s: unexpected request: %+v", tt.name, req)
+			}
+		default:
+			if tt.commit {
+				t.Fatalf("%s: expected commit", tt.name)
+			}
+		}
+	}
+}
+
+// Ensure commit subscribers are told about the writes they miss.
+func TestPointsWriter_CommitSubscriber_Dropped(t *testing.T) {
+	ms := NewPointsWriterMetaClient()
+	pr := &coordinator.WritePointsRequest{
+		Database:        "mydb",
+		RetentionPolicy: "myrp",
+	}
+	pr.AddPoint("cpu", 1.0, time.Now(), nil)
+
+	commitPoints := make(chan *coordinator.WritePointsRequest, 1)
+	var dropped []*coordinator.WritePointsRequest
+	c := coordinator.NewPointsWriter()
+	c.MetaClient = ms
+	c.TSDBStore = &fakeStore{
+		WriteFn: func(shardID uint64, points []models.Point) error { return nil },
+	}
+	c.AddCommitSubscriberWithDrop(commitPoints, func(req *coordinator.WritePointsRequest) {
+		dropped = append(dropped, req)
+	})
+	c.Node = &influxdb.Node{ID: 1}
+	c.Open()
+	defer c.Close()
+
+	for i := 0; i < 2; i++ {
+		if err := c.WritePointsPrivileged(pr.Database, pr.RetentionPolicy, models.ConsistencyLevelOne, pr.Points); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if len(commitPoints) != 1 {
+		t.Fatalf("unexpected commits: %d", len(commitPoints))
+	} else if len(dropped) != 1 || dropped[0].Database != "mydb" || len(dropped[0].Points) != 1 {
+		t.Fatalf("unexpected drops: %v", dropped)
+	}
+}
+
 type fakePointsWriter struct {
 	WritePointsIntoFn func(*coordinator.IntoWriteRequest) error
 }
@@ -546,17 +636,157 @@ func TestBufferedPointsWriter(t *testing.T) {
 	}
 }
 
+// Ensure writes to a database with a quota are refused once it is exceeded.
+func TestPointsWriter_WritePoints_Quota(t *testing.T) {
+	ms := NewPointsWriterMetaClient()
+	ms.DatabaseFn = func(database string) *meta.DatabaseInfo {
+		return &meta.DatabaseInfo{Name: database, DefaultRetentionPolicy: "myrp", Quota: &meta.QuotaInfo{MaxSeries: 10}}
+	}
+
+	var written bool
+	store := &fakeStore{
+		WriteFn: func(shardID uint64, points []models.Point) error {
+			written = true
+			return nil
+		},
+		EnforceQuotaFn: func(database string, q tsdb.Quota, n int) error {
+			if database != "mydb" || q.MaxSeries != 10 || n != 1 {
+				t.Fatalf("unexpected quota check: %s %+v %d", database, q, n)
+			}
+			return &tsdb.QuotaExceededError{Database: database, Resource: "series", Usage: 10, Limit: 10}
+		},
+	}
+
+	c := coordinator.NewPointsWriter()
+	c.MetaClient = ms
+	c.TSDBStore = store
+	c.Node = &influxdb.Node{ID: 1}
+
+	pr := &coordinator.WritePointsRequest{Database: "mydb"}
+	pr.AddPoint("cpu", 1.0, time.Now(), nil)
+	err := c.WritePointsPrivileged(pr.Database, pr.RetentionPolicy, models.ConsistencyLevelOne, pr.Points)
+	if _, ok := err.(*tsdb.QuotaExceededError); !ok {
+		t.Fatalf("unexpected error: %v", err)
+	} else if written {
+		t.Fatal("unexpected write")
+	}
+}
+
+// Ensure writes to a shard whose queue is full fail instead of piling up.
+func TestPointsWriter_WritePoints_QueueFull(t *testing.T) {
+	started, release := make(chan struct{}, 2), make(chan struct{})
+	defer close(release)
+	store := &fakeStore{
+		WriteFn: func(shardID uint64, points []models.Point) error {
+			started <- struct{}{}
+			<-release
+			return nil
+		},
+	}
+
+	c := coordinator.NewPointsWriter()
+	c.MetaClient = NewPointsWriterMetaClient()
+	c.TSDBStore = store
+	c.Node = &influxdb.Node{ID: 1}
+	c.WriteTimeout = 50 * time.Millisecond
+	c.ShardWriteConcurrency = 1
+	c.ShardWriteQueueDepth = 1
+
+	write := func() error {
+		pr := &coordinator.WritePointsRequest{Database: "mydb"}
+		pr.AddPoint("cpu", 1.0, time.Now(), nil)
+		return c.WritePointsPrivileged(pr.Database, pr.RetentionPolicy, models.ConsistencyLevelOne, pr.Points)
+	}
+
+	// The first write is being written by the only worker of the shard, so
+	// the second one waits in the queue until it times out.
+	go write()
+	<-started
+	if err := write(); err != coordinator.ErrTimeout {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := write(); err != coordinator.ErrShardWriteQueueFull {
+		t.Fatalf("unexpected error: %v", err)
+	} else if stats := c.Statistics(nil); stats[0].Values["writeQueueFull"] != int64(1) {
+		t.Fatalf("unexpected statistics: %v", stats[0].Values)
+	}
+}
+
+// Ensure the shard write workers and queue limit are shared by all shards.
+func TestPointsWriter_WritePoints_SharedQueue(t *testing.T) {
+	started, release := make(chan uint64, 2), make(chan struct{})
+	defer close(release)
+	store := &fakeStore{
+		WriteFn: func(shardID uint64, points []models.Point) error {
+			started <- shardID
+			<-release
+			return nil
+		},
+	}
+
+	ms := NewPointsWriterMetaClient()
+	c := coordinator.NewPointsWriter()
+	c.MetaClient = ms
+	c.TSDBStore = store
+	c.Node = &influxdb.Node{ID: 1}
+	c.WriteTimeout = 50 * time.Millisecond
+	c.ShardWriteConcurrency = 1
+	c.ShardWriteQueueDepth = 1
+
+	// Each write goes to the shard group of its time.
+	write := func(t time.Time) error {
+		pr := &coordinator.WritePointsRequest{Database: "mydb"}
+		pr.AddPoint("cpu", 1.0, t, nil)
+		return c.WritePointsPrivileged(pr.Database, pr.RetentionPolicy, models.ConsistencyLevelOne, pr.Points)
+	}
+	now := time.Now()
+
+	// The only worker is busy with the first shard, so the write to the
+	// second shard waits in its queue.
+	go write(now)
+	<-started
+	if err := write(now.Add(time.Hour)); err != coordinator.ErrTimeout {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The queue limit is reached, so a write to the first shard fails.
+	if err := write(now); err != coordinator.ErrShardWriteQueueFull {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The queued write to the second shard is written next.
+	sg, err := ms.CreateShardGroup("mydb", "myp", now.Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	release <- struct{}{}
+	select {
+	case id := <-started:
+		if id != sg.Shards[0].ID {
+			t.Fatalf("unexpected shard: %d", id)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for queued write")
+	}
+}
+
 var shardID uint64
 
 type fakeStore struct {
-	WriteFn       func(shardID uint64, points []models.Point) error
-	CreateShardfn func(database, retentionPolicy string, shardID uint64, enabled bool) error
+	WriteFn        func(shardID uint64, points []models.Point) error
+	CreateShardfn  func(database, retentionPolicy string, shardID uint64, enabled bool) error
+	EnforceQuotaFn func(database string, q tsdb.Quota, n int) error
 }
 
 func (f *fakeStore) WriteToShard(shardID uint64, points []models.Point) error {
 	return f.WriteFn(shardID, points)
 }
 
+func (f *fakeStore) EnforceQuota(database string, q tsdb.Quota, n int) error {
+	return f.EnforceQuotaFn(database, q, n)
+}
+
 func (f *fakeStore) CreateShard(database, retentionPolicy string, shardID uint64, enabled bool) error {
 	return f.CreateShardfn(database, retentionPolicy, shardID, enabled)
 }
@@ -579,6 +809,10 @@ func NewPointsWriterMetaClient() *PointsWriterMetaClient {
 		return rp, nil
 	}
 
+	ms.DatabaseFn = func(database string) *meta.DatabaseInfo {
+		return &meta.DatabaseInfo{Name: database, DefaultRetentionPolicy: rp.Name, RetentionPolicies: []meta.RetentionPolicyInfo{*rp}}
+	}
+
 	ms.CreateShardGroupIfNotExistsFn = func(database, policy string, timestamp time.Time) (*meta.ShardGroupInfo, error) {
 		for i, sg := range rp.ShardGroups {
 			if timestamp.Equal(sg.StartTime) || timestamp.After(sg.StartTime) && timestamp.Before(sg.EndTime) {
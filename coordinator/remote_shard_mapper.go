@@ -0,0 +1,522 @@
+package coordinator
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"regexp"
+	"time"
+
+	"github.com/influxdata/influxdb/query"
+	"github.com/influxdata/influxdb/services/meta"
+	"github.com/influxdata/influxdb/tsdb"
+	"github.com/influxdata/influxql"
+)
+
+// DefaultDialTimeout is the default time to wait for a connection to another
+// data node.
+const DefaultDialTimeout = 5 * time.Second
+
+// maxShardMessageSize is the largest request or response read from the
+// shard service.
+const maxShardMessageSize = 64 * 1024 * 1024
+
+// Request types of the shard service.
+const (
+	shardRequestFieldDimensions byte = iota + 1
+	shardRequestMapType
+	shardRequestCreateIterator
+	shardRequestIteratorCost
+)
+
+// NodeDialer dials the shard service of another data node.
+type NodeDialer interface {
+	DialNode(nodeID uint64) (net.Conn, error)
+}
+
+// TCPNodeDialer dials the shard service of data nodes at fixed TCP addresses.
+type TCPNodeDialer struct {
+	// Addrs are the bind addresses of the data nodes by node ID.
+	Addrs map[uint64]string
+
+	// Timeout is the time to wait for a connection. DefaultDialTimeout is
+	// used if it is zero.
+	Timeout time.Duration
+}
+
+// DialNode connects to the shard service of the data node with the given ID.
+func (d *TCPNodeDialer) DialNode(nodeID uint64) (net.Conn, error) {
+	addr, ok := d.Addrs[nodeID]
+	if !ok {
+		return nil, fmt.Errorf("no address for data node %d", nodeID)
+	}
+
+	timeout := d.Timeout
+	if timeout == 0 {
+		timeout = DefaultDialTimeout
+	}
+	conn, err := net.DialTimeout("tcp", addr, timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	// Select the shard service on the multiplexed listener of the node.
+	if _, err := conn.Write([]byte{MuxHeader}); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return conn, nil
+}
+
+// RemoteShardMapper implements a ShardMapper for shards that may be owned by
+// other data nodes. Shards in the local store are read directly and the
+// others are read from their first owner over TCP.
+type RemoteShardMapper struct {
+	MetaClient interface {
+		ShardGroupsByTimeRange(database, policy string, min, max time.Time) (a []meta.ShardGroupInfo, err error)
+	}
+
+	TSDBStore interface {
+		ShardGroup(ids []uint64) tsdb.ShardGroup
+		Shard(id uint64) *tsdb.Shard
+	}
+
+	// Dialer connects to the nodes owning the shards missing from the local
+	// store. If it is nil, those shards are skipped like by LocalShardMapper.
+	Dialer NodeDialer
+}
+
+// MapShards maps the sources to the local and remote shards holding them.
+func (e *RemoteShardMapper) MapShards(sources influxql.Sources, t influxql.TimeRange, opt query.SelectOptions) (query.ShardGroup, error) {
+	a := &RemoteShardMapping{
+		LocalShardMapping: LocalShardMapping{
+			ShardMap: make(map[Source]tsdb.ShardGroup),
+		},
+		RemoteMap: make(map[Source][]*RemoteShardGroup),
+	}
+
+	tmin := time.Unix(0, t.MinTimeNano())
+	tmax := time.Unix(0, t.MaxTimeNano())
+	if err := e.mapShards(a, sources, tmin, tmax, opt.InterruptCh); err != nil {
+		return nil, err
+	}
+	a.MinTime, a.MaxTime = tmin, tmax
+	return a, nil
+}
+
+func (e *RemoteShardMapper) mapShards(a *RemoteShardMapping, sources influxql.Sources, tmin, tmax time.Time, interrupt <-chan struct{}) error {
+	for _, s := range sources {
+		// Stop mapping if the query was killed or timed out in the meantime.
+		select {
+		case <-interrupt:
+			return query.ErrQueryInterrupted
+		default:
+		}
+
+		switch s := s.(type) {
+		case *influxql.Measurement:
+			source := Source{
+				Database:        s.Database,
+				RetentionPolicy: s.RetentionPolicy,
+			}
+			if _, ok := a.ShardMap[source]; ok {
+				continue
+			}
+
+			groups, err := e.MetaClient.ShardGroupsByTimeRange(s.Database, s.RetentionPolicy, tmin, tmax)
+			if err != nil {
+				return err
+			}
+
+			var localIDs []uint64
+			remote := make(map[uint64]*RemoteShardGroup)
+			var remoteGroups []*RemoteShardGroup
+			for _, g := range groups {
+				for _, si := range g.Shards {
+					if e.TSDBStore.Shard(si.ID) != nil || e.Dialer == nil || len(si.Owners) == 0 {
+						localIDs = append(localIDs, si.ID)
+						continue
+					}
+
+					nodeID := si.Owners[0].NodeID
+					rg := remote[nodeID]
+					if rg == nil {
+						rg = &RemoteShardGroup{NodeID: nodeID, Dialer: e.Dialer}
+						remote[nodeID] = rg
+						remoteGroups = append(remoteGroups, rg)
+					}
+					rg.ShardIDs = append(rg.ShardIDs, si.ID)
+				}
+			}
+
+			if len(localIDs) > 0 {
+				a.ShardMap[source] = e.TSDBStore.ShardGroup(localIDs)
+			} else {
+				a.ShardMap[source] = nil
+			}
+			if len(remoteGroups) > 0 {
+				a.RemoteMap[source] = remoteGroups
+			}
+		case *influxql.SubQuery:
+			if err := e.mapShards(a, s.Statement.Sources, tmin, tmax, interrupt); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// RemoteShardMapping combines the shards of the local store with the shards
+// read from other data nodes.
+type RemoteShardMapping struct {
+	LocalShardMapping
+
+	// RemoteMap holds the shards of each source that are read from other
+	// data nodes, grouped by node.
+	RemoteMap map[Source][]*RemoteShardGroup
+}
+
+func (a *RemoteShardMapping) FieldDimensions(m *influxql.Measurement) (fields map[string]influxql.DataType, dimensions map[string]struct{}, err error) {
+	fields, dimensions, err = a.LocalShardMapping.FieldDimensions(m)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, rg := range a.remoteGroups(m) {
+		f, d, err := rg.FieldDimensions(m)
+		if err != nil {
+			return nil, nil, err
+		}
+		if fields == nil {
+			fields = make(map[string]influxql.DataType)
+			dimensions = make(map[string]struct{})
+		}
+		for k, typ := range f {
+			if fields[k].LessThan(typ) {
+				fields[k] = typ
+			}
+		}
+		for k := range d {
+			dimensions[k] = struct{}{}
+		}
+	}
+	return fields, dimensions, nil
+}
+
+func (a *RemoteShardMapping) MapType(m *influxql.Measurement, field string) influxql.DataType {
+	typ := a.LocalShardMapping.MapType(m, field)
+	for _, rg := range a.remoteGroups(m) {
+		// A node that cannot be reached does not know the type. The error is
+		// returned once the iterators are created.
+		t, err := rg.MapType(m, field)
+		if err == nil && typ.LessThan(t) {
+			typ = t
+		}
+	}
+	return typ
+}
+
+func (a *RemoteShardMapping) CreateIterator(ctx context.Context, m *influxql.Measurement, opt query.IteratorOptions) (query.Iterator, error) {
+	remote := a.remoteGroups(m)
+	if len(remote) == 0 {
+		return a.LocalShardMapping.CreateIterator(ctx, m, opt)
+	}
+
+	// Override the time constraints if they don't match each other.
+	if !a.MinTime.IsZero() && opt.StartTime < a.MinTime.UnixNano() {
+		opt.StartTime = a.MinTime.UnixNano()
+	}
+	if !a.MaxTime.IsZero() && opt.EndTime > a.MaxTime.UnixNano() {
+		opt.EndTime = a.MaxTime.UnixNano()
+	}
+
+	inputs := make([]query.Iterator, 0, len(remote)+1)
+	if itr, err := a.LocalShardMapping.CreateIterator(ctx, m, opt); err != nil {
+		return nil, err
+	} else if itr != nil {
+		inputs = append(inputs, itr)
+	}
+
+	for _, rg := range remote {
+		itr, err := rg.CreateIterator(ctx, m, opt)
+		if err != nil {
+			query.Iterators(inputs).Close()
+			return nil, err
+		} else if itr != nil {
+			inputs = append(inputs, itr)
+		}
+	}
+
+	if len(inputs) == 0 {
+		return nil, nil
+	}
+	return query.Iterators(inputs).Merge(opt)
+}
+
+func (a *RemoteShardMapping) IteratorCost(m *influxql.Measurement, opt query.IteratorOptions) (query.IteratorCost, error) {
+	costs, err := a.LocalShardMapping.IteratorCost(m, opt)
+	if err != nil {
+		return query.IteratorCost{}, err
+	}
+
+	if !a.MinTime.IsZero() && opt.StartTime < a.MinTime.UnixNano() {
+		opt.StartTime = a.MinTime.UnixNano()
+	}
+	if !a.MaxTime.IsZero() && opt.EndTime > a.MaxTime.UnixNano() {
+		opt.EndTime = a.MaxTime.UnixNano()
+	}
+
+	for _, rg := range a.remoteGroups(m) {
+		cost, err := rg.IteratorCost(m, opt)
+		if err != nil {
+			return query.IteratorCost{}, err
+		}
+		costs = costs.Combine(cost)
+	}
+	return costs, nil
+}
+
+// Close clears out the list of mapped shards.
+func (a *RemoteShardMapping) Close() error {
+	a.RemoteMap = nil
+	return a.LocalShardMapping.Close()
+}
+
+func (a *RemoteShardMapping) remoteGroups(m *influxql.Measurement) []*RemoteShardGroup {
+	return a.RemoteMap[Source{
+		Database:        m.Database,
+		RetentionPolicy: m.RetentionPolicy,
+	}]
+}
+
+// RemoteShardGroup reads shards owned by another data node through the shard
+// service of that node. Each call opens a new connection.
+type RemoteShardGroup struct {
+	NodeID   uint64
+	ShardIDs []uint64
+	Dialer   NodeDialer
+}
+
+// FieldDimensions returns the fields and dimensions of m in the shards.
+func (g *RemoteShardGroup) FieldDimensions(m *influxql.Measurement) (map[string]influxql.DataType, map[string]struct{}, error) {
+	var resp shardResponse
+	if err := g.call(&shardRequest{Type: shardRequestFieldDimensions, Measurement: m}, &resp, nil); err != nil {
+		return nil, nil, err
+	}
+
+	dimensions := make(map[string]struct{}, len(resp.Dimensions))
+	for _, d := range resp.Dimensions {
+		dimensions[d] = struct{}{}
+	}
+	return resp.Fields, dimensions, nil
+}
+
+// MapType returns the type of field in the shards.
+func (g *RemoteShardGroup) MapType(m *influxql.Measurement, field string) (influxql.DataType, error) {
+	var resp shardResponse
+	if err := g.call(&shardRequest{Type: shardRequestMapType, Measurement: m, Field: field}, &resp, nil); err != nil {
+		return influxql.Unknown, err
+	}
+	return resp.Type, nil
+}
+
+// CreateIterator creates an iterator that streams the points of m from the
+// shards. It returns nil if the shards hold no points for m.
+func (g *RemoteShardGroup) CreateIterator(ctx context.Context, m *influxql.Measurement, opt query.IteratorOptions) (query.Iterator, error) {
+	var itr query.Iterator
+	if err := g.call(&shardRequest{Type: shardRequestCreateIterator, Measurement: m, Options: &opt}, nil, func(resp *shardResponse, conn net.Conn, r *bufio.Reader) {
+		if resp.Type == influxql.Unknown {
+			return
+		}
+		itr = query.NewReaderIterator(ctx, &remoteIteratorReader{Reader: r, Closer: conn}, resp.Type, query.IteratorStats{})
+	}); err != nil {
+		return nil, err
+	}
+	return itr, nil
+}
+
+// IteratorCost returns the cost of reading m from the shards.
+func (g *RemoteShardGroup) IteratorCost(m *influxql.Measurement, opt query.IteratorOptions) (query.IteratorCost, error) {
+	var resp shardResponse
+	if err := g.call(&shardRequest{Type: shardRequestIteratorCost, Measurement: m, Options: &opt}, &resp, nil); err != nil {
+		return query.IteratorCost{}, err
+	}
+	return resp.Cost, nil
+}
+
+// call sends req to the node and reads its response. If stream is set, it is
+// given the connection, which it owns from then on, instead of closing it.
+func (g *RemoteShardGroup) call(req *shardRequest, resp *shardResponse, stream func(resp *shardResponse, conn net.Conn, r *bufio.Reader)) error {
+	conn, err := g.Dialer.DialNode(g.NodeID)
+	if err != nil {
+		return fmt.Errorf("dial data node %d: %s", g.NodeID, err)
+	}
+
+	req.ShardIDs = g.ShardIDs
+	if resp == nil {
+		resp = &shardResponse{}
+	}
+
+	r := bufio.NewReader(conn)
+	if err := func() error {
+		if err := writeShardRequest(conn, req); err != nil {
+			return err
+		} else if err := readShardMessage(r, resp); err != nil {
+			return err
+		} else if resp.Err != "" {
+			return errors.New(resp.Err)
+		}
+		return nil
+	}(); err != nil {
+		conn.Close()
+		return fmt.Errorf("data node %d: %s", g.NodeID, err)
+	}
+
+	if stream == nil {
+		return conn.Close()
+	}
+	stream(resp, conn, r)
+	if resp.Type == influxql.Unknown {
+		conn.Close()
+	}
+	return nil
+}
+
+// remoteIteratorReader reads the points of a remote iterator and closes the
+// connection when the iterator is closed.
+type remoteIteratorReader struct {
+	*bufio.Reader
+	io.Closer
+}
+
+// shardRequest is a request to the shard service of a data node.
+type shardRequest struct {
+	Type        byte
+	ShardIDs    []uint64
+	Measurement *influxql.Measurement
+	Field       string
+	Options     *query.IteratorOptions
+}
+
+// shardRequestBody is the encoded form of a shardRequest that follows its
+// type on the connection.
+type shardRequestBody struct {
+	ShardIDs        []uint64
+	Database        string
+	RetentionPolicy string
+	Name            string `json:",omitempty"`
+	Regex           string `json:",omitempty"`
+	SystemIterator  string `json:",omitempty"`
+	Field           string `json:",omitempty"`
+	Options         []byte `json:",omitempty"`
+}
+
+// shardResponse is the response of the shard service. The points of an
+// iterator follow the response of a CreateIterator request.
+type shardResponse struct {
+	Err        string                       `json:",omitempty"`
+	Fields     map[string]influxql.DataType `json:",omitempty"`
+	Dimensions []string                     `json:",omitempty"`
+	Type       influxql.DataType            `json:",omitempty"`
+	Cost       query.IteratorCost
+}
+
+func writeShardRequest(w io.Writer, req *shardRequest) error {
+	body := shardRequestBody{
+		ShardIDs: req.ShardIDs,
+		Field:    req.Field,
+	}
+	if m := req.Measurement; m != nil {
+		body.Database = m.Database
+		body.RetentionPolicy = m.RetentionPolicy
+		body.Name = m.Name
+		body.SystemIterator = m.SystemIterator
+		if m.Regex != nil {
+			body.Regex = m.Regex.Val.String()
+		}
+	}
+	if req.Options != nil {
+		buf, err := req.Options.MarshalBinary()
+		if err != nil {
+			return err
+		}
+		body.Options = buf
+	}
+
+	if _, err := w.Write([]byte{req.Type}); err != nil {
+		return err
+	}
+	return writeShardMessage(w, &body)
+}
+
+func readShardRequest(r io.Reader) (*shardRequest, error) {
+	var typ [1]byte
+	if _, err := io.ReadFull(r, typ[:]); err != nil {
+		return nil, err
+	}
+
+	var body shardRequestBody
+	if err := readShardMessage(r, &body); err != nil {
+		return nil, err
+	}
+
+	req := &shardRequest{
+		Type:     typ[0],
+		ShardIDs: body.ShardIDs,
+		Field:    body.Field,
+		Measurement: &influxql.Measurement{
+			Database:        body.Database,
+			RetentionPolicy: body.RetentionPolicy,
+			Name:            body.Name,
+			SystemIterator:  body.SystemIterator,
+		},
+	}
+	if body.Regex != "" {
+		re, err := regexp.Compile(body.Regex)
+		if err != nil {
+			return nil, err
+		}
+		req.Measurement.Regex = &influxql.RegexLiteral{Val: re}
+	}
+	if body.Options != nil {
+		req.Options = &query.IteratorOptions{}
+		if err := req.Options.UnmarshalBinary(body.Options); err != nil {
+			return nil, err
+		}
+	}
+	return req, nil
+}
+
+// writeShardMessage writes v as JSON prefixed by its length.
+func writeShardMessage(w io.Writer, v interface{}) error {
+	buf, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if err := binary.Write(w, binary.BigEndian, uint32(len(buf))); err != nil {
+		return err
+	}
+	_, err = w.Write(buf)
+	return err
+}
+
+// readShardMessage reads a message written by writeShardMessage into v.
+func readShardMessage(r io.Reader, v interface{}) error {
+	var sz uint32
+	if err := binary.Read(r, binary.BigEndian, &sz); err != nil {
+		return err
+	} else if sz > maxShardMessageSize {
+		return fmt.Errorf("shard message too large: %d bytes", sz)
+	}
+
+	buf := make([]byte, sz)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return err
+	}
+	return json.Unmarshal(buf, v)
+}
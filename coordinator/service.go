@@ -0,0 +1,211 @@
+package coordinator
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/influxdata/influxdb/query"
+	"github.com/influxdata/influxdb/tsdb"
+	"github.com/influxdata/influxql"
+	"github.com/uber-go/zap"
+)
+
+// MuxHeader is the header byte used for the TCP muxer.
+const MuxHeader = 2
+
+// errMissingIteratorOptions is returned for a request that needs iterator
+// options but has none.
+var errMissingIteratorOptions = errors.New("missing iterator options")
+
+// Service serves the shards of the local store to the RemoteShardMappers of
+// other data nodes.
+type Service struct {
+	wg      sync.WaitGroup
+	closing chan struct{}
+
+	TSDBStore interface {
+		ShardGroup(ids []uint64) tsdb.ShardGroup
+	}
+
+	Listener net.Listener
+	Logger   zap.Logger
+}
+
+// NewService returns a new instance of Service.
+func NewService() *Service {
+	return &Service{
+		closing: make(chan struct{}),
+		Logger:  zap.New(zap.NullEncoder()),
+	}
+}
+
+// Open starts the service.
+func (s *Service) Open() error {
+	s.Logger.Info("Starting shard query service")
+
+	s.wg.Add(1)
+	go s.serve()
+	return nil
+}
+
+// Close implements the Service interface.
+func (s *Service) Close() error {
+	if s.closed() {
+		return nil
+	}
+	close(s.closing)
+
+	if s.Listener != nil {
+		s.Listener.Close()
+	}
+	s.wg.Wait()
+	return nil
+}
+
+// WithLogger sets the logger on the service.
+func (s *Service) WithLogger(log zap.Logger) {
+	s.Logger = log.With(zap.String("service", "shard-query"))
+}
+
+// serve serves shard requests from the listener.
+func (s *Service) serve() {
+	defer s.wg.Done()
+
+	for {
+		// Wait for next connection.
+		conn, err := s.Listener.Accept()
+		if err != nil {
+			if s.closed() || strings.Contains(err.Error(), "connection closed") {
+				s.Logger.Info("shard query listener closed")
+				return
+			}
+			s.Logger.Info(fmt.Sprint("error accepting shard query request: ", err.Error()))
+			continue
+		}
+
+		// Handle connection in separate goroutine.
+		s.wg.Add(1)
+		go func(conn net.Conn) {
+			defer s.wg.Done()
+			defer conn.Close()
+			if err := s.handleConn(conn); err != nil {
+				s.Logger.Info(err.Error())
+			}
+		}(conn)
+	}
+}
+
+// closed returns true if the service is being closed.
+func (s *Service) closed() bool {
+	select {
+	case <-s.closing:
+		return true
+	default:
+		return false
+	}
+}
+
+// handleConn processes a single request on conn. This is run in a separate
+// goroutine.
+func (s *Service) handleConn(conn net.Conn) error {
+	req, err := readShardRequest(bufio.NewReader(conn))
+	if err != nil {
+		return fmt.Errorf("read shard request: %s", err)
+	}
+
+	m := req.Measurement
+	a := &LocalShardMapping{
+		ShardMap: map[Source]tsdb.ShardGroup{
+			{Database: m.Database, RetentionPolicy: m.RetentionPolicy}: s.TSDBStore.ShardGroup(req.ShardIDs),
+		},
+	}
+	defer a.Close()
+
+	var resp shardResponse
+	switch req.Type {
+	case shardRequestFieldDimensions:
+		fields, dimensions, err := a.FieldDimensions(m)
+		if err != nil {
+			return writeShardError(conn, err)
+		}
+		resp.Fields = fields
+		for d := range dimensions {
+			resp.Dimensions = append(resp.Dimensions, d)
+		}
+		sort.Strings(resp.Dimensions)
+	case shardRequestMapType:
+		resp.Type = a.MapType(m, req.Field)
+	case shardRequestIteratorCost:
+		if req.Options == nil {
+			return writeShardError(conn, errMissingIteratorOptions)
+		}
+		cost, err := a.IteratorCost(m, *req.Options)
+		if err != nil {
+			return writeShardError(conn, err)
+		}
+		resp.Cost = cost
+	case shardRequestCreateIterator:
+		if req.Options == nil {
+			return writeShardError(conn, errMissingIteratorOptions)
+		}
+		return s.createIterator(conn, a, req)
+	default:
+		return writeShardError(conn, fmt.Errorf("shard request type unknown: %v", req.Type))
+	}
+	return writeShardMessage(conn, &resp)
+}
+
+// createIterator writes the response to a CreateIterator request followed
+// by the points of the iterator.
+func (s *Service) createIterator(conn net.Conn, a *LocalShardMapping, req *shardRequest) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	itr, err := a.CreateIterator(ctx, req.Measurement, *req.Options)
+	if err != nil {
+		return writeShardError(conn, err)
+	} else if itr == nil {
+		return writeShardMessage(conn, &shardResponse{})
+	}
+	defer itr.Close()
+
+	resp := shardResponse{Type: iteratorType(itr)}
+	if err := writeShardMessage(conn, &resp); err != nil {
+		return err
+	}
+
+	w := bufio.NewWriter(conn)
+	if err := query.NewIteratorEncoder(w).EncodeIterator(itr); err != nil {
+		return err
+	}
+	return w.Flush()
+}
+
+// iteratorType returns the data type of the points of itr.
+func iteratorType(itr query.Iterator) influxql.DataType {
+	switch itr.(type) {
+	case query.FloatIterator:
+		return influxql.Float
+	case query.IntegerIterator:
+		return influxql.Integer
+	case query.UnsignedIterator:
+		return influxql.Unsigned
+	case query.StringIterator:
+		return influxql.String
+	case query.BooleanIterator:
+		return influxql.Boolean
+	default:
+		return influxql.Unknown
+	}
+}
+
+// writeShardError writes err as the response to a request.
+func writeShardError(conn net.Conn, err error) error {
+	return writeShardMessage(conn, &shardResponse{Err: err.Error()})
+}
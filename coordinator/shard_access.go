@@ -0,0 +1,90 @@
+package coordinator
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/influxdata/influxdb/pkg/tracing"
+	"github.com/influxdata/influxdb/pkg/tracing/fields"
+)
+
+// shardAccess is the data a query read from a shard.
+type shardAccess struct {
+	id           uint64
+	iterators    int64
+	blocksRead   int64
+	bytesDecoded int64
+}
+
+// shardAccesses returns the shards read by the iterators of a trace, sorted
+// by id. The blocks and bytes are the sum of the counters that the engine
+// records per block type for each iterator it creates.
+func shardAccesses(root *tracing.TreeNode) []shardAccess {
+	if root == nil {
+		return nil
+	}
+
+	m := make(map[uint64]*shardAccess)
+	tracing.Walk(shardAccessVisitor(m), root)
+
+	a := make([]shardAccess, 0, len(m))
+	for _, sa := range m {
+		a = append(a, *sa)
+	}
+	sort.Slice(a, func(i, j int) bool { return a[i].id < a[j].id })
+	return a
+}
+
+type shardAccessVisitor map[uint64]*shardAccess
+
+func (v shardAccessVisitor) Visit(n *tracing.TreeNode) tracing.Visitor {
+	var id uint64
+	var ok bool
+	for _, l := range n.Raw.Labels {
+		if l.Key == "shard_id" {
+			var err error
+			id, err = strconv.ParseUint(l.Value, 10, 64)
+			ok = err == nil
+			break
+		}
+	}
+	if !ok {
+		return v
+	}
+
+	sa := v[id]
+	if sa == nil {
+		sa = &shardAccess{id: id}
+		v[id] = sa
+	}
+	sa.iterators++
+	for _, f := range n.Raw.Fields {
+		n, ok := f.Value().(int64)
+		if !ok {
+			continue
+		}
+		switch {
+		case strings.HasSuffix(f.Key(), "_blocks_decoded"):
+			sa.blocksRead += n
+		case strings.HasSuffix(f.Key(), "_blocks_size_bytes"):
+			sa.bytesDecoded += n
+		}
+	}
+	return v
+}
+
+// addShardAccessSpans adds a span under span for each shard that the
+// iterators of its trace read from.
+func addShardAccessSpans(t *tracing.Trace, span *tracing.Span) {
+	for _, sa := range shardAccesses(t.Tree()) {
+		s := span.StartSpan("shard_access")
+		s.SetLabels("shard_id", strconv.FormatUint(sa.id, 10))
+		s.SetFields(fields.New(
+			fields.Int64("iterators", sa.iterators),
+			fields.Int64("blocks_read", sa.blocksRead),
+			fields.Int64("bytes_decoded", sa.bytesDecoded),
+		))
+		s.Finish()
+	}
+}
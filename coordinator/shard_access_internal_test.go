@@ -0,0 +1,49 @@
+package coordinator
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/influxdata/influxdb/pkg/tracing"
+	"github.com/influxdata/influxdb/pkg/tracing/fields"
+)
+
+func TestAddShardAccessSpans(t *testing.T) {
+	tr, root := tracing.NewTrace("select")
+
+	// Two iterators of shard 2 and one of shard 1.
+	for _, itr := range []struct {
+		shard  string
+		blocks int64
+		bytes  int64
+	}{
+		{"2", 3, 300},
+		{"1", 1, 100},
+		{"2", 2, 200},
+	} {
+		s := root.StartSpan("create_iterator")
+		s.SetLabels("shard_id", itr.shard, "measurement", "cpu")
+		s.SetFields(fields.New(
+			fields.Int64("float_blocks_decoded", itr.blocks),
+			fields.Int64("float_blocks_size_bytes", itr.bytes),
+			fields.Int64("integer_blocks_decoded", 1),
+			fields.Int64("integer_blocks_size_bytes", 10),
+		))
+		s.Finish()
+	}
+	root.Finish()
+
+	if got, exp := shardAccesses(tr.Tree()), []shardAccess{
+		{id: 1, iterators: 1, blocksRead: 2, bytesDecoded: 110},
+		{id: 2, iterators: 2, blocksRead: 7, bytesDecoded: 520},
+	}; !reflect.DeepEqual(got, exp) {
+		t.Fatalf("unexpected shard accesses: %+v", got)
+	}
+
+	addShardAccessSpans(tr, root)
+	out := tr.Tree().String()
+	if !strings.Contains(out, "shard_access") || !strings.Contains(out, "bytes_decoded: 520") {
+		t.Fatalf("unexpected tree:\n%s", out)
+	}
+}
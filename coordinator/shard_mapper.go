@@ -37,15 +37,22 @@ func (e *LocalShardMapper) MapShards(sources influxql.Sources, t influxql.TimeRa
 
 	tmin := time.Unix(0, t.MinTimeNano())
 	tmax := time.Unix(0, t.MaxTimeNano())
-	if err := e.mapShards(a, sources, tmin, tmax); err != nil {
+	if err := e.mapShards(a, sources, tmin, tmax, opt.InterruptCh); err != nil {
 		return nil, err
 	}
 	a.MinTime, a.MaxTime = tmin, tmax
 	return a, nil
 }
 
-func (e *LocalShardMapper) mapShards(a *LocalShardMapping, sources influxql.Sources, tmin, tmax time.Time) error {
+func (e *LocalShardMapper) mapShards(a *LocalShardMapping, sources influxql.Sources, tmin, tmax time.Time, interrupt <-chan struct{}) error {
 	for _, s := range sources {
+		// Stop mapping if the query was killed or timed out in the meantime.
+		select {
+		case <-interrupt:
+			return query.ErrQueryInterrupted
+		default:
+		}
+
 		switch s := s.(type) {
 		case *influxql.Measurement:
 			source := Source{
@@ -75,7 +82,7 @@ func (e *LocalShardMapper) mapShards(a *LocalShardMapping, sources influxql.Sour
 				a.ShardMap[source] = e.TSDBStore.ShardGroup(shardIDs)
 			}
 		case *influxql.SubQuery:
-			if err := e.mapShards(a, s.Statement.Sources, tmin, tmax); err != nil {
+			if err := e.mapShards(a, s.Statement.Sources, tmin, tmax, interrupt); err != nil {
 				return err
 			}
 		}
@@ -2,6 +2,7 @@ package coordinator_test
 
 import (
 	"context"
+	"net"
 	"reflect"
 	"testing"
 	"time"
@@ -103,3 +104,158 @@ func TestLocalShardMapper(t *testing.T) {
 		t.Fatalf("unexpected error: %s", err)
 	}
 }
+
+func TestLocalShardMapper_Interrupted(t *testing.T) {
+	var metaClient MetaClient
+	metaClient.ShardGroupsByTimeRangeFn = func(database, policy string, min, max time.Time) ([]meta.ShardGroupInfo, error) {
+		t.Error("unexpected shard mapping of an interrupted query")
+		return nil, nil
+	}
+
+	shardMapper := &coordinator.LocalShardMapper{
+		MetaClient: &metaClient,
+		TSDBStore:  &internal.TSDBStoreMock{},
+	}
+
+	interrupt := make(chan struct{})
+	close(interrupt)
+
+	measurement := &influxql.Measurement{Database: "db0", RetentionPolicy: "rp0", Name: "cpu"}
+	if _, err := shardMapper.MapShards([]influxql.Source{measurement}, influxql.TimeRange{}, query.SelectOptions{
+		InterruptCh: interrupt,
+	}); err != query.ErrQueryInterrupted {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestRemoteShardMapper(t *testing.T) {
+	var metaClient MetaClient
+	metaClient.ShardGroupsByTimeRangeFn = func(database, policy string, min, max time.Time) ([]meta.ShardGroupInfo, error) {
+		return []meta.ShardGroupInfo{
+			{ID: 1, Shards: []meta.ShardInfo{
+				{ID: 1, Owners: []meta.ShardOwner{{NodeID: 1}}},
+				{ID: 2, Owners: []meta.ShardOwner{{NodeID: 2}}},
+			}},
+		}, nil
+	}
+
+	// Shard 1 is in the local store and shard 2 is owned by the service.
+	newShardGroup := func(value float64) tsdb.ShardGroup {
+		var sh MockShard
+		sh.FieldDimensionsFn = func(measurements []string) (map[string]influxql.DataType, map[string]struct{}, error) {
+			if value == 1 {
+				return map[string]influxql.DataType{"value": influxql.Float}, map[string]struct{}{"host": {}}, nil
+			}
+			return map[string]influxql.DataType{"value": influxql.Float}, map[string]struct{}{"region": {}}, nil
+		}
+		sh.CreateIteratorFn = func(ctx context.Context, m *influxql.Measurement, opt query.IteratorOptions) (query.Iterator, error) {
+			if m.Name != "cpu" {
+				t.Errorf("unexpected measurement: %s", m.Name)
+			}
+			return &FloatIterator{Points: []query.FloatPoint{
+				{Name: "cpu", Time: int64(value), Value: value},
+			}}, nil
+		}
+		return &sh
+	}
+
+	tsdbStore := &internal.TSDBStoreMock{}
+	tsdbStore.ShardFn = func(id uint64) *tsdb.Shard {
+		if id == 1 {
+			return &tsdb.Shard{}
+		}
+		return nil
+	}
+	tsdbStore.ShardGroupFn = func(ids []uint64) tsdb.ShardGroup {
+		if !reflect.DeepEqual(ids, []uint64{1}) {
+			t.Errorf("unexpected local shard ids: %#v", ids)
+		}
+		return newShardGroup(1)
+	}
+
+	remoteStore := &internal.TSDBStoreMock{}
+	remoteStore.ShardGroupFn = func(ids []uint64) tsdb.ShardGroup {
+		if !reflect.DeepEqual(ids, []uint64{2}) {
+			t.Errorf("unexpected remote shard ids: %#v", ids)
+		}
+		return newShardGroup(2)
+	}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := coordinator.NewService()
+	s.TSDBStore = remoteStore
+	s.Listener = ln
+	if err := s.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	shardMapper := &coordinator.RemoteShardMapper{
+		MetaClient: &metaClient,
+		TSDBStore:  tsdbStore,
+		Dialer: NodeDialerFunc(func(nodeID uint64) (net.Conn, error) {
+			if nodeID != 2 {
+				t.Errorf("unexpected node id: %d", nodeID)
+			}
+			return net.Dial("tcp", ln.Addr().String())
+		}),
+	}
+
+	measurement := &influxql.Measurement{
+		Database:        "db0",
+		RetentionPolicy: "rp0",
+		Name:            "cpu",
+	}
+	ic, err := shardMapper.MapShards([]influxql.Source{measurement}, influxql.TimeRange{}, query.SelectOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer ic.Close()
+
+	fields, dimensions, err := ic.FieldDimensions(measurement)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	} else if exp := map[string]influxql.DataType{"value": influxql.Float}; !reflect.DeepEqual(fields, exp) {
+		t.Errorf("unexpected fields: %#v", fields)
+	} else if exp := map[string]struct{}{"host": {}, "region": {}}; !reflect.DeepEqual(dimensions, exp) {
+		t.Errorf("unexpected dimensions: %#v", dimensions)
+	}
+
+	if typ := ic.MapType(measurement, "value"); typ != influxql.Float {
+		t.Errorf("unexpected type: %s", typ)
+	}
+
+	itr, err := ic.CreateIterator(context.Background(), measurement, query.IteratorOptions{
+		StartTime: influxql.MinTime,
+		EndTime:   influxql.MaxTime,
+		Ascending: true,
+		Ordered:   true,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer itr.Close()
+
+	var values []float64
+	fitr := itr.(query.FloatIterator)
+	for {
+		p, err := fitr.Next()
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		} else if p == nil {
+			break
+		}
+		values = append(values, p.Value)
+	}
+	if !reflect.DeepEqual(values, []float64{1, 2}) {
+		t.Errorf("unexpected values: %v", values)
+	}
+}
+
+// NodeDialerFunc is a coordinator.NodeDialer that calls itself.
+type NodeDialerFunc func(nodeID uint64) (net.Conn, error)
+
+func (fn NodeDialerFunc) DialNode(nodeID uint64) (net.Conn, error) { return fn(nodeID) }
@@ -3,6 +3,7 @@ package coordinator
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -14,6 +15,7 @@ import (
 	"github.com/influxdata/influxdb"
 	"github.com/influxdata/influxdb/models"
 	"github.com/influxdata/influxdb/monitor"
+	"github.com/influxdata/influxdb/pkg/limiter"
 	"github.com/influxdata/influxdb/pkg/tracing"
 	"github.com/influxdata/influxdb/pkg/tracing/fields"
 	"github.com/influxdata/influxdb/query"
@@ -53,10 +55,23 @@ type StatementExecutor struct {
 	MaxSelectPointN   int
 	MaxSelectSeriesN  int
 	MaxSelectBucketsN int
+
+	// Memory an iterator of a SELECT uses before it spills to SpillDir.
+	MaxSelectMemory int64
+	SpillDir        string
+
+	// BackgroundRate limits the bytes per second that batch queries, such as
+	// continuous queries, write with SELECT INTO. It is shared with other
+	// background jobs like compactions.
+	BackgroundRate *limiter.Rate
 }
 
 // ExecuteStatement executes the given statement with the given execution context.
 func (e *StatementExecutor) ExecuteStatement(stmt influxql.Statement, ctx query.ExecutionContext) error {
+	if ctx.DryRun {
+		return e.executeDryRun(stmt, &ctx)
+	}
+
 	// Select statements are handled separately so that they can be streamed.
 	if stmt, ok := stmt.(*influxql.SelectStatement); ok {
 		return e.executeSelectStatement(context.Background(), stmt, &ctx)
@@ -97,7 +112,7 @@ func (e *StatementExecutor) ExecuteStatement(stmt influxql.Statement, ctx query.
 		}
 		err = e.executeCreateUserStatement(stmt)
 	case *influxql.DeleteSeriesStatement:
-		err = e.executeDeleteSeriesStatement(stmt, ctx.Database)
+		err = e.executeDeleteSeriesStatement(stmt, &ctx)
 	case *influxql.DropContinuousQueryStatement:
 		if ctx.ReadOnly {
 			messages = append(messages, query.ReadOnlyWarning(stmt.String()))
@@ -117,7 +132,7 @@ func (e *StatementExecutor) ExecuteStatement(stmt influxql.Statement, ctx query.
 		if ctx.ReadOnly {
 			messages = append(messages, query.ReadOnlyWarning(stmt.String()))
 		}
-		err = e.executeDropSeriesStatement(stmt, ctx.Database)
+		err = e.executeDropSeriesStatement(stmt, &ctx)
 	case *influxql.DropRetentionPolicyStatement:
 		if ctx.ReadOnly {
 			messages = append(messages, query.ReadOnlyWarning(stmt.String()))
@@ -217,6 +232,47 @@ func (e *StatementExecutor) ExecuteStatement(stmt influxql.Statement, ctx query.
 	})
 }
 
+// executeDryRun reports the shards, series and values that a DELETE, DROP
+// MEASUREMENT or DROP SERIES statement would remove without removing them.
+func (e *StatementExecutor) executeDryRun(stmt influxql.Statement, ctx *query.ExecutionContext) error {
+	var stats tsdb.DeleteStats
+	var err error
+	switch stmt := stmt.(type) {
+	case *influxql.DeleteSeriesStatement:
+		if dbi := e.MetaClient.Database(ctx.Database); dbi == nil {
+			return query.ErrDatabaseNotFound(ctx.Database)
+		}
+		condition := influxql.Reduce(stmt.Condition, &influxql.NowValuer{Now: time.Now().UTC()})
+		stats, err = e.TSDBStore.DeleteSeriesDryRun(ctx.Database, stmt.Sources, condition)
+	case *influxql.DropMeasurementStatement:
+		if dbi := e.MetaClient.Database(ctx.Database); dbi == nil {
+			return query.ErrDatabaseNotFound(ctx.Database)
+		}
+		stats, err = e.TSDBStore.DeleteMeasurementDryRun(ctx.Database, stmt.Name)
+	case *influxql.DropSeriesStatement:
+		if dbi := e.MetaClient.Database(ctx.Database); dbi == nil {
+			return query.ErrDatabaseNotFound(ctx.Database)
+		} else if influxql.HasTimeExpr(stmt.Condition) {
+			return errors.New("DROP SERIES doesn't support time in WHERE clause")
+		}
+		stats, err = e.TSDBStore.DeleteSeriesDryRun(ctx.Database, stmt.Sources, stmt.Condition)
+	default:
+		return errors.New("dry run is only supported for DELETE, DROP MEASUREMENT and DROP SERIES statements")
+	}
+	if err != nil {
+		return err
+	}
+
+	return ctx.Send(&query.Result{
+		StatementID: ctx.StatementID,
+		Series: models.Rows{{
+			Name:    "dry run",
+			Columns: []string{"shards", "series", "values"},
+			Values:  [][]interface{}{{stats.Shards, stats.Series, stats.Values}},
+		}},
+	})
+}
+
 func (e *StatementExecutor) executeAlterRetentionPolicyStatement(stmt *influxql.AlterRetentionPolicyStatement) error {
 	rpu := &meta.RetentionPolicyUpdate{
 		Duration:           stmt.Duration,
@@ -322,16 +378,16 @@ func (e *StatementExecutor) executeCreateUserStatement(q *influxql.CreateUserSta
 	return err
 }
 
-func (e *StatementExecutor) executeDeleteSeriesStatement(stmt *influxql.DeleteSeriesStatement, database string) error {
-	if dbi := e.MetaClient.Database(database); dbi == nil {
-		return query.ErrDatabaseNotFound(database)
+func (e *StatementExecutor) executeDeleteSeriesStatement(stmt *influxql.DeleteSeriesStatement, ctx *query.ExecutionContext) error {
+	if dbi := e.MetaClient.Database(ctx.Database); dbi == nil {
+		return query.ErrDatabaseNotFound(ctx.Database)
 	}
 
 	// Convert "now()" to current time.
 	stmt.Condition = influxql.Reduce(stmt.Condition, &influxql.NowValuer{Now: time.Now().UTC()})
 
 	// Locally delete the series.
-	return e.TSDBStore.DeleteSeries(database, stmt.Sources, stmt.Condition)
+	return e.TSDBStore.DeleteSeriesWithProgress(ctx.Database, stmt.Sources, stmt.Condition, deleteSeriesProgress(ctx), ctx.InterruptCh)
 }
 
 func (e *StatementExecutor) executeDropContinuousQueryStatement(q *influxql.DropContinuousQueryStatement) error {
@@ -342,12 +398,17 @@ func (e *StatementExecutor) executeDropContinuousQueryStatement(q *influxql.Drop
 // It does not return an error if the database was not found on any of
 // the nodes, or in the Meta store.
 func (e *StatementExecutor) executeDropDatabaseStatement(stmt *influxql.DropDatabaseStatement) error {
-	if e.MetaClient.Database(stmt.Name) == nil {
+	dbi := e.MetaClient.Database(stmt.Name)
+	if dbi == nil {
 		return nil
 	}
 
-	// Locally delete the datababse.
-	if err := e.TSDBStore.DeleteDatabase(stmt.Name); err != nil {
+	// Locally delete the datababse, keeping its shards in the trash if it
+	// is enabled so the database can be undropped.
+	info, err := json.Marshal(dbi)
+	if err != nil {
+		return err
+	} else if err := e.TSDBStore.TrashDatabase(stmt.Name, info); err != nil {
 		return err
 	}
 
@@ -364,9 +425,9 @@ func (e *StatementExecutor) executeDropMeasurementStatement(stmt *influxql.DropM
 	return e.TSDBStore.DeleteMeasurement(database, stmt.Name)
 }
 
-func (e *StatementExecutor) executeDropSeriesStatement(stmt *influxql.DropSeriesStatement, database string) error {
-	if dbi := e.MetaClient.Database(database); dbi == nil {
-		return query.ErrDatabaseNotFound(database)
+func (e *StatementExecutor) executeDropSeriesStatement(stmt *influxql.DropSeriesStatement, ctx *query.ExecutionContext) error {
+	if dbi := e.MetaClient.Database(ctx.Database); dbi == nil {
+		return query.ErrDatabaseNotFound(ctx.Database)
 	}
 
 	// Check for time in WHERE clause (not supported).
@@ -375,7 +436,15 @@ func (e *StatementExecutor) executeDropSeriesStatement(stmt *influxql.DropSeries
 	}
 
 	// Locally drop the series.
-	return e.TSDBStore.DeleteSeries(database, stmt.Sources, stmt.Condition)
+	return e.TSDBStore.DeleteSeriesWithProgress(ctx.Database, stmt.Sources, stmt.Condition, deleteSeriesProgress(ctx), ctx.InterruptCh)
+}
+
+// deleteSeriesProgress returns a function that reports the progress of a
+// series delete in SHOW QUERIES.
+func deleteSeriesProgress(ctx *query.ExecutionContext) tsdb.DeleteSeriesProgressFunc {
+	return func(shardsDone, shardsTotal int, series int64) {
+		ctx.Query.SetProgress(fmt.Sprintf("deleted %d series, %d/%d shards done", series, shardsDone, shardsTotal))
+	}
 }
 
 func (e *StatementExecutor) executeDropShardStatement(stmt *influxql.DropShardStatement) error {
@@ -421,6 +490,9 @@ func (e *StatementExecutor) executeExplainStatement(q *influxql.ExplainStatement
 		MaxSeriesN:  e.MaxSelectSeriesN,
 		MaxBucketsN: e.MaxSelectBucketsN,
 		Authorizer:  ectx.Authorizer,
+
+		MaxMemory: e.MaxSelectMemory,
+		SpillDir:  e.SpillDir,
 	}
 
 	// Prepare the query for execution, but do not actually execute it.
@@ -509,6 +581,10 @@ CLEANUP:
 	)
 	span.Finish()
 
+	// Summarize the data read from each shard, which is otherwise spread
+	// over the iterators created for each measurement.
+	addShardAccessSpans(t, span)
+
 	row := &models.Row{
 		Columns: []string{"EXPLAIN ANALYZE"},
 	}
@@ -571,9 +647,20 @@ func (e *StatementExecutor) executeSelectStatement(ctx context.Context, stmt *in
 	var writeN int64
 	var emitted bool
 
+	// When statistics are requested, the most recent result is held back so
+	// the statistics can be attached to it once the statement is finished.
+	var pending *query.Result
+	var stats query.ResultStats
+	var lastPartial bool
+	var seriesValuesN int
+
 	var pointsWriter *BufferedPointsWriter
 	if stmt.Target != nil {
-		pointsWriter = NewBufferedPointsWriter(e.PointsWriter, stmt.Target.Measurement.Database, stmt.Target.Measurement.RetentionPolicy, 10000)
+		w := e.PointsWriter
+		if ectx.Priority == query.BatchPriority && e.BackgroundRate != nil {
+			w = &throttledPointsWriter{w: w, rate: e.BackgroundRate}
+		}
+		pointsWriter = NewBufferedPointsWriter(w, stmt.Target.Measurement.Database, stmt.Target.Measurement.RetentionPolicy, 10000)
 	}
 
 	for {
@@ -590,6 +677,24 @@ func (e *StatementExecutor) executeSelectStatement(ctx context.Context, stmt *in
 			break
 		}
 
+		if ectx.IncludeStats {
+			// A partial row is continued by the next row so only count
+			// the series once.
+			if !lastPartial {
+				stats.SeriesN++
+				seriesValuesN = 0
+			}
+			stats.ValuesN += len(row.Values)
+			seriesValuesN += len(row.Values)
+			lastPartial = partial
+
+			// The limits are applied by the iterators, so reaching one
+			// means more data may have been left out.
+			if (stmt.Limit > 0 && seriesValuesN >= stmt.Limit) || (stmt.SLimit > 0 && stats.SeriesN >= stmt.SLimit) {
+				stats.Truncated = true
+			}
+		}
+
 		// Write points back into system for INTO statements.
 		if stmt.Target != nil {
 			if err := e.writeInto(pointsWriter, stmt, row); err != nil {
@@ -605,6 +710,13 @@ func (e *StatementExecutor) executeSelectStatement(ctx context.Context, stmt *in
 			Partial:     partial,
 		}
 
+		// When statistics are requested, hold back the result.
+		if ectx.IncludeStats {
+			if result, pending = pending, result; result == nil {
+				continue
+			}
+		}
+
 		// Send results or exit if closing.
 		if err := ectx.Send(result); err != nil {
 			return err
@@ -613,6 +725,11 @@ func (e *StatementExecutor) executeSelectStatement(ctx context.Context, stmt *in
 		emitted = true
 	}
 
+	if ectx.IncludeStats {
+		itrStats := query.Iterators(itrs).Stats()
+		stats.ScannedSeriesN, stats.ScannedPointN = itrStats.SeriesN, itrStats.PointN
+	}
+
 	// Flush remaining points and emit write count if an INTO statement.
 	if stmt.Target != nil {
 		if err := pointsWriter.Flush(); err != nil {
@@ -624,7 +741,7 @@ func (e *StatementExecutor) executeSelectStatement(ctx context.Context, stmt *in
 			messages = append(messages, query.ReadOnlyWarning(stmt.String()))
 		}
 
-		return ectx.Send(&query.Result{
+		result := &query.Result{
 			StatementID: ectx.StatementID,
 			Messages:    messages,
 			Series: []*models.Row{{
@@ -632,15 +749,29 @@ func (e *StatementExecutor) executeSelectStatement(ctx context.Context, stmt *in
 				Columns: []string{"time", "written"},
 				Values:  [][]interface{}{{time.Unix(0, 0).UTC(), writeN}},
 			}},
-		})
+		}
+		if ectx.IncludeStats {
+			result.Stats = &stats
+		}
+		return ectx.Send(result)
+	}
+
+	// Send the held back result along with the statistics.
+	if pending != nil {
+		pending.Stats = &stats
+		return ectx.Send(pending)
 	}
 
 	// Always emit at least one result.
 	if !emitted {
-		return ectx.Send(&query.Result{
+		result := &query.Result{
 			StatementID: ectx.StatementID,
 			Series:      make([]*models.Row, 0),
-		})
+		}
+		if ectx.IncludeStats {
+			result.Stats = &stats
+		}
+		return ectx.Send(result)
 	}
 
 	return nil
@@ -653,6 +784,9 @@ func (e *StatementExecutor) createIterators(ctx context.Context, stmt *influxql.
 		MaxSeriesN:  e.MaxSelectSeriesN,
 		MaxBucketsN: e.MaxSelectBucketsN,
 		Authorizer:  ectx.Authorizer,
+
+		MaxMemory: e.MaxSelectMemory,
+		SpillDir:  e.SpillDir,
 	}
 
 	// Create a set of iterators from a selection.
@@ -807,9 +941,28 @@ func (e *StatementExecutor) executeShowRetentionPoliciesStatement(q *influxql.Sh
 		return nil, influxdb.ErrDatabaseNotFound(q.Database)
 	}
 
-	row := &models.Row{Columns: []string{"name", "duration", "shardGroupDuration", "replicaN", "default"}}
+	row := &models.Row{Columns: []string{"name", "duration", "shardGroupDuration", "replicaN", "default", "shardGroupN", "shardN", "diskBytes"}}
 	for _, rpi := range di.RetentionPolicies {
-		row.Values = append(row.Values, []interface{}{rpi.Name, rpi.Duration.String(), rpi.ShardGroupDuration.String(), rpi.ReplicaN, di.DefaultRetentionPolicy == rpi.Name})
+		// Count the shards of the policy and the bytes they use on disk.
+		// Shards associated with deleted shard groups are effectively deleted.
+		var shardGroupN, shardN int
+		var diskBytes int64
+		for _, sgi := range rpi.ShardGroups {
+			if sgi.Deleted() {
+				continue
+			}
+			shardGroupN++
+			for _, si := range sgi.Shards {
+				shardN++
+				size, err := e.TSDBStore.ShardDiskSize(si.ID)
+				if err != nil {
+					return nil, err
+				}
+				diskBytes += size
+			}
+		}
+
+		row.Values = append(row.Values, []interface{}{rpi.Name, rpi.Duration.String(), rpi.ShardGroupDuration.String(), rpi.ReplicaN, di.DefaultRetentionPolicy == rpi.Name, shardGroupN, shardN, diskBytes})
 	}
 	return []*models.Row{row}, nil
 }
@@ -819,7 +972,7 @@ func (e *StatementExecutor) executeShowShardsStatement(stmt *influxql.ShowShards
 
 	rows := []*models.Row{}
 	for _, di := range dis {
-		row := &models.Row{Columns: []string{"id", "database", "retention_policy", "shard_group", "start_time", "end_time", "expiry_time", "owners"}, Name: di.Name}
+		row := &models.Row{Columns: []string{"id", "database", "retention_policy", "shard_group", "start_time", "end_time", "expiry_time", "owners", "disk_bytes"}, Name: di.Name}
 		for _, rpi := range di.RetentionPolicies {
 			for _, sgi := range rpi.ShardGroups {
 				// Shards associated with deleted shard groups are effectively deleted.
@@ -834,6 +987,12 @@ func (e *StatementExecutor) executeShowShardsStatement(stmt *influxql.ShowShards
 						ownerIDs[i] = owner.NodeID
 					}
 
+					// Shards that are not stored on this node have no size.
+					size, err := e.TSDBStore.ShardDiskSize(si.ID)
+					if err != nil {
+						return nil, err
+					}
+
 					row.Values = append(row.Values, []interface{}{
 						si.ID,
 						di.Name,
@@ -843,6 +1002,7 @@ func (e *StatementExecutor) executeShowShardsStatement(stmt *influxql.ShowShards
 						sgi.EndTime.UTC().Format(time.RFC3339),
 						sgi.EndTime.Add(rpi.Duration).UTC().Format(time.RFC3339),
 						joinUint64(ownerIDs),
+						size,
 					})
 				}
 			}
@@ -937,17 +1097,21 @@ func (e *StatementExecutor) executeShowSubscriptionsStatement(stmt *influxql.Sho
 func (e *StatementExecutor) executeShowTagKeys(q *influxql.ShowTagKeysStatement, ctx *query.ExecutionContext) error {
 	if q.Database == "" {
 		return ErrDatabaseNameRequired
+	} else if err := authorizeSourcesDatabase(ctx.Authorizer, q.Database); err != nil {
+		return err
 	}
 
 	// Determine shard set based on database and time range.
-	// SHOW TAG KEYS returns all tag keys for the default retention policy.
+	// SHOW TAG KEYS returns all tag keys for the retention policy named in
+	// the FROM clause or, if there is none, the default retention policy.
 	di := e.MetaClient.Database(q.Database)
 	if di == nil {
 		return fmt.Errorf("database not found: %s", q.Database)
 	}
 
-	if di.DefaultRetentionPolicy == "" {
-		return fmt.Errorf("database %s does not have default retention policy", q.Database)
+	rp, err := sourcesRetentionPolicy(di, q.Sources)
+	if err != nil {
+		return err
 	}
 
 	// Determine appropriate time range. If one or fewer time boundaries provided
@@ -958,7 +1122,7 @@ func (e *StatementExecutor) executeShowTagKeys(q *influxql.ShowTagKeysStatement,
 		return err
 	}
 
-	sgis, err := e.MetaClient.ShardGroupsByTimeRange(di.Name, di.DefaultRetentionPolicy, timeRange.MinTime(), timeRange.MaxTime())
+	sgis, err := e.MetaClient.ShardGroupsByTimeRange(di.Name, rp, timeRange.MinTime(), timeRange.MaxTime())
 	if err != nil {
 		return err
 	}
@@ -978,7 +1142,10 @@ func (e *StatementExecutor) executeShowTagKeys(q *influxql.ShowTagKeysStatement,
 		})
 	}
 
+	// SLIMIT and SOFFSET limit the measurements, which are the series of
+	// the result, while LIMIT and OFFSET limit the keys of each measurement.
 	emitted := false
+	seriesN := 0
 	for _, m := range tagKeys {
 		keys := m.Keys
 
@@ -997,6 +1164,13 @@ func (e *StatementExecutor) executeShowTagKeys(q *influxql.ShowTagKeysStatement,
 			continue
 		}
 
+		seriesN++
+		if seriesN <= q.SOffset {
+			continue
+		} else if q.SLimit > 0 && seriesN > q.SOffset+q.SLimit {
+			break
+		}
+
 		row := &models.Row{
 			Name:    m.Measurement,
 			Columns: []string{"tagKey"},
@@ -1027,17 +1201,21 @@ func (e *StatementExecutor) executeShowTagKeys(q *influxql.ShowTagKeysStatement,
 func (e *StatementExecutor) executeShowTagValues(q *influxql.ShowTagValuesStatement, ctx *query.ExecutionContext) error {
 	if q.Database == "" {
 		return ErrDatabaseNameRequired
+	} else if err := authorizeSourcesDatabase(ctx.Authorizer, q.Database); err != nil {
+		return err
 	}
 
 	// Determine shard set based on database and time range.
-	// SHOW TAG VALUES returns all tag values for the default retention policy.
+	// SHOW TAG VALUES returns all tag values for the retention policy named in
+	// the FROM clause or, if there is none, the default retention policy.
 	di := e.MetaClient.Database(q.Database)
 	if di == nil {
 		return fmt.Errorf("database not found: %s", q.Database)
 	}
 
-	if di.DefaultRetentionPolicy == "" {
-		return fmt.Errorf("database %s does not have default retention policy", q.Database)
+	rp, err := sourcesRetentionPolicy(di, q.Sources)
+	if err != nil {
+		return err
 	}
 
 	// Determine appropriate time range. If one or fewer time boundaries provided
@@ -1048,7 +1226,7 @@ func (e *StatementExecutor) executeShowTagValues(q *influxql.ShowTagValuesStatem
 		return err
 	}
 
-	sgis, err := e.MetaClient.ShardGroupsByTimeRange(q.Database, di.DefaultRetentionPolicy, timeRange.MinTime(), timeRange.MaxTime())
+	sgis, err := e.MetaClient.ShardGroupsByTimeRange(q.Database, rp, timeRange.MinTime(), timeRange.MaxTime())
 	if err != nil {
 		return err
 	}
@@ -1117,6 +1295,39 @@ func (e *StatementExecutor) executeShowTagValues(q *influxql.ShowTagValuesStatem
 	return nil
 }
 
+// authorizeSourcesDatabase returns an error if auth may not read database.
+// A database named in the FROM clause of a SHOW statement replaces the one
+// the statement was authorized on, so it has to be checked again.
+func authorizeSourcesDatabase(auth query.Authorizer, database string) error {
+	if auth != nil && !auth.AuthorizeDatabase(influxql.ReadPrivilege, database) {
+		return influxdb.NewError(influxdb.ErrorCodeUnauthorized, fmt.Sprintf("not authorized to read database %s", database))
+	}
+	return nil
+}
+
+// sourcesRetentionPolicy returns the single retention policy referenced by
+// the sources of a SHOW statement. The default retention policy of the
+// database is used when no sources are given.
+func sourcesRetentionPolicy(di *meta.DatabaseInfo, sources influxql.Sources) (string, error) {
+	var rp string
+	for _, m := range sources.Measurements() {
+		if m.RetentionPolicy == "" {
+			continue
+		} else if rp != "" && rp != m.RetentionPolicy {
+			return "", fmt.Errorf("multiple retention policies not supported: %s, %s", rp, m.RetentionPolicy)
+		}
+		rp = m.RetentionPolicy
+	}
+
+	if rp == "" {
+		if di.DefaultRetentionPolicy == "" {
+			return "", fmt.Errorf("database %s does not have default retention policy", di.Name)
+		}
+		rp = di.DefaultRetentionPolicy
+	}
+	return rp, nil
+}
+
 func (e *StatementExecutor) executeShowUsersStatement(q *influxql.ShowUsersStatement) (models.Rows, error) {
 	row := &models.Row{Columns: []string{"user", "admin"}}
 	for _, ui := range e.MetaClient.Users() {
@@ -1204,6 +1415,23 @@ func (w *BufferedPointsWriter) Len() int { return len(w.buf) }
 // Cap returns the capacity (in points) of the buffer.
 func (w *BufferedPointsWriter) Cap() int { return cap(w.buf) }
 
+// throttledPointsWriter waits for the size of each write to be admitted by a
+// rate limit before passing it on.
+type throttledPointsWriter struct {
+	w    pointsWriter
+	rate *limiter.Rate
+}
+
+// WritePointsInto implements pointsWriter for throttledPointsWriter.
+func (w *throttledPointsWriter) WritePointsInto(req *IntoWriteRequest) error {
+	var n int
+	for _, p := range req.Points {
+		n += p.StringSize()
+	}
+	w.rate.WaitN(n)
+	return w.w.WritePointsInto(req)
+}
+
 func (e *StatementExecutor) writeInto(w pointsWriter, stmt *influxql.SelectStatement, row *models.Row) error {
 	if stmt.Target.Measurement.Database == "" {
 		return errNoDatabaseInTarget
@@ -1372,16 +1600,22 @@ type TSDBStore interface {
 
 	DeleteDatabase(name string) error
 	DeleteMeasurement(database, name string) error
+	TrashDatabase(name string, info []byte) error
 	DeleteRetentionPolicy(database, name string) error
-	DeleteSeries(database string, sources []influxql.Source, condition influxql.Expr) error
+	DeleteSeriesWithProgress(database string, sources []influxql.Source, condition influxql.Expr, progress tsdb.DeleteSeriesProgressFunc, interrupt <-chan struct{}) error
 	DeleteShard(id uint64) error
 
+	DeleteMeasurementDryRun(database, name string) (tsdb.DeleteStats, error)
+	DeleteSeriesDryRun(database string, sources []influxql.Source, condition influxql.Expr) (tsdb.DeleteStats, error)
+
 	MeasurementNames(database string, cond influxql.Expr) ([][]byte, error)
 	TagKeys(auth query.Authorizer, shardIDs []uint64, cond influxql.Expr) ([]tsdb.TagKeys, error)
 	TagValues(auth query.Authorizer, shardIDs []uint64, cond influxql.Expr) ([]tsdb.TagValues, error)
 
 	SeriesCardinality(database string) (int64, error)
 	MeasurementsCardinality(database string) (int64, error)
+
+	ShardDiskSize(id uint64) (int64, error)
 }
 
 var _ TSDBStore = LocalTSDBStore{}
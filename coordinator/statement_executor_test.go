@@ -15,9 +15,11 @@ import (
 	"github.com/influxdata/influxdb/coordinator"
 	"github.com/influxdata/influxdb/internal"
 	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/pkg/limiter"
 	"github.com/influxdata/influxdb/query"
 	"github.com/influxdata/influxdb/services/meta"
 	"github.com/influxdata/influxdb/tsdb"
+	"github.com/influxdata/influxdb/tsdb/tsdbtest"
 	"github.com/influxdata/influxql"
 	"github.com/uber-go/zap"
 )
@@ -84,6 +86,99 @@ func TestQueryExecutor_ExecuteQuery_SelectStatement(t *testing.T) {
 	}
 }
 
+// Ensure query executor attaches statistics to the last result when requested.
+func TestQueryExecutor_ExecuteQuery_SelectStatement_IncludeStats(t *testing.T) {
+	e := DefaultQueryExecutor()
+
+	e.MetaClient.ShardGroupsByTimeRangeFn = func(database, policy string, min, max time.Time) (a []meta.ShardGroupInfo, err error) {
+		return []meta.ShardGroupInfo{
+			{ID: 1, Shards: []meta.ShardInfo{
+				{ID: 100, Owners: []meta.ShardOwner{{NodeID: 0}}},
+			}},
+		}, nil
+	}
+
+	e.TSDBStore.ShardGroupFn = func(ids []uint64) tsdb.ShardGroup {
+		var sh MockShard
+		sh.CreateIteratorFn = func(_ context.Context, _ *influxql.Measurement, _ query.IteratorOptions) (query.Iterator, error) {
+			return &FloatIterator{Points: []query.FloatPoint{
+				{Name: "cpu", Time: int64(0 * time.Second), Aux: []interface{}{float64(100)}},
+				{Name: "cpu", Time: int64(1 * time.Second), Aux: []interface{}{float64(200)}},
+			}}, nil
+		}
+		sh.FieldDimensionsFn = func(measurements []string) (fields map[string]influxql.DataType, dimensions map[string]struct{}, err error) {
+			return map[string]influxql.DataType{"value": influxql.Float}, nil, nil
+		}
+		return &sh
+	}
+
+	// Use a chunk size of one so the series is split across two results.
+	results := ReadAllResults(e.QueryExecutor.ExecuteQuery(MustParseQuery(`SELECT * FROM cpu`), query.ExecutionOptions{
+		Database:     "db0",
+		ChunkSize:    1,
+		IncludeStats: true,
+	}, make(chan struct{})))
+	if len(results) != 2 {
+		t.Fatalf("unexpected results: %s", spew.Sdump(results))
+	} else if results[0].Stats != nil {
+		t.Fatalf("unexpected stats on first result: %s", spew.Sdump(results[0].Stats))
+	} else if !reflect.DeepEqual(results[1].Stats, &query.ResultStats{SeriesN: 1, ValuesN: 2}) {
+		t.Fatalf("unexpected stats: %s", spew.Sdump(results[1].Stats))
+	}
+}
+
+// Ensure query executor reports limit truncation and the statistics of a
+// SELECT INTO statement when requested.
+func TestQueryExecutor_ExecuteQuery_SelectStatement_IncludeStats_LimitsAndInto(t *testing.T) {
+	e := DefaultQueryExecutor()
+	e.StatementExecutor.PointsWriter = PointsWriterIntoFunc(func(req *coordinator.IntoWriteRequest) error {
+		return nil
+	})
+
+	e.MetaClient.ShardGroupsByTimeRangeFn = func(database, policy string, min, max time.Time) (a []meta.ShardGroupInfo, err error) {
+		return []meta.ShardGroupInfo{
+			{ID: 1, Shards: []meta.ShardInfo{
+				{ID: 100, Owners: []meta.ShardOwner{{NodeID: 0}}},
+			}},
+		}, nil
+	}
+
+	e.TSDBStore.ShardGroupFn = func(ids []uint64) tsdb.ShardGroup {
+		var sh MockShard
+		sh.CreateIteratorFn = func(_ context.Context, _ *influxql.Measurement, _ query.IteratorOptions) (query.Iterator, error) {
+			return &FloatIterator{Points: []query.FloatPoint{
+				{Name: "cpu", Time: int64(0 * time.Second), Value: 100, Aux: []interface{}{float64(100)}},
+				{Name: "cpu", Time: int64(1 * time.Second), Value: 200, Aux: []interface{}{float64(200)}},
+				{Name: "cpu", Time: int64(2 * time.Second), Value: 300, Aux: []interface{}{float64(300)}},
+			}}, nil
+		}
+		sh.FieldDimensionsFn = func(measurements []string) (fields map[string]influxql.DataType, dimensions map[string]struct{}, err error) {
+			return map[string]influxql.DataType{"value": influxql.Float}, nil, nil
+		}
+		return &sh
+	}
+
+	for _, tt := range []struct {
+		q     string
+		stats query.ResultStats
+	}{
+		{q: `SELECT value FROM cpu`, stats: query.ResultStats{SeriesN: 1, ValuesN: 3}},
+		{q: `SELECT value FROM cpu LIMIT 2`, stats: query.ResultStats{SeriesN: 1, ValuesN: 2, Truncated: true}},
+		{q: `SELECT value FROM cpu SLIMIT 1`, stats: query.ResultStats{SeriesN: 1, ValuesN: 3, Truncated: true}},
+		{q: `SELECT value INTO cpu_copy FROM cpu`, stats: query.ResultStats{SeriesN: 1, ValuesN: 3}},
+	} {
+		results := ReadAllResults(e.QueryExecutor.ExecuteQuery(MustParseQuery(tt.q), query.ExecutionOptions{
+			Database:     "db0",
+			IncludeStats: true,
+		}, make(chan struct{})))
+		if len(results) != 1 || results[0].Err != nil {
+			t.Fatalf("%s: unexpected results: %s", tt.q, spew.Sdump(results))
+		} else if !reflect.DeepEqual(results[0].Stats, &tt.stats) {
+			t.Errorf("%s: unexpected stats: %s", tt.q, spew.Sdump(results[0].Stats))
+		}
+	}
+}
+
 // Ensure query executor can enforce a maximum bucket selection count.
 func TestQueryExecutor_ExecuteQuery_MaxSelectBucketsN(t *testing.T) {
 	e := DefaultQueryExecutor()
@@ -129,6 +224,187 @@ func TestQueryExecutor_ExecuteQuery_MaxSelectBucketsN(t *testing.T) {
 	}
 }
 
+// Ensure query executor writes the results of a SELECT INTO statement to the
+// target measurement in the default database and retention policy.
+func TestQueryExecutor_ExecuteQuery_SelectInto(t *testing.T) {
+	e := DefaultQueryExecutor()
+
+	var reqs []*coordinator.IntoWriteRequest
+	e.StatementExecutor.PointsWriter = PointsWriterIntoFunc(func(req *coordinator.IntoWriteRequest) error {
+		reqs = append(reqs, req)
+		return nil
+	})
+
+	e.MetaClient.ShardGroupsByTimeRangeFn = func(database, policy string, min, max time.Time) (a []meta.ShardGroupInfo, err error) {
+		return []meta.ShardGroupInfo{
+			{ID: 1, Shards: []meta.ShardInfo{
+				{ID: 100, Owners: []meta.ShardOwner{{NodeID: 0}}},
+			}},
+		}, nil
+	}
+
+	e.TSDBStore.ShardGroupFn = func(ids []uint64) tsdb.ShardGroup {
+		var sh MockShard
+		sh.CreateIteratorFn = func(_ context.Context, _ *influxql.Measurement, _ query.IteratorOptions) (query.Iterator, error) {
+			return &FloatIterator{Points: []query.FloatPoint{
+				{Name: "cpu", Time: int64(0 * time.Second), Value: 100},
+				{Name: "cpu", Time: int64(1 * time.Second), Value: 200},
+				{Name: "cpu", Time: int64(5 * time.Minute), Value: 300},
+			}}, nil
+		}
+		sh.FieldDimensionsFn = func(measurements []string) (fields map[string]influxql.DataType, dimensions map[string]struct{}, err error) {
+			return map[string]influxql.DataType{"value": influxql.Float}, nil, nil
+		}
+		return &sh
+	}
+
+	if a := ReadAllResults(e.ExecuteQuery(`SELECT mean(value) INTO downsampled_cpu FROM cpu WHERE time >= 0 AND time < 10m GROUP BY time(5m)`, "db0", 0)); !reflect.DeepEqual(a, []*query.Result{
+		{
+			StatementID: 0,
+			Series: []*models.Row{{
+				Name:    "result",
+				Columns: []string{"time", "written"},
+				Values:  [][]interface{}{{time.Unix(0, 0).UTC(), int64(2)}},
+			}},
+		},
+	}) {
+		t.Fatalf("unexpected results: %s", spew.Sdump(a))
+	}
+
+	if len(reqs) != 1 {
+		t.Fatalf("unexpected write requests: %d", len(reqs))
+	} else if req := reqs[0]; req.Database != "db0" || req.RetentionPolicy != "rp0" {
+		t.Fatalf("unexpected target: %s.%s", req.Database, req.RetentionPolicy)
+	}
+
+	var points []string
+	for _, p := range reqs[0].Points {
+		points = append(points, p.String())
+	}
+	if exp := []string{"downsampled_cpu mean=150 0", "downsampled_cpu mean=300 300000000000"}; !reflect.DeepEqual(points, exp) {
+		t.Fatalf("unexpected points: %v", points)
+	}
+}
+
+// Ensure the SELECT INTO writes of batch queries are limited by the
+// background throughput limit.
+func TestQueryExecutor_ExecuteQuery_SelectInto_BackgroundRate(t *testing.T) {
+	e := DefaultQueryExecutor()
+	e.StatementExecutor.BackgroundRate = limiter.NewRate(1000, 1)
+
+	var n int
+	e.StatementExecutor.PointsWriter = PointsWriterIntoFunc(func(req *coordinator.IntoWriteRequest) error {
+		n += len(req.Points)
+		return nil
+	})
+
+	e.MetaClient.ShardGroupsByTimeRangeFn = func(database, policy string, min, max time.Time) (a []meta.ShardGroupInfo, err error) {
+		return []meta.ShardGroupInfo{
+			{ID: 1, Shards: []meta.ShardInfo{
+				{ID: 100, Owners: []meta.ShardOwner{{NodeID: 0}}},
+			}},
+		}, nil
+	}
+
+	e.TSDBStore.ShardGroupFn = func(ids []uint64) tsdb.ShardGroup {
+		var sh MockShard
+		sh.CreateIteratorFn = func(_ context.Context, _ *influxql.Measurement, _ query.IteratorOptions) (query.Iterator, error) {
+			return &FloatIterator{Points: []query.FloatPoint{
+				{Name: "cpu", Time: int64(0 * time.Second), Value: 100},
+				{Name: "cpu", Time: int64(5 * time.Minute), Value: 300},
+			}}, nil
+		}
+		sh.FieldDimensionsFn = func(measurements []string) (fields map[string]influxql.DataType, dimensions map[string]struct{}, err error) {
+			return map[string]influxql.DataType{"value": influxql.Float}, nil, nil
+		}
+		return &sh
+	}
+
+	// The two points written are about 60 bytes, which take about 60ms to
+	// be admitted at 1000 bytes per second.
+	start := time.Now()
+	results := ReadAllResults(e.QueryExecutor.ExecuteQuery(MustParseQuery(`SELECT mean(value) INTO downsampled_cpu FROM cpu WHERE time >= 0 AND time < 10m GROUP BY time(5m)`), query.ExecutionOptions{
+		Database: "db0",
+		Priority: query.BatchPriority,
+	}, make(chan struct{})))
+	if len(results) != 1 || results[0].Err != nil {
+		t.Fatalf("unexpected results: %s", spew.Sdump(results))
+	} else if n != 2 {
+		t.Fatalf("unexpected points written: %d", n)
+	} else if d := time.Since(start); d < 40*time.Millisecond {
+		t.Fatalf("expected the write to be throttled, took %s", d)
+	}
+}
+
+// Ensure destructive statements report what they would delete on a dry run.
+func TestQueryExecutor_ExecuteQuery_DryRun(t *testing.T) {
+	e := DefaultQueryExecutor()
+	e.TSDBStore.DeleteSeriesWithProgressFn = func(database string, sources []influxql.Source, condition influxql.Expr, progress tsdb.DeleteSeriesProgressFunc, interrupt <-chan struct{}) error {
+		t.Fatal("unexpected delete")
+		return nil
+	}
+	e.TSDBStore.DeleteSeriesDryRunFn = func(database string, sources []influxql.Source, condition influxql.Expr) (tsdb.DeleteStats, error) {
+		if database != "db0" {
+			t.Fatalf("unexpected database: %s", database)
+		} else if got, exp := condition.String(), "host = 'serverA'"; got != exp {
+			t.Fatalf("unexpected condition: %s", got)
+		}
+		return tsdb.DeleteStats{Shards: 2, Series: 1, Values: 10}, nil
+	}
+
+	q := MustParseQuery(`DROP SERIES FROM cpu WHERE host = 'serverA'; DROP DATABASE db0`)
+	results := ReadAllResults(e.QueryExecutor.ExecuteQuery(q, query.ExecutionOptions{Database: "db0", DryRun: true}, nil))
+	if exp := []*query.Result{
+		{
+			StatementID: 0,
+			Series: []*models.Row{{
+				Name:    "dry run",
+				Columns: []string{"shards", "series", "values"},
+				Values:  [][]interface{}{{int64(2), int64(1), int64(10)}},
+			}},
+		},
+		{
+			StatementID: 1,
+			Err:         errors.New("dry run is only supported for DELETE, DROP MEASUREMENT and DROP SERIES statements"),
+		},
+	}; !reflect.DeepEqual(results, exp) {
+		t.Fatalf("unexpected results: %s", spew.Sdump(results))
+	}
+}
+
+// Ensure SHOW TAG KEYS limits the measurements with SLIMIT and SOFFSET and
+// the keys of each measurement with LIMIT and OFFSET.
+func TestStatementExecutor_ShowTagKeys_Limits(t *testing.T) {
+	e := tsdbtest.MustOpenQueryExecutor("inmem")
+	defer e.Close()
+	e.MustWritePoints("db0", "", tsdbtest.NewPointGenerator(4, 3, 1).Points())
+
+	results, err := e.ExecuteQuery(`SHOW TAG KEYS LIMIT 1 OFFSET 1 SLIMIT 2 SOFFSET 1`, "db0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exp := []*query.Result{
+		{
+			StatementID: 0,
+			Series: []*models.Row{{
+				Name:    "m1",
+				Columns: []string{"tagKey"},
+				Values:  [][]interface{}{{"tagKey1"}},
+			}},
+		},
+		{
+			StatementID: 0,
+			Series: []*models.Row{{
+				Name:    "m2",
+				Columns: []string{"tagKey"},
+				Values:  [][]interface{}{{"tagKey1"}},
+			}},
+		},
+	}; !reflect.DeepEqual(results, exp) {
+		t.Fatalf("unexpected results: %s", spew.Sdump(results))
+	}
+}
+
 func TestStatementExecutor_NormalizeDropSeries(t *testing.T) {
 	q, err := influxql.ParseQuery("DROP SERIES FROM cpu")
 	if err != nil {
@@ -258,6 +534,42 @@ func TestQueryExecutor_ExecuteQuery_ShowDatabases(t *testing.T) {
 	}
 }
 
+// Ensure SHOW TAG KEYS and SHOW TAG VALUES check that the user may read the
+// database named in their FROM clause, which replaces the ON database.
+func TestQueryExecutor_ExecuteQuery_ShowTags_SourceDatabaseAuthorization(t *testing.T) {
+	qe := query.NewQueryExecutor()
+	qe.StatementExecutor = &coordinator.StatementExecutor{
+		MetaClient: &internal.MetaClientMock{
+			DatabaseFn: func(name string) *meta.DatabaseInfo {
+				return &meta.DatabaseInfo{Name: name, DefaultRetentionPolicy: "rp0"}
+			},
+		},
+	}
+
+	opt := query.ExecutionOptions{
+		Authorizer: &mockAuthorizer{
+			AuthorizeDatabaseFn: func(p influxql.Privilege, name string) bool {
+				return name == "db0"
+			},
+		},
+	}
+
+	for _, s := range []string{
+		`SHOW TAG KEYS ON db0 FROM mydb.myrp1.cpu`,
+		`SHOW TAG VALUES ON db0 FROM mydb.myrp1.cpu WITH KEY = "host"`,
+	} {
+		q, err := influxql.ParseQuery(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		results := ReadAllResults(qe.ExecuteQuery(q, opt, make(chan struct{})))
+		if len(results) != 1 || results[0].Err == nil || results[0].Err.Error() != "not authorized to read database mydb" {
+			t.Fatalf("%s: unexpected results: %s", s, spew.Sdump(results))
+		}
+	}
+}
+
 // QueryExecutor is a test wrapper for coordinator.QueryExecutor.
 type QueryExecutor struct {
 	*query.QueryExecutor
@@ -325,6 +637,15 @@ func (e *QueryExecutor) ExecuteQuery(q, database string, chunkSize int) <-chan *
 	}, make(chan struct{}))
 }
 
+// PointsWriterIntoFunc is a function that implements the writes of SELECT
+// INTO statements.
+type PointsWriterIntoFunc func(req *coordinator.IntoWriteRequest) error
+
+// WritePointsInto calls fn with req.
+func (fn PointsWriterIntoFunc) WritePointsInto(req *coordinator.IntoWriteRequest) error {
+	return fn(req)
+}
+
 type MockShard struct {
 	Measurements      []string
 	FieldDimensionsFn func(measurements []string) (fields map[string]influxql.DataType, dimensions map[string]struct{}, err error)
@@ -410,3 +731,114 @@ func (itr *FloatIterator) Next() (*query.FloatPoint, error) {
 	itr.Points = itr.Points[1:]
 	return v, nil
 }
+
+func TestQueryExecutor_ExecuteQuery_ShowRetentionPolicies(t *testing.T) {
+	qe := query.NewQueryExecutor()
+	qe.StatementExecutor = &coordinator.StatementExecutor{
+		MetaClient: &internal.MetaClientMock{
+			DatabaseFn: func(name string) *meta.DatabaseInfo {
+				return &meta.DatabaseInfo{
+					Name:                   "db0",
+					DefaultRetentionPolicy: "rp0",
+					RetentionPolicies: []meta.RetentionPolicyInfo{{
+						Name:               "rp0",
+						Duration:           24 * time.Hour,
+						ShardGroupDuration: time.Hour,
+						ReplicaN:           1,
+						ShardGroups: []meta.ShardGroupInfo{
+							{ID: 1, Shards: []meta.ShardInfo{{ID: 1}, {ID: 2}}},
+							{ID: 2, Shards: []meta.ShardInfo{{ID: 3}}},
+							{ID: 3, Shards: []meta.ShardInfo{{ID: 4}}, DeletedAt: time.Unix(0, 1)},
+						},
+					}},
+				}
+			},
+		},
+		TSDBStore: &internal.TSDBStoreMock{
+			ShardDiskSizeFn: func(id uint64) (int64, error) {
+				return int64(id) * 100, nil
+			},
+		},
+	}
+
+	q, err := influxql.ParseQuery("SHOW RETENTION POLICIES ON db0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The shards of deleted shard groups are not counted.
+	results := ReadAllResults(qe.ExecuteQuery(q, query.ExecutionOptions{}, make(chan struct{})))
+	exp := []*query.Result{
+		{
+			StatementID: 0,
+			Series: []*models.Row{{
+				Columns: []string{"name", "duration", "shardGroupDuration", "replicaN", "default", "shardGroupN", "shardN", "diskBytes"},
+				Values: [][]interface{}{
+					{"rp0", "24h0m0s", "1h0m0s", 1, true, 2, 3, int64(600)},
+				},
+			}},
+		},
+	}
+	if !reflect.DeepEqual(results, exp) {
+		t.Fatalf("unexpected results: exp %s, got %s", spew.Sdump(exp), spew.Sdump(results))
+	}
+}
+
+func TestQueryExecutor_ExecuteQuery_ShowShards(t *testing.T) {
+	start := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	qe := query.NewQueryExecutor()
+	qe.StatementExecutor = &coordinator.StatementExecutor{
+		MetaClient: &internal.MetaClientMock{
+			DatabasesFn: func() []meta.DatabaseInfo {
+				return []meta.DatabaseInfo{{
+					Name: "db0",
+					RetentionPolicies: []meta.RetentionPolicyInfo{{
+						Name:     "rp0",
+						Duration: 24 * time.Hour,
+						ShardGroups: []meta.ShardGroupInfo{{
+							ID:        1,
+							StartTime: start,
+							EndTime:   start.Add(time.Hour),
+							Shards: []meta.ShardInfo{
+								{ID: 1, Owners: []meta.ShardOwner{{NodeID: 0}}},
+								{ID: 2},
+							},
+						}},
+					}},
+				}}
+			},
+		},
+		TSDBStore: &internal.TSDBStoreMock{
+			ShardDiskSizeFn: func(id uint64) (int64, error) {
+				// Only shard 1 is stored locally.
+				if id == 1 {
+					return 1024, nil
+				}
+				return 0, nil
+			},
+		},
+	}
+
+	q, err := influxql.ParseQuery("SHOW SHARDS")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	results := ReadAllResults(qe.ExecuteQuery(q, query.ExecutionOptions{}, make(chan struct{})))
+	exp := []*query.Result{
+		{
+			StatementID: 0,
+			Series: []*models.Row{{
+				Name:    "db0",
+				Columns: []string{"id", "database", "retention_policy", "shard_group", "start_time", "end_time", "expiry_time", "owners", "disk_bytes"},
+				Values: [][]interface{}{
+					{uint64(1), "db0", "rp0", uint64(1), "2000-01-01T00:00:00Z", "2000-01-01T01:00:00Z", "2000-01-02T01:00:00Z", "0", int64(1024)},
+					{uint64(2), "db0", "rp0", uint64(1), "2000-01-01T00:00:00Z", "2000-01-01T01:00:00Z", "2000-01-02T01:00:00Z", "", int64(0)},
+				},
+			}},
+		},
+	}
+	if !reflect.DeepEqual(results, exp) {
+		t.Fatalf("unexpected results: exp %s, got %s", spew.Sdump(exp), spew.Sdump(results))
+	}
+}
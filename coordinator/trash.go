@@ -0,0 +1,87 @@
+package coordinator
+
+import (
+	"encoding/json"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/services/meta"
+	"github.com/influxdata/influxdb/tsdb"
+)
+
+// DatabaseTrash undrops databases whose shards were moved to the trash of
+// the local store when they were dropped.
+type DatabaseTrash struct {
+	MetaClient interface {
+		databaseCopier
+		CreateContinuousQuery(database, name, query string) error
+		SetQuota(database string, q *meta.QuotaInfo) error
+	}
+
+	TSDBStore interface {
+		TrashedDatabases() ([]tsdb.TrashedDatabase, error)
+		RestoreTrashedShard(id, rp string, srcID uint64, database string, dstID uint64) error
+		DeleteTrash(id string) error
+		DeleteDatabase(name string) error
+	}
+}
+
+// TrashedDatabases returns the databases in the trash, oldest first.
+func (t *DatabaseTrash) TrashedDatabases() ([]tsdb.TrashedDatabase, error) {
+	return t.TSDBStore.TrashedDatabases()
+}
+
+// UndropDatabase recreates the most recently dropped database with the given
+// name from the trash, with its retention policies, continuous queries,
+// quota and data. Subscriptions and user privileges are not restored.
+func (t *DatabaseTrash) UndropDatabase(name string) error {
+	if t.MetaClient.Database(name) != nil {
+		return meta.ErrDatabaseExists
+	}
+
+	a, err := t.TSDBStore.TrashedDatabases()
+	if err != nil {
+		return err
+	}
+
+	var td *tsdb.TrashedDatabase
+	for i := range a {
+		if a[i].Name == name {
+			td = &a[i]
+		}
+	}
+	if td == nil {
+		return influxdb.ErrDatabaseNotFound(name)
+	}
+
+	var di meta.DatabaseInfo
+	if err := json.Unmarshal(td.Info, &di); err != nil {
+		return err
+	}
+	di.Name = name
+
+	if err := t.undropDatabase(td.ID, &di); err != nil {
+		t.TSDBStore.DeleteDatabase(name)
+		t.MetaClient.DropDatabase(name)
+		return err
+	}
+	return t.TSDBStore.DeleteTrash(td.ID)
+}
+
+func (t *DatabaseTrash) undropDatabase(id string, di *meta.DatabaseInfo) error {
+	if err := copyDatabase(t.MetaClient, di, di.Name, func(srcID uint64, rp string, dstID uint64) error {
+		return t.TSDBStore.RestoreTrashedShard(id, rp, srcID, di.Name, dstID)
+	}); err != nil {
+		return err
+	}
+
+	for _, cqi := range di.ContinuousQueries {
+		if err := t.MetaClient.CreateContinuousQuery(di.Name, cqi.Name, cqi.Query); err != nil {
+			return err
+		}
+	}
+
+	if di.Quota != nil {
+		return t.MetaClient.SetQuota(di.Name, di.Quota)
+	}
+	return nil
+}
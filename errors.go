@@ -6,18 +6,75 @@ import (
 	"strings"
 )
 
+// ErrorCode is a stable identifier for a class of error. Clients should branch
+// on the code rather than on the error message, which may change.
+type ErrorCode string
+
+// Error codes returned to clients.
+const (
+	ErrorCodeInvalidQuery            ErrorCode = "invalid_query"
+	ErrorCodeUnauthorized            ErrorCode = "unauthorized"
+	ErrorCodeDatabaseNotFound        ErrorCode = "database_not_found"
+	ErrorCodeRetentionPolicyNotFound ErrorCode = "retention_policy_not_found"
+	ErrorCodeFieldTypeConflict       ErrorCode = "field_type_conflict"
+	ErrorCodeQueryTimeout            ErrorCode = "query_timeout"
+	ErrorCodeQueryInterrupted        ErrorCode = "query_interrupted"
+	ErrorCodeLimitExceeded           ErrorCode = "limit_exceeded"
+	ErrorCodeNotExecuted             ErrorCode = "not_executed"
+	ErrorCodeShutdown                ErrorCode = "shutdown"
+	ErrorCodeQuotaExceeded           ErrorCode = "quota_exceeded"
+)
+
+// Error is an error annotated with an ErrorCode.
+type Error struct {
+	Code ErrorCode
+	Err  error
+}
+
+// NewError returns a new error with the given code and message.
+func NewError(code ErrorCode, msg string) error {
+	return &Error{Code: code, Err: errors.New(msg)}
+}
+
+// Error returns the message of the underlying error.
+func (e *Error) Error() string { return e.Err.Error() }
+
+// ErrorCode returns the code associated with the error.
+func (e *Error) ErrorCode() ErrorCode { return e.Code }
+
+// ErrorCodeOf returns the stable code for err. An empty code is returned for
+// nil errors and for errors that cannot be classified.
+func ErrorCodeOf(err error) ErrorCode {
+	if err == nil {
+		return ""
+	}
+	if e, ok := err.(interface {
+		ErrorCode() ErrorCode
+	}); ok {
+		return e.ErrorCode()
+	}
+	if IsAuthorizationError(err) {
+		return ErrorCodeUnauthorized
+	} else if IsClientError(err) {
+		return ErrorCodeFieldTypeConflict
+	}
+	return ""
+}
+
 // ErrFieldTypeConflict is returned when a new field already exists with a
 // different type.
 var ErrFieldTypeConflict = errors.New("field type conflict")
 
 // ErrDatabaseNotFound indicates that a database operation failed on the
 // specified database because the specified database does not exist.
-func ErrDatabaseNotFound(name string) error { return fmt.Errorf("database not found: %s", name) }
+func ErrDatabaseNotFound(name string) error {
+	return &Error{Code: ErrorCodeDatabaseNotFound, Err: fmt.Errorf("database not found: %s", name)}
+}
 
 // ErrRetentionPolicyNotFound indicates that the named retention policy could
 // not be found in the database.
 func ErrRetentionPolicyNotFound(name string) error {
-	return fmt.Errorf("retention policy not found: %s", name)
+	return &Error{Code: ErrorCodeRetentionPolicyNotFound, Err: fmt.Errorf("retention policy not found: %s", name)}
 }
 
 // IsAuthorizationError indicates whether an error is due to an authorization failure
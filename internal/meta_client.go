@@ -16,6 +16,7 @@ type MetaClientMock struct {
 	CreateRetentionPolicyFn             func(database string, spec *meta.RetentionPolicySpec, makeDefault bool) (*meta.RetentionPolicyInfo, error)
 	CreateShardGroupFn                  func(database, policy string, timestamp time.Time) (*meta.ShardGroupInfo, error)
 	CreateSubscriptionFn                func(database, rp, name, mode string, destinations []string) error
+	CreateTokenFn                       func(name string, privileges map[string]influxql.Privilege) (string, error)
 	CreateUserFn                        func(name, password string, admin bool) (meta.User, error)
 
 	DatabaseFn  func(name string) *meta.DatabaseInfo
@@ -28,6 +29,7 @@ type MetaClientMock struct {
 	DropRetentionPolicyFn func(database, name string) error
 	DropSubscriptionFn    func(database, rp, name string) error
 	DropShardFn           func(id uint64) error
+	DropTokenFn           func(name string) error
 	DropUserFn            func(name string) error
 
 	OpenFn func() error
@@ -37,16 +39,20 @@ type MetaClientMock struct {
 	RetentionPolicyFn func(database, name string) (rpi *meta.RetentionPolicyInfo, err error)
 
 	AuthenticateFn           func(username, password string) (ui meta.User, err error)
+	AuthenticateTokenFn      func(token string) (meta.User, error)
 	AdminUserExistsFn        func() bool
 	SetAdminPrivilegeFn      func(username string, admin bool) error
 	SetDataFn                func(*meta.Data) error
 	SetPrivilegeFn           func(username, database string, p influxql.Privilege) error
+	SetQuotaFn               func(database string, q *meta.QuotaInfo) error
+	SetUserSettingsFn        func(name string, s meta.UserSettings) error
 	ShardGroupsByTimeRangeFn func(database, policy string, min, max time.Time) (a []meta.ShardGroupInfo, err error)
 	ShardOwnerFn             func(shardID uint64) (database, policy string, sgi *meta.ShardGroupInfo)
 	UpdateRetentionPolicyFn  func(database, name string, rpu *meta.RetentionPolicyUpdate, makeDefault bool) error
 	UpdateUserFn             func(name, password string) error
 	UserPrivilegeFn          func(username, database string) (*influxql.Privilege, error)
 	UserPrivilegesFn         func(username string) (map[string]influxql.Privilege, error)
+	TokensFn                 func() []meta.TokenInfo
 	UserFn                   func(username string) (meta.User, error)
 	UsersFn                  func() []meta.UserInfo
 }
@@ -79,6 +85,10 @@ func (c *MetaClientMock) CreateSubscription(database, rp, name, mode string, des
 	return c.CreateSubscriptionFn(database, rp, name, mode, destinations)
 }
 
+func (c *MetaClientMock) CreateToken(name string, privileges map[string]influxql.Privilege) (string, error) {
+	return c.CreateTokenFn(name, privileges)
+}
+
 func (c *MetaClientMock) CreateUser(name, password string, admin bool) (meta.User, error) {
 	return c.CreateUserFn(name, password, admin)
 }
@@ -115,6 +125,10 @@ func (c *MetaClientMock) DropSubscription(database, rp, name string) error {
 	return c.DropSubscriptionFn(database, rp, name)
 }
 
+func (c *MetaClientMock) DropToken(name string) error {
+	return c.DropTokenFn(name)
+}
+
 func (c *MetaClientMock) DropUser(name string) error {
 	return c.DropUserFn(name)
 }
@@ -131,6 +145,14 @@ func (c *MetaClientMock) SetPrivilege(username, database string, p influxql.Priv
 	return c.SetPrivilegeFn(username, database, p)
 }
 
+func (c *MetaClientMock) SetQuota(database string, q *meta.QuotaInfo) error {
+	return c.SetQuotaFn(database, q)
+}
+
+func (c *MetaClientMock) SetUserSettings(name string, s meta.UserSettings) error {
+	return c.SetUserSettingsFn(name, s)
+}
+
 func (c *MetaClientMock) ShardGroupsByTimeRange(database, policy string, min, max time.Time) (a []meta.ShardGroupInfo, err error) {
 	return c.ShardGroupsByTimeRangeFn(database, policy, min, max)
 }
@@ -158,10 +180,14 @@ func (c *MetaClientMock) UserPrivileges(username string) (map[string]influxql.Pr
 func (c *MetaClientMock) Authenticate(username, password string) (meta.User, error) {
 	return c.AuthenticateFn(username, password)
 }
+func (c *MetaClientMock) AuthenticateToken(token string) (meta.User, error) {
+	return c.AuthenticateTokenFn(token)
+}
 func (c *MetaClientMock) AdminUserExists() bool { return c.AdminUserExistsFn() }
 
 func (c *MetaClientMock) User(username string) (meta.User, error) { return c.UserFn(username) }
 func (c *MetaClientMock) Users() []meta.UserInfo                  { return c.UsersFn() }
+func (c *MetaClientMock) Tokens() []meta.TokenInfo                { return c.TokensFn() }
 
 func (c *MetaClientMock) Open() error                { return c.OpenFn() }
 func (c *MetaClientMock) Data() meta.Data            { return c.DataFn() }
@@ -13,38 +13,43 @@ import (
 
 // TSDBStoreMock is a mockable implementation of tsdb.Store.
 type TSDBStoreMock struct {
-	BackupShardFn             func(id uint64, since time.Time, w io.Writer) error
-	CloseFn                   func() error
-	CreateShardFn             func(database, policy string, shardID uint64, enabled bool) error
-	CreateShardSnapshotFn     func(id uint64) (string, error)
-	DatabasesFn               func() []string
-	DeleteDatabaseFn          func(name string) error
-	DeleteMeasurementFn       func(database, name string) error
-	DeleteRetentionPolicyFn   func(database, name string) error
-	DeleteSeriesFn            func(database string, sources []influxql.Source, condition influxql.Expr) error
-	DeleteShardFn             func(id uint64) error
-	DiskSizeFn                func() (int64, error)
-	ExpandSourcesFn           func(sources influxql.Sources) (influxql.Sources, error)
-	ImportShardFn             func(id uint64, r io.Reader) error
-	MeasurementSeriesCountsFn func(database string) (measuments int, series int)
-	MeasurementsCardinalityFn func(database string) (int64, error)
-	MeasurementNamesFn        func(database string, cond influxql.Expr) ([][]byte, error)
-	OpenFn                    func() error
-	PathFn                    func() string
-	RestoreShardFn            func(id uint64, r io.Reader) error
-	SeriesCardinalityFn       func(database string) (int64, error)
-	SetShardEnabledFn         func(shardID uint64, enabled bool) error
-	ShardFn                   func(id uint64) *tsdb.Shard
-	ShardGroupFn              func(ids []uint64) tsdb.ShardGroup
-	ShardIDsFn                func() []uint64
-	ShardNFn                  func() int
-	ShardRelativePathFn       func(id uint64) (string, error)
-	ShardsFn                  func(ids []uint64) []*tsdb.Shard
-	StatisticsFn              func(tags map[string]string) []models.Statistic
-	TagKeysFn                 func(auth query.Authorizer, shardIDs []uint64, cond influxql.Expr) ([]tsdb.TagKeys, error)
-	TagValuesFn               func(auth query.Authorizer, shardIDs []uint64, cond influxql.Expr) ([]tsdb.TagValues, error)
-	WithLoggerFn              func(log zap.Logger)
-	WriteToShardFn            func(shardID uint64, points []models.Point) error
+	BackupShardFn              func(id uint64, since time.Time, w io.Writer) error
+	CloseFn                    func() error
+	CreateShardFn              func(database, policy string, shardID uint64, enabled bool) error
+	CreateShardSnapshotFn      func(id uint64) (string, error)
+	DatabasesFn                func() []string
+	DeleteDatabaseFn           func(name string) error
+	DeleteMeasurementFn        func(database, name string) error
+	DeleteMeasurementDryRunFn  func(database, name string) (tsdb.DeleteStats, error)
+	DeleteRetentionPolicyFn    func(database, name string) error
+	DeleteSeriesFn             func(database string, sources []influxql.Source, condition influxql.Expr) error
+	DeleteSeriesDryRunFn       func(database string, sources []influxql.Source, condition influxql.Expr) (tsdb.DeleteStats, error)
+	DeleteSeriesWithProgressFn func(database string, sources []influxql.Source, condition influxql.Expr, progress tsdb.DeleteSeriesProgressFunc, interrupt <-chan struct{}) error
+	DeleteShardFn              func(id uint64) error
+	DiskSizeFn                 func() (int64, error)
+	ExpandSourcesFn            func(sources influxql.Sources) (influxql.Sources, error)
+	ImportShardFn              func(id uint64, r io.Reader) error
+	MeasurementSeriesCountsFn  func(database string) (measuments int, series int)
+	MeasurementsCardinalityFn  func(database string) (int64, error)
+	MeasurementNamesFn         func(database string, cond influxql.Expr) ([][]byte, error)
+	OpenFn                     func() error
+	PathFn                     func() string
+	RestoreShardFn             func(id uint64, r io.Reader) error
+	SeriesCardinalityFn        func(database string) (int64, error)
+	SetShardEnabledFn          func(shardID uint64, enabled bool) error
+	ShardDiskSizeFn            func(id uint64) (int64, error)
+	ShardFn                    func(id uint64) *tsdb.Shard
+	ShardGroupFn               func(ids []uint64) tsdb.ShardGroup
+	ShardIDsFn                 func() []uint64
+	ShardNFn                   func() int
+	ShardRelativePathFn        func(id uint64) (string, error)
+	ShardsFn                   func(ids []uint64) []*tsdb.Shard
+	StatisticsFn               func(tags map[string]string) []models.Statistic
+	TagKeysFn                  func(auth query.Authorizer, shardIDs []uint64, cond influxql.Expr) ([]tsdb.TagKeys, error)
+	TagValuesFn                func(auth query.Authorizer, shardIDs []uint64, cond influxql.Expr) ([]tsdb.TagValues, error)
+	TrashDatabaseFn            func(name string, info []byte) error
+	WithLoggerFn               func(log zap.Logger)
+	WriteToShardFn             func(shardID uint64, points []models.Point) error
 }
 
 func (s *TSDBStoreMock) BackupShard(id uint64, since time.Time, w io.Writer) error {
@@ -66,12 +71,21 @@ func (s *TSDBStoreMock) DeleteDatabase(name string) error {
 func (s *TSDBStoreMock) DeleteMeasurement(database string, name string) error {
 	return s.DeleteMeasurementFn(database, name)
 }
+func (s *TSDBStoreMock) DeleteMeasurementDryRun(database string, name string) (tsdb.DeleteStats, error) {
+	return s.DeleteMeasurementDryRunFn(database, name)
+}
 func (s *TSDBStoreMock) DeleteRetentionPolicy(database string, name string) error {
 	return s.DeleteRetentionPolicyFn(database, name)
 }
 func (s *TSDBStoreMock) DeleteSeries(database string, sources []influxql.Source, condition influxql.Expr) error {
 	return s.DeleteSeriesFn(database, sources, condition)
 }
+func (s *TSDBStoreMock) DeleteSeriesDryRun(database string, sources []influxql.Source, condition influxql.Expr) (tsdb.DeleteStats, error) {
+	return s.DeleteSeriesDryRunFn(database, sources, condition)
+}
+func (s *TSDBStoreMock) DeleteSeriesWithProgress(database string, sources []influxql.Source, condition influxql.Expr, progress tsdb.DeleteSeriesProgressFunc, interrupt <-chan struct{}) error {
+	return s.DeleteSeriesWithProgressFn(database, sources, condition, progress, interrupt)
+}
 func (s *TSDBStoreMock) DeleteShard(shardID uint64) error {
 	return s.DeleteShardFn(shardID)
 }
@@ -108,6 +122,9 @@ func (s *TSDBStoreMock) SeriesCardinality(database string) (int64, error) {
 func (s *TSDBStoreMock) SetShardEnabled(shardID uint64, enabled bool) error {
 	return s.SetShardEnabledFn(shardID, enabled)
 }
+func (s *TSDBStoreMock) ShardDiskSize(id uint64) (int64, error) {
+	return s.ShardDiskSizeFn(id)
+}
 func (s *TSDBStoreMock) Shard(id uint64) *tsdb.Shard {
 	return s.ShardFn(id)
 }
@@ -135,6 +152,9 @@ func (s *TSDBStoreMock) TagKeys(auth query.Authorizer, shardIDs []uint64, cond i
 func (s *TSDBStoreMock) TagValues(auth query.Authorizer, shardIDs []uint64, cond influxql.Expr) ([]tsdb.TagValues, error) {
 	return s.TagValuesFn(auth, shardIDs, cond)
 }
+func (s *TSDBStoreMock) TrashDatabase(name string, info []byte) error {
+	return s.TrashDatabaseFn(name, info)
+}
 func (s *TSDBStoreMock) WithLogger(log zap.Logger) {
 	s.WithLoggerFn(log)
 }
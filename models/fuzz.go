@@ -0,0 +1,28 @@
+// +build gofuzz
+
+package models
+
+import "time"
+
+// Fuzz is the entry point for go-fuzz.  It parses data as line protocol and
+// exercises the accessors of the parsed points, which must not panic for any
+// input.  Build and run it with:
+//
+//	go-fuzz-build github.com/influxdata/influxdb/models
+//	go-fuzz -bin=models-fuzz.zip -workdir=models/testdata/fuzz
+func Fuzz(data []byte) int {
+	points, err := ParsePointsWithPrecision(data, time.Unix(0, 0), "n")
+	if err != nil {
+		return 0
+	}
+
+	for _, p := range points {
+		if _, err := p.Fields(); err != nil {
+			return 0
+		}
+		p.Tags()
+		p.Split(len(data) / 2)
+		ParsePointsString(p.String())
+	}
+	return 1
+}
@@ -182,6 +182,19 @@ type FieldIterator interface {
 	Reset()
 }
 
+// FindField advances iter to the field named key, so its value can be read
+// with the typed accessor for its type without decoding the other fields. It
+// returns false if there is no such field.
+func FindField(iter FieldIterator, key []byte) bool {
+	iter.Reset()
+	for iter.Next() {
+		if bytes.Equal(iter.FieldKey(), key) {
+			return true
+		}
+	}
+	return false
+}
+
 // Points represents a sortable list of points by timestamp.
 type Points []Point
 
@@ -293,19 +306,54 @@ func ParseName(buf []byte) ([]byte, error) {
 // ParsePointsWithPrecision is similar to ParsePoints, but allows the
 // caller to provide a precision for time.
 //
+// Points without a timestamp are assigned defaultTime.  If another point of
+// the same series in buf already has that time, the point is moved to the next
+// free time, in steps of the precision, so that it does not overwrite it.
+//
 // NOTE: to minimize heap allocations, the returned Points will refer to subslices of buf.
 // This can have the unintended effect preventing buf from being garbage collected.
 func ParsePointsWithPrecision(buf []byte, defaultTime time.Time, precision string) ([]Point, error) {
+	points, lerrs := ParsePointsWithLineErrors(buf, defaultTime, precision)
+	if len(lerrs) > 0 {
+		failed := make([]string, len(lerrs))
+		for i, lerr := range lerrs {
+			failed[i] = lerr.Error()
+		}
+		return points, fmt.Errorf("%s", strings.Join(failed, "\n"))
+	}
+	return points, nil
+}
+
+// LineError describes a line that could not be parsed as a point.
+type LineError struct {
+	Line int    // line number within the buffer, starting at 1
+	Text string // the text of the line
+	Err  error  // the reason the line was rejected
+}
+
+func (e LineError) Error() string {
+	return fmt.Sprintf("unable to parse '%s': %v", e.Text, e.Err)
+}
+
+// ParsePointsWithLineErrors is similar to ParsePointsWithPrecision, but
+// returns an error for each line that could not be parsed.
+func ParsePointsWithLineErrors(buf []byte, defaultTime time.Time, precision string) ([]Point, []LineError) {
 	points := make([]Point, 0, bytes.Count(buf, []byte{'\n'})+1)
 	var (
-		pos    int
-		block  []byte
-		failed []string
+		pos     int
+		line    = 1
+		block   []byte
+		failed  []LineError
+		untimed []*point
 	)
 	for pos < len(buf) {
 		pos, block = scanLine(buf, pos)
 		pos++
 
+		// Quoted field values may span several lines.
+		blockLine := line
+		line += bytes.Count(block, []byte{'\n'}) + 1
+
 		if len(block) == 0 {
 			continue
 		}
@@ -329,17 +377,65 @@ func ParsePointsWithPrecision(buf []byte, defaultTime time.Time, precision strin
 
 		pt, err := parsePoint(block[start:], defaultTime, precision)
 		if err != nil {
-			failed = append(failed, fmt.Sprintf("unable to parse '%s': %v", string(block[start:]), err))
-		} else {
-			points = append(points, pt)
+			failed = append(failed, LineError{Line: blockLine, Text: string(block[start:]), Err: err})
+			continue
 		}
 
+		if p := pt.(*point); len(p.ts) == 0 {
+			untimed = append(untimed, p)
+		}
+		points = append(points, pt)
 	}
-	if len(failed) > 0 {
-		return points, fmt.Errorf("%s", strings.Join(failed, "\n"))
+
+	if len(untimed) > 0 {
+		offsetUntimed(points, untimed, precision)
+	}
+	return points, failed
+}
+
+// offsetUntimed moves each point without a timestamp that has the time of an
+// earlier untimed point or of any timed point of its series to the next free
+// time of the series, in steps of the precision.
+func offsetUntimed(points []Point, untimed []*point, precision string) {
+	unit, ok := precisionDuration(precision)
+	if !ok {
+		unit = time.Nanosecond
+	}
+
+	// Collect the times used by timed points of the series with untimed points.
+	used := make(map[string]map[int64]struct{}, len(untimed))
+	for _, p := range untimed {
+		used[string(p.key)] = nil
+	}
+	for _, pt := range points {
+		p := pt.(*point)
+		if len(p.ts) == 0 {
+			continue
+		}
+		times, ok := used[string(p.key)]
+		if !ok {
+			continue
+		} else if times == nil {
+			times = make(map[int64]struct{})
+			used[string(p.key)] = times
+		}
+		times[p.time.UnixNano()] = struct{}{}
 	}
-	return points, nil
 
+	for _, p := range untimed {
+		times := used[string(p.key)]
+		if times == nil {
+			times = make(map[int64]struct{})
+			used[string(p.key)] = times
+		}
+		for {
+			if _, ok := times[p.time.UnixNano()]; !ok {
+				break
+			}
+			p.time = p.time.Add(unit)
+		}
+		times[p.time.UnixNano()] = struct{}{}
+	}
 }
 
 func parsePoint(buf []byte, defaultTime time.Time, precision string) (Point, error) {
@@ -421,20 +517,40 @@ func parsePoint(buf []byte, defaultTime time.Time, precision string) (Point, err
 
 // GetPrecisionMultiplier will return a multiplier for the precision specified.
 func GetPrecisionMultiplier(precision string) int64 {
-	d := time.Nanosecond
+	d, ok := precisionDuration(precision)
+	if !ok {
+		d = time.Nanosecond
+	}
+	return int64(d)
+}
+
+// ValidatePrecision returns an error if precision is not a known write precision.
+func ValidatePrecision(precision string) error {
+	if _, ok := precisionDuration(precision); !ok {
+		return fmt.Errorf("invalid precision %q (use n, u, ms, s, m or h)", precision)
+	}
+	return nil
+}
+
+// precisionDuration returns the duration of one unit of precision.  Both the
+// short units used by line protocol ("n", "u") and the units of Go durations
+// ("ns", "us") are accepted.  An empty precision is nanoseconds.
+func precisionDuration(precision string) (time.Duration, bool) {
 	switch precision {
-	case "u":
-		d = time.Microsecond
+	case "", "n", "ns":
+		return time.Nanosecond, true
+	case "u", "us", "µs":
+		return time.Microsecond, true
 	case "ms":
-		d = time.Millisecond
+		return time.Millisecond, true
 	case "s":
-		d = time.Second
+		return time.Second, true
 	case "m":
-		d = time.Minute
+		return time.Minute, true
 	case "h":
-		d = time.Hour
+		return time.Hour, true
 	}
-	return int64(d)
+	return 0, false
 }
 
 // scanKey scans buf starting at i for the measurement and tag portion of the point.
@@ -728,6 +844,11 @@ func scanFields(buf []byte, i int) (int, []byte, error) {
 		if buf[i] == '"' && equals > commas {
 			quoted = !quoted
 			i++
+
+			// A closing quote must end the field value.
+			if !quoted && i < len(buf) && buf[i] != ',' && buf[i] != ' ' && buf[i] != '"' {
+				return i, buf[start:i], fmt.Errorf("invalid field format")
+			}
 			continue
 		}
 
@@ -1123,6 +1244,24 @@ func scanTo(buf []byte, i int, stop byte) (int, []byte) {
 	return i, buf[start:i]
 }
 
+// scanFieldKey returns the position of the '=' ending the field key starting
+// at i and the key.  Escaped characters are skipped the same way scanFields
+// skips them, so an escaped backslash does not escape a following '='.
+func scanFieldKey(buf []byte, i int) (int, []byte) {
+	start := i
+	for i < len(buf) {
+		if buf[i] == '\\' && i+1 < len(buf) {
+			i += 2
+			continue
+		}
+		if buf[i] == '=' {
+			break
+		}
+		i++
+	}
+	return i, buf[start:i]
+}
+
 // scanTo returns the end position in buf and the next consecutive block
 // of bytes, starting from i and ending with stop byte.  If there are leading
 // spaces, they are skipped.
@@ -1169,6 +1308,10 @@ func scanTagValue(buf []byte, i int) (int, []byte) {
 }
 
 func scanFieldValue(buf []byte, i int) (int, []byte) {
+	if i > len(buf) {
+		return len(buf), nil
+	}
+
 	start := i
 	quoted := false
 	for i < len(buf) {
@@ -1289,6 +1432,37 @@ func NewPoint(name string, tags Tags, fields Fields, t time.Time) (Point, error)
 	}, nil
 }
 
+// NewPointWithFields returns a new point with the given measurement name, tags,
+// fields encoded by b and timestamp. Unlike NewPoint, the field values are
+// never boxed or collected in a map. b may be reset and reused once the point
+// is created.
+func NewPointWithFields(name string, tags Tags, b *FieldsBuilder, t time.Time) (Point, error) {
+	if b.err != nil {
+		return nil, b.err
+	} else if len(b.buf) == 0 {
+		return nil, ErrPointMustHaveAField
+	}
+
+	if !t.IsZero() {
+		if err := CheckTime(t); err != nil {
+			return nil, err
+		}
+	}
+
+	key := MakeKey([]byte(name), tags)
+	if sz := seriesKeySize(key, nil) + b.maxKeyLen; sz > MaxKeyLength {
+		return nil, fmt.Errorf("max key length exceeded: %v > %v", sz, MaxKeyLength)
+	}
+
+	fields := make([]byte, len(b.buf))
+	copy(fields, b.buf)
+	return &point{
+		key:    key,
+		time:   t,
+		fields: fields,
+	}, nil
+}
+
 // pointKey checks some basic requirements for valid points, and returns the
 // key, along with an possible error.
 func pointKey(measurement string, tags Tags, fields Fields, t time.Time) ([]byte, error) {
@@ -1503,7 +1677,10 @@ func walkFields(buf []byte, fn func(key, value []byte) bool) {
 	var i int
 	var key, val []byte
 	for len(buf) > 0 {
-		i, key = scanTo(buf, 0, '=')
+		i, key = scanFieldKey(buf, 0)
+		if i >= len(buf) {
+			break
+		}
 		buf = buf[i+1:]
 		i, val = scanFieldValue(buf, 0)
 		buf = buf[i:]
@@ -1538,6 +1715,14 @@ func MakeKey(name []byte, tags Tags) []byte {
 	return append(EscapeMeasurement(unescapeMeasurement(name)), tags.HashKey()...)
 }
 
+// CanonicalKey returns the series key that MakeKey creates from the
+// measurement and tags of p. Points parsed from line protocol keep the
+// escaping of the client in their key, so points written with a different
+// escaping of the same measurement and tags create different series.
+func CanonicalKey(p Point) []byte {
+	return MakeKey(p.Name(), p.Tags())
+}
+
 // SetTags replaces the tags for the point.
 func (p *point) SetTags(tags Tags) {
 	p.key = MakeKey(p.Name(), tags)
@@ -1568,18 +1753,8 @@ func (p *point) Fields() (Fields, error) {
 
 // SetPrecision will round a time to the specified precision.
 func (p *point) SetPrecision(precision string) {
-	switch precision {
-	case "n":
-	case "u":
-		p.SetTime(p.Time().Truncate(time.Microsecond))
-	case "ms":
-		p.SetTime(p.Time().Truncate(time.Millisecond))
-	case "s":
-		p.SetTime(p.Time().Truncate(time.Second))
-	case "m":
-		p.SetTime(p.Time().Truncate(time.Minute))
-	case "h":
-		p.SetTime(p.Time().Truncate(time.Hour))
+	if d, ok := precisionDuration(precision); ok && d > time.Nanosecond {
+		p.SetTime(p.Time().Truncate(d))
 	}
 }
 
@@ -1779,7 +1954,7 @@ func (p *point) Split(size int) []Point {
 	var start, cur int
 
 	for cur < len(p.fields) {
-		end, _ := scanTo(p.fields, cur, '=')
+		end, _ := scanFieldKey(p.fields, cur)
 		end, _ = scanFieldValue(p.fields, end+1)
 
 		if cur > start && end-start > size {
@@ -2134,7 +2309,7 @@ func (p *point) Next() bool {
 		return false
 	}
 
-	p.it.end, p.it.key = scanTo(p.fields, p.it.start, '=')
+	p.it.end, p.it.key = scanFieldKey(p.fields, p.it.start)
 	if escape.IsEscaped(p.it.key) {
 		p.it.keybuf = escape.AppendUnescaped(p.it.keybuf[:0], p.it.key)
 		p.it.key = p.it.keybuf
@@ -2185,6 +2360,9 @@ func (p *point) Type() FieldType {
 
 // StringValue returns the string value of the current field.
 func (p *point) StringValue() string {
+	if len(p.it.valueBuf) < 2 {
+		return ""
+	}
 	return unescapeStringField(string(p.it.valueBuf[1 : len(p.it.valueBuf)-1]))
 }
 
@@ -2322,6 +2500,82 @@ func appendField(b []byte, k string, v interface{}) []byte {
 	return b
 }
 
+// FieldsBuilder encodes typed field values directly into the representation
+// used by points, for use with NewPointWithFields. Each field key must only be
+// added once.
+type FieldsBuilder struct {
+	buf       []byte
+	maxKeyLen int
+	err       error
+}
+
+// AddFloat adds a float field.
+func (b *FieldsBuilder) AddFloat(key string, v float64) {
+	if math.IsNaN(v) {
+		b.setErr(fmt.Errorf("NaN is an unsupported value for field %s", key))
+		return
+	}
+	b.appendKey(key)
+	b.buf = strconv.AppendFloat(b.buf, v, 'f', -1, 64)
+}
+
+// AddInteger adds an integer field.
+func (b *FieldsBuilder) AddInteger(key string, v int64) {
+	b.appendKey(key)
+	b.buf = strconv.AppendInt(b.buf, v, 10)
+	b.buf = append(b.buf, 'i')
+}
+
+// AddUnsigned adds an unsigned field.
+func (b *FieldsBuilder) AddUnsigned(key string, v uint64) {
+	b.appendKey(key)
+	b.buf = strconv.AppendUint(b.buf, v, 10)
+	b.buf = append(b.buf, 'u')
+}
+
+// AddString adds a string field.
+func (b *FieldsBuilder) AddString(key, v string) {
+	b.appendKey(key)
+	b.buf = append(b.buf, '"')
+	b.buf = append(b.buf, EscapeStringField(v)...)
+	b.buf = append(b.buf, '"')
+}
+
+// AddBoolean adds a boolean field.
+func (b *FieldsBuilder) AddBoolean(key string, v bool) {
+	b.appendKey(key)
+	b.buf = strconv.AppendBool(b.buf, v)
+}
+
+// Reset removes all fields from the builder.
+func (b *FieldsBuilder) Reset() {
+	b.buf = b.buf[:0]
+	b.maxKeyLen = 0
+	b.err = nil
+}
+
+func (b *FieldsBuilder) appendKey(key string) {
+	if len(key) == 0 {
+		b.setErr(fmt.Errorf("all fields must have non-empty names"))
+	}
+	if len(key) > b.maxKeyLen {
+		b.maxKeyLen = len(key)
+	}
+
+	if len(b.buf) > 0 {
+		b.buf = append(b.buf, ',')
+	}
+	b.buf = append(b.buf, escape.String(key)...)
+	b.buf = append(b.buf, '=')
+}
+
+// setErr records the first invalid field added to the builder.
+func (b *FieldsBuilder) setErr(err error) {
+	if b.err == nil {
+		b.err = err
+	}
+}
+
 type byteSlices [][]byte
 
 func (a byteSlices) Len() int           { return len(a) }
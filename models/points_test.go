@@ -4,8 +4,10 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"math"
 	"math/rand"
+	"path/filepath"
 	"reflect"
 	"strconv"
 	"strings"
@@ -182,6 +184,19 @@ func BenchmarkNewPoint(b *testing.B) {
 	}
 }
 
+func BenchmarkNewPointWithFields(b *testing.B) {
+	ts := time.Now()
+	var fb models.FieldsBuilder
+	for i := 0; i < b.N; i++ {
+		fb.Reset()
+		fb.AddInteger("int", 10)
+		fb.AddFloat("float", 11.1)
+		fb.AddString("string", "foo")
+		fb.AddBoolean("bool", true)
+		p, _ = models.NewPointWithFields("measurement", tags, &fb, ts)
+	}
+}
+
 func BenchmarkNewPointFromBinary(b *testing.B) {
 	pts, err := models.ParsePointsString("cpu value1=1.0,value2=1.0,value3=3.0,value4=4,value5=\"five\" 1000000000")
 	if err != nil {
@@ -421,6 +436,69 @@ func TestParsePointNoTimestamp(t *testing.T) {
 	test(t, "cpu value=1", NewTestPoint("cpu", nil, models.Fields{"value": 1.0}, time.Unix(0, 0)))
 }
 
+// Ensure that no input in the fuzzing corpus panics the parser or the
+// accessors of the points it returns.
+func TestParsePoints_FuzzCorpus(t *testing.T) {
+	dir := filepath.Join("testdata", "fuzz", "corpus")
+	fis, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, fi := range fis {
+		buf, err := ioutil.ReadFile(filepath.Join(dir, fi.Name()))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		t.Run(fi.Name(), func(t *testing.T) {
+			points, _ := models.ParsePointsWithPrecision(buf, time.Unix(0, 0), "n")
+			for _, p := range points {
+				p.Fields()
+				p.Tags()
+				p.Split(len(buf) / 2)
+				models.ParsePointsString(p.String())
+
+				iter := p.FieldIterator()
+				for iter.Next() {
+					switch iter.Type() {
+					case models.Float:
+						iter.FloatValue()
+					case models.Integer:
+						iter.IntegerValue()
+					case models.Unsigned:
+						iter.UnsignedValue()
+					case models.String:
+						iter.StringValue()
+					case models.Boolean:
+						iter.BooleanValue()
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestParsePoint_EscapedBackslashFieldKey(t *testing.T) {
+	pts, err := models.ParsePointsString(`cpu a\\=1,b=2`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fields, err := pts[0].Fields()
+	if err != nil {
+		t.Fatal(err)
+	} else if exp := (models.Fields{`a\\`: 1.0, "b": 2.0}); !reflect.DeepEqual(fields, exp) {
+		t.Fatalf("unexpected fields: %v", fields)
+	}
+}
+
+func TestParsePoint_TextAfterClosingQuote(t *testing.T) {
+	if _, err := models.ParsePointsString(`cpu value="a"b`); err == nil || !strings.Contains(err.Error(), "invalid field format") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
 func TestParsePointMissingQuote(t *testing.T) {
 	expectedSuffix := "unbalanced quotes"
 	examples := []string{
@@ -1738,6 +1816,12 @@ func TestParsePointsWithPrecision(t *testing.T) {
 			precision: "u",
 			exp:       "cpu,host=serverA,region=us-east value=1.0 946730096789012000",
 		},
+		{
+			name:      "microsecond alias",
+			line:      `cpu,host=serverA,region=us-east value=1.0 946730096789012`,
+			precision: "us",
+			exp:       "cpu,host=serverA,region=us-east value=1.0 946730096789012000",
+		},
 		{
 			name:      "millisecond",
 			line:      `cpu,host=serverA,region=us-east value=1.0 946730096789`,
@@ -1780,6 +1864,19 @@ func TestParsePointsWithPrecision(t *testing.T) {
 	}
 }
 
+func TestValidatePrecision(t *testing.T) {
+	for _, precision := range []string{"", "n", "ns", "u", "us", "µs", "ms", "s", "m", "h"} {
+		if err := models.ValidatePrecision(precision); err != nil {
+			t.Errorf("%q: unexpected error: %s", precision, err)
+		}
+	}
+	for _, precision := range []string{"x", "sec", "nanoseconds", "H"} {
+		if err := models.ValidatePrecision(precision); err == nil {
+			t.Errorf("%q: expected error", precision)
+		}
+	}
+}
+
 func TestParsePointsWithPrecisionNoTime(t *testing.T) {
 	line := `cpu,host=serverA,region=us-east value=1.0`
 	tm, _ := time.Parse(time.RFC3339Nano, "2000-01-01T12:34:56.789012345Z")
@@ -1842,6 +1939,52 @@ func TestParsePointsWithPrecisionNoTime(t *testing.T) {
 	}
 }
 
+// Ensure points for the same series without timestamps do not share a time.
+func TestParsePointsWithPrecisionNoTime_SameSeries(t *testing.T) {
+	tm, _ := time.Parse(time.RFC3339Nano, "2000-01-01T12:34:56Z")
+	pts, err := models.ParsePointsWithPrecision([]byte("cpu,host=a value=1\ncpu,host=b value=2\ncpu,host=a value=3\ncpu,host=a value=4 946730096\ncpu,host=a value=5"), tm, "s")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The offsets are in seconds and skip the time of value=4.
+	exp := []string{
+		"cpu,host=a value=1 946730097000000000",
+		"cpu,host=b value=2 946730096000000000",
+		"cpu,host=a value=3 946730098000000000",
+		"cpu,host=a value=4 946730096000000000",
+		"cpu,host=a value=5 946730099000000000",
+	}
+	if len(pts) != len(exp) {
+		t.Fatalf("unexpected number of points: %d", len(pts))
+	}
+	for i, pt := range pts {
+		if got := pt.String(); got != exp[i] {
+			t.Errorf("%d: unexpected point:\n got %v\n exp %v", i, got, exp[i])
+		}
+	}
+}
+
+func TestParsePointsWithLineErrors(t *testing.T) {
+	buf := "# comment\ncpu value=1\ncpu value=\"multi\nline\"\ncpu value=\n\ncpu value=2 x\ncpu value=3"
+	pts, lerrs := models.ParsePointsWithLineErrors([]byte(buf), time.Now().UTC(), "n")
+	if len(pts) != 3 {
+		t.Fatalf("unexpected number of points: %d", len(pts))
+	}
+
+	if len(lerrs) != 2 {
+		t.Fatalf("unexpected number of line errors: %d", len(lerrs))
+	}
+	for i, exp := range []models.LineError{
+		{Line: 5, Text: "cpu value="},
+		{Line: 7, Text: "cpu value=2 x"},
+	} {
+		if lerrs[i].Line != exp.Line || lerrs[i].Text != exp.Text || lerrs[i].Err == nil {
+			t.Errorf("%d: unexpected line error: %+v", i, lerrs[i])
+		}
+	}
+}
+
 func TestParsePointsWithPrecisionComments(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -1925,6 +2068,50 @@ func TestNewPointWithoutField(t *testing.T) {
 	}
 }
 
+func TestNewPointWithFields(t *testing.T) {
+	var fb models.FieldsBuilder
+	fb.AddFloat("value", 1.5)
+	fb.AddInteger("n", -2)
+	fb.AddUnsigned("u", 3)
+	fb.AddString("name bar", `a"b`)
+	fb.AddBoolean("ok", true)
+
+	pt, err := models.NewPointWithFields("cpu", models.NewTags(map[string]string{"host": "a"}), &fb, time.Unix(0, 0))
+	if err != nil {
+		t.Fatal(err)
+	} else if exp := `cpu,host=a value=1.5,n=-2i,u=3u,name\ bar="a\"b",ok=true 0`; pt.String() != exp {
+		t.Errorf("NewPointWithFields().String() mismatch.\ngot %v\nexp %v", pt.String(), exp)
+	}
+
+	// The builder can be reused without changing the point.
+	fb.Reset()
+	fb.AddFloat("value", 2)
+	if fields, err := pt.Fields(); err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(fields, models.Fields{"value": 1.5, "n": int64(-2), "u": uint64(3), "name bar": `a"b`, "ok": true}) {
+		t.Errorf("unexpected fields: %v", fields)
+	}
+
+	iter := pt.FieldIterator()
+	if !models.FindField(iter, []byte("n")) {
+		t.Fatal("expected field n")
+	} else if v, err := iter.IntegerValue(); err != nil || v != -2 {
+		t.Fatalf("unexpected value: %v (%v)", v, err)
+	} else if models.FindField(iter, []byte("missing")) {
+		t.Fatal("unexpected field")
+	}
+
+	// Invalid fields are reported when the point is created.
+	fb.Reset()
+	if _, err := models.NewPointWithFields("cpu", nil, &fb, time.Unix(0, 0)); err != models.ErrPointMustHaveAField {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	fb.AddFloat("value", math.NaN())
+	if _, err := models.NewPointWithFields("cpu", nil, &fb, time.Unix(0, 0)); err == nil || err.Error() != "NaN is an unsupported value for field value" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
 func TestNewPointUnhandledType(t *testing.T) {
 	// nil value
 	pt := models.MustNewPoint("cpu", nil, models.Fields{"value": nil}, time.Unix(0, 0))
@@ -1967,6 +2154,28 @@ func TestMakeKeyEscaped(t *testing.T) {
 
 }
 
+func TestCanonicalKey(t *testing.T) {
+	for _, tt := range []struct {
+		line string
+		exp  string
+	}{
+		{line: `cpu,host=a,region=b value=1`, exp: `cpu,host=a,region=b`},
+		{line: `cpu,region=b,host=a value=1`, exp: `cpu,host=a,region=b`},
+		{line: `cpu,host=a\ b value=1`, exp: `cpu,host=a\ b`},
+		{line: `cpu,host=a\=b value=1`, exp: `cpu,host=a\=b`},
+		{line: `c\=pu,host=a value=1`, exp: `c=pu,host=a`},
+		{line: `c\"pu,host=a value=1`, exp: `c"pu,host=a`},
+	} {
+		p, err := models.ParsePointsString(tt.line)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := string(models.CanonicalKey(p[0])); got != tt.exp {
+			t.Errorf("%s: unexpected canonical key: got %s, exp %s", tt.line, got, tt.exp)
+		}
+	}
+}
+
 func TestPrecisionString(t *testing.T) {
 	tags := map[string]interface{}{"value": float64(1)}
 	tm, _ := time.Parse(time.RFC3339Nano, "2000-01-01T12:34:56.789012345Z")
@@ -0,0 +1,195 @@
+// Package bindaddr parses the bind addresses of listeners and listens on
+// several of them at once.
+//
+// A bind address setting holds one or more addresses separated by commas,
+// such as "127.0.0.1:8086,[::1]:8086". The host of an address may be the
+// name of a network interface, such as "eth0:8086", which binds to every
+// address of that interface.
+package bindaddr
+
+import (
+	"errors"
+	"net"
+	"strings"
+	"sync"
+)
+
+// Split returns the addresses of a bind address setting.
+func Split(s string) []string {
+	var addrs []string
+	for _, addr := range strings.Split(s, ",") {
+		if addr = strings.TrimSpace(addr); addr != "" {
+			addrs = append(addrs, addr)
+		}
+	}
+	return addrs
+}
+
+// Parse returns the addresses of a bind address setting with the names of
+// network interfaces replaced by their addresses.
+func Parse(s string) ([]string, error) {
+	var addrs []string
+	for _, addr := range Split(s) {
+		a, err := expand(addr)
+		if err != nil {
+			return nil, err
+		}
+		addrs = append(addrs, a...)
+	}
+	if len(addrs) == 0 {
+		return nil, errors.New("bind address must be specified")
+	}
+	return addrs, nil
+}
+
+// expand returns the addresses of the interface named by the host of addr,
+// or addr itself if its host is not the name of an interface.
+func expand(addr string) ([]string, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil || host == "" || net.ParseIP(host) != nil {
+		return []string{addr}, nil
+	}
+
+	ifi, err := net.InterfaceByName(host)
+	if err != nil {
+		return []string{addr}, nil // a host name
+	}
+
+	ifaddrs, err := ifi.Addrs()
+	if err != nil {
+		return nil, err
+	}
+
+	var addrs []string
+	for _, a := range ifaddrs {
+		ipnet, ok := a.(*net.IPNet)
+		if !ok {
+			continue
+		}
+		ip := ipnet.IP.String()
+		if ipnet.IP.To4() == nil && ipnet.IP.IsLinkLocalUnicast() {
+			ip += "%" + ifi.Name
+		}
+		addrs = append(addrs, net.JoinHostPort(ip, port))
+	}
+	if len(addrs) == 0 {
+		return nil, errors.New("no addresses on interface " + ifi.Name)
+	}
+	return addrs, nil
+}
+
+// Listen listens on every address of a bind address setting and returns a
+// listener that accepts the connections of all of them. Its Addr is the
+// address of the first listener.
+func Listen(network, s string) (net.Listener, error) {
+	addrs, err := Parse(s)
+	if err != nil {
+		return nil, err
+	}
+
+	lns := make([]net.Listener, 0, len(addrs))
+	for _, addr := range addrs {
+		ln, err := net.Listen(network, addr)
+		if err != nil {
+			for _, ln := range lns {
+				ln.Close()
+			}
+			return nil, err
+		}
+		lns = append(lns, ln)
+	}
+	return Merge(lns), nil
+}
+
+// Merge returns a listener that accepts the connections of all of lns. Its
+// Addr is the address of the first listener. Merge returns lns[0] if it is
+// the only listener.
+func Merge(lns []net.Listener) net.Listener {
+	if len(lns) == 1 {
+		return lns[0]
+	}
+	return newMultiListener(lns)
+}
+
+// errClosed is returned by Accept once a multiListener is closed.
+var errClosed = errors.New("use of closed network connection")
+
+type acceptResult struct {
+	conn net.Conn
+	err  error
+}
+
+// multiListener accepts the connections of several listeners.
+type multiListener struct {
+	lns     []net.Listener
+	c       chan acceptResult
+	closing chan struct{}
+	once    sync.Once
+	wg      sync.WaitGroup
+}
+
+func newMultiListener(lns []net.Listener) *multiListener {
+	l := &multiListener{
+		lns:     lns,
+		c:       make(chan acceptResult),
+		closing: make(chan struct{}),
+	}
+	for _, ln := range lns {
+		l.wg.Add(1)
+		go l.accept(ln)
+	}
+	return l
+}
+
+// accept sends the connections and errors of ln to Accept until ln fails
+// with an error that is not temporary.
+func (l *multiListener) accept(ln net.Listener) {
+	defer l.wg.Done()
+	for {
+		conn, err := ln.Accept()
+		select {
+		case l.c <- acceptResult{conn: conn, err: err}:
+		case <-l.closing:
+			if conn != nil {
+				conn.Close()
+			}
+			return
+		}
+
+		if err != nil {
+			if e, ok := err.(interface {
+				Temporary() bool
+			}); !ok || !e.Temporary() {
+				return
+			}
+		}
+	}
+}
+
+// Accept waits for and returns the next connection of any of the listeners.
+func (l *multiListener) Accept() (net.Conn, error) {
+	select {
+	case r := <-l.c:
+		return r.conn, r.err
+	case <-l.closing:
+		return nil, &net.OpError{Op: "accept", Net: l.Addr().Network(), Addr: l.Addr(), Err: errClosed}
+	}
+}
+
+// Close closes all of the listeners.
+func (l *multiListener) Close() error {
+	var err error
+	l.once.Do(func() {
+		close(l.closing)
+		for _, ln := range l.lns {
+			if e := ln.Close(); e != nil && err == nil {
+				err = e
+			}
+		}
+		l.wg.Wait()
+	})
+	return err
+}
+
+// Addr returns the address of the first listener.
+func (l *multiListener) Addr() net.Addr { return l.lns[0].Addr() }
@@ -0,0 +1,101 @@
+package bindaddr
+
+import (
+	"net"
+	"reflect"
+	"testing"
+)
+
+func TestSplit(t *testing.T) {
+	if got, exp := Split(" 127.0.0.1:8086, [::1]:8086,,"), []string{"127.0.0.1:8086", "[::1]:8086"}; !reflect.DeepEqual(got, exp) {
+		t.Fatalf("unexpected addresses: got %v, exp %v", got, exp)
+	}
+}
+
+func TestParse(t *testing.T) {
+	if _, err := Parse(" , "); err == nil {
+		t.Fatal("expected error for empty setting")
+	}
+
+	got, err := Parse(":8086,localhost:8086,[::1]:8086")
+	if err != nil {
+		t.Fatal(err)
+	} else if exp := []string{":8086", "localhost:8086", "[::1]:8086"}; !reflect.DeepEqual(got, exp) {
+		t.Fatalf("unexpected addresses: got %v, exp %v", got, exp)
+	}
+}
+
+func TestParse_Interface(t *testing.T) {
+	ifis, err := net.Interfaces()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var lo *net.Interface
+	for i := range ifis {
+		if ifis[i].Flags&net.FlagLoopback != 0 {
+			lo = &ifis[i]
+			break
+		}
+	}
+	if lo == nil {
+		t.Skip("no loopback interface")
+	}
+
+	addrs, err := Parse(lo.Name + ":8086")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var found bool
+	for _, addr := range addrs {
+		if addr == "127.0.0.1:8086" {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("expected 127.0.0.1:8086 in %v", addrs)
+	}
+}
+
+func TestListen_Multiple(t *testing.T) {
+	ln, err := Listen("tcp", "127.0.0.1:0,127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	ml, ok := ln.(*multiListener)
+	if !ok {
+		t.Fatalf("unexpected listener type: %T", ln)
+	} else if ln.Addr() != ml.lns[0].Addr() {
+		t.Fatalf("unexpected address: %s", ln.Addr())
+	}
+
+	// Connections to each address are accepted.
+	for _, l := range ml.lns {
+		conn, err := net.Dial("tcp", l.Addr().String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer conn.Close()
+
+		accepted, err := ln.Accept()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if accepted.LocalAddr().String() != l.Addr().String() {
+			t.Fatalf("unexpected local address: got %s, exp %s", accepted.LocalAddr(), l.Addr())
+		}
+		accepted.Close()
+	}
+
+	if err := ln.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ln.Accept(); err == nil {
+		t.Fatal("expected error after close")
+	} else if e, ok := err.(net.Error); !ok || e.Temporary() {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
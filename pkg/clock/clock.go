@@ -0,0 +1,263 @@
+// Package clock provides the current time, timers and tickers to services so
+// that tests can control the passing of time instead of sleeping.
+package clock
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Clock represents the passing of time.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+
+	// After waits for d to elapse and then sends the current time on the
+	// returned channel.
+	After(d time.Duration) <-chan time.Time
+
+	// NewTimer creates a Timer that sends the current time on its channel
+	// after at least d has elapsed.
+	NewTimer(d time.Duration) *Timer
+
+	// NewTicker returns a Ticker that sends the current time on its channel
+	// every d.
+	NewTicker(d time.Duration) *Ticker
+}
+
+// New returns a Clock backed by the time package.
+func New() Clock { return realClock{} }
+
+type realClock struct{}
+
+func (realClock) Now() time.Time                         { return time.Now() }
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+func (realClock) NewTimer(d time.Duration) *Timer {
+	t := time.NewTimer(d)
+	return &Timer{C: t.C, timer: t}
+}
+
+func (realClock) NewTicker(d time.Duration) *Ticker {
+	t := time.NewTicker(d)
+	return &Ticker{C: t.C, ticker: t}
+}
+
+// Timer represents a single event, like time.Timer.
+type Timer struct {
+	C <-chan time.Time
+
+	timer *time.Timer // set by the real clock
+
+	// Set by a Mock.
+	c    chan time.Time
+	next time.Time
+	mock *Mock
+}
+
+// Stop prevents the Timer from firing. It returns false if the timer has
+// already expired or been stopped.
+func (t *Timer) Stop() bool {
+	if t.timer != nil {
+		return t.timer.Stop()
+	}
+	return t.mock.removeTimer(t)
+}
+
+// Reset changes the timer to expire after d. It returns true if the timer
+// had been active.
+func (t *Timer) Reset(d time.Duration) bool {
+	if t.timer != nil {
+		return t.timer.Reset(d)
+	}
+	active := t.mock.removeTimer(t)
+	t.mock.addTimer(t, d)
+	return active
+}
+
+// Ticker delivers ticks at intervals, like time.Ticker.
+type Ticker struct {
+	C <-chan time.Time
+
+	ticker *time.Ticker // set by the real clock
+
+	// Set by a Mock.
+	c    chan time.Time
+	d    time.Duration
+	next time.Time
+	mock *Mock
+}
+
+// Stop turns off the ticker.
+func (t *Ticker) Stop() {
+	if t.ticker != nil {
+		t.ticker.Stop()
+		return
+	}
+	t.mock.removeTicker(t)
+}
+
+// Mock is a Clock whose time only moves when Add or Set is called. Timers and
+// tickers fire in order of their deadlines as the time moves past them. Like
+// the time package, a timer or ticker drops a tick if its channel is full.
+type Mock struct {
+	mu      sync.Mutex
+	cond    *sync.Cond // signaled when a timer or ticker is added
+	now     time.Time
+	timers  []*Timer
+	tickers []*Ticker
+}
+
+// NewMock returns a Mock set to now.
+func NewMock(now time.Time) *Mock {
+	m := &Mock{now: now}
+	m.cond = sync.NewCond(&m.mu)
+	return m
+}
+
+// BlockUntil waits until at least n timers and tickers are waiting for the
+// time to move. Tests call it before Add to make sure the goroutine under
+// test is waiting on the mock.
+func (m *Mock) BlockUntil(n int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for len(m.timers)+len(m.tickers) < n {
+		m.cond.Wait()
+	}
+}
+
+// Now returns the current time of the mock.
+func (m *Mock) Now() time.Time {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.now
+}
+
+// After returns a channel that receives the time once d has been added to
+// the mock.
+func (m *Mock) After(d time.Duration) <-chan time.Time {
+	return m.NewTimer(d).C
+}
+
+// NewTimer creates a Timer that fires once d has been added to the mock.
+func (m *Mock) NewTimer(d time.Duration) *Timer {
+	c := make(chan time.Time, 1)
+	t := &Timer{C: c, c: c, mock: m}
+	m.addTimer(t, d)
+	return t
+}
+
+// NewTicker returns a Ticker that fires each time d is added to the mock.
+func (m *Mock) NewTicker(d time.Duration) *Ticker {
+	if d <= 0 {
+		panic("non-positive interval for NewTicker")
+	}
+
+	c := make(chan time.Time, 1)
+	t := &Ticker{C: c, c: c, d: d, mock: m}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	t.next = m.now.Add(d)
+	m.tickers = append(m.tickers, t)
+	m.cond.Broadcast()
+	return t
+}
+
+// Add moves the time of the mock forward by d, firing the timers and tickers
+// whose deadlines are passed on the way.
+func (m *Mock) Add(d time.Duration) {
+	m.Set(m.Now().Add(d))
+}
+
+// Set moves the time of the mock to t, firing the timers and tickers whose
+// deadlines are passed on the way. Setting an earlier time fires nothing.
+func (m *Mock) Set(t time.Time) {
+	for m.fireNext(t) {
+	}
+
+	m.mu.Lock()
+	m.now = t
+	m.mu.Unlock()
+}
+
+// fireNext fires the earliest timer or ticker due at or before t and moves
+// the time to its deadline. It returns false if nothing is due.
+func (m *Mock) fireNext(t time.Time) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	sort.SliceStable(m.timers, func(i, j int) bool { return m.timers[i].next.Before(m.timers[j].next) })
+	sort.SliceStable(m.tickers, func(i, j int) bool { return m.tickers[i].next.Before(m.tickers[j].next) })
+
+	var timer *Timer
+	var ticker *Ticker
+	if len(m.timers) > 0 && !m.timers[0].next.After(t) {
+		timer = m.timers[0]
+	}
+	if len(m.tickers) > 0 && !m.tickers[0].next.After(t) {
+		if timer == nil || m.tickers[0].next.Before(timer.next) {
+			timer, ticker = nil, m.tickers[0]
+		}
+	}
+
+	switch {
+	case timer != nil:
+		m.timers = m.timers[1:]
+		m.now = timer.next
+		send(timer.c, m.now)
+	case ticker != nil:
+		m.now = ticker.next
+		ticker.next = ticker.next.Add(ticker.d)
+		send(ticker.c, m.now)
+	default:
+		return false
+	}
+	return true
+}
+
+// addTimer schedules t to fire after d. Like a timer of the time package,
+// it fires immediately if d is not positive.
+func (m *Mock) addTimer(t *Timer, d time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	t.next = m.now.Add(d)
+	if d <= 0 {
+		send(t.c, m.now)
+		return
+	}
+	m.timers = append(m.timers, t)
+	m.cond.Broadcast()
+}
+
+func (m *Mock) removeTimer(t *Timer) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i := range m.timers {
+		if m.timers[i] == t {
+			m.timers = append(m.timers[:i], m.timers[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+func (m *Mock) removeTicker(t *Ticker) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i := range m.tickers {
+		if m.tickers[i] == t {
+			m.tickers = append(m.tickers[:i], m.tickers[i+1:]...)
+			return
+		}
+	}
+}
+
+// send sends now on c unless c is full.
+func send(c chan time.Time, now time.Time) {
+	select {
+	case c <- now:
+	default:
+	}
+}
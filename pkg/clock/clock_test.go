@@ -0,0 +1,72 @@
+package clock_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/pkg/clock"
+)
+
+// Ensure the timers and tickers of a mock fire in order as time is added.
+func TestMock_Add(t *testing.T) {
+	start := time.Unix(0, 0).UTC()
+	m := clock.NewMock(start)
+
+	timer := m.NewTimer(90 * time.Second)
+	ticker := m.NewTicker(time.Minute)
+	after := m.After(time.Hour)
+
+	m.Add(30 * time.Second)
+	select {
+	case <-timer.C:
+		t.Fatal("unexpected timer")
+	case <-ticker.C:
+		t.Fatal("unexpected tick")
+	default:
+	}
+
+	m.Add(30 * time.Second)
+	if now := <-ticker.C; !now.Equal(start.Add(time.Minute)) {
+		t.Fatalf("unexpected tick: %s", now)
+	}
+
+	// The timer fires at its deadline even though the time moves past it.
+	m.Add(time.Minute)
+	if now := <-timer.C; !now.Equal(start.Add(90 * time.Second)) {
+		t.Fatalf("unexpected timer: %s", now)
+	} else if now := <-ticker.C; !now.Equal(start.Add(2 * time.Minute)) {
+		t.Fatalf("unexpected tick: %s", now)
+	} else if now := m.Now(); !now.Equal(start.Add(2 * time.Minute)) {
+		t.Fatalf("unexpected now: %s", now)
+	}
+
+	// A stopped ticker does not fire and a reset timer fires again.
+	ticker.Stop()
+	if timer.Reset(time.Minute) {
+		t.Fatal("expected expired timer")
+	}
+	m.Set(start.Add(time.Hour))
+	if now := <-timer.C; !now.Equal(start.Add(3 * time.Minute)) {
+		t.Fatalf("unexpected timer: %s", now)
+	} else if now := <-after; !now.Equal(start.Add(time.Hour)) {
+		t.Fatalf("unexpected after: %s", now)
+	}
+	select {
+	case <-ticker.C:
+		t.Fatal("unexpected tick after stop")
+	default:
+	}
+}
+
+// Ensure BlockUntil returns once a goroutine waits on the mock.
+func TestMock_BlockUntil(t *testing.T) {
+	m := clock.NewMock(time.Unix(0, 0))
+	done := make(chan time.Time)
+	go func() { done <- <-m.After(time.Second) }()
+
+	m.BlockUntil(1)
+	m.Add(time.Second)
+	if now := <-done; !now.Equal(time.Unix(1, 0)) {
+		t.Fatalf("unexpected time: %s", now)
+	}
+}
@@ -0,0 +1,130 @@
+// Package ipfilter restricts the source addresses that listeners accept
+// traffic from.
+package ipfilter
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// Filter accepts or rejects source addresses by the networks they are in.
+// A nil Filter accepts every address.
+type Filter struct {
+	allow []*net.IPNet
+	deny  []*net.IPNet
+}
+
+// New returns a Filter from lists of networks in CIDR notation, such as
+// "10.0.0.0/8", or single addresses. An address is accepted if it is in one
+// of the allowed networks, or allow is empty, and in none of the denied
+// networks. New returns nil if both lists are empty.
+func New(allow, deny []string) (*Filter, error) {
+	if len(allow) == 0 && len(deny) == 0 {
+		return nil, nil
+	}
+
+	var f Filter
+	var err error
+	if f.allow, err = parseNetworks(allow); err != nil {
+		return nil, err
+	}
+	if f.deny, err = parseNetworks(deny); err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
+
+func parseNetworks(a []string) ([]*net.IPNet, error) {
+	networks := make([]*net.IPNet, 0, len(a))
+	for _, s := range a {
+		if !strings.Contains(s, "/") {
+			ip := net.ParseIP(s)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid network: %q", s)
+			}
+			bits := 8 * net.IPv6len
+			if ip4 := ip.To4(); ip4 != nil {
+				ip, bits = ip4, 8*net.IPv4len
+			}
+			networks = append(networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+
+		_, n, err := net.ParseCIDR(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid network: %q", s)
+		}
+		networks = append(networks, n)
+	}
+	return networks, nil
+}
+
+// Allowed returns true if traffic from ip is accepted.
+func (f *Filter) Allowed(ip net.IP) bool {
+	if f == nil {
+		return true
+	}
+
+	for _, n := range f.deny {
+		if n.Contains(ip) {
+			return false
+		}
+	}
+	if len(f.allow) == 0 {
+		return true
+	}
+	for _, n := range f.allow {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowedAddr returns true if traffic from addr is accepted. Addresses that
+// are not TCP, UDP or IP addresses are always accepted.
+func (f *Filter) AllowedAddr(addr net.Addr) bool {
+	if f == nil {
+		return true
+	}
+
+	switch addr := addr.(type) {
+	case *net.TCPAddr:
+		return f.Allowed(addr.IP)
+	case *net.UDPAddr:
+		return f.Allowed(addr.IP)
+	case *net.IPAddr:
+		return f.Allowed(addr.IP)
+	default:
+		return true
+	}
+}
+
+// Listener returns a listener that closes the connections that the filter
+// rejects as soon as they are accepted. It returns ln if f is nil.
+func (f *Filter) Listener(ln net.Listener) net.Listener {
+	if f == nil {
+		return ln
+	}
+	return &listener{Listener: ln, filter: f}
+}
+
+type listener struct {
+	net.Listener
+	filter *Filter
+}
+
+// Accept waits for and returns the next connection from an accepted address.
+func (l *listener) Accept() (net.Conn, error) {
+	for {
+		conn, err := l.Listener.Accept()
+		if err != nil {
+			return nil, err
+		}
+		if l.filter.AllowedAddr(conn.RemoteAddr()) {
+			return conn, nil
+		}
+		conn.Close()
+	}
+}
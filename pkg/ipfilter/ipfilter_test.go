@@ -0,0 +1,103 @@
+package ipfilter_test
+
+import (
+	"net"
+	"testing"
+
+	"github.com/influxdata/influxdb/pkg/ipfilter"
+)
+
+func TestFilter_Allowed(t *testing.T) {
+	f, err := ipfilter.New([]string{"10.0.0.0/8", "192.168.1.5", "fd00::/8"}, []string{"10.1.0.0/16"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		ip  string
+		exp bool
+	}{
+		{ip: "10.0.0.1", exp: true},
+		{ip: "10.1.2.3", exp: false},
+		{ip: "192.168.1.5", exp: true},
+		{ip: "192.168.1.6", exp: false},
+		{ip: "::ffff:10.0.0.1", exp: true},
+		{ip: "fd00::1", exp: true},
+		{ip: "fe80::1", exp: false},
+	} {
+		if got := f.Allowed(net.ParseIP(tt.ip)); got != tt.exp {
+			t.Errorf("%s: got %v, exp %v", tt.ip, got, tt.exp)
+		}
+	}
+}
+
+func TestFilter_DenyOnly(t *testing.T) {
+	f, err := ipfilter.New(nil, []string{"127.0.0.1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if f.Allowed(net.ParseIP("127.0.0.1")) {
+		t.Error("expected denied address to be rejected")
+	}
+	if !f.Allowed(net.ParseIP("127.0.0.2")) {
+		t.Error("expected other address to be accepted")
+	}
+}
+
+func TestFilter_Empty(t *testing.T) {
+	f, err := ipfilter.New(nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	} else if f != nil {
+		t.Fatalf("expected nil filter, got %#v", f)
+	}
+
+	if !f.Allowed(net.ParseIP("127.0.0.1")) {
+		t.Error("expected nil filter to accept every address")
+	}
+}
+
+func TestNew_Invalid(t *testing.T) {
+	if _, err := ipfilter.New([]string{"10.0.0.0/33"}, nil); err == nil {
+		t.Error("expected error for invalid network")
+	}
+	if _, err := ipfilter.New(nil, []string{"localhost"}); err == nil {
+		t.Error("expected error for invalid address")
+	}
+}
+
+func TestFilter_Listener(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	f, err := ipfilter.New(nil, []string{"127.0.0.0/8"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ln = f.Listener(ln)
+
+	conn, err := net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	// The rejected connection is closed by the listener, which keeps waiting
+	// until it is closed itself.
+	done := make(chan error)
+	go func() {
+		_, err := ln.Accept()
+		done <- err
+	}()
+	if _, err := conn.Read(make([]byte, 1)); err == nil {
+		t.Fatal("expected rejected connection to be closed")
+	}
+	ln.Close()
+	if err := <-done; err == nil {
+		t.Fatal("expected accept error after close")
+	}
+}
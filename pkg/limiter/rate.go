@@ -0,0 +1,91 @@
+package limiter
+
+import (
+	"sync"
+	"time"
+)
+
+// Rate is a token bucket throughput limiter shared by multiple callers.  Tokens
+// are typically bytes.  The bucket refills at limit tokens per second and holds at
+// most burst tokens.  A nil Rate does not limit callers.
+type Rate struct {
+	mu     sync.Mutex
+	limit  float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// NewRate returns a Rate that admits limit tokens per second with bursts of up
+// to burst tokens.  A burst less than 1 defaults to limit.
+func NewRate(limit, burst int) *Rate {
+	if burst < 1 {
+		burst = limit
+	}
+	return &Rate{
+		limit:  float64(limit),
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// WaitN takes n tokens and blocks until the rate allows them to be used.
+// Requests larger than the burst are admitted by waiting until the bucket has
+// refilled the difference.
+func (r *Rate) WaitN(n int) {
+	if d := r.reserve(n); d > 0 {
+		time.Sleep(d)
+	}
+}
+
+// AllowN takes n tokens and returns true if the rate allows them to be used
+// now.  Otherwise no tokens are taken.  Requests larger than the burst are
+// admitted when the bucket is full and leave it in debt until it refills.
+func (r *Rate) AllowN(n int) bool {
+	if r == nil || n <= 0 {
+		return true
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.refill(time.Now())
+	need := float64(n)
+	if need > r.burst {
+		need = r.burst
+	}
+	if r.tokens < need {
+		return false
+	}
+	r.tokens -= float64(n)
+	return true
+}
+
+// refill adds the tokens accumulated since the last call.
+func (r *Rate) refill(now time.Time) {
+	r.tokens += now.Sub(r.last).Seconds() * r.limit
+	if r.tokens > r.burst {
+		r.tokens = r.burst
+	}
+	r.last = now
+}
+
+// reserve takes n tokens from the bucket and returns how long the caller has
+// to wait before using them.
+func (r *Rate) reserve(n int) time.Duration {
+	if r == nil || n <= 0 {
+		return 0
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.refill(time.Now())
+
+	r.tokens -= float64(n)
+	if r.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-r.tokens / r.limit * float64(time.Second))
+}
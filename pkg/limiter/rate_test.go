@@ -0,0 +1,52 @@
+package limiter_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/pkg/limiter"
+)
+
+func TestRate_WaitN(t *testing.T) {
+	r := limiter.NewRate(1000, 100)
+
+	// The first burst is admitted immediately.
+	start := time.Now()
+	r.WaitN(100)
+	if d := time.Since(start); d > 50*time.Millisecond {
+		t.Fatalf("unexpected wait for burst: %s", d)
+	}
+
+	// Exceeding the burst waits for the bucket to refill.
+	start = time.Now()
+	r.WaitN(100)
+	if d := time.Since(start); d < 50*time.Millisecond {
+		t.Fatalf("expected wait for refill, waited %s", d)
+	}
+}
+
+func TestRate_AllowN(t *testing.T) {
+	r := limiter.NewRate(10, 0)
+
+	if !r.AllowN(10) {
+		t.Fatal("expected burst to be allowed")
+	} else if r.AllowN(5) {
+		t.Fatal("expected empty bucket to refuse tokens")
+	}
+
+	// Requests larger than the burst are allowed by a full bucket.
+	r = limiter.NewRate(10, 0)
+	if !r.AllowN(100) {
+		t.Fatal("expected large request to be allowed")
+	} else if r.AllowN(1) {
+		t.Fatal("expected bucket in debt to refuse tokens")
+	}
+}
+
+func TestRate_Nil(t *testing.T) {
+	var r *limiter.Rate
+	r.WaitN(1 << 30)
+	if !r.AllowN(1 << 30) {
+		t.Fatal("expected nil rate to allow tokens")
+	}
+}
@@ -0,0 +1,117 @@
+package parquet
+
+import (
+	"encoding/binary"
+)
+
+// Field types of the Thrift compact protocol.
+const (
+	compactBooleanTrue  = 1
+	compactBooleanFalse = 2
+	compactByte         = 3
+	compactI32          = 5
+	compactI64          = 6
+	compactBinary       = 8
+	compactList         = 9
+	compactStruct       = 12
+)
+
+// thriftWriter encodes the Thrift structures of the Parquet metadata using
+// the compact protocol. Fields must be written in the order of their ids.
+type thriftWriter struct {
+	buf    []byte
+	lastID int16
+	stack  []int16
+}
+
+func (t *thriftWriter) fieldHeader(id int16, typ byte) {
+	if delta := id - t.lastID; delta > 0 && delta <= 15 {
+		t.buf = append(t.buf, byte(delta)<<4|typ)
+	} else {
+		t.buf = append(t.buf, typ)
+		t.varint(int64(id))
+	}
+	t.lastID = id
+}
+
+func (t *thriftWriter) varint(v int64) {
+	t.uvarint(uint64(v<<1) ^ uint64(v>>63))
+}
+
+func (t *thriftWriter) uvarint(v uint64) {
+	var b [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(b[:], v)
+	t.buf = append(t.buf, b[:n]...)
+}
+
+func (t *thriftWriter) writeBool(id int16, v bool) {
+	if v {
+		t.fieldHeader(id, compactBooleanTrue)
+	} else {
+		t.fieldHeader(id, compactBooleanFalse)
+	}
+}
+
+func (t *thriftWriter) writeByte(id int16, v int8) {
+	t.fieldHeader(id, compactByte)
+	t.buf = append(t.buf, byte(v))
+}
+
+func (t *thriftWriter) writeI32(id int16, v int32) {
+	t.fieldHeader(id, compactI32)
+	t.varint(int64(v))
+}
+
+func (t *thriftWriter) writeI64(id int16, v int64) {
+	t.fieldHeader(id, compactI64)
+	t.varint(v)
+}
+
+func (t *thriftWriter) writeString(id int16, v string) {
+	t.fieldHeader(id, compactBinary)
+	t.uvarint(uint64(len(v)))
+	t.buf = append(t.buf, v...)
+}
+
+// writeListHeader starts a list field of n elements of type typ. The
+// elements are written with the methods that have no field id.
+func (t *thriftWriter) writeListHeader(id int16, typ byte, n int) {
+	t.fieldHeader(id, compactList)
+	if n < 15 {
+		t.buf = append(t.buf, byte(n)<<4|typ)
+	} else {
+		t.buf = append(t.buf, 0xf0|typ)
+		t.uvarint(uint64(n))
+	}
+}
+
+func (t *thriftWriter) i32Elem(v int32) { t.varint(int64(v)) }
+
+func (t *thriftWriter) stringElem(v string) {
+	t.uvarint(uint64(len(v)))
+	t.buf = append(t.buf, v...)
+}
+
+// beginStruct starts a struct field. Every struct is ended with endStruct.
+func (t *thriftWriter) beginStruct(id int16) {
+	t.fieldHeader(id, compactStruct)
+	t.beginStructElem()
+}
+
+// beginStructElem starts a struct that is an element of a list.
+func (t *thriftWriter) beginStructElem() {
+	t.stack = append(t.stack, t.lastID)
+	t.lastID = 0
+}
+
+func (t *thriftWriter) endStruct() {
+	t.buf = append(t.buf, 0)
+	t.lastID, t.stack = t.stack[len(t.stack)-1], t.stack[:len(t.stack)-1]
+}
+
+// emptyStruct writes a struct field without fields, as used by the members
+// of Thrift unions that carry no data.
+func (t *thriftWriter) emptyStruct(id int16) {
+	t.beginStruct(id)
+	t.endStruct()
+}
@@ -0,0 +1,458 @@
+// Package parquet writes tables as Apache Parquet files.
+//
+// The writer supports the flat schemas needed to export time series: every
+// column is optional and holds booleans, signed or unsigned 64-bit integers,
+// doubles, UTF-8 strings or nanosecond timestamps. Values are written with
+// the plain encoding in a single data page per column chunk.
+package parquet
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"time"
+
+	"github.com/golang/snappy"
+)
+
+// DefaultRowGroupSize is the default number of rows in a row group.
+const DefaultRowGroupSize = 65536
+
+// magic starts and ends every Parquet file.
+const magic = "PAR1"
+
+// ErrWriterClosed is returned when writing to a closed writer.
+var ErrWriterClosed = errors.New("parquet: writer closed")
+
+// Type is the type of the values of a column.
+type Type int
+
+const (
+	// Boolean columns hold bool values.
+	Boolean Type = iota
+	// Int64 columns hold int64 values.
+	Int64
+	// Uint64 columns hold uint64 values.
+	Uint64
+	// Double columns hold float64 values.
+	Double
+	// String columns hold string values.
+	String
+	// Timestamp columns hold time.Time values or int64 nanoseconds since the
+	// epoch and are annotated as UTC timestamps with nanosecond precision.
+	Timestamp
+)
+
+// String returns the name of the type.
+func (t Type) String() string {
+	switch t {
+	case Boolean:
+		return "boolean"
+	case Int64:
+		return "int64"
+	case Uint64:
+		return "uint64"
+	case Double:
+		return "double"
+	case String:
+		return "string"
+	case Timestamp:
+		return "timestamp"
+	}
+	return fmt.Sprintf("Type(%d)", int(t))
+}
+
+// Physical types, encodings and codecs of the Parquet format.
+const (
+	physicalBoolean   = 0
+	physicalInt64     = 2
+	physicalDouble    = 5
+	physicalByteArray = 6
+
+	encodingPlain = 0
+	encodingRLE   = 3
+
+	codecUncompressed = 0
+	codecSnappy       = 1
+
+	convertedUTF8   = 0
+	convertedUint64 = 14
+
+	repetitionOptional = 1
+	pageTypeData       = 0
+)
+
+func (t Type) physical() int32 {
+	switch t {
+	case Boolean:
+		return physicalBoolean
+	case Double:
+		return physicalDouble
+	case String:
+		return physicalByteArray
+	}
+	return physicalInt64
+}
+
+// Column describes a column of the file.
+type Column struct {
+	Name string
+	Type Type
+}
+
+// Writer writes rows to a Parquet file. Rows are buffered and written a row
+// group at a time; the file is not valid until the writer is closed.
+type Writer struct {
+	w       io.Writer
+	columns []Column
+	chunks  []columnChunk
+
+	// RowGroupSize is the number of rows buffered before a row group is
+	// written to the underlying writer.
+	RowGroupSize int
+
+	// Compress sets if pages are compressed with snappy.
+	Compress bool
+
+	offset    int64
+	rows      int
+	numRows   int64
+	rowGroups []rowGroup
+	err       error
+	closed    bool
+}
+
+// NewWriter returns a writer of a file with the given columns to w.
+func NewWriter(w io.Writer, columns []Column) *Writer {
+	return &Writer{
+		w:            w,
+		columns:      columns,
+		chunks:       make([]columnChunk, len(columns)),
+		RowGroupSize: DefaultRowGroupSize,
+		Compress:     true,
+	}
+}
+
+// Write buffers a row. The row has a value for every column; a nil value is
+// a null.
+func (w *Writer) Write(row []interface{}) error {
+	if w.closed {
+		return ErrWriterClosed
+	} else if w.err != nil {
+		return w.err
+	} else if len(row) != len(w.columns) {
+		return fmt.Errorf("parquet: row has %d values, expected %d", len(row), len(w.columns))
+	}
+
+	// Check every value before buffering any of them so a bad row is not
+	// partially written.
+	for i, v := range row {
+		if v != nil && !w.columns[i].Type.accepts(v) {
+			return fmt.Errorf("parquet: cannot write %T to %s column %q", v, w.columns[i].Type, w.columns[i].Name)
+		}
+	}
+	for i, v := range row {
+		w.chunks[i].append(v)
+	}
+
+	w.rows++
+	if w.RowGroupSize > 0 && w.rows >= w.RowGroupSize {
+		return w.flush()
+	}
+	return nil
+}
+
+// Close writes the buffered rows and the file metadata. It does not close
+// the underlying writer.
+func (w *Writer) Close() error {
+	if w.closed {
+		return ErrWriterClosed
+	}
+	w.closed = true
+
+	if w.rows > 0 {
+		if err := w.flush(); err != nil {
+			return err
+		}
+	} else if w.err != nil {
+		return w.err
+	}
+	if err := w.writeMagic(); err != nil {
+		return err
+	}
+
+	meta := w.encodeFileMetaData()
+	var size [4]byte
+	binary.LittleEndian.PutUint32(size[:], uint32(len(meta)))
+	if err := w.write(meta); err != nil {
+		return err
+	} else if err := w.write(size[:]); err != nil {
+		return err
+	}
+	return w.write([]byte(magic))
+}
+
+// write writes b to the underlying writer. After a failed write every
+// following write returns the same error.
+func (w *Writer) write(b []byte) error {
+	if w.err != nil {
+		return w.err
+	}
+	n, err := w.w.Write(b)
+	w.offset += int64(n)
+	w.err = err
+	return err
+}
+
+func (w *Writer) writeMagic() error {
+	if w.offset > 0 {
+		return w.err
+	}
+	return w.write([]byte(magic))
+}
+
+// flush writes the buffered rows as a row group.
+func (w *Writer) flush() error {
+	if err := w.writeMagic(); err != nil {
+		return err
+	}
+
+	rg := rowGroup{numRows: int64(w.rows)}
+	for i := range w.chunks {
+		c := &w.chunks[i]
+		page := c.encode(w.columns[i].Type)
+
+		codec, data := int32(codecUncompressed), page
+		if w.Compress {
+			codec, data = codecSnappy, snappy.Encode(nil, page)
+		}
+
+		var t thriftWriter
+		t.beginStructElem()
+		t.writeI32(1, pageTypeData)
+		t.writeI32(2, int32(len(page)))
+		t.writeI32(3, int32(len(data)))
+		t.beginStruct(5)
+		t.writeI32(1, int32(len(c.defs)))
+		t.writeI32(2, encodingPlain)
+		t.writeI32(3, encodingRLE)
+		t.writeI32(4, encodingRLE)
+		t.endStruct()
+		t.endStruct()
+
+		meta := columnMetaData{
+			codec:            codec,
+			numValues:        int64(len(c.defs)),
+			uncompressedSize: int64(len(t.buf) + len(page)),
+			compressedSize:   int64(len(t.buf) + len(data)),
+			dataPageOffset:   w.offset,
+		}
+		if err := w.write(t.buf); err != nil {
+			return err
+		} else if err := w.write(data); err != nil {
+			return err
+		}
+		rg.columns = append(rg.columns, meta)
+		rg.totalByteSize += meta.uncompressedSize
+		c.reset()
+	}
+
+	w.rowGroups = append(w.rowGroups, rg)
+	w.numRows += int64(w.rows)
+	w.rows = 0
+	return nil
+}
+
+func (w *Writer) encodeFileMetaData() []byte {
+	var t thriftWriter
+	t.beginStructElem()
+	t.writeI32(1, 1)
+
+	// The schema is a root element followed by one element per column.
+	t.writeListHeader(2, compactStruct, len(w.columns)+1)
+	t.beginStructElem()
+	t.writeString(4, "schema")
+	t.writeI32(5, int32(len(w.columns)))
+	t.endStruct()
+	for _, col := range w.columns {
+		t.beginStructElem()
+		t.writeI32(1, col.Type.physical())
+		t.writeI32(3, repetitionOptional)
+		t.writeString(4, col.Name)
+		switch col.Type {
+		case String:
+			t.writeI32(6, convertedUTF8)
+			t.beginStruct(10)
+			t.emptyStruct(1)
+			t.endStruct()
+		case Uint64:
+			t.writeI32(6, convertedUint64)
+			t.beginStruct(10)
+			t.beginStruct(10)
+			t.writeByte(1, 64)
+			t.writeBool(2, false)
+			t.endStruct()
+			t.endStruct()
+		case Timestamp:
+			t.beginStruct(10)
+			t.beginStruct(8)
+			t.writeBool(1, true)
+			t.beginStruct(2)
+			t.emptyStruct(3)
+			t.endStruct()
+			t.endStruct()
+			t.endStruct()
+		}
+		t.endStruct()
+	}
+
+	t.writeI64(3, w.numRows)
+
+	t.writeListHeader(4, compactStruct, len(w.rowGroups))
+	for _, rg := range w.rowGroups {
+		t.beginStructElem()
+		t.writeListHeader(1, compactStruct, len(rg.columns))
+		for i, meta := range rg.columns {
+			col := w.columns[i]
+			t.beginStructElem()
+			t.writeI64(2, meta.dataPageOffset)
+			t.beginStruct(3)
+			t.writeI32(1, col.Type.physical())
+			t.writeListHeader(2, compactI32, 2)
+			t.i32Elem(encodingPlain)
+			t.i32Elem(encodingRLE)
+			t.writeListHeader(3, compactBinary, 1)
+			t.stringElem(col.Name)
+			t.writeI32(4, meta.codec)
+			t.writeI64(5, meta.numValues)
+			t.writeI64(6, meta.uncompressedSize)
+			t.writeI64(7, meta.compressedSize)
+			t.writeI64(9, meta.dataPageOffset)
+			t.endStruct()
+			t.endStruct()
+		}
+		t.writeI64(2, rg.totalByteSize)
+		t.writeI64(3, rg.numRows)
+		t.endStruct()
+	}
+
+	t.writeString(6, "influxdb")
+	t.endStruct()
+	return t.buf
+}
+
+// rowGroup is the metadata of a written row group.
+type rowGroup struct {
+	columns       []columnMetaData
+	totalByteSize int64
+	numRows       int64
+}
+
+// columnMetaData is the metadata of a written column chunk.
+type columnMetaData struct {
+	codec            int32
+	numValues        int64
+	uncompressedSize int64
+	compressedSize   int64
+	dataPageOffset   int64
+}
+
+// accepts returns true if v can be written to a column of type t.
+func (t Type) accepts(v interface{}) bool {
+	switch v.(type) {
+	case bool:
+		return t == Boolean
+	case int64:
+		return t == Int64 || t == Timestamp
+	case uint64:
+		return t == Uint64
+	case float64:
+		return t == Double
+	case string:
+		return t == String
+	case time.Time:
+		return t == Timestamp
+	}
+	return false
+}
+
+// columnChunk buffers the values of a column for the current row group.
+type columnChunk struct {
+	defs  []bool
+	bools []bool
+	data  []byte
+}
+
+func (c *columnChunk) append(v interface{}) {
+	c.defs = append(c.defs, v != nil)
+	switch v := v.(type) {
+	case bool:
+		c.bools = append(c.bools, v)
+	case int64:
+		c.data = appendUint64(c.data, uint64(v))
+	case uint64:
+		c.data = appendUint64(c.data, v)
+	case float64:
+		c.data = appendUint64(c.data, math.Float64bits(v))
+	case time.Time:
+		c.data = appendUint64(c.data, uint64(v.UnixNano()))
+	case string:
+		var size [4]byte
+		binary.LittleEndian.PutUint32(size[:], uint32(len(v)))
+		c.data = append(append(c.data, size[:]...), v...)
+	}
+}
+
+func (c *columnChunk) reset() {
+	c.defs, c.bools, c.data = c.defs[:0], c.bools[:0], c.data[:0]
+}
+
+// encode returns the data page of the chunk: the definition levels followed
+// by the plain encoded values that are not null.
+func (c *columnChunk) encode(typ Type) []byte {
+	levels := encodeLevels(c.defs)
+	page := make([]byte, 4, 4+len(levels)+len(c.data)+len(c.bools)/8+1)
+	binary.LittleEndian.PutUint32(page, uint32(len(levels)))
+	page = append(page, levels...)
+
+	if typ == Boolean {
+		packed := make([]byte, (len(c.bools)+7)/8)
+		for i, v := range c.bools {
+			if v {
+				packed[i/8] |= 1 << uint(i%8)
+			}
+		}
+		return append(page, packed...)
+	}
+	return append(page, c.data...)
+}
+
+// encodeLevels encodes definition levels of bit width 1 as runs of the
+// RLE/bit-packing hybrid encoding.
+func encodeLevels(defs []bool) []byte {
+	var buf []byte
+	var header [binary.MaxVarintLen64]byte
+	for i := 0; i < len(defs); {
+		j := i + 1
+		for j < len(defs) && defs[j] == defs[i] {
+			j++
+		}
+		n := binary.PutUvarint(header[:], uint64(j-i)<<1)
+		buf = append(buf, header[:n]...)
+		if defs[i] {
+			buf = append(buf, 1)
+		} else {
+			buf = append(buf, 0)
+		}
+		i = j
+	}
+	return buf
+}
+
+func appendUint64(b []byte, v uint64) []byte {
+	var buf [8]byte
+	binary.LittleEndian.PutUint64(buf[:], v)
+	return append(b, buf[:]...)
+}
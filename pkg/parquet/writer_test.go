@@ -0,0 +1,260 @@
+package parquet_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/golang/snappy"
+	"github.com/influxdata/influxdb/pkg/parquet"
+)
+
+func TestWriter(t *testing.T) {
+	for _, compress := range []bool{false, true} {
+		var buf bytes.Buffer
+		w := parquet.NewWriter(&buf, []parquet.Column{
+			{Name: "time", Type: parquet.Timestamp},
+			{Name: "host", Type: parquet.String},
+			{Name: "value", Type: parquet.Double},
+			{Name: "count", Type: parquet.Int64},
+			{Name: "total", Type: parquet.Uint64},
+			{Name: "ok", Type: parquet.Boolean},
+		})
+		w.Compress = compress
+		w.RowGroupSize = 2
+
+		rows := [][]interface{}{
+			{time.Unix(0, 10), "serverA", 1.5, int64(-1), uint64(1), true},
+			{int64(20), nil, nil, int64(2), nil, false},
+			{time.Unix(0, 30), "serverB", 2.5, nil, uint64(math.MaxUint64), nil},
+		}
+		for _, row := range rows {
+			if err := w.Write(row); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		f := readFile(t, buf.Bytes())
+		if f.numRows != 3 {
+			t.Fatalf("unexpected number of rows: %d", f.numRows)
+		} else if exp := []string{"time", "host", "value", "count", "total", "ok"}; !reflect.DeepEqual(f.names, exp) {
+			t.Fatalf("unexpected columns: %v", f.names)
+		} else if len(f.rowGroups) != 2 {
+			t.Fatalf("unexpected number of row groups: %d", len(f.rowGroups))
+		}
+
+		exp := [][]interface{}{
+			{int64(10), int64(20), int64(30)},
+			{"serverA", nil, "serverB"},
+			{1.5, nil, 2.5},
+			{int64(-1), int64(2), nil},
+			{uint64(1), nil, uint64(math.MaxUint64)},
+			{true, false, nil},
+		}
+		for i, typ := range []int64{2, 6, 5, 2, 2, 0} {
+			var values []interface{}
+			for _, rg := range f.rowGroups {
+				values = append(values, readColumn(t, buf.Bytes(), rg[i], typ)...)
+			}
+			if i == 4 {
+				for j, v := range values {
+					if v != nil {
+						values[j] = uint64(v.(int64))
+					}
+				}
+			}
+			if !reflect.DeepEqual(values, exp[i]) {
+				t.Fatalf("compress=%v: unexpected values in column %s: %v", compress, f.names[i], values)
+			}
+		}
+	}
+}
+
+func TestWriter_Errors(t *testing.T) {
+	var buf bytes.Buffer
+	w := parquet.NewWriter(&buf, []parquet.Column{{Name: "value", Type: parquet.Double}})
+	if err := w.Write([]interface{}{"a"}); err == nil || err.Error() != `parquet: cannot write string to double column "value"` {
+		t.Fatalf("unexpected error: %v", err)
+	} else if err := w.Write([]interface{}{1.0, 2.0}); err == nil {
+		t.Fatal("expected error")
+	}
+
+	// A file without rows is still valid.
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	} else if f := readFile(t, buf.Bytes()); f.numRows != 0 || len(f.rowGroups) != 0 {
+		t.Fatalf("unexpected file: %+v", f)
+	} else if err := w.Write([]interface{}{1.0}); err != parquet.ErrWriterClosed {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+// file is the decoded metadata of a Parquet file.
+type file struct {
+	names     []string
+	numRows   int64
+	rowGroups [][]map[int16]interface{}
+}
+
+func readFile(t *testing.T, b []byte) file {
+	t.Helper()
+	if !bytes.HasPrefix(b, []byte("PAR1")) || !bytes.HasSuffix(b, []byte("PAR1")) {
+		t.Fatal("missing magic")
+	}
+	size := binary.LittleEndian.Uint32(b[len(b)-8:])
+	d := &thriftReader{b: b[len(b)-8-int(size) : len(b)-8]}
+	meta := d.readStruct()
+
+	var f file
+	f.numRows = meta[3].(int64)
+	for _, elem := range meta[2].([]interface{})[1:] {
+		f.names = append(f.names, string(elem.(map[int16]interface{})[4].([]byte)))
+	}
+	for _, rg := range meta[4].([]interface{}) {
+		var chunks []map[int16]interface{}
+		for _, c := range rg.(map[int16]interface{})[1].([]interface{}) {
+			chunks = append(chunks, c.(map[int16]interface{})[3].(map[int16]interface{}))
+		}
+		f.rowGroups = append(f.rowGroups, chunks)
+	}
+	return f
+}
+
+// readColumn decodes the values of a column chunk with a single data page.
+func readColumn(t *testing.T, b []byte, meta map[int16]interface{}, typ int64) []interface{} {
+	t.Helper()
+	if meta[1].(int64) != typ {
+		t.Fatalf("unexpected type: %d", meta[1])
+	}
+	d := &thriftReader{b: b[meta[9].(int64):]}
+	header := d.readStruct()
+	page := d.b[d.i : d.i+int(header[3].(int64))]
+	if meta[4].(int64) == 1 {
+		var err error
+		if page, err = snappy.Decode(nil, page); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if int64(len(page)) != header[2].(int64) {
+		t.Fatalf("unexpected page size: %d", len(page))
+	}
+
+	// Decode the runs of definition levels.
+	n := int(header[5].(map[int16]interface{})[1].(int64))
+	levels := page[4 : 4+binary.LittleEndian.Uint32(page)]
+	page = page[4+len(levels):]
+	var defs []bool
+	for len(levels) > 0 {
+		run, sz := binary.Uvarint(levels)
+		for i := 0; i < int(run>>1); i++ {
+			defs = append(defs, levels[sz] == 1)
+		}
+		levels = levels[sz+1:]
+	}
+	if len(defs) != n {
+		t.Fatalf("unexpected number of levels: %d", len(defs))
+	}
+
+	var values []interface{}
+	var bit int
+	for _, def := range defs {
+		if !def {
+			values = append(values, nil)
+			continue
+		}
+		switch typ {
+		case 0:
+			values = append(values, page[bit/8]&(1<<uint(bit%8)) != 0)
+			bit++
+		case 2:
+			values = append(values, int64(binary.LittleEndian.Uint64(page)))
+			page = page[8:]
+		case 5:
+			values = append(values, math.Float64frombits(binary.LittleEndian.Uint64(page)))
+			page = page[8:]
+		case 6:
+			sz := binary.LittleEndian.Uint32(page)
+			values = append(values, string(page[4:4+sz]))
+			page = page[4+sz:]
+		}
+	}
+	return values
+}
+
+// thriftReader decodes structs of the Thrift compact protocol into maps of
+// field ids to values.
+type thriftReader struct {
+	b []byte
+	i int
+}
+
+func (d *thriftReader) readStruct() map[int16]interface{} {
+	m := make(map[int16]interface{})
+	var id int16
+	for {
+		h := d.b[d.i]
+		d.i++
+		if h == 0 {
+			return m
+		}
+		if delta := h >> 4; delta != 0 {
+			id += int16(delta)
+		} else {
+			id = int16(d.readVarint())
+		}
+		m[id] = d.readValue(h & 0x0f)
+	}
+}
+
+func (d *thriftReader) readValue(typ byte) interface{} {
+	switch typ {
+	case 1:
+		return true
+	case 2:
+		return false
+	case 3:
+		d.i++
+		return int8(d.b[d.i-1])
+	case 5, 6:
+		return d.readVarint()
+	case 8:
+		n, sz := binary.Uvarint(d.b[d.i:])
+		d.i += sz + int(n)
+		return d.b[d.i-int(n) : d.i]
+	case 9:
+		h := d.b[d.i]
+		d.i++
+		n := int(h >> 4)
+		if n == 15 {
+			v, sz := binary.Uvarint(d.b[d.i:])
+			d.i += sz
+			n = int(v)
+		}
+		list := make([]interface{}, n)
+		for i := range list {
+			if h&0x0f == 1 {
+				// Booleans in lists are written as a byte.
+				list[i] = d.b[d.i] == 1
+				d.i++
+				continue
+			}
+			list[i] = d.readValue(h & 0x0f)
+		}
+		return list
+	case 12:
+		return d.readStruct()
+	}
+	panic("unexpected type")
+}
+
+func (d *thriftReader) readVarint() int64 {
+	v, sz := binary.Uvarint(d.b[d.i:])
+	d.i += sz
+	return int64(v>>1) ^ -int64(v&1)
+}
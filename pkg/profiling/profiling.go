@@ -0,0 +1,48 @@
+// Package profiling attaches profiler labels to the stages of the write path
+// so CPU and heap profiles can be broken down by stage.
+//
+// Labels are disabled by default because attaching them allocates on every
+// call.  When enabled, samples taken while a stage runs carry a "stage" label
+// that can be filtered with the -tagfocus option of go tool pprof.
+package profiling
+
+import (
+	"context"
+	"runtime/pprof"
+	"sync/atomic"
+)
+
+// Stages of the write path.
+const (
+	StageParse      = "parse"
+	StageWAL        = "wal"
+	StageCache      = "cache"
+	StageSnapshot   = "snapshot"
+	StageCompaction = "compaction"
+)
+
+var enabled int32
+
+// SetEnabled enables or disables profiler labels.
+func SetEnabled(v bool) {
+	var i int32
+	if v {
+		i = 1
+	}
+	atomic.StoreInt32(&enabled, i)
+}
+
+// Enabled returns true if profiler labels are enabled.
+func Enabled() bool {
+	return atomic.LoadInt32(&enabled) == 1
+}
+
+// Do calls fn.  If profiler labels are enabled, fn runs with the stage label set
+// to stage.
+func Do(stage string, fn func()) {
+	if !Enabled() {
+		fn()
+		return
+	}
+	pprof.Do(context.Background(), pprof.Labels("stage", stage), func(context.Context) { fn() })
+}
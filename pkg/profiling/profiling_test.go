@@ -0,0 +1,23 @@
+package profiling_test
+
+import (
+	"testing"
+
+	"github.com/influxdata/influxdb/pkg/profiling"
+)
+
+func TestDo(t *testing.T) {
+	for _, enabled := range []bool{false, true} {
+		profiling.SetEnabled(enabled)
+		if got := profiling.Enabled(); got != enabled {
+			t.Fatalf("unexpected enabled: exp %v, got %v", enabled, got)
+		}
+
+		var called bool
+		profiling.Do(profiling.StageWAL, func() { called = true })
+		if !called {
+			t.Fatalf("function not called with labels enabled=%v", enabled)
+		}
+	}
+	profiling.SetEnabled(false)
+}
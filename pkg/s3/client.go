@@ -0,0 +1,319 @@
+// Package s3 implements a client of S3-compatible object stores that is
+// sufficient to stream backups to and from a bucket and to move the data of
+// cold shards into one.
+package s3
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+// emptyPayloadHash is the SHA-256 hash of an empty request body.
+const emptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+// Error is an error returned by the object store.
+type Error struct {
+	StatusCode int
+	Code       string `xml:"Code"`
+	Message    string `xml:"Message"`
+}
+
+func (e *Error) Error() string {
+	if e.Code == "" {
+		return fmt.Sprintf("s3: %s", http.StatusText(e.StatusCode))
+	}
+	return fmt.Sprintf("s3: %s: %s", e.Code, e.Message)
+}
+
+// IsNotFound returns true if err is returned for a missing object or upload.
+func IsNotFound(err error) bool {
+	e, ok := err.(*Error)
+	return ok && e.StatusCode == http.StatusNotFound
+}
+
+// Client is a client of a bucket of an S3-compatible object store.
+type Client struct {
+	config Config
+	now    func() time.Time
+
+	// HTTPClient is the client requests are sent with.
+	HTTPClient *http.Client
+}
+
+// NewClient returns a client of the bucket of the config.
+func NewClient(c Config) *Client {
+	return &Client{
+		config:     c,
+		now:        time.Now,
+		HTTPClient: http.DefaultClient,
+	}
+}
+
+// Object is an object in the bucket.
+type Object struct {
+	Key  string `xml:"Key"`
+	Size int64  `xml:"Size"`
+}
+
+// List returns the objects whose keys start with prefix, in key order.
+func (c *Client) List(prefix string) ([]Object, error) {
+	var objects []Object
+	var token string
+	for {
+		q := url.Values{"list-type": {"2"}, "prefix": {prefix}}
+		if token != "" {
+			q.Set("continuation-token", token)
+		}
+
+		var result struct {
+			Contents              []Object `xml:"Contents"`
+			IsTruncated           bool     `xml:"IsTruncated"`
+			NextContinuationToken string   `xml:"NextContinuationToken"`
+		}
+		if err := c.doXML("GET", "", q, nil, nil, &result); err != nil {
+			return nil, err
+		}
+		objects = append(objects, result.Contents...)
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			return objects, nil
+		}
+		token = result.NextContinuationToken
+	}
+}
+
+// Glob returns the keys of the objects that match pattern, in key order.
+// The pattern has the syntax of path.Match.
+func (c *Client) Glob(pattern string) ([]string, error) {
+	if _, err := path.Match(pattern, ""); err != nil {
+		return nil, err
+	}
+
+	prefix := pattern
+	if i := strings.IndexAny(pattern, `*?[\`); i >= 0 {
+		prefix = pattern[:i]
+	}
+	objects, err := c.List(prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []string
+	for _, o := range objects {
+		if ok, _ := path.Match(pattern, o.Key); ok {
+			keys = append(keys, o.Key)
+		}
+	}
+	return keys, nil
+}
+
+// Get returns the content of an object. The caller must close it.
+func (c *Client) Get(key string) (io.ReadCloser, error) {
+	resp, err := c.do("GET", key, nil, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// GetRange returns n bytes of an object starting at off, or fewer if the
+// object ends before. The caller must close it.
+func (c *Client) GetRange(key string, off, n int64) (io.ReadCloser, error) {
+	header := http.Header{"Range": {fmt.Sprintf("bytes=%d-%d", off, off+n-1)}}
+	resp, err := c.do("GET", key, nil, header, nil)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// Delete removes an object. Removing a missing object is not an error.
+func (c *Client) Delete(key string) error {
+	resp, err := c.do("DELETE", key, nil, nil, nil)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+// Copy copies the object src to dst within the bucket.
+func (c *Client) Copy(src, dst string) error {
+	header := http.Header{"X-Amz-Copy-Source": {escapePath("/" + c.config.Bucket + "/" + src)}}
+	return c.doXML("PUT", dst, nil, header, nil, nil)
+}
+
+// doXML sends a request and decodes an XML response body into v.
+func (c *Client) doXML(method, key string, query url.Values, header http.Header, body []byte, v interface{}) error {
+	resp, err := c.do(method, key, query, header, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	// Some errors, such as of completing an upload, are returned after the
+	// status of a successful response has been sent.
+	head := b
+	if len(head) > 256 {
+		head = head[:256]
+	}
+	if bytes.Contains(head, []byte("<Error>")) {
+		e := &Error{StatusCode: resp.StatusCode}
+		if err := xml.Unmarshal(b, e); err == nil {
+			return e
+		}
+	}
+	if v == nil {
+		return nil
+	}
+	return xml.Unmarshal(b, v)
+}
+
+// do sends a signed request for an object, or for the bucket if key is
+// empty. Responses with an error status are returned as an *Error.
+func (c *Client) do(method, key string, query url.Values, header http.Header, body []byte) (*http.Response, error) {
+	u, err := url.Parse(strings.TrimSuffix(c.config.Endpoint, "/"))
+	if err != nil {
+		return nil, err
+	}
+	u.Path += "/" + c.config.Bucket
+	if key != "" {
+		u.Path += "/" + key
+	}
+	u.RawPath = escapePath(u.Path)
+	u.RawQuery = canonicalQuery(query)
+
+	req, err := http.NewRequest(method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	c.sign(req, body)
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode/100 != 2 {
+		defer resp.Body.Close()
+		e := &Error{StatusCode: resp.StatusCode}
+		if b, err := ioutil.ReadAll(resp.Body); err == nil {
+			xml.Unmarshal(b, e)
+		}
+		return nil, e
+	}
+	return resp, nil
+}
+
+// sign signs a request with AWS Signature Version 4.
+func (c *Client) sign(req *http.Request, body []byte) {
+	now := c.now().UTC()
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+
+	payloadHash := emptyPayloadHash
+	if len(body) > 0 {
+		sum := sha256.Sum256(body)
+		payloadHash = hex.EncodeToString(sum[:])
+	}
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	if c.config.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", c.config.SessionToken)
+	}
+
+	// Sign the host and the headers that affect the content of the request.
+	headers := map[string]string{"host": req.URL.Host}
+	for k, v := range req.Header {
+		k = strings.ToLower(k)
+		if strings.HasPrefix(k, "x-amz-") || k == "content-md5" || k == "content-type" || k == "range" {
+			headers[k] = strings.TrimSpace(strings.Join(v, ","))
+		}
+	}
+	names := make([]string, 0, len(headers))
+	for k := range headers {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	var canonical bytes.Buffer
+	fmt.Fprintf(&canonical, "%s\n%s\n%s\n", req.Method, req.URL.EscapedPath(), req.URL.RawQuery)
+	for _, k := range names {
+		fmt.Fprintf(&canonical, "%s:%s\n", k, headers[k])
+	}
+	signedHeaders := strings.Join(names, ";")
+	fmt.Fprintf(&canonical, "\n%s\n%s", signedHeaders, payloadHash)
+
+	scope := date + "/" + c.config.Region + "/s3/aws4_request"
+	hash := sha256.Sum256(canonical.Bytes())
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(hash[:])
+
+	key := hmacSHA256([]byte("AWS4"+c.config.SecretAccessKey), date)
+	key = hmacSHA256(key, c.config.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		c.config.AccessKeyID, scope, signedHeaders, signature))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+// escapePath escapes every byte of a path other than the unreserved
+// characters and slashes, as the signature requires.
+func escapePath(p string) string {
+	return strings.Replace(escape(p), "%2F", "/", -1)
+}
+
+// canonicalQuery encodes a query with its keys sorted and every reserved
+// character escaped.
+func canonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var parts []string
+	for _, k := range keys {
+		for _, v := range query[k] {
+			parts = append(parts, escape(k)+"="+escape(v))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+func escape(s string) string {
+	var buf strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '-' || c == '_' || c == '.' || c == '~' {
+			buf.WriteByte(c)
+		} else {
+			fmt.Fprintf(&buf, "%%%02X", c)
+		}
+	}
+	return buf.String()
+}
@@ -0,0 +1,85 @@
+package s3
+
+import (
+	"errors"
+	"os"
+
+	"github.com/BurntSushi/toml"
+	itoml "github.com/influxdata/influxdb/toml"
+)
+
+const (
+	// DefaultRegion is the region requests are signed for by default.
+	DefaultRegion = "us-east-1"
+
+	// DefaultPartSize is the default size of the parts of multipart uploads.
+	DefaultPartSize = 16 * 1024 * 1024
+
+	// MinPartSize is the smallest part size object stores accept for all
+	// but the last part of an upload.
+	MinPartSize = 5 * 1024 * 1024
+)
+
+// Config is the configuration of an S3-compatible object store.
+type Config struct {
+	// Endpoint is the base URL of the object store, such as
+	// https://s3.us-west-2.amazonaws.com or http://minio:9000. Buckets are
+	// always addressed in the path of the URL.
+	Endpoint string `toml:"endpoint"`
+	Region   string `toml:"region"`
+	Bucket   string `toml:"bucket"`
+
+	// The credentials default to the AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY
+	// and AWS_SESSION_TOKEN environment variables.
+	AccessKeyID     string `toml:"access-key-id"`
+	SecretAccessKey string `toml:"secret-access-key"`
+	SessionToken    string `toml:"session-token"`
+
+	PartSize itoml.Size `toml:"part-size"`
+}
+
+// NewConfig returns a new Config with defaults.
+func NewConfig() Config {
+	return Config{
+		Region:   DefaultRegion,
+		PartSize: DefaultPartSize,
+	}
+}
+
+// LoadConfig reads a config from a TOML file and fills in credentials that
+// the file does not set from the environment.
+func LoadConfig(path string) (Config, error) {
+	c := NewConfig()
+	if _, err := toml.DecodeFile(path, &c); err != nil {
+		return c, err
+	}
+	c = c.WithEnvCredentials()
+	return c, c.Validate()
+}
+
+// WithEnvCredentials returns a copy of the config with the credentials read
+// from the environment if the config does not set any.
+func (c Config) WithEnvCredentials() Config {
+	if c.AccessKeyID == "" && c.SecretAccessKey == "" {
+		c.AccessKeyID = os.Getenv("AWS_ACCESS_KEY_ID")
+		c.SecretAccessKey = os.Getenv("AWS_SECRET_ACCESS_KEY")
+		if c.SessionToken == "" {
+			c.SessionToken = os.Getenv("AWS_SESSION_TOKEN")
+		}
+	}
+	return c
+}
+
+// Validate returns an error if the config is invalid.
+func (c Config) Validate() error {
+	if c.Endpoint == "" {
+		return errors.New("s3 endpoint must be specified")
+	} else if c.Bucket == "" {
+		return errors.New("s3 bucket must be specified")
+	} else if c.AccessKeyID == "" || c.SecretAccessKey == "" {
+		return errors.New("s3 credentials must be specified")
+	} else if c.PartSize < MinPartSize {
+		return errors.New("s3 part-size must be at least 5m")
+	}
+	return nil
+}
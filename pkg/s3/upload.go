@@ -0,0 +1,208 @@
+package s3
+
+import (
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrUploadChanged is returned when resuming an upload whose uploaded parts
+// do not match the data being uploaded. The incomplete upload is aborted so
+// the next upload of the key starts over.
+var ErrUploadChanged = errors.New("s3: data does not match the incomplete upload")
+
+// partAttempts is the number of times the upload of a part is attempted.
+const partAttempts = 3
+
+// part is a part of a multipart upload.
+type part struct {
+	PartNumber int    `xml:"PartNumber"`
+	ETag       string `xml:"ETag"`
+	Size       int64  `xml:"Size,omitempty"`
+}
+
+// Upload streams r to the object key with a multipart upload and returns
+// the number of bytes uploaded. The object is only created once r has been
+// read to the end; nothing is created if r is empty.
+//
+// An upload that fails while reading r is left incomplete. The next upload
+// of the same key resumes it: parts that were already uploaded are not sent
+// again if the data read for them has the same MD5 hash. Stores that do not
+// use the MD5 hash of a part as its ETag, such as for objects encrypted with
+// KMS keys, always start over.
+func (c *Client) Upload(key string, r io.Reader) (int64, error) {
+	uploadID, uploaded, err := c.resumableUpload(key)
+	if err != nil {
+		return 0, err
+	}
+	if uploadID == "" {
+		if uploadID, err = c.createUpload(key); err != nil {
+			return 0, err
+		}
+	}
+
+	var n int64
+	var parts []part
+	buf := make([]byte, int(c.config.PartSize))
+	for num := 1; ; num++ {
+		sz, rerr := io.ReadFull(r, buf)
+		if rerr == io.EOF {
+			break
+		} else if rerr != nil && rerr != io.ErrUnexpectedEOF {
+			return n, rerr
+		}
+		data := buf[:sz]
+
+		sum := md5.Sum(data)
+		etag := hex.EncodeToString(sum[:])
+		if p, ok := uploaded[num]; ok {
+			if p.Size != int64(sz) || strings.Trim(p.ETag, `"`) != etag {
+				c.abortUpload(key, uploadID)
+				return n, ErrUploadChanged
+			}
+			parts = append(parts, p)
+		} else {
+			p, err := c.uploadPart(key, uploadID, num, data, sum[:])
+			if err != nil {
+				return n, err
+			}
+			parts = append(parts, p)
+		}
+		n += int64(sz)
+
+		if rerr == io.ErrUnexpectedEOF {
+			break
+		}
+	}
+
+	if n == 0 {
+		return 0, c.abortUpload(key, uploadID)
+	}
+	return n, c.completeUpload(key, uploadID, parts)
+}
+
+// resumableUpload returns the most recent incomplete upload of key and its
+// parts. The ID is empty if there is none.
+func (c *Client) resumableUpload(key string) (string, map[int]part, error) {
+	var uploadID string
+	var initiated time.Time
+	q := url.Values{"uploads": {""}, "prefix": {key}}
+	for {
+		var result struct {
+			Uploads []struct {
+				Key       string    `xml:"Key"`
+				UploadID  string    `xml:"UploadId"`
+				Initiated time.Time `xml:"Initiated"`
+			} `xml:"Upload"`
+			IsTruncated        bool   `xml:"IsTruncated"`
+			NextKeyMarker      string `xml:"NextKeyMarker"`
+			NextUploadIDMarker string `xml:"NextUploadIdMarker"`
+		}
+		if err := c.doXML("GET", "", q, nil, nil, &result); err != nil {
+			return "", nil, err
+		}
+		for _, u := range result.Uploads {
+			if u.Key == key && (uploadID == "" || u.Initiated.After(initiated)) {
+				uploadID, initiated = u.UploadID, u.Initiated
+			}
+		}
+		if !result.IsTruncated {
+			break
+		}
+		q.Set("key-marker", result.NextKeyMarker)
+		q.Set("upload-id-marker", result.NextUploadIDMarker)
+	}
+	if uploadID == "" {
+		return "", nil, nil
+	}
+
+	parts := make(map[int]part)
+	q = url.Values{"uploadId": {uploadID}}
+	for {
+		var result struct {
+			Parts                []part `xml:"Part"`
+			IsTruncated          bool   `xml:"IsTruncated"`
+			NextPartNumberMarker string `xml:"NextPartNumberMarker"`
+		}
+		if err := c.doXML("GET", key, q, nil, nil, &result); IsNotFound(err) {
+			// The upload was completed or aborted since it was listed.
+			return "", nil, nil
+		} else if err != nil {
+			return "", nil, err
+		}
+		for _, p := range result.Parts {
+			parts[p.PartNumber] = p
+		}
+		if !result.IsTruncated {
+			return uploadID, parts, nil
+		}
+		q.Set("part-number-marker", result.NextPartNumberMarker)
+	}
+}
+
+func (c *Client) createUpload(key string) (string, error) {
+	var result struct {
+		UploadID string `xml:"UploadId"`
+	}
+	if err := c.doXML("POST", key, url.Values{"uploads": {""}}, nil, nil, &result); err != nil {
+		return "", err
+	}
+	return result.UploadID, nil
+}
+
+// uploadPart uploads a part, retrying failed attempts.
+func (c *Client) uploadPart(key, uploadID string, num int, data, sum []byte) (part, error) {
+	q := url.Values{"partNumber": {strconv.Itoa(num)}, "uploadId": {uploadID}}
+	header := http.Header{"Content-Md5": {base64.StdEncoding.EncodeToString(sum)}}
+
+	var err error
+	for i := 0; i < partAttempts; i++ {
+		if i > 0 {
+			time.Sleep(time.Duration(i) * time.Second)
+		}
+
+		var resp *http.Response
+		if resp, err = c.do("PUT", key, q, header, data); err == nil {
+			resp.Body.Close()
+			return part{PartNumber: num, ETag: resp.Header.Get("ETag"), Size: int64(len(data))}, nil
+		} else if e, ok := err.(*Error); ok && e.StatusCode/100 == 4 {
+			break
+		}
+	}
+	return part{}, err
+}
+
+func (c *Client) completeUpload(key, uploadID string, parts []part) error {
+	type completePart struct {
+		PartNumber int    `xml:"PartNumber"`
+		ETag       string `xml:"ETag"`
+	}
+	body := struct {
+		XMLName xml.Name       `xml:"CompleteMultipartUpload"`
+		Parts   []completePart `xml:"Part"`
+	}{}
+	for _, p := range parts {
+		body.Parts = append(body.Parts, completePart{PartNumber: p.PartNumber, ETag: p.ETag})
+	}
+	b, err := xml.Marshal(body)
+	if err != nil {
+		return err
+	}
+	return c.doXML("POST", key, url.Values{"uploadId": {uploadID}}, nil, b, nil)
+}
+
+func (c *Client) abortUpload(key, uploadID string) error {
+	resp, err := c.do("DELETE", key, url.Values{"uploadId": {uploadID}}, nil, nil)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
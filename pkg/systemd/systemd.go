@@ -0,0 +1,94 @@
+// Package systemd implements the parts of the systemd service protocol that
+// influxd uses: listeners passed by socket activation and notifications of
+// the service state.
+package systemd
+
+import (
+	"net"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/influxdata/influxdb/pkg/bindaddr"
+)
+
+// listenFdsStart is the first file descriptor passed by socket activation.
+const listenFdsStart = 3
+
+// Listeners returns the listeners passed to the process by socket activation,
+// keyed by the name set with FileDescriptorName= in the socket unit. Sockets
+// without a name are keyed by "unknown", and several sockets with the same
+// name are merged into one listener. The environment variables of socket
+// activation are unset so that child processes do not inherit them.
+func Listeners() (map[string]net.Listener, error) {
+	defer func() {
+		os.Unsetenv("LISTEN_PID")
+		os.Unsetenv("LISTEN_FDS")
+		os.Unsetenv("LISTEN_FDNAMES")
+	}()
+
+	pid, err := strconv.Atoi(os.Getenv("LISTEN_PID"))
+	if err != nil || pid != os.Getpid() {
+		return nil, nil
+	}
+	nfds, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+	if err != nil || nfds <= 0 {
+		return nil, nil
+	}
+	names := strings.Split(os.Getenv("LISTEN_FDNAMES"), ":")
+
+	byName := make(map[string][]net.Listener)
+	closeAll := func() {
+		for _, lns := range byName {
+			for _, ln := range lns {
+				ln.Close()
+			}
+		}
+	}
+	for i := 0; i < nfds; i++ {
+		name := "unknown"
+		if i < len(names) && names[i] != "" {
+			name = names[i]
+		}
+
+		// FileListener duplicates the descriptor, so the file is closed
+		// once the listener is created.
+		f := os.NewFile(uintptr(listenFdsStart+i), name)
+		ln, err := net.FileListener(f)
+		f.Close()
+		if err != nil {
+			closeAll()
+			return nil, err
+		}
+		byName[name] = append(byName[name], ln)
+	}
+
+	listeners := make(map[string]net.Listener, len(byName))
+	for name, lns := range byName {
+		listeners[name] = bindaddr.Merge(lns)
+	}
+	return listeners, nil
+}
+
+// Notify sends state, such as "READY=1", to the service manager. It returns
+// false if the process was not started by a service manager that expects
+// notifications.
+func Notify(state string) (bool, error) {
+	socket := os.Getenv("NOTIFY_SOCKET")
+	if socket == "" {
+		return false, nil
+	}
+
+	// A leading '@' names a socket in the abstract namespace, which the net
+	// package handles itself.
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: socket, Net: "unixgram"})
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close()
+
+	if _, err := conn.Write([]byte(state)); err != nil {
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,72 @@
+package systemd_test
+
+import (
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"testing"
+
+	"github.com/influxdata/influxdb/pkg/systemd"
+)
+
+func TestNotify(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("unix sockets are not supported on windows")
+	}
+
+	dir, err := ioutil.TempDir("", "systemd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "notify.sock")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	defer os.Unsetenv("NOTIFY_SOCKET")
+	os.Setenv("NOTIFY_SOCKET", path)
+
+	if sent, err := systemd.Notify("READY=1"); err != nil {
+		t.Fatal(err)
+	} else if !sent {
+		t.Fatal("expected notification to be sent")
+	}
+
+	buf := make([]byte, 64)
+	n, err := conn.Read(buf)
+	if err != nil {
+		t.Fatal(err)
+	} else if got := string(buf[:n]); got != "READY=1" {
+		t.Fatalf("unexpected state: %q", got)
+	}
+}
+
+func TestNotify_NoSocket(t *testing.T) {
+	os.Unsetenv("NOTIFY_SOCKET")
+	if sent, err := systemd.Notify("READY=1"); err != nil {
+		t.Fatal(err)
+	} else if sent {
+		t.Fatal("expected no notification without a socket")
+	}
+}
+
+func TestListeners_OtherProcess(t *testing.T) {
+	os.Setenv("LISTEN_PID", strconv.Itoa(os.Getpid()+1))
+	os.Setenv("LISTEN_FDS", "1")
+
+	if lns, err := systemd.Listeners(); err != nil {
+		t.Fatal(err)
+	} else if len(lns) != 0 {
+		t.Fatalf("unexpected listeners: %v", lns)
+	}
+	if os.Getenv("LISTEN_FDS") != "" {
+		t.Fatal("expected environment to be unset")
+	}
+}
@@ -1,6 +1,9 @@
 package query_test
 
 import (
+	"fmt"
+	"io/ioutil"
+	"os"
 	"testing"
 	"time"
 
@@ -352,6 +355,67 @@ func TestCallIterator_Max_Float(t *testing.T) {
 	}
 }
 
+// Ensure that the groups of a window are spilled to disk when they exceed the
+// group memory and that the results do not change.
+func TestCallIterator_MaxMemory(t *testing.T) {
+	dir, err := ioutil.TempDir("", "query-spill-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	var points []query.FloatPoint
+	for i := 0; i < 100; i++ {
+		for j := 0; j < 50; j++ {
+			points = append(points, query.FloatPoint{
+				Name:  "cpu",
+				Time:  int64(i),
+				Value: float64((i * j) % 17),
+				Tags:  ParseTags(fmt.Sprintf("host=host%02d", j)),
+			})
+		}
+	}
+
+	for _, expr := range []string{`count("value")`, `max("value")`, `mean("value")`} {
+		t.Run(expr, func(t *testing.T) {
+			readAll := func(maxMemory int64) [][]query.Point {
+				opt := query.IteratorOptions{
+					Expr:      MustParseExpr(expr),
+					GroupBy:   map[string]struct{}{"host": {}},
+					Interval:  query.Interval{Duration: 10 * time.Nanosecond},
+					Ordered:   true,
+					Ascending: true,
+					MaxMemory: maxMemory,
+					SpillDir:  dir,
+				}
+
+				itr, err := query.NewCallIterator(&FloatIterator{Points: points}, opt)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				a, err := Iterators([]query.Iterator{itr}).ReadAll()
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+				itr.Close()
+				return a
+			}
+
+			exp := readAll(0)
+			if got := readAll(1000); !cmp.Equal(got, exp) {
+				t.Fatalf("unexpected points:\n%s", cmp.Diff(got, exp))
+			}
+
+			if fis, err := ioutil.ReadDir(dir); err != nil {
+				t.Fatal(err)
+			} else if len(fis) != 0 {
+				t.Fatalf("unexpected spill files: %d", len(fis))
+			}
+		})
+	}
+}
+
 // Ensure that a integer iterator can be created for a max() call.
 func TestCallIterator_Max_Integer(t *testing.T) {
 	itr, _ := query.NewCallIterator(
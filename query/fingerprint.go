@@ -0,0 +1,47 @@
+package query
+
+import (
+	"fmt"
+	"hash/fnv"
+
+	"github.com/influxdata/influxql"
+)
+
+// Fingerprint returns the normalized text of a query and a hash of it that
+// identifies the shape of the query. Literals in the conditions of SELECT
+// statements are replaced with a placeholder so queries that only differ by
+// the values they filter on, such as a host name or a time range, have the
+// same fingerprint. Function arguments, such as the GROUP BY interval, are
+// kept because they change the cost of the query.
+//
+// The query is not modified.
+func Fingerprint(q *influxql.Query) (fingerprint, normalized string) {
+	// Rewrite a copy of the query so the caller's AST is left intact.
+	other, err := influxql.ParseQuery(q.String())
+	if err != nil {
+		other = q
+	} else {
+		influxql.RewriteFunc(other, func(n influxql.Node) influxql.Node {
+			if stmt, ok := n.(*influxql.SelectStatement); ok && stmt.Condition != nil {
+				stmt.Condition = influxql.RewriteExpr(stmt.Condition, normalizeLiteral)
+			}
+			return n
+		})
+	}
+
+	normalized = other.String()
+	h := fnv.New64a()
+	h.Write([]byte(normalized))
+	return fmt.Sprintf("%016x", h.Sum64()), normalized
+}
+
+// normalizeLiteral replaces a literal with a placeholder.
+func normalizeLiteral(expr influxql.Expr) influxql.Expr {
+	switch expr.(type) {
+	case *influxql.NumberLiteral, *influxql.IntegerLiteral, *influxql.UnsignedLiteral,
+		*influxql.StringLiteral, *influxql.BooleanLiteral, *influxql.TimeLiteral,
+		*influxql.DurationLiteral, *influxql.RegexLiteral, *influxql.ListLiteral:
+		return &influxql.BoundParameter{Name: "_"}
+	}
+	return expr
+}
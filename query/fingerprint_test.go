@@ -0,0 +1,38 @@
+package query_test
+
+import (
+	"testing"
+
+	"github.com/influxdata/influxdb/query"
+	"github.com/influxdata/influxql"
+)
+
+func TestFingerprint(t *testing.T) {
+	fingerprint := func(s string) (string, string) {
+		q, err := influxql.ParseQuery(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		before := q.String()
+		f, normalized := query.Fingerprint(q)
+		if q.String() != before {
+			t.Fatalf("query modified: %s", q)
+		}
+		return f, normalized
+	}
+
+	f1, normalized := fingerprint(`SELECT mean(value) FROM cpu WHERE host = 'serverA' AND time > now() - 1h GROUP BY time(1m)`)
+	if exp := `SELECT mean(value) FROM cpu WHERE host = $_ AND time > now() - $_ GROUP BY time(1m)`; normalized != exp {
+		t.Fatalf("unexpected normalized query:\n got %s\n exp %s", normalized, exp)
+	}
+
+	// Queries that only differ in their condition values share a fingerprint.
+	if f2, _ := fingerprint(`SELECT mean(value) FROM cpu WHERE host = 'serverB' AND time > now() - 6h GROUP BY time(1m)`); f1 != f2 {
+		t.Fatalf("fingerprints differ: %s != %s", f1, f2)
+	}
+
+	// Queries with a different shape do not.
+	if f3, _ := fingerprint(`SELECT mean(value) FROM cpu WHERE host = 'serverA' AND time > now() - 1h GROUP BY time(5m)`); f1 == f3 {
+		t.Fatalf("fingerprints are equal: %s", f1)
+	}
+}
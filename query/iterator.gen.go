@@ -349,6 +349,10 @@ func (itr *floatSortedMergeIterator) Next() (*FloatPoint, error) { return itr.po
 func (itr *floatSortedMergeIterator) pop() (*FloatPoint, error) {
 	// Initialize the heap. See the MergeIterator to see why this has to be done lazily.
 	if !itr.init {
+		if err := itr.spill(); err != nil {
+			return nil, err
+		}
+
 		items := itr.heap.items
 		itr.heap.items = make([]*floatSortedMergeHeapItem, 0, len(items))
 		for _, item := range items {
@@ -387,6 +391,113 @@ func (itr *floatSortedMergeIterator) pop() (*FloatPoint, error) {
 	return p, nil
 }
 
+// spill merges the inputs a batch at a time into runs on disk while there are
+// more inputs than can be merged within opt.MaxMemory. The runs then replace
+// the inputs.
+func (itr *floatSortedMergeIterator) spill() error {
+	n := sortedMergeMaxInputs(itr.heap.opt.MaxMemory)
+	if n == 0 || len(itr.inputs) <= n {
+		return nil
+	}
+
+	for len(itr.inputs) > n {
+		inputs := itr.inputs
+		runs := make([]FloatIterator, 0, (len(inputs)+n-1)/n)
+		for len(inputs) > 0 {
+			batch := inputs
+			if len(batch) > n {
+				batch = batch[:n]
+			}
+			inputs = inputs[len(batch):]
+
+			run, err := spillFloatSortedMerge(batch, itr.heap.opt)
+			if err != nil {
+				// Keep the runs and remaining inputs so they are closed.
+				itr.inputs = append(runs, inputs...)
+				itr.heap.items = nil
+				return err
+			}
+			runs = append(runs, run)
+		}
+		itr.inputs = runs
+	}
+
+	itr.heap.items = make([]*floatSortedMergeHeapItem, 0, len(itr.inputs))
+	for _, input := range itr.inputs {
+		itr.heap.items = append(itr.heap.items, &floatSortedMergeHeapItem{itr: input})
+	}
+	return nil
+}
+
+// spillFloatSortedMerge writes the sorted merge of inputs to a spill file
+// and returns an iterator that reads it back. The inputs are closed.
+func spillFloatSortedMerge(inputs []FloatIterator, opt IteratorOptions) (FloatIterator, error) {
+	itr := newFloatSortedMergeIterator(inputs, opt).(*floatSortedMergeIterator)
+	defer itr.Close()
+
+	f, err := newSpillFile(opt.SpillDir)
+	if err != nil {
+		return nil, err
+	}
+
+	enc := NewFloatPointEncoder(f)
+	for {
+		select {
+		case <-opt.InterruptCh:
+			f.Close()
+			return nil, ErrQueryInterrupted
+		default:
+		}
+
+		p, err := itr.pop()
+		if err != nil {
+			f.Close()
+			return nil, err
+		} else if p == nil {
+			break
+		}
+
+		if err := enc.EncodeFloatPoint(p); err != nil {
+			f.Close()
+			return nil, err
+		}
+	}
+
+	if err := f.rewind(); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &floatSpillIterator{
+		f:     f,
+		dec:   NewFloatPointDecoder(context.Background(), f),
+		stats: itr.Stats(),
+	}, nil
+}
+
+// floatSpillIterator reads the points written to a spill file.
+type floatSpillIterator struct {
+	f     *spillFile
+	dec   *FloatPointDecoder
+	point FloatPoint
+	stats IteratorStats // stats of the iterators that were spilled
+}
+
+// Stats returns the stats of the iterators that were spilled.
+func (itr *floatSpillIterator) Stats() IteratorStats { return itr.stats }
+
+// Close closes and removes the spill file.
+func (itr *floatSpillIterator) Close() error { return itr.f.Close() }
+
+// Next returns the next point from the spill file.
+func (itr *floatSpillIterator) Next() (*FloatPoint, error) {
+	if err := itr.dec.DecodeFloatPoint(&itr.point); err == io.EOF {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return &itr.point, nil
+}
+
 // floatSortedMergeHeap represents a heap of floatSortedMergeHeapItems.
 // Items are sorted with the following priority:
 //     - By their measurement name;
@@ -1060,6 +1171,100 @@ func (itr *floatChanIterator) Next() (*FloatPoint, error) {
 	return p, nil
 }
 
+// floatReduceRun is a run of reduced points of a window spilled to disk.
+// The points are sorted in the order they are returned by a reduce iterator.
+type floatReduceRun struct {
+	f   *spillFile
+	dec *FloatPointDecoder
+	key string // group of p
+	p   FloatPoint
+}
+
+// next reads the next point of the run. It returns io.EOF at the end of the run.
+func (r *floatReduceRun) next() error {
+	key, err := readSpillKey(r.f)
+	if err != nil {
+		return err
+	}
+	r.key = key
+	return r.dec.DecodeFloatPoint(&r.p)
+}
+
+// floatReduceRuns merges the runs of a window whose groups did not fit
+// in memory.
+type floatReduceRuns []*floatReduceRun
+
+func (a floatReduceRuns) Len() int      { return len(a) }
+func (a floatReduceRuns) Swap(i, j int) { a[i], a[j] = a[j], a[i] }
+func (a floatReduceRuns) Less(i, j int) bool {
+	if a[i].p.Time != a[j].p.Time {
+		return a[i].p.Time < a[j].p.Time
+	}
+	return a[i].key < a[j].key
+}
+
+func (a *floatReduceRuns) Push(x interface{}) {
+	*a = append(*a, x.(*floatReduceRun))
+}
+
+func (a *floatReduceRuns) Pop() interface{} {
+	old := *a
+	n := len(old)
+	r := old[n-1]
+	*a = old[:n-1]
+	return r
+}
+
+// add adds the run written to f. The runs take ownership of f.
+func (a *floatReduceRuns) add(f *spillFile) error {
+	if err := f.rewind(); err != nil {
+		f.Close()
+		return err
+	}
+
+	r := &floatReduceRun{f: f, dec: NewFloatPointDecoder(context.Background(), f)}
+	if err := r.next(); err == io.EOF {
+		return f.Close()
+	} else if err != nil {
+		f.Close()
+		return err
+	}
+	heap.Push(a, r)
+	return nil
+}
+
+// read returns up to n of the next points of the runs. The points are in
+// reverse order so they can be popped off the end of the slice.
+func (a *floatReduceRuns) read(n int) ([]FloatPoint, error) {
+	points := make([]FloatPoint, 0, n)
+	for len(points) < n && len(*a) > 0 {
+		r := (*a)[0]
+		points = append(points, r.p)
+		if err := r.next(); err == io.EOF {
+			heap.Pop(a)
+			r.f.Close()
+		} else if err != nil {
+			return nil, err
+		} else {
+			heap.Fix(a, 0)
+		}
+	}
+
+	for i, j := 0, len(points)-1; i < j; i, j = i+1, j-1 {
+		points[i], points[j] = points[j], points[i]
+	}
+	return points, nil
+}
+
+// Close removes the files of the remaining runs.
+func (a *floatReduceRuns) Close() error {
+	for _, r := range *a {
+		r.f.Close()
+	}
+	*a = nil
+	return nil
+}
+
 // floatReduceFloatIterator executes a reducer for every interval and buffers the result.
 type floatReduceFloatIterator struct {
 	input    *bufFloatIterator
@@ -1068,6 +1273,9 @@ type floatReduceFloatIterator struct {
 	opt      IteratorOptions
 	points   []FloatPoint
 	keepTags bool
+
+	// Runs of the current window if its groups did not fit in memory.
+	runs *floatReduceRuns
 }
 
 func newFloatReduceFloatIterator(input FloatIterator, opt IteratorOptions, createFn func() (FloatPointAggregator, FloatPointEmitter)) *floatReduceFloatIterator {
@@ -1083,7 +1291,12 @@ func newFloatReduceFloatIterator(input FloatIterator, opt IteratorOptions, creat
 func (itr *floatReduceFloatIterator) Stats() IteratorStats { return itr.input.Stats() }
 
 // Close closes the iterator and all child iterators.
-func (itr *floatReduceFloatIterator) Close() error { return itr.input.Close() }
+func (itr *floatReduceFloatIterator) Close() error {
+	if itr.runs != nil {
+		itr.runs.Close()
+	}
+	return itr.input.Close()
+}
 
 // Next returns the minimum value for the next available interval.
 func (itr *floatReduceFloatIterator) Next() (*FloatPoint, error) {
@@ -1110,9 +1323,35 @@ type floatReduceFloatPoint struct {
 	Emitter    FloatPointEmitter
 }
 
+// floatReduceFloatWindow holds the groups of a window while its
+// points are aggregated.
+type floatReduceFloatWindow struct {
+	groups map[string]*floatReduceFloatPoint
+	size   int64 // estimated memory used by groups
+
+	// Points of the groups that did not fit within opt.MaxMemory.
+	spill *spillFile
+	enc   *FloatPointEncoder
+}
+
+// close removes the spill file of the window.
+func (w *floatReduceFloatWindow) close() {
+	if w.spill != nil {
+		w.spill.Close()
+	}
+}
+
 // reduce executes fn once for every point in the next window.
 // The previous value for the dimension is passed to fn.
 func (itr *floatReduceFloatIterator) reduce() ([]FloatPoint, error) {
+	// Return the remaining points of a window that was spilled to disk.
+	if itr.runs != nil {
+		if a, err := itr.runs.read(reduceSpillBatchSize); err != nil || len(a) > 0 {
+			return a, err
+		}
+		itr.runs = nil
+	}
+
 	// Calculate next window.
 	var (
 		startTime, endTime int64
@@ -1137,11 +1376,12 @@ func (itr *floatReduceFloatIterator) reduce() ([]FloatPoint, error) {
 	}
 
 	// Create points by tags.
-	m := make(map[string]*floatReduceFloatPoint)
+	w := &floatReduceFloatWindow{groups: make(map[string]*floatReduceFloatPoint)}
 	for {
 		// Read next point.
 		curr, err := itr.input.NextInWindow(startTime, endTime)
 		if err != nil {
+			w.close()
 			return nil, err
 		} else if curr == nil {
 			break
@@ -1161,26 +1401,57 @@ func (itr *floatReduceFloatIterator) reduce() ([]FloatPoint, error) {
 			break
 		}
 
-		// Retrieve the tags on this point for this level of the query.
-		// This may be different than the bucket dimensions.
-		tags := curr.Tags.Subset(itr.dims)
-		id := tags.ID()
+		if err := itr.aggregate(w, curr); err != nil {
+			w.close()
+			return nil, err
+		}
+	}
 
-		// Retrieve the aggregator for this name/tag combination or create one.
-		rp := m[id]
-		if rp == nil {
-			aggregator, emitter := itr.create()
-			rp = &floatReduceFloatPoint{
-				Name:       curr.Name,
-				Tags:       tags,
-				Aggregator: aggregator,
-				Emitter:    emitter,
+	if w.spill != nil {
+		return itr.reduceSpilled(w, startTime)
+	}
+	return itr.emit(w.groups, startTime), nil
+}
+
+// aggregate adds p to the aggregator of its group in w. If p belongs to a new
+// group and the groups of w already use opt.MaxMemory, p is spilled to
+// disk instead.
+func (itr *floatReduceFloatIterator) aggregate(w *floatReduceFloatWindow, p *FloatPoint) error {
+	// Retrieve the tags on this point for this level of the query.
+	// This may be different than the bucket dimensions.
+	tags := p.Tags.Subset(itr.dims)
+	id := tags.ID()
+
+	// Retrieve the aggregator for this name/tag combination or create one.
+	rp := w.groups[id]
+	if rp == nil {
+		if max := itr.opt.MaxMemory; max > 0 && w.size >= max {
+			if w.spill == nil {
+				f, err := newSpillFile(itr.opt.SpillDir)
+				if err != nil {
+					return err
+				}
+				w.spill, w.enc = f, NewFloatPointEncoder(f)
 			}
-			m[id] = rp
+			return w.enc.EncodeFloatPoint(p)
 		}
-		rp.Aggregator.AggregateFloat(curr)
+
+		aggregator, emitter := itr.create()
+		rp = &floatReduceFloatPoint{
+			Name:       p.Name,
+			Tags:       tags,
+			Aggregator: aggregator,
+			Emitter:    emitter,
+		}
+		w.groups[id] = rp
+		w.size += reduceGroupSize(p.Name, id)
 	}
+	rp.Aggregator.AggregateFloat(p)
+	return nil
+}
 
+// emit returns the points of every group of a window in reverse order.
+func (itr *floatReduceFloatIterator) emit(m map[string]*floatReduceFloatPoint, startTime int64) []FloatPoint {
 	// Reverse sort points by name & tag if our output is supposed to be ordered.
 	keys := make([]string, 0, len(m))
 	for k := range m {
@@ -1217,7 +1488,115 @@ func (itr *floatReduceFloatIterator) reduce() ([]FloatPoint, error) {
 		sort.Stable(sort.Reverse(floatPointsByTime(a)))
 	}
 
-	return a, nil
+	return a
+}
+
+// reduceSpilled reduces a window whose groups did not fit in memory. The
+// points of the groups in memory are written to a run on disk and the points
+// spilled by w are reduced in the same way, until every group is reduced. The
+// runs are then merged in the order the points would have been emitted.
+func (itr *floatReduceFloatIterator) reduceSpilled(w *floatReduceFloatWindow, startTime int64) ([]FloatPoint, error) {
+	runs := &floatReduceRuns{}
+	for {
+		if err := itr.writeRun(runs, w.groups, startTime); err != nil {
+			w.close()
+			runs.Close()
+			return nil, err
+		} else if w.spill == nil {
+			break
+		}
+
+		input := w.spill
+		next, err := itr.reduceSpillFile(input)
+		input.Close()
+		if err != nil {
+			runs.Close()
+			return nil, err
+		}
+		w = next
+	}
+
+	itr.runs = runs
+	return runs.read(reduceSpillBatchSize)
+}
+
+// reduceSpillFile aggregates the points spilled to f into a new window.
+func (itr *floatReduceFloatIterator) reduceSpillFile(f *spillFile) (*floatReduceFloatWindow, error) {
+	if err := f.rewind(); err != nil {
+		return nil, err
+	}
+	dec := NewFloatPointDecoder(context.Background(), f)
+
+	w := &floatReduceFloatWindow{groups: make(map[string]*floatReduceFloatPoint)}
+	for {
+		select {
+		case <-itr.opt.InterruptCh:
+			w.close()
+			return nil, ErrQueryInterrupted
+		default:
+		}
+
+		var p FloatPoint
+		if err := dec.DecodeFloatPoint(&p); err == io.EOF {
+			return w, nil
+		} else if err != nil {
+			w.close()
+			return nil, err
+		}
+
+		if err := itr.aggregate(w, &p); err != nil {
+			w.close()
+			return nil, err
+		}
+	}
+}
+
+// writeRun writes the points of the groups in m to a new run of runs. The
+// points are sorted by time and then by group, which is the order they are
+// returned in when the groups all fit in memory.
+func (itr *floatReduceFloatIterator) writeRun(runs *floatReduceRuns, m map[string]*floatReduceFloatPoint, startTime int64) error {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	type record struct {
+		key string
+		p   FloatPoint
+	}
+	records := make([]record, 0, len(keys))
+	for _, k := range keys {
+		rp := m[k]
+		for _, p := range rp.Emitter.Emit() {
+			p.Name = rp.Name
+			if !itr.keepTags {
+				p.Tags = rp.Tags
+			}
+			// Set the points time to the interval time if the reducer didn't provide one.
+			if p.Time == ZeroTime {
+				p.Time = startTime
+			}
+			records = append(records, record{key: k, p: p})
+		}
+	}
+	sort.SliceStable(records, func(i, j int) bool { return records[i].p.Time < records[j].p.Time })
+
+	f, err := newSpillFile(itr.opt.SpillDir)
+	if err != nil {
+		return err
+	}
+	enc := NewFloatPointEncoder(f)
+	for i := range records {
+		if err := writeSpillKey(f, records[i].key); err != nil {
+			f.Close()
+			return err
+		} else if err := enc.EncodeFloatPoint(&records[i].p); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	return runs.add(f)
 }
 
 // floatStreamFloatIterator streams inputs into the iterator and emits points gradually.
@@ -1483,6 +1862,9 @@ type floatReduceIntegerIterator struct {
 	opt      IteratorOptions
 	points   []IntegerPoint
 	keepTags bool
+
+	// Runs of the current window if its groups did not fit in memory.
+	runs *integerReduceRuns
 }
 
 func newFloatReduceIntegerIterator(input FloatIterator, opt IteratorOptions, createFn func() (FloatPointAggregator, IntegerPointEmitter)) *floatReduceIntegerIterator {
@@ -1498,7 +1880,12 @@ func newFloatReduceIntegerIterator(input FloatIterator, opt IteratorOptions, cre
 func (itr *floatReduceIntegerIterator) Stats() IteratorStats { return itr.input.Stats() }
 
 // Close closes the iterator and all child iterators.
-func (itr *floatReduceIntegerIterator) Close() error { return itr.input.Close() }
+func (itr *floatReduceIntegerIterator) Close() error {
+	if itr.runs != nil {
+		itr.runs.Close()
+	}
+	return itr.input.Close()
+}
 
 // Next returns the minimum value for the next available interval.
 func (itr *floatReduceIntegerIterator) Next() (*IntegerPoint, error) {
@@ -1525,9 +1912,35 @@ type floatReduceIntegerPoint struct {
 	Emitter    IntegerPointEmitter
 }
 
+// floatReduceIntegerWindow holds the groups of a window while its
+// points are aggregated.
+type floatReduceIntegerWindow struct {
+	groups map[string]*floatReduceIntegerPoint
+	size   int64 // estimated memory used by groups
+
+	// Points of the groups that did not fit within opt.MaxMemory.
+	spill *spillFile
+	enc   *FloatPointEncoder
+}
+
+// close removes the spill file of the window.
+func (w *floatReduceIntegerWindow) close() {
+	if w.spill != nil {
+		w.spill.Close()
+	}
+}
+
 // reduce executes fn once for every point in the next window.
 // The previous value for the dimension is passed to fn.
 func (itr *floatReduceIntegerIterator) reduce() ([]IntegerPoint, error) {
+	// Return the remaining points of a window that was spilled to disk.
+	if itr.runs != nil {
+		if a, err := itr.runs.read(reduceSpillBatchSize); err != nil || len(a) > 0 {
+			return a, err
+		}
+		itr.runs = nil
+	}
+
 	// Calculate next window.
 	var (
 		startTime, endTime int64
@@ -1552,11 +1965,12 @@ func (itr *floatReduceIntegerIterator) reduce() ([]IntegerPoint, error) {
 	}
 
 	// Create points by tags.
-	m := make(map[string]*floatReduceIntegerPoint)
+	w := &floatReduceIntegerWindow{groups: make(map[string]*floatReduceIntegerPoint)}
 	for {
 		// Read next point.
 		curr, err := itr.input.NextInWindow(startTime, endTime)
 		if err != nil {
+			w.close()
 			return nil, err
 		} else if curr == nil {
 			break
@@ -1576,26 +1990,57 @@ func (itr *floatReduceIntegerIterator) reduce() ([]IntegerPoint, error) {
 			break
 		}
 
-		// Retrieve the tags on this point for this level of the query.
-		// This may be different than the bucket dimensions.
-		tags := curr.Tags.Subset(itr.dims)
-		id := tags.ID()
+		if err := itr.aggregate(w, curr); err != nil {
+			w.close()
+			return nil, err
+		}
+	}
 
-		// Retrieve the aggregator for this name/tag combination or create one.
-		rp := m[id]
-		if rp == nil {
-			aggregator, emitter := itr.create()
-			rp = &floatReduceIntegerPoint{
-				Name:       curr.Name,
-				Tags:       tags,
-				Aggregator: aggregator,
-				Emitter:    emitter,
+	if w.spill != nil {
+		return itr.reduceSpilled(w, startTime)
+	}
+	return itr.emit(w.groups, startTime), nil
+}
+
+// aggregate adds p to the aggregator of its group in w. If p belongs to a new
+// group and the groups of w already use opt.MaxMemory, p is spilled to
+// disk instead.
+func (itr *floatReduceIntegerIterator) aggregate(w *floatReduceIntegerWindow, p *FloatPoint) error {
+	// Retrieve the tags on this point for this level of the query.
+	// This may be different than the bucket dimensions.
+	tags := p.Tags.Subset(itr.dims)
+	id := tags.ID()
+
+	// Retrieve the aggregator for this name/tag combination or create one.
+	rp := w.groups[id]
+	if rp == nil {
+		if max := itr.opt.MaxMemory; max > 0 && w.size >= max {
+			if w.spill == nil {
+				f, err := newSpillFile(itr.opt.SpillDir)
+				if err != nil {
+					return err
+				}
+				w.spill, w.enc = f, NewFloatPointEncoder(f)
 			}
-			m[id] = rp
+			return w.enc.EncodeFloatPoint(p)
 		}
-		rp.Aggregator.AggregateFloat(curr)
+
+		aggregator, emitter := itr.create()
+		rp = &floatReduceIntegerPoint{
+			Name:       p.Name,
+			Tags:       tags,
+			Aggregator: aggregator,
+			Emitter:    emitter,
+		}
+		w.groups[id] = rp
+		w.size += reduceGroupSize(p.Name, id)
 	}
+	rp.Aggregator.AggregateFloat(p)
+	return nil
+}
 
+// emit returns the points of every group of a window in reverse order.
+func (itr *floatReduceIntegerIterator) emit(m map[string]*floatReduceIntegerPoint, startTime int64) []IntegerPoint {
 	// Reverse sort points by name & tag if our output is supposed to be ordered.
 	keys := make([]string, 0, len(m))
 	for k := range m {
@@ -1632,38 +2077,146 @@ func (itr *floatReduceIntegerIterator) reduce() ([]IntegerPoint, error) {
 		sort.Stable(sort.Reverse(integerPointsByTime(a)))
 	}
 
-	return a, nil
+	return a
 }
 
-// floatStreamIntegerIterator streams inputs into the iterator and emits points gradually.
-type floatStreamIntegerIterator struct {
-	input  *bufFloatIterator
-	create func() (FloatPointAggregator, IntegerPointEmitter)
-	dims   []string
-	opt    IteratorOptions
-	m      map[string]*floatReduceIntegerPoint
-	points []IntegerPoint
-}
+// reduceSpilled reduces a window whose groups did not fit in memory. The
+// points of the groups in memory are written to a run on disk and the points
+// spilled by w are reduced in the same way, until every group is reduced. The
+// runs are then merged in the order the points would have been emitted.
+func (itr *floatReduceIntegerIterator) reduceSpilled(w *floatReduceIntegerWindow, startTime int64) ([]IntegerPoint, error) {
+	runs := &integerReduceRuns{}
+	for {
+		if err := itr.writeRun(runs, w.groups, startTime); err != nil {
+			w.close()
+			runs.Close()
+			return nil, err
+		} else if w.spill == nil {
+			break
+		}
 
-// newFloatStreamIntegerIterator returns a new instance of floatStreamIntegerIterator.
-func newFloatStreamIntegerIterator(input FloatIterator, createFn func() (FloatPointAggregator, IntegerPointEmitter), opt IteratorOptions) *floatStreamIntegerIterator {
-	return &floatStreamIntegerIterator{
-		input:  newBufFloatIterator(input),
-		create: createFn,
-		dims:   opt.GetDimensions(),
-		opt:    opt,
-		m:      make(map[string]*floatReduceIntegerPoint),
+		input := w.spill
+		next, err := itr.reduceSpillFile(input)
+		input.Close()
+		if err != nil {
+			runs.Close()
+			return nil, err
+		}
+		w = next
 	}
-}
 
-// Stats returns stats from the input iterator.
-func (itr *floatStreamIntegerIterator) Stats() IteratorStats { return itr.input.Stats() }
+	itr.runs = runs
+	return runs.read(reduceSpillBatchSize)
+}
 
-// Close closes the iterator and all child iterators.
-func (itr *floatStreamIntegerIterator) Close() error { return itr.input.Close() }
+// reduceSpillFile aggregates the points spilled to f into a new window.
+func (itr *floatReduceIntegerIterator) reduceSpillFile(f *spillFile) (*floatReduceIntegerWindow, error) {
+	if err := f.rewind(); err != nil {
+		return nil, err
+	}
+	dec := NewFloatPointDecoder(context.Background(), f)
 
-// Next returns the next value for the stream iterator.
-func (itr *floatStreamIntegerIterator) Next() (*IntegerPoint, error) {
+	w := &floatReduceIntegerWindow{groups: make(map[string]*floatReduceIntegerPoint)}
+	for {
+		select {
+		case <-itr.opt.InterruptCh:
+			w.close()
+			return nil, ErrQueryInterrupted
+		default:
+		}
+
+		var p FloatPoint
+		if err := dec.DecodeFloatPoint(&p); err == io.EOF {
+			return w, nil
+		} else if err != nil {
+			w.close()
+			return nil, err
+		}
+
+		if err := itr.aggregate(w, &p); err != nil {
+			w.close()
+			return nil, err
+		}
+	}
+}
+
+// writeRun writes the points of the groups in m to a new run of runs. The
+// points are sorted by time and then by group, which is the order they are
+// returned in when the groups all fit in memory.
+func (itr *floatReduceIntegerIterator) writeRun(runs *integerReduceRuns, m map[string]*floatReduceIntegerPoint, startTime int64) error {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	type record struct {
+		key string
+		p   IntegerPoint
+	}
+	records := make([]record, 0, len(keys))
+	for _, k := range keys {
+		rp := m[k]
+		for _, p := range rp.Emitter.Emit() {
+			p.Name = rp.Name
+			if !itr.keepTags {
+				p.Tags = rp.Tags
+			}
+			// Set the points time to the interval time if the reducer didn't provide one.
+			if p.Time == ZeroTime {
+				p.Time = startTime
+			}
+			records = append(records, record{key: k, p: p})
+		}
+	}
+	sort.SliceStable(records, func(i, j int) bool { return records[i].p.Time < records[j].p.Time })
+
+	f, err := newSpillFile(itr.opt.SpillDir)
+	if err != nil {
+		return err
+	}
+	enc := NewIntegerPointEncoder(f)
+	for i := range records {
+		if err := writeSpillKey(f, records[i].key); err != nil {
+			f.Close()
+			return err
+		} else if err := enc.EncodeIntegerPoint(&records[i].p); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	return runs.add(f)
+}
+
+// floatStreamIntegerIterator streams inputs into the iterator and emits points gradually.
+type floatStreamIntegerIterator struct {
+	input  *bufFloatIterator
+	create func() (FloatPointAggregator, IntegerPointEmitter)
+	dims   []string
+	opt    IteratorOptions
+	m      map[string]*floatReduceIntegerPoint
+	points []IntegerPoint
+}
+
+// newFloatStreamIntegerIterator returns a new instance of floatStreamIntegerIterator.
+func newFloatStreamIntegerIterator(input FloatIterator, createFn func() (FloatPointAggregator, IntegerPointEmitter), opt IteratorOptions) *floatStreamIntegerIterator {
+	return &floatStreamIntegerIterator{
+		input:  newBufFloatIterator(input),
+		create: createFn,
+		dims:   opt.GetDimensions(),
+		opt:    opt,
+		m:      make(map[string]*floatReduceIntegerPoint),
+	}
+}
+
+// Stats returns stats from the input iterator.
+func (itr *floatStreamIntegerIterator) Stats() IteratorStats { return itr.input.Stats() }
+
+// Close closes the iterator and all child iterators.
+func (itr *floatStreamIntegerIterator) Close() error { return itr.input.Close() }
+
+// Next returns the next value for the stream iterator.
+func (itr *floatStreamIntegerIterator) Next() (*IntegerPoint, error) {
 	// Calculate next window if we have no more points.
 	if len(itr.points) == 0 {
 		var err error
@@ -1902,6 +2455,9 @@ type floatReduceUnsignedIterator struct {
 	opt      IteratorOptions
 	points   []UnsignedPoint
 	keepTags bool
+
+	// Runs of the current window if its groups did not fit in memory.
+	runs *unsignedReduceRuns
 }
 
 func newFloatReduceUnsignedIterator(input FloatIterator, opt IteratorOptions, createFn func() (FloatPointAggregator, UnsignedPointEmitter)) *floatReduceUnsignedIterator {
@@ -1917,7 +2473,12 @@ func newFloatReduceUnsignedIterator(input FloatIterator, opt IteratorOptions, cr
 func (itr *floatReduceUnsignedIterator) Stats() IteratorStats { return itr.input.Stats() }
 
 // Close closes the iterator and all child iterators.
-func (itr *floatReduceUnsignedIterator) Close() error { return itr.input.Close() }
+func (itr *floatReduceUnsignedIterator) Close() error {
+	if itr.runs != nil {
+		itr.runs.Close()
+	}
+	return itr.input.Close()
+}
 
 // Next returns the minimum value for the next available interval.
 func (itr *floatReduceUnsignedIterator) Next() (*UnsignedPoint, error) {
@@ -1944,9 +2505,35 @@ type floatReduceUnsignedPoint struct {
 	Emitter    UnsignedPointEmitter
 }
 
+// floatReduceUnsignedWindow holds the groups of a window while its
+// points are aggregated.
+type floatReduceUnsignedWindow struct {
+	groups map[string]*floatReduceUnsignedPoint
+	size   int64 // estimated memory used by groups
+
+	// Points of the groups that did not fit within opt.MaxMemory.
+	spill *spillFile
+	enc   *FloatPointEncoder
+}
+
+// close removes the spill file of the window.
+func (w *floatReduceUnsignedWindow) close() {
+	if w.spill != nil {
+		w.spill.Close()
+	}
+}
+
 // reduce executes fn once for every point in the next window.
 // The previous value for the dimension is passed to fn.
 func (itr *floatReduceUnsignedIterator) reduce() ([]UnsignedPoint, error) {
+	// Return the remaining points of a window that was spilled to disk.
+	if itr.runs != nil {
+		if a, err := itr.runs.read(reduceSpillBatchSize); err != nil || len(a) > 0 {
+			return a, err
+		}
+		itr.runs = nil
+	}
+
 	// Calculate next window.
 	var (
 		startTime, endTime int64
@@ -1971,11 +2558,12 @@ func (itr *floatReduceUnsignedIterator) reduce() ([]UnsignedPoint, error) {
 	}
 
 	// Create points by tags.
-	m := make(map[string]*floatReduceUnsignedPoint)
+	w := &floatReduceUnsignedWindow{groups: make(map[string]*floatReduceUnsignedPoint)}
 	for {
 		// Read next point.
 		curr, err := itr.input.NextInWindow(startTime, endTime)
 		if err != nil {
+			w.close()
 			return nil, err
 		} else if curr == nil {
 			break
@@ -1995,26 +2583,57 @@ func (itr *floatReduceUnsignedIterator) reduce() ([]UnsignedPoint, error) {
 			break
 		}
 
-		// Retrieve the tags on this point for this level of the query.
-		// This may be different than the bucket dimensions.
-		tags := curr.Tags.Subset(itr.dims)
-		id := tags.ID()
+		if err := itr.aggregate(w, curr); err != nil {
+			w.close()
+			return nil, err
+		}
+	}
 
-		// Retrieve the aggregator for this name/tag combination or create one.
-		rp := m[id]
-		if rp == nil {
-			aggregator, emitter := itr.create()
-			rp = &floatReduceUnsignedPoint{
-				Name:       curr.Name,
-				Tags:       tags,
-				Aggregator: aggregator,
-				Emitter:    emitter,
+	if w.spill != nil {
+		return itr.reduceSpilled(w, startTime)
+	}
+	return itr.emit(w.groups, startTime), nil
+}
+
+// aggregate adds p to the aggregator of its group in w. If p belongs to a new
+// group and the groups of w already use opt.MaxMemory, p is spilled to
+// disk instead.
+func (itr *floatReduceUnsignedIterator) aggregate(w *floatReduceUnsignedWindow, p *FloatPoint) error {
+	// Retrieve the tags on this point for this level of the query.
+	// This may be different than the bucket dimensions.
+	tags := p.Tags.Subset(itr.dims)
+	id := tags.ID()
+
+	// Retrieve the aggregator for this name/tag combination or create one.
+	rp := w.groups[id]
+	if rp == nil {
+		if max := itr.opt.MaxMemory; max > 0 && w.size >= max {
+			if w.spill == nil {
+				f, err := newSpillFile(itr.opt.SpillDir)
+				if err != nil {
+					return err
+				}
+				w.spill, w.enc = f, NewFloatPointEncoder(f)
 			}
-			m[id] = rp
+			return w.enc.EncodeFloatPoint(p)
 		}
-		rp.Aggregator.AggregateFloat(curr)
+
+		aggregator, emitter := itr.create()
+		rp = &floatReduceUnsignedPoint{
+			Name:       p.Name,
+			Tags:       tags,
+			Aggregator: aggregator,
+			Emitter:    emitter,
+		}
+		w.groups[id] = rp
+		w.size += reduceGroupSize(p.Name, id)
 	}
+	rp.Aggregator.AggregateFloat(p)
+	return nil
+}
 
+// emit returns the points of every group of a window in reverse order.
+func (itr *floatReduceUnsignedIterator) emit(m map[string]*floatReduceUnsignedPoint, startTime int64) []UnsignedPoint {
 	// Reverse sort points by name & tag if our output is supposed to be ordered.
 	keys := make([]string, 0, len(m))
 	for k := range m {
@@ -2051,7 +2670,115 @@ func (itr *floatReduceUnsignedIterator) reduce() ([]UnsignedPoint, error) {
 		sort.Stable(sort.Reverse(unsignedPointsByTime(a)))
 	}
 
-	return a, nil
+	return a
+}
+
+// reduceSpilled reduces a window whose groups did not fit in memory. The
+// points of the groups in memory are written to a run on disk and the points
+// spilled by w are reduced in the same way, until every group is reduced. The
+// runs are then merged in the order the points would have been emitted.
+func (itr *floatReduceUnsignedIterator) reduceSpilled(w *floatReduceUnsignedWindow, startTime int64) ([]UnsignedPoint, error) {
+	runs := &unsignedReduceRuns{}
+	for {
+		if err := itr.writeRun(runs, w.groups, startTime); err != nil {
+			w.close()
+			runs.Close()
+			return nil, err
+		} else if w.spill == nil {
+			break
+		}
+
+		input := w.spill
+		next, err := itr.reduceSpillFile(input)
+		input.Close()
+		if err != nil {
+			runs.Close()
+			return nil, err
+		}
+		w = next
+	}
+
+	itr.runs = runs
+	return runs.read(reduceSpillBatchSize)
+}
+
+// reduceSpillFile aggregates the points spilled to f into a new window.
+func (itr *floatReduceUnsignedIterator) reduceSpillFile(f *spillFile) (*floatReduceUnsignedWindow, error) {
+	if err := f.rewind(); err != nil {
+		return nil, err
+	}
+	dec := NewFloatPointDecoder(context.Background(), f)
+
+	w := &floatReduceUnsignedWindow{groups: make(map[string]*floatReduceUnsignedPoint)}
+	for {
+		select {
+		case <-itr.opt.InterruptCh:
+			w.close()
+			return nil, ErrQueryInterrupted
+		default:
+		}
+
+		var p FloatPoint
+		if err := dec.DecodeFloatPoint(&p); err == io.EOF {
+			return w, nil
+		} else if err != nil {
+			w.close()
+			return nil, err
+		}
+
+		if err := itr.aggregate(w, &p); err != nil {
+			w.close()
+			return nil, err
+		}
+	}
+}
+
+// writeRun writes the points of the groups in m to a new run of runs. The
+// points are sorted by time and then by group, which is the order they are
+// returned in when the groups all fit in memory.
+func (itr *floatReduceUnsignedIterator) writeRun(runs *unsignedReduceRuns, m map[string]*floatReduceUnsignedPoint, startTime int64) error {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	type record struct {
+		key string
+		p   UnsignedPoint
+	}
+	records := make([]record, 0, len(keys))
+	for _, k := range keys {
+		rp := m[k]
+		for _, p := range rp.Emitter.Emit() {
+			p.Name = rp.Name
+			if !itr.keepTags {
+				p.Tags = rp.Tags
+			}
+			// Set the points time to the interval time if the reducer didn't provide one.
+			if p.Time == ZeroTime {
+				p.Time = startTime
+			}
+			records = append(records, record{key: k, p: p})
+		}
+	}
+	sort.SliceStable(records, func(i, j int) bool { return records[i].p.Time < records[j].p.Time })
+
+	f, err := newSpillFile(itr.opt.SpillDir)
+	if err != nil {
+		return err
+	}
+	enc := NewUnsignedPointEncoder(f)
+	for i := range records {
+		if err := writeSpillKey(f, records[i].key); err != nil {
+			f.Close()
+			return err
+		} else if err := enc.EncodeUnsignedPoint(&records[i].p); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	return runs.add(f)
 }
 
 // floatStreamUnsignedIterator streams inputs into the iterator and emits points gradually.
@@ -2321,6 +3048,9 @@ type floatReduceStringIterator struct {
 	opt      IteratorOptions
 	points   []StringPoint
 	keepTags bool
+
+	// Runs of the current window if its groups did not fit in memory.
+	runs *stringReduceRuns
 }
 
 func newFloatReduceStringIterator(input FloatIterator, opt IteratorOptions, createFn func() (FloatPointAggregator, StringPointEmitter)) *floatReduceStringIterator {
@@ -2336,7 +3066,12 @@ func newFloatReduceStringIterator(input FloatIterator, opt IteratorOptions, crea
 func (itr *floatReduceStringIterator) Stats() IteratorStats { return itr.input.Stats() }
 
 // Close closes the iterator and all child iterators.
-func (itr *floatReduceStringIterator) Close() error { return itr.input.Close() }
+func (itr *floatReduceStringIterator) Close() error {
+	if itr.runs != nil {
+		itr.runs.Close()
+	}
+	return itr.input.Close()
+}
 
 // Next returns the minimum value for the next available interval.
 func (itr *floatReduceStringIterator) Next() (*StringPoint, error) {
@@ -2363,9 +3098,35 @@ type floatReduceStringPoint struct {
 	Emitter    StringPointEmitter
 }
 
+// floatReduceStringWindow holds the groups of a window while its
+// points are aggregated.
+type floatReduceStringWindow struct {
+	groups map[string]*floatReduceStringPoint
+	size   int64 // estimated memory used by groups
+
+	// Points of the groups that did not fit within opt.MaxMemory.
+	spill *spillFile
+	enc   *FloatPointEncoder
+}
+
+// close removes the spill file of the window.
+func (w *floatReduceStringWindow) close() {
+	if w.spill != nil {
+		w.spill.Close()
+	}
+}
+
 // reduce executes fn once for every point in the next window.
 // The previous value for the dimension is passed to fn.
 func (itr *floatReduceStringIterator) reduce() ([]StringPoint, error) {
+	// Return the remaining points of a window that was spilled to disk.
+	if itr.runs != nil {
+		if a, err := itr.runs.read(reduceSpillBatchSize); err != nil || len(a) > 0 {
+			return a, err
+		}
+		itr.runs = nil
+	}
+
 	// Calculate next window.
 	var (
 		startTime, endTime int64
@@ -2390,11 +3151,12 @@ func (itr *floatReduceStringIterator) reduce() ([]StringPoint, error) {
 	}
 
 	// Create points by tags.
-	m := make(map[string]*floatReduceStringPoint)
+	w := &floatReduceStringWindow{groups: make(map[string]*floatReduceStringPoint)}
 	for {
 		// Read next point.
 		curr, err := itr.input.NextInWindow(startTime, endTime)
 		if err != nil {
+			w.close()
 			return nil, err
 		} else if curr == nil {
 			break
@@ -2414,26 +3176,57 @@ func (itr *floatReduceStringIterator) reduce() ([]StringPoint, error) {
 			break
 		}
 
-		// Retrieve the tags on this point for this level of the query.
-		// This may be different than the bucket dimensions.
-		tags := curr.Tags.Subset(itr.dims)
-		id := tags.ID()
+		if err := itr.aggregate(w, curr); err != nil {
+			w.close()
+			return nil, err
+		}
+	}
 
-		// Retrieve the aggregator for this name/tag combination or create one.
-		rp := m[id]
-		if rp == nil {
-			aggregator, emitter := itr.create()
-			rp = &floatReduceStringPoint{
-				Name:       curr.Name,
-				Tags:       tags,
-				Aggregator: aggregator,
-				Emitter:    emitter,
+	if w.spill != nil {
+		return itr.reduceSpilled(w, startTime)
+	}
+	return itr.emit(w.groups, startTime), nil
+}
+
+// aggregate adds p to the aggregator of its group in w. If p belongs to a new
+// group and the groups of w already use opt.MaxMemory, p is spilled to
+// disk instead.
+func (itr *floatReduceStringIterator) aggregate(w *floatReduceStringWindow, p *FloatPoint) error {
+	// Retrieve the tags on this point for this level of the query.
+	// This may be different than the bucket dimensions.
+	tags := p.Tags.Subset(itr.dims)
+	id := tags.ID()
+
+	// Retrieve the aggregator for this name/tag combination or create one.
+	rp := w.groups[id]
+	if rp == nil {
+		if max := itr.opt.MaxMemory; max > 0 && w.size >= max {
+			if w.spill == nil {
+				f, err := newSpillFile(itr.opt.SpillDir)
+				if err != nil {
+					return err
+				}
+				w.spill, w.enc = f, NewFloatPointEncoder(f)
 			}
-			m[id] = rp
+			return w.enc.EncodeFloatPoint(p)
 		}
-		rp.Aggregator.AggregateFloat(curr)
+
+		aggregator, emitter := itr.create()
+		rp = &floatReduceStringPoint{
+			Name:       p.Name,
+			Tags:       tags,
+			Aggregator: aggregator,
+			Emitter:    emitter,
+		}
+		w.groups[id] = rp
+		w.size += reduceGroupSize(p.Name, id)
 	}
+	rp.Aggregator.AggregateFloat(p)
+	return nil
+}
 
+// emit returns the points of every group of a window in reverse order.
+func (itr *floatReduceStringIterator) emit(m map[string]*floatReduceStringPoint, startTime int64) []StringPoint {
 	// Reverse sort points by name & tag if our output is supposed to be ordered.
 	keys := make([]string, 0, len(m))
 	for k := range m {
@@ -2470,7 +3263,115 @@ func (itr *floatReduceStringIterator) reduce() ([]StringPoint, error) {
 		sort.Stable(sort.Reverse(stringPointsByTime(a)))
 	}
 
-	return a, nil
+	return a
+}
+
+// reduceSpilled reduces a window whose groups did not fit in memory. The
+// points of the groups in memory are written to a run on disk and the points
+// spilled by w are reduced in the same way, until every group is reduced. The
+// runs are then merged in the order the points would have been emitted.
+func (itr *floatReduceStringIterator) reduceSpilled(w *floatReduceStringWindow, startTime int64) ([]StringPoint, error) {
+	runs := &stringReduceRuns{}
+	for {
+		if err := itr.writeRun(runs, w.groups, startTime); err != nil {
+			w.close()
+			runs.Close()
+			return nil, err
+		} else if w.spill == nil {
+			break
+		}
+
+		input := w.spill
+		next, err := itr.reduceSpillFile(input)
+		input.Close()
+		if err != nil {
+			runs.Close()
+			return nil, err
+		}
+		w = next
+	}
+
+	itr.runs = runs
+	return runs.read(reduceSpillBatchSize)
+}
+
+// reduceSpillFile aggregates the points spilled to f into a new window.
+func (itr *floatReduceStringIterator) reduceSpillFile(f *spillFile) (*floatReduceStringWindow, error) {
+	if err := f.rewind(); err != nil {
+		return nil, err
+	}
+	dec := NewFloatPointDecoder(context.Background(), f)
+
+	w := &floatReduceStringWindow{groups: make(map[string]*floatReduceStringPoint)}
+	for {
+		select {
+		case <-itr.opt.InterruptCh:
+			w.close()
+			return nil, ErrQueryInterrupted
+		default:
+		}
+
+		var p FloatPoint
+		if err := dec.DecodeFloatPoint(&p); err == io.EOF {
+			return w, nil
+		} else if err != nil {
+			w.close()
+			return nil, err
+		}
+
+		if err := itr.aggregate(w, &p); err != nil {
+			w.close()
+			return nil, err
+		}
+	}
+}
+
+// writeRun writes the points of the groups in m to a new run of runs. The
+// points are sorted by time and then by group, which is the order they are
+// returned in when the groups all fit in memory.
+func (itr *floatReduceStringIterator) writeRun(runs *stringReduceRuns, m map[string]*floatReduceStringPoint, startTime int64) error {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	type record struct {
+		key string
+		p   StringPoint
+	}
+	records := make([]record, 0, len(keys))
+	for _, k := range keys {
+		rp := m[k]
+		for _, p := range rp.Emitter.Emit() {
+			p.Name = rp.Name
+			if !itr.keepTags {
+				p.Tags = rp.Tags
+			}
+			// Set the points time to the interval time if the reducer didn't provide one.
+			if p.Time == ZeroTime {
+				p.Time = startTime
+			}
+			records = append(records, record{key: k, p: p})
+		}
+	}
+	sort.SliceStable(records, func(i, j int) bool { return records[i].p.Time < records[j].p.Time })
+
+	f, err := newSpillFile(itr.opt.SpillDir)
+	if err != nil {
+		return err
+	}
+	enc := NewStringPointEncoder(f)
+	for i := range records {
+		if err := writeSpillKey(f, records[i].key); err != nil {
+			f.Close()
+			return err
+		} else if err := enc.EncodeStringPoint(&records[i].p); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	return runs.add(f)
 }
 
 // floatStreamStringIterator streams inputs into the iterator and emits points gradually.
@@ -2740,6 +3641,9 @@ type floatReduceBooleanIterator struct {
 	opt      IteratorOptions
 	points   []BooleanPoint
 	keepTags bool
+
+	// Runs of the current window if its groups did not fit in memory.
+	runs *booleanReduceRuns
 }
 
 func newFloatReduceBooleanIterator(input FloatIterator, opt IteratorOptions, createFn func() (FloatPointAggregator, BooleanPointEmitter)) *floatReduceBooleanIterator {
@@ -2755,7 +3659,12 @@ func newFloatReduceBooleanIterator(input FloatIterator, opt IteratorOptions, cre
 func (itr *floatReduceBooleanIterator) Stats() IteratorStats { return itr.input.Stats() }
 
 // Close closes the iterator and all child iterators.
-func (itr *floatReduceBooleanIterator) Close() error { return itr.input.Close() }
+func (itr *floatReduceBooleanIterator) Close() error {
+	if itr.runs != nil {
+		itr.runs.Close()
+	}
+	return itr.input.Close()
+}
 
 // Next returns the minimum value for the next available interval.
 func (itr *floatReduceBooleanIterator) Next() (*BooleanPoint, error) {
@@ -2782,9 +3691,35 @@ type floatReduceBooleanPoint struct {
 	Emitter    BooleanPointEmitter
 }
 
-// reduce executes fn once for every point in the next window.
-// The previous value for the dimension is passed to fn.
-func (itr *floatReduceBooleanIterator) reduce() ([]BooleanPoint, error) {
+// floatReduceBooleanWindow holds the groups of a window while its
+// points are aggregated.
+type floatReduceBooleanWindow struct {
+	groups map[string]*floatReduceBooleanPoint
+	size   int64 // estimated memory used by groups
+
+	// Points of the groups that did not fit within opt.MaxMemory.
+	spill *spillFile
+	enc   *FloatPointEncoder
+}
+
+// close removes the spill file of the window.
+func (w *floatReduceBooleanWindow) close() {
+	if w.spill != nil {
+		w.spill.Close()
+	}
+}
+
+// reduce executes fn once for every point in the next window.
+// The previous value for the dimension is passed to fn.
+func (itr *floatReduceBooleanIterator) reduce() ([]BooleanPoint, error) {
+	// Return the remaining points of a window that was spilled to disk.
+	if itr.runs != nil {
+		if a, err := itr.runs.read(reduceSpillBatchSize); err != nil || len(a) > 0 {
+			return a, err
+		}
+		itr.runs = nil
+	}
+
 	// Calculate next window.
 	var (
 		startTime, endTime int64
@@ -2809,11 +3744,12 @@ func (itr *floatReduceBooleanIterator) reduce() ([]BooleanPoint, error) {
 	}
 
 	// Create points by tags.
-	m := make(map[string]*floatReduceBooleanPoint)
+	w := &floatReduceBooleanWindow{groups: make(map[string]*floatReduceBooleanPoint)}
 	for {
 		// Read next point.
 		curr, err := itr.input.NextInWindow(startTime, endTime)
 		if err != nil {
+			w.close()
 			return nil, err
 		} else if curr == nil {
 			break
@@ -2833,26 +3769,57 @@ func (itr *floatReduceBooleanIterator) reduce() ([]BooleanPoint, error) {
 			break
 		}
 
-		// Retrieve the tags on this point for this level of the query.
-		// This may be different than the bucket dimensions.
-		tags := curr.Tags.Subset(itr.dims)
-		id := tags.ID()
+		if err := itr.aggregate(w, curr); err != nil {
+			w.close()
+			return nil, err
+		}
+	}
 
-		// Retrieve the aggregator for this name/tag combination or create one.
-		rp := m[id]
-		if rp == nil {
-			aggregator, emitter := itr.create()
-			rp = &floatReduceBooleanPoint{
-				Name:       curr.Name,
-				Tags:       tags,
-				Aggregator: aggregator,
-				Emitter:    emitter,
+	if w.spill != nil {
+		return itr.reduceSpilled(w, startTime)
+	}
+	return itr.emit(w.groups, startTime), nil
+}
+
+// aggregate adds p to the aggregator of its group in w. If p belongs to a new
+// group and the groups of w already use opt.MaxMemory, p is spilled to
+// disk instead.
+func (itr *floatReduceBooleanIterator) aggregate(w *floatReduceBooleanWindow, p *FloatPoint) error {
+	// Retrieve the tags on this point for this level of the query.
+	// This may be different than the bucket dimensions.
+	tags := p.Tags.Subset(itr.dims)
+	id := tags.ID()
+
+	// Retrieve the aggregator for this name/tag combination or create one.
+	rp := w.groups[id]
+	if rp == nil {
+		if max := itr.opt.MaxMemory; max > 0 && w.size >= max {
+			if w.spill == nil {
+				f, err := newSpillFile(itr.opt.SpillDir)
+				if err != nil {
+					return err
+				}
+				w.spill, w.enc = f, NewFloatPointEncoder(f)
 			}
-			m[id] = rp
+			return w.enc.EncodeFloatPoint(p)
 		}
-		rp.Aggregator.AggregateFloat(curr)
+
+		aggregator, emitter := itr.create()
+		rp = &floatReduceBooleanPoint{
+			Name:       p.Name,
+			Tags:       tags,
+			Aggregator: aggregator,
+			Emitter:    emitter,
+		}
+		w.groups[id] = rp
+		w.size += reduceGroupSize(p.Name, id)
 	}
+	rp.Aggregator.AggregateFloat(p)
+	return nil
+}
 
+// emit returns the points of every group of a window in reverse order.
+func (itr *floatReduceBooleanIterator) emit(m map[string]*floatReduceBooleanPoint, startTime int64) []BooleanPoint {
 	// Reverse sort points by name & tag if our output is supposed to be ordered.
 	keys := make([]string, 0, len(m))
 	for k := range m {
@@ -2889,7 +3856,115 @@ func (itr *floatReduceBooleanIterator) reduce() ([]BooleanPoint, error) {
 		sort.Stable(sort.Reverse(booleanPointsByTime(a)))
 	}
 
-	return a, nil
+	return a
+}
+
+// reduceSpilled reduces a window whose groups did not fit in memory. The
+// points of the groups in memory are written to a run on disk and the points
+// spilled by w are reduced in the same way, until every group is reduced. The
+// runs are then merged in the order the points would have been emitted.
+func (itr *floatReduceBooleanIterator) reduceSpilled(w *floatReduceBooleanWindow, startTime int64) ([]BooleanPoint, error) {
+	runs := &booleanReduceRuns{}
+	for {
+		if err := itr.writeRun(runs, w.groups, startTime); err != nil {
+			w.close()
+			runs.Close()
+			return nil, err
+		} else if w.spill == nil {
+			break
+		}
+
+		input := w.spill
+		next, err := itr.reduceSpillFile(input)
+		input.Close()
+		if err != nil {
+			runs.Close()
+			return nil, err
+		}
+		w = next
+	}
+
+	itr.runs = runs
+	return runs.read(reduceSpillBatchSize)
+}
+
+// reduceSpillFile aggregates the points spilled to f into a new window.
+func (itr *floatReduceBooleanIterator) reduceSpillFile(f *spillFile) (*floatReduceBooleanWindow, error) {
+	if err := f.rewind(); err != nil {
+		return nil, err
+	}
+	dec := NewFloatPointDecoder(context.Background(), f)
+
+	w := &floatReduceBooleanWindow{groups: make(map[string]*floatReduceBooleanPoint)}
+	for {
+		select {
+		case <-itr.opt.InterruptCh:
+			w.close()
+			return nil, ErrQueryInterrupted
+		default:
+		}
+
+		var p FloatPoint
+		if err := dec.DecodeFloatPoint(&p); err == io.EOF {
+			return w, nil
+		} else if err != nil {
+			w.close()
+			return nil, err
+		}
+
+		if err := itr.aggregate(w, &p); err != nil {
+			w.close()
+			return nil, err
+		}
+	}
+}
+
+// writeRun writes the points of the groups in m to a new run of runs. The
+// points are sorted by time and then by group, which is the order they are
+// returned in when the groups all fit in memory.
+func (itr *floatReduceBooleanIterator) writeRun(runs *booleanReduceRuns, m map[string]*floatReduceBooleanPoint, startTime int64) error {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	type record struct {
+		key string
+		p   BooleanPoint
+	}
+	records := make([]record, 0, len(keys))
+	for _, k := range keys {
+		rp := m[k]
+		for _, p := range rp.Emitter.Emit() {
+			p.Name = rp.Name
+			if !itr.keepTags {
+				p.Tags = rp.Tags
+			}
+			// Set the points time to the interval time if the reducer didn't provide one.
+			if p.Time == ZeroTime {
+				p.Time = startTime
+			}
+			records = append(records, record{key: k, p: p})
+		}
+	}
+	sort.SliceStable(records, func(i, j int) bool { return records[i].p.Time < records[j].p.Time })
+
+	f, err := newSpillFile(itr.opt.SpillDir)
+	if err != nil {
+		return err
+	}
+	enc := NewBooleanPointEncoder(f)
+	for i := range records {
+		if err := writeSpillKey(f, records[i].key); err != nil {
+			f.Close()
+			return err
+		} else if err := enc.EncodeBooleanPoint(&records[i].p); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	return runs.add(f)
 }
 
 // floatStreamBooleanIterator streams inputs into the iterator and emits points gradually.
@@ -3752,6 +4827,10 @@ func (itr *integerSortedMergeIterator) Next() (*IntegerPoint, error) { return it
 func (itr *integerSortedMergeIterator) pop() (*IntegerPoint, error) {
 	// Initialize the heap. See the MergeIterator to see why this has to be done lazily.
 	if !itr.init {
+		if err := itr.spill(); err != nil {
+			return nil, err
+		}
+
 		items := itr.heap.items
 		itr.heap.items = make([]*integerSortedMergeHeapItem, 0, len(items))
 		for _, item := range items {
@@ -3790,6 +4869,113 @@ func (itr *integerSortedMergeIterator) pop() (*IntegerPoint, error) {
 	return p, nil
 }
 
+// spill merges the inputs a batch at a time into runs on disk while there are
+// more inputs than can be merged within opt.MaxMemory. The runs then replace
+// the inputs.
+func (itr *integerSortedMergeIterator) spill() error {
+	n := sortedMergeMaxInputs(itr.heap.opt.MaxMemory)
+	if n == 0 || len(itr.inputs) <= n {
+		return nil
+	}
+
+	for len(itr.inputs) > n {
+		inputs := itr.inputs
+		runs := make([]IntegerIterator, 0, (len(inputs)+n-1)/n)
+		for len(inputs) > 0 {
+			batch := inputs
+			if len(batch) > n {
+				batch = batch[:n]
+			}
+			inputs = inputs[len(batch):]
+
+			run, err := spillIntegerSortedMerge(batch, itr.heap.opt)
+			if err != nil {
+				// Keep the runs and remaining inputs so they are closed.
+				itr.inputs = append(runs, inputs...)
+				itr.heap.items = nil
+				return err
+			}
+			runs = append(runs, run)
+		}
+		itr.inputs = runs
+	}
+
+	itr.heap.items = make([]*integerSortedMergeHeapItem, 0, len(itr.inputs))
+	for _, input := range itr.inputs {
+		itr.heap.items = append(itr.heap.items, &integerSortedMergeHeapItem{itr: input})
+	}
+	return nil
+}
+
+// spillIntegerSortedMerge writes the sorted merge of inputs to a spill file
+// and returns an iterator that reads it back. The inputs are closed.
+func spillIntegerSortedMerge(inputs []IntegerIterator, opt IteratorOptions) (IntegerIterator, error) {
+	itr := newIntegerSortedMergeIterator(inputs, opt).(*integerSortedMergeIterator)
+	defer itr.Close()
+
+	f, err := newSpillFile(opt.SpillDir)
+	if err != nil {
+		return nil, err
+	}
+
+	enc := NewIntegerPointEncoder(f)
+	for {
+		select {
+		case <-opt.InterruptCh:
+			f.Close()
+			return nil, ErrQueryInterrupted
+		default:
+		}
+
+		p, err := itr.pop()
+		if err != nil {
+			f.Close()
+			return nil, err
+		} else if p == nil {
+			break
+		}
+
+		if err := enc.EncodeIntegerPoint(p); err != nil {
+			f.Close()
+			return nil, err
+		}
+	}
+
+	if err := f.rewind(); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &integerSpillIterator{
+		f:     f,
+		dec:   NewIntegerPointDecoder(context.Background(), f),
+		stats: itr.Stats(),
+	}, nil
+}
+
+// integerSpillIterator reads the points written to a spill file.
+type integerSpillIterator struct {
+	f     *spillFile
+	dec   *IntegerPointDecoder
+	point IntegerPoint
+	stats IteratorStats // stats of the iterators that were spilled
+}
+
+// Stats returns the stats of the iterators that were spilled.
+func (itr *integerSpillIterator) Stats() IteratorStats { return itr.stats }
+
+// Close closes and removes the spill file.
+func (itr *integerSpillIterator) Close() error { return itr.f.Close() }
+
+// Next returns the next point from the spill file.
+func (itr *integerSpillIterator) Next() (*IntegerPoint, error) {
+	if err := itr.dec.DecodeIntegerPoint(&itr.point); err == io.EOF {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return &itr.point, nil
+}
+
 // integerSortedMergeHeap represents a heap of integerSortedMergeHeapItems.
 // Items are sorted with the following priority:
 //     - By their measurement name;
@@ -4460,6 +5646,100 @@ func (itr *integerChanIterator) Next() (*IntegerPoint, error) {
 	return p, nil
 }
 
+// integerReduceRun is a run of reduced points of a window spilled to disk.
+// The points are sorted in the order they are returned by a reduce iterator.
+type integerReduceRun struct {
+	f   *spillFile
+	dec *IntegerPointDecoder
+	key string // group of p
+	p   IntegerPoint
+}
+
+// next reads the next point of the run. It returns io.EOF at the end of the run.
+func (r *integerReduceRun) next() error {
+	key, err := readSpillKey(r.f)
+	if err != nil {
+		return err
+	}
+	r.key = key
+	return r.dec.DecodeIntegerPoint(&r.p)
+}
+
+// integerReduceRuns merges the runs of a window whose groups did not fit
+// in memory.
+type integerReduceRuns []*integerReduceRun
+
+func (a integerReduceRuns) Len() int      { return len(a) }
+func (a integerReduceRuns) Swap(i, j int) { a[i], a[j] = a[j], a[i] }
+func (a integerReduceRuns) Less(i, j int) bool {
+	if a[i].p.Time != a[j].p.Time {
+		return a[i].p.Time < a[j].p.Time
+	}
+	return a[i].key < a[j].key
+}
+
+func (a *integerReduceRuns) Push(x interface{}) {
+	*a = append(*a, x.(*integerReduceRun))
+}
+
+func (a *integerReduceRuns) Pop() interface{} {
+	old := *a
+	n := len(old)
+	r := old[n-1]
+	*a = old[:n-1]
+	return r
+}
+
+// add adds the run written to f. The runs take ownership of f.
+func (a *integerReduceRuns) add(f *spillFile) error {
+	if err := f.rewind(); err != nil {
+		f.Close()
+		return err
+	}
+
+	r := &integerReduceRun{f: f, dec: NewIntegerPointDecoder(context.Background(), f)}
+	if err := r.next(); err == io.EOF {
+		return f.Close()
+	} else if err != nil {
+		f.Close()
+		return err
+	}
+	heap.Push(a, r)
+	return nil
+}
+
+// read returns up to n of the next points of the runs. The points are in
+// reverse order so they can be popped off the end of the slice.
+func (a *integerReduceRuns) read(n int) ([]IntegerPoint, error) {
+	points := make([]IntegerPoint, 0, n)
+	for len(points) < n && len(*a) > 0 {
+		r := (*a)[0]
+		points = append(points, r.p)
+		if err := r.next(); err == io.EOF {
+			heap.Pop(a)
+			r.f.Close()
+		} else if err != nil {
+			return nil, err
+		} else {
+			heap.Fix(a, 0)
+		}
+	}
+
+	for i, j := 0, len(points)-1; i < j; i, j = i+1, j-1 {
+		points[i], points[j] = points[j], points[i]
+	}
+	return points, nil
+}
+
+// Close removes the files of the remaining runs.
+func (a *integerReduceRuns) Close() error {
+	for _, r := range *a {
+		r.f.Close()
+	}
+	*a = nil
+	return nil
+}
+
 // integerReduceFloatIterator executes a reducer for every interval and buffers the result.
 type integerReduceFloatIterator struct {
 	input    *bufIntegerIterator
@@ -4468,6 +5748,9 @@ type integerReduceFloatIterator struct {
 	opt      IteratorOptions
 	points   []FloatPoint
 	keepTags bool
+
+	// Runs of the current window if its groups did not fit in memory.
+	runs *floatReduceRuns
 }
 
 func newIntegerReduceFloatIterator(input IntegerIterator, opt IteratorOptions, createFn func() (IntegerPointAggregator, FloatPointEmitter)) *integerReduceFloatIterator {
@@ -4483,7 +5766,12 @@ func newIntegerReduceFloatIterator(input IntegerIterator, opt IteratorOptions, c
 func (itr *integerReduceFloatIterator) Stats() IteratorStats { return itr.input.Stats() }
 
 // Close closes the iterator and all child iterators.
-func (itr *integerReduceFloatIterator) Close() error { return itr.input.Close() }
+func (itr *integerReduceFloatIterator) Close() error {
+	if itr.runs != nil {
+		itr.runs.Close()
+	}
+	return itr.input.Close()
+}
 
 // Next returns the minimum value for the next available interval.
 func (itr *integerReduceFloatIterator) Next() (*FloatPoint, error) {
@@ -4510,9 +5798,35 @@ type integerReduceFloatPoint struct {
 	Emitter    FloatPointEmitter
 }
 
+// integerReduceFloatWindow holds the groups of a window while its
+// points are aggregated.
+type integerReduceFloatWindow struct {
+	groups map[string]*integerReduceFloatPoint
+	size   int64 // estimated memory used by groups
+
+	// Points of the groups that did not fit within opt.MaxMemory.
+	spill *spillFile
+	enc   *IntegerPointEncoder
+}
+
+// close removes the spill file of the window.
+func (w *integerReduceFloatWindow) close() {
+	if w.spill != nil {
+		w.spill.Close()
+	}
+}
+
 // reduce executes fn once for every point in the next window.
 // The previous value for the dimension is passed to fn.
 func (itr *integerReduceFloatIterator) reduce() ([]FloatPoint, error) {
+	// Return the remaining points of a window that was spilled to disk.
+	if itr.runs != nil {
+		if a, err := itr.runs.read(reduceSpillBatchSize); err != nil || len(a) > 0 {
+			return a, err
+		}
+		itr.runs = nil
+	}
+
 	// Calculate next window.
 	var (
 		startTime, endTime int64
@@ -4537,11 +5851,12 @@ func (itr *integerReduceFloatIterator) reduce() ([]FloatPoint, error) {
 	}
 
 	// Create points by tags.
-	m := make(map[string]*integerReduceFloatPoint)
+	w := &integerReduceFloatWindow{groups: make(map[string]*integerReduceFloatPoint)}
 	for {
 		// Read next point.
 		curr, err := itr.input.NextInWindow(startTime, endTime)
 		if err != nil {
+			w.close()
 			return nil, err
 		} else if curr == nil {
 			break
@@ -4561,26 +5876,57 @@ func (itr *integerReduceFloatIterator) reduce() ([]FloatPoint, error) {
 			break
 		}
 
-		// Retrieve the tags on this point for this level of the query.
-		// This may be different than the bucket dimensions.
-		tags := curr.Tags.Subset(itr.dims)
-		id := tags.ID()
+		if err := itr.aggregate(w, curr); err != nil {
+			w.close()
+			return nil, err
+		}
+	}
 
-		// Retrieve the aggregator for this name/tag combination or create one.
-		rp := m[id]
-		if rp == nil {
-			aggregator, emitter := itr.create()
-			rp = &integerReduceFloatPoint{
-				Name:       curr.Name,
-				Tags:       tags,
-				Aggregator: aggregator,
-				Emitter:    emitter,
+	if w.spill != nil {
+		return itr.reduceSpilled(w, startTime)
+	}
+	return itr.emit(w.groups, startTime), nil
+}
+
+// aggregate adds p to the aggregator of its group in w. If p belongs to a new
+// group and the groups of w already use opt.MaxMemory, p is spilled to
+// disk instead.
+func (itr *integerReduceFloatIterator) aggregate(w *integerReduceFloatWindow, p *IntegerPoint) error {
+	// Retrieve the tags on this point for this level of the query.
+	// This may be different than the bucket dimensions.
+	tags := p.Tags.Subset(itr.dims)
+	id := tags.ID()
+
+	// Retrieve the aggregator for this name/tag combination or create one.
+	rp := w.groups[id]
+	if rp == nil {
+		if max := itr.opt.MaxMemory; max > 0 && w.size >= max {
+			if w.spill == nil {
+				f, err := newSpillFile(itr.opt.SpillDir)
+				if err != nil {
+					return err
+				}
+				w.spill, w.enc = f, NewIntegerPointEncoder(f)
 			}
-			m[id] = rp
+			return w.enc.EncodeIntegerPoint(p)
 		}
-		rp.Aggregator.AggregateInteger(curr)
+
+		aggregator, emitter := itr.create()
+		rp = &integerReduceFloatPoint{
+			Name:       p.Name,
+			Tags:       tags,
+			Aggregator: aggregator,
+			Emitter:    emitter,
+		}
+		w.groups[id] = rp
+		w.size += reduceGroupSize(p.Name, id)
 	}
+	rp.Aggregator.AggregateInteger(p)
+	return nil
+}
 
+// emit returns the points of every group of a window in reverse order.
+func (itr *integerReduceFloatIterator) emit(m map[string]*integerReduceFloatPoint, startTime int64) []FloatPoint {
 	// Reverse sort points by name & tag if our output is supposed to be ordered.
 	keys := make([]string, 0, len(m))
 	for k := range m {
@@ -4617,35 +5963,143 @@ func (itr *integerReduceFloatIterator) reduce() ([]FloatPoint, error) {
 		sort.Stable(sort.Reverse(floatPointsByTime(a)))
 	}
 
-	return a, nil
+	return a
 }
 
-// integerStreamFloatIterator streams inputs into the iterator and emits points gradually.
-type integerStreamFloatIterator struct {
-	input  *bufIntegerIterator
-	create func() (IntegerPointAggregator, FloatPointEmitter)
-	dims   []string
-	opt    IteratorOptions
-	m      map[string]*integerReduceFloatPoint
-	points []FloatPoint
-}
+// reduceSpilled reduces a window whose groups did not fit in memory. The
+// points of the groups in memory are written to a run on disk and the points
+// spilled by w are reduced in the same way, until every group is reduced. The
+// runs are then merged in the order the points would have been emitted.
+func (itr *integerReduceFloatIterator) reduceSpilled(w *integerReduceFloatWindow, startTime int64) ([]FloatPoint, error) {
+	runs := &floatReduceRuns{}
+	for {
+		if err := itr.writeRun(runs, w.groups, startTime); err != nil {
+			w.close()
+			runs.Close()
+			return nil, err
+		} else if w.spill == nil {
+			break
+		}
 
-// newIntegerStreamFloatIterator returns a new instance of integerStreamFloatIterator.
-func newIntegerStreamFloatIterator(input IntegerIterator, createFn func() (IntegerPointAggregator, FloatPointEmitter), opt IteratorOptions) *integerStreamFloatIterator {
-	return &integerStreamFloatIterator{
-		input:  newBufIntegerIterator(input),
-		create: createFn,
-		dims:   opt.GetDimensions(),
-		opt:    opt,
-		m:      make(map[string]*integerReduceFloatPoint),
+		input := w.spill
+		next, err := itr.reduceSpillFile(input)
+		input.Close()
+		if err != nil {
+			runs.Close()
+			return nil, err
+		}
+		w = next
 	}
+
+	itr.runs = runs
+	return runs.read(reduceSpillBatchSize)
 }
 
-// Stats returns stats from the input iterator.
-func (itr *integerStreamFloatIterator) Stats() IteratorStats { return itr.input.Stats() }
+// reduceSpillFile aggregates the points spilled to f into a new window.
+func (itr *integerReduceFloatIterator) reduceSpillFile(f *spillFile) (*integerReduceFloatWindow, error) {
+	if err := f.rewind(); err != nil {
+		return nil, err
+	}
+	dec := NewIntegerPointDecoder(context.Background(), f)
 
-// Close closes the iterator and all child iterators.
-func (itr *integerStreamFloatIterator) Close() error { return itr.input.Close() }
+	w := &integerReduceFloatWindow{groups: make(map[string]*integerReduceFloatPoint)}
+	for {
+		select {
+		case <-itr.opt.InterruptCh:
+			w.close()
+			return nil, ErrQueryInterrupted
+		default:
+		}
+
+		var p IntegerPoint
+		if err := dec.DecodeIntegerPoint(&p); err == io.EOF {
+			return w, nil
+		} else if err != nil {
+			w.close()
+			return nil, err
+		}
+
+		if err := itr.aggregate(w, &p); err != nil {
+			w.close()
+			return nil, err
+		}
+	}
+}
+
+// writeRun writes the points of the groups in m to a new run of runs. The
+// points are sorted by time and then by group, which is the order they are
+// returned in when the groups all fit in memory.
+func (itr *integerReduceFloatIterator) writeRun(runs *floatReduceRuns, m map[string]*integerReduceFloatPoint, startTime int64) error {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	type record struct {
+		key string
+		p   FloatPoint
+	}
+	records := make([]record, 0, len(keys))
+	for _, k := range keys {
+		rp := m[k]
+		for _, p := range rp.Emitter.Emit() {
+			p.Name = rp.Name
+			if !itr.keepTags {
+				p.Tags = rp.Tags
+			}
+			// Set the points time to the interval time if the reducer didn't provide one.
+			if p.Time == ZeroTime {
+				p.Time = startTime
+			}
+			records = append(records, record{key: k, p: p})
+		}
+	}
+	sort.SliceStable(records, func(i, j int) bool { return records[i].p.Time < records[j].p.Time })
+
+	f, err := newSpillFile(itr.opt.SpillDir)
+	if err != nil {
+		return err
+	}
+	enc := NewFloatPointEncoder(f)
+	for i := range records {
+		if err := writeSpillKey(f, records[i].key); err != nil {
+			f.Close()
+			return err
+		} else if err := enc.EncodeFloatPoint(&records[i].p); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	return runs.add(f)
+}
+
+// integerStreamFloatIterator streams inputs into the iterator and emits points gradually.
+type integerStreamFloatIterator struct {
+	input  *bufIntegerIterator
+	create func() (IntegerPointAggregator, FloatPointEmitter)
+	dims   []string
+	opt    IteratorOptions
+	m      map[string]*integerReduceFloatPoint
+	points []FloatPoint
+}
+
+// newIntegerStreamFloatIterator returns a new instance of integerStreamFloatIterator.
+func newIntegerStreamFloatIterator(input IntegerIterator, createFn func() (IntegerPointAggregator, FloatPointEmitter), opt IteratorOptions) *integerStreamFloatIterator {
+	return &integerStreamFloatIterator{
+		input:  newBufIntegerIterator(input),
+		create: createFn,
+		dims:   opt.GetDimensions(),
+		opt:    opt,
+		m:      make(map[string]*integerReduceFloatPoint),
+	}
+}
+
+// Stats returns stats from the input iterator.
+func (itr *integerStreamFloatIterator) Stats() IteratorStats { return itr.input.Stats() }
+
+// Close closes the iterator and all child iterators.
+func (itr *integerStreamFloatIterator) Close() error { return itr.input.Close() }
 
 // Next returns the next value for the stream iterator.
 func (itr *integerStreamFloatIterator) Next() (*FloatPoint, error) {
@@ -4887,6 +6341,9 @@ type integerReduceIntegerIterator struct {
 	opt      IteratorOptions
 	points   []IntegerPoint
 	keepTags bool
+
+	// Runs of the current window if its groups did not fit in memory.
+	runs *integerReduceRuns
 }
 
 func newIntegerReduceIntegerIterator(input IntegerIterator, opt IteratorOptions, createFn func() (IntegerPointAggregator, IntegerPointEmitter)) *integerReduceIntegerIterator {
@@ -4902,7 +6359,12 @@ func newIntegerReduceIntegerIterator(input IntegerIterator, opt IteratorOptions,
 func (itr *integerReduceIntegerIterator) Stats() IteratorStats { return itr.input.Stats() }
 
 // Close closes the iterator and all child iterators.
-func (itr *integerReduceIntegerIterator) Close() error { return itr.input.Close() }
+func (itr *integerReduceIntegerIterator) Close() error {
+	if itr.runs != nil {
+		itr.runs.Close()
+	}
+	return itr.input.Close()
+}
 
 // Next returns the minimum value for the next available interval.
 func (itr *integerReduceIntegerIterator) Next() (*IntegerPoint, error) {
@@ -4929,9 +6391,35 @@ type integerReduceIntegerPoint struct {
 	Emitter    IntegerPointEmitter
 }
 
+// integerReduceIntegerWindow holds the groups of a window while its
+// points are aggregated.
+type integerReduceIntegerWindow struct {
+	groups map[string]*integerReduceIntegerPoint
+	size   int64 // estimated memory used by groups
+
+	// Points of the groups that did not fit within opt.MaxMemory.
+	spill *spillFile
+	enc   *IntegerPointEncoder
+}
+
+// close removes the spill file of the window.
+func (w *integerReduceIntegerWindow) close() {
+	if w.spill != nil {
+		w.spill.Close()
+	}
+}
+
 // reduce executes fn once for every point in the next window.
 // The previous value for the dimension is passed to fn.
 func (itr *integerReduceIntegerIterator) reduce() ([]IntegerPoint, error) {
+	// Return the remaining points of a window that was spilled to disk.
+	if itr.runs != nil {
+		if a, err := itr.runs.read(reduceSpillBatchSize); err != nil || len(a) > 0 {
+			return a, err
+		}
+		itr.runs = nil
+	}
+
 	// Calculate next window.
 	var (
 		startTime, endTime int64
@@ -4956,11 +6444,12 @@ func (itr *integerReduceIntegerIterator) reduce() ([]IntegerPoint, error) {
 	}
 
 	// Create points by tags.
-	m := make(map[string]*integerReduceIntegerPoint)
+	w := &integerReduceIntegerWindow{groups: make(map[string]*integerReduceIntegerPoint)}
 	for {
 		// Read next point.
 		curr, err := itr.input.NextInWindow(startTime, endTime)
 		if err != nil {
+			w.close()
 			return nil, err
 		} else if curr == nil {
 			break
@@ -4980,26 +6469,57 @@ func (itr *integerReduceIntegerIterator) reduce() ([]IntegerPoint, error) {
 			break
 		}
 
-		// Retrieve the tags on this point for this level of the query.
-		// This may be different than the bucket dimensions.
-		tags := curr.Tags.Subset(itr.dims)
-		id := tags.ID()
+		if err := itr.aggregate(w, curr); err != nil {
+			w.close()
+			return nil, err
+		}
+	}
 
-		// Retrieve the aggregator for this name/tag combination or create one.
-		rp := m[id]
-		if rp == nil {
-			aggregator, emitter := itr.create()
-			rp = &integerReduceIntegerPoint{
-				Name:       curr.Name,
-				Tags:       tags,
-				Aggregator: aggregator,
-				Emitter:    emitter,
+	if w.spill != nil {
+		return itr.reduceSpilled(w, startTime)
+	}
+	return itr.emit(w.groups, startTime), nil
+}
+
+// aggregate adds p to the aggregator of its group in w. If p belongs to a new
+// group and the groups of w already use opt.MaxMemory, p is spilled to
+// disk instead.
+func (itr *integerReduceIntegerIterator) aggregate(w *integerReduceIntegerWindow, p *IntegerPoint) error {
+	// Retrieve the tags on this point for this level of the query.
+	// This may be different than the bucket dimensions.
+	tags := p.Tags.Subset(itr.dims)
+	id := tags.ID()
+
+	// Retrieve the aggregator for this name/tag combination or create one.
+	rp := w.groups[id]
+	if rp == nil {
+		if max := itr.opt.MaxMemory; max > 0 && w.size >= max {
+			if w.spill == nil {
+				f, err := newSpillFile(itr.opt.SpillDir)
+				if err != nil {
+					return err
+				}
+				w.spill, w.enc = f, NewIntegerPointEncoder(f)
 			}
-			m[id] = rp
+			return w.enc.EncodeIntegerPoint(p)
 		}
-		rp.Aggregator.AggregateInteger(curr)
+
+		aggregator, emitter := itr.create()
+		rp = &integerReduceIntegerPoint{
+			Name:       p.Name,
+			Tags:       tags,
+			Aggregator: aggregator,
+			Emitter:    emitter,
+		}
+		w.groups[id] = rp
+		w.size += reduceGroupSize(p.Name, id)
 	}
+	rp.Aggregator.AggregateInteger(p)
+	return nil
+}
 
+// emit returns the points of every group of a window in reverse order.
+func (itr *integerReduceIntegerIterator) emit(m map[string]*integerReduceIntegerPoint, startTime int64) []IntegerPoint {
 	// Reverse sort points by name & tag if our output is supposed to be ordered.
 	keys := make([]string, 0, len(m))
 	for k := range m {
@@ -5036,7 +6556,115 @@ func (itr *integerReduceIntegerIterator) reduce() ([]IntegerPoint, error) {
 		sort.Stable(sort.Reverse(integerPointsByTime(a)))
 	}
 
-	return a, nil
+	return a
+}
+
+// reduceSpilled reduces a window whose groups did not fit in memory. The
+// points of the groups in memory are written to a run on disk and the points
+// spilled by w are reduced in the same way, until every group is reduced. The
+// runs are then merged in the order the points would have been emitted.
+func (itr *integerReduceIntegerIterator) reduceSpilled(w *integerReduceIntegerWindow, startTime int64) ([]IntegerPoint, error) {
+	runs := &integerReduceRuns{}
+	for {
+		if err := itr.writeRun(runs, w.groups, startTime); err != nil {
+			w.close()
+			runs.Close()
+			return nil, err
+		} else if w.spill == nil {
+			break
+		}
+
+		input := w.spill
+		next, err := itr.reduceSpillFile(input)
+		input.Close()
+		if err != nil {
+			runs.Close()
+			return nil, err
+		}
+		w = next
+	}
+
+	itr.runs = runs
+	return runs.read(reduceSpillBatchSize)
+}
+
+// reduceSpillFile aggregates the points spilled to f into a new window.
+func (itr *integerReduceIntegerIterator) reduceSpillFile(f *spillFile) (*integerReduceIntegerWindow, error) {
+	if err := f.rewind(); err != nil {
+		return nil, err
+	}
+	dec := NewIntegerPointDecoder(context.Background(), f)
+
+	w := &integerReduceIntegerWindow{groups: make(map[string]*integerReduceIntegerPoint)}
+	for {
+		select {
+		case <-itr.opt.InterruptCh:
+			w.close()
+			return nil, ErrQueryInterrupted
+		default:
+		}
+
+		var p IntegerPoint
+		if err := dec.DecodeIntegerPoint(&p); err == io.EOF {
+			return w, nil
+		} else if err != nil {
+			w.close()
+			return nil, err
+		}
+
+		if err := itr.aggregate(w, &p); err != nil {
+			w.close()
+			return nil, err
+		}
+	}
+}
+
+// writeRun writes the points of the groups in m to a new run of runs. The
+// points are sorted by time and then by group, which is the order they are
+// returned in when the groups all fit in memory.
+func (itr *integerReduceIntegerIterator) writeRun(runs *integerReduceRuns, m map[string]*integerReduceIntegerPoint, startTime int64) error {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	type record struct {
+		key string
+		p   IntegerPoint
+	}
+	records := make([]record, 0, len(keys))
+	for _, k := range keys {
+		rp := m[k]
+		for _, p := range rp.Emitter.Emit() {
+			p.Name = rp.Name
+			if !itr.keepTags {
+				p.Tags = rp.Tags
+			}
+			// Set the points time to the interval time if the reducer didn't provide one.
+			if p.Time == ZeroTime {
+				p.Time = startTime
+			}
+			records = append(records, record{key: k, p: p})
+		}
+	}
+	sort.SliceStable(records, func(i, j int) bool { return records[i].p.Time < records[j].p.Time })
+
+	f, err := newSpillFile(itr.opt.SpillDir)
+	if err != nil {
+		return err
+	}
+	enc := NewIntegerPointEncoder(f)
+	for i := range records {
+		if err := writeSpillKey(f, records[i].key); err != nil {
+			f.Close()
+			return err
+		} else if err := enc.EncodeIntegerPoint(&records[i].p); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	return runs.add(f)
 }
 
 // integerStreamIntegerIterator streams inputs into the iterator and emits points gradually.
@@ -5302,6 +6930,9 @@ type integerReduceUnsignedIterator struct {
 	opt      IteratorOptions
 	points   []UnsignedPoint
 	keepTags bool
+
+	// Runs of the current window if its groups did not fit in memory.
+	runs *unsignedReduceRuns
 }
 
 func newIntegerReduceUnsignedIterator(input IntegerIterator, opt IteratorOptions, createFn func() (IntegerPointAggregator, UnsignedPointEmitter)) *integerReduceUnsignedIterator {
@@ -5317,7 +6948,12 @@ func newIntegerReduceUnsignedIterator(input IntegerIterator, opt IteratorOptions
 func (itr *integerReduceUnsignedIterator) Stats() IteratorStats { return itr.input.Stats() }
 
 // Close closes the iterator and all child iterators.
-func (itr *integerReduceUnsignedIterator) Close() error { return itr.input.Close() }
+func (itr *integerReduceUnsignedIterator) Close() error {
+	if itr.runs != nil {
+		itr.runs.Close()
+	}
+	return itr.input.Close()
+}
 
 // Next returns the minimum value for the next available interval.
 func (itr *integerReduceUnsignedIterator) Next() (*UnsignedPoint, error) {
@@ -5344,9 +6980,35 @@ type integerReduceUnsignedPoint struct {
 	Emitter    UnsignedPointEmitter
 }
 
+// integerReduceUnsignedWindow holds the groups of a window while its
+// points are aggregated.
+type integerReduceUnsignedWindow struct {
+	groups map[string]*integerReduceUnsignedPoint
+	size   int64 // estimated memory used by groups
+
+	// Points of the groups that did not fit within opt.MaxMemory.
+	spill *spillFile
+	enc   *IntegerPointEncoder
+}
+
+// close removes the spill file of the window.
+func (w *integerReduceUnsignedWindow) close() {
+	if w.spill != nil {
+		w.spill.Close()
+	}
+}
+
 // reduce executes fn once for every point in the next window.
 // The previous value for the dimension is passed to fn.
 func (itr *integerReduceUnsignedIterator) reduce() ([]UnsignedPoint, error) {
+	// Return the remaining points of a window that was spilled to disk.
+	if itr.runs != nil {
+		if a, err := itr.runs.read(reduceSpillBatchSize); err != nil || len(a) > 0 {
+			return a, err
+		}
+		itr.runs = nil
+	}
+
 	// Calculate next window.
 	var (
 		startTime, endTime int64
@@ -5371,11 +7033,12 @@ func (itr *integerReduceUnsignedIterator) reduce() ([]UnsignedPoint, error) {
 	}
 
 	// Create points by tags.
-	m := make(map[string]*integerReduceUnsignedPoint)
+	w := &integerReduceUnsignedWindow{groups: make(map[string]*integerReduceUnsignedPoint)}
 	for {
 		// Read next point.
 		curr, err := itr.input.NextInWindow(startTime, endTime)
 		if err != nil {
+			w.close()
 			return nil, err
 		} else if curr == nil {
 			break
@@ -5395,26 +7058,57 @@ func (itr *integerReduceUnsignedIterator) reduce() ([]UnsignedPoint, error) {
 			break
 		}
 
-		// Retrieve the tags on this point for this level of the query.
-		// This may be different than the bucket dimensions.
-		tags := curr.Tags.Subset(itr.dims)
-		id := tags.ID()
+		if err := itr.aggregate(w, curr); err != nil {
+			w.close()
+			return nil, err
+		}
+	}
 
-		// Retrieve the aggregator for this name/tag combination or create one.
-		rp := m[id]
-		if rp == nil {
-			aggregator, emitter := itr.create()
-			rp = &integerReduceUnsignedPoint{
-				Name:       curr.Name,
-				Tags:       tags,
-				Aggregator: aggregator,
-				Emitter:    emitter,
+	if w.spill != nil {
+		return itr.reduceSpilled(w, startTime)
+	}
+	return itr.emit(w.groups, startTime), nil
+}
+
+// aggregate adds p to the aggregator of its group in w. If p belongs to a new
+// group and the groups of w already use opt.MaxMemory, p is spilled to
+// disk instead.
+func (itr *integerReduceUnsignedIterator) aggregate(w *integerReduceUnsignedWindow, p *IntegerPoint) error {
+	// Retrieve the tags on this point for this level of the query.
+	// This may be different than the bucket dimensions.
+	tags := p.Tags.Subset(itr.dims)
+	id := tags.ID()
+
+	// Retrieve the aggregator for this name/tag combination or create one.
+	rp := w.groups[id]
+	if rp == nil {
+		if max := itr.opt.MaxMemory; max > 0 && w.size >= max {
+			if w.spill == nil {
+				f, err := newSpillFile(itr.opt.SpillDir)
+				if err != nil {
+					return err
+				}
+				w.spill, w.enc = f, NewIntegerPointEncoder(f)
 			}
-			m[id] = rp
+			return w.enc.EncodeIntegerPoint(p)
 		}
-		rp.Aggregator.AggregateInteger(curr)
+
+		aggregator, emitter := itr.create()
+		rp = &integerReduceUnsignedPoint{
+			Name:       p.Name,
+			Tags:       tags,
+			Aggregator: aggregator,
+			Emitter:    emitter,
+		}
+		w.groups[id] = rp
+		w.size += reduceGroupSize(p.Name, id)
 	}
+	rp.Aggregator.AggregateInteger(p)
+	return nil
+}
 
+// emit returns the points of every group of a window in reverse order.
+func (itr *integerReduceUnsignedIterator) emit(m map[string]*integerReduceUnsignedPoint, startTime int64) []UnsignedPoint {
 	// Reverse sort points by name & tag if our output is supposed to be ordered.
 	keys := make([]string, 0, len(m))
 	for k := range m {
@@ -5451,7 +7145,115 @@ func (itr *integerReduceUnsignedIterator) reduce() ([]UnsignedPoint, error) {
 		sort.Stable(sort.Reverse(unsignedPointsByTime(a)))
 	}
 
-	return a, nil
+	return a
+}
+
+// reduceSpilled reduces a window whose groups did not fit in memory. The
+// points of the groups in memory are written to a run on disk and the points
+// spilled by w are reduced in the same way, until every group is reduced. The
+// runs are then merged in the order the points would have been emitted.
+func (itr *integerReduceUnsignedIterator) reduceSpilled(w *integerReduceUnsignedWindow, startTime int64) ([]UnsignedPoint, error) {
+	runs := &unsignedReduceRuns{}
+	for {
+		if err := itr.writeRun(runs, w.groups, startTime); err != nil {
+			w.close()
+			runs.Close()
+			return nil, err
+		} else if w.spill == nil {
+			break
+		}
+
+		input := w.spill
+		next, err := itr.reduceSpillFile(input)
+		input.Close()
+		if err != nil {
+			runs.Close()
+			return nil, err
+		}
+		w = next
+	}
+
+	itr.runs = runs
+	return runs.read(reduceSpillBatchSize)
+}
+
+// reduceSpillFile aggregates the points spilled to f into a new window.
+func (itr *integerReduceUnsignedIterator) reduceSpillFile(f *spillFile) (*integerReduceUnsignedWindow, error) {
+	if err := f.rewind(); err != nil {
+		return nil, err
+	}
+	dec := NewIntegerPointDecoder(context.Background(), f)
+
+	w := &integerReduceUnsignedWindow{groups: make(map[string]*integerReduceUnsignedPoint)}
+	for {
+		select {
+		case <-itr.opt.InterruptCh:
+			w.close()
+			return nil, ErrQueryInterrupted
+		default:
+		}
+
+		var p IntegerPoint
+		if err := dec.DecodeIntegerPoint(&p); err == io.EOF {
+			return w, nil
+		} else if err != nil {
+			w.close()
+			return nil, err
+		}
+
+		if err := itr.aggregate(w, &p); err != nil {
+			w.close()
+			return nil, err
+		}
+	}
+}
+
+// writeRun writes the points of the groups in m to a new run of runs. The
+// points are sorted by time and then by group, which is the order they are
+// returned in when the groups all fit in memory.
+func (itr *integerReduceUnsignedIterator) writeRun(runs *unsignedReduceRuns, m map[string]*integerReduceUnsignedPoint, startTime int64) error {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	type record struct {
+		key string
+		p   UnsignedPoint
+	}
+	records := make([]record, 0, len(keys))
+	for _, k := range keys {
+		rp := m[k]
+		for _, p := range rp.Emitter.Emit() {
+			p.Name = rp.Name
+			if !itr.keepTags {
+				p.Tags = rp.Tags
+			}
+			// Set the points time to the interval time if the reducer didn't provide one.
+			if p.Time == ZeroTime {
+				p.Time = startTime
+			}
+			records = append(records, record{key: k, p: p})
+		}
+	}
+	sort.SliceStable(records, func(i, j int) bool { return records[i].p.Time < records[j].p.Time })
+
+	f, err := newSpillFile(itr.opt.SpillDir)
+	if err != nil {
+		return err
+	}
+	enc := NewUnsignedPointEncoder(f)
+	for i := range records {
+		if err := writeSpillKey(f, records[i].key); err != nil {
+			f.Close()
+			return err
+		} else if err := enc.EncodeUnsignedPoint(&records[i].p); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	return runs.add(f)
 }
 
 // integerStreamUnsignedIterator streams inputs into the iterator and emits points gradually.
@@ -5721,6 +7523,9 @@ type integerReduceStringIterator struct {
 	opt      IteratorOptions
 	points   []StringPoint
 	keepTags bool
+
+	// Runs of the current window if its groups did not fit in memory.
+	runs *stringReduceRuns
 }
 
 func newIntegerReduceStringIterator(input IntegerIterator, opt IteratorOptions, createFn func() (IntegerPointAggregator, StringPointEmitter)) *integerReduceStringIterator {
@@ -5736,7 +7541,12 @@ func newIntegerReduceStringIterator(input IntegerIterator, opt IteratorOptions,
 func (itr *integerReduceStringIterator) Stats() IteratorStats { return itr.input.Stats() }
 
 // Close closes the iterator and all child iterators.
-func (itr *integerReduceStringIterator) Close() error { return itr.input.Close() }
+func (itr *integerReduceStringIterator) Close() error {
+	if itr.runs != nil {
+		itr.runs.Close()
+	}
+	return itr.input.Close()
+}
 
 // Next returns the minimum value for the next available interval.
 func (itr *integerReduceStringIterator) Next() (*StringPoint, error) {
@@ -5763,9 +7573,35 @@ type integerReduceStringPoint struct {
 	Emitter    StringPointEmitter
 }
 
+// integerReduceStringWindow holds the groups of a window while its
+// points are aggregated.
+type integerReduceStringWindow struct {
+	groups map[string]*integerReduceStringPoint
+	size   int64 // estimated memory used by groups
+
+	// Points of the groups that did not fit within opt.MaxMemory.
+	spill *spillFile
+	enc   *IntegerPointEncoder
+}
+
+// close removes the spill file of the window.
+func (w *integerReduceStringWindow) close() {
+	if w.spill != nil {
+		w.spill.Close()
+	}
+}
+
 // reduce executes fn once for every point in the next window.
 // The previous value for the dimension is passed to fn.
 func (itr *integerReduceStringIterator) reduce() ([]StringPoint, error) {
+	// Return the remaining points of a window that was spilled to disk.
+	if itr.runs != nil {
+		if a, err := itr.runs.read(reduceSpillBatchSize); err != nil || len(a) > 0 {
+			return a, err
+		}
+		itr.runs = nil
+	}
+
 	// Calculate next window.
 	var (
 		startTime, endTime int64
@@ -5790,11 +7626,12 @@ func (itr *integerReduceStringIterator) reduce() ([]StringPoint, error) {
 	}
 
 	// Create points by tags.
-	m := make(map[string]*integerReduceStringPoint)
+	w := &integerReduceStringWindow{groups: make(map[string]*integerReduceStringPoint)}
 	for {
 		// Read next point.
 		curr, err := itr.input.NextInWindow(startTime, endTime)
 		if err != nil {
+			w.close()
 			return nil, err
 		} else if curr == nil {
 			break
@@ -5814,26 +7651,57 @@ func (itr *integerReduceStringIterator) reduce() ([]StringPoint, error) {
 			break
 		}
 
-		// Retrieve the tags on this point for this level of the query.
-		// This may be different than the bucket dimensions.
-		tags := curr.Tags.Subset(itr.dims)
-		id := tags.ID()
+		if err := itr.aggregate(w, curr); err != nil {
+			w.close()
+			return nil, err
+		}
+	}
 
-		// Retrieve the aggregator for this name/tag combination or create one.
-		rp := m[id]
-		if rp == nil {
-			aggregator, emitter := itr.create()
-			rp = &integerReduceStringPoint{
-				Name:       curr.Name,
-				Tags:       tags,
-				Aggregator: aggregator,
-				Emitter:    emitter,
+	if w.spill != nil {
+		return itr.reduceSpilled(w, startTime)
+	}
+	return itr.emit(w.groups, startTime), nil
+}
+
+// aggregate adds p to the aggregator of its group in w. If p belongs to a new
+// group and the groups of w already use opt.MaxMemory, p is spilled to
+// disk instead.
+func (itr *integerReduceStringIterator) aggregate(w *integerReduceStringWindow, p *IntegerPoint) error {
+	// Retrieve the tags on this point for this level of the query.
+	// This may be different than the bucket dimensions.
+	tags := p.Tags.Subset(itr.dims)
+	id := tags.ID()
+
+	// Retrieve the aggregator for this name/tag combination or create one.
+	rp := w.groups[id]
+	if rp == nil {
+		if max := itr.opt.MaxMemory; max > 0 && w.size >= max {
+			if w.spill == nil {
+				f, err := newSpillFile(itr.opt.SpillDir)
+				if err != nil {
+					return err
+				}
+				w.spill, w.enc = f, NewIntegerPointEncoder(f)
 			}
-			m[id] = rp
+			return w.enc.EncodeIntegerPoint(p)
 		}
-		rp.Aggregator.AggregateInteger(curr)
+
+		aggregator, emitter := itr.create()
+		rp = &integerReduceStringPoint{
+			Name:       p.Name,
+			Tags:       tags,
+			Aggregator: aggregator,
+			Emitter:    emitter,
+		}
+		w.groups[id] = rp
+		w.size += reduceGroupSize(p.Name, id)
 	}
+	rp.Aggregator.AggregateInteger(p)
+	return nil
+}
 
+// emit returns the points of every group of a window in reverse order.
+func (itr *integerReduceStringIterator) emit(m map[string]*integerReduceStringPoint, startTime int64) []StringPoint {
 	// Reverse sort points by name & tag if our output is supposed to be ordered.
 	keys := make([]string, 0, len(m))
 	for k := range m {
@@ -5870,7 +7738,115 @@ func (itr *integerReduceStringIterator) reduce() ([]StringPoint, error) {
 		sort.Stable(sort.Reverse(stringPointsByTime(a)))
 	}
 
-	return a, nil
+	return a
+}
+
+// reduceSpilled reduces a window whose groups did not fit in memory. The
+// points of the groups in memory are written to a run on disk and the points
+// spilled by w are reduced in the same way, until every group is reduced. The
+// runs are then merged in the order the points would have been emitted.
+func (itr *integerReduceStringIterator) reduceSpilled(w *integerReduceStringWindow, startTime int64) ([]StringPoint, error) {
+	runs := &stringReduceRuns{}
+	for {
+		if err := itr.writeRun(runs, w.groups, startTime); err != nil {
+			w.close()
+			runs.Close()
+			return nil, err
+		} else if w.spill == nil {
+			break
+		}
+
+		input := w.spill
+		next, err := itr.reduceSpillFile(input)
+		input.Close()
+		if err != nil {
+			runs.Close()
+			return nil, err
+		}
+		w = next
+	}
+
+	itr.runs = runs
+	return runs.read(reduceSpillBatchSize)
+}
+
+// reduceSpillFile aggregates the points spilled to f into a new window.
+func (itr *integerReduceStringIterator) reduceSpillFile(f *spillFile) (*integerReduceStringWindow, error) {
+	if err := f.rewind(); err != nil {
+		return nil, err
+	}
+	dec := NewIntegerPointDecoder(context.Background(), f)
+
+	w := &integerReduceStringWindow{groups: make(map[string]*integerReduceStringPoint)}
+	for {
+		select {
+		case <-itr.opt.InterruptCh:
+			w.close()
+			return nil, ErrQueryInterrupted
+		default:
+		}
+
+		var p IntegerPoint
+		if err := dec.DecodeIntegerPoint(&p); err == io.EOF {
+			return w, nil
+		} else if err != nil {
+			w.close()
+			return nil, err
+		}
+
+		if err := itr.aggregate(w, &p); err != nil {
+			w.close()
+			return nil, err
+		}
+	}
+}
+
+// writeRun writes the points of the groups in m to a new run of runs. The
+// points are sorted by time and then by group, which is the order they are
+// returned in when the groups all fit in memory.
+func (itr *integerReduceStringIterator) writeRun(runs *stringReduceRuns, m map[string]*integerReduceStringPoint, startTime int64) error {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	type record struct {
+		key string
+		p   StringPoint
+	}
+	records := make([]record, 0, len(keys))
+	for _, k := range keys {
+		rp := m[k]
+		for _, p := range rp.Emitter.Emit() {
+			p.Name = rp.Name
+			if !itr.keepTags {
+				p.Tags = rp.Tags
+			}
+			// Set the points time to the interval time if the reducer didn't provide one.
+			if p.Time == ZeroTime {
+				p.Time = startTime
+			}
+			records = append(records, record{key: k, p: p})
+		}
+	}
+	sort.SliceStable(records, func(i, j int) bool { return records[i].p.Time < records[j].p.Time })
+
+	f, err := newSpillFile(itr.opt.SpillDir)
+	if err != nil {
+		return err
+	}
+	enc := NewStringPointEncoder(f)
+	for i := range records {
+		if err := writeSpillKey(f, records[i].key); err != nil {
+			f.Close()
+			return err
+		} else if err := enc.EncodeStringPoint(&records[i].p); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	return runs.add(f)
 }
 
 // integerStreamStringIterator streams inputs into the iterator and emits points gradually.
@@ -6140,6 +8116,9 @@ type integerReduceBooleanIterator struct {
 	opt      IteratorOptions
 	points   []BooleanPoint
 	keepTags bool
+
+	// Runs of the current window if its groups did not fit in memory.
+	runs *booleanReduceRuns
 }
 
 func newIntegerReduceBooleanIterator(input IntegerIterator, opt IteratorOptions, createFn func() (IntegerPointAggregator, BooleanPointEmitter)) *integerReduceBooleanIterator {
@@ -6155,7 +8134,12 @@ func newIntegerReduceBooleanIterator(input IntegerIterator, opt IteratorOptions,
 func (itr *integerReduceBooleanIterator) Stats() IteratorStats { return itr.input.Stats() }
 
 // Close closes the iterator and all child iterators.
-func (itr *integerReduceBooleanIterator) Close() error { return itr.input.Close() }
+func (itr *integerReduceBooleanIterator) Close() error {
+	if itr.runs != nil {
+		itr.runs.Close()
+	}
+	return itr.input.Close()
+}
 
 // Next returns the minimum value for the next available interval.
 func (itr *integerReduceBooleanIterator) Next() (*BooleanPoint, error) {
@@ -6182,9 +8166,35 @@ type integerReduceBooleanPoint struct {
 	Emitter    BooleanPointEmitter
 }
 
+// integerReduceBooleanWindow holds the groups of a window while its
+// points are aggregated.
+type integerReduceBooleanWindow struct {
+	groups map[string]*integerReduceBooleanPoint
+	size   int64 // estimated memory used by groups
+
+	// Points of the groups that did not fit within opt.MaxMemory.
+	spill *spillFile
+	enc   *IntegerPointEncoder
+}
+
+// close removes the spill file of the window.
+func (w *integerReduceBooleanWindow) close() {
+	if w.spill != nil {
+		w.spill.Close()
+	}
+}
+
 // reduce executes fn once for every point in the next window.
 // The previous value for the dimension is passed to fn.
 func (itr *integerReduceBooleanIterator) reduce() ([]BooleanPoint, error) {
+	// Return the remaining points of a window that was spilled to disk.
+	if itr.runs != nil {
+		if a, err := itr.runs.read(reduceSpillBatchSize); err != nil || len(a) > 0 {
+			return a, err
+		}
+		itr.runs = nil
+	}
+
 	// Calculate next window.
 	var (
 		startTime, endTime int64
@@ -6209,11 +8219,12 @@ func (itr *integerReduceBooleanIterator) reduce() ([]BooleanPoint, error) {
 	}
 
 	// Create points by tags.
-	m := make(map[string]*integerReduceBooleanPoint)
+	w := &integerReduceBooleanWindow{groups: make(map[string]*integerReduceBooleanPoint)}
 	for {
 		// Read next point.
 		curr, err := itr.input.NextInWindow(startTime, endTime)
 		if err != nil {
+			w.close()
 			return nil, err
 		} else if curr == nil {
 			break
@@ -6233,26 +8244,57 @@ func (itr *integerReduceBooleanIterator) reduce() ([]BooleanPoint, error) {
 			break
 		}
 
-		// Retrieve the tags on this point for this level of the query.
-		// This may be different than the bucket dimensions.
-		tags := curr.Tags.Subset(itr.dims)
-		id := tags.ID()
+		if err := itr.aggregate(w, curr); err != nil {
+			w.close()
+			return nil, err
+		}
+	}
 
-		// Retrieve the aggregator for this name/tag combination or create one.
-		rp := m[id]
-		if rp == nil {
-			aggregator, emitter := itr.create()
-			rp = &integerReduceBooleanPoint{
-				Name:       curr.Name,
-				Tags:       tags,
-				Aggregator: aggregator,
-				Emitter:    emitter,
+	if w.spill != nil {
+		return itr.reduceSpilled(w, startTime)
+	}
+	return itr.emit(w.groups, startTime), nil
+}
+
+// aggregate adds p to the aggregator of its group in w. If p belongs to a new
+// group and the groups of w already use opt.MaxMemory, p is spilled to
+// disk instead.
+func (itr *integerReduceBooleanIterator) aggregate(w *integerReduceBooleanWindow, p *IntegerPoint) error {
+	// Retrieve the tags on this point for this level of the query.
+	// This may be different than the bucket dimensions.
+	tags := p.Tags.Subset(itr.dims)
+	id := tags.ID()
+
+	// Retrieve the aggregator for this name/tag combination or create one.
+	rp := w.groups[id]
+	if rp == nil {
+		if max := itr.opt.MaxMemory; max > 0 && w.size >= max {
+			if w.spill == nil {
+				f, err := newSpillFile(itr.opt.SpillDir)
+				if err != nil {
+					return err
+				}
+				w.spill, w.enc = f, NewIntegerPointEncoder(f)
 			}
-			m[id] = rp
+			return w.enc.EncodeIntegerPoint(p)
 		}
-		rp.Aggregator.AggregateInteger(curr)
+
+		aggregator, emitter := itr.create()
+		rp = &integerReduceBooleanPoint{
+			Name:       p.Name,
+			Tags:       tags,
+			Aggregator: aggregator,
+			Emitter:    emitter,
+		}
+		w.groups[id] = rp
+		w.size += reduceGroupSize(p.Name, id)
 	}
+	rp.Aggregator.AggregateInteger(p)
+	return nil
+}
 
+// emit returns the points of every group of a window in reverse order.
+func (itr *integerReduceBooleanIterator) emit(m map[string]*integerReduceBooleanPoint, startTime int64) []BooleanPoint {
 	// Reverse sort points by name & tag if our output is supposed to be ordered.
 	keys := make([]string, 0, len(m))
 	for k := range m {
@@ -6289,7 +8331,115 @@ func (itr *integerReduceBooleanIterator) reduce() ([]BooleanPoint, error) {
 		sort.Stable(sort.Reverse(booleanPointsByTime(a)))
 	}
 
-	return a, nil
+	return a
+}
+
+// reduceSpilled reduces a window whose groups did not fit in memory. The
+// points of the groups in memory are written to a run on disk and the points
+// spilled by w are reduced in the same way, until every group is reduced. The
+// runs are then merged in the order the points would have been emitted.
+func (itr *integerReduceBooleanIterator) reduceSpilled(w *integerReduceBooleanWindow, startTime int64) ([]BooleanPoint, error) {
+	runs := &booleanReduceRuns{}
+	for {
+		if err := itr.writeRun(runs, w.groups, startTime); err != nil {
+			w.close()
+			runs.Close()
+			return nil, err
+		} else if w.spill == nil {
+			break
+		}
+
+		input := w.spill
+		next, err := itr.reduceSpillFile(input)
+		input.Close()
+		if err != nil {
+			runs.Close()
+			return nil, err
+		}
+		w = next
+	}
+
+	itr.runs = runs
+	return runs.read(reduceSpillBatchSize)
+}
+
+// reduceSpillFile aggregates the points spilled to f into a new window.
+func (itr *integerReduceBooleanIterator) reduceSpillFile(f *spillFile) (*integerReduceBooleanWindow, error) {
+	if err := f.rewind(); err != nil {
+		return nil, err
+	}
+	dec := NewIntegerPointDecoder(context.Background(), f)
+
+	w := &integerReduceBooleanWindow{groups: make(map[string]*integerReduceBooleanPoint)}
+	for {
+		select {
+		case <-itr.opt.InterruptCh:
+			w.close()
+			return nil, ErrQueryInterrupted
+		default:
+		}
+
+		var p IntegerPoint
+		if err := dec.DecodeIntegerPoint(&p); err == io.EOF {
+			return w, nil
+		} else if err != nil {
+			w.close()
+			return nil, err
+		}
+
+		if err := itr.aggregate(w, &p); err != nil {
+			w.close()
+			return nil, err
+		}
+	}
+}
+
+// writeRun writes the points of the groups in m to a new run of runs. The
+// points are sorted by time and then by group, which is the order they are
+// returned in when the groups all fit in memory.
+func (itr *integerReduceBooleanIterator) writeRun(runs *booleanReduceRuns, m map[string]*integerReduceBooleanPoint, startTime int64) error {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	type record struct {
+		key string
+		p   BooleanPoint
+	}
+	records := make([]record, 0, len(keys))
+	for _, k := range keys {
+		rp := m[k]
+		for _, p := range rp.Emitter.Emit() {
+			p.Name = rp.Name
+			if !itr.keepTags {
+				p.Tags = rp.Tags
+			}
+			// Set the points time to the interval time if the reducer didn't provide one.
+			if p.Time == ZeroTime {
+				p.Time = startTime
+			}
+			records = append(records, record{key: k, p: p})
+		}
+	}
+	sort.SliceStable(records, func(i, j int) bool { return records[i].p.Time < records[j].p.Time })
+
+	f, err := newSpillFile(itr.opt.SpillDir)
+	if err != nil {
+		return err
+	}
+	enc := NewBooleanPointEncoder(f)
+	for i := range records {
+		if err := writeSpillKey(f, records[i].key); err != nil {
+			f.Close()
+			return err
+		} else if err := enc.EncodeBooleanPoint(&records[i].p); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	return runs.add(f)
 }
 
 // integerStreamBooleanIterator streams inputs into the iterator and emits points gradually.
@@ -7152,6 +9302,10 @@ func (itr *unsignedSortedMergeIterator) Next() (*UnsignedPoint, error) { return
 func (itr *unsignedSortedMergeIterator) pop() (*UnsignedPoint, error) {
 	// Initialize the heap. See the MergeIterator to see why this has to be done lazily.
 	if !itr.init {
+		if err := itr.spill(); err != nil {
+			return nil, err
+		}
+
 		items := itr.heap.items
 		itr.heap.items = make([]*unsignedSortedMergeHeapItem, 0, len(items))
 		for _, item := range items {
@@ -7190,6 +9344,113 @@ func (itr *unsignedSortedMergeIterator) pop() (*UnsignedPoint, error) {
 	return p, nil
 }
 
+// spill merges the inputs a batch at a time into runs on disk while there are
+// more inputs than can be merged within opt.MaxMemory. The runs then replace
+// the inputs.
+func (itr *unsignedSortedMergeIterator) spill() error {
+	n := sortedMergeMaxInputs(itr.heap.opt.MaxMemory)
+	if n == 0 || len(itr.inputs) <= n {
+		return nil
+	}
+
+	for len(itr.inputs) > n {
+		inputs := itr.inputs
+		runs := make([]UnsignedIterator, 0, (len(inputs)+n-1)/n)
+		for len(inputs) > 0 {
+			batch := inputs
+			if len(batch) > n {
+				batch = batch[:n]
+			}
+			inputs = inputs[len(batch):]
+
+			run, err := spillUnsignedSortedMerge(batch, itr.heap.opt)
+			if err != nil {
+				// Keep the runs and remaining inputs so they are closed.
+				itr.inputs = append(runs, inputs...)
+				itr.heap.items = nil
+				return err
+			}
+			runs = append(runs, run)
+		}
+		itr.inputs = runs
+	}
+
+	itr.heap.items = make([]*unsignedSortedMergeHeapItem, 0, len(itr.inputs))
+	for _, input := range itr.inputs {
+		itr.heap.items = append(itr.heap.items, &unsignedSortedMergeHeapItem{itr: input})
+	}
+	return nil
+}
+
+// spillUnsignedSortedMerge writes the sorted merge of inputs to a spill file
+// and returns an iterator that reads it back. The inputs are closed.
+func spillUnsignedSortedMerge(inputs []UnsignedIterator, opt IteratorOptions) (UnsignedIterator, error) {
+	itr := newUnsignedSortedMergeIterator(inputs, opt).(*unsignedSortedMergeIterator)
+	defer itr.Close()
+
+	f, err := newSpillFile(opt.SpillDir)
+	if err != nil {
+		return nil, err
+	}
+
+	enc := NewUnsignedPointEncoder(f)
+	for {
+		select {
+		case <-opt.InterruptCh:
+			f.Close()
+			return nil, ErrQueryInterrupted
+		default:
+		}
+
+		p, err := itr.pop()
+		if err != nil {
+			f.Close()
+			return nil, err
+		} else if p == nil {
+			break
+		}
+
+		if err := enc.EncodeUnsignedPoint(p); err != nil {
+			f.Close()
+			return nil, err
+		}
+	}
+
+	if err := f.rewind(); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &unsignedSpillIterator{
+		f:     f,
+		dec:   NewUnsignedPointDecoder(context.Background(), f),
+		stats: itr.Stats(),
+	}, nil
+}
+
+// unsignedSpillIterator reads the points written to a spill file.
+type unsignedSpillIterator struct {
+	f     *spillFile
+	dec   *UnsignedPointDecoder
+	point UnsignedPoint
+	stats IteratorStats // stats of the iterators that were spilled
+}
+
+// Stats returns the stats of the iterators that were spilled.
+func (itr *unsignedSpillIterator) Stats() IteratorStats { return itr.stats }
+
+// Close closes and removes the spill file.
+func (itr *unsignedSpillIterator) Close() error { return itr.f.Close() }
+
+// Next returns the next point from the spill file.
+func (itr *unsignedSpillIterator) Next() (*UnsignedPoint, error) {
+	if err := itr.dec.DecodeUnsignedPoint(&itr.point); err == io.EOF {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return &itr.point, nil
+}
+
 // unsignedSortedMergeHeap represents a heap of unsignedSortedMergeHeapItems.
 // Items are sorted with the following priority:
 //     - By their measurement name;
@@ -7860,45 +10121,147 @@ func (itr *unsignedChanIterator) Next() (*UnsignedPoint, error) {
 	return p, nil
 }
 
-// unsignedReduceFloatIterator executes a reducer for every interval and buffers the result.
-type unsignedReduceFloatIterator struct {
-	input    *bufUnsignedIterator
-	create   func() (UnsignedPointAggregator, FloatPointEmitter)
-	dims     []string
-	opt      IteratorOptions
-	points   []FloatPoint
-	keepTags bool
+// unsignedReduceRun is a run of reduced points of a window spilled to disk.
+// The points are sorted in the order they are returned by a reduce iterator.
+type unsignedReduceRun struct {
+	f   *spillFile
+	dec *UnsignedPointDecoder
+	key string // group of p
+	p   UnsignedPoint
 }
 
-func newUnsignedReduceFloatIterator(input UnsignedIterator, opt IteratorOptions, createFn func() (UnsignedPointAggregator, FloatPointEmitter)) *unsignedReduceFloatIterator {
-	return &unsignedReduceFloatIterator{
-		input:  newBufUnsignedIterator(input),
-		create: createFn,
-		dims:   opt.GetDimensions(),
-		opt:    opt,
+// next reads the next point of the run. It returns io.EOF at the end of the run.
+func (r *unsignedReduceRun) next() error {
+	key, err := readSpillKey(r.f)
+	if err != nil {
+		return err
 	}
+	r.key = key
+	return r.dec.DecodeUnsignedPoint(&r.p)
 }
 
-// Stats returns stats from the input iterator.
-func (itr *unsignedReduceFloatIterator) Stats() IteratorStats { return itr.input.Stats() }
-
-// Close closes the iterator and all child iterators.
-func (itr *unsignedReduceFloatIterator) Close() error { return itr.input.Close() }
+// unsignedReduceRuns merges the runs of a window whose groups did not fit
+// in memory.
+type unsignedReduceRuns []*unsignedReduceRun
 
-// Next returns the minimum value for the next available interval.
-func (itr *unsignedReduceFloatIterator) Next() (*FloatPoint, error) {
-	// Calculate next window if we have no more points.
-	if len(itr.points) == 0 {
-		var err error
-		itr.points, err = itr.reduce()
-		if len(itr.points) == 0 {
-			return nil, err
-		}
+func (a unsignedReduceRuns) Len() int      { return len(a) }
+func (a unsignedReduceRuns) Swap(i, j int) { a[i], a[j] = a[j], a[i] }
+func (a unsignedReduceRuns) Less(i, j int) bool {
+	if a[i].p.Time != a[j].p.Time {
+		return a[i].p.Time < a[j].p.Time
 	}
+	return a[i].key < a[j].key
+}
 
-	// Pop next point off the stack.
-	p := &itr.points[len(itr.points)-1]
-	itr.points = itr.points[:len(itr.points)-1]
+func (a *unsignedReduceRuns) Push(x interface{}) {
+	*a = append(*a, x.(*unsignedReduceRun))
+}
+
+func (a *unsignedReduceRuns) Pop() interface{} {
+	old := *a
+	n := len(old)
+	r := old[n-1]
+	*a = old[:n-1]
+	return r
+}
+
+// add adds the run written to f. The runs take ownership of f.
+func (a *unsignedReduceRuns) add(f *spillFile) error {
+	if err := f.rewind(); err != nil {
+		f.Close()
+		return err
+	}
+
+	r := &unsignedReduceRun{f: f, dec: NewUnsignedPointDecoder(context.Background(), f)}
+	if err := r.next(); err == io.EOF {
+		return f.Close()
+	} else if err != nil {
+		f.Close()
+		return err
+	}
+	heap.Push(a, r)
+	return nil
+}
+
+// read returns up to n of the next points of the runs. The points are in
+// reverse order so they can be popped off the end of the slice.
+func (a *unsignedReduceRuns) read(n int) ([]UnsignedPoint, error) {
+	points := make([]UnsignedPoint, 0, n)
+	for len(points) < n && len(*a) > 0 {
+		r := (*a)[0]
+		points = append(points, r.p)
+		if err := r.next(); err == io.EOF {
+			heap.Pop(a)
+			r.f.Close()
+		} else if err != nil {
+			return nil, err
+		} else {
+			heap.Fix(a, 0)
+		}
+	}
+
+	for i, j := 0, len(points)-1; i < j; i, j = i+1, j-1 {
+		points[i], points[j] = points[j], points[i]
+	}
+	return points, nil
+}
+
+// Close removes the files of the remaining runs.
+func (a *unsignedReduceRuns) Close() error {
+	for _, r := range *a {
+		r.f.Close()
+	}
+	*a = nil
+	return nil
+}
+
+// unsignedReduceFloatIterator executes a reducer for every interval and buffers the result.
+type unsignedReduceFloatIterator struct {
+	input    *bufUnsignedIterator
+	create   func() (UnsignedPointAggregator, FloatPointEmitter)
+	dims     []string
+	opt      IteratorOptions
+	points   []FloatPoint
+	keepTags bool
+
+	// Runs of the current window if its groups did not fit in memory.
+	runs *floatReduceRuns
+}
+
+func newUnsignedReduceFloatIterator(input UnsignedIterator, opt IteratorOptions, createFn func() (UnsignedPointAggregator, FloatPointEmitter)) *unsignedReduceFloatIterator {
+	return &unsignedReduceFloatIterator{
+		input:  newBufUnsignedIterator(input),
+		create: createFn,
+		dims:   opt.GetDimensions(),
+		opt:    opt,
+	}
+}
+
+// Stats returns stats from the input iterator.
+func (itr *unsignedReduceFloatIterator) Stats() IteratorStats { return itr.input.Stats() }
+
+// Close closes the iterator and all child iterators.
+func (itr *unsignedReduceFloatIterator) Close() error {
+	if itr.runs != nil {
+		itr.runs.Close()
+	}
+	return itr.input.Close()
+}
+
+// Next returns the minimum value for the next available interval.
+func (itr *unsignedReduceFloatIterator) Next() (*FloatPoint, error) {
+	// Calculate next window if we have no more points.
+	if len(itr.points) == 0 {
+		var err error
+		itr.points, err = itr.reduce()
+		if len(itr.points) == 0 {
+			return nil, err
+		}
+	}
+
+	// Pop next point off the stack.
+	p := &itr.points[len(itr.points)-1]
+	itr.points = itr.points[:len(itr.points)-1]
 	return p, nil
 }
 
@@ -7910,9 +10273,35 @@ type unsignedReduceFloatPoint struct {
 	Emitter    FloatPointEmitter
 }
 
+// unsignedReduceFloatWindow holds the groups of a window while its
+// points are aggregated.
+type unsignedReduceFloatWindow struct {
+	groups map[string]*unsignedReduceFloatPoint
+	size   int64 // estimated memory used by groups
+
+	// Points of the groups that did not fit within opt.MaxMemory.
+	spill *spillFile
+	enc   *UnsignedPointEncoder
+}
+
+// close removes the spill file of the window.
+func (w *unsignedReduceFloatWindow) close() {
+	if w.spill != nil {
+		w.spill.Close()
+	}
+}
+
 // reduce executes fn once for every point in the next window.
 // The previous value for the dimension is passed to fn.
 func (itr *unsignedReduceFloatIterator) reduce() ([]FloatPoint, error) {
+	// Return the remaining points of a window that was spilled to disk.
+	if itr.runs != nil {
+		if a, err := itr.runs.read(reduceSpillBatchSize); err != nil || len(a) > 0 {
+			return a, err
+		}
+		itr.runs = nil
+	}
+
 	// Calculate next window.
 	var (
 		startTime, endTime int64
@@ -7937,11 +10326,12 @@ func (itr *unsignedReduceFloatIterator) reduce() ([]FloatPoint, error) {
 	}
 
 	// Create points by tags.
-	m := make(map[string]*unsignedReduceFloatPoint)
+	w := &unsignedReduceFloatWindow{groups: make(map[string]*unsignedReduceFloatPoint)}
 	for {
 		// Read next point.
 		curr, err := itr.input.NextInWindow(startTime, endTime)
 		if err != nil {
+			w.close()
 			return nil, err
 		} else if curr == nil {
 			break
@@ -7961,26 +10351,57 @@ func (itr *unsignedReduceFloatIterator) reduce() ([]FloatPoint, error) {
 			break
 		}
 
-		// Retrieve the tags on this point for this level of the query.
-		// This may be different than the bucket dimensions.
-		tags := curr.Tags.Subset(itr.dims)
-		id := tags.ID()
+		if err := itr.aggregate(w, curr); err != nil {
+			w.close()
+			return nil, err
+		}
+	}
 
-		// Retrieve the aggregator for this name/tag combination or create one.
-		rp := m[id]
-		if rp == nil {
-			aggregator, emitter := itr.create()
-			rp = &unsignedReduceFloatPoint{
-				Name:       curr.Name,
-				Tags:       tags,
-				Aggregator: aggregator,
-				Emitter:    emitter,
+	if w.spill != nil {
+		return itr.reduceSpilled(w, startTime)
+	}
+	return itr.emit(w.groups, startTime), nil
+}
+
+// aggregate adds p to the aggregator of its group in w. If p belongs to a new
+// group and the groups of w already use opt.MaxMemory, p is spilled to
+// disk instead.
+func (itr *unsignedReduceFloatIterator) aggregate(w *unsignedReduceFloatWindow, p *UnsignedPoint) error {
+	// Retrieve the tags on this point for this level of the query.
+	// This may be different than the bucket dimensions.
+	tags := p.Tags.Subset(itr.dims)
+	id := tags.ID()
+
+	// Retrieve the aggregator for this name/tag combination or create one.
+	rp := w.groups[id]
+	if rp == nil {
+		if max := itr.opt.MaxMemory; max > 0 && w.size >= max {
+			if w.spill == nil {
+				f, err := newSpillFile(itr.opt.SpillDir)
+				if err != nil {
+					return err
+				}
+				w.spill, w.enc = f, NewUnsignedPointEncoder(f)
 			}
-			m[id] = rp
+			return w.enc.EncodeUnsignedPoint(p)
 		}
-		rp.Aggregator.AggregateUnsigned(curr)
+
+		aggregator, emitter := itr.create()
+		rp = &unsignedReduceFloatPoint{
+			Name:       p.Name,
+			Tags:       tags,
+			Aggregator: aggregator,
+			Emitter:    emitter,
+		}
+		w.groups[id] = rp
+		w.size += reduceGroupSize(p.Name, id)
 	}
+	rp.Aggregator.AggregateUnsigned(p)
+	return nil
+}
 
+// emit returns the points of every group of a window in reverse order.
+func (itr *unsignedReduceFloatIterator) emit(m map[string]*unsignedReduceFloatPoint, startTime int64) []FloatPoint {
 	// Reverse sort points by name & tag if our output is supposed to be ordered.
 	keys := make([]string, 0, len(m))
 	for k := range m {
@@ -8017,7 +10438,115 @@ func (itr *unsignedReduceFloatIterator) reduce() ([]FloatPoint, error) {
 		sort.Stable(sort.Reverse(floatPointsByTime(a)))
 	}
 
-	return a, nil
+	return a
+}
+
+// reduceSpilled reduces a window whose groups did not fit in memory. The
+// points of the groups in memory are written to a run on disk and the points
+// spilled by w are reduced in the same way, until every group is reduced. The
+// runs are then merged in the order the points would have been emitted.
+func (itr *unsignedReduceFloatIterator) reduceSpilled(w *unsignedReduceFloatWindow, startTime int64) ([]FloatPoint, error) {
+	runs := &floatReduceRuns{}
+	for {
+		if err := itr.writeRun(runs, w.groups, startTime); err != nil {
+			w.close()
+			runs.Close()
+			return nil, err
+		} else if w.spill == nil {
+			break
+		}
+
+		input := w.spill
+		next, err := itr.reduceSpillFile(input)
+		input.Close()
+		if err != nil {
+			runs.Close()
+			return nil, err
+		}
+		w = next
+	}
+
+	itr.runs = runs
+	return runs.read(reduceSpillBatchSize)
+}
+
+// reduceSpillFile aggregates the points spilled to f into a new window.
+func (itr *unsignedReduceFloatIterator) reduceSpillFile(f *spillFile) (*unsignedReduceFloatWindow, error) {
+	if err := f.rewind(); err != nil {
+		return nil, err
+	}
+	dec := NewUnsignedPointDecoder(context.Background(), f)
+
+	w := &unsignedReduceFloatWindow{groups: make(map[string]*unsignedReduceFloatPoint)}
+	for {
+		select {
+		case <-itr.opt.InterruptCh:
+			w.close()
+			return nil, ErrQueryInterrupted
+		default:
+		}
+
+		var p UnsignedPoint
+		if err := dec.DecodeUnsignedPoint(&p); err == io.EOF {
+			return w, nil
+		} else if err != nil {
+			w.close()
+			return nil, err
+		}
+
+		if err := itr.aggregate(w, &p); err != nil {
+			w.close()
+			return nil, err
+		}
+	}
+}
+
+// writeRun writes the points of the groups in m to a new run of runs. The
+// points are sorted by time and then by group, which is the order they are
+// returned in when the groups all fit in memory.
+func (itr *unsignedReduceFloatIterator) writeRun(runs *floatReduceRuns, m map[string]*unsignedReduceFloatPoint, startTime int64) error {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	type record struct {
+		key string
+		p   FloatPoint
+	}
+	records := make([]record, 0, len(keys))
+	for _, k := range keys {
+		rp := m[k]
+		for _, p := range rp.Emitter.Emit() {
+			p.Name = rp.Name
+			if !itr.keepTags {
+				p.Tags = rp.Tags
+			}
+			// Set the points time to the interval time if the reducer didn't provide one.
+			if p.Time == ZeroTime {
+				p.Time = startTime
+			}
+			records = append(records, record{key: k, p: p})
+		}
+	}
+	sort.SliceStable(records, func(i, j int) bool { return records[i].p.Time < records[j].p.Time })
+
+	f, err := newSpillFile(itr.opt.SpillDir)
+	if err != nil {
+		return err
+	}
+	enc := NewFloatPointEncoder(f)
+	for i := range records {
+		if err := writeSpillKey(f, records[i].key); err != nil {
+			f.Close()
+			return err
+		} else if err := enc.EncodeFloatPoint(&records[i].p); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	return runs.add(f)
 }
 
 // unsignedStreamFloatIterator streams inputs into the iterator and emits points gradually.
@@ -8287,6 +10816,9 @@ type unsignedReduceIntegerIterator struct {
 	opt      IteratorOptions
 	points   []IntegerPoint
 	keepTags bool
+
+	// Runs of the current window if its groups did not fit in memory.
+	runs *integerReduceRuns
 }
 
 func newUnsignedReduceIntegerIterator(input UnsignedIterator, opt IteratorOptions, createFn func() (UnsignedPointAggregator, IntegerPointEmitter)) *unsignedReduceIntegerIterator {
@@ -8302,7 +10834,12 @@ func newUnsignedReduceIntegerIterator(input UnsignedIterator, opt IteratorOption
 func (itr *unsignedReduceIntegerIterator) Stats() IteratorStats { return itr.input.Stats() }
 
 // Close closes the iterator and all child iterators.
-func (itr *unsignedReduceIntegerIterator) Close() error { return itr.input.Close() }
+func (itr *unsignedReduceIntegerIterator) Close() error {
+	if itr.runs != nil {
+		itr.runs.Close()
+	}
+	return itr.input.Close()
+}
 
 // Next returns the minimum value for the next available interval.
 func (itr *unsignedReduceIntegerIterator) Next() (*IntegerPoint, error) {
@@ -8329,9 +10866,35 @@ type unsignedReduceIntegerPoint struct {
 	Emitter    IntegerPointEmitter
 }
 
+// unsignedReduceIntegerWindow holds the groups of a window while its
+// points are aggregated.
+type unsignedReduceIntegerWindow struct {
+	groups map[string]*unsignedReduceIntegerPoint
+	size   int64 // estimated memory used by groups
+
+	// Points of the groups that did not fit within opt.MaxMemory.
+	spill *spillFile
+	enc   *UnsignedPointEncoder
+}
+
+// close removes the spill file of the window.
+func (w *unsignedReduceIntegerWindow) close() {
+	if w.spill != nil {
+		w.spill.Close()
+	}
+}
+
 // reduce executes fn once for every point in the next window.
 // The previous value for the dimension is passed to fn.
 func (itr *unsignedReduceIntegerIterator) reduce() ([]IntegerPoint, error) {
+	// Return the remaining points of a window that was spilled to disk.
+	if itr.runs != nil {
+		if a, err := itr.runs.read(reduceSpillBatchSize); err != nil || len(a) > 0 {
+			return a, err
+		}
+		itr.runs = nil
+	}
+
 	// Calculate next window.
 	var (
 		startTime, endTime int64
@@ -8356,11 +10919,12 @@ func (itr *unsignedReduceIntegerIterator) reduce() ([]IntegerPoint, error) {
 	}
 
 	// Create points by tags.
-	m := make(map[string]*unsignedReduceIntegerPoint)
+	w := &unsignedReduceIntegerWindow{groups: make(map[string]*unsignedReduceIntegerPoint)}
 	for {
 		// Read next point.
 		curr, err := itr.input.NextInWindow(startTime, endTime)
 		if err != nil {
+			w.close()
 			return nil, err
 		} else if curr == nil {
 			break
@@ -8380,26 +10944,57 @@ func (itr *unsignedReduceIntegerIterator) reduce() ([]IntegerPoint, error) {
 			break
 		}
 
-		// Retrieve the tags on this point for this level of the query.
-		// This may be different than the bucket dimensions.
-		tags := curr.Tags.Subset(itr.dims)
-		id := tags.ID()
+		if err := itr.aggregate(w, curr); err != nil {
+			w.close()
+			return nil, err
+		}
+	}
 
-		// Retrieve the aggregator for this name/tag combination or create one.
-		rp := m[id]
-		if rp == nil {
-			aggregator, emitter := itr.create()
-			rp = &unsignedReduceIntegerPoint{
-				Name:       curr.Name,
-				Tags:       tags,
-				Aggregator: aggregator,
-				Emitter:    emitter,
+	if w.spill != nil {
+		return itr.reduceSpilled(w, startTime)
+	}
+	return itr.emit(w.groups, startTime), nil
+}
+
+// aggregate adds p to the aggregator of its group in w. If p belongs to a new
+// group and the groups of w already use opt.MaxMemory, p is spilled to
+// disk instead.
+func (itr *unsignedReduceIntegerIterator) aggregate(w *unsignedReduceIntegerWindow, p *UnsignedPoint) error {
+	// Retrieve the tags on this point for this level of the query.
+	// This may be different than the bucket dimensions.
+	tags := p.Tags.Subset(itr.dims)
+	id := tags.ID()
+
+	// Retrieve the aggregator for this name/tag combination or create one.
+	rp := w.groups[id]
+	if rp == nil {
+		if max := itr.opt.MaxMemory; max > 0 && w.size >= max {
+			if w.spill == nil {
+				f, err := newSpillFile(itr.opt.SpillDir)
+				if err != nil {
+					return err
+				}
+				w.spill, w.enc = f, NewUnsignedPointEncoder(f)
 			}
-			m[id] = rp
+			return w.enc.EncodeUnsignedPoint(p)
 		}
-		rp.Aggregator.AggregateUnsigned(curr)
+
+		aggregator, emitter := itr.create()
+		rp = &unsignedReduceIntegerPoint{
+			Name:       p.Name,
+			Tags:       tags,
+			Aggregator: aggregator,
+			Emitter:    emitter,
+		}
+		w.groups[id] = rp
+		w.size += reduceGroupSize(p.Name, id)
 	}
+	rp.Aggregator.AggregateUnsigned(p)
+	return nil
+}
 
+// emit returns the points of every group of a window in reverse order.
+func (itr *unsignedReduceIntegerIterator) emit(m map[string]*unsignedReduceIntegerPoint, startTime int64) []IntegerPoint {
 	// Reverse sort points by name & tag if our output is supposed to be ordered.
 	keys := make([]string, 0, len(m))
 	for k := range m {
@@ -8436,7 +11031,115 @@ func (itr *unsignedReduceIntegerIterator) reduce() ([]IntegerPoint, error) {
 		sort.Stable(sort.Reverse(integerPointsByTime(a)))
 	}
 
-	return a, nil
+	return a
+}
+
+// reduceSpilled reduces a window whose groups did not fit in memory. The
+// points of the groups in memory are written to a run on disk and the points
+// spilled by w are reduced in the same way, until every group is reduced. The
+// runs are then merged in the order the points would have been emitted.
+func (itr *unsignedReduceIntegerIterator) reduceSpilled(w *unsignedReduceIntegerWindow, startTime int64) ([]IntegerPoint, error) {
+	runs := &integerReduceRuns{}
+	for {
+		if err := itr.writeRun(runs, w.groups, startTime); err != nil {
+			w.close()
+			runs.Close()
+			return nil, err
+		} else if w.spill == nil {
+			break
+		}
+
+		input := w.spill
+		next, err := itr.reduceSpillFile(input)
+		input.Close()
+		if err != nil {
+			runs.Close()
+			return nil, err
+		}
+		w = next
+	}
+
+	itr.runs = runs
+	return runs.read(reduceSpillBatchSize)
+}
+
+// reduceSpillFile aggregates the points spilled to f into a new window.
+func (itr *unsignedReduceIntegerIterator) reduceSpillFile(f *spillFile) (*unsignedReduceIntegerWindow, error) {
+	if err := f.rewind(); err != nil {
+		return nil, err
+	}
+	dec := NewUnsignedPointDecoder(context.Background(), f)
+
+	w := &unsignedReduceIntegerWindow{groups: make(map[string]*unsignedReduceIntegerPoint)}
+	for {
+		select {
+		case <-itr.opt.InterruptCh:
+			w.close()
+			return nil, ErrQueryInterrupted
+		default:
+		}
+
+		var p UnsignedPoint
+		if err := dec.DecodeUnsignedPoint(&p); err == io.EOF {
+			return w, nil
+		} else if err != nil {
+			w.close()
+			return nil, err
+		}
+
+		if err := itr.aggregate(w, &p); err != nil {
+			w.close()
+			return nil, err
+		}
+	}
+}
+
+// writeRun writes the points of the groups in m to a new run of runs. The
+// points are sorted by time and then by group, which is the order they are
+// returned in when the groups all fit in memory.
+func (itr *unsignedReduceIntegerIterator) writeRun(runs *integerReduceRuns, m map[string]*unsignedReduceIntegerPoint, startTime int64) error {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	type record struct {
+		key string
+		p   IntegerPoint
+	}
+	records := make([]record, 0, len(keys))
+	for _, k := range keys {
+		rp := m[k]
+		for _, p := range rp.Emitter.Emit() {
+			p.Name = rp.Name
+			if !itr.keepTags {
+				p.Tags = rp.Tags
+			}
+			// Set the points time to the interval time if the reducer didn't provide one.
+			if p.Time == ZeroTime {
+				p.Time = startTime
+			}
+			records = append(records, record{key: k, p: p})
+		}
+	}
+	sort.SliceStable(records, func(i, j int) bool { return records[i].p.Time < records[j].p.Time })
+
+	f, err := newSpillFile(itr.opt.SpillDir)
+	if err != nil {
+		return err
+	}
+	enc := NewIntegerPointEncoder(f)
+	for i := range records {
+		if err := writeSpillKey(f, records[i].key); err != nil {
+			f.Close()
+			return err
+		} else if err := enc.EncodeIntegerPoint(&records[i].p); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	return runs.add(f)
 }
 
 // unsignedStreamIntegerIterator streams inputs into the iterator and emits points gradually.
@@ -8706,6 +11409,9 @@ type unsignedReduceUnsignedIterator struct {
 	opt      IteratorOptions
 	points   []UnsignedPoint
 	keepTags bool
+
+	// Runs of the current window if its groups did not fit in memory.
+	runs *unsignedReduceRuns
 }
 
 func newUnsignedReduceUnsignedIterator(input UnsignedIterator, opt IteratorOptions, createFn func() (UnsignedPointAggregator, UnsignedPointEmitter)) *unsignedReduceUnsignedIterator {
@@ -8721,7 +11427,12 @@ func newUnsignedReduceUnsignedIterator(input UnsignedIterator, opt IteratorOptio
 func (itr *unsignedReduceUnsignedIterator) Stats() IteratorStats { return itr.input.Stats() }
 
 // Close closes the iterator and all child iterators.
-func (itr *unsignedReduceUnsignedIterator) Close() error { return itr.input.Close() }
+func (itr *unsignedReduceUnsignedIterator) Close() error {
+	if itr.runs != nil {
+		itr.runs.Close()
+	}
+	return itr.input.Close()
+}
 
 // Next returns the minimum value for the next available interval.
 func (itr *unsignedReduceUnsignedIterator) Next() (*UnsignedPoint, error) {
@@ -8748,9 +11459,35 @@ type unsignedReduceUnsignedPoint struct {
 	Emitter    UnsignedPointEmitter
 }
 
+// unsignedReduceUnsignedWindow holds the groups of a window while its
+// points are aggregated.
+type unsignedReduceUnsignedWindow struct {
+	groups map[string]*unsignedReduceUnsignedPoint
+	size   int64 // estimated memory used by groups
+
+	// Points of the groups that did not fit within opt.MaxMemory.
+	spill *spillFile
+	enc   *UnsignedPointEncoder
+}
+
+// close removes the spill file of the window.
+func (w *unsignedReduceUnsignedWindow) close() {
+	if w.spill != nil {
+		w.spill.Close()
+	}
+}
+
 // reduce executes fn once for every point in the next window.
 // The previous value for the dimension is passed to fn.
 func (itr *unsignedReduceUnsignedIterator) reduce() ([]UnsignedPoint, error) {
+	// Return the remaining points of a window that was spilled to disk.
+	if itr.runs != nil {
+		if a, err := itr.runs.read(reduceSpillBatchSize); err != nil || len(a) > 0 {
+			return a, err
+		}
+		itr.runs = nil
+	}
+
 	// Calculate next window.
 	var (
 		startTime, endTime int64
@@ -8775,11 +11512,12 @@ func (itr *unsignedReduceUnsignedIterator) reduce() ([]UnsignedPoint, error) {
 	}
 
 	// Create points by tags.
-	m := make(map[string]*unsignedReduceUnsignedPoint)
+	w := &unsignedReduceUnsignedWindow{groups: make(map[string]*unsignedReduceUnsignedPoint)}
 	for {
 		// Read next point.
 		curr, err := itr.input.NextInWindow(startTime, endTime)
 		if err != nil {
+			w.close()
 			return nil, err
 		} else if curr == nil {
 			break
@@ -8799,26 +11537,57 @@ func (itr *unsignedReduceUnsignedIterator) reduce() ([]UnsignedPoint, error) {
 			break
 		}
 
-		// Retrieve the tags on this point for this level of the query.
-		// This may be different than the bucket dimensions.
-		tags := curr.Tags.Subset(itr.dims)
-		id := tags.ID()
+		if err := itr.aggregate(w, curr); err != nil {
+			w.close()
+			return nil, err
+		}
+	}
 
-		// Retrieve the aggregator for this name/tag combination or create one.
-		rp := m[id]
-		if rp == nil {
-			aggregator, emitter := itr.create()
-			rp = &unsignedReduceUnsignedPoint{
-				Name:       curr.Name,
-				Tags:       tags,
-				Aggregator: aggregator,
-				Emitter:    emitter,
+	if w.spill != nil {
+		return itr.reduceSpilled(w, startTime)
+	}
+	return itr.emit(w.groups, startTime), nil
+}
+
+// aggregate adds p to the aggregator of its group in w. If p belongs to a new
+// group and the groups of w already use opt.MaxMemory, p is spilled to
+// disk instead.
+func (itr *unsignedReduceUnsignedIterator) aggregate(w *unsignedReduceUnsignedWindow, p *UnsignedPoint) error {
+	// Retrieve the tags on this point for this level of the query.
+	// This may be different than the bucket dimensions.
+	tags := p.Tags.Subset(itr.dims)
+	id := tags.ID()
+
+	// Retrieve the aggregator for this name/tag combination or create one.
+	rp := w.groups[id]
+	if rp == nil {
+		if max := itr.opt.MaxMemory; max > 0 && w.size >= max {
+			if w.spill == nil {
+				f, err := newSpillFile(itr.opt.SpillDir)
+				if err != nil {
+					return err
+				}
+				w.spill, w.enc = f, NewUnsignedPointEncoder(f)
 			}
-			m[id] = rp
+			return w.enc.EncodeUnsignedPoint(p)
 		}
-		rp.Aggregator.AggregateUnsigned(curr)
+
+		aggregator, emitter := itr.create()
+		rp = &unsignedReduceUnsignedPoint{
+			Name:       p.Name,
+			Tags:       tags,
+			Aggregator: aggregator,
+			Emitter:    emitter,
+		}
+		w.groups[id] = rp
+		w.size += reduceGroupSize(p.Name, id)
 	}
+	rp.Aggregator.AggregateUnsigned(p)
+	return nil
+}
 
+// emit returns the points of every group of a window in reverse order.
+func (itr *unsignedReduceUnsignedIterator) emit(m map[string]*unsignedReduceUnsignedPoint, startTime int64) []UnsignedPoint {
 	// Reverse sort points by name & tag if our output is supposed to be ordered.
 	keys := make([]string, 0, len(m))
 	for k := range m {
@@ -8855,7 +11624,115 @@ func (itr *unsignedReduceUnsignedIterator) reduce() ([]UnsignedPoint, error) {
 		sort.Stable(sort.Reverse(unsignedPointsByTime(a)))
 	}
 
-	return a, nil
+	return a
+}
+
+// reduceSpilled reduces a window whose groups did not fit in memory. The
+// points of the groups in memory are written to a run on disk and the points
+// spilled by w are reduced in the same way, until every group is reduced. The
+// runs are then merged in the order the points would have been emitted.
+func (itr *unsignedReduceUnsignedIterator) reduceSpilled(w *unsignedReduceUnsignedWindow, startTime int64) ([]UnsignedPoint, error) {
+	runs := &unsignedReduceRuns{}
+	for {
+		if err := itr.writeRun(runs, w.groups, startTime); err != nil {
+			w.close()
+			runs.Close()
+			return nil, err
+		} else if w.spill == nil {
+			break
+		}
+
+		input := w.spill
+		next, err := itr.reduceSpillFile(input)
+		input.Close()
+		if err != nil {
+			runs.Close()
+			return nil, err
+		}
+		w = next
+	}
+
+	itr.runs = runs
+	return runs.read(reduceSpillBatchSize)
+}
+
+// reduceSpillFile aggregates the points spilled to f into a new window.
+func (itr *unsignedReduceUnsignedIterator) reduceSpillFile(f *spillFile) (*unsignedReduceUnsignedWindow, error) {
+	if err := f.rewind(); err != nil {
+		return nil, err
+	}
+	dec := NewUnsignedPointDecoder(context.Background(), f)
+
+	w := &unsignedReduceUnsignedWindow{groups: make(map[string]*unsignedReduceUnsignedPoint)}
+	for {
+		select {
+		case <-itr.opt.InterruptCh:
+			w.close()
+			return nil, ErrQueryInterrupted
+		default:
+		}
+
+		var p UnsignedPoint
+		if err := dec.DecodeUnsignedPoint(&p); err == io.EOF {
+			return w, nil
+		} else if err != nil {
+			w.close()
+			return nil, err
+		}
+
+		if err := itr.aggregate(w, &p); err != nil {
+			w.close()
+			return nil, err
+		}
+	}
+}
+
+// writeRun writes the points of the groups in m to a new run of runs. The
+// points are sorted by time and then by group, which is the order they are
+// returned in when the groups all fit in memory.
+func (itr *unsignedReduceUnsignedIterator) writeRun(runs *unsignedReduceRuns, m map[string]*unsignedReduceUnsignedPoint, startTime int64) error {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	type record struct {
+		key string
+		p   UnsignedPoint
+	}
+	records := make([]record, 0, len(keys))
+	for _, k := range keys {
+		rp := m[k]
+		for _, p := range rp.Emitter.Emit() {
+			p.Name = rp.Name
+			if !itr.keepTags {
+				p.Tags = rp.Tags
+			}
+			// Set the points time to the interval time if the reducer didn't provide one.
+			if p.Time == ZeroTime {
+				p.Time = startTime
+			}
+			records = append(records, record{key: k, p: p})
+		}
+	}
+	sort.SliceStable(records, func(i, j int) bool { return records[i].p.Time < records[j].p.Time })
+
+	f, err := newSpillFile(itr.opt.SpillDir)
+	if err != nil {
+		return err
+	}
+	enc := NewUnsignedPointEncoder(f)
+	for i := range records {
+		if err := writeSpillKey(f, records[i].key); err != nil {
+			f.Close()
+			return err
+		} else if err := enc.EncodeUnsignedPoint(&records[i].p); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	return runs.add(f)
 }
 
 // unsignedStreamUnsignedIterator streams inputs into the iterator and emits points gradually.
@@ -9121,6 +11998,9 @@ type unsignedReduceStringIterator struct {
 	opt      IteratorOptions
 	points   []StringPoint
 	keepTags bool
+
+	// Runs of the current window if its groups did not fit in memory.
+	runs *stringReduceRuns
 }
 
 func newUnsignedReduceStringIterator(input UnsignedIterator, opt IteratorOptions, createFn func() (UnsignedPointAggregator, StringPointEmitter)) *unsignedReduceStringIterator {
@@ -9136,7 +12016,12 @@ func newUnsignedReduceStringIterator(input UnsignedIterator, opt IteratorOptions
 func (itr *unsignedReduceStringIterator) Stats() IteratorStats { return itr.input.Stats() }
 
 // Close closes the iterator and all child iterators.
-func (itr *unsignedReduceStringIterator) Close() error { return itr.input.Close() }
+func (itr *unsignedReduceStringIterator) Close() error {
+	if itr.runs != nil {
+		itr.runs.Close()
+	}
+	return itr.input.Close()
+}
 
 // Next returns the minimum value for the next available interval.
 func (itr *unsignedReduceStringIterator) Next() (*StringPoint, error) {
@@ -9163,9 +12048,35 @@ type unsignedReduceStringPoint struct {
 	Emitter    StringPointEmitter
 }
 
+// unsignedReduceStringWindow holds the groups of a window while its
+// points are aggregated.
+type unsignedReduceStringWindow struct {
+	groups map[string]*unsignedReduceStringPoint
+	size   int64 // estimated memory used by groups
+
+	// Points of the groups that did not fit within opt.MaxMemory.
+	spill *spillFile
+	enc   *UnsignedPointEncoder
+}
+
+// close removes the spill file of the window.
+func (w *unsignedReduceStringWindow) close() {
+	if w.spill != nil {
+		w.spill.Close()
+	}
+}
+
 // reduce executes fn once for every point in the next window.
 // The previous value for the dimension is passed to fn.
 func (itr *unsignedReduceStringIterator) reduce() ([]StringPoint, error) {
+	// Return the remaining points of a window that was spilled to disk.
+	if itr.runs != nil {
+		if a, err := itr.runs.read(reduceSpillBatchSize); err != nil || len(a) > 0 {
+			return a, err
+		}
+		itr.runs = nil
+	}
+
 	// Calculate next window.
 	var (
 		startTime, endTime int64
@@ -9190,11 +12101,12 @@ func (itr *unsignedReduceStringIterator) reduce() ([]StringPoint, error) {
 	}
 
 	// Create points by tags.
-	m := make(map[string]*unsignedReduceStringPoint)
+	w := &unsignedReduceStringWindow{groups: make(map[string]*unsignedReduceStringPoint)}
 	for {
 		// Read next point.
 		curr, err := itr.input.NextInWindow(startTime, endTime)
 		if err != nil {
+			w.close()
 			return nil, err
 		} else if curr == nil {
 			break
@@ -9214,63 +12126,202 @@ func (itr *unsignedReduceStringIterator) reduce() ([]StringPoint, error) {
 			break
 		}
 
-		// Retrieve the tags on this point for this level of the query.
-		// This may be different than the bucket dimensions.
-		tags := curr.Tags.Subset(itr.dims)
-		id := tags.ID()
+		if err := itr.aggregate(w, curr); err != nil {
+			w.close()
+			return nil, err
+		}
+	}
+
+	if w.spill != nil {
+		return itr.reduceSpilled(w, startTime)
+	}
+	return itr.emit(w.groups, startTime), nil
+}
+
+// aggregate adds p to the aggregator of its group in w. If p belongs to a new
+// group and the groups of w already use opt.MaxMemory, p is spilled to
+// disk instead.
+func (itr *unsignedReduceStringIterator) aggregate(w *unsignedReduceStringWindow, p *UnsignedPoint) error {
+	// Retrieve the tags on this point for this level of the query.
+	// This may be different than the bucket dimensions.
+	tags := p.Tags.Subset(itr.dims)
+	id := tags.ID()
+
+	// Retrieve the aggregator for this name/tag combination or create one.
+	rp := w.groups[id]
+	if rp == nil {
+		if max := itr.opt.MaxMemory; max > 0 && w.size >= max {
+			if w.spill == nil {
+				f, err := newSpillFile(itr.opt.SpillDir)
+				if err != nil {
+					return err
+				}
+				w.spill, w.enc = f, NewUnsignedPointEncoder(f)
+			}
+			return w.enc.EncodeUnsignedPoint(p)
+		}
+
+		aggregator, emitter := itr.create()
+		rp = &unsignedReduceStringPoint{
+			Name:       p.Name,
+			Tags:       tags,
+			Aggregator: aggregator,
+			Emitter:    emitter,
+		}
+		w.groups[id] = rp
+		w.size += reduceGroupSize(p.Name, id)
+	}
+	rp.Aggregator.AggregateUnsigned(p)
+	return nil
+}
+
+// emit returns the points of every group of a window in reverse order.
+func (itr *unsignedReduceStringIterator) emit(m map[string]*unsignedReduceStringPoint, startTime int64) []StringPoint {
+	// Reverse sort points by name & tag if our output is supposed to be ordered.
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	if len(keys) > 1 && itr.opt.Ordered {
+		sort.Sort(reverseStringSlice(keys))
+	}
+
+	// Assume the points are already sorted until proven otherwise.
+	sortedByTime := true
+	// Emit the points for each name & tag combination.
+	a := make([]StringPoint, 0, len(m))
+	for _, k := range keys {
+		rp := m[k]
+		points := rp.Emitter.Emit()
+		for i := len(points) - 1; i >= 0; i-- {
+			points[i].Name = rp.Name
+			if !itr.keepTags {
+				points[i].Tags = rp.Tags
+			}
+			// Set the points time to the interval time if the reducer didn't provide one.
+			if points[i].Time == ZeroTime {
+				points[i].Time = startTime
+			} else {
+				sortedByTime = false
+			}
+			a = append(a, points[i])
+		}
+	}
+
+	// Points may be out of order. Perform a stable sort by time if requested.
+	if !sortedByTime && itr.opt.Ordered {
+		sort.Stable(sort.Reverse(stringPointsByTime(a)))
+	}
+
+	return a
+}
+
+// reduceSpilled reduces a window whose groups did not fit in memory. The
+// points of the groups in memory are written to a run on disk and the points
+// spilled by w are reduced in the same way, until every group is reduced. The
+// runs are then merged in the order the points would have been emitted.
+func (itr *unsignedReduceStringIterator) reduceSpilled(w *unsignedReduceStringWindow, startTime int64) ([]StringPoint, error) {
+	runs := &stringReduceRuns{}
+	for {
+		if err := itr.writeRun(runs, w.groups, startTime); err != nil {
+			w.close()
+			runs.Close()
+			return nil, err
+		} else if w.spill == nil {
+			break
+		}
+
+		input := w.spill
+		next, err := itr.reduceSpillFile(input)
+		input.Close()
+		if err != nil {
+			runs.Close()
+			return nil, err
+		}
+		w = next
+	}
+
+	itr.runs = runs
+	return runs.read(reduceSpillBatchSize)
+}
+
+// reduceSpillFile aggregates the points spilled to f into a new window.
+func (itr *unsignedReduceStringIterator) reduceSpillFile(f *spillFile) (*unsignedReduceStringWindow, error) {
+	if err := f.rewind(); err != nil {
+		return nil, err
+	}
+	dec := NewUnsignedPointDecoder(context.Background(), f)
+
+	w := &unsignedReduceStringWindow{groups: make(map[string]*unsignedReduceStringPoint)}
+	for {
+		select {
+		case <-itr.opt.InterruptCh:
+			w.close()
+			return nil, ErrQueryInterrupted
+		default:
+		}
+
+		var p UnsignedPoint
+		if err := dec.DecodeUnsignedPoint(&p); err == io.EOF {
+			return w, nil
+		} else if err != nil {
+			w.close()
+			return nil, err
+		}
 
-		// Retrieve the aggregator for this name/tag combination or create one.
-		rp := m[id]
-		if rp == nil {
-			aggregator, emitter := itr.create()
-			rp = &unsignedReduceStringPoint{
-				Name:       curr.Name,
-				Tags:       tags,
-				Aggregator: aggregator,
-				Emitter:    emitter,
-			}
-			m[id] = rp
+		if err := itr.aggregate(w, &p); err != nil {
+			w.close()
+			return nil, err
 		}
-		rp.Aggregator.AggregateUnsigned(curr)
 	}
+}
 
-	// Reverse sort points by name & tag if our output is supposed to be ordered.
+// writeRun writes the points of the groups in m to a new run of runs. The
+// points are sorted by time and then by group, which is the order they are
+// returned in when the groups all fit in memory.
+func (itr *unsignedReduceStringIterator) writeRun(runs *stringReduceRuns, m map[string]*unsignedReduceStringPoint, startTime int64) error {
 	keys := make([]string, 0, len(m))
 	for k := range m {
 		keys = append(keys, k)
 	}
-	if len(keys) > 1 && itr.opt.Ordered {
-		sort.Sort(reverseStringSlice(keys))
-	}
+	sort.Strings(keys)
 
-	// Assume the points are already sorted until proven otherwise.
-	sortedByTime := true
-	// Emit the points for each name & tag combination.
-	a := make([]StringPoint, 0, len(m))
+	type record struct {
+		key string
+		p   StringPoint
+	}
+	records := make([]record, 0, len(keys))
 	for _, k := range keys {
 		rp := m[k]
-		points := rp.Emitter.Emit()
-		for i := len(points) - 1; i >= 0; i-- {
-			points[i].Name = rp.Name
+		for _, p := range rp.Emitter.Emit() {
+			p.Name = rp.Name
 			if !itr.keepTags {
-				points[i].Tags = rp.Tags
+				p.Tags = rp.Tags
 			}
 			// Set the points time to the interval time if the reducer didn't provide one.
-			if points[i].Time == ZeroTime {
-				points[i].Time = startTime
-			} else {
-				sortedByTime = false
+			if p.Time == ZeroTime {
+				p.Time = startTime
 			}
-			a = append(a, points[i])
+			records = append(records, record{key: k, p: p})
 		}
 	}
+	sort.SliceStable(records, func(i, j int) bool { return records[i].p.Time < records[j].p.Time })
 
-	// Points may be out of order. Perform a stable sort by time if requested.
-	if !sortedByTime && itr.opt.Ordered {
-		sort.Stable(sort.Reverse(stringPointsByTime(a)))
+	f, err := newSpillFile(itr.opt.SpillDir)
+	if err != nil {
+		return err
 	}
-
-	return a, nil
+	enc := NewStringPointEncoder(f)
+	for i := range records {
+		if err := writeSpillKey(f, records[i].key); err != nil {
+			f.Close()
+			return err
+		} else if err := enc.EncodeStringPoint(&records[i].p); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	return runs.add(f)
 }
 
 // unsignedStreamStringIterator streams inputs into the iterator and emits points gradually.
@@ -9540,6 +12591,9 @@ type unsignedReduceBooleanIterator struct {
 	opt      IteratorOptions
 	points   []BooleanPoint
 	keepTags bool
+
+	// Runs of the current window if its groups did not fit in memory.
+	runs *booleanReduceRuns
 }
 
 func newUnsignedReduceBooleanIterator(input UnsignedIterator, opt IteratorOptions, createFn func() (UnsignedPointAggregator, BooleanPointEmitter)) *unsignedReduceBooleanIterator {
@@ -9555,7 +12609,12 @@ func newUnsignedReduceBooleanIterator(input UnsignedIterator, opt IteratorOption
 func (itr *unsignedReduceBooleanIterator) Stats() IteratorStats { return itr.input.Stats() }
 
 // Close closes the iterator and all child iterators.
-func (itr *unsignedReduceBooleanIterator) Close() error { return itr.input.Close() }
+func (itr *unsignedReduceBooleanIterator) Close() error {
+	if itr.runs != nil {
+		itr.runs.Close()
+	}
+	return itr.input.Close()
+}
 
 // Next returns the minimum value for the next available interval.
 func (itr *unsignedReduceBooleanIterator) Next() (*BooleanPoint, error) {
@@ -9582,9 +12641,35 @@ type unsignedReduceBooleanPoint struct {
 	Emitter    BooleanPointEmitter
 }
 
+// unsignedReduceBooleanWindow holds the groups of a window while its
+// points are aggregated.
+type unsignedReduceBooleanWindow struct {
+	groups map[string]*unsignedReduceBooleanPoint
+	size   int64 // estimated memory used by groups
+
+	// Points of the groups that did not fit within opt.MaxMemory.
+	spill *spillFile
+	enc   *UnsignedPointEncoder
+}
+
+// close removes the spill file of the window.
+func (w *unsignedReduceBooleanWindow) close() {
+	if w.spill != nil {
+		w.spill.Close()
+	}
+}
+
 // reduce executes fn once for every point in the next window.
 // The previous value for the dimension is passed to fn.
 func (itr *unsignedReduceBooleanIterator) reduce() ([]BooleanPoint, error) {
+	// Return the remaining points of a window that was spilled to disk.
+	if itr.runs != nil {
+		if a, err := itr.runs.read(reduceSpillBatchSize); err != nil || len(a) > 0 {
+			return a, err
+		}
+		itr.runs = nil
+	}
+
 	// Calculate next window.
 	var (
 		startTime, endTime int64
@@ -9609,11 +12694,12 @@ func (itr *unsignedReduceBooleanIterator) reduce() ([]BooleanPoint, error) {
 	}
 
 	// Create points by tags.
-	m := make(map[string]*unsignedReduceBooleanPoint)
+	w := &unsignedReduceBooleanWindow{groups: make(map[string]*unsignedReduceBooleanPoint)}
 	for {
 		// Read next point.
 		curr, err := itr.input.NextInWindow(startTime, endTime)
 		if err != nil {
+			w.close()
 			return nil, err
 		} else if curr == nil {
 			break
@@ -9633,26 +12719,57 @@ func (itr *unsignedReduceBooleanIterator) reduce() ([]BooleanPoint, error) {
 			break
 		}
 
-		// Retrieve the tags on this point for this level of the query.
-		// This may be different than the bucket dimensions.
-		tags := curr.Tags.Subset(itr.dims)
-		id := tags.ID()
+		if err := itr.aggregate(w, curr); err != nil {
+			w.close()
+			return nil, err
+		}
+	}
 
-		// Retrieve the aggregator for this name/tag combination or create one.
-		rp := m[id]
-		if rp == nil {
-			aggregator, emitter := itr.create()
-			rp = &unsignedReduceBooleanPoint{
-				Name:       curr.Name,
-				Tags:       tags,
-				Aggregator: aggregator,
-				Emitter:    emitter,
+	if w.spill != nil {
+		return itr.reduceSpilled(w, startTime)
+	}
+	return itr.emit(w.groups, startTime), nil
+}
+
+// aggregate adds p to the aggregator of its group in w. If p belongs to a new
+// group and the groups of w already use opt.MaxMemory, p is spilled to
+// disk instead.
+func (itr *unsignedReduceBooleanIterator) aggregate(w *unsignedReduceBooleanWindow, p *UnsignedPoint) error {
+	// Retrieve the tags on this point for this level of the query.
+	// This may be different than the bucket dimensions.
+	tags := p.Tags.Subset(itr.dims)
+	id := tags.ID()
+
+	// Retrieve the aggregator for this name/tag combination or create one.
+	rp := w.groups[id]
+	if rp == nil {
+		if max := itr.opt.MaxMemory; max > 0 && w.size >= max {
+			if w.spill == nil {
+				f, err := newSpillFile(itr.opt.SpillDir)
+				if err != nil {
+					return err
+				}
+				w.spill, w.enc = f, NewUnsignedPointEncoder(f)
 			}
-			m[id] = rp
+			return w.enc.EncodeUnsignedPoint(p)
 		}
-		rp.Aggregator.AggregateUnsigned(curr)
+
+		aggregator, emitter := itr.create()
+		rp = &unsignedReduceBooleanPoint{
+			Name:       p.Name,
+			Tags:       tags,
+			Aggregator: aggregator,
+			Emitter:    emitter,
+		}
+		w.groups[id] = rp
+		w.size += reduceGroupSize(p.Name, id)
 	}
+	rp.Aggregator.AggregateUnsigned(p)
+	return nil
+}
 
+// emit returns the points of every group of a window in reverse order.
+func (itr *unsignedReduceBooleanIterator) emit(m map[string]*unsignedReduceBooleanPoint, startTime int64) []BooleanPoint {
 	// Reverse sort points by name & tag if our output is supposed to be ordered.
 	keys := make([]string, 0, len(m))
 	for k := range m {
@@ -9689,7 +12806,115 @@ func (itr *unsignedReduceBooleanIterator) reduce() ([]BooleanPoint, error) {
 		sort.Stable(sort.Reverse(booleanPointsByTime(a)))
 	}
 
-	return a, nil
+	return a
+}
+
+// reduceSpilled reduces a window whose groups did not fit in memory. The
+// points of the groups in memory are written to a run on disk and the points
+// spilled by w are reduced in the same way, until every group is reduced. The
+// runs are then merged in the order the points would have been emitted.
+func (itr *unsignedReduceBooleanIterator) reduceSpilled(w *unsignedReduceBooleanWindow, startTime int64) ([]BooleanPoint, error) {
+	runs := &booleanReduceRuns{}
+	for {
+		if err := itr.writeRun(runs, w.groups, startTime); err != nil {
+			w.close()
+			runs.Close()
+			return nil, err
+		} else if w.spill == nil {
+			break
+		}
+
+		input := w.spill
+		next, err := itr.reduceSpillFile(input)
+		input.Close()
+		if err != nil {
+			runs.Close()
+			return nil, err
+		}
+		w = next
+	}
+
+	itr.runs = runs
+	return runs.read(reduceSpillBatchSize)
+}
+
+// reduceSpillFile aggregates the points spilled to f into a new window.
+func (itr *unsignedReduceBooleanIterator) reduceSpillFile(f *spillFile) (*unsignedReduceBooleanWindow, error) {
+	if err := f.rewind(); err != nil {
+		return nil, err
+	}
+	dec := NewUnsignedPointDecoder(context.Background(), f)
+
+	w := &unsignedReduceBooleanWindow{groups: make(map[string]*unsignedReduceBooleanPoint)}
+	for {
+		select {
+		case <-itr.opt.InterruptCh:
+			w.close()
+			return nil, ErrQueryInterrupted
+		default:
+		}
+
+		var p UnsignedPoint
+		if err := dec.DecodeUnsignedPoint(&p); err == io.EOF {
+			return w, nil
+		} else if err != nil {
+			w.close()
+			return nil, err
+		}
+
+		if err := itr.aggregate(w, &p); err != nil {
+			w.close()
+			return nil, err
+		}
+	}
+}
+
+// writeRun writes the points of the groups in m to a new run of runs. The
+// points are sorted by time and then by group, which is the order they are
+// returned in when the groups all fit in memory.
+func (itr *unsignedReduceBooleanIterator) writeRun(runs *booleanReduceRuns, m map[string]*unsignedReduceBooleanPoint, startTime int64) error {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	type record struct {
+		key string
+		p   BooleanPoint
+	}
+	records := make([]record, 0, len(keys))
+	for _, k := range keys {
+		rp := m[k]
+		for _, p := range rp.Emitter.Emit() {
+			p.Name = rp.Name
+			if !itr.keepTags {
+				p.Tags = rp.Tags
+			}
+			// Set the points time to the interval time if the reducer didn't provide one.
+			if p.Time == ZeroTime {
+				p.Time = startTime
+			}
+			records = append(records, record{key: k, p: p})
+		}
+	}
+	sort.SliceStable(records, func(i, j int) bool { return records[i].p.Time < records[j].p.Time })
+
+	f, err := newSpillFile(itr.opt.SpillDir)
+	if err != nil {
+		return err
+	}
+	enc := NewBooleanPointEncoder(f)
+	for i := range records {
+		if err := writeSpillKey(f, records[i].key); err != nil {
+			f.Close()
+			return err
+		} else if err := enc.EncodeBooleanPoint(&records[i].p); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	return runs.add(f)
 }
 
 // unsignedStreamBooleanIterator streams inputs into the iterator and emits points gradually.
@@ -10552,6 +13777,10 @@ func (itr *stringSortedMergeIterator) Next() (*StringPoint, error) { return itr.
 func (itr *stringSortedMergeIterator) pop() (*StringPoint, error) {
 	// Initialize the heap. See the MergeIterator to see why this has to be done lazily.
 	if !itr.init {
+		if err := itr.spill(); err != nil {
+			return nil, err
+		}
+
 		items := itr.heap.items
 		itr.heap.items = make([]*stringSortedMergeHeapItem, 0, len(items))
 		for _, item := range items {
@@ -10590,6 +13819,113 @@ func (itr *stringSortedMergeIterator) pop() (*StringPoint, error) {
 	return p, nil
 }
 
+// spill merges the inputs a batch at a time into runs on disk while there are
+// more inputs than can be merged within opt.MaxMemory. The runs then replace
+// the inputs.
+func (itr *stringSortedMergeIterator) spill() error {
+	n := sortedMergeMaxInputs(itr.heap.opt.MaxMemory)
+	if n == 0 || len(itr.inputs) <= n {
+		return nil
+	}
+
+	for len(itr.inputs) > n {
+		inputs := itr.inputs
+		runs := make([]StringIterator, 0, (len(inputs)+n-1)/n)
+		for len(inputs) > 0 {
+			batch := inputs
+			if len(batch) > n {
+				batch = batch[:n]
+			}
+			inputs = inputs[len(batch):]
+
+			run, err := spillStringSortedMerge(batch, itr.heap.opt)
+			if err != nil {
+				// Keep the runs and remaining inputs so they are closed.
+				itr.inputs = append(runs, inputs...)
+				itr.heap.items = nil
+				return err
+			}
+			runs = append(runs, run)
+		}
+		itr.inputs = runs
+	}
+
+	itr.heap.items = make([]*stringSortedMergeHeapItem, 0, len(itr.inputs))
+	for _, input := range itr.inputs {
+		itr.heap.items = append(itr.heap.items, &stringSortedMergeHeapItem{itr: input})
+	}
+	return nil
+}
+
+// spillStringSortedMerge writes the sorted merge of inputs to a spill file
+// and returns an iterator that reads it back. The inputs are closed.
+func spillStringSortedMerge(inputs []StringIterator, opt IteratorOptions) (StringIterator, error) {
+	itr := newStringSortedMergeIterator(inputs, opt).(*stringSortedMergeIterator)
+	defer itr.Close()
+
+	f, err := newSpillFile(opt.SpillDir)
+	if err != nil {
+		return nil, err
+	}
+
+	enc := NewStringPointEncoder(f)
+	for {
+		select {
+		case <-opt.InterruptCh:
+			f.Close()
+			return nil, ErrQueryInterrupted
+		default:
+		}
+
+		p, err := itr.pop()
+		if err != nil {
+			f.Close()
+			return nil, err
+		} else if p == nil {
+			break
+		}
+
+		if err := enc.EncodeStringPoint(p); err != nil {
+			f.Close()
+			return nil, err
+		}
+	}
+
+	if err := f.rewind(); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &stringSpillIterator{
+		f:     f,
+		dec:   NewStringPointDecoder(context.Background(), f),
+		stats: itr.Stats(),
+	}, nil
+}
+
+// stringSpillIterator reads the points written to a spill file.
+type stringSpillIterator struct {
+	f     *spillFile
+	dec   *StringPointDecoder
+	point StringPoint
+	stats IteratorStats // stats of the iterators that were spilled
+}
+
+// Stats returns the stats of the iterators that were spilled.
+func (itr *stringSpillIterator) Stats() IteratorStats { return itr.stats }
+
+// Close closes and removes the spill file.
+func (itr *stringSpillIterator) Close() error { return itr.f.Close() }
+
+// Next returns the next point from the spill file.
+func (itr *stringSpillIterator) Next() (*StringPoint, error) {
+	if err := itr.dec.DecodeStringPoint(&itr.point); err == io.EOF {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return &itr.point, nil
+}
+
 // stringSortedMergeHeap represents a heap of stringSortedMergeHeapItems.
 // Items are sorted with the following priority:
 //     - By their measurement name;
@@ -11246,6 +14582,100 @@ func (itr *stringChanIterator) Next() (*StringPoint, error) {
 	return p, nil
 }
 
+// stringReduceRun is a run of reduced points of a window spilled to disk.
+// The points are sorted in the order they are returned by a reduce iterator.
+type stringReduceRun struct {
+	f   *spillFile
+	dec *StringPointDecoder
+	key string // group of p
+	p   StringPoint
+}
+
+// next reads the next point of the run. It returns io.EOF at the end of the run.
+func (r *stringReduceRun) next() error {
+	key, err := readSpillKey(r.f)
+	if err != nil {
+		return err
+	}
+	r.key = key
+	return r.dec.DecodeStringPoint(&r.p)
+}
+
+// stringReduceRuns merges the runs of a window whose groups did not fit
+// in memory.
+type stringReduceRuns []*stringReduceRun
+
+func (a stringReduceRuns) Len() int      { return len(a) }
+func (a stringReduceRuns) Swap(i, j int) { a[i], a[j] = a[j], a[i] }
+func (a stringReduceRuns) Less(i, j int) bool {
+	if a[i].p.Time != a[j].p.Time {
+		return a[i].p.Time < a[j].p.Time
+	}
+	return a[i].key < a[j].key
+}
+
+func (a *stringReduceRuns) Push(x interface{}) {
+	*a = append(*a, x.(*stringReduceRun))
+}
+
+func (a *stringReduceRuns) Pop() interface{} {
+	old := *a
+	n := len(old)
+	r := old[n-1]
+	*a = old[:n-1]
+	return r
+}
+
+// add adds the run written to f. The runs take ownership of f.
+func (a *stringReduceRuns) add(f *spillFile) error {
+	if err := f.rewind(); err != nil {
+		f.Close()
+		return err
+	}
+
+	r := &stringReduceRun{f: f, dec: NewStringPointDecoder(context.Background(), f)}
+	if err := r.next(); err == io.EOF {
+		return f.Close()
+	} else if err != nil {
+		f.Close()
+		return err
+	}
+	heap.Push(a, r)
+	return nil
+}
+
+// read returns up to n of the next points of the runs. The points are in
+// reverse order so they can be popped off the end of the slice.
+func (a *stringReduceRuns) read(n int) ([]StringPoint, error) {
+	points := make([]StringPoint, 0, n)
+	for len(points) < n && len(*a) > 0 {
+		r := (*a)[0]
+		points = append(points, r.p)
+		if err := r.next(); err == io.EOF {
+			heap.Pop(a)
+			r.f.Close()
+		} else if err != nil {
+			return nil, err
+		} else {
+			heap.Fix(a, 0)
+		}
+	}
+
+	for i, j := 0, len(points)-1; i < j; i, j = i+1, j-1 {
+		points[i], points[j] = points[j], points[i]
+	}
+	return points, nil
+}
+
+// Close removes the files of the remaining runs.
+func (a *stringReduceRuns) Close() error {
+	for _, r := range *a {
+		r.f.Close()
+	}
+	*a = nil
+	return nil
+}
+
 // stringReduceFloatIterator executes a reducer for every interval and buffers the result.
 type stringReduceFloatIterator struct {
 	input    *bufStringIterator
@@ -11254,6 +14684,9 @@ type stringReduceFloatIterator struct {
 	opt      IteratorOptions
 	points   []FloatPoint
 	keepTags bool
+
+	// Runs of the current window if its groups did not fit in memory.
+	runs *floatReduceRuns
 }
 
 func newStringReduceFloatIterator(input StringIterator, opt IteratorOptions, createFn func() (StringPointAggregator, FloatPointEmitter)) *stringReduceFloatIterator {
@@ -11269,7 +14702,12 @@ func newStringReduceFloatIterator(input StringIterator, opt IteratorOptions, cre
 func (itr *stringReduceFloatIterator) Stats() IteratorStats { return itr.input.Stats() }
 
 // Close closes the iterator and all child iterators.
-func (itr *stringReduceFloatIterator) Close() error { return itr.input.Close() }
+func (itr *stringReduceFloatIterator) Close() error {
+	if itr.runs != nil {
+		itr.runs.Close()
+	}
+	return itr.input.Close()
+}
 
 // Next returns the minimum value for the next available interval.
 func (itr *stringReduceFloatIterator) Next() (*FloatPoint, error) {
@@ -11296,9 +14734,35 @@ type stringReduceFloatPoint struct {
 	Emitter    FloatPointEmitter
 }
 
+// stringReduceFloatWindow holds the groups of a window while its
+// points are aggregated.
+type stringReduceFloatWindow struct {
+	groups map[string]*stringReduceFloatPoint
+	size   int64 // estimated memory used by groups
+
+	// Points of the groups that did not fit within opt.MaxMemory.
+	spill *spillFile
+	enc   *StringPointEncoder
+}
+
+// close removes the spill file of the window.
+func (w *stringReduceFloatWindow) close() {
+	if w.spill != nil {
+		w.spill.Close()
+	}
+}
+
 // reduce executes fn once for every point in the next window.
 // The previous value for the dimension is passed to fn.
 func (itr *stringReduceFloatIterator) reduce() ([]FloatPoint, error) {
+	// Return the remaining points of a window that was spilled to disk.
+	if itr.runs != nil {
+		if a, err := itr.runs.read(reduceSpillBatchSize); err != nil || len(a) > 0 {
+			return a, err
+		}
+		itr.runs = nil
+	}
+
 	// Calculate next window.
 	var (
 		startTime, endTime int64
@@ -11323,11 +14787,12 @@ func (itr *stringReduceFloatIterator) reduce() ([]FloatPoint, error) {
 	}
 
 	// Create points by tags.
-	m := make(map[string]*stringReduceFloatPoint)
+	w := &stringReduceFloatWindow{groups: make(map[string]*stringReduceFloatPoint)}
 	for {
 		// Read next point.
 		curr, err := itr.input.NextInWindow(startTime, endTime)
 		if err != nil {
+			w.close()
 			return nil, err
 		} else if curr == nil {
 			break
@@ -11347,26 +14812,57 @@ func (itr *stringReduceFloatIterator) reduce() ([]FloatPoint, error) {
 			break
 		}
 
-		// Retrieve the tags on this point for this level of the query.
-		// This may be different than the bucket dimensions.
-		tags := curr.Tags.Subset(itr.dims)
-		id := tags.ID()
+		if err := itr.aggregate(w, curr); err != nil {
+			w.close()
+			return nil, err
+		}
+	}
 
-		// Retrieve the aggregator for this name/tag combination or create one.
-		rp := m[id]
-		if rp == nil {
-			aggregator, emitter := itr.create()
-			rp = &stringReduceFloatPoint{
-				Name:       curr.Name,
-				Tags:       tags,
-				Aggregator: aggregator,
-				Emitter:    emitter,
+	if w.spill != nil {
+		return itr.reduceSpilled(w, startTime)
+	}
+	return itr.emit(w.groups, startTime), nil
+}
+
+// aggregate adds p to the aggregator of its group in w. If p belongs to a new
+// group and the groups of w already use opt.MaxMemory, p is spilled to
+// disk instead.
+func (itr *stringReduceFloatIterator) aggregate(w *stringReduceFloatWindow, p *StringPoint) error {
+	// Retrieve the tags on this point for this level of the query.
+	// This may be different than the bucket dimensions.
+	tags := p.Tags.Subset(itr.dims)
+	id := tags.ID()
+
+	// Retrieve the aggregator for this name/tag combination or create one.
+	rp := w.groups[id]
+	if rp == nil {
+		if max := itr.opt.MaxMemory; max > 0 && w.size >= max {
+			if w.spill == nil {
+				f, err := newSpillFile(itr.opt.SpillDir)
+				if err != nil {
+					return err
+				}
+				w.spill, w.enc = f, NewStringPointEncoder(f)
 			}
-			m[id] = rp
+			return w.enc.EncodeStringPoint(p)
 		}
-		rp.Aggregator.AggregateString(curr)
+
+		aggregator, emitter := itr.create()
+		rp = &stringReduceFloatPoint{
+			Name:       p.Name,
+			Tags:       tags,
+			Aggregator: aggregator,
+			Emitter:    emitter,
+		}
+		w.groups[id] = rp
+		w.size += reduceGroupSize(p.Name, id)
 	}
+	rp.Aggregator.AggregateString(p)
+	return nil
+}
 
+// emit returns the points of every group of a window in reverse order.
+func (itr *stringReduceFloatIterator) emit(m map[string]*stringReduceFloatPoint, startTime int64) []FloatPoint {
 	// Reverse sort points by name & tag if our output is supposed to be ordered.
 	keys := make([]string, 0, len(m))
 	for k := range m {
@@ -11403,7 +14899,115 @@ func (itr *stringReduceFloatIterator) reduce() ([]FloatPoint, error) {
 		sort.Stable(sort.Reverse(floatPointsByTime(a)))
 	}
 
-	return a, nil
+	return a
+}
+
+// reduceSpilled reduces a window whose groups did not fit in memory. The
+// points of the groups in memory are written to a run on disk and the points
+// spilled by w are reduced in the same way, until every group is reduced. The
+// runs are then merged in the order the points would have been emitted.
+func (itr *stringReduceFloatIterator) reduceSpilled(w *stringReduceFloatWindow, startTime int64) ([]FloatPoint, error) {
+	runs := &floatReduceRuns{}
+	for {
+		if err := itr.writeRun(runs, w.groups, startTime); err != nil {
+			w.close()
+			runs.Close()
+			return nil, err
+		} else if w.spill == nil {
+			break
+		}
+
+		input := w.spill
+		next, err := itr.reduceSpillFile(input)
+		input.Close()
+		if err != nil {
+			runs.Close()
+			return nil, err
+		}
+		w = next
+	}
+
+	itr.runs = runs
+	return runs.read(reduceSpillBatchSize)
+}
+
+// reduceSpillFile aggregates the points spilled to f into a new window.
+func (itr *stringReduceFloatIterator) reduceSpillFile(f *spillFile) (*stringReduceFloatWindow, error) {
+	if err := f.rewind(); err != nil {
+		return nil, err
+	}
+	dec := NewStringPointDecoder(context.Background(), f)
+
+	w := &stringReduceFloatWindow{groups: make(map[string]*stringReduceFloatPoint)}
+	for {
+		select {
+		case <-itr.opt.InterruptCh:
+			w.close()
+			return nil, ErrQueryInterrupted
+		default:
+		}
+
+		var p StringPoint
+		if err := dec.DecodeStringPoint(&p); err == io.EOF {
+			return w, nil
+		} else if err != nil {
+			w.close()
+			return nil, err
+		}
+
+		if err := itr.aggregate(w, &p); err != nil {
+			w.close()
+			return nil, err
+		}
+	}
+}
+
+// writeRun writes the points of the groups in m to a new run of runs. The
+// points are sorted by time and then by group, which is the order they are
+// returned in when the groups all fit in memory.
+func (itr *stringReduceFloatIterator) writeRun(runs *floatReduceRuns, m map[string]*stringReduceFloatPoint, startTime int64) error {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	type record struct {
+		key string
+		p   FloatPoint
+	}
+	records := make([]record, 0, len(keys))
+	for _, k := range keys {
+		rp := m[k]
+		for _, p := range rp.Emitter.Emit() {
+			p.Name = rp.Name
+			if !itr.keepTags {
+				p.Tags = rp.Tags
+			}
+			// Set the points time to the interval time if the reducer didn't provide one.
+			if p.Time == ZeroTime {
+				p.Time = startTime
+			}
+			records = append(records, record{key: k, p: p})
+		}
+	}
+	sort.SliceStable(records, func(i, j int) bool { return records[i].p.Time < records[j].p.Time })
+
+	f, err := newSpillFile(itr.opt.SpillDir)
+	if err != nil {
+		return err
+	}
+	enc := NewFloatPointEncoder(f)
+	for i := range records {
+		if err := writeSpillKey(f, records[i].key); err != nil {
+			f.Close()
+			return err
+		} else if err := enc.EncodeFloatPoint(&records[i].p); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	return runs.add(f)
 }
 
 // stringStreamFloatIterator streams inputs into the iterator and emits points gradually.
@@ -11673,6 +15277,9 @@ type stringReduceIntegerIterator struct {
 	opt      IteratorOptions
 	points   []IntegerPoint
 	keepTags bool
+
+	// Runs of the current window if its groups did not fit in memory.
+	runs *integerReduceRuns
 }
 
 func newStringReduceIntegerIterator(input StringIterator, opt IteratorOptions, createFn func() (StringPointAggregator, IntegerPointEmitter)) *stringReduceIntegerIterator {
@@ -11688,7 +15295,12 @@ func newStringReduceIntegerIterator(input StringIterator, opt IteratorOptions, c
 func (itr *stringReduceIntegerIterator) Stats() IteratorStats { return itr.input.Stats() }
 
 // Close closes the iterator and all child iterators.
-func (itr *stringReduceIntegerIterator) Close() error { return itr.input.Close() }
+func (itr *stringReduceIntegerIterator) Close() error {
+	if itr.runs != nil {
+		itr.runs.Close()
+	}
+	return itr.input.Close()
+}
 
 // Next returns the minimum value for the next available interval.
 func (itr *stringReduceIntegerIterator) Next() (*IntegerPoint, error) {
@@ -11715,9 +15327,35 @@ type stringReduceIntegerPoint struct {
 	Emitter    IntegerPointEmitter
 }
 
+// stringReduceIntegerWindow holds the groups of a window while its
+// points are aggregated.
+type stringReduceIntegerWindow struct {
+	groups map[string]*stringReduceIntegerPoint
+	size   int64 // estimated memory used by groups
+
+	// Points of the groups that did not fit within opt.MaxMemory.
+	spill *spillFile
+	enc   *StringPointEncoder
+}
+
+// close removes the spill file of the window.
+func (w *stringReduceIntegerWindow) close() {
+	if w.spill != nil {
+		w.spill.Close()
+	}
+}
+
 // reduce executes fn once for every point in the next window.
 // The previous value for the dimension is passed to fn.
 func (itr *stringReduceIntegerIterator) reduce() ([]IntegerPoint, error) {
+	// Return the remaining points of a window that was spilled to disk.
+	if itr.runs != nil {
+		if a, err := itr.runs.read(reduceSpillBatchSize); err != nil || len(a) > 0 {
+			return a, err
+		}
+		itr.runs = nil
+	}
+
 	// Calculate next window.
 	var (
 		startTime, endTime int64
@@ -11742,11 +15380,12 @@ func (itr *stringReduceIntegerIterator) reduce() ([]IntegerPoint, error) {
 	}
 
 	// Create points by tags.
-	m := make(map[string]*stringReduceIntegerPoint)
+	w := &stringReduceIntegerWindow{groups: make(map[string]*stringReduceIntegerPoint)}
 	for {
 		// Read next point.
 		curr, err := itr.input.NextInWindow(startTime, endTime)
 		if err != nil {
+			w.close()
 			return nil, err
 		} else if curr == nil {
 			break
@@ -11766,26 +15405,57 @@ func (itr *stringReduceIntegerIterator) reduce() ([]IntegerPoint, error) {
 			break
 		}
 
-		// Retrieve the tags on this point for this level of the query.
-		// This may be different than the bucket dimensions.
-		tags := curr.Tags.Subset(itr.dims)
-		id := tags.ID()
+		if err := itr.aggregate(w, curr); err != nil {
+			w.close()
+			return nil, err
+		}
+	}
 
-		// Retrieve the aggregator for this name/tag combination or create one.
-		rp := m[id]
-		if rp == nil {
-			aggregator, emitter := itr.create()
-			rp = &stringReduceIntegerPoint{
-				Name:       curr.Name,
-				Tags:       tags,
-				Aggregator: aggregator,
-				Emitter:    emitter,
+	if w.spill != nil {
+		return itr.reduceSpilled(w, startTime)
+	}
+	return itr.emit(w.groups, startTime), nil
+}
+
+// aggregate adds p to the aggregator of its group in w. If p belongs to a new
+// group and the groups of w already use opt.MaxMemory, p is spilled to
+// disk instead.
+func (itr *stringReduceIntegerIterator) aggregate(w *stringReduceIntegerWindow, p *StringPoint) error {
+	// Retrieve the tags on this point for this level of the query.
+	// This may be different than the bucket dimensions.
+	tags := p.Tags.Subset(itr.dims)
+	id := tags.ID()
+
+	// Retrieve the aggregator for this name/tag combination or create one.
+	rp := w.groups[id]
+	if rp == nil {
+		if max := itr.opt.MaxMemory; max > 0 && w.size >= max {
+			if w.spill == nil {
+				f, err := newSpillFile(itr.opt.SpillDir)
+				if err != nil {
+					return err
+				}
+				w.spill, w.enc = f, NewStringPointEncoder(f)
 			}
-			m[id] = rp
+			return w.enc.EncodeStringPoint(p)
 		}
-		rp.Aggregator.AggregateString(curr)
+
+		aggregator, emitter := itr.create()
+		rp = &stringReduceIntegerPoint{
+			Name:       p.Name,
+			Tags:       tags,
+			Aggregator: aggregator,
+			Emitter:    emitter,
+		}
+		w.groups[id] = rp
+		w.size += reduceGroupSize(p.Name, id)
 	}
+	rp.Aggregator.AggregateString(p)
+	return nil
+}
 
+// emit returns the points of every group of a window in reverse order.
+func (itr *stringReduceIntegerIterator) emit(m map[string]*stringReduceIntegerPoint, startTime int64) []IntegerPoint {
 	// Reverse sort points by name & tag if our output is supposed to be ordered.
 	keys := make([]string, 0, len(m))
 	for k := range m {
@@ -11822,7 +15492,115 @@ func (itr *stringReduceIntegerIterator) reduce() ([]IntegerPoint, error) {
 		sort.Stable(sort.Reverse(integerPointsByTime(a)))
 	}
 
-	return a, nil
+	return a
+}
+
+// reduceSpilled reduces a window whose groups did not fit in memory. The
+// points of the groups in memory are written to a run on disk and the points
+// spilled by w are reduced in the same way, until every group is reduced. The
+// runs are then merged in the order the points would have been emitted.
+func (itr *stringReduceIntegerIterator) reduceSpilled(w *stringReduceIntegerWindow, startTime int64) ([]IntegerPoint, error) {
+	runs := &integerReduceRuns{}
+	for {
+		if err := itr.writeRun(runs, w.groups, startTime); err != nil {
+			w.close()
+			runs.Close()
+			return nil, err
+		} else if w.spill == nil {
+			break
+		}
+
+		input := w.spill
+		next, err := itr.reduceSpillFile(input)
+		input.Close()
+		if err != nil {
+			runs.Close()
+			return nil, err
+		}
+		w = next
+	}
+
+	itr.runs = runs
+	return runs.read(reduceSpillBatchSize)
+}
+
+// reduceSpillFile aggregates the points spilled to f into a new window.
+func (itr *stringReduceIntegerIterator) reduceSpillFile(f *spillFile) (*stringReduceIntegerWindow, error) {
+	if err := f.rewind(); err != nil {
+		return nil, err
+	}
+	dec := NewStringPointDecoder(context.Background(), f)
+
+	w := &stringReduceIntegerWindow{groups: make(map[string]*stringReduceIntegerPoint)}
+	for {
+		select {
+		case <-itr.opt.InterruptCh:
+			w.close()
+			return nil, ErrQueryInterrupted
+		default:
+		}
+
+		var p StringPoint
+		if err := dec.DecodeStringPoint(&p); err == io.EOF {
+			return w, nil
+		} else if err != nil {
+			w.close()
+			return nil, err
+		}
+
+		if err := itr.aggregate(w, &p); err != nil {
+			w.close()
+			return nil, err
+		}
+	}
+}
+
+// writeRun writes the points of the groups in m to a new run of runs. The
+// points are sorted by time and then by group, which is the order they are
+// returned in when the groups all fit in memory.
+func (itr *stringReduceIntegerIterator) writeRun(runs *integerReduceRuns, m map[string]*stringReduceIntegerPoint, startTime int64) error {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	type record struct {
+		key string
+		p   IntegerPoint
+	}
+	records := make([]record, 0, len(keys))
+	for _, k := range keys {
+		rp := m[k]
+		for _, p := range rp.Emitter.Emit() {
+			p.Name = rp.Name
+			if !itr.keepTags {
+				p.Tags = rp.Tags
+			}
+			// Set the points time to the interval time if the reducer didn't provide one.
+			if p.Time == ZeroTime {
+				p.Time = startTime
+			}
+			records = append(records, record{key: k, p: p})
+		}
+	}
+	sort.SliceStable(records, func(i, j int) bool { return records[i].p.Time < records[j].p.Time })
+
+	f, err := newSpillFile(itr.opt.SpillDir)
+	if err != nil {
+		return err
+	}
+	enc := NewIntegerPointEncoder(f)
+	for i := range records {
+		if err := writeSpillKey(f, records[i].key); err != nil {
+			f.Close()
+			return err
+		} else if err := enc.EncodeIntegerPoint(&records[i].p); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	return runs.add(f)
 }
 
 // stringStreamIntegerIterator streams inputs into the iterator and emits points gradually.
@@ -12092,6 +15870,9 @@ type stringReduceUnsignedIterator struct {
 	opt      IteratorOptions
 	points   []UnsignedPoint
 	keepTags bool
+
+	// Runs of the current window if its groups did not fit in memory.
+	runs *unsignedReduceRuns
 }
 
 func newStringReduceUnsignedIterator(input StringIterator, opt IteratorOptions, createFn func() (StringPointAggregator, UnsignedPointEmitter)) *stringReduceUnsignedIterator {
@@ -12107,7 +15888,12 @@ func newStringReduceUnsignedIterator(input StringIterator, opt IteratorOptions,
 func (itr *stringReduceUnsignedIterator) Stats() IteratorStats { return itr.input.Stats() }
 
 // Close closes the iterator and all child iterators.
-func (itr *stringReduceUnsignedIterator) Close() error { return itr.input.Close() }
+func (itr *stringReduceUnsignedIterator) Close() error {
+	if itr.runs != nil {
+		itr.runs.Close()
+	}
+	return itr.input.Close()
+}
 
 // Next returns the minimum value for the next available interval.
 func (itr *stringReduceUnsignedIterator) Next() (*UnsignedPoint, error) {
@@ -12134,9 +15920,35 @@ type stringReduceUnsignedPoint struct {
 	Emitter    UnsignedPointEmitter
 }
 
+// stringReduceUnsignedWindow holds the groups of a window while its
+// points are aggregated.
+type stringReduceUnsignedWindow struct {
+	groups map[string]*stringReduceUnsignedPoint
+	size   int64 // estimated memory used by groups
+
+	// Points of the groups that did not fit within opt.MaxMemory.
+	spill *spillFile
+	enc   *StringPointEncoder
+}
+
+// close removes the spill file of the window.
+func (w *stringReduceUnsignedWindow) close() {
+	if w.spill != nil {
+		w.spill.Close()
+	}
+}
+
 // reduce executes fn once for every point in the next window.
 // The previous value for the dimension is passed to fn.
 func (itr *stringReduceUnsignedIterator) reduce() ([]UnsignedPoint, error) {
+	// Return the remaining points of a window that was spilled to disk.
+	if itr.runs != nil {
+		if a, err := itr.runs.read(reduceSpillBatchSize); err != nil || len(a) > 0 {
+			return a, err
+		}
+		itr.runs = nil
+	}
+
 	// Calculate next window.
 	var (
 		startTime, endTime int64
@@ -12161,11 +15973,12 @@ func (itr *stringReduceUnsignedIterator) reduce() ([]UnsignedPoint, error) {
 	}
 
 	// Create points by tags.
-	m := make(map[string]*stringReduceUnsignedPoint)
+	w := &stringReduceUnsignedWindow{groups: make(map[string]*stringReduceUnsignedPoint)}
 	for {
 		// Read next point.
 		curr, err := itr.input.NextInWindow(startTime, endTime)
 		if err != nil {
+			w.close()
 			return nil, err
 		} else if curr == nil {
 			break
@@ -12185,26 +15998,57 @@ func (itr *stringReduceUnsignedIterator) reduce() ([]UnsignedPoint, error) {
 			break
 		}
 
-		// Retrieve the tags on this point for this level of the query.
-		// This may be different than the bucket dimensions.
-		tags := curr.Tags.Subset(itr.dims)
-		id := tags.ID()
+		if err := itr.aggregate(w, curr); err != nil {
+			w.close()
+			return nil, err
+		}
+	}
 
-		// Retrieve the aggregator for this name/tag combination or create one.
-		rp := m[id]
-		if rp == nil {
-			aggregator, emitter := itr.create()
-			rp = &stringReduceUnsignedPoint{
-				Name:       curr.Name,
-				Tags:       tags,
-				Aggregator: aggregator,
-				Emitter:    emitter,
+	if w.spill != nil {
+		return itr.reduceSpilled(w, startTime)
+	}
+	return itr.emit(w.groups, startTime), nil
+}
+
+// aggregate adds p to the aggregator of its group in w. If p belongs to a new
+// group and the groups of w already use opt.MaxMemory, p is spilled to
+// disk instead.
+func (itr *stringReduceUnsignedIterator) aggregate(w *stringReduceUnsignedWindow, p *StringPoint) error {
+	// Retrieve the tags on this point for this level of the query.
+	// This may be different than the bucket dimensions.
+	tags := p.Tags.Subset(itr.dims)
+	id := tags.ID()
+
+	// Retrieve the aggregator for this name/tag combination or create one.
+	rp := w.groups[id]
+	if rp == nil {
+		if max := itr.opt.MaxMemory; max > 0 && w.size >= max {
+			if w.spill == nil {
+				f, err := newSpillFile(itr.opt.SpillDir)
+				if err != nil {
+					return err
+				}
+				w.spill, w.enc = f, NewStringPointEncoder(f)
 			}
-			m[id] = rp
+			return w.enc.EncodeStringPoint(p)
 		}
-		rp.Aggregator.AggregateString(curr)
+
+		aggregator, emitter := itr.create()
+		rp = &stringReduceUnsignedPoint{
+			Name:       p.Name,
+			Tags:       tags,
+			Aggregator: aggregator,
+			Emitter:    emitter,
+		}
+		w.groups[id] = rp
+		w.size += reduceGroupSize(p.Name, id)
 	}
+	rp.Aggregator.AggregateString(p)
+	return nil
+}
 
+// emit returns the points of every group of a window in reverse order.
+func (itr *stringReduceUnsignedIterator) emit(m map[string]*stringReduceUnsignedPoint, startTime int64) []UnsignedPoint {
 	// Reverse sort points by name & tag if our output is supposed to be ordered.
 	keys := make([]string, 0, len(m))
 	for k := range m {
@@ -12214,34 +16058,142 @@ func (itr *stringReduceUnsignedIterator) reduce() ([]UnsignedPoint, error) {
 		sort.Sort(reverseStringSlice(keys))
 	}
 
-	// Assume the points are already sorted until proven otherwise.
-	sortedByTime := true
-	// Emit the points for each name & tag combination.
-	a := make([]UnsignedPoint, 0, len(m))
+	// Assume the points are already sorted until proven otherwise.
+	sortedByTime := true
+	// Emit the points for each name & tag combination.
+	a := make([]UnsignedPoint, 0, len(m))
+	for _, k := range keys {
+		rp := m[k]
+		points := rp.Emitter.Emit()
+		for i := len(points) - 1; i >= 0; i-- {
+			points[i].Name = rp.Name
+			if !itr.keepTags {
+				points[i].Tags = rp.Tags
+			}
+			// Set the points time to the interval time if the reducer didn't provide one.
+			if points[i].Time == ZeroTime {
+				points[i].Time = startTime
+			} else {
+				sortedByTime = false
+			}
+			a = append(a, points[i])
+		}
+	}
+
+	// Points may be out of order. Perform a stable sort by time if requested.
+	if !sortedByTime && itr.opt.Ordered {
+		sort.Stable(sort.Reverse(unsignedPointsByTime(a)))
+	}
+
+	return a
+}
+
+// reduceSpilled reduces a window whose groups did not fit in memory. The
+// points of the groups in memory are written to a run on disk and the points
+// spilled by w are reduced in the same way, until every group is reduced. The
+// runs are then merged in the order the points would have been emitted.
+func (itr *stringReduceUnsignedIterator) reduceSpilled(w *stringReduceUnsignedWindow, startTime int64) ([]UnsignedPoint, error) {
+	runs := &unsignedReduceRuns{}
+	for {
+		if err := itr.writeRun(runs, w.groups, startTime); err != nil {
+			w.close()
+			runs.Close()
+			return nil, err
+		} else if w.spill == nil {
+			break
+		}
+
+		input := w.spill
+		next, err := itr.reduceSpillFile(input)
+		input.Close()
+		if err != nil {
+			runs.Close()
+			return nil, err
+		}
+		w = next
+	}
+
+	itr.runs = runs
+	return runs.read(reduceSpillBatchSize)
+}
+
+// reduceSpillFile aggregates the points spilled to f into a new window.
+func (itr *stringReduceUnsignedIterator) reduceSpillFile(f *spillFile) (*stringReduceUnsignedWindow, error) {
+	if err := f.rewind(); err != nil {
+		return nil, err
+	}
+	dec := NewStringPointDecoder(context.Background(), f)
+
+	w := &stringReduceUnsignedWindow{groups: make(map[string]*stringReduceUnsignedPoint)}
+	for {
+		select {
+		case <-itr.opt.InterruptCh:
+			w.close()
+			return nil, ErrQueryInterrupted
+		default:
+		}
+
+		var p StringPoint
+		if err := dec.DecodeStringPoint(&p); err == io.EOF {
+			return w, nil
+		} else if err != nil {
+			w.close()
+			return nil, err
+		}
+
+		if err := itr.aggregate(w, &p); err != nil {
+			w.close()
+			return nil, err
+		}
+	}
+}
+
+// writeRun writes the points of the groups in m to a new run of runs. The
+// points are sorted by time and then by group, which is the order they are
+// returned in when the groups all fit in memory.
+func (itr *stringReduceUnsignedIterator) writeRun(runs *unsignedReduceRuns, m map[string]*stringReduceUnsignedPoint, startTime int64) error {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	type record struct {
+		key string
+		p   UnsignedPoint
+	}
+	records := make([]record, 0, len(keys))
 	for _, k := range keys {
 		rp := m[k]
-		points := rp.Emitter.Emit()
-		for i := len(points) - 1; i >= 0; i-- {
-			points[i].Name = rp.Name
+		for _, p := range rp.Emitter.Emit() {
+			p.Name = rp.Name
 			if !itr.keepTags {
-				points[i].Tags = rp.Tags
+				p.Tags = rp.Tags
 			}
 			// Set the points time to the interval time if the reducer didn't provide one.
-			if points[i].Time == ZeroTime {
-				points[i].Time = startTime
-			} else {
-				sortedByTime = false
+			if p.Time == ZeroTime {
+				p.Time = startTime
 			}
-			a = append(a, points[i])
+			records = append(records, record{key: k, p: p})
 		}
 	}
+	sort.SliceStable(records, func(i, j int) bool { return records[i].p.Time < records[j].p.Time })
 
-	// Points may be out of order. Perform a stable sort by time if requested.
-	if !sortedByTime && itr.opt.Ordered {
-		sort.Stable(sort.Reverse(unsignedPointsByTime(a)))
+	f, err := newSpillFile(itr.opt.SpillDir)
+	if err != nil {
+		return err
 	}
-
-	return a, nil
+	enc := NewUnsignedPointEncoder(f)
+	for i := range records {
+		if err := writeSpillKey(f, records[i].key); err != nil {
+			f.Close()
+			return err
+		} else if err := enc.EncodeUnsignedPoint(&records[i].p); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	return runs.add(f)
 }
 
 // stringStreamUnsignedIterator streams inputs into the iterator and emits points gradually.
@@ -12511,6 +16463,9 @@ type stringReduceStringIterator struct {
 	opt      IteratorOptions
 	points   []StringPoint
 	keepTags bool
+
+	// Runs of the current window if its groups did not fit in memory.
+	runs *stringReduceRuns
 }
 
 func newStringReduceStringIterator(input StringIterator, opt IteratorOptions, createFn func() (StringPointAggregator, StringPointEmitter)) *stringReduceStringIterator {
@@ -12526,7 +16481,12 @@ func newStringReduceStringIterator(input StringIterator, opt IteratorOptions, cr
 func (itr *stringReduceStringIterator) Stats() IteratorStats { return itr.input.Stats() }
 
 // Close closes the iterator and all child iterators.
-func (itr *stringReduceStringIterator) Close() error { return itr.input.Close() }
+func (itr *stringReduceStringIterator) Close() error {
+	if itr.runs != nil {
+		itr.runs.Close()
+	}
+	return itr.input.Close()
+}
 
 // Next returns the minimum value for the next available interval.
 func (itr *stringReduceStringIterator) Next() (*StringPoint, error) {
@@ -12553,9 +16513,35 @@ type stringReduceStringPoint struct {
 	Emitter    StringPointEmitter
 }
 
+// stringReduceStringWindow holds the groups of a window while its
+// points are aggregated.
+type stringReduceStringWindow struct {
+	groups map[string]*stringReduceStringPoint
+	size   int64 // estimated memory used by groups
+
+	// Points of the groups that did not fit within opt.MaxMemory.
+	spill *spillFile
+	enc   *StringPointEncoder
+}
+
+// close removes the spill file of the window.
+func (w *stringReduceStringWindow) close() {
+	if w.spill != nil {
+		w.spill.Close()
+	}
+}
+
 // reduce executes fn once for every point in the next window.
 // The previous value for the dimension is passed to fn.
 func (itr *stringReduceStringIterator) reduce() ([]StringPoint, error) {
+	// Return the remaining points of a window that was spilled to disk.
+	if itr.runs != nil {
+		if a, err := itr.runs.read(reduceSpillBatchSize); err != nil || len(a) > 0 {
+			return a, err
+		}
+		itr.runs = nil
+	}
+
 	// Calculate next window.
 	var (
 		startTime, endTime int64
@@ -12580,11 +16566,12 @@ func (itr *stringReduceStringIterator) reduce() ([]StringPoint, error) {
 	}
 
 	// Create points by tags.
-	m := make(map[string]*stringReduceStringPoint)
+	w := &stringReduceStringWindow{groups: make(map[string]*stringReduceStringPoint)}
 	for {
 		// Read next point.
 		curr, err := itr.input.NextInWindow(startTime, endTime)
 		if err != nil {
+			w.close()
 			return nil, err
 		} else if curr == nil {
 			break
@@ -12604,26 +16591,57 @@ func (itr *stringReduceStringIterator) reduce() ([]StringPoint, error) {
 			break
 		}
 
-		// Retrieve the tags on this point for this level of the query.
-		// This may be different than the bucket dimensions.
-		tags := curr.Tags.Subset(itr.dims)
-		id := tags.ID()
+		if err := itr.aggregate(w, curr); err != nil {
+			w.close()
+			return nil, err
+		}
+	}
 
-		// Retrieve the aggregator for this name/tag combination or create one.
-		rp := m[id]
-		if rp == nil {
-			aggregator, emitter := itr.create()
-			rp = &stringReduceStringPoint{
-				Name:       curr.Name,
-				Tags:       tags,
-				Aggregator: aggregator,
-				Emitter:    emitter,
+	if w.spill != nil {
+		return itr.reduceSpilled(w, startTime)
+	}
+	return itr.emit(w.groups, startTime), nil
+}
+
+// aggregate adds p to the aggregator of its group in w. If p belongs to a new
+// group and the groups of w already use opt.MaxMemory, p is spilled to
+// disk instead.
+func (itr *stringReduceStringIterator) aggregate(w *stringReduceStringWindow, p *StringPoint) error {
+	// Retrieve the tags on this point for this level of the query.
+	// This may be different than the bucket dimensions.
+	tags := p.Tags.Subset(itr.dims)
+	id := tags.ID()
+
+	// Retrieve the aggregator for this name/tag combination or create one.
+	rp := w.groups[id]
+	if rp == nil {
+		if max := itr.opt.MaxMemory; max > 0 && w.size >= max {
+			if w.spill == nil {
+				f, err := newSpillFile(itr.opt.SpillDir)
+				if err != nil {
+					return err
+				}
+				w.spill, w.enc = f, NewStringPointEncoder(f)
 			}
-			m[id] = rp
+			return w.enc.EncodeStringPoint(p)
 		}
-		rp.Aggregator.AggregateString(curr)
+
+		aggregator, emitter := itr.create()
+		rp = &stringReduceStringPoint{
+			Name:       p.Name,
+			Tags:       tags,
+			Aggregator: aggregator,
+			Emitter:    emitter,
+		}
+		w.groups[id] = rp
+		w.size += reduceGroupSize(p.Name, id)
 	}
+	rp.Aggregator.AggregateString(p)
+	return nil
+}
 
+// emit returns the points of every group of a window in reverse order.
+func (itr *stringReduceStringIterator) emit(m map[string]*stringReduceStringPoint, startTime int64) []StringPoint {
 	// Reverse sort points by name & tag if our output is supposed to be ordered.
 	keys := make([]string, 0, len(m))
 	for k := range m {
@@ -12660,7 +16678,115 @@ func (itr *stringReduceStringIterator) reduce() ([]StringPoint, error) {
 		sort.Stable(sort.Reverse(stringPointsByTime(a)))
 	}
 
-	return a, nil
+	return a
+}
+
+// reduceSpilled reduces a window whose groups did not fit in memory. The
+// points of the groups in memory are written to a run on disk and the points
+// spilled by w are reduced in the same way, until every group is reduced. The
+// runs are then merged in the order the points would have been emitted.
+func (itr *stringReduceStringIterator) reduceSpilled(w *stringReduceStringWindow, startTime int64) ([]StringPoint, error) {
+	runs := &stringReduceRuns{}
+	for {
+		if err := itr.writeRun(runs, w.groups, startTime); err != nil {
+			w.close()
+			runs.Close()
+			return nil, err
+		} else if w.spill == nil {
+			break
+		}
+
+		input := w.spill
+		next, err := itr.reduceSpillFile(input)
+		input.Close()
+		if err != nil {
+			runs.Close()
+			return nil, err
+		}
+		w = next
+	}
+
+	itr.runs = runs
+	return runs.read(reduceSpillBatchSize)
+}
+
+// reduceSpillFile aggregates the points spilled to f into a new window.
+func (itr *stringReduceStringIterator) reduceSpillFile(f *spillFile) (*stringReduceStringWindow, error) {
+	if err := f.rewind(); err != nil {
+		return nil, err
+	}
+	dec := NewStringPointDecoder(context.Background(), f)
+
+	w := &stringReduceStringWindow{groups: make(map[string]*stringReduceStringPoint)}
+	for {
+		select {
+		case <-itr.opt.InterruptCh:
+			w.close()
+			return nil, ErrQueryInterrupted
+		default:
+		}
+
+		var p StringPoint
+		if err := dec.DecodeStringPoint(&p); err == io.EOF {
+			return w, nil
+		} else if err != nil {
+			w.close()
+			return nil, err
+		}
+
+		if err := itr.aggregate(w, &p); err != nil {
+			w.close()
+			return nil, err
+		}
+	}
+}
+
+// writeRun writes the points of the groups in m to a new run of runs. The
+// points are sorted by time and then by group, which is the order they are
+// returned in when the groups all fit in memory.
+func (itr *stringReduceStringIterator) writeRun(runs *stringReduceRuns, m map[string]*stringReduceStringPoint, startTime int64) error {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	type record struct {
+		key string
+		p   StringPoint
+	}
+	records := make([]record, 0, len(keys))
+	for _, k := range keys {
+		rp := m[k]
+		for _, p := range rp.Emitter.Emit() {
+			p.Name = rp.Name
+			if !itr.keepTags {
+				p.Tags = rp.Tags
+			}
+			// Set the points time to the interval time if the reducer didn't provide one.
+			if p.Time == ZeroTime {
+				p.Time = startTime
+			}
+			records = append(records, record{key: k, p: p})
+		}
+	}
+	sort.SliceStable(records, func(i, j int) bool { return records[i].p.Time < records[j].p.Time })
+
+	f, err := newSpillFile(itr.opt.SpillDir)
+	if err != nil {
+		return err
+	}
+	enc := NewStringPointEncoder(f)
+	for i := range records {
+		if err := writeSpillKey(f, records[i].key); err != nil {
+			f.Close()
+			return err
+		} else if err := enc.EncodeStringPoint(&records[i].p); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	return runs.add(f)
 }
 
 // stringStreamStringIterator streams inputs into the iterator and emits points gradually.
@@ -12926,6 +17052,9 @@ type stringReduceBooleanIterator struct {
 	opt      IteratorOptions
 	points   []BooleanPoint
 	keepTags bool
+
+	// Runs of the current window if its groups did not fit in memory.
+	runs *booleanReduceRuns
 }
 
 func newStringReduceBooleanIterator(input StringIterator, opt IteratorOptions, createFn func() (StringPointAggregator, BooleanPointEmitter)) *stringReduceBooleanIterator {
@@ -12941,7 +17070,12 @@ func newStringReduceBooleanIterator(input StringIterator, opt IteratorOptions, c
 func (itr *stringReduceBooleanIterator) Stats() IteratorStats { return itr.input.Stats() }
 
 // Close closes the iterator and all child iterators.
-func (itr *stringReduceBooleanIterator) Close() error { return itr.input.Close() }
+func (itr *stringReduceBooleanIterator) Close() error {
+	if itr.runs != nil {
+		itr.runs.Close()
+	}
+	return itr.input.Close()
+}
 
 // Next returns the minimum value for the next available interval.
 func (itr *stringReduceBooleanIterator) Next() (*BooleanPoint, error) {
@@ -12968,9 +17102,35 @@ type stringReduceBooleanPoint struct {
 	Emitter    BooleanPointEmitter
 }
 
+// stringReduceBooleanWindow holds the groups of a window while its
+// points are aggregated.
+type stringReduceBooleanWindow struct {
+	groups map[string]*stringReduceBooleanPoint
+	size   int64 // estimated memory used by groups
+
+	// Points of the groups that did not fit within opt.MaxMemory.
+	spill *spillFile
+	enc   *StringPointEncoder
+}
+
+// close removes the spill file of the window.
+func (w *stringReduceBooleanWindow) close() {
+	if w.spill != nil {
+		w.spill.Close()
+	}
+}
+
 // reduce executes fn once for every point in the next window.
 // The previous value for the dimension is passed to fn.
 func (itr *stringReduceBooleanIterator) reduce() ([]BooleanPoint, error) {
+	// Return the remaining points of a window that was spilled to disk.
+	if itr.runs != nil {
+		if a, err := itr.runs.read(reduceSpillBatchSize); err != nil || len(a) > 0 {
+			return a, err
+		}
+		itr.runs = nil
+	}
+
 	// Calculate next window.
 	var (
 		startTime, endTime int64
@@ -12995,11 +17155,12 @@ func (itr *stringReduceBooleanIterator) reduce() ([]BooleanPoint, error) {
 	}
 
 	// Create points by tags.
-	m := make(map[string]*stringReduceBooleanPoint)
+	w := &stringReduceBooleanWindow{groups: make(map[string]*stringReduceBooleanPoint)}
 	for {
 		// Read next point.
 		curr, err := itr.input.NextInWindow(startTime, endTime)
 		if err != nil {
+			w.close()
 			return nil, err
 		} else if curr == nil {
 			break
@@ -13019,26 +17180,57 @@ func (itr *stringReduceBooleanIterator) reduce() ([]BooleanPoint, error) {
 			break
 		}
 
-		// Retrieve the tags on this point for this level of the query.
-		// This may be different than the bucket dimensions.
-		tags := curr.Tags.Subset(itr.dims)
-		id := tags.ID()
+		if err := itr.aggregate(w, curr); err != nil {
+			w.close()
+			return nil, err
+		}
+	}
 
-		// Retrieve the aggregator for this name/tag combination or create one.
-		rp := m[id]
-		if rp == nil {
-			aggregator, emitter := itr.create()
-			rp = &stringReduceBooleanPoint{
-				Name:       curr.Name,
-				Tags:       tags,
-				Aggregator: aggregator,
-				Emitter:    emitter,
+	if w.spill != nil {
+		return itr.reduceSpilled(w, startTime)
+	}
+	return itr.emit(w.groups, startTime), nil
+}
+
+// aggregate adds p to the aggregator of its group in w. If p belongs to a new
+// group and the groups of w already use opt.MaxMemory, p is spilled to
+// disk instead.
+func (itr *stringReduceBooleanIterator) aggregate(w *stringReduceBooleanWindow, p *StringPoint) error {
+	// Retrieve the tags on this point for this level of the query.
+	// This may be different than the bucket dimensions.
+	tags := p.Tags.Subset(itr.dims)
+	id := tags.ID()
+
+	// Retrieve the aggregator for this name/tag combination or create one.
+	rp := w.groups[id]
+	if rp == nil {
+		if max := itr.opt.MaxMemory; max > 0 && w.size >= max {
+			if w.spill == nil {
+				f, err := newSpillFile(itr.opt.SpillDir)
+				if err != nil {
+					return err
+				}
+				w.spill, w.enc = f, NewStringPointEncoder(f)
 			}
-			m[id] = rp
+			return w.enc.EncodeStringPoint(p)
 		}
-		rp.Aggregator.AggregateString(curr)
+
+		aggregator, emitter := itr.create()
+		rp = &stringReduceBooleanPoint{
+			Name:       p.Name,
+			Tags:       tags,
+			Aggregator: aggregator,
+			Emitter:    emitter,
+		}
+		w.groups[id] = rp
+		w.size += reduceGroupSize(p.Name, id)
 	}
+	rp.Aggregator.AggregateString(p)
+	return nil
+}
 
+// emit returns the points of every group of a window in reverse order.
+func (itr *stringReduceBooleanIterator) emit(m map[string]*stringReduceBooleanPoint, startTime int64) []BooleanPoint {
 	// Reverse sort points by name & tag if our output is supposed to be ordered.
 	keys := make([]string, 0, len(m))
 	for k := range m {
@@ -13075,7 +17267,115 @@ func (itr *stringReduceBooleanIterator) reduce() ([]BooleanPoint, error) {
 		sort.Stable(sort.Reverse(booleanPointsByTime(a)))
 	}
 
-	return a, nil
+	return a
+}
+
+// reduceSpilled reduces a window whose groups did not fit in memory. The
+// points of the groups in memory are written to a run on disk and the points
+// spilled by w are reduced in the same way, until every group is reduced. The
+// runs are then merged in the order the points would have been emitted.
+func (itr *stringReduceBooleanIterator) reduceSpilled(w *stringReduceBooleanWindow, startTime int64) ([]BooleanPoint, error) {
+	runs := &booleanReduceRuns{}
+	for {
+		if err := itr.writeRun(runs, w.groups, startTime); err != nil {
+			w.close()
+			runs.Close()
+			return nil, err
+		} else if w.spill == nil {
+			break
+		}
+
+		input := w.spill
+		next, err := itr.reduceSpillFile(input)
+		input.Close()
+		if err != nil {
+			runs.Close()
+			return nil, err
+		}
+		w = next
+	}
+
+	itr.runs = runs
+	return runs.read(reduceSpillBatchSize)
+}
+
+// reduceSpillFile aggregates the points spilled to f into a new window.
+func (itr *stringReduceBooleanIterator) reduceSpillFile(f *spillFile) (*stringReduceBooleanWindow, error) {
+	if err := f.rewind(); err != nil {
+		return nil, err
+	}
+	dec := NewStringPointDecoder(context.Background(), f)
+
+	w := &stringReduceBooleanWindow{groups: make(map[string]*stringReduceBooleanPoint)}
+	for {
+		select {
+		case <-itr.opt.InterruptCh:
+			w.close()
+			return nil, ErrQueryInterrupted
+		default:
+		}
+
+		var p StringPoint
+		if err := dec.DecodeStringPoint(&p); err == io.EOF {
+			return w, nil
+		} else if err != nil {
+			w.close()
+			return nil, err
+		}
+
+		if err := itr.aggregate(w, &p); err != nil {
+			w.close()
+			return nil, err
+		}
+	}
+}
+
+// writeRun writes the points of the groups in m to a new run of runs. The
+// points are sorted by time and then by group, which is the order they are
+// returned in when the groups all fit in memory.
+func (itr *stringReduceBooleanIterator) writeRun(runs *booleanReduceRuns, m map[string]*stringReduceBooleanPoint, startTime int64) error {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	type record struct {
+		key string
+		p   BooleanPoint
+	}
+	records := make([]record, 0, len(keys))
+	for _, k := range keys {
+		rp := m[k]
+		for _, p := range rp.Emitter.Emit() {
+			p.Name = rp.Name
+			if !itr.keepTags {
+				p.Tags = rp.Tags
+			}
+			// Set the points time to the interval time if the reducer didn't provide one.
+			if p.Time == ZeroTime {
+				p.Time = startTime
+			}
+			records = append(records, record{key: k, p: p})
+		}
+	}
+	sort.SliceStable(records, func(i, j int) bool { return records[i].p.Time < records[j].p.Time })
+
+	f, err := newSpillFile(itr.opt.SpillDir)
+	if err != nil {
+		return err
+	}
+	enc := NewBooleanPointEncoder(f)
+	for i := range records {
+		if err := writeSpillKey(f, records[i].key); err != nil {
+			f.Close()
+			return err
+		} else if err := enc.EncodeBooleanPoint(&records[i].p); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	return runs.add(f)
 }
 
 // stringStreamBooleanIterator streams inputs into the iterator and emits points gradually.
@@ -13938,6 +18238,10 @@ func (itr *booleanSortedMergeIterator) Next() (*BooleanPoint, error) { return it
 func (itr *booleanSortedMergeIterator) pop() (*BooleanPoint, error) {
 	// Initialize the heap. See the MergeIterator to see why this has to be done lazily.
 	if !itr.init {
+		if err := itr.spill(); err != nil {
+			return nil, err
+		}
+
 		items := itr.heap.items
 		itr.heap.items = make([]*booleanSortedMergeHeapItem, 0, len(items))
 		for _, item := range items {
@@ -13965,15 +18269,122 @@ func (itr *booleanSortedMergeIterator) pop() (*BooleanPoint, error) {
 		return nil, nil
 	}
 
-	// Copy the point for return.
-	p := item.point.Clone()
-
-	// Read the next item from the cursor. Push back to heap if one exists.
-	if item.point, item.err = item.itr.Next(); item.point != nil {
-		heap.Push(itr.heap, item)
+	// Copy the point for return.
+	p := item.point.Clone()
+
+	// Read the next item from the cursor. Push back to heap if one exists.
+	if item.point, item.err = item.itr.Next(); item.point != nil {
+		heap.Push(itr.heap, item)
+	}
+
+	return p, nil
+}
+
+// spill merges the inputs a batch at a time into runs on disk while there are
+// more inputs than can be merged within opt.MaxMemory. The runs then replace
+// the inputs.
+func (itr *booleanSortedMergeIterator) spill() error {
+	n := sortedMergeMaxInputs(itr.heap.opt.MaxMemory)
+	if n == 0 || len(itr.inputs) <= n {
+		return nil
+	}
+
+	for len(itr.inputs) > n {
+		inputs := itr.inputs
+		runs := make([]BooleanIterator, 0, (len(inputs)+n-1)/n)
+		for len(inputs) > 0 {
+			batch := inputs
+			if len(batch) > n {
+				batch = batch[:n]
+			}
+			inputs = inputs[len(batch):]
+
+			run, err := spillBooleanSortedMerge(batch, itr.heap.opt)
+			if err != nil {
+				// Keep the runs and remaining inputs so they are closed.
+				itr.inputs = append(runs, inputs...)
+				itr.heap.items = nil
+				return err
+			}
+			runs = append(runs, run)
+		}
+		itr.inputs = runs
+	}
+
+	itr.heap.items = make([]*booleanSortedMergeHeapItem, 0, len(itr.inputs))
+	for _, input := range itr.inputs {
+		itr.heap.items = append(itr.heap.items, &booleanSortedMergeHeapItem{itr: input})
+	}
+	return nil
+}
+
+// spillBooleanSortedMerge writes the sorted merge of inputs to a spill file
+// and returns an iterator that reads it back. The inputs are closed.
+func spillBooleanSortedMerge(inputs []BooleanIterator, opt IteratorOptions) (BooleanIterator, error) {
+	itr := newBooleanSortedMergeIterator(inputs, opt).(*booleanSortedMergeIterator)
+	defer itr.Close()
+
+	f, err := newSpillFile(opt.SpillDir)
+	if err != nil {
+		return nil, err
+	}
+
+	enc := NewBooleanPointEncoder(f)
+	for {
+		select {
+		case <-opt.InterruptCh:
+			f.Close()
+			return nil, ErrQueryInterrupted
+		default:
+		}
+
+		p, err := itr.pop()
+		if err != nil {
+			f.Close()
+			return nil, err
+		} else if p == nil {
+			break
+		}
+
+		if err := enc.EncodeBooleanPoint(p); err != nil {
+			f.Close()
+			return nil, err
+		}
+	}
+
+	if err := f.rewind(); err != nil {
+		f.Close()
+		return nil, err
 	}
+	return &booleanSpillIterator{
+		f:     f,
+		dec:   NewBooleanPointDecoder(context.Background(), f),
+		stats: itr.Stats(),
+	}, nil
+}
 
-	return p, nil
+// booleanSpillIterator reads the points written to a spill file.
+type booleanSpillIterator struct {
+	f     *spillFile
+	dec   *BooleanPointDecoder
+	point BooleanPoint
+	stats IteratorStats // stats of the iterators that were spilled
+}
+
+// Stats returns the stats of the iterators that were spilled.
+func (itr *booleanSpillIterator) Stats() IteratorStats { return itr.stats }
+
+// Close closes and removes the spill file.
+func (itr *booleanSpillIterator) Close() error { return itr.f.Close() }
+
+// Next returns the next point from the spill file.
+func (itr *booleanSpillIterator) Next() (*BooleanPoint, error) {
+	if err := itr.dec.DecodeBooleanPoint(&itr.point); err == io.EOF {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return &itr.point, nil
 }
 
 // booleanSortedMergeHeap represents a heap of booleanSortedMergeHeapItems.
@@ -14632,6 +19043,100 @@ func (itr *booleanChanIterator) Next() (*BooleanPoint, error) {
 	return p, nil
 }
 
+// booleanReduceRun is a run of reduced points of a window spilled to disk.
+// The points are sorted in the order they are returned by a reduce iterator.
+type booleanReduceRun struct {
+	f   *spillFile
+	dec *BooleanPointDecoder
+	key string // group of p
+	p   BooleanPoint
+}
+
+// next reads the next point of the run. It returns io.EOF at the end of the run.
+func (r *booleanReduceRun) next() error {
+	key, err := readSpillKey(r.f)
+	if err != nil {
+		return err
+	}
+	r.key = key
+	return r.dec.DecodeBooleanPoint(&r.p)
+}
+
+// booleanReduceRuns merges the runs of a window whose groups did not fit
+// in memory.
+type booleanReduceRuns []*booleanReduceRun
+
+func (a booleanReduceRuns) Len() int      { return len(a) }
+func (a booleanReduceRuns) Swap(i, j int) { a[i], a[j] = a[j], a[i] }
+func (a booleanReduceRuns) Less(i, j int) bool {
+	if a[i].p.Time != a[j].p.Time {
+		return a[i].p.Time < a[j].p.Time
+	}
+	return a[i].key < a[j].key
+}
+
+func (a *booleanReduceRuns) Push(x interface{}) {
+	*a = append(*a, x.(*booleanReduceRun))
+}
+
+func (a *booleanReduceRuns) Pop() interface{} {
+	old := *a
+	n := len(old)
+	r := old[n-1]
+	*a = old[:n-1]
+	return r
+}
+
+// add adds the run written to f. The runs take ownership of f.
+func (a *booleanReduceRuns) add(f *spillFile) error {
+	if err := f.rewind(); err != nil {
+		f.Close()
+		return err
+	}
+
+	r := &booleanReduceRun{f: f, dec: NewBooleanPointDecoder(context.Background(), f)}
+	if err := r.next(); err == io.EOF {
+		return f.Close()
+	} else if err != nil {
+		f.Close()
+		return err
+	}
+	heap.Push(a, r)
+	return nil
+}
+
+// read returns up to n of the next points of the runs. The points are in
+// reverse order so they can be popped off the end of the slice.
+func (a *booleanReduceRuns) read(n int) ([]BooleanPoint, error) {
+	points := make([]BooleanPoint, 0, n)
+	for len(points) < n && len(*a) > 0 {
+		r := (*a)[0]
+		points = append(points, r.p)
+		if err := r.next(); err == io.EOF {
+			heap.Pop(a)
+			r.f.Close()
+		} else if err != nil {
+			return nil, err
+		} else {
+			heap.Fix(a, 0)
+		}
+	}
+
+	for i, j := 0, len(points)-1; i < j; i, j = i+1, j-1 {
+		points[i], points[j] = points[j], points[i]
+	}
+	return points, nil
+}
+
+// Close removes the files of the remaining runs.
+func (a *booleanReduceRuns) Close() error {
+	for _, r := range *a {
+		r.f.Close()
+	}
+	*a = nil
+	return nil
+}
+
 // booleanReduceFloatIterator executes a reducer for every interval and buffers the result.
 type booleanReduceFloatIterator struct {
 	input    *bufBooleanIterator
@@ -14640,6 +19145,9 @@ type booleanReduceFloatIterator struct {
 	opt      IteratorOptions
 	points   []FloatPoint
 	keepTags bool
+
+	// Runs of the current window if its groups did not fit in memory.
+	runs *floatReduceRuns
 }
 
 func newBooleanReduceFloatIterator(input BooleanIterator, opt IteratorOptions, createFn func() (BooleanPointAggregator, FloatPointEmitter)) *booleanReduceFloatIterator {
@@ -14655,7 +19163,12 @@ func newBooleanReduceFloatIterator(input BooleanIterator, opt IteratorOptions, c
 func (itr *booleanReduceFloatIterator) Stats() IteratorStats { return itr.input.Stats() }
 
 // Close closes the iterator and all child iterators.
-func (itr *booleanReduceFloatIterator) Close() error { return itr.input.Close() }
+func (itr *booleanReduceFloatIterator) Close() error {
+	if itr.runs != nil {
+		itr.runs.Close()
+	}
+	return itr.input.Close()
+}
 
 // Next returns the minimum value for the next available interval.
 func (itr *booleanReduceFloatIterator) Next() (*FloatPoint, error) {
@@ -14682,9 +19195,35 @@ type booleanReduceFloatPoint struct {
 	Emitter    FloatPointEmitter
 }
 
+// booleanReduceFloatWindow holds the groups of a window while its
+// points are aggregated.
+type booleanReduceFloatWindow struct {
+	groups map[string]*booleanReduceFloatPoint
+	size   int64 // estimated memory used by groups
+
+	// Points of the groups that did not fit within opt.MaxMemory.
+	spill *spillFile
+	enc   *BooleanPointEncoder
+}
+
+// close removes the spill file of the window.
+func (w *booleanReduceFloatWindow) close() {
+	if w.spill != nil {
+		w.spill.Close()
+	}
+}
+
 // reduce executes fn once for every point in the next window.
 // The previous value for the dimension is passed to fn.
 func (itr *booleanReduceFloatIterator) reduce() ([]FloatPoint, error) {
+	// Return the remaining points of a window that was spilled to disk.
+	if itr.runs != nil {
+		if a, err := itr.runs.read(reduceSpillBatchSize); err != nil || len(a) > 0 {
+			return a, err
+		}
+		itr.runs = nil
+	}
+
 	// Calculate next window.
 	var (
 		startTime, endTime int64
@@ -14709,11 +19248,12 @@ func (itr *booleanReduceFloatIterator) reduce() ([]FloatPoint, error) {
 	}
 
 	// Create points by tags.
-	m := make(map[string]*booleanReduceFloatPoint)
+	w := &booleanReduceFloatWindow{groups: make(map[string]*booleanReduceFloatPoint)}
 	for {
 		// Read next point.
 		curr, err := itr.input.NextInWindow(startTime, endTime)
 		if err != nil {
+			w.close()
 			return nil, err
 		} else if curr == nil {
 			break
@@ -14733,26 +19273,57 @@ func (itr *booleanReduceFloatIterator) reduce() ([]FloatPoint, error) {
 			break
 		}
 
-		// Retrieve the tags on this point for this level of the query.
-		// This may be different than the bucket dimensions.
-		tags := curr.Tags.Subset(itr.dims)
-		id := tags.ID()
+		if err := itr.aggregate(w, curr); err != nil {
+			w.close()
+			return nil, err
+		}
+	}
 
-		// Retrieve the aggregator for this name/tag combination or create one.
-		rp := m[id]
-		if rp == nil {
-			aggregator, emitter := itr.create()
-			rp = &booleanReduceFloatPoint{
-				Name:       curr.Name,
-				Tags:       tags,
-				Aggregator: aggregator,
-				Emitter:    emitter,
+	if w.spill != nil {
+		return itr.reduceSpilled(w, startTime)
+	}
+	return itr.emit(w.groups, startTime), nil
+}
+
+// aggregate adds p to the aggregator of its group in w. If p belongs to a new
+// group and the groups of w already use opt.MaxMemory, p is spilled to
+// disk instead.
+func (itr *booleanReduceFloatIterator) aggregate(w *booleanReduceFloatWindow, p *BooleanPoint) error {
+	// Retrieve the tags on this point for this level of the query.
+	// This may be different than the bucket dimensions.
+	tags := p.Tags.Subset(itr.dims)
+	id := tags.ID()
+
+	// Retrieve the aggregator for this name/tag combination or create one.
+	rp := w.groups[id]
+	if rp == nil {
+		if max := itr.opt.MaxMemory; max > 0 && w.size >= max {
+			if w.spill == nil {
+				f, err := newSpillFile(itr.opt.SpillDir)
+				if err != nil {
+					return err
+				}
+				w.spill, w.enc = f, NewBooleanPointEncoder(f)
 			}
-			m[id] = rp
+			return w.enc.EncodeBooleanPoint(p)
 		}
-		rp.Aggregator.AggregateBoolean(curr)
+
+		aggregator, emitter := itr.create()
+		rp = &booleanReduceFloatPoint{
+			Name:       p.Name,
+			Tags:       tags,
+			Aggregator: aggregator,
+			Emitter:    emitter,
+		}
+		w.groups[id] = rp
+		w.size += reduceGroupSize(p.Name, id)
 	}
+	rp.Aggregator.AggregateBoolean(p)
+	return nil
+}
 
+// emit returns the points of every group of a window in reverse order.
+func (itr *booleanReduceFloatIterator) emit(m map[string]*booleanReduceFloatPoint, startTime int64) []FloatPoint {
 	// Reverse sort points by name & tag if our output is supposed to be ordered.
 	keys := make([]string, 0, len(m))
 	for k := range m {
@@ -14789,7 +19360,115 @@ func (itr *booleanReduceFloatIterator) reduce() ([]FloatPoint, error) {
 		sort.Stable(sort.Reverse(floatPointsByTime(a)))
 	}
 
-	return a, nil
+	return a
+}
+
+// reduceSpilled reduces a window whose groups did not fit in memory. The
+// points of the groups in memory are written to a run on disk and the points
+// spilled by w are reduced in the same way, until every group is reduced. The
+// runs are then merged in the order the points would have been emitted.
+func (itr *booleanReduceFloatIterator) reduceSpilled(w *booleanReduceFloatWindow, startTime int64) ([]FloatPoint, error) {
+	runs := &floatReduceRuns{}
+	for {
+		if err := itr.writeRun(runs, w.groups, startTime); err != nil {
+			w.close()
+			runs.Close()
+			return nil, err
+		} else if w.spill == nil {
+			break
+		}
+
+		input := w.spill
+		next, err := itr.reduceSpillFile(input)
+		input.Close()
+		if err != nil {
+			runs.Close()
+			return nil, err
+		}
+		w = next
+	}
+
+	itr.runs = runs
+	return runs.read(reduceSpillBatchSize)
+}
+
+// reduceSpillFile aggregates the points spilled to f into a new window.
+func (itr *booleanReduceFloatIterator) reduceSpillFile(f *spillFile) (*booleanReduceFloatWindow, error) {
+	if err := f.rewind(); err != nil {
+		return nil, err
+	}
+	dec := NewBooleanPointDecoder(context.Background(), f)
+
+	w := &booleanReduceFloatWindow{groups: make(map[string]*booleanReduceFloatPoint)}
+	for {
+		select {
+		case <-itr.opt.InterruptCh:
+			w.close()
+			return nil, ErrQueryInterrupted
+		default:
+		}
+
+		var p BooleanPoint
+		if err := dec.DecodeBooleanPoint(&p); err == io.EOF {
+			return w, nil
+		} else if err != nil {
+			w.close()
+			return nil, err
+		}
+
+		if err := itr.aggregate(w, &p); err != nil {
+			w.close()
+			return nil, err
+		}
+	}
+}
+
+// writeRun writes the points of the groups in m to a new run of runs. The
+// points are sorted by time and then by group, which is the order they are
+// returned in when the groups all fit in memory.
+func (itr *booleanReduceFloatIterator) writeRun(runs *floatReduceRuns, m map[string]*booleanReduceFloatPoint, startTime int64) error {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	type record struct {
+		key string
+		p   FloatPoint
+	}
+	records := make([]record, 0, len(keys))
+	for _, k := range keys {
+		rp := m[k]
+		for _, p := range rp.Emitter.Emit() {
+			p.Name = rp.Name
+			if !itr.keepTags {
+				p.Tags = rp.Tags
+			}
+			// Set the points time to the interval time if the reducer didn't provide one.
+			if p.Time == ZeroTime {
+				p.Time = startTime
+			}
+			records = append(records, record{key: k, p: p})
+		}
+	}
+	sort.SliceStable(records, func(i, j int) bool { return records[i].p.Time < records[j].p.Time })
+
+	f, err := newSpillFile(itr.opt.SpillDir)
+	if err != nil {
+		return err
+	}
+	enc := NewFloatPointEncoder(f)
+	for i := range records {
+		if err := writeSpillKey(f, records[i].key); err != nil {
+			f.Close()
+			return err
+		} else if err := enc.EncodeFloatPoint(&records[i].p); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	return runs.add(f)
 }
 
 // booleanStreamFloatIterator streams inputs into the iterator and emits points gradually.
@@ -15059,6 +19738,9 @@ type booleanReduceIntegerIterator struct {
 	opt      IteratorOptions
 	points   []IntegerPoint
 	keepTags bool
+
+	// Runs of the current window if its groups did not fit in memory.
+	runs *integerReduceRuns
 }
 
 func newBooleanReduceIntegerIterator(input BooleanIterator, opt IteratorOptions, createFn func() (BooleanPointAggregator, IntegerPointEmitter)) *booleanReduceIntegerIterator {
@@ -15074,7 +19756,12 @@ func newBooleanReduceIntegerIterator(input BooleanIterator, opt IteratorOptions,
 func (itr *booleanReduceIntegerIterator) Stats() IteratorStats { return itr.input.Stats() }
 
 // Close closes the iterator and all child iterators.
-func (itr *booleanReduceIntegerIterator) Close() error { return itr.input.Close() }
+func (itr *booleanReduceIntegerIterator) Close() error {
+	if itr.runs != nil {
+		itr.runs.Close()
+	}
+	return itr.input.Close()
+}
 
 // Next returns the minimum value for the next available interval.
 func (itr *booleanReduceIntegerIterator) Next() (*IntegerPoint, error) {
@@ -15101,9 +19788,35 @@ type booleanReduceIntegerPoint struct {
 	Emitter    IntegerPointEmitter
 }
 
+// booleanReduceIntegerWindow holds the groups of a window while its
+// points are aggregated.
+type booleanReduceIntegerWindow struct {
+	groups map[string]*booleanReduceIntegerPoint
+	size   int64 // estimated memory used by groups
+
+	// Points of the groups that did not fit within opt.MaxMemory.
+	spill *spillFile
+	enc   *BooleanPointEncoder
+}
+
+// close removes the spill file of the window.
+func (w *booleanReduceIntegerWindow) close() {
+	if w.spill != nil {
+		w.spill.Close()
+	}
+}
+
 // reduce executes fn once for every point in the next window.
 // The previous value for the dimension is passed to fn.
 func (itr *booleanReduceIntegerIterator) reduce() ([]IntegerPoint, error) {
+	// Return the remaining points of a window that was spilled to disk.
+	if itr.runs != nil {
+		if a, err := itr.runs.read(reduceSpillBatchSize); err != nil || len(a) > 0 {
+			return a, err
+		}
+		itr.runs = nil
+	}
+
 	// Calculate next window.
 	var (
 		startTime, endTime int64
@@ -15128,11 +19841,12 @@ func (itr *booleanReduceIntegerIterator) reduce() ([]IntegerPoint, error) {
 	}
 
 	// Create points by tags.
-	m := make(map[string]*booleanReduceIntegerPoint)
+	w := &booleanReduceIntegerWindow{groups: make(map[string]*booleanReduceIntegerPoint)}
 	for {
 		// Read next point.
 		curr, err := itr.input.NextInWindow(startTime, endTime)
 		if err != nil {
+			w.close()
 			return nil, err
 		} else if curr == nil {
 			break
@@ -15152,26 +19866,57 @@ func (itr *booleanReduceIntegerIterator) reduce() ([]IntegerPoint, error) {
 			break
 		}
 
-		// Retrieve the tags on this point for this level of the query.
-		// This may be different than the bucket dimensions.
-		tags := curr.Tags.Subset(itr.dims)
-		id := tags.ID()
+		if err := itr.aggregate(w, curr); err != nil {
+			w.close()
+			return nil, err
+		}
+	}
 
-		// Retrieve the aggregator for this name/tag combination or create one.
-		rp := m[id]
-		if rp == nil {
-			aggregator, emitter := itr.create()
-			rp = &booleanReduceIntegerPoint{
-				Name:       curr.Name,
-				Tags:       tags,
-				Aggregator: aggregator,
-				Emitter:    emitter,
+	if w.spill != nil {
+		return itr.reduceSpilled(w, startTime)
+	}
+	return itr.emit(w.groups, startTime), nil
+}
+
+// aggregate adds p to the aggregator of its group in w. If p belongs to a new
+// group and the groups of w already use opt.MaxMemory, p is spilled to
+// disk instead.
+func (itr *booleanReduceIntegerIterator) aggregate(w *booleanReduceIntegerWindow, p *BooleanPoint) error {
+	// Retrieve the tags on this point for this level of the query.
+	// This may be different than the bucket dimensions.
+	tags := p.Tags.Subset(itr.dims)
+	id := tags.ID()
+
+	// Retrieve the aggregator for this name/tag combination or create one.
+	rp := w.groups[id]
+	if rp == nil {
+		if max := itr.opt.MaxMemory; max > 0 && w.size >= max {
+			if w.spill == nil {
+				f, err := newSpillFile(itr.opt.SpillDir)
+				if err != nil {
+					return err
+				}
+				w.spill, w.enc = f, NewBooleanPointEncoder(f)
 			}
-			m[id] = rp
+			return w.enc.EncodeBooleanPoint(p)
 		}
-		rp.Aggregator.AggregateBoolean(curr)
+
+		aggregator, emitter := itr.create()
+		rp = &booleanReduceIntegerPoint{
+			Name:       p.Name,
+			Tags:       tags,
+			Aggregator: aggregator,
+			Emitter:    emitter,
+		}
+		w.groups[id] = rp
+		w.size += reduceGroupSize(p.Name, id)
 	}
+	rp.Aggregator.AggregateBoolean(p)
+	return nil
+}
 
+// emit returns the points of every group of a window in reverse order.
+func (itr *booleanReduceIntegerIterator) emit(m map[string]*booleanReduceIntegerPoint, startTime int64) []IntegerPoint {
 	// Reverse sort points by name & tag if our output is supposed to be ordered.
 	keys := make([]string, 0, len(m))
 	for k := range m {
@@ -15208,7 +19953,115 @@ func (itr *booleanReduceIntegerIterator) reduce() ([]IntegerPoint, error) {
 		sort.Stable(sort.Reverse(integerPointsByTime(a)))
 	}
 
-	return a, nil
+	return a
+}
+
+// reduceSpilled reduces a window whose groups did not fit in memory. The
+// points of the groups in memory are written to a run on disk and the points
+// spilled by w are reduced in the same way, until every group is reduced. The
+// runs are then merged in the order the points would have been emitted.
+func (itr *booleanReduceIntegerIterator) reduceSpilled(w *booleanReduceIntegerWindow, startTime int64) ([]IntegerPoint, error) {
+	runs := &integerReduceRuns{}
+	for {
+		if err := itr.writeRun(runs, w.groups, startTime); err != nil {
+			w.close()
+			runs.Close()
+			return nil, err
+		} else if w.spill == nil {
+			break
+		}
+
+		input := w.spill
+		next, err := itr.reduceSpillFile(input)
+		input.Close()
+		if err != nil {
+			runs.Close()
+			return nil, err
+		}
+		w = next
+	}
+
+	itr.runs = runs
+	return runs.read(reduceSpillBatchSize)
+}
+
+// reduceSpillFile aggregates the points spilled to f into a new window.
+func (itr *booleanReduceIntegerIterator) reduceSpillFile(f *spillFile) (*booleanReduceIntegerWindow, error) {
+	if err := f.rewind(); err != nil {
+		return nil, err
+	}
+	dec := NewBooleanPointDecoder(context.Background(), f)
+
+	w := &booleanReduceIntegerWindow{groups: make(map[string]*booleanReduceIntegerPoint)}
+	for {
+		select {
+		case <-itr.opt.InterruptCh:
+			w.close()
+			return nil, ErrQueryInterrupted
+		default:
+		}
+
+		var p BooleanPoint
+		if err := dec.DecodeBooleanPoint(&p); err == io.EOF {
+			return w, nil
+		} else if err != nil {
+			w.close()
+			return nil, err
+		}
+
+		if err := itr.aggregate(w, &p); err != nil {
+			w.close()
+			return nil, err
+		}
+	}
+}
+
+// writeRun writes the points of the groups in m to a new run of runs. The
+// points are sorted by time and then by group, which is the order they are
+// returned in when the groups all fit in memory.
+func (itr *booleanReduceIntegerIterator) writeRun(runs *integerReduceRuns, m map[string]*booleanReduceIntegerPoint, startTime int64) error {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	type record struct {
+		key string
+		p   IntegerPoint
+	}
+	records := make([]record, 0, len(keys))
+	for _, k := range keys {
+		rp := m[k]
+		for _, p := range rp.Emitter.Emit() {
+			p.Name = rp.Name
+			if !itr.keepTags {
+				p.Tags = rp.Tags
+			}
+			// Set the points time to the interval time if the reducer didn't provide one.
+			if p.Time == ZeroTime {
+				p.Time = startTime
+			}
+			records = append(records, record{key: k, p: p})
+		}
+	}
+	sort.SliceStable(records, func(i, j int) bool { return records[i].p.Time < records[j].p.Time })
+
+	f, err := newSpillFile(itr.opt.SpillDir)
+	if err != nil {
+		return err
+	}
+	enc := NewIntegerPointEncoder(f)
+	for i := range records {
+		if err := writeSpillKey(f, records[i].key); err != nil {
+			f.Close()
+			return err
+		} else if err := enc.EncodeIntegerPoint(&records[i].p); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	return runs.add(f)
 }
 
 // booleanStreamIntegerIterator streams inputs into the iterator and emits points gradually.
@@ -15478,6 +20331,9 @@ type booleanReduceUnsignedIterator struct {
 	opt      IteratorOptions
 	points   []UnsignedPoint
 	keepTags bool
+
+	// Runs of the current window if its groups did not fit in memory.
+	runs *unsignedReduceRuns
 }
 
 func newBooleanReduceUnsignedIterator(input BooleanIterator, opt IteratorOptions, createFn func() (BooleanPointAggregator, UnsignedPointEmitter)) *booleanReduceUnsignedIterator {
@@ -15493,7 +20349,12 @@ func newBooleanReduceUnsignedIterator(input BooleanIterator, opt IteratorOptions
 func (itr *booleanReduceUnsignedIterator) Stats() IteratorStats { return itr.input.Stats() }
 
 // Close closes the iterator and all child iterators.
-func (itr *booleanReduceUnsignedIterator) Close() error { return itr.input.Close() }
+func (itr *booleanReduceUnsignedIterator) Close() error {
+	if itr.runs != nil {
+		itr.runs.Close()
+	}
+	return itr.input.Close()
+}
 
 // Next returns the minimum value for the next available interval.
 func (itr *booleanReduceUnsignedIterator) Next() (*UnsignedPoint, error) {
@@ -15520,9 +20381,35 @@ type booleanReduceUnsignedPoint struct {
 	Emitter    UnsignedPointEmitter
 }
 
+// booleanReduceUnsignedWindow holds the groups of a window while its
+// points are aggregated.
+type booleanReduceUnsignedWindow struct {
+	groups map[string]*booleanReduceUnsignedPoint
+	size   int64 // estimated memory used by groups
+
+	// Points of the groups that did not fit within opt.MaxMemory.
+	spill *spillFile
+	enc   *BooleanPointEncoder
+}
+
+// close removes the spill file of the window.
+func (w *booleanReduceUnsignedWindow) close() {
+	if w.spill != nil {
+		w.spill.Close()
+	}
+}
+
 // reduce executes fn once for every point in the next window.
 // The previous value for the dimension is passed to fn.
 func (itr *booleanReduceUnsignedIterator) reduce() ([]UnsignedPoint, error) {
+	// Return the remaining points of a window that was spilled to disk.
+	if itr.runs != nil {
+		if a, err := itr.runs.read(reduceSpillBatchSize); err != nil || len(a) > 0 {
+			return a, err
+		}
+		itr.runs = nil
+	}
+
 	// Calculate next window.
 	var (
 		startTime, endTime int64
@@ -15547,11 +20434,12 @@ func (itr *booleanReduceUnsignedIterator) reduce() ([]UnsignedPoint, error) {
 	}
 
 	// Create points by tags.
-	m := make(map[string]*booleanReduceUnsignedPoint)
+	w := &booleanReduceUnsignedWindow{groups: make(map[string]*booleanReduceUnsignedPoint)}
 	for {
 		// Read next point.
 		curr, err := itr.input.NextInWindow(startTime, endTime)
 		if err != nil {
+			w.close()
 			return nil, err
 		} else if curr == nil {
 			break
@@ -15571,26 +20459,57 @@ func (itr *booleanReduceUnsignedIterator) reduce() ([]UnsignedPoint, error) {
 			break
 		}
 
-		// Retrieve the tags on this point for this level of the query.
-		// This may be different than the bucket dimensions.
-		tags := curr.Tags.Subset(itr.dims)
-		id := tags.ID()
+		if err := itr.aggregate(w, curr); err != nil {
+			w.close()
+			return nil, err
+		}
+	}
+
+	if w.spill != nil {
+		return itr.reduceSpilled(w, startTime)
+	}
+	return itr.emit(w.groups, startTime), nil
+}
 
-		// Retrieve the aggregator for this name/tag combination or create one.
-		rp := m[id]
-		if rp == nil {
-			aggregator, emitter := itr.create()
-			rp = &booleanReduceUnsignedPoint{
-				Name:       curr.Name,
-				Tags:       tags,
-				Aggregator: aggregator,
-				Emitter:    emitter,
+// aggregate adds p to the aggregator of its group in w. If p belongs to a new
+// group and the groups of w already use opt.MaxMemory, p is spilled to
+// disk instead.
+func (itr *booleanReduceUnsignedIterator) aggregate(w *booleanReduceUnsignedWindow, p *BooleanPoint) error {
+	// Retrieve the tags on this point for this level of the query.
+	// This may be different than the bucket dimensions.
+	tags := p.Tags.Subset(itr.dims)
+	id := tags.ID()
+
+	// Retrieve the aggregator for this name/tag combination or create one.
+	rp := w.groups[id]
+	if rp == nil {
+		if max := itr.opt.MaxMemory; max > 0 && w.size >= max {
+			if w.spill == nil {
+				f, err := newSpillFile(itr.opt.SpillDir)
+				if err != nil {
+					return err
+				}
+				w.spill, w.enc = f, NewBooleanPointEncoder(f)
 			}
-			m[id] = rp
+			return w.enc.EncodeBooleanPoint(p)
 		}
-		rp.Aggregator.AggregateBoolean(curr)
+
+		aggregator, emitter := itr.create()
+		rp = &booleanReduceUnsignedPoint{
+			Name:       p.Name,
+			Tags:       tags,
+			Aggregator: aggregator,
+			Emitter:    emitter,
+		}
+		w.groups[id] = rp
+		w.size += reduceGroupSize(p.Name, id)
 	}
+	rp.Aggregator.AggregateBoolean(p)
+	return nil
+}
 
+// emit returns the points of every group of a window in reverse order.
+func (itr *booleanReduceUnsignedIterator) emit(m map[string]*booleanReduceUnsignedPoint, startTime int64) []UnsignedPoint {
 	// Reverse sort points by name & tag if our output is supposed to be ordered.
 	keys := make([]string, 0, len(m))
 	for k := range m {
@@ -15627,7 +20546,115 @@ func (itr *booleanReduceUnsignedIterator) reduce() ([]UnsignedPoint, error) {
 		sort.Stable(sort.Reverse(unsignedPointsByTime(a)))
 	}
 
-	return a, nil
+	return a
+}
+
+// reduceSpilled reduces a window whose groups did not fit in memory. The
+// points of the groups in memory are written to a run on disk and the points
+// spilled by w are reduced in the same way, until every group is reduced. The
+// runs are then merged in the order the points would have been emitted.
+func (itr *booleanReduceUnsignedIterator) reduceSpilled(w *booleanReduceUnsignedWindow, startTime int64) ([]UnsignedPoint, error) {
+	runs := &unsignedReduceRuns{}
+	for {
+		if err := itr.writeRun(runs, w.groups, startTime); err != nil {
+			w.close()
+			runs.Close()
+			return nil, err
+		} else if w.spill == nil {
+			break
+		}
+
+		input := w.spill
+		next, err := itr.reduceSpillFile(input)
+		input.Close()
+		if err != nil {
+			runs.Close()
+			return nil, err
+		}
+		w = next
+	}
+
+	itr.runs = runs
+	return runs.read(reduceSpillBatchSize)
+}
+
+// reduceSpillFile aggregates the points spilled to f into a new window.
+func (itr *booleanReduceUnsignedIterator) reduceSpillFile(f *spillFile) (*booleanReduceUnsignedWindow, error) {
+	if err := f.rewind(); err != nil {
+		return nil, err
+	}
+	dec := NewBooleanPointDecoder(context.Background(), f)
+
+	w := &booleanReduceUnsignedWindow{groups: make(map[string]*booleanReduceUnsignedPoint)}
+	for {
+		select {
+		case <-itr.opt.InterruptCh:
+			w.close()
+			return nil, ErrQueryInterrupted
+		default:
+		}
+
+		var p BooleanPoint
+		if err := dec.DecodeBooleanPoint(&p); err == io.EOF {
+			return w, nil
+		} else if err != nil {
+			w.close()
+			return nil, err
+		}
+
+		if err := itr.aggregate(w, &p); err != nil {
+			w.close()
+			return nil, err
+		}
+	}
+}
+
+// writeRun writes the points of the groups in m to a new run of runs. The
+// points are sorted by time and then by group, which is the order they are
+// returned in when the groups all fit in memory.
+func (itr *booleanReduceUnsignedIterator) writeRun(runs *unsignedReduceRuns, m map[string]*booleanReduceUnsignedPoint, startTime int64) error {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	type record struct {
+		key string
+		p   UnsignedPoint
+	}
+	records := make([]record, 0, len(keys))
+	for _, k := range keys {
+		rp := m[k]
+		for _, p := range rp.Emitter.Emit() {
+			p.Name = rp.Name
+			if !itr.keepTags {
+				p.Tags = rp.Tags
+			}
+			// Set the points time to the interval time if the reducer didn't provide one.
+			if p.Time == ZeroTime {
+				p.Time = startTime
+			}
+			records = append(records, record{key: k, p: p})
+		}
+	}
+	sort.SliceStable(records, func(i, j int) bool { return records[i].p.Time < records[j].p.Time })
+
+	f, err := newSpillFile(itr.opt.SpillDir)
+	if err != nil {
+		return err
+	}
+	enc := NewUnsignedPointEncoder(f)
+	for i := range records {
+		if err := writeSpillKey(f, records[i].key); err != nil {
+			f.Close()
+			return err
+		} else if err := enc.EncodeUnsignedPoint(&records[i].p); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	return runs.add(f)
 }
 
 // booleanStreamUnsignedIterator streams inputs into the iterator and emits points gradually.
@@ -15897,6 +20924,9 @@ type booleanReduceStringIterator struct {
 	opt      IteratorOptions
 	points   []StringPoint
 	keepTags bool
+
+	// Runs of the current window if its groups did not fit in memory.
+	runs *stringReduceRuns
 }
 
 func newBooleanReduceStringIterator(input BooleanIterator, opt IteratorOptions, createFn func() (BooleanPointAggregator, StringPointEmitter)) *booleanReduceStringIterator {
@@ -15912,7 +20942,12 @@ func newBooleanReduceStringIterator(input BooleanIterator, opt IteratorOptions,
 func (itr *booleanReduceStringIterator) Stats() IteratorStats { return itr.input.Stats() }
 
 // Close closes the iterator and all child iterators.
-func (itr *booleanReduceStringIterator) Close() error { return itr.input.Close() }
+func (itr *booleanReduceStringIterator) Close() error {
+	if itr.runs != nil {
+		itr.runs.Close()
+	}
+	return itr.input.Close()
+}
 
 // Next returns the minimum value for the next available interval.
 func (itr *booleanReduceStringIterator) Next() (*StringPoint, error) {
@@ -15939,9 +20974,35 @@ type booleanReduceStringPoint struct {
 	Emitter    StringPointEmitter
 }
 
+// booleanReduceStringWindow holds the groups of a window while its
+// points are aggregated.
+type booleanReduceStringWindow struct {
+	groups map[string]*booleanReduceStringPoint
+	size   int64 // estimated memory used by groups
+
+	// Points of the groups that did not fit within opt.MaxMemory.
+	spill *spillFile
+	enc   *BooleanPointEncoder
+}
+
+// close removes the spill file of the window.
+func (w *booleanReduceStringWindow) close() {
+	if w.spill != nil {
+		w.spill.Close()
+	}
+}
+
 // reduce executes fn once for every point in the next window.
 // The previous value for the dimension is passed to fn.
 func (itr *booleanReduceStringIterator) reduce() ([]StringPoint, error) {
+	// Return the remaining points of a window that was spilled to disk.
+	if itr.runs != nil {
+		if a, err := itr.runs.read(reduceSpillBatchSize); err != nil || len(a) > 0 {
+			return a, err
+		}
+		itr.runs = nil
+	}
+
 	// Calculate next window.
 	var (
 		startTime, endTime int64
@@ -15966,11 +21027,12 @@ func (itr *booleanReduceStringIterator) reduce() ([]StringPoint, error) {
 	}
 
 	// Create points by tags.
-	m := make(map[string]*booleanReduceStringPoint)
+	w := &booleanReduceStringWindow{groups: make(map[string]*booleanReduceStringPoint)}
 	for {
 		// Read next point.
 		curr, err := itr.input.NextInWindow(startTime, endTime)
 		if err != nil {
+			w.close()
 			return nil, err
 		} else if curr == nil {
 			break
@@ -15990,26 +21052,57 @@ func (itr *booleanReduceStringIterator) reduce() ([]StringPoint, error) {
 			break
 		}
 
-		// Retrieve the tags on this point for this level of the query.
-		// This may be different than the bucket dimensions.
-		tags := curr.Tags.Subset(itr.dims)
-		id := tags.ID()
+		if err := itr.aggregate(w, curr); err != nil {
+			w.close()
+			return nil, err
+		}
+	}
 
-		// Retrieve the aggregator for this name/tag combination or create one.
-		rp := m[id]
-		if rp == nil {
-			aggregator, emitter := itr.create()
-			rp = &booleanReduceStringPoint{
-				Name:       curr.Name,
-				Tags:       tags,
-				Aggregator: aggregator,
-				Emitter:    emitter,
+	if w.spill != nil {
+		return itr.reduceSpilled(w, startTime)
+	}
+	return itr.emit(w.groups, startTime), nil
+}
+
+// aggregate adds p to the aggregator of its group in w. If p belongs to a new
+// group and the groups of w already use opt.MaxMemory, p is spilled to
+// disk instead.
+func (itr *booleanReduceStringIterator) aggregate(w *booleanReduceStringWindow, p *BooleanPoint) error {
+	// Retrieve the tags on this point for this level of the query.
+	// This may be different than the bucket dimensions.
+	tags := p.Tags.Subset(itr.dims)
+	id := tags.ID()
+
+	// Retrieve the aggregator for this name/tag combination or create one.
+	rp := w.groups[id]
+	if rp == nil {
+		if max := itr.opt.MaxMemory; max > 0 && w.size >= max {
+			if w.spill == nil {
+				f, err := newSpillFile(itr.opt.SpillDir)
+				if err != nil {
+					return err
+				}
+				w.spill, w.enc = f, NewBooleanPointEncoder(f)
 			}
-			m[id] = rp
+			return w.enc.EncodeBooleanPoint(p)
 		}
-		rp.Aggregator.AggregateBoolean(curr)
+
+		aggregator, emitter := itr.create()
+		rp = &booleanReduceStringPoint{
+			Name:       p.Name,
+			Tags:       tags,
+			Aggregator: aggregator,
+			Emitter:    emitter,
+		}
+		w.groups[id] = rp
+		w.size += reduceGroupSize(p.Name, id)
 	}
+	rp.Aggregator.AggregateBoolean(p)
+	return nil
+}
 
+// emit returns the points of every group of a window in reverse order.
+func (itr *booleanReduceStringIterator) emit(m map[string]*booleanReduceStringPoint, startTime int64) []StringPoint {
 	// Reverse sort points by name & tag if our output is supposed to be ordered.
 	keys := make([]string, 0, len(m))
 	for k := range m {
@@ -16046,7 +21139,115 @@ func (itr *booleanReduceStringIterator) reduce() ([]StringPoint, error) {
 		sort.Stable(sort.Reverse(stringPointsByTime(a)))
 	}
 
-	return a, nil
+	return a
+}
+
+// reduceSpilled reduces a window whose groups did not fit in memory. The
+// points of the groups in memory are written to a run on disk and the points
+// spilled by w are reduced in the same way, until every group is reduced. The
+// runs are then merged in the order the points would have been emitted.
+func (itr *booleanReduceStringIterator) reduceSpilled(w *booleanReduceStringWindow, startTime int64) ([]StringPoint, error) {
+	runs := &stringReduceRuns{}
+	for {
+		if err := itr.writeRun(runs, w.groups, startTime); err != nil {
+			w.close()
+			runs.Close()
+			return nil, err
+		} else if w.spill == nil {
+			break
+		}
+
+		input := w.spill
+		next, err := itr.reduceSpillFile(input)
+		input.Close()
+		if err != nil {
+			runs.Close()
+			return nil, err
+		}
+		w = next
+	}
+
+	itr.runs = runs
+	return runs.read(reduceSpillBatchSize)
+}
+
+// reduceSpillFile aggregates the points spilled to f into a new window.
+func (itr *booleanReduceStringIterator) reduceSpillFile(f *spillFile) (*booleanReduceStringWindow, error) {
+	if err := f.rewind(); err != nil {
+		return nil, err
+	}
+	dec := NewBooleanPointDecoder(context.Background(), f)
+
+	w := &booleanReduceStringWindow{groups: make(map[string]*booleanReduceStringPoint)}
+	for {
+		select {
+		case <-itr.opt.InterruptCh:
+			w.close()
+			return nil, ErrQueryInterrupted
+		default:
+		}
+
+		var p BooleanPoint
+		if err := dec.DecodeBooleanPoint(&p); err == io.EOF {
+			return w, nil
+		} else if err != nil {
+			w.close()
+			return nil, err
+		}
+
+		if err := itr.aggregate(w, &p); err != nil {
+			w.close()
+			return nil, err
+		}
+	}
+}
+
+// writeRun writes the points of the groups in m to a new run of runs. The
+// points are sorted by time and then by group, which is the order they are
+// returned in when the groups all fit in memory.
+func (itr *booleanReduceStringIterator) writeRun(runs *stringReduceRuns, m map[string]*booleanReduceStringPoint, startTime int64) error {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	type record struct {
+		key string
+		p   StringPoint
+	}
+	records := make([]record, 0, len(keys))
+	for _, k := range keys {
+		rp := m[k]
+		for _, p := range rp.Emitter.Emit() {
+			p.Name = rp.Name
+			if !itr.keepTags {
+				p.Tags = rp.Tags
+			}
+			// Set the points time to the interval time if the reducer didn't provide one.
+			if p.Time == ZeroTime {
+				p.Time = startTime
+			}
+			records = append(records, record{key: k, p: p})
+		}
+	}
+	sort.SliceStable(records, func(i, j int) bool { return records[i].p.Time < records[j].p.Time })
+
+	f, err := newSpillFile(itr.opt.SpillDir)
+	if err != nil {
+		return err
+	}
+	enc := NewStringPointEncoder(f)
+	for i := range records {
+		if err := writeSpillKey(f, records[i].key); err != nil {
+			f.Close()
+			return err
+		} else if err := enc.EncodeStringPoint(&records[i].p); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	return runs.add(f)
 }
 
 // booleanStreamStringIterator streams inputs into the iterator and emits points gradually.
@@ -16316,6 +21517,9 @@ type booleanReduceBooleanIterator struct {
 	opt      IteratorOptions
 	points   []BooleanPoint
 	keepTags bool
+
+	// Runs of the current window if its groups did not fit in memory.
+	runs *booleanReduceRuns
 }
 
 func newBooleanReduceBooleanIterator(input BooleanIterator, opt IteratorOptions, createFn func() (BooleanPointAggregator, BooleanPointEmitter)) *booleanReduceBooleanIterator {
@@ -16331,7 +21535,12 @@ func newBooleanReduceBooleanIterator(input BooleanIterator, opt IteratorOptions,
 func (itr *booleanReduceBooleanIterator) Stats() IteratorStats { return itr.input.Stats() }
 
 // Close closes the iterator and all child iterators.
-func (itr *booleanReduceBooleanIterator) Close() error { return itr.input.Close() }
+func (itr *booleanReduceBooleanIterator) Close() error {
+	if itr.runs != nil {
+		itr.runs.Close()
+	}
+	return itr.input.Close()
+}
 
 // Next returns the minimum value for the next available interval.
 func (itr *booleanReduceBooleanIterator) Next() (*BooleanPoint, error) {
@@ -16358,9 +21567,35 @@ type booleanReduceBooleanPoint struct {
 	Emitter    BooleanPointEmitter
 }
 
+// booleanReduceBooleanWindow holds the groups of a window while its
+// points are aggregated.
+type booleanReduceBooleanWindow struct {
+	groups map[string]*booleanReduceBooleanPoint
+	size   int64 // estimated memory used by groups
+
+	// Points of the groups that did not fit within opt.MaxMemory.
+	spill *spillFile
+	enc   *BooleanPointEncoder
+}
+
+// close removes the spill file of the window.
+func (w *booleanReduceBooleanWindow) close() {
+	if w.spill != nil {
+		w.spill.Close()
+	}
+}
+
 // reduce executes fn once for every point in the next window.
 // The previous value for the dimension is passed to fn.
 func (itr *booleanReduceBooleanIterator) reduce() ([]BooleanPoint, error) {
+	// Return the remaining points of a window that was spilled to disk.
+	if itr.runs != nil {
+		if a, err := itr.runs.read(reduceSpillBatchSize); err != nil || len(a) > 0 {
+			return a, err
+		}
+		itr.runs = nil
+	}
+
 	// Calculate next window.
 	var (
 		startTime, endTime int64
@@ -16385,11 +21620,12 @@ func (itr *booleanReduceBooleanIterator) reduce() ([]BooleanPoint, error) {
 	}
 
 	// Create points by tags.
-	m := make(map[string]*booleanReduceBooleanPoint)
+	w := &booleanReduceBooleanWindow{groups: make(map[string]*booleanReduceBooleanPoint)}
 	for {
 		// Read next point.
 		curr, err := itr.input.NextInWindow(startTime, endTime)
 		if err != nil {
+			w.close()
 			return nil, err
 		} else if curr == nil {
 			break
@@ -16409,26 +21645,57 @@ func (itr *booleanReduceBooleanIterator) reduce() ([]BooleanPoint, error) {
 			break
 		}
 
-		// Retrieve the tags on this point for this level of the query.
-		// This may be different than the bucket dimensions.
-		tags := curr.Tags.Subset(itr.dims)
-		id := tags.ID()
+		if err := itr.aggregate(w, curr); err != nil {
+			w.close()
+			return nil, err
+		}
+	}
 
-		// Retrieve the aggregator for this name/tag combination or create one.
-		rp := m[id]
-		if rp == nil {
-			aggregator, emitter := itr.create()
-			rp = &booleanReduceBooleanPoint{
-				Name:       curr.Name,
-				Tags:       tags,
-				Aggregator: aggregator,
-				Emitter:    emitter,
+	if w.spill != nil {
+		return itr.reduceSpilled(w, startTime)
+	}
+	return itr.emit(w.groups, startTime), nil
+}
+
+// aggregate adds p to the aggregator of its group in w. If p belongs to a new
+// group and the groups of w already use opt.MaxMemory, p is spilled to
+// disk instead.
+func (itr *booleanReduceBooleanIterator) aggregate(w *booleanReduceBooleanWindow, p *BooleanPoint) error {
+	// Retrieve the tags on this point for this level of the query.
+	// This may be different than the bucket dimensions.
+	tags := p.Tags.Subset(itr.dims)
+	id := tags.ID()
+
+	// Retrieve the aggregator for this name/tag combination or create one.
+	rp := w.groups[id]
+	if rp == nil {
+		if max := itr.opt.MaxMemory; max > 0 && w.size >= max {
+			if w.spill == nil {
+				f, err := newSpillFile(itr.opt.SpillDir)
+				if err != nil {
+					return err
+				}
+				w.spill, w.enc = f, NewBooleanPointEncoder(f)
 			}
-			m[id] = rp
+			return w.enc.EncodeBooleanPoint(p)
 		}
-		rp.Aggregator.AggregateBoolean(curr)
+
+		aggregator, emitter := itr.create()
+		rp = &booleanReduceBooleanPoint{
+			Name:       p.Name,
+			Tags:       tags,
+			Aggregator: aggregator,
+			Emitter:    emitter,
+		}
+		w.groups[id] = rp
+		w.size += reduceGroupSize(p.Name, id)
 	}
+	rp.Aggregator.AggregateBoolean(p)
+	return nil
+}
 
+// emit returns the points of every group of a window in reverse order.
+func (itr *booleanReduceBooleanIterator) emit(m map[string]*booleanReduceBooleanPoint, startTime int64) []BooleanPoint {
 	// Reverse sort points by name & tag if our output is supposed to be ordered.
 	keys := make([]string, 0, len(m))
 	for k := range m {
@@ -16465,7 +21732,115 @@ func (itr *booleanReduceBooleanIterator) reduce() ([]BooleanPoint, error) {
 		sort.Stable(sort.Reverse(booleanPointsByTime(a)))
 	}
 
-	return a, nil
+	return a
+}
+
+// reduceSpilled reduces a window whose groups did not fit in memory. The
+// points of the groups in memory are written to a run on disk and the points
+// spilled by w are reduced in the same way, until every group is reduced. The
+// runs are then merged in the order the points would have been emitted.
+func (itr *booleanReduceBooleanIterator) reduceSpilled(w *booleanReduceBooleanWindow, startTime int64) ([]BooleanPoint, error) {
+	runs := &booleanReduceRuns{}
+	for {
+		if err := itr.writeRun(runs, w.groups, startTime); err != nil {
+			w.close()
+			runs.Close()
+			return nil, err
+		} else if w.spill == nil {
+			break
+		}
+
+		input := w.spill
+		next, err := itr.reduceSpillFile(input)
+		input.Close()
+		if err != nil {
+			runs.Close()
+			return nil, err
+		}
+		w = next
+	}
+
+	itr.runs = runs
+	return runs.read(reduceSpillBatchSize)
+}
+
+// reduceSpillFile aggregates the points spilled to f into a new window.
+func (itr *booleanReduceBooleanIterator) reduceSpillFile(f *spillFile) (*booleanReduceBooleanWindow, error) {
+	if err := f.rewind(); err != nil {
+		return nil, err
+	}
+	dec := NewBooleanPointDecoder(context.Background(), f)
+
+	w := &booleanReduceBooleanWindow{groups: make(map[string]*booleanReduceBooleanPoint)}
+	for {
+		select {
+		case <-itr.opt.InterruptCh:
+			w.close()
+			return nil, ErrQueryInterrupted
+		default:
+		}
+
+		var p BooleanPoint
+		if err := dec.DecodeBooleanPoint(&p); err == io.EOF {
+			return w, nil
+		} else if err != nil {
+			w.close()
+			return nil, err
+		}
+
+		if err := itr.aggregate(w, &p); err != nil {
+			w.close()
+			return nil, err
+		}
+	}
+}
+
+// writeRun writes the points of the groups in m to a new run of runs. The
+// points are sorted by time and then by group, which is the order they are
+// returned in when the groups all fit in memory.
+func (itr *booleanReduceBooleanIterator) writeRun(runs *booleanReduceRuns, m map[string]*booleanReduceBooleanPoint, startTime int64) error {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	type record struct {
+		key string
+		p   BooleanPoint
+	}
+	records := make([]record, 0, len(keys))
+	for _, k := range keys {
+		rp := m[k]
+		for _, p := range rp.Emitter.Emit() {
+			p.Name = rp.Name
+			if !itr.keepTags {
+				p.Tags = rp.Tags
+			}
+			// Set the points time to the interval time if the reducer didn't provide one.
+			if p.Time == ZeroTime {
+				p.Time = startTime
+			}
+			records = append(records, record{key: k, p: p})
+		}
+	}
+	sort.SliceStable(records, func(i, j int) bool { return records[i].p.Time < records[j].p.Time })
+
+	f, err := newSpillFile(itr.opt.SpillDir)
+	if err != nil {
+		return err
+	}
+	enc := NewBooleanPointEncoder(f)
+	for i := range records {
+		if err := writeSpillKey(f, records[i].key); err != nil {
+			f.Close()
+			return err
+		} else if err := enc.EncodeBooleanPoint(&records[i].p); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	return runs.add(f)
 }
 
 // booleanStreamBooleanIterator streams inputs into the iterator and emits points gradually.
@@ -719,6 +719,15 @@ type IteratorOptions struct {
 	// Limits on the creation of iterators.
 	MaxSeriesN int
 
+	// Maximum estimated memory an iterator uses to merge its inputs or to
+	// hold the groups of a window before it spills points to disk. Zero
+	// means unlimited.
+	MaxMemory int64
+
+	// Directory of the files spilled to disk. If empty, the default
+	// directory for temporary files is used.
+	SpillDir string
+
 	// If this channel is set and is closed, the iterator should try to exit
 	// and close as soon as possible.
 	InterruptCh <-chan struct{}
@@ -792,6 +801,8 @@ func newIteratorOptionsStmt(stmt *influxql.SelectStatement, sopt SelectOptions)
 	opt.Limit, opt.Offset = stmt.Limit, stmt.Offset
 	opt.SLimit, opt.SOffset = stmt.SLimit, stmt.SOffset
 	opt.MaxSeriesN = sopt.MaxSeriesN
+	opt.MaxMemory = sopt.MaxMemory
+	opt.SpillDir = sopt.SpillDir
 	opt.InterruptCh = sopt.InterruptCh
 	opt.Authorizer = sopt.Authorizer
 
@@ -816,6 +827,7 @@ func newIteratorOptionsSubstatement(stmt *influxql.SelectStatement, opt Iterator
 		subOpt.GroupBy[d] = struct{}{}
 	}
 	subOpt.InterruptCh = opt.InterruptCh
+	subOpt.MaxMemory, subOpt.SpillDir = opt.MaxMemory, opt.SpillDir
 
 	// Extract the time range and condition from the condition.
 	cond, t, err := influxql.ConditionExpr(stmt.Condition, nil)
@@ -879,6 +891,15 @@ func (opt IteratorOptions) SeekTime() int64 {
 	return opt.EndTime
 }
 
+// StopTime returns the time the iterator should stop reading at. It is the
+// end time for ascending iterators and the start time for descending ones.
+func (opt IteratorOptions) StopTime() int64 {
+	if opt.Ascending {
+		return opt.EndTime
+	}
+	return opt.StartTime
+}
+
 // Window returns the time window [start,end) that t falls within.
 func (opt IteratorOptions) Window(t int64) (start, end int64) {
 	if opt.Interval.IsZero() {
@@ -1573,6 +1594,8 @@ func (enc *IteratorEncoder) EncodeIterator(itr Iterator) error {
 		return enc.encodeFloatIterator(itr)
 	case IntegerIterator:
 		return enc.encodeIntegerIterator(itr)
+	case UnsignedIterator:
+		return enc.encodeUnsignedIterator(itr)
 	case StringIterator:
 		return enc.encodeStringIterator(itr)
 	case BooleanIterator:
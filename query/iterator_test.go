@@ -4,7 +4,9 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"io/ioutil"
 	"math"
+	"os"
 	"reflect"
 	"strings"
 	"testing"
@@ -572,6 +574,66 @@ func TestSortedMergeIterator_Nil(t *testing.T) {
 	}
 }
 
+// Ensure that a sorted merge of more inputs than fit in memory is merged
+// through runs on disk.
+func TestSortedMergeIterator_MaxMemory(t *testing.T) {
+	dir, err := ioutil.TempDir("", "query-spill-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	readAll := func(maxMemory int64) [][]query.Point {
+		inputs := make([]*FloatIterator, 25)
+		for i := range inputs {
+			// Spread each series over two inputs.
+			host := fmt.Sprintf("host=%02d", (i*7)%13)
+			points := make([]query.FloatPoint, 20)
+			for j := range points {
+				points[j] = query.FloatPoint{
+					Name:  "cpu",
+					Tags:  ParseTags(host),
+					Time:  int64(j*2 + i%2),
+					Value: float64(i*100 + j),
+					Aux:   []interface{}{fmt.Sprintf("v%d", i)},
+				}
+			}
+			inputs[i] = &FloatIterator{Points: points}
+		}
+
+		itr := query.NewSortedMergeIterator(FloatIterators(inputs), query.IteratorOptions{
+			Dimensions: []string{"host"},
+			Ascending:  true,
+			MaxMemory:  maxMemory,
+			SpillDir:   dir,
+		})
+		a, err := Iterators([]query.Iterator{itr}).ReadAll()
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		itr.Close()
+
+		for i, input := range inputs {
+			if !input.Closed {
+				t.Errorf("iterator %d not closed", i)
+			}
+		}
+		return a
+	}
+
+	// Merge three inputs at a time.
+	exp := readAll(0)
+	if got := readAll(3 * 64 * 1024); !deep.Equal(got, exp) {
+		t.Fatalf("unexpected points: %s", spew.Sdump(got))
+	}
+
+	if fis, err := ioutil.ReadDir(dir); err != nil {
+		t.Fatal(err)
+	} else if len(fis) != 0 {
+		t.Fatalf("unexpected spill files: %d", len(fis))
+	}
+}
+
 func TestSortedMergeIterator_Coerce_Float(t *testing.T) {
 	inputs := []query.Iterator{
 		&FloatIterator{Points: []query.FloatPoint{
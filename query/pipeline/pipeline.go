@@ -0,0 +1,728 @@
+// Package pipeline compiles pipeline queries onto InfluxQL.
+//
+// A pipeline is a chain of function calls joined by the |> operator. Each
+// call transforms the series produced by the previous one:
+//
+//	from(bucket: "telegraf/autogen")
+//	  |> range(start: -1h)
+//	  |> filter(fn: (r) => r._measurement == "cpu" and r._field == "usage_user" and r.host =~ /server0[12]/)
+//	  |> group(columns: ["host"])
+//	  |> aggregateWindow(every: 1m, fn: mean)
+//
+// A pipeline compiles to a single SELECT statement, so it is planned and
+// executed by the same iterators as the equivalent InfluxQL query.
+package pipeline
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/influxdata/influxql"
+)
+
+// Aggregates lists the functions that may be used as a pipeline stage or as
+// the fn argument of aggregateWindow.
+var Aggregates = []string{
+	"count", "first", "last", "max", "mean", "median", "min", "mode", "spread", "stddev", "sum",
+}
+
+// Query is a compiled pipeline.
+type Query struct {
+	// Database and RetentionPolicy are the bucket named by from(). The
+	// retention policy is empty when the bucket only names a database.
+	Database        string
+	RetentionPolicy string
+
+	// Statement is the InfluxQL statement the pipeline compiles to.
+	Statement *influxql.SelectStatement
+}
+
+// String returns the InfluxQL text of the compiled query.
+func (q *Query) String() string { return q.Statement.String() }
+
+// ident is a bare identifier used as an argument value, such as mean.
+type ident string
+
+// lambda is a predicate function such as (r) => r.host == "a".
+type lambda struct {
+	expr influxql.Expr
+}
+
+// call is a single stage of a pipeline.
+type call struct {
+	name string
+	args map[string]interface{}
+}
+
+// Compile parses a pipeline and compiles it to an InfluxQL query.
+func Compile(text string) (*Query, error) {
+	calls, err := parse(text)
+	if err != nil {
+		return nil, err
+	}
+	return compile(calls)
+}
+
+// parser builds the stages of a pipeline from its tokens.
+type parser struct {
+	items []item
+	i     int
+}
+
+func parse(text string) ([]*call, error) {
+	items, err := scan(text)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{items: items}
+
+	var calls []*call
+	for {
+		c, err := p.parseCall()
+		if err != nil {
+			return nil, err
+		}
+		calls = append(calls, c)
+
+		switch it := p.next(); it.tok {
+		case tokenPipe:
+		case tokenEOF:
+			return calls, nil
+		default:
+			return nil, p.unexpected(it, "|>")
+		}
+	}
+}
+
+func (p *parser) next() item {
+	it := p.items[p.i]
+	if it.tok != tokenEOF {
+		p.i++
+	}
+	return it
+}
+
+func (p *parser) peek() item { return p.items[p.i] }
+
+func (p *parser) expect(tok token, exp string) (item, error) {
+	it := p.next()
+	if it.tok != tok {
+		return it, p.unexpected(it, exp)
+	}
+	return it, nil
+}
+
+func (p *parser) unexpected(it item, exp string) error {
+	if it.tok == tokenEOF {
+		return fmt.Errorf("found EOF, expected %s", exp)
+	}
+	return fmt.Errorf("found %s, expected %s at position %d", it.lit, exp, it.pos)
+}
+
+// parseCall parses name(arg: value, ...).
+func (p *parser) parseCall() (*call, error) {
+	name, err := p.expect(tokenIdent, "function")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := p.expect(tokenLParen, "("); err != nil {
+		return nil, err
+	}
+
+	c := &call{name: name.lit, args: make(map[string]interface{})}
+	if p.peek().tok == tokenRParen {
+		p.next()
+		return c, nil
+	}
+	for {
+		key, err := p.expect(tokenIdent, "argument name")
+		if err != nil {
+			return nil, err
+		}
+		if _, err := p.expect(tokenColon, ":"); err != nil {
+			return nil, err
+		}
+		v, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := c.args[key.lit]; ok {
+			return nil, fmt.Errorf("%s: duplicate argument %s", c.name, key.lit)
+		}
+		c.args[key.lit] = v
+
+		switch it := p.next(); it.tok {
+		case tokenComma:
+		case tokenRParen:
+			return c, nil
+		default:
+			return nil, p.unexpected(it, ", or )")
+		}
+	}
+}
+
+// parseValue parses an argument value.
+func (p *parser) parseValue() (interface{}, error) {
+	it := p.next()
+	switch it.tok {
+	case tokenString:
+		return it.lit, nil
+	case tokenIdent:
+		return ident(it.lit), nil
+	case tokenNumber:
+		return parseNumber(it)
+	case tokenDuration:
+		return parseDuration(it)
+	case tokenLBrack:
+		var list []interface{}
+		if p.peek().tok == tokenRBrack {
+			p.next()
+			return list, nil
+		}
+		for {
+			v, err := p.parseValue()
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, v)
+
+			switch it := p.next(); it.tok {
+			case tokenComma:
+			case tokenRBrack:
+				return list, nil
+			default:
+				return nil, p.unexpected(it, ", or ]")
+			}
+		}
+	case tokenLParen:
+		return p.parseLambda()
+	}
+	return nil, p.unexpected(it, "value")
+}
+
+// parseLambda parses the remainder of (r) => predicate.
+func (p *parser) parseLambda() (*lambda, error) {
+	param, err := p.expect(tokenIdent, "parameter name")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := p.expect(tokenRParen, ")"); err != nil {
+		return nil, err
+	}
+	if _, err := p.expect(tokenArrow, "=>"); err != nil {
+		return nil, err
+	}
+	expr, err := p.parseOr(param.lit)
+	if err != nil {
+		return nil, err
+	}
+	return &lambda{expr: expr}, nil
+}
+
+func (p *parser) parseOr(param string) (influxql.Expr, error) {
+	expr, err := p.parseAnd(param)
+	if err != nil {
+		return nil, err
+	}
+	for it := p.peek(); it.tok == tokenIdent && it.lit == "or"; it = p.peek() {
+		p.next()
+		rhs, err := p.parseAnd(param)
+		if err != nil {
+			return nil, err
+		}
+		expr = &influxql.BinaryExpr{Op: influxql.OR, LHS: expr, RHS: rhs}
+	}
+	return expr, nil
+}
+
+func (p *parser) parseAnd(param string) (influxql.Expr, error) {
+	expr, err := p.parseComparison(param)
+	if err != nil {
+		return nil, err
+	}
+	for it := p.peek(); it.tok == tokenIdent && it.lit == "and"; it = p.peek() {
+		p.next()
+		rhs, err := p.parseComparison(param)
+		if err != nil {
+			return nil, err
+		}
+		expr = and(expr, rhs)
+	}
+	return expr, nil
+}
+
+// parseComparison parses r.key <op> value or a parenthesized predicate.
+func (p *parser) parseComparison(param string) (influxql.Expr, error) {
+	if p.peek().tok == tokenLParen {
+		p.next()
+		expr, err := p.parseOr(param)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := p.expect(tokenRParen, ")"); err != nil {
+			return nil, err
+		}
+		return &influxql.ParenExpr{Expr: expr}, nil
+	}
+
+	it, err := p.expect(tokenIdent, param)
+	if err != nil {
+		return nil, err
+	} else if it.lit != param {
+		return nil, p.unexpected(it, param)
+	}
+	if _, err := p.expect(tokenDot, "."); err != nil {
+		return nil, err
+	}
+	key, err := p.expect(tokenIdent, "column")
+	if err != nil {
+		return nil, err
+	}
+
+	var op influxql.Token
+	opItem := p.next()
+	switch opItem.tok {
+	case tokenEQ:
+		op = influxql.EQ
+	case tokenNEQ:
+		op = influxql.NEQ
+	case tokenEQREGEX:
+		op = influxql.EQREGEX
+	case tokenNEQREGEX:
+		op = influxql.NEQREGEX
+	default:
+		return nil, p.unexpected(opItem, "==, !=, =~ or !~")
+	}
+
+	var rhs influxql.Expr
+	switch it := p.next(); it.tok {
+	case tokenString:
+		if op == influxql.EQREGEX || op == influxql.NEQREGEX {
+			return nil, fmt.Errorf("%s requires a regex at position %d", opItem.lit, it.pos)
+		}
+		rhs = &influxql.StringLiteral{Val: it.lit}
+	case tokenRegex:
+		if op != influxql.EQREGEX && op != influxql.NEQREGEX {
+			return nil, fmt.Errorf("%s requires a string at position %d", opItem.lit, it.pos)
+		}
+		re, err := regexp.Compile(it.lit)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regex at position %d: %s", it.pos, err)
+		}
+		rhs = &influxql.RegexLiteral{Val: re}
+	default:
+		return nil, p.unexpected(it, "string or regex")
+	}
+	return &influxql.BinaryExpr{Op: op, LHS: &influxql.VarRef{Val: key.lit}, RHS: rhs}, nil
+}
+
+func parseNumber(it item) (interface{}, error) {
+	if strings.Contains(it.lit, ".") {
+		f, err := strconv.ParseFloat(it.lit, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %s at position %d", it.lit, it.pos)
+		}
+		return f, nil
+	}
+	n, err := strconv.ParseInt(it.lit, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid integer %s at position %d", it.lit, it.pos)
+	}
+	return n, nil
+}
+
+func parseDuration(it item) (interface{}, error) {
+	lit := it.lit
+	neg := strings.HasPrefix(lit, "-")
+	if neg {
+		lit = lit[1:]
+	}
+
+	// Durations are made of several InfluxQL duration literals, such as 1h30m.
+	var d time.Duration
+	for lit != "" {
+		i := strings.IndexFunc(lit, func(r rune) bool { return r < '0' || r > '9' })
+		if i <= 0 {
+			return nil, fmt.Errorf("invalid duration %s at position %d", it.lit, it.pos)
+		}
+		j := i + strings.IndexFunc(lit[i:], func(r rune) bool { return r >= '0' && r <= '9' })
+		if j < i {
+			j = len(lit)
+		}
+		part, err := influxql.ParseDuration(lit[:j])
+		if err != nil {
+			return nil, fmt.Errorf("invalid duration %s at position %d", it.lit, it.pos)
+		}
+		d += part
+		lit = lit[j:]
+	}
+	if neg {
+		d = -d
+	}
+	return d, nil
+}
+
+// compiler accumulates the parts of the SELECT statement.
+type compiler struct {
+	q           *Query
+	measurement string
+	field       string
+	aggregate   string
+	interval    time.Duration
+	condition   influxql.Expr
+	timeRange   influxql.Expr
+	dimensions  []string
+	limit       int
+}
+
+func compile(calls []*call) (*Query, error) {
+	c := &compiler{q: &Query{}}
+	if calls[0].name != "from" {
+		return nil, errors.New("a pipeline must start with from()")
+	}
+	for i, call := range calls {
+		if call.name == "from" && i > 0 {
+			return nil, errors.New("from() may only start a pipeline")
+		}
+		if err := c.compileCall(call); err != nil {
+			return nil, fmt.Errorf("%s: %s", call.name, err)
+		}
+	}
+
+	if c.timeRange == nil {
+		return nil, errors.New("a pipeline requires range()")
+	} else if c.measurement == "" {
+		return nil, errors.New(`a pipeline requires a filter on r._measurement`)
+	}
+	return c.statement()
+}
+
+func (c *compiler) compileCall(call *call) error {
+	switch call.name {
+	case "from":
+		return c.compileFrom(call)
+	case "range":
+		return c.compileRange(call)
+	case "filter":
+		return c.compileFilter(call)
+	case "group":
+		return c.compileGroup(call)
+	case "aggregateWindow":
+		return c.compileAggregateWindow(call)
+	case "limit":
+		n, err := intArg(call, "n", true)
+		if err != nil {
+			return err
+		} else if n <= 0 {
+			return errors.New("n must be greater than 0")
+		}
+		c.limit = int(n)
+		return checkArgs(call, "n")
+	case "yield":
+		// Results are always returned; a yield only names them.
+		return checkArgs(call, "name")
+	}
+
+	if isAggregate(call.name) {
+		if err := c.setAggregate(call.name); err != nil {
+			return err
+		}
+		return checkArgs(call)
+	}
+	return errors.New("unknown function")
+}
+
+// compileFrom sets the database and retention policy from a bucket. Like the
+// version 2 write endpoint, a bucket is a database optionally followed by a
+// slash and a retention policy.
+func (c *compiler) compileFrom(call *call) error {
+	bucket, err := stringArg(call, "bucket", true)
+	if err != nil {
+		return err
+	} else if bucket == "" {
+		return errors.New("bucket is required")
+	}
+	c.q.Database = bucket
+	if i := strings.IndexByte(bucket, '/'); i >= 0 {
+		c.q.Database, c.q.RetentionPolicy = bucket[:i], bucket[i+1:]
+	}
+	return checkArgs(call, "bucket")
+}
+
+func (c *compiler) compileRange(call *call) error {
+	if c.timeRange != nil {
+		return errors.New("range may only be used once")
+	}
+	start, err := timeArg(call, "start", true)
+	if err != nil {
+		return err
+	}
+	c.timeRange = &influxql.BinaryExpr{Op: influxql.GTE, LHS: &influxql.VarRef{Val: "time"}, RHS: start}
+
+	if stop, err := timeArg(call, "stop", false); err != nil {
+		return err
+	} else if stop != nil {
+		c.timeRange = and(c.timeRange, &influxql.BinaryExpr{Op: influxql.LT, LHS: &influxql.VarRef{Val: "time"}, RHS: stop})
+	}
+	return checkArgs(call, "start", "stop")
+}
+
+// compileFilter adds the predicate of a filter to the statement. Comparisons
+// of r._measurement and r._field choose the measurement and field to select
+// from and must be equalities joined to the rest of the predicate by and.
+func (c *compiler) compileFilter(call *call) error {
+	v, ok := call.args["fn"]
+	if !ok {
+		return errors.New("missing required argument fn")
+	}
+	fn, ok := v.(*lambda)
+	if !ok {
+		return errors.New("fn must be a function")
+	}
+
+	var rest influxql.Expr
+	for _, expr := range conjuncts(fn.expr) {
+		if bin, ok := expr.(*influxql.BinaryExpr); ok && bin.Op == influxql.EQ {
+			if ref, ok := bin.LHS.(*influxql.VarRef); ok && (ref.Val == "_measurement" || ref.Val == "_field") {
+				value := bin.RHS.(*influxql.StringLiteral).Val
+				target := &c.measurement
+				if ref.Val == "_field" {
+					target = &c.field
+				}
+				if *target != "" && *target != value {
+					return fmt.Errorf("conflicting filters on %s", ref.Val)
+				}
+				*target = value
+				continue
+			}
+		}
+
+		var err error
+		influxql.WalkFunc(expr, func(n influxql.Node) {
+			if ref, ok := n.(*influxql.VarRef); ok && err == nil && (ref.Val == "_measurement" || ref.Val == "_field") {
+				err = fmt.Errorf("%s may only be compared with == and combined using and", ref.Val)
+			} else if ok && err == nil && strings.HasPrefix(ref.Val, "_") {
+				err = fmt.Errorf("unsupported column %s", ref.Val)
+			}
+		})
+		if err != nil {
+			return err
+		}
+		rest = and(rest, expr)
+	}
+	if rest != nil {
+		c.condition = and(c.condition, rest)
+	}
+	return checkArgs(call, "fn")
+}
+
+func (c *compiler) compileGroup(call *call) error {
+	c.dimensions = nil
+	if v, ok := call.args["columns"]; ok {
+		list, ok := v.([]interface{})
+		if !ok {
+			return errors.New("columns must be a list of strings")
+		}
+		for _, v := range list {
+			s, ok := v.(string)
+			if !ok {
+				return errors.New("columns must be a list of strings")
+			} else if strings.HasPrefix(s, "_") {
+				return fmt.Errorf("cannot group by %s", s)
+			}
+			c.dimensions = append(c.dimensions, s)
+		}
+	}
+	return checkArgs(call, "columns")
+}
+
+func (c *compiler) compileAggregateWindow(call *call) error {
+	every, ok := call.args["every"].(time.Duration)
+	if !ok {
+		return errors.New("every must be a duration")
+	} else if every <= 0 {
+		return errors.New("every must be greater than 0")
+	}
+	fn, ok := call.args["fn"].(ident)
+	if !ok || !isAggregate(string(fn)) {
+		return fmt.Errorf("fn must be one of %s", strings.Join(Aggregates, ", "))
+	}
+	if err := c.setAggregate(string(fn)); err != nil {
+		return err
+	}
+	c.interval = every
+	return checkArgs(call, "every", "fn")
+}
+
+func (c *compiler) setAggregate(name string) error {
+	if c.aggregate != "" {
+		return errors.New("only one aggregate may be used in a pipeline")
+	}
+	c.aggregate = name
+	return nil
+}
+
+// statement builds the SELECT statement. The statement is formatted and
+// parsed again so that it is initialized exactly as an InfluxQL query.
+func (c *compiler) statement() (*Query, error) {
+	var field influxql.Expr = &influxql.Wildcard{}
+	if c.field != "" {
+		field = &influxql.VarRef{Val: c.field}
+	}
+	if c.aggregate != "" {
+		field = &influxql.Call{Name: c.aggregate, Args: []influxql.Expr{field}}
+	}
+
+	stmt := &influxql.SelectStatement{
+		Fields: influxql.Fields{{Expr: field}},
+		Sources: influxql.Sources{&influxql.Measurement{
+			Database:        c.q.Database,
+			RetentionPolicy: c.q.RetentionPolicy,
+			Name:            c.measurement,
+		}},
+		Condition: and(c.condition, c.timeRange),
+		Limit:     c.limit,
+	}
+	if c.interval > 0 {
+		stmt.Dimensions = append(stmt.Dimensions, &influxql.Dimension{Expr: &influxql.Call{
+			Name: "time",
+			Args: []influxql.Expr{&influxql.DurationLiteral{Val: c.interval}},
+		}})
+	}
+	for _, name := range c.dimensions {
+		stmt.Dimensions = append(stmt.Dimensions, &influxql.Dimension{Expr: &influxql.VarRef{Val: name}})
+	}
+
+	parsed, err := influxql.ParseStatement(stmt.String())
+	if err != nil {
+		return nil, err
+	}
+	c.q.Statement = parsed.(*influxql.SelectStatement)
+	return c.q, nil
+}
+
+// and joins two expressions with AND. Either expression may be nil.
+func and(lhs, rhs influxql.Expr) influxql.Expr {
+	if lhs == nil {
+		return rhs
+	} else if rhs == nil {
+		return lhs
+	}
+	return &influxql.BinaryExpr{Op: influxql.AND, LHS: paren(lhs), RHS: paren(rhs)}
+}
+
+// paren wraps an OR expression in parentheses so it keeps its precedence
+// when the statement is formatted.
+func paren(expr influxql.Expr) influxql.Expr {
+	if bin, ok := expr.(*influxql.BinaryExpr); ok && bin.Op == influxql.OR {
+		return &influxql.ParenExpr{Expr: expr}
+	}
+	return expr
+}
+
+// conjuncts splits an expression into the expressions joined by AND.
+func conjuncts(expr influxql.Expr) []influxql.Expr {
+	switch expr := expr.(type) {
+	case *influxql.BinaryExpr:
+		if expr.Op == influxql.AND {
+			return append(conjuncts(expr.LHS), conjuncts(expr.RHS)...)
+		}
+	case *influxql.ParenExpr:
+		return conjuncts(expr.Expr)
+	}
+	return []influxql.Expr{expr}
+}
+
+func isAggregate(name string) bool {
+	for _, agg := range Aggregates {
+		if agg == name {
+			return true
+		}
+	}
+	return false
+}
+
+// checkArgs returns an error if a call has an argument not in names.
+func checkArgs(call *call, names ...string) error {
+	for key := range call.args {
+		found := false
+		for _, name := range names {
+			if key == name {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("unexpected argument %s", key)
+		}
+	}
+	return nil
+}
+
+func stringArg(call *call, name string, required bool) (string, error) {
+	v, ok := call.args[name]
+	if !ok {
+		if required {
+			return "", fmt.Errorf("missing required argument %s", name)
+		}
+		return "", nil
+	}
+	s, ok := v.(string)
+	if !ok {
+		return "", fmt.Errorf("%s must be a string", name)
+	}
+	return s, nil
+}
+
+func intArg(call *call, name string, required bool) (int64, error) {
+	v, ok := call.args[name]
+	if !ok {
+		if required {
+			return 0, fmt.Errorf("missing required argument %s", name)
+		}
+		return 0, nil
+	}
+	n, ok := v.(int64)
+	if !ok {
+		return 0, fmt.Errorf("%s must be an integer", name)
+	}
+	return n, nil
+}
+
+// timeArg returns a time bound as an InfluxQL expression. A duration is
+// relative to now() and a string is an RFC3339 timestamp.
+func timeArg(call *call, name string, required bool) (influxql.Expr, error) {
+	v, ok := call.args[name]
+	if !ok {
+		if required {
+			return nil, fmt.Errorf("missing required argument %s", name)
+		}
+		return nil, nil
+	}
+
+	now := &influxql.Call{Name: "now"}
+	switch v := v.(type) {
+	case time.Duration:
+		if v < 0 {
+			return &influxql.BinaryExpr{Op: influxql.SUB, LHS: now, RHS: &influxql.DurationLiteral{Val: -v}}, nil
+		} else if v > 0 {
+			return &influxql.BinaryExpr{Op: influxql.ADD, LHS: now, RHS: &influxql.DurationLiteral{Val: v}}, nil
+		}
+		return now, nil
+	case ident:
+		if v == "now" {
+			return now, nil
+		}
+	case string:
+		t, err := time.Parse(time.RFC3339Nano, v)
+		if err != nil {
+			return nil, fmt.Errorf("%s must be an RFC3339 time: %s", name, err)
+		}
+		return &influxql.StringLiteral{Val: t.UTC().Format(time.RFC3339Nano)}, nil
+	}
+	return nil, fmt.Errorf("%s must be a duration or time", name)
+}
@@ -0,0 +1,71 @@
+package pipeline_test
+
+import (
+	"testing"
+
+	"github.com/influxdata/influxdb/query/pipeline"
+)
+
+func TestCompile(t *testing.T) {
+	for _, tt := range []struct {
+		s   string
+		exp string
+		db  string
+		rp  string
+	}{
+		{
+			s:   `from(bucket: "telegraf") |> range(start: -1h) |> filter(fn: (r) => r._measurement == "cpu")`,
+			exp: `SELECT * FROM telegraf..cpu WHERE time >= now() - 1h`,
+			db:  "telegraf",
+		},
+		{
+			s: `from(bucket: "telegraf/autogen")
+  |> range(start: -1h30m, stop: -5m)
+  |> filter(fn: (r) => r._measurement == "cpu" and r._field == "usage_user")
+  |> filter(fn: (r) => r.host == "a" or r.host =~ /^b/)
+  |> group(columns: ["host"])
+  |> aggregateWindow(every: 1m, fn: mean)
+  |> limit(n: 10)`,
+			exp: `SELECT mean(usage_user) FROM telegraf.autogen.cpu WHERE (host = 'a' OR host =~ /^b/) AND time >= now() - 90m AND time < now() - 5m GROUP BY time(1m), host LIMIT 10`,
+			db:  "telegraf",
+			rp:  "autogen",
+		},
+		{
+			s:   `from(bucket: "db") |> range(start: "2017-01-01T00:00:00Z", stop: now) |> filter(fn: (r) => (r._measurement == "mem" and r.region != "us")) |> max()`,
+			exp: `SELECT max(*) FROM db..mem WHERE region != 'us' AND time >= '2017-01-01T00:00:00Z' AND time < now()`,
+			db:  "db",
+		},
+	} {
+		q, err := pipeline.Compile(tt.s)
+		if err != nil {
+			t.Fatalf("%s: %s", tt.s, err)
+		} else if q.String() != tt.exp {
+			t.Fatalf("unexpected statement:\n got %s\n exp %s", q, tt.exp)
+		} else if q.Database != tt.db || q.RetentionPolicy != tt.rp {
+			t.Fatalf("unexpected bucket: db=%q rp=%q", q.Database, q.RetentionPolicy)
+		}
+	}
+}
+
+func TestCompile_Errors(t *testing.T) {
+	for _, tt := range []struct {
+		s   string
+		err string
+	}{
+		{s: ``, err: `found EOF, expected function`},
+		{s: `range(start: -1h)`, err: `a pipeline must start with from()`},
+		{s: `from(bucket: "db") |> filter(fn: (r) => r._measurement == "cpu")`, err: `a pipeline requires range()`},
+		{s: `from(bucket: "db") |> range(start: -1h)`, err: `a pipeline requires a filter on r._measurement`},
+		{s: `from(bucket: "db") |> range(start: -1h) |> map(fn: (r) => r.x == "y")`, err: `map: unknown function`},
+		{s: `from(bucket: "db") |> range(start: -1h, end: 0)`, err: `range: unexpected argument end`},
+		{s: `from(bucket: "db") |> range(start: -1h) |> filter(fn: (r) => r._measurement == "a" or r._measurement == "b")`, err: `filter: _measurement may only be compared with == and combined using and`},
+		{s: `from(bucket: "db") |> range(start: -1h) |> filter(fn: (r) => r.host == /a/)`, err: `== requires a string at position 71`},
+		{s: `from(bucket: "db") |> range(start: -1h) |> mean() |> sum()`, err: `sum: only one aggregate may be used in a pipeline`},
+		{s: `from(bucket: "db") |> range(start: -1h) |> aggregateWindow(every: 1m, fn: derivative)`, err: `aggregateWindow: fn must be one of count, first, last, max, mean, median, min, mode, spread, stddev, sum`},
+		{s: `from(bucket: "db) |> range(start: -1h)`, err: `unterminated " at position 13`},
+	} {
+		if _, err := pipeline.Compile(tt.s); err == nil || err.Error() != tt.err {
+			t.Errorf("%s: unexpected error:\n got %v\n exp %s", tt.s, err, tt.err)
+		}
+	}
+}
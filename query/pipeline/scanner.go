@@ -0,0 +1,196 @@
+package pipeline
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// token is a lexical token of a pipeline.
+type token int
+
+const (
+	tokenEOF token = iota
+	tokenIdent
+	tokenString
+	tokenNumber
+	tokenDuration
+	tokenRegex
+	tokenPipe   // |>
+	tokenArrow  // =>
+	tokenLParen // (
+	tokenRParen // )
+	tokenLBrack // [
+	tokenRBrack // ]
+	tokenColon  // :
+	tokenComma  // ,
+	tokenDot    // .
+	tokenEQ     // ==
+	tokenNEQ    // !=
+	tokenEQREGEX
+	tokenNEQREGEX
+)
+
+// item is a token and the text it was scanned from.
+type item struct {
+	tok token
+	lit string
+	pos int
+}
+
+// scanner splits a pipeline into tokens.
+type scanner struct {
+	s   string
+	i   int
+	err error
+}
+
+// scan returns all of the tokens in s.
+func scan(s string) ([]item, error) {
+	sc := &scanner{s: s}
+	var items []item
+	for {
+		it := sc.next()
+		if sc.err != nil {
+			return nil, sc.err
+		}
+		items = append(items, it)
+		if it.tok == tokenEOF {
+			return items, nil
+		}
+	}
+}
+
+func (sc *scanner) errorf(format string, args ...interface{}) item {
+	sc.err = fmt.Errorf("%s at position %d", fmt.Sprintf(format, args...), sc.i)
+	return item{tok: tokenEOF, pos: sc.i}
+}
+
+func (sc *scanner) next() item {
+	// Skip whitespace and line comments.
+	for sc.i < len(sc.s) {
+		if c := sc.s[sc.i]; c == ' ' || c == '\t' || c == '\n' || c == '\r' {
+			sc.i++
+		} else if strings.HasPrefix(sc.s[sc.i:], "//") {
+			for sc.i < len(sc.s) && sc.s[sc.i] != '\n' {
+				sc.i++
+			}
+		} else {
+			break
+		}
+	}
+	if sc.i >= len(sc.s) {
+		return item{tok: tokenEOF, pos: sc.i}
+	}
+
+	start := sc.i
+	for _, op := range []struct {
+		lit string
+		tok token
+	}{
+		{"|>", tokenPipe}, {"=>", tokenArrow}, {"==", tokenEQ}, {"!=", tokenNEQ},
+		{"=~", tokenEQREGEX}, {"!~", tokenNEQREGEX},
+	} {
+		if strings.HasPrefix(sc.s[sc.i:], op.lit) {
+			sc.i += len(op.lit)
+			return item{tok: op.tok, lit: op.lit, pos: start}
+		}
+	}
+
+	c := sc.s[sc.i]
+	switch c {
+	case '(':
+		sc.i++
+		return item{tok: tokenLParen, lit: "(", pos: start}
+	case ')':
+		sc.i++
+		return item{tok: tokenRParen, lit: ")", pos: start}
+	case '[':
+		sc.i++
+		return item{tok: tokenLBrack, lit: "[", pos: start}
+	case ']':
+		sc.i++
+		return item{tok: tokenRBrack, lit: "]", pos: start}
+	case ':':
+		sc.i++
+		return item{tok: tokenColon, lit: ":", pos: start}
+	case ',':
+		sc.i++
+		return item{tok: tokenComma, lit: ",", pos: start}
+	case '.':
+		sc.i++
+		return item{tok: tokenDot, lit: ".", pos: start}
+	case '"':
+		return sc.scanDelimited('"', tokenString)
+	case '/':
+		return sc.scanDelimited('/', tokenRegex)
+	}
+
+	if c == '-' || (c >= '0' && c <= '9') {
+		return sc.scanNumber()
+	}
+	if c == '_' || unicode.IsLetter(rune(c)) {
+		for sc.i < len(sc.s) && (sc.s[sc.i] == '_' || unicode.IsLetter(rune(sc.s[sc.i])) || unicode.IsDigit(rune(sc.s[sc.i]))) {
+			sc.i++
+		}
+		return item{tok: tokenIdent, lit: sc.s[start:sc.i], pos: start}
+	}
+	return sc.errorf("unexpected character %q", c)
+}
+
+// scanDelimited scans a string or regex ending with an unescaped delim.
+func (sc *scanner) scanDelimited(delim byte, tok token) item {
+	start := sc.i
+	sc.i++
+	var buf strings.Builder
+	for sc.i < len(sc.s) {
+		c := sc.s[sc.i]
+		if c == '\\' && sc.i+1 < len(sc.s) {
+			// Regexes keep their escapes; strings only unescape the delimiter and backslash.
+			if next := sc.s[sc.i+1]; tok == tokenRegex && next != delim {
+				buf.WriteByte(c)
+			} else if tok == tokenString && next != delim && next != '\\' {
+				buf.WriteByte(c)
+			}
+			buf.WriteByte(sc.s[sc.i+1])
+			sc.i += 2
+			continue
+		}
+		sc.i++
+		if c == delim {
+			return item{tok: tok, lit: buf.String(), pos: start}
+		}
+		buf.WriteByte(c)
+	}
+	sc.i = start
+	return sc.errorf("unterminated %c", delim)
+}
+
+// scanNumber scans an integer, a float or a duration such as -1h30m.
+func (sc *scanner) scanNumber() item {
+	start := sc.i
+	if sc.s[sc.i] == '-' {
+		sc.i++
+	}
+	digits := sc.i
+	for sc.i < len(sc.s) && (sc.s[sc.i] >= '0' && sc.s[sc.i] <= '9' || sc.s[sc.i] == '.') {
+		sc.i++
+	}
+	if sc.i == digits {
+		return sc.errorf("expected number")
+	}
+
+	// A unit after the digits makes the number a duration. Durations may
+	// be made of several parts, such as 1h30m.
+	tok := tokenNumber
+	for sc.i < len(sc.s) && unicode.IsLetter(rune(sc.s[sc.i])) {
+		tok = tokenDuration
+		for sc.i < len(sc.s) && (unicode.IsLetter(rune(sc.s[sc.i]))) {
+			sc.i++
+		}
+		for sc.i < len(sc.s) && sc.s[sc.i] >= '0' && sc.s[sc.i] <= '9' {
+			sc.i++
+		}
+	}
+	return item{tok: tok, lit: sc.s[start:sc.i], pos: start}
+}
@@ -5,12 +5,15 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"reflect"
 	"runtime/debug"
 	"strconv"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
 
+	"github.com/influxdata/influxdb"
 	"github.com/influxdata/influxdb/models"
 	"github.com/influxdata/influxql"
 	"github.com/uber-go/zap"
@@ -18,24 +21,25 @@ import (
 
 var (
 	// ErrInvalidQuery is returned when executing an unknown query type.
-	ErrInvalidQuery = errors.New("invalid query")
+	ErrInvalidQuery = influxdb.NewError(influxdb.ErrorCodeInvalidQuery, "invalid query")
 
 	// ErrNotExecuted is returned when a statement is not executed in a query.
-	// This can occur when a previous statement in the same query has errored.
-	ErrNotExecuted = errors.New("not executed")
+	// This can occur when the query was interrupted or when a previous
+	// statement in the same query has errored and AbortOnError was set.
+	ErrNotExecuted = influxdb.NewError(influxdb.ErrorCodeNotExecuted, "not executed")
 
 	// ErrQueryInterrupted is an error returned when the query is interrupted.
-	ErrQueryInterrupted = errors.New("query interrupted")
+	ErrQueryInterrupted = influxdb.NewError(influxdb.ErrorCodeQueryInterrupted, "query interrupted")
 
 	// ErrQueryAborted is an error returned when the query is aborted.
-	ErrQueryAborted = errors.New("query aborted")
+	ErrQueryAborted = influxdb.NewError(influxdb.ErrorCodeQueryInterrupted, "query aborted")
 
 	// ErrQueryEngineShutdown is an error sent when the query cannot be
 	// created because the query engine was shutdown.
-	ErrQueryEngineShutdown = errors.New("query engine shutdown")
+	ErrQueryEngineShutdown = influxdb.NewError(influxdb.ErrorCodeShutdown, "query engine shutdown")
 
 	// ErrQueryTimeoutLimitExceeded is an error when a query hits the max time allowed to run.
-	ErrQueryTimeoutLimitExceeded = errors.New("query-timeout limit exceeded")
+	ErrQueryTimeoutLimitExceeded = influxdb.NewError(influxdb.ErrorCodeQueryTimeout, "query-timeout limit exceeded")
 
 	// ErrAlreadyKilled is returned when attempting to kill a query that has already been killed.
 	ErrAlreadyKilled = errors.New("already killed")
@@ -55,17 +59,23 @@ const (
 )
 
 // ErrDatabaseNotFound returns a database not found error for the given database name.
-func ErrDatabaseNotFound(name string) error { return fmt.Errorf("database not found: %s", name) }
+func ErrDatabaseNotFound(name string) error { return influxdb.ErrDatabaseNotFound(name) }
 
 // ErrMaxSelectPointsLimitExceeded is an error when a query hits the maximum number of points.
 func ErrMaxSelectPointsLimitExceeded(n, limit int) error {
-	return fmt.Errorf("max-select-point limit exceeed: (%d/%d)", n, limit)
+	return influxdb.NewError(influxdb.ErrorCodeLimitExceeded, fmt.Sprintf("max-select-point limit exceeed: (%d/%d)", n, limit))
+}
+
+// ErrQueryMemoryLimitExceeded is an error when the results held by a query
+// exceed the maximum memory of a query.
+func ErrQueryMemoryLimitExceeded(n, limit int64) error {
+	return influxdb.NewError(influxdb.ErrorCodeLimitExceeded, fmt.Sprintf("query exceeded memory limit (%d/%d bytes)", n, limit))
 }
 
 // ErrMaxConcurrentQueriesLimitExceeded is an error when a query cannot be run
 // because the maximum number of queries has been reached.
 func ErrMaxConcurrentQueriesLimitExceeded(n, limit int) error {
-	return fmt.Errorf("max-concurrent-queries limit exceeded(%d, %d)", n, limit)
+	return influxdb.NewError(influxdb.ErrorCodeLimitExceeded, fmt.Sprintf("max-concurrent-queries limit exceeded(%d, %d)", n, limit))
 }
 
 // Authorizer reports whether certain operations are authorized.
@@ -123,8 +133,33 @@ type ExecutionOptions struct {
 	// Quiet suppresses non-essential output from the query executor.
 	Quiet bool
 
+	// IncludeStats attaches execution statistics to the last result
+	// of each SELECT statement.
+	IncludeStats bool
+
 	// AbortCh is a channel that signals when results are no longer desired by the caller.
 	AbortCh <-chan struct{}
+
+	// AbortOnError stops executing the remaining statements in the query
+	// after the first statement that returns an error.
+	AbortOnError bool
+
+	// Priority is the scheduling class of the query.
+	Priority Priority
+
+	// Timeout is the maximum time the query may run. It can only shorten
+	// the QueryTimeout of the TaskManager. If zero, QueryTimeout applies.
+	Timeout time.Duration
+
+	// DryRun reports the data that DELETE, DROP MEASUREMENT and DROP SERIES
+	// statements would remove instead of removing it. Other statements that
+	// modify the server are refused.
+	DryRun bool
+
+	// Streaming is set when the caller returns each result as soon as it
+	// is received instead of holding the results in memory. The results of
+	// a streaming query do not count toward MaxQueryMemory.
+	Streaming bool
 }
 
 // ExecutionContext contains state that the query is currently executing with.
@@ -165,6 +200,10 @@ func (ctx *ExecutionContext) send(result *Result) error {
 // Send sends a Result to the Results channel and will exit if the query has
 // been interrupted or aborted.
 func (ctx *ExecutionContext) Send(result *Result) error {
+	if err := ctx.Query.allocate(result); err != nil {
+		return err
+	}
+
 	select {
 	case <-ctx.InterruptCh:
 		return ErrQueryInterrupted
@@ -221,6 +260,12 @@ type QueryExecutor struct {
 	// Defaults to discarding all log output.
 	Logger zap.Logger
 
+	// DisabledStatements are the kinds of statements, such as "DROP DATABASE"
+	// or "DELETE", that can only be executed by the admin users named in
+	// DisabledStatementsExemptUsers.
+	DisabledStatements            []string
+	DisabledStatementsExemptUsers []string
+
 	// expvar-based stats.
 	stats *QueryStatistics
 }
@@ -289,7 +334,8 @@ func (e *QueryExecutor) executeQuery(query *influxql.Query, opt ExecutionOptions
 		atomic.AddInt64(&e.stats.QueryExecutionDuration, time.Since(start).Nanoseconds())
 	}(time.Now())
 
-	qid, task, err := e.TaskManager.AttachQuery(query, opt.Database, closing)
+	timeout := e.TaskManager.queryTimeout(opt.Timeout)
+	qid, task, err := e.TaskManager.attachQuery(query, opt.Database, opt.Priority, timeout, closing)
 	if err != nil {
 		select {
 		case results <- &Result{Err: err}:
@@ -299,6 +345,10 @@ func (e *QueryExecutor) executeQuery(query *influxql.Query, opt ExecutionOptions
 	}
 	defer e.TaskManager.DetachQuery(qid)
 
+	if !opt.Streaming {
+		task.maxMemory = e.TaskManager.MaxQueryMemory
+	}
+
 	// Setup the execution context that will be used when executing statements.
 	ctx := ExecutionContext{
 		QueryID:          qid,
@@ -310,7 +360,6 @@ func (e *QueryExecutor) executeQuery(query *influxql.Query, opt ExecutionOptions
 	}
 
 	var i int
-LOOP:
 	for ; i < len(query.Statements); i++ {
 		ctx.StatementID = i
 		stmt := query.Statements[i]
@@ -323,80 +372,21 @@ LOOP:
 			}
 		}
 
-		// Do not let queries manually use the system measurements. If we find
-		// one, return an error. This prevents a person from using the
-		// measurement incorrectly and causing a panic.
-		if stmt, ok := stmt.(*influxql.SelectStatement); ok {
-			for _, s := range stmt.Sources {
-				switch s := s.(type) {
-				case *influxql.Measurement:
-					if influxql.IsSystemName(s.Name) {
-						command := "the appropriate meta command"
-						switch s.Name {
-						case "_fieldKeys":
-							command = "SHOW FIELD KEYS"
-						case "_measurements":
-							command = "SHOW MEASUREMENTS"
-						case "_series":
-							command = "SHOW SERIES"
-						case "_tagKeys":
-							command = "SHOW TAG KEYS"
-						case "_tags":
-							command = "SHOW TAG VALUES"
-						}
-						results <- &Result{
-							Err: fmt.Errorf("unable to use system source '%s': use %s instead", s.Name, command),
-						}
-						break LOOP
-					}
-				}
-			}
-		}
-
-		// Rewrite statements, if necessary.
-		// This can occur on meta read statements which convert to SELECT statements.
-		newStmt, err := RewriteStatement(stmt)
-		if err != nil {
-			results <- &Result{Err: err}
-			break
-		}
-		stmt = newStmt
-
-		// Normalize each statement if possible.
-		if normalizer, ok := e.StatementExecutor.(StatementNormalizer); ok {
-			if err := normalizer.NormalizeStatement(stmt, defaultDB); err != nil {
-				if err := ctx.send(&Result{Err: err}); err == ErrQueryAborted {
-					return
-				}
-				break
-			}
-		}
-
-		// Log each normalized statement.
-		if !ctx.Quiet {
-			e.Logger.Info(stmt.String())
-		}
-
-		// Send any other statements to the underlying statement executor.
-		err = e.StatementExecutor.ExecuteStatement(stmt, ctx)
-		if err == ErrQueryInterrupted {
-			// Query was interrupted so retrieve the real interrupt error from
-			// the query task if there is one.
-			if qerr := task.Error(); qerr != nil {
-				err = qerr
-			}
-		}
-
 		// Send an error for this result if it failed for some reason.
-		if err != nil {
+		if err := e.executeStatement(stmt, defaultDB, ctx); err != nil {
 			if err := ctx.send(&Result{
 				StatementID: i,
 				Err:         err,
 			}); err == ErrQueryAborted {
 				return
 			}
-			// Stop after the first error.
-			break
+
+			// Stop after the first error if requested by the caller.
+			// Otherwise, each statement reports its own error.
+			if opt.AbortOnError {
+				i++
+				break
+			}
 		}
 
 		// Check if the query was interrupted during an uninterruptible statement.
@@ -411,12 +401,13 @@ LOOP:
 		}
 
 		if interrupted {
+			i++
 			break
 		}
 	}
 
 	// Send error results for any statements which were not executed.
-	for ; i < len(query.Statements)-1; i++ {
+	for ; i < len(query.Statements); i++ {
 		if err := ctx.send(&Result{
 			StatementID: i,
 			Err:         ErrNotExecuted,
@@ -426,6 +417,184 @@ LOOP:
 	}
 }
 
+// Authorize returns an error if stmt is one of the disabled kinds of
+// statements and auth is not an exempt admin user. When authentication is
+// disabled, nobody is exempt.
+func (e *QueryExecutor) Authorize(stmt influxql.Statement, auth Authorizer) error {
+	kind := disabledStatement(stmt, e.DisabledStatements)
+	if kind == "" {
+		return nil
+	}
+
+	if u, ok := auth.(interface {
+		ID() string
+		IsAdmin() bool
+	}); ok && u.IsAdmin() {
+		for _, name := range e.DisabledStatementsExemptUsers {
+			if name == u.ID() {
+				return nil
+			}
+		}
+	}
+	return influxdb.NewError(influxdb.ErrorCodeUnauthorized, fmt.Sprintf("%s statements are disabled", kind))
+}
+
+// statementKinds are the kinds of statements, by statement type, that can be
+// disabled.
+var statementKinds = map[reflect.Type]string{
+	reflect.TypeOf(&influxql.AlterRetentionPolicyStatement{}):       "ALTER RETENTION POLICY",
+	reflect.TypeOf(&influxql.CreateContinuousQueryStatement{}):      "CREATE CONTINUOUS QUERY",
+	reflect.TypeOf(&influxql.CreateDatabaseStatement{}):             "CREATE DATABASE",
+	reflect.TypeOf(&influxql.CreateRetentionPolicyStatement{}):      "CREATE RETENTION POLICY",
+	reflect.TypeOf(&influxql.CreateSubscriptionStatement{}):         "CREATE SUBSCRIPTION",
+	reflect.TypeOf(&influxql.CreateUserStatement{}):                 "CREATE USER",
+	reflect.TypeOf(&influxql.DeleteSeriesStatement{}):               "DELETE",
+	reflect.TypeOf(&influxql.DeleteStatement{}):                     "DELETE",
+	reflect.TypeOf(&influxql.DropContinuousQueryStatement{}):        "DROP CONTINUOUS QUERY",
+	reflect.TypeOf(&influxql.DropDatabaseStatement{}):               "DROP DATABASE",
+	reflect.TypeOf(&influxql.DropMeasurementStatement{}):            "DROP MEASUREMENT",
+	reflect.TypeOf(&influxql.DropRetentionPolicyStatement{}):        "DROP RETENTION POLICY",
+	reflect.TypeOf(&influxql.DropSeriesStatement{}):                 "DROP SERIES",
+	reflect.TypeOf(&influxql.DropShardStatement{}):                  "DROP SHARD",
+	reflect.TypeOf(&influxql.DropSubscriptionStatement{}):           "DROP SUBSCRIPTION",
+	reflect.TypeOf(&influxql.DropUserStatement{}):                   "DROP USER",
+	reflect.TypeOf(&influxql.ExplainStatement{}):                    "EXPLAIN",
+	reflect.TypeOf(&influxql.GrantStatement{}):                      "GRANT",
+	reflect.TypeOf(&influxql.GrantAdminStatement{}):                 "GRANT",
+	reflect.TypeOf(&influxql.KillQueryStatement{}):                  "KILL QUERY",
+	reflect.TypeOf(&influxql.RevokeStatement{}):                     "REVOKE",
+	reflect.TypeOf(&influxql.RevokeAdminStatement{}):                "REVOKE",
+	reflect.TypeOf(&influxql.SelectStatement{}):                     "SELECT",
+	reflect.TypeOf(&influxql.SetPasswordUserStatement{}):            "SET PASSWORD",
+	reflect.TypeOf(&influxql.ShowContinuousQueriesStatement{}):      "SHOW CONTINUOUS QUERIES",
+	reflect.TypeOf(&influxql.ShowDatabasesStatement{}):              "SHOW DATABASES",
+	reflect.TypeOf(&influxql.ShowDiagnosticsStatement{}):            "SHOW DIAGNOSTICS",
+	reflect.TypeOf(&influxql.ShowFieldKeyCardinalityStatement{}):    "SHOW FIELD KEY CARDINALITY",
+	reflect.TypeOf(&influxql.ShowFieldKeysStatement{}):              "SHOW FIELD KEYS",
+	reflect.TypeOf(&influxql.ShowGrantsForUserStatement{}):          "SHOW GRANTS",
+	reflect.TypeOf(&influxql.ShowMeasurementCardinalityStatement{}): "SHOW MEASUREMENT CARDINALITY",
+	reflect.TypeOf(&influxql.ShowMeasurementsStatement{}):           "SHOW MEASUREMENTS",
+	reflect.TypeOf(&influxql.ShowQueriesStatement{}):                "SHOW QUERIES",
+	reflect.TypeOf(&influxql.ShowRetentionPoliciesStatement{}):      "SHOW RETENTION POLICIES",
+	reflect.TypeOf(&influxql.ShowSeriesCardinalityStatement{}):      "SHOW SERIES CARDINALITY",
+	reflect.TypeOf(&influxql.ShowSeriesStatement{}):                 "SHOW SERIES",
+	reflect.TypeOf(&influxql.ShowShardGroupsStatement{}):            "SHOW SHARD GROUPS",
+	reflect.TypeOf(&influxql.ShowShardsStatement{}):                 "SHOW SHARDS",
+	reflect.TypeOf(&influxql.ShowStatsStatement{}):                  "SHOW STATS",
+	reflect.TypeOf(&influxql.ShowSubscriptionsStatement{}):          "SHOW SUBSCRIPTIONS",
+	reflect.TypeOf(&influxql.ShowTagKeyCardinalityStatement{}):      "SHOW TAG KEY CARDINALITY",
+	reflect.TypeOf(&influxql.ShowTagKeysStatement{}):                "SHOW TAG KEYS",
+	reflect.TypeOf(&influxql.ShowTagValuesCardinalityStatement{}):   "SHOW TAG VALUES CARDINALITY",
+	reflect.TypeOf(&influxql.ShowTagValuesStatement{}):              "SHOW TAG VALUES",
+	reflect.TypeOf(&influxql.ShowUsersStatement{}):                  "SHOW USERS",
+}
+
+// normalizeStatementKind returns kind in upper case with single spaces.
+func normalizeStatementKind(kind string) string {
+	return strings.Join(strings.Fields(strings.ToUpper(kind)), " ")
+}
+
+// ValidateStatementKinds returns an error if any of kinds is not a kind of
+// statement that can be disabled, such as "DROP DATABASE".
+func ValidateStatementKinds(kinds []string) error {
+	known := make(map[string]struct{}, len(statementKinds))
+	for _, kind := range statementKinds {
+		known[kind] = struct{}{}
+	}
+
+	for _, kind := range kinds {
+		if _, ok := known[normalizeStatementKind(kind)]; !ok {
+			return fmt.Errorf("unknown statement kind: %q", kind)
+		}
+	}
+	return nil
+}
+
+// disabledStatement returns the kind in disabled that stmt is, or an empty
+// string if it is none of them.
+func disabledStatement(stmt influxql.Statement, disabled []string) string {
+	if len(disabled) == 0 {
+		return ""
+	}
+
+	kind, ok := statementKinds[reflect.TypeOf(stmt)]
+	if !ok {
+		return ""
+	}
+	for _, k := range disabled {
+		if normalizeStatementKind(k) == kind {
+			return kind
+		}
+	}
+	return ""
+}
+
+// executeStatement prepares a single statement and passes it to the
+// underlying statement executor.
+func (e *QueryExecutor) executeStatement(stmt influxql.Statement, defaultDB string, ctx ExecutionContext) error {
+	if err := e.Authorize(stmt, ctx.Authorizer); err != nil {
+		return err
+	}
+
+	// Do not let queries manually use the system measurements. If we find
+	// one, return an error. This prevents a person from using the
+	// measurement incorrectly and causing a panic.
+	if stmt, ok := stmt.(*influxql.SelectStatement); ok {
+		for _, s := range stmt.Sources {
+			switch s := s.(type) {
+			case *influxql.Measurement:
+				if influxql.IsSystemName(s.Name) {
+					command := "the appropriate meta command"
+					switch s.Name {
+					case "_fieldKeys":
+						command = "SHOW FIELD KEYS"
+					case "_measurements":
+						command = "SHOW MEASUREMENTS"
+					case "_series":
+						command = "SHOW SERIES"
+					case "_tagKeys":
+						command = "SHOW TAG KEYS"
+					case "_tags":
+						command = "SHOW TAG VALUES"
+					}
+					return fmt.Errorf("unable to use system source '%s': use %s instead", s.Name, command)
+				}
+			}
+		}
+	}
+
+	// Rewrite statements, if necessary.
+	// This can occur on meta read statements which convert to SELECT statements.
+	newStmt, err := RewriteStatement(stmt)
+	if err != nil {
+		return err
+	}
+	stmt = newStmt
+
+	// Normalize each statement if possible.
+	if normalizer, ok := e.StatementExecutor.(StatementNormalizer); ok {
+		if err := normalizer.NormalizeStatement(stmt, defaultDB); err != nil {
+			return err
+		}
+	}
+
+	// Log each normalized statement.
+	if !ctx.Quiet {
+		e.Logger.Info(stmt.String())
+	}
+
+	// Send any other statements to the underlying statement executor.
+	err = e.StatementExecutor.ExecuteStatement(stmt, ctx)
+	if err == ErrQueryInterrupted {
+		// Query was interrupted so retrieve the real interrupt error from
+		// the query task if there is one.
+		if qerr := ctx.Query.Error(); qerr != nil {
+			err = qerr
+		}
+	}
+	return err
+}
+
 // Determines if the QueryExecutor will recover any panics or let them crash
 // the server.
 var willCrash bool
@@ -463,10 +632,17 @@ type QueryMonitorFunc func(<-chan struct{}) error
 // QueryTask is the internal data structure for managing queries.
 // For the public use data structure that gets returned, see QueryTask.
 type QueryTask struct {
+	// Estimated memory of the results sent by the query and its limit.
+	// Accessed atomically.
+	memoryN   int64
+	maxMemory int64
+
 	query     string
 	database  string
+	priority  Priority
 	status    TaskStatus
 	startTime time.Time
+	progress  string
 	closing   chan struct{}
 	monitorCh chan error
 	err       error
@@ -489,6 +665,35 @@ func (q *QueryTask) Error() error {
 	return q.err
 }
 
+// SetProgress sets the progress of a long running statement of the query
+// that is reported by SHOW QUERIES.
+func (q *QueryTask) SetProgress(progress string) {
+	if q == nil {
+		return
+	}
+	q.mu.Lock()
+	q.progress = progress
+	q.mu.Unlock()
+}
+
+// allocate adds the size of a result to the memory held by the query. It
+// returns an error once the query holds more than its maximum memory.
+func (q *QueryTask) allocate(r *Result) error {
+	if q == nil || q.maxMemory <= 0 {
+		return nil
+	}
+	if n := atomic.AddInt64(&q.memoryN, r.size()); n > q.maxMemory {
+		return ErrQueryMemoryLimitExceeded(n, q.maxMemory)
+	}
+	return nil
+}
+
+func (q *QueryTask) getProgress() string {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.progress
+}
+
 func (q *QueryTask) setError(err error) {
 	q.mu.Lock()
 	q.err = err
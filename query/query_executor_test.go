@@ -7,6 +7,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/influxdata/influxdb/models"
 	"github.com/influxdata/influxdb/query"
 	"github.com/influxdata/influxql"
 )
@@ -366,6 +367,37 @@ func TestQueryExecutor_Limit_Timeout(t *testing.T) {
 	}
 }
 
+func TestQueryExecutor_Limit_Timeout_PerQuery(t *testing.T) {
+	q, err := influxql.ParseQuery(`SELECT count(value) FROM cpu`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e := NewQueryExecutor()
+	e.StatementExecutor = &StatementExecutor{
+		ExecuteStatementFn: func(stmt influxql.Statement, ctx query.ExecutionContext) error {
+			select {
+			case <-ctx.InterruptCh:
+				return query.ErrQueryInterrupted
+			case <-time.After(time.Second):
+				t.Errorf("timeout has not killed the query")
+				return errUnexpected
+			}
+		},
+	}
+	e.TaskManager.QueryTimeout = time.Hour
+
+	// The timeout of the query shortens the timeout of the executor.
+	results := e.ExecuteQuery(q, query.ExecutionOptions{Timeout: time.Millisecond}, nil)
+	result := <-results
+	if result.Err == nil || !strings.Contains(result.Err.Error(), "query-timeout") {
+		t.Errorf("unexpected error: %s", result.Err)
+	}
+	if _, ok := <-results; ok {
+		t.Error("expected results to be closed")
+	}
+}
+
 func TestQueryExecutor_Limit_ConcurrentQueries(t *testing.T) {
 	q, err := influxql.ParseQuery(`SELECT count(value) FROM cpu`)
 	if err != nil {
@@ -405,6 +437,141 @@ func TestQueryExecutor_Limit_ConcurrentQueries(t *testing.T) {
 	}
 }
 
+func TestQueryExecutor_Limit_Memory(t *testing.T) {
+	q, err := influxql.ParseQuery(`SELECT * FROM cpu`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e := NewQueryExecutor()
+	e.StatementExecutor = &StatementExecutor{
+		ExecuteStatementFn: func(stmt influxql.Statement, ctx query.ExecutionContext) error {
+			for {
+				if err := ctx.Send(&query.Result{Series: models.Rows{{
+					Name:    "cpu",
+					Columns: []string{"time", "value"},
+					Values:  [][]interface{}{{time.Unix(0, 0), "aaaaaaaaaa"}},
+				}}}); err != nil {
+					return err
+				}
+			}
+		},
+	}
+	e.TaskManager.MaxQueryMemory = 1000
+	defer e.Close()
+
+	var n int
+	for result := range e.ExecuteQuery(q, query.ExecutionOptions{}, nil) {
+		if result.Err != nil {
+			if !strings.Contains(result.Err.Error(), "query exceeded memory limit") {
+				t.Errorf("unexpected error: %s", result.Err)
+			}
+			break
+		}
+		n++
+	}
+
+	// Each result is estimated at 86 bytes.
+	if n != 11 {
+		t.Errorf("unexpected number of results: %d", n)
+	}
+
+	// Streaming queries are not limited.
+	closing := make(chan struct{})
+	results := e.ExecuteQuery(q, query.ExecutionOptions{Streaming: true}, closing)
+	for i := 0; i < 100; i++ {
+		if result := <-results; result.Err != nil {
+			t.Fatalf("unexpected error: %s", result.Err)
+		}
+	}
+	close(closing)
+	discardOutput(results)
+}
+
+func TestQueryExecutor_Limit_ConcurrentBatchQueries(t *testing.T) {
+	q, err := influxql.ParseQuery(`SELECT count(value) FROM cpu`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	qid := make(chan uint64)
+	done := make(chan struct{})
+
+	e := NewQueryExecutor()
+	e.StatementExecutor = &StatementExecutor{
+		ExecuteStatementFn: func(stmt influxql.Statement, ctx query.ExecutionContext) error {
+			qid <- ctx.QueryID
+			<-done
+			return nil
+		},
+	}
+	e.TaskManager.MaxConcurrentBatchQueries = 1
+	defer e.Close()
+
+	// Start a batch query and wait for it to be executing.
+	batch := query.ExecutionOptions{Priority: query.BatchPriority}
+	go discardOutput(e.ExecuteQuery(q, batch, nil))
+	<-qid
+
+	// An interactive query is not limited by the batch queries.
+	go discardOutput(e.ExecuteQuery(q, query.ExecutionOptions{}, nil))
+	<-qid
+
+	// A second batch query waits for the first batch query to finish.
+	go discardOutput(e.ExecuteQuery(q, batch, nil))
+	select {
+	case <-qid:
+		t.Fatal("unexpected statement execution for the second batch query")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	done <- struct{}{}
+	select {
+	case <-qid:
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for the second batch query")
+	}
+	close(done)
+}
+
+func TestQueryExecutor_Limit_ConcurrentBatchQueries_Interrupt(t *testing.T) {
+	q, err := influxql.ParseQuery(`SELECT count(value) FROM cpu`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	qid := make(chan uint64)
+
+	e := NewQueryExecutor()
+	e.StatementExecutor = &StatementExecutor{
+		ExecuteStatementFn: func(stmt influxql.Statement, ctx query.ExecutionContext) error {
+			qid <- ctx.QueryID
+			<-ctx.InterruptCh
+			return query.ErrQueryInterrupted
+		},
+	}
+	e.TaskManager.MaxConcurrentQueries = 1
+	defer e.Close()
+
+	// Fill the only query slot.
+	go discardOutput(e.ExecuteQuery(q, query.ExecutionOptions{}, nil))
+	<-qid
+
+	// A batch query waits instead of failing and can be interrupted while waiting.
+	closing := make(chan struct{})
+	results := e.ExecuteQuery(q, query.ExecutionOptions{Priority: query.BatchPriority}, closing)
+	close(closing)
+
+	select {
+	case result := <-results:
+		if result.Err != query.ErrQueryInterrupted {
+			t.Errorf("unexpected error: %s", result.Err)
+		}
+	case <-qid:
+		t.Errorf("unexpected statement execution for the batch query")
+	}
+}
+
 func TestQueryExecutor_Close(t *testing.T) {
 	q, err := influxql.ParseQuery(`SELECT count(value) FROM cpu`)
 	if err != nil {
@@ -528,6 +695,104 @@ func TestQueryExecutor_InvalidSource(t *testing.T) {
 	}
 }
 
+func TestQueryExecutor_StatementError(t *testing.T) {
+	q, err := influxql.ParseQuery(`SELECT count(value) FROM cpu; SELECT count(value) FROM mem; SELECT count(value) FROM disk`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e := NewQueryExecutor()
+	e.StatementExecutor = &StatementExecutor{
+		ExecuteStatementFn: func(stmt influxql.Statement, ctx query.ExecutionContext) error {
+			if ctx.StatementID == 1 {
+				return errUnexpected
+			}
+			ctx.Results <- &query.Result{StatementID: ctx.StatementID}
+			return nil
+		},
+	}
+
+	for _, tt := range []struct {
+		name         string
+		abortOnError bool
+		errs         []error
+	}{
+		{name: "Continue", errs: []error{nil, errUnexpected, nil}},
+		{name: "AbortOnError", abortOnError: true, errs: []error{nil, errUnexpected, query.ErrNotExecuted}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var results []*query.Result
+			for r := range e.ExecuteQuery(q, query.ExecutionOptions{AbortOnError: tt.abortOnError}, nil) {
+				results = append(results, r)
+			}
+
+			if len(results) != len(tt.errs) {
+				t.Fatalf("unexpected number of results: %d", len(results))
+			}
+			for i, r := range results {
+				if r.StatementID != i {
+					t.Errorf("%d. unexpected statement id: %d", i, r.StatementID)
+				}
+				if r.Err != tt.errs[i] {
+					t.Errorf("%d. unexpected error: %v", i, r.Err)
+				}
+			}
+		})
+	}
+}
+
+// User is an Authorizer with a name and admin privilege.
+type User struct {
+	query.OpenAuthorizer
+	Name  string
+	Admin bool
+}
+
+func (u *User) ID() string    { return u.Name }
+func (u *User) IsAdmin() bool { return u.Admin }
+
+func TestQueryExecutor_DisabledStatements(t *testing.T) {
+	e := NewQueryExecutor()
+	e.DisabledStatements = []string{"drop database", "DELETE", "DROP SERIES", "DROP DATABSE", "SHOW"}
+	e.DisabledStatementsExemptUsers = []string{"root", "alice"}
+	e.StatementExecutor = &StatementExecutor{
+		ExecuteStatementFn: func(stmt influxql.Statement, ctx query.ExecutionContext) error {
+			ctx.Results <- &query.Result{StatementID: ctx.StatementID}
+			return nil
+		},
+	}
+
+	for _, tt := range []struct {
+		name string
+		s    string
+		auth query.Authorizer
+		err  string
+	}{
+		{name: "ExemptAdmin", s: `DROP DATABASE db0`, auth: &User{Name: "root", Admin: true}},
+		{name: "Admin", s: `DROP DATABASE db0`, auth: &User{Name: "bob", Admin: true}, err: "DROP DATABASE statements are disabled"},
+		{name: "ExemptNonAdmin", s: `DELETE FROM cpu`, auth: &User{Name: "alice"}, err: "DELETE statements are disabled"},
+		{name: "AuthDisabled", s: `DROP SERIES FROM cpu`, auth: query.OpenAuthorizer{}, err: "DROP SERIES statements are disabled"},
+		{name: "Enabled", s: `DROP MEASUREMENT cpu`, auth: query.OpenAuthorizer{}},
+		{name: "Select", s: `SELECT value FROM "delete"`, auth: query.OpenAuthorizer{}},
+		{name: "ShowDatabases", s: `SHOW DATABASES`, auth: query.OpenAuthorizer{}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := influxql.ParseQuery(tt.s)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for r := range e.ExecuteQuery(q, query.ExecutionOptions{Authorizer: tt.auth}, nil) {
+				if tt.err == "" && r.Err != nil {
+					t.Errorf("unexpected error: %s", r.Err)
+				} else if tt.err != "" && (r.Err == nil || r.Err.Error() != tt.err) {
+					t.Errorf("unexpected error: %v", r.Err)
+				}
+			}
+		})
+	}
+}
+
 func discardOutput(results <-chan *query.Result) {
 	for range results {
 		// Read all results and discard.
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 
+	"github.com/influxdata/influxdb"
 	"github.com/influxdata/influxdb/models"
 	"github.com/influxdata/influxql"
 )
@@ -81,6 +82,22 @@ func ReadOnlyWarning(stmt string) *Message {
 	}
 }
 
+// ResultStats contains statistics about the execution of a statement.
+type ResultStats struct {
+	// Number of series and values returned to the client, or written by
+	// a SELECT INTO statement.
+	SeriesN int `json:"series"`
+	ValuesN int `json:"values"`
+
+	// Number of series and points read by the underlying iterators.
+	ScannedSeriesN int `json:"scanned_series"`
+	ScannedPointN  int `json:"scanned_points"`
+
+	// Truncated is set when the output reached a LIMIT or SLIMIT of the
+	// statement, or the row limit of the server, so data may be missing.
+	Truncated bool `json:"truncated,omitempty"`
+}
+
 // Result represents a resultset returned from a single statement.
 // Rows represents a list of rows that can be sorted consistently by name/tag.
 type Result struct {
@@ -91,6 +108,37 @@ type Result struct {
 	Messages    []*Message
 	Partial     bool
 	Err         error
+
+	// Stats holds execution statistics for the statement. It is only set
+	// on the last result of a statement when requested by the caller.
+	Stats *ResultStats
+}
+
+// resultValueSize is the estimated memory used by a value of a result in
+// addition to the data of a string, for the interface and the boxed value.
+const resultValueSize = 32
+
+// size returns the estimated memory used by the series of the result.
+func (r *Result) size() int64 {
+	var n int64
+	for _, row := range r.Series {
+		n += int64(len(row.Name))
+		for k, v := range row.Tags {
+			n += int64(len(k) + len(v))
+		}
+		for _, c := range row.Columns {
+			n += int64(len(c))
+		}
+		for _, values := range row.Values {
+			n += int64(len(values)) * resultValueSize
+			for _, v := range values {
+				if s, ok := v.(string); ok {
+					n += int64(len(s))
+				}
+			}
+		}
+	}
+	return n
 }
 
 // MarshalJSON encodes the result into JSON.
@@ -101,7 +149,9 @@ func (r *Result) MarshalJSON() ([]byte, error) {
 		Series      []*models.Row `json:"series,omitempty"`
 		Messages    []*Message    `json:"messages,omitempty"`
 		Partial     bool          `json:"partial,omitempty"`
+		Stats       *ResultStats  `json:"stats,omitempty"`
 		Err         string        `json:"error,omitempty"`
+		Code        string        `json:"code,omitempty"`
 	}
 
 	// Copy fields to output struct.
@@ -109,8 +159,10 @@ func (r *Result) MarshalJSON() ([]byte, error) {
 	o.Series = r.Series
 	o.Messages = r.Messages
 	o.Partial = r.Partial
+	o.Stats = r.Stats
 	if r.Err != nil {
 		o.Err = r.Err.Error()
+		o.Code = string(influxdb.ErrorCodeOf(r.Err))
 	}
 
 	return json.Marshal(&o)
@@ -123,7 +175,9 @@ func (r *Result) UnmarshalJSON(b []byte) error {
 		Series      []*models.Row `json:"series,omitempty"`
 		Messages    []*Message    `json:"messages,omitempty"`
 		Partial     bool          `json:"partial,omitempty"`
+		Stats       *ResultStats  `json:"stats,omitempty"`
 		Err         string        `json:"error,omitempty"`
+		Code        string        `json:"code,omitempty"`
 	}
 
 	err := json.Unmarshal(b, &o)
@@ -134,8 +188,13 @@ func (r *Result) UnmarshalJSON(b []byte) error {
 	r.Series = o.Series
 	r.Messages = o.Messages
 	r.Partial = o.Partial
+	r.Stats = o.Stats
 	if o.Err != "" {
-		r.Err = errors.New(o.Err)
+		if o.Code != "" {
+			r.Err = influxdb.NewError(influxdb.ErrorCode(o.Code), o.Err)
+		} else {
+			r.Err = errors.New(o.Err)
+		}
 	}
 	return nil
 }
@@ -30,6 +30,10 @@ type SelectOptions struct {
 
 	// Maximum number of buckets for a statement.
 	MaxBucketsN int
+
+	// Maximum memory an iterator uses before it spills points to SpillDir.
+	MaxMemory int64
+	SpillDir  string
 }
 
 // ShardMapper retrieves and maps shards into an IteratorCreator that can later be
@@ -0,0 +1,109 @@
+package query
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+	"os"
+)
+
+// reduceGroupOverhead is the estimated memory used by a group of a reduce
+// iterator in addition to its name and tags, for the map entry, the group
+// itself and its aggregator and emitter.
+const reduceGroupOverhead = 256
+
+// reduceSpillBatchSize is the number of points read at a time from the runs
+// of a window that was spilled to disk.
+const reduceSpillBatchSize = 1000
+
+// sortedMergeInputSize is the estimated memory used by an input of a sorted
+// merge, such as the cursors of a series and their decoded blocks.
+const sortedMergeInputSize = 64 * 1024
+
+// reduceGroupSize returns the estimated memory used by a group of a reduce
+// iterator with the given name and tags ID.
+func reduceGroupSize(name, id string) int64 {
+	return int64(len(name) + len(id) + reduceGroupOverhead)
+}
+
+// sortedMergeMaxInputs returns the number of inputs a sorted merge can merge
+// at once within maxMemory, or 0 if there is no limit.
+func sortedMergeMaxInputs(maxMemory int64) int {
+	if maxMemory <= 0 {
+		return 0
+	} else if n := maxMemory / sortedMergeInputSize; n > 2 {
+		return int(n)
+	}
+	return 2
+}
+
+// spillFile is a temporary file that an iterator writes points to when they
+// do not fit within IteratorOptions.MaxMemory. The points are written to it
+// once and then read back from the start. The file is removed when it is
+// closed.
+type spillFile struct {
+	f *os.File
+	w *bufio.Writer
+	r *bufio.Reader
+}
+
+// newSpillFile creates a spill file in dir. If dir is empty, the default
+// directory for temporary files is used.
+func newSpillFile(dir string) (*spillFile, error) {
+	f, err := ioutil.TempFile(dir, "influxdb-spill-")
+	if err != nil {
+		return nil, err
+	}
+	return &spillFile{f: f, w: bufio.NewWriter(f)}, nil
+}
+
+// Write writes p to the file.
+func (s *spillFile) Write(p []byte) (int, error) { return s.w.Write(p) }
+
+// Read reads from the file. The file must have been rewound.
+func (s *spillFile) Read(p []byte) (int, error) { return s.r.Read(p) }
+
+// rewind flushes the written data and moves the file to its start for
+// reading.
+func (s *spillFile) rewind() error {
+	if err := s.w.Flush(); err != nil {
+		return err
+	} else if _, err := s.f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	s.r = bufio.NewReader(s.f)
+	return nil
+}
+
+// Close closes and removes the file.
+func (s *spillFile) Close() error {
+	err := s.f.Close()
+	if e := os.Remove(s.f.Name()); err == nil {
+		err = e
+	}
+	return err
+}
+
+// writeSpillKey writes the group key of the point that follows it in a spill
+// file.
+func writeSpillKey(w io.Writer, key string) error {
+	if err := binary.Write(w, binary.BigEndian, uint32(len(key))); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, key)
+	return err
+}
+
+// readSpillKey reads a group key written with writeSpillKey.
+func readSpillKey(r io.Reader) (string, error) {
+	var sz uint32
+	if err := binary.Read(r, binary.BigEndian, &sz); err != nil {
+		return "", err
+	}
+	buf := make([]byte, sz)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return "", err
+	}
+	return string(buf), nil
+}
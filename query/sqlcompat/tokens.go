@@ -0,0 +1,172 @@
+package sqlcompat
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/influxdata/influxql"
+)
+
+// tokenKind is the class of a SQL token. Only the distinctions needed to
+// rewrite SQL constructs before the statement is parsed as InfluxQL are made.
+type tokenKind int
+
+const (
+	tokenSpace tokenKind = iota
+	tokenWord
+	tokenString
+	tokenIdent
+	tokenOther
+)
+
+// sqlToken is a token and the exact text it was read from so the statement
+// can be reassembled unchanged.
+type sqlToken struct {
+	kind tokenKind
+	text string
+}
+
+// tokenize splits a statement into tokens. Quoted strings and identifiers
+// are kept whole so words inside them are never rewritten.
+func tokenize(s string) []sqlToken {
+	var tokens []sqlToken
+	for i := 0; i < len(s); {
+		start := i
+		c := s[i]
+		switch {
+		case c == '\'' || c == '"':
+			// A doubled quote or a backslash escapes the quote.
+			i++
+			for i < len(s) {
+				if s[i] == '\\' {
+					i += 2
+					continue
+				} else if s[i] == c {
+					if i+1 < len(s) && s[i+1] == c {
+						i += 2
+						continue
+					}
+					i++
+					break
+				}
+				i++
+			}
+			if i > len(s) {
+				i = len(s)
+			}
+			kind := tokenString
+			if c == '"' {
+				kind = tokenIdent
+			}
+			tokens = append(tokens, sqlToken{kind: kind, text: s[start:i]})
+		case unicode.IsSpace(rune(c)):
+			for i < len(s) && unicode.IsSpace(rune(s[i])) {
+				i++
+			}
+			tokens = append(tokens, sqlToken{kind: tokenSpace, text: s[start:i]})
+		case isWordChar(c):
+			for i < len(s) && isWordChar(s[i]) {
+				i++
+			}
+			tokens = append(tokens, sqlToken{kind: tokenWord, text: s[start:i]})
+		default:
+			i++
+			tokens = append(tokens, sqlToken{kind: tokenOther, text: s[start:i]})
+		}
+	}
+	return tokens
+}
+
+func isWordChar(c byte) bool {
+	return c == '_' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+// join reassembles tokens into a statement.
+func join(tokens []sqlToken) string {
+	var buf strings.Builder
+	for _, t := range tokens {
+		buf.WriteString(t.text)
+	}
+	return buf.String()
+}
+
+// next returns the index of the first token at or after i that is not
+// whitespace, or len(tokens).
+func next(tokens []sqlToken, i int) int {
+	for i < len(tokens) && tokens[i].kind == tokenSpace {
+		i++
+	}
+	return i
+}
+
+// isWord returns true if the token at i is the keyword word.
+func isWord(tokens []sqlToken, i int, word string) bool {
+	return i < len(tokens) && tokens[i].kind == tokenWord && strings.EqualFold(tokens[i].text, word)
+}
+
+// rewrite replaces interval 'n unit' literals with InfluxQL durations and
+// the doubled quotes that escape a quote in SQL strings with backslashes.
+func rewrite(tokens []sqlToken) ([]sqlToken, error) {
+	var out []sqlToken
+	for i := 0; i < len(tokens); i++ {
+		if j := next(tokens, i+1); isWord(tokens, i, "interval") && j < len(tokens) && tokens[j].kind == tokenString {
+			d, err := parseInterval(strings.Trim(tokens[j].text, "'"))
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, sqlToken{kind: tokenWord, text: influxql.FormatDuration(d)})
+			i = j
+			continue
+		}
+
+		if t := tokens[i]; t.kind == tokenString && len(t.text) > 2 {
+			inner := t.text[1 : len(t.text)-1]
+			t.text = "'" + strings.Replace(inner, "''", `\'`, -1) + "'"
+			out = append(out, t)
+			continue
+		}
+		out = append(out, tokens[i])
+	}
+	return out, nil
+}
+
+// extractOrderBy removes the ORDER BY clause of the outer statement and
+// returns its items. InfluxQL rejects any sort field other than time while
+// parsing, so the clause is resolved after the rest of the statement.
+func extractOrderBy(tokens []sqlToken) ([]sqlToken, []sortField) {
+	depth := 0
+	for i := range tokens {
+		switch tokens[i].text {
+		case "(":
+			depth++
+		case ")":
+			depth--
+		}
+		if depth != 0 || !isWord(tokens, i, "order") || !isWord(tokens, next(tokens, i+1), "by") {
+			continue
+		}
+
+		// The clause ends at the first keyword that may follow it.
+		var fields []sortField
+		j := next(tokens, next(tokens, i+1)+1)
+		for j < len(tokens) && !isWord(tokens, j, "limit") && !isWord(tokens, j, "offset") &&
+			!isWord(tokens, j, "slimit") && !isWord(tokens, j, "soffset") && !isWord(tokens, j, "tz") && tokens[j].text != ";" {
+			switch {
+			case isWord(tokens, j, "asc"):
+			case isWord(tokens, j, "desc"):
+				if len(fields) > 0 {
+					fields[len(fields)-1].ascending = false
+				}
+			case tokens[j].kind == tokenWord:
+				fields = append(fields, sortField{name: tokens[j].text, ascending: true})
+			case tokens[j].kind == tokenIdent:
+				fields = append(fields, sortField{name: strings.Trim(tokens[j].text, `"`), ascending: true})
+			}
+			j = next(tokens, j+1)
+		}
+
+		out := append(append([]sqlToken{}, tokens[:i]...), sqlToken{kind: tokenSpace, text: " "})
+		return append(out, tokens[j:]...), fields
+	}
+	return tokens, nil
+}
@@ -0,0 +1,251 @@
+// Package sqlcompat translates a constrained, read-only SQL dialect into
+// InfluxQL so that tools with generic SQL connectors can query the database.
+//
+// The dialect is the InfluxQL SELECT statement with these SQL additions:
+//
+//   - time_bucket(width, time) and date_trunc(unit, time) select and group by
+//     fixed time intervals, and may be referred to by alias or position in the
+//     GROUP BY and ORDER BY clauses.
+//   - interval 'n unit' literals, such as interval '5 minutes'.
+//   - Columns that are also listed in GROUP BY are returned as tags.
+//   - avg() is an alias of mean().
+//
+// Only a single SELECT statement without an INTO clause is accepted.
+package sqlcompat
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/influxdata/influxql"
+)
+
+// Translate parses a SQL SELECT statement and returns the equivalent InfluxQL
+// statement.
+func Translate(sql string) (*influxql.SelectStatement, error) {
+	tokens := tokenize(sql)
+	tokens, err := rewrite(tokens)
+	if err != nil {
+		return nil, err
+	}
+	tokens, sortFields := extractOrderBy(tokens)
+
+	q, err := influxql.ParseQuery(join(tokens))
+	if err != nil {
+		return nil, err
+	} else if len(q.Statements) != 1 {
+		return nil, errors.New("exactly one statement is required")
+	}
+	sel, ok := q.Statements[0].(*influxql.SelectStatement)
+	if !ok {
+		return nil, errors.New("only SELECT statements are supported")
+	} else if sel.Target != nil {
+		return nil, errors.New("SELECT INTO is not supported")
+	}
+
+	t := &translator{stmt: sel}
+	if err := t.translate(sortFields); err != nil {
+		return nil, err
+	}
+
+	// Parse the translated statement again so it is initialized exactly as
+	// if it had been written in InfluxQL.
+	stmt, err := influxql.ParseStatement(sel.String())
+	if err != nil {
+		return nil, err
+	}
+	return stmt.(*influxql.SelectStatement), nil
+}
+
+// sortField is an item of the ORDER BY clause before it is resolved against
+// the select list.
+type sortField struct {
+	name      string
+	ascending bool
+}
+
+// translator rewrites the SQL constructs of a parsed statement.
+type translator struct {
+	stmt *influxql.SelectStatement
+
+	// interval is the width of the time bucket. bucket is the alias and
+	// position of the bucket in the select list.
+	interval time.Duration
+	bucket   string
+	position int
+}
+
+func (t *translator) translate(sortFields []sortField) error {
+	fields := t.stmt.Fields
+	t.stmt.Fields = nil
+
+	// Find the time bucket in the select list and drop it; InfluxQL always
+	// returns the time column.
+	for i, f := range fields {
+		if d, ok, err := bucketInterval(f.Expr); err != nil {
+			return err
+		} else if ok {
+			if t.interval != 0 {
+				return errors.New("only one time bucket may be selected")
+			}
+			t.interval, t.bucket, t.position = d, f.Alias, i+1
+			continue
+		}
+		t.stmt.Fields = append(t.stmt.Fields, f)
+	}
+
+	// Resolve the GROUP BY clause. The time bucket becomes a time()
+	// dimension and positions refer to the original select list.
+	var dimensions influxql.Dimensions
+	var bucketGrouped bool
+	grouped := make(map[string]struct{})
+	for _, dim := range t.stmt.Dimensions {
+		expr, isBucket := dim.Expr, false
+		if lit, ok := expr.(*influxql.IntegerLiteral); ok {
+			if lit.Val < 1 || int(lit.Val) > len(fields) {
+				return fmt.Errorf("GROUP BY position %d is not in select list", lit.Val)
+			}
+			expr, isBucket = fields[lit.Val-1].Expr, int(lit.Val) == t.position
+		}
+
+		if d, ok, err := bucketInterval(expr); err != nil {
+			return err
+		} else if ok && t.interval != 0 && d != t.interval {
+			return errors.New("GROUP BY time bucket does not match the select list")
+		} else if ok {
+			t.interval, isBucket = d, true
+		} else if ref, ok := expr.(*influxql.VarRef); ok && t.bucket != "" && ref.Val == t.bucket {
+			isBucket = true
+		}
+
+		if isBucket {
+			if bucketGrouped {
+				return errors.New("the time bucket may only be grouped once")
+			}
+			bucketGrouped = true
+			dimensions = append(dimensions, &influxql.Dimension{Expr: &influxql.Call{
+				Name: "time",
+				Args: []influxql.Expr{&influxql.DurationLiteral{Val: t.interval}},
+			}})
+			continue
+		}
+
+		if ref, ok := expr.(*influxql.VarRef); ok {
+			grouped[ref.Val] = struct{}{}
+		}
+		dimensions = append(dimensions, &influxql.Dimension{Expr: expr})
+	}
+	if t.position != 0 && !bucketGrouped {
+		return errors.New("a selected time bucket must be listed in GROUP BY")
+	}
+	t.stmt.Dimensions = dimensions
+
+	// Grouped columns are returned as tags of each series.
+	selected := t.stmt.Fields
+	t.stmt.Fields = nil
+	for _, f := range selected {
+		if ref, ok := f.Expr.(*influxql.VarRef); ok {
+			if _, ok := grouped[ref.Val]; ok {
+				continue
+			}
+		}
+		influxql.WalkFunc(f.Expr, func(n influxql.Node) {
+			if call, ok := n.(*influxql.Call); ok && strings.ToLower(call.Name) == "avg" {
+				call.Name = "mean"
+			}
+		})
+		t.stmt.Fields = append(t.stmt.Fields, f)
+	}
+	if len(t.stmt.Fields) == 0 {
+		return errors.New("at least one column that is not grouped must be selected")
+	}
+
+	return t.resolveOrderBy(sortFields, fields)
+}
+
+// resolveOrderBy sets the sort order. InfluxQL can only sort by time, so the
+// ORDER BY clause may only refer to time or the time bucket.
+func (t *translator) resolveOrderBy(sortFields []sortField, fields influxql.Fields) error {
+	for _, sf := range sortFields {
+		name := sf.name
+		if n, err := strconv.Atoi(name); err == nil {
+			if n < 1 || n > len(fields) {
+				return fmt.Errorf("ORDER BY position %d is not in select list", n)
+			} else if n != t.position {
+				return errors.New("only ORDER BY time is supported")
+			}
+			name = "time"
+		}
+		if !strings.EqualFold(name, "time") && !(t.position != 0 && name == fields[t.position-1].Alias) {
+			return errors.New("only ORDER BY time is supported")
+		}
+		t.stmt.SortFields = append(t.stmt.SortFields, &influxql.SortField{Name: "time", Ascending: sf.ascending})
+	}
+	return nil
+}
+
+// bucketInterval returns the width of a time_bucket() or date_trunc() call.
+func bucketInterval(expr influxql.Expr) (time.Duration, bool, error) {
+	call, ok := expr.(*influxql.Call)
+	if !ok {
+		return 0, false, nil
+	}
+
+	name := strings.ToLower(call.Name)
+	if name != "time_bucket" && name != "date_trunc" {
+		return 0, false, nil
+	} else if len(call.Args) != 2 {
+		return 0, false, fmt.Errorf("%s expects 2 arguments", name)
+	} else if ref, ok := call.Args[1].(*influxql.VarRef); !ok || ref.Val != "time" {
+		return 0, false, fmt.Errorf("%s may only be applied to time", name)
+	}
+
+	switch arg := call.Args[0].(type) {
+	case *influxql.DurationLiteral:
+		if name == "time_bucket" {
+			return arg.Val, true, nil
+		}
+	case *influxql.StringLiteral:
+		if name == "date_trunc" {
+			d, err := parseInterval("1 " + arg.Val)
+			return d, err == nil, err
+		}
+		d, err := parseInterval(arg.Val)
+		return d, err == nil, err
+	}
+	return 0, false, fmt.Errorf("invalid %s width: %s", name, call.Args[0])
+}
+
+// intervalUnits maps the units of SQL intervals to durations.
+var intervalUnits = map[string]time.Duration{
+	"microsecond": time.Microsecond,
+	"millisecond": time.Millisecond,
+	"second":      time.Second,
+	"minute":      time.Minute,
+	"hour":        time.Hour,
+	"day":         24 * time.Hour,
+	"week":        7 * 24 * time.Hour,
+}
+
+// parseInterval parses an interval such as "5 minutes" or an InfluxQL
+// duration such as "5m".
+func parseInterval(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	parts := strings.Fields(s)
+	if len(parts) != 2 {
+		if d, err := influxql.ParseDuration(s); err == nil {
+			return d, nil
+		}
+		return 0, fmt.Errorf("invalid interval: %q", s)
+	}
+
+	n, err := strconv.ParseInt(parts[0], 10, 64)
+	unit, ok := intervalUnits[strings.TrimSuffix(strings.ToLower(parts[1]), "s")]
+	if err != nil || !ok || n <= 0 {
+		return 0, fmt.Errorf("invalid interval: %q", s)
+	}
+	return time.Duration(n) * unit, nil
+}
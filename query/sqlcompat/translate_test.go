@@ -0,0 +1,58 @@
+package sqlcompat_test
+
+import (
+	"testing"
+
+	"github.com/influxdata/influxdb/query/sqlcompat"
+)
+
+func TestTranslate(t *testing.T) {
+	for _, tt := range []struct {
+		s   string
+		exp string
+	}{
+		{
+			s:   `SELECT usage_user FROM cpu WHERE host = 'a'`,
+			exp: `SELECT usage_user FROM cpu WHERE host = 'a'`,
+		},
+		{
+			s:   `SELECT time_bucket('5 minutes', time) AS bucket, host, avg(usage_user) AS usage FROM telegraf.autogen.cpu WHERE time > now() - interval '1 hour' GROUP BY bucket, host ORDER BY bucket DESC LIMIT 10`,
+			exp: `SELECT mean(usage_user) AS usage FROM telegraf.autogen.cpu WHERE time > now() - 1h GROUP BY time(5m), host ORDER BY time DESC LIMIT 10`,
+		},
+		{
+			s:   `select date_trunc('hour', time), region, max("value") from "mem" where region <> 'interval ''1 day''' group by 1, 2 order by 1`,
+			exp: `SELECT max(value) FROM mem WHERE region != 'interval \'1 day\'' GROUP BY time(1h), region ORDER BY time ASC`,
+		},
+		{
+			s:   `SELECT count(value) FROM cpu GROUP BY time_bucket(interval '30 seconds', time)`,
+			exp: `SELECT count(value) FROM cpu GROUP BY time(30s)`,
+		},
+	} {
+		stmt, err := sqlcompat.Translate(tt.s)
+		if err != nil {
+			t.Fatalf("%s: %s", tt.s, err)
+		} else if stmt.String() != tt.exp {
+			t.Fatalf("unexpected statement:\n got %s\n exp %s", stmt, tt.exp)
+		}
+	}
+}
+
+func TestTranslate_Errors(t *testing.T) {
+	for _, tt := range []struct {
+		s   string
+		err string
+	}{
+		{s: `DROP DATABASE db0`, err: `only SELECT statements are supported`},
+		{s: `SELECT value FROM cpu; SELECT value FROM mem`, err: `exactly one statement is required`},
+		{s: `SELECT value INTO other FROM cpu`, err: `SELECT INTO is not supported`},
+		{s: `SELECT value FROM cpu ORDER BY value`, err: `only ORDER BY time is supported`},
+		{s: `SELECT time_bucket('1 minute', time), mean(value) FROM cpu`, err: `a selected time bucket must be listed in GROUP BY`},
+		{s: `SELECT time_bucket('1 fortnight', time), mean(value) FROM cpu GROUP BY 1`, err: `invalid interval: "1 fortnight"`},
+		{s: `SELECT host FROM cpu GROUP BY host`, err: `at least one column that is not grouped must be selected`},
+		{s: `SELECT mean(value) FROM cpu GROUP BY 3`, err: `GROUP BY position 3 is not in select list`},
+	} {
+		if _, err := sqlcompat.Translate(tt.s); err == nil || err.Error() != tt.err {
+			t.Errorf("%s: unexpected error:\n got %v\n exp %s", tt.s, err, tt.err)
+		}
+	}
+}
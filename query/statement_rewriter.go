@@ -2,6 +2,7 @@ package query
 
 import (
 	"errors"
+	"fmt"
 	"regexp"
 
 	"github.com/influxdata/influxql"
@@ -254,8 +255,14 @@ func rewriteShowTagValuesStatement(stmt *influxql.ShowTagValuesStatement) (influ
 	}
 	condition = rewriteSourcesCondition(stmt.Sources, condition)
 
+	database, sources, err := rewriteQualifiedSources(stmt.Sources, stmt.Database)
+	if err != nil {
+		return nil, err
+	}
+
 	return &influxql.ShowTagValuesStatement{
-		Database:   stmt.Database,
+		Database:   database,
+		Sources:    sources,
 		Op:         stmt.Op,
 		TagKeyExpr: stmt.TagKeyExpr,
 		Condition:  condition,
@@ -337,8 +344,14 @@ func rewriteShowTagValuesCardinalityStatement(stmt *influxql.ShowTagValuesCardin
 }
 
 func rewriteShowTagKeysStatement(stmt *influxql.ShowTagKeysStatement) (influxql.Statement, error) {
+	database, sources, err := rewriteQualifiedSources(stmt.Sources, stmt.Database)
+	if err != nil {
+		return nil, err
+	}
+
 	return &influxql.ShowTagKeysStatement{
-		Database:   stmt.Database,
+		Database:   database,
+		Sources:    sources,
 		Condition:  rewriteSourcesCondition(stmt.Sources, stmt.Condition),
 		SortFields: stmt.SortFields,
 		Limit:      stmt.Limit,
@@ -414,6 +427,53 @@ func rewriteSources(sources influxql.Sources, systemIterator, defaultDatabase st
 	return newSources
 }
 
+// rewriteQualifiedSources resolves the database of a statement from the
+// database and retention policy qualifiers of its sources.
+//
+// The sources are only returned when one of them names a retention policy,
+// with unqualified sources set to the resolved database. Otherwise the
+// statement's database is sufficient and no sources are returned.
+func rewriteQualifiedSources(sources influxql.Sources, database string) (string, influxql.Sources, error) {
+	var sourceDatabase string
+	var hasRP bool
+	for _, src := range sources {
+		mm, ok := src.(*influxql.Measurement)
+		if !ok {
+			continue
+		}
+		if mm.Database != "" {
+			if sourceDatabase != "" && sourceDatabase != mm.Database {
+				return "", nil, fmt.Errorf("multiple databases not supported: %s, %s", sourceDatabase, mm.Database)
+			}
+			sourceDatabase = mm.Database
+		}
+		if mm.RetentionPolicy != "" {
+			hasRP = true
+		}
+	}
+
+	// As with other statements, a database in the FROM clause takes
+	// precedence over the default database.
+	if sourceDatabase != "" {
+		database = sourceDatabase
+	}
+	if !hasRP {
+		return database, nil, nil
+	}
+
+	newSources := make(influxql.Sources, 0, len(sources))
+	for _, src := range sources {
+		mm, ok := src.(*influxql.Measurement)
+		if !ok {
+			continue
+		}
+		newM := mm.Clone()
+		newM.Database = database
+		newSources = append(newSources, newM)
+	}
+	return database, newSources, nil
+}
+
 // rewriteSourcesCondition rewrites sources into `name` expressions.
 // Merges with cond and returns a new condition.
 func rewriteSourcesCondition(sources influxql.Sources, cond influxql.Expr) influxql.Expr {
@@ -158,27 +158,27 @@ func TestRewriteStatement(t *testing.T) {
 		},
 		{
 			stmt: `SHOW TAG KEYS FROM mydb.myrp1.cpu`,
-			s:    `SHOW TAG KEYS WHERE _name = 'cpu'`,
+			s:    `SHOW TAG KEYS ON mydb FROM mydb.myrp1.cpu WHERE _name = 'cpu'`,
 		},
 		{
 			stmt: `SHOW TAG KEYS ON db0 FROM mydb.myrp1.cpu`,
-			s:    `SHOW TAG KEYS ON db0 WHERE _name = 'cpu'`,
+			s:    `SHOW TAG KEYS ON mydb FROM mydb.myrp1.cpu WHERE _name = 'cpu'`,
 		},
 		{
 			stmt: `SHOW TAG KEYS FROM mydb.myrp1./c.*/`,
-			s:    `SHOW TAG KEYS WHERE _name =~ /c.*/`,
+			s:    `SHOW TAG KEYS ON mydb FROM mydb.myrp1./c.*/ WHERE _name =~ /c.*/`,
 		},
 		{
 			stmt: `SHOW TAG KEYS ON db0 FROM mydb.myrp1./c.*/`,
-			s:    `SHOW TAG KEYS ON db0 WHERE _name =~ /c.*/`,
+			s:    `SHOW TAG KEYS ON mydb FROM mydb.myrp1./c.*/ WHERE _name =~ /c.*/`,
 		},
 		{
 			stmt: `SHOW TAG KEYS FROM mydb.myrp1.cpu WHERE region = 'uswest'`,
-			s:    `SHOW TAG KEYS WHERE (_name = 'cpu') AND (region = 'uswest')`,
+			s:    `SHOW TAG KEYS ON mydb FROM mydb.myrp1.cpu WHERE (_name = 'cpu') AND (region = 'uswest')`,
 		},
 		{
 			stmt: `SHOW TAG KEYS ON db0 FROM mydb.myrp1.cpu WHERE region = 'uswest'`,
-			s:    `SHOW TAG KEYS ON db0 WHERE (_name = 'cpu') AND (region = 'uswest')`,
+			s:    `SHOW TAG KEYS ON mydb FROM mydb.myrp1.cpu WHERE (_name = 'cpu') AND (region = 'uswest')`,
 		},
 		{
 			stmt: `SHOW TAG KEYS WHERE time > 0`,
@@ -214,27 +214,27 @@ func TestRewriteStatement(t *testing.T) {
 		},
 		{
 			stmt: `SHOW TAG KEYS FROM mydb.myrp1.cpu WHERE time > 0`,
-			s:    `SHOW TAG KEYS WHERE (_name = 'cpu') AND (time > 0)`,
+			s:    `SHOW TAG KEYS ON mydb FROM mydb.myrp1.cpu WHERE (_name = 'cpu') AND (time > 0)`,
 		},
 		{
 			stmt: `SHOW TAG KEYS ON db0 FROM mydb.myrp1.cpu WHERE time > 0`,
-			s:    `SHOW TAG KEYS ON db0 WHERE (_name = 'cpu') AND (time > 0)`,
+			s:    `SHOW TAG KEYS ON mydb FROM mydb.myrp1.cpu WHERE (_name = 'cpu') AND (time > 0)`,
 		},
 		{
 			stmt: `SHOW TAG KEYS FROM mydb.myrp1./c.*/ WHERE time > 0`,
-			s:    `SHOW TAG KEYS WHERE (_name =~ /c.*/) AND (time > 0)`,
+			s:    `SHOW TAG KEYS ON mydb FROM mydb.myrp1./c.*/ WHERE (_name =~ /c.*/) AND (time > 0)`,
 		},
 		{
 			stmt: `SHOW TAG KEYS ON db0 FROM mydb.myrp1./c.*/ WHERE time > 0`,
-			s:    `SHOW TAG KEYS ON db0 WHERE (_name =~ /c.*/) AND (time > 0)`,
+			s:    `SHOW TAG KEYS ON mydb FROM mydb.myrp1./c.*/ WHERE (_name =~ /c.*/) AND (time > 0)`,
 		},
 		{
 			stmt: `SHOW TAG KEYS FROM mydb.myrp1.cpu WHERE region = 'uswest' AND time > 0`,
-			s:    `SHOW TAG KEYS WHERE (_name = 'cpu') AND (region = 'uswest' AND time > 0)`,
+			s:    `SHOW TAG KEYS ON mydb FROM mydb.myrp1.cpu WHERE (_name = 'cpu') AND (region = 'uswest' AND time > 0)`,
 		},
 		{
 			stmt: `SHOW TAG KEYS ON db0 FROM mydb.myrp1.cpu WHERE region = 'uswest' AND time > 0`,
-			s:    `SHOW TAG KEYS ON db0 WHERE (_name = 'cpu') AND (region = 'uswest' AND time > 0)`,
+			s:    `SHOW TAG KEYS ON mydb FROM mydb.myrp1.cpu WHERE (_name = 'cpu') AND (region = 'uswest' AND time > 0)`,
 		},
 		{
 			stmt: `SHOW TAG VALUES WITH KEY = "region"`,
@@ -260,6 +260,10 @@ func TestRewriteStatement(t *testing.T) {
 			stmt: `SHOW TAG VALUES FROM cpu WITH KEY = "region"`,
 			s:    `SHOW TAG VALUES WITH KEY = region WHERE (_name = 'cpu') AND (_tagKey = 'region')`,
 		},
+		{
+			stmt: `SHOW TAG VALUES FROM mydb.myrp1.cpu WITH KEY = "region"`,
+			s:    `SHOW TAG VALUES ON mydb FROM mydb.myrp1.cpu WITH KEY = region WHERE (_name = 'cpu') AND (_tagKey = 'region')`,
+		},
 		{
 			stmt: `SHOW TAG VALUES WITH KEY != "region"`,
 			s:    `SHOW TAG VALUES WITH KEY != region WHERE _tagKey != 'region'`,
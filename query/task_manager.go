@@ -2,6 +2,7 @@ package query
 
 import (
 	"fmt"
+	"strings"
 	"sync"
 	"time"
 
@@ -37,6 +38,42 @@ func (t TaskStatus) String() string {
 	panic(fmt.Sprintf("unknown task status: %d", int(t)))
 }
 
+// Priority is the scheduling class of a query.
+type Priority int
+
+const (
+	// InteractivePriority is the priority of queries that a user is waiting
+	// on, such as dashboards. Interactive queries are never queued.
+	InteractivePriority Priority = iota
+
+	// BatchPriority is the priority of background queries, such as continuous
+	// queries and backfills. Batch queries wait for a free slot instead of
+	// failing when the concurrency limits are reached.
+	BatchPriority
+)
+
+// ParsePriority returns the priority named by s. An empty string is the
+// interactive priority.
+func ParsePriority(s string) (Priority, error) {
+	switch strings.ToLower(s) {
+	case "", "interactive":
+		return InteractivePriority, nil
+	case "batch":
+		return BatchPriority, nil
+	}
+	return 0, fmt.Errorf("invalid query priority: %s", s)
+}
+
+func (p Priority) String() string {
+	switch p {
+	case InteractivePriority:
+		return "interactive"
+	case BatchPriority:
+		return "batch"
+	}
+	panic(fmt.Sprintf("unknown query priority: %d", int(p)))
+}
+
 // TaskManager takes care of all aspects related to managing running queries.
 type TaskManager struct {
 	// Query execution timeout.
@@ -49,6 +86,16 @@ type TaskManager struct {
 	// Maximum number of concurrent queries.
 	MaxConcurrentQueries int
 
+	// Maximum estimated memory, in bytes, of the results a query holds
+	// before they are returned. Queries over the limit fail. If zero, the
+	// memory of a query is unlimited.
+	MaxQueryMemory int64
+
+	// Maximum number of concurrent batch queries. Batch queries over this
+	// limit, or over MaxConcurrentQueries, wait until a running query
+	// finishes so they leave room for interactive queries.
+	MaxConcurrentBatchQueries int
+
 	// Logger to use for all logging.
 	// Defaults to discarding all log output.
 	Logger zap.Logger
@@ -58,6 +105,11 @@ type TaskManager struct {
 	nextID   uint64
 	mu       sync.RWMutex
 	shutdown bool
+
+	// batchN is the number of running batch queries. released is closed
+	// and replaced whenever a query is detached to wake waiting batch queries.
+	batchN   int
+	released chan struct{}
 }
 
 // NewTaskManager creates a new TaskManager.
@@ -67,6 +119,7 @@ func NewTaskManager() *TaskManager {
 		Logger:       zap.New(zap.NullEncoder()),
 		queries:      make(map[uint64]*QueryTask),
 		nextID:       1,
+		released:     make(chan struct{}),
 	}
 }
 
@@ -125,11 +178,11 @@ func (t *TaskManager) executeShowQueriesStatement(q *influxql.ShowQueriesStateme
 			d = d - (d % time.Microsecond)
 		}
 
-		values = append(values, []interface{}{id, qi.query, qi.database, d.String(), qi.status.String()})
+		values = append(values, []interface{}{id, qi.query, qi.database, d.String(), qi.status.String(), qi.getProgress()})
 	}
 
 	return []*models.Row{{
-		Columns: []string{"qid", "query", "database", "duration", "status"},
+		Columns: []string{"qid", "query", "database", "duration", "status", "progress"},
 		Values:  values,
 	}}, nil
 }
@@ -151,21 +204,55 @@ func (t *TaskManager) queryError(qid uint64, err error) {
 //
 // After a query finishes running, the system is free to reuse a query id.
 func (t *TaskManager) AttachQuery(q *influxql.Query, database string, interrupt <-chan struct{}) (uint64, *QueryTask, error) {
+	return t.AttachQueryWithPriority(q, database, InteractivePriority, interrupt)
+}
+
+// AttachQueryWithPriority attaches a query with the given priority. Batch
+// queries block until there is room for them under the concurrency limits or
+// until interrupt is closed.
+func (t *TaskManager) AttachQueryWithPriority(q *influxql.Query, database string, priority Priority, interrupt <-chan struct{}) (uint64, *QueryTask, error) {
+	return t.attachQuery(q, database, priority, t.QueryTimeout, interrupt)
+}
+
+// attachQuery attaches a query that is killed once it has run for timeout.
+// A zero timeout never kills the query.
+func (t *TaskManager) attachQuery(q *influxql.Query, database string, priority Priority, timeout time.Duration, interrupt <-chan struct{}) (uint64, *QueryTask, error) {
 	t.mu.Lock()
 	defer t.mu.Unlock()
 
-	if t.shutdown {
-		return 0, nil, ErrQueryEngineShutdown
+	for {
+		if t.shutdown {
+			return 0, nil, ErrQueryEngineShutdown
+		}
+
+		if priority != BatchPriority || t.canAttachBatch() {
+			break
+		}
+
+		released := t.released
+		t.mu.Unlock()
+		select {
+		case <-released:
+			t.mu.Lock()
+		case <-interrupt:
+			t.mu.Lock()
+			return 0, nil, ErrQueryInterrupted
+		}
 	}
 
 	if t.MaxConcurrentQueries > 0 && len(t.queries) >= t.MaxConcurrentQueries {
 		return 0, nil, ErrMaxConcurrentQueriesLimitExceeded(len(t.queries), t.MaxConcurrentQueries)
 	}
 
+	if priority == BatchPriority {
+		t.batchN++
+	}
+
 	qid := t.nextID
 	query := &QueryTask{
 		query:     q.String(),
 		database:  database,
+		priority:  priority,
 		status:    RunningTask,
 		startTime: time.Now(),
 		closing:   make(chan struct{}),
@@ -173,7 +260,7 @@ func (t *TaskManager) AttachQuery(q *influxql.Query, database string, interrupt
 	}
 	t.queries[qid] = query
 
-	go t.waitForQuery(qid, query.closing, interrupt, query.monitorCh)
+	go t.waitForQuery(qid, timeout, query.closing, interrupt, query.monitorCh)
 	if t.LogQueriesAfter != 0 {
 		go query.monitor(func(closing <-chan struct{}) error {
 			timer := time.NewTimer(t.LogQueriesAfter)
@@ -192,6 +279,22 @@ func (t *TaskManager) AttachQuery(q *influxql.Query, database string, interrupt
 	return qid, query, nil
 }
 
+// canAttachBatch returns true if a batch query can run without exceeding the
+// concurrency limits. The lock must be held.
+func (t *TaskManager) canAttachBatch() bool {
+	if t.MaxConcurrentBatchQueries > 0 && t.batchN >= t.MaxConcurrentBatchQueries {
+		return false
+	}
+	return t.MaxConcurrentQueries <= 0 || len(t.queries) < t.MaxConcurrentQueries
+}
+
+// release wakes the batch queries waiting for a running query to finish.
+// The lock must be held.
+func (t *TaskManager) release() {
+	close(t.released)
+	t.released = make(chan struct{})
+}
+
 // KillQuery enters a query into the killed state and closes the channel
 // from the TaskManager. This method can be used to forcefully terminate a
 // running query.
@@ -219,6 +322,10 @@ func (t *TaskManager) DetachQuery(qid uint64) error {
 
 	query.close()
 	delete(t.queries, qid)
+	if query.priority == BatchPriority {
+		t.batchN--
+	}
+	t.release()
 	return nil
 }
 
@@ -248,10 +355,19 @@ func (t *TaskManager) Queries() []QueryInfo {
 	return queries
 }
 
-func (t *TaskManager) waitForQuery(qid uint64, interrupt <-chan struct{}, closing <-chan struct{}, monitorCh <-chan error) {
+// queryTimeout returns the timeout of a query that requested the given
+// timeout. A query may shorten the QueryTimeout but not extend it.
+func (t *TaskManager) queryTimeout(requested time.Duration) time.Duration {
+	if requested > 0 && (t.QueryTimeout == 0 || requested < t.QueryTimeout) {
+		return requested
+	}
+	return t.QueryTimeout
+}
+
+func (t *TaskManager) waitForQuery(qid uint64, timeout time.Duration, interrupt <-chan struct{}, closing <-chan struct{}, monitorCh <-chan error) {
 	var timerCh <-chan time.Time
-	if t.QueryTimeout != 0 {
-		timer := time.NewTimer(t.QueryTimeout)
+	if timeout != 0 {
+		timer := time.NewTimer(timeout)
 		timerCh = timer.C
 		defer timer.Stop()
 	}
@@ -285,5 +401,6 @@ func (t *TaskManager) Close() error {
 		query.close()
 	}
 	t.queries = nil
+	t.release()
 	return nil
 }
@@ -0,0 +1,55 @@
+package cdc
+
+import (
+	"errors"
+
+	"github.com/influxdata/influxdb/monitor/diagnostics"
+)
+
+const (
+	// DefaultLogSize is the default number of writes retained per database.
+	DefaultLogSize = 10000
+)
+
+// Config represents the configuration for the change stream.
+type Config struct {
+	Enabled bool `toml:"enabled"`
+
+	// LogSize is the number of most recent writes retained for each
+	// database. Consumers that fall further behind miss writes.
+	LogSize int `toml:"log-size"`
+}
+
+// NewConfig returns a new Config with defaults.
+func NewConfig() Config {
+	return Config{
+		Enabled: false,
+		LogSize: DefaultLogSize,
+	}
+}
+
+// Validate returns an error if the Config is invalid.
+func (c Config) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+
+	if c.LogSize <= 0 {
+		return errors.New("log-size must be positive")
+	}
+	return nil
+}
+
+// Diagnostics returns a diagnostics representation of a subset of the Config.
+func (c Config) Diagnostics() (*diagnostics.Diagnostics, error) {
+	if !c.Enabled {
+		return diagnostics.RowFromMap(map[string]interface{}{
+			"enabled": false,
+		}), nil
+	}
+
+	return diagnostics.RowFromMap(map[string]interface{}{
+		"enabled":  true,
+		"log-size": c.LogSize,
+	}), nil
+}
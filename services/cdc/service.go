@@ -0,0 +1,247 @@
+// Package cdc provides a stream of the writes committed to each database so
+// that external systems can follow changes without polling queries.
+package cdc // import "github.com/influxdata/influxdb/services/cdc"
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/influxdata/influxdb/coordinator"
+	"github.com/influxdata/influxdb/models"
+	"github.com/uber-go/zap"
+)
+
+// Statistics for the change stream.
+const (
+	statWritesCaptured = "writesCaptured"
+	statWritesDropped  = "writesDropped"
+	statWritesEvicted  = "writesEvicted"
+)
+
+// Change is a write committed to a database. Changes of a database are
+// numbered by sequence, starting at 1 each time the server starts. A dropped
+// change is a write that was committed but could not be captured because
+// the stream fell behind; it has no points.
+type Change struct {
+	Seq             uint64
+	RetentionPolicy string
+	Points          []models.Point
+	Dropped         bool
+}
+
+// changeLog holds the most recent changes of a database.
+type changeLog struct {
+	changes []Change
+	seq     uint64
+}
+
+// Service keeps the most recent writes committed to each database in memory
+// and serves them to consumers in order.
+type Service struct {
+	Logger zap.Logger
+
+	logSize int
+	points  chan *coordinator.WritePointsRequest
+
+	mu     sync.Mutex
+	logs   map[string]*changeLog
+	notify chan struct{} // closed when a change is added
+
+	closing chan struct{}
+	wg      sync.WaitGroup
+	stats   *Statistics
+}
+
+// NewService returns a new instance of the change stream.
+func NewService(c Config) *Service {
+	return &Service{
+		Logger:  zap.New(zap.NullEncoder()),
+		logSize: c.LogSize,
+		points:  make(chan *coordinator.WritePointsRequest, 100),
+		logs:    make(map[string]*changeLog),
+		notify:  make(chan struct{}),
+		stats:   &Statistics{},
+	}
+}
+
+// WithLogger sets the logger for the service.
+func (s *Service) WithLogger(log zap.Logger) {
+	s.Logger = log.With(zap.String("service", "cdc"))
+}
+
+// Open starts capturing writes.
+func (s *Service) Open() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closing != nil {
+		return nil
+	}
+
+	s.Logger.Info("Starting change stream")
+	s.closing = make(chan struct{})
+	s.wg.Add(1)
+	go s.run()
+	return nil
+}
+
+// Close stops capturing writes and releases waiting consumers.
+func (s *Service) Close() error {
+	s.mu.Lock()
+	if s.closing == nil {
+		s.mu.Unlock()
+		return nil
+	}
+	close(s.closing)
+	s.mu.Unlock()
+
+	s.wg.Wait()
+
+	s.mu.Lock()
+	s.closing = nil
+	s.mu.Unlock()
+	return nil
+}
+
+// Points returns the channel committed writes are sent to.
+func (s *Service) Points() chan<- *coordinator.WritePointsRequest {
+	return s.points
+}
+
+// Statistics maintains the statistics for the change stream.
+type Statistics struct {
+	WritesCaptured int64
+	WritesDropped  int64
+	WritesEvicted  int64
+}
+
+// Statistics returns statistics for periodic monitoring.
+func (s *Service) Statistics(tags map[string]string) []models.Statistic {
+	return []models.Statistic{{
+		Name: "cdc",
+		Tags: tags,
+		Values: map[string]interface{}{
+			statWritesCaptured: atomic.LoadInt64(&s.stats.WritesCaptured),
+			statWritesDropped:  atomic.LoadInt64(&s.stats.WritesDropped),
+			statWritesEvicted:  atomic.LoadInt64(&s.stats.WritesEvicted),
+		},
+	}}
+}
+
+func (s *Service) run() {
+	defer s.wg.Done()
+	for {
+		select {
+		case <-s.closing:
+			return
+		case req := <-s.points:
+			s.append(req)
+		}
+	}
+}
+
+// Dropped records a committed write that could not be sent to the service
+// so that consumers reading past it know to resync.
+func (s *Service) Dropped(req *coordinator.WritePointsRequest) {
+	if len(req.Points) == 0 {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.add(req.Database, Change{RetentionPolicy: req.RetentionPolicy, Dropped: true})
+	atomic.AddInt64(&s.stats.WritesDropped, 1)
+}
+
+// append adds a write to the log of its database and wakes consumers.
+func (s *Service) append(req *coordinator.WritePointsRequest) {
+	if len(req.Points) == 0 {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.add(req.Database, Change{RetentionPolicy: req.RetentionPolicy, Points: req.Points})
+	atomic.AddInt64(&s.stats.WritesCaptured, 1)
+}
+
+// add numbers c, adds it to the log of database and wakes consumers. s.mu
+// must be held.
+func (s *Service) add(database string, c Change) {
+	log := s.logs[database]
+	if log == nil {
+		log = &changeLog{}
+		s.logs[database] = log
+	}
+	log.seq++
+	c.Seq = log.seq
+	log.changes = append(log.changes, c)
+
+	if n := len(log.changes) - s.logSize; n > 0 {
+		log.changes = append(log.changes[:0:0], log.changes[n:]...)
+		atomic.AddInt64(&s.stats.WritesEvicted, int64(n))
+	}
+
+	close(s.notify)
+	s.notify = make(chan struct{})
+}
+
+// Changes returns up to limit changes of a database with a sequence greater
+// than since. If there are none, it waits up to timeout for one to be
+// committed or until done is closed. Truncated is true when changes after
+// since are no longer retained, either because the consumer fell behind or
+// because the server restarted, in which case the oldest retained changes
+// are returned. It is also true when a returned change was dropped; the
+// changes around it are not complete either, as dropped writes are recorded
+// as soon as they are missed rather than in commit order.
+func (s *Service) Changes(database string, since uint64, limit int, timeout time.Duration, done <-chan struct{}) (changes []Change, truncated bool) {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	for {
+		s.mu.Lock()
+		changes, truncated = s.read(database, since, limit)
+		notify, closing := s.notify, s.closing
+		s.mu.Unlock()
+
+		if len(changes) > 0 || truncated {
+			return changes, truncated
+		}
+
+		select {
+		case <-notify:
+		case <-timer.C:
+			return nil, false
+		case <-done:
+			return nil, false
+		case <-closing:
+			return nil, false
+		}
+	}
+}
+
+// read returns the retained changes after since. s.mu must be held.
+func (s *Service) read(database string, since uint64, limit int) ([]Change, bool) {
+	log := s.logs[database]
+	if log == nil || len(log.changes) == 0 {
+		return nil, since > 0
+	}
+
+	var truncated bool
+	first := log.changes[0].Seq
+	if since > log.seq || since+1 < first {
+		since, truncated = first-1, true
+	}
+
+	changes := log.changes[since+1-first:]
+	if limit > 0 && len(changes) > limit {
+		changes = changes[:limit]
+	}
+	for _, c := range changes {
+		if c.Dropped {
+			truncated = true
+			break
+		}
+	}
+	return append([]Change(nil), changes...), truncated
+}
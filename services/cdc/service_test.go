@@ -0,0 +1,112 @@
+package cdc_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/coordinator"
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/services/cdc"
+)
+
+func TestService_Changes(t *testing.T) {
+	c := cdc.NewConfig()
+	c.Enabled = true
+	c.LogSize = 2
+	s := cdc.NewService(c)
+	if err := s.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	write := func(db, line string) {
+		s.Points() <- &coordinator.WritePointsRequest{
+			Database:        db,
+			RetentionPolicy: "autogen",
+			Points:          mustParsePoints(line),
+		}
+	}
+
+	// A consumer waits for the next write.
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		write("db0", "cpu value=1 1")
+	}()
+	changes, truncated := s.Changes("db0", 0, 10, time.Second, nil)
+	if truncated || len(changes) != 1 {
+		t.Fatalf("unexpected changes: %v truncated=%v", changes, truncated)
+	} else if changes[0].Seq != 1 || changes[0].RetentionPolicy != "autogen" || changes[0].Points[0].String() != "cpu value=1 1" {
+		t.Fatalf("unexpected change: %+v", changes[0])
+	}
+
+	// Writes to other databases are in their own stream.
+	write("db1", "mem value=1 1")
+	write("db0", "cpu value=2 2")
+	write("db0", "cpu value=3 3")
+	waitForChange(t, s, "db0", 3)
+
+	if changes, truncated := s.Changes("db0", 1, 1, 0, nil); truncated || len(changes) != 1 || changes[0].Seq != 2 {
+		t.Fatalf("unexpected changes: %v truncated=%v", changes, truncated)
+	}
+
+	// The first write has been evicted from the log.
+	if changes, truncated := s.Changes("db0", 0, 10, 0, nil); !truncated || len(changes) != 2 || changes[0].Seq != 2 {
+		t.Fatalf("unexpected changes: %v truncated=%v", changes, truncated)
+	}
+
+	// A consumer ahead of the log, such as after a restart, starts over.
+	if changes, truncated := s.Changes("db0", 10, 10, 0, nil); !truncated || len(changes) != 2 {
+		t.Fatalf("unexpected changes: %v truncated=%v", changes, truncated)
+	}
+
+	// A consumer that is up to date times out without changes.
+	if changes, truncated := s.Changes("db0", 3, 10, 10*time.Millisecond, nil); truncated || len(changes) != 0 {
+		t.Fatalf("unexpected changes: %v truncated=%v", changes, truncated)
+	}
+}
+
+func TestService_Changes_Dropped(t *testing.T) {
+	c := cdc.NewConfig()
+	c.Enabled = true
+	s := cdc.NewService(c)
+	if err := s.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	s.Points() <- &coordinator.WritePointsRequest{Database: "db0", RetentionPolicy: "autogen", Points: mustParsePoints("cpu value=1 1")}
+	waitForChange(t, s, "db0", 1)
+	s.Dropped(&coordinator.WritePointsRequest{Database: "db0", RetentionPolicy: "autogen", Points: mustParsePoints("cpu value=2 2")})
+	s.Points() <- &coordinator.WritePointsRequest{Database: "db0", RetentionPolicy: "autogen", Points: mustParsePoints("cpu value=3 3")}
+
+	// A consumer reading past the dropped write must resync.
+	changes, truncated := s.Changes("db0", 1, 10, time.Second, nil)
+	if !truncated || len(changes) == 0 || !changes[0].Dropped || changes[0].Seq != 2 || len(changes[0].Points) != 0 {
+		t.Fatalf("unexpected changes: %v truncated=%v", changes, truncated)
+	}
+
+	// A consumer past the dropped write is not affected.
+	waitForChange(t, s, "db0", 3)
+	if changes, truncated := s.Changes("db0", 2, 10, 0, nil); truncated || len(changes) != 1 || changes[0].Seq != 3 {
+		t.Fatalf("unexpected changes: %v truncated=%v", changes, truncated)
+	}
+}
+
+// waitForChange waits until the change with the given sequence is in the log.
+func waitForChange(t *testing.T, s *cdc.Service, db string, seq uint64) {
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); {
+		changes, _ := s.Changes(db, seq-1, 1, 10*time.Millisecond, nil)
+		if len(changes) > 0 && changes[0].Seq == seq {
+			return
+		}
+	}
+	t.Fatalf("timed out waiting for change %d", seq)
+}
+
+func mustParsePoints(s string) []models.Point {
+	points, err := models.ParsePointsString(s)
+	if err != nil {
+		panic(err)
+	}
+	return points
+}
@@ -452,20 +452,21 @@ func (s *Service) UnmarshalValueList(vl *api.ValueList) []models.Point {
 	timestamp := vl.Time.UTC()
 
 	var points []models.Point
+	var fields models.FieldsBuilder
 	for i := range vl.Values {
 		var name string
 		name = fmt.Sprintf("%s_%s", vl.Identifier.Plugin, vl.DSName(i))
 		tags := make(map[string]string, 4)
-		fields := make(map[string]interface{}, 1)
 
 		// Convert interface back to actual type, then to float64
+		fields.Reset()
 		switch value := vl.Values[i].(type) {
 		case api.Gauge:
-			fields["value"] = float64(value)
+			fields.AddFloat("value", float64(value))
 		case api.Derive:
-			fields["value"] = float64(value)
+			fields.AddFloat("value", float64(value))
 		case api.Counter:
-			fields["value"] = float64(value)
+			fields.AddFloat("value", float64(value))
 		}
 
 		if vl.Identifier.Host != "" {
@@ -482,7 +483,7 @@ func (s *Service) UnmarshalValueList(vl *api.ValueList) []models.Point {
 		}
 
 		// Drop invalid points
-		p, err := models.NewPoint(name, models.NewTags(tags), fields, timestamp)
+		p, err := models.NewPointWithFields(name, models.NewTags(tags), &fields, timestamp)
 		if err != nil {
 			s.Logger.Info(fmt.Sprintf("Dropping point %v: %v", name, err))
 			atomic.AddInt64(&s.stats.InvalidDroppedPoints, 1)
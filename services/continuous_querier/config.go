@@ -31,6 +31,11 @@ type Config struct {
 	// every minute, this should be set to 1 minute. The default is set to '1s' so the interval
 	// is compatible with most aggregations.
 	RunInterval toml.Duration `toml:"run-interval"`
+
+	// LateArrivalHorizon is how far back points written into windows that a
+	// CQ already computed make it recompute those windows. Zero disables
+	// recomputing windows for late points.
+	LateArrivalHorizon toml.Duration `toml:"late-arrival-horizon"`
 }
 
 // NewConfig returns a new instance of Config with defaults.
@@ -54,6 +59,9 @@ func (c Config) Validate() error {
 	if c.RunInterval <= 0 {
 		return errors.New("run-interval must be positive")
 	}
+	if c.LateArrivalHorizon < 0 {
+		return errors.New("late-arrival-horizon must not be negative")
+	}
 
 	return nil
 }
@@ -67,8 +75,9 @@ func (c Config) Diagnostics() (*diagnostics.Diagnostics, error) {
 	}
 
 	return diagnostics.RowFromMap(map[string]interface{}{
-		"enabled":             true,
-		"query-stats-enabled": c.QueryStatsEnabled,
-		"run-interval":        c.RunInterval,
+		"enabled":              true,
+		"query-stats-enabled":  c.QueryStatsEnabled,
+		"run-interval":         c.RunInterval,
+		"late-arrival-horizon": c.LateArrivalHorizon,
 	}), nil
 }
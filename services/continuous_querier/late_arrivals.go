@@ -0,0 +1,210 @@
+package continuous_querier
+
+import (
+	"fmt"
+	"sort"
+	"sync/atomic"
+	"time"
+
+	"github.com/influxdata/influxdb/coordinator"
+	"github.com/influxdata/influxdb/services/meta"
+	"github.com/influxdata/influxql"
+)
+
+// lateWindows tracks the time range a continuous query has computed and the
+// windows within it that received points afterwards.
+type lateWindows struct {
+	database string
+	sources  []*influxql.Measurement
+	target   *influxql.Measurement
+	interval time.Duration
+	offset   time.Duration
+	location *time.Location
+
+	// end is the end of the time ranges computed so far.
+	end time.Time
+
+	// dirty holds the start times of the windows to recompute.
+	dirty map[int64]struct{}
+}
+
+// windowStart returns the start of the window that contains t.
+func (w *lateWindows) windowStart(t int64) time.Time {
+	ts := time.Unix(0, t).In(w.location)
+	return truncate(ts.Add(-w.offset), w.interval).Add(w.offset)
+}
+
+// matches returns true if points written to a measurement of a database and
+// retention policy are read by the continuous query. Points written into
+// the target of the query are its own results and never match.
+func (w *lateWindows) matches(database, rp, name string) bool {
+	if w.target.Database == database && w.target.RetentionPolicy == rp &&
+		(w.target.Name == "" || w.target.Name == name) {
+		return false
+	}
+
+	for _, m := range w.sources {
+		db := m.Database
+		if db == "" {
+			db = w.database
+		}
+		if db != database || (m.RetentionPolicy != "" && m.RetentionPolicy != rp) {
+			continue
+		}
+		if m.Regex != nil {
+			if m.Regex.Val.MatchString(name) {
+				return true
+			}
+		} else if m.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Points returns a channel that receives the committed writes whose late
+// points mark the computed windows of continuous queries to be recomputed.
+func (s *Service) Points() chan<- *coordinator.WritePointsRequest {
+	return s.points
+}
+
+// trackLateArrivals marks the windows touched by late points until the
+// service is closed.
+func (s *Service) trackLateArrivals(stop <-chan struct{}) {
+	defer s.wg.Done()
+	for {
+		select {
+		case <-stop:
+			return
+		case req := <-s.points:
+			s.markLateArrivals(req)
+		}
+	}
+}
+
+// markLateArrivals marks the windows that the points of a write fall into
+// if continuous queries reading them have already computed those windows.
+// Points older than the lateness horizon are ignored.
+func (s *Service) markLateArrivals(req *coordinator.WritePointsRequest) {
+	horizon := s.Clock.Now().Add(-s.lateArrivalHorizon).UnixNano()
+
+	s.lateMu.Lock()
+	defer s.lateMu.Unlock()
+	for _, w := range s.lateWindows {
+		end := w.end.UnixNano()
+		for _, p := range req.Points {
+			if t := p.UnixNano(); t >= end || t < horizon {
+				continue
+			} else if !w.matches(req.Database, req.RetentionPolicy, string(p.Name())) {
+				continue
+			}
+			w.dirty[w.windowStart(p.UnixNano()).UnixNano()] = struct{}{}
+		}
+	}
+}
+
+// markComputed records that a continuous query computed the time range up to
+// end. Dirty windows in the range are kept, since their late points may have
+// arrived after the query read them.
+func (s *Service) markComputed(id string, cq *ContinuousQuery, interval, offset time.Duration, end time.Time) {
+	s.lateMu.Lock()
+	defer s.lateMu.Unlock()
+
+	w := s.lateWindows[id]
+	if w == nil {
+		w = &lateWindows{dirty: make(map[int64]struct{})}
+		s.lateWindows[id] = w
+	}
+
+	// The query may have been replaced, so update it on every run.
+	target := *cq.q.Target.Measurement
+	if target.Database == "" {
+		target.Database = cq.Database
+	}
+	w.database = cq.Database
+	w.sources = cq.q.Sources.Measurements()
+	w.target = &target
+	w.interval = interval
+	w.offset = offset
+	w.location = time.UTC
+	if cq.q.Location != nil {
+		w.location = cq.q.Location
+	}
+
+	if end.After(w.end) {
+		w.end = end
+	}
+}
+
+// pruneLateWindows stops tracking the windows of the continuous queries that
+// no longer exist.
+func (s *Service) pruneLateWindows(dbs []meta.DatabaseInfo) {
+	ids := make(map[string]struct{})
+	for _, db := range dbs {
+		for _, cq := range db.ContinuousQueries {
+			ids[fmt.Sprintf("%s%s%s", db.Name, idDelimiter, cq.Name)] = struct{}{}
+		}
+	}
+
+	s.lateMu.Lock()
+	defer s.lateMu.Unlock()
+	for id := range s.lateWindows {
+		if _, ok := ids[id]; !ok {
+			delete(s.lateWindows, id)
+		}
+	}
+}
+
+// recomputeLateWindows runs a continuous query again over the windows that
+// received late points, merging adjacent windows into one time range.
+func (s *Service) recomputeLateWindows(dbi *meta.DatabaseInfo, cqi *meta.ContinuousQueryInfo, now time.Time) error {
+	id := fmt.Sprintf("%s%s%s", dbi.Name, idDelimiter, cqi.Name)
+	horizon := now.Add(-s.lateArrivalHorizon).UnixNano()
+
+	s.lateMu.Lock()
+	w := s.lateWindows[id]
+	if w == nil || len(w.dirty) == 0 {
+		s.lateMu.Unlock()
+		return nil
+	}
+	starts := make([]int64, 0, len(w.dirty))
+	for t := range w.dirty {
+		if t >= horizon {
+			starts = append(starts, t)
+		}
+	}
+	w.dirty = make(map[int64]struct{})
+	interval := w.interval
+	s.lateMu.Unlock()
+
+	sort.Slice(starts, func(i, j int) bool { return starts[i] < starts[j] })
+	for len(starts) > 0 {
+		start := time.Unix(0, starts[0])
+		end := start.Add(interval)
+		n := 1
+		for ; n < len(starts) && starts[n] == end.UnixNano(); n++ {
+			end = end.Add(interval)
+		}
+		starts = starts[n:]
+
+		cq, err := NewContinuousQuery(dbi.Name, cqi)
+		if err != nil {
+			return err
+		}
+		if cq.intoRP() == "" {
+			cq.setIntoRP(dbi.DefaultRetentionPolicy)
+		}
+		if err := cq.q.SetTimeRange(start, end); err != nil {
+			return err
+		}
+
+		if s.loggingEnabled {
+			s.Logger.Info(fmt.Sprintf("recomputing continuous query %s for late points (%v to %v)", cq.Info.Name, start.UTC(), end.UTC()))
+		}
+		if res := s.runContinuousQueryAndWriteResult(cq); res.Err != nil {
+			return res.Err
+		}
+		atomic.AddInt64(&s.stats.LateWindowsRecomputed, int64(n))
+	}
+	return nil
+}
@@ -9,7 +9,9 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/influxdata/influxdb/coordinator"
 	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/pkg/clock"
 	"github.com/influxdata/influxdb/query"
 	"github.com/influxdata/influxdb/services/meta"
 	"github.com/influxdata/influxql"
@@ -29,8 +31,9 @@ const (
 
 // Statistics for the CQ service.
 const (
-	statQueryOK   = "queryOk"
-	statQueryFail = "queryFail"
+	statQueryOK               = "queryOk"
+	statQueryFail             = "queryFail"
+	statLateWindowsRecomputed = "lateWindowsRecomputed"
 )
 
 // ContinuousQuerier represents a service that executes continuous queries.
@@ -88,6 +91,7 @@ type Service struct {
 	// RunCh can be used by clients to signal service to run CQs.
 	RunCh             chan *RunRequest
 	Logger            zap.Logger
+	Clock             clock.Clock // replaced by tests to control when CQs run
 	loggingEnabled    bool
 	queryStatsEnabled bool
 	stats             *Statistics
@@ -96,6 +100,13 @@ type Service struct {
 	lastRuns map[string]time.Time
 	stop     chan struct{}
 	wg       *sync.WaitGroup
+
+	// Windows that received points after they were computed are recomputed
+	// within the late arrival horizon.
+	lateArrivalHorizon time.Duration
+	lateMu             sync.Mutex
+	lateWindows        map[string]*lateWindows
+	points             chan *coordinator.WritePointsRequest
 }
 
 // NewService returns a new instance of Service.
@@ -105,11 +116,16 @@ func NewService(c Config) *Service {
 		Monitor:           nullMonitor(0),
 		RunInterval:       time.Duration(c.RunInterval),
 		RunCh:             make(chan *RunRequest),
+		Clock:             clock.New(),
 		loggingEnabled:    c.LogEnabled,
 		queryStatsEnabled: c.QueryStatsEnabled,
 		Logger:            zap.New(zap.NullEncoder()),
 		stats:             &Statistics{},
 		lastRuns:          map[string]time.Time{},
+
+		lateArrivalHorizon: time.Duration(c.LateArrivalHorizon),
+		lateWindows:        make(map[string]*lateWindows),
+		points:             make(chan *coordinator.WritePointsRequest, 100),
 	}
 
 	return s
@@ -130,6 +146,10 @@ func (s *Service) Open() error {
 	s.wg = &sync.WaitGroup{}
 	s.wg.Add(1)
 	go s.backgroundLoop()
+	if s.lateArrivalHorizon > 0 {
+		s.wg.Add(1)
+		go s.trackLateArrivals(s.stop)
+	}
 	return nil
 }
 
@@ -152,8 +172,9 @@ func (s *Service) WithLogger(log zap.Logger) {
 
 // Statistics maintains the statistics for the continuous query service.
 type Statistics struct {
-	QueryOK   int64
-	QueryFail int64
+	QueryOK               int64
+	QueryFail             int64
+	LateWindowsRecomputed int64
 }
 
 type statistic struct {
@@ -167,8 +188,9 @@ func (s *Service) Statistics(tags map[string]string) []models.Statistic {
 		Name: "cq",
 		Tags: tags,
 		Values: map[string]interface{}{
-			statQueryOK:   atomic.LoadInt64(&s.stats.QueryOK),
-			statQueryFail: atomic.LoadInt64(&s.stats.QueryFail),
+			statQueryOK:               atomic.LoadInt64(&s.stats.QueryOK),
+			statQueryFail:             atomic.LoadInt64(&s.stats.QueryFail),
+			statLateWindowsRecomputed: atomic.LoadInt64(&s.stats.LateWindowsRecomputed),
 		},
 	}}
 }
@@ -212,7 +234,7 @@ func (s *Service) Run(database, name string, t time.Time) error {
 // backgroundLoop runs on a go routine and periodically executes CQs.
 func (s *Service) backgroundLoop() {
 	leaseName := "continuous_querier"
-	t := time.NewTimer(s.RunInterval)
+	t := s.Clock.NewTimer(s.RunInterval)
 	defer t.Stop()
 	defer s.wg.Done()
 	for {
@@ -234,7 +256,7 @@ func (s *Service) backgroundLoop() {
 				continue
 			}
 			if _, err := s.MetaClient.AcquireLease(leaseName); err == nil {
-				s.runContinuousQueries(&RunRequest{Now: time.Now()})
+				s.runContinuousQueries(&RunRequest{Now: s.Clock.Now()})
 			}
 			t.Reset(s.RunInterval)
 		}
@@ -271,8 +293,20 @@ func (s *Service) runContinuousQueries(req *RunRequest) {
 			} else if ok {
 				atomic.AddInt64(&s.stats.QueryOK, 1)
 			}
+
+			if s.lateArrivalHorizon > 0 {
+				if err := s.recomputeLateWindows(&db, &cq, req.Now); err != nil {
+					s.Logger.Info(fmt.Sprintf("error recomputing late windows: %s: err = %s", cq.Query, err))
+					atomic.AddInt64(&s.stats.QueryFail, 1)
+				}
+			}
 		}
 	}
+
+	// Stop tracking the windows of dropped CQs.
+	if s.lateArrivalHorizon > 0 {
+		s.pruneLateWindows(dbs)
+	}
 }
 
 // ExecuteContinuousQuery may execute a single CQ. This will return false if there were no errors and the CQ was not run.
@@ -367,7 +401,7 @@ func (s *Service) ExecuteContinuousQuery(dbi *meta.DatabaseInfo, cqi *meta.Conti
 
 	var start time.Time
 	if s.loggingEnabled || s.queryStatsEnabled {
-		start = time.Now()
+		start = s.Clock.Now()
 	}
 
 	if s.loggingEnabled {
@@ -383,7 +417,7 @@ func (s *Service) ExecuteContinuousQuery(dbi *meta.DatabaseInfo, cqi *meta.Conti
 
 	var execDuration time.Duration
 	if s.loggingEnabled || s.queryStatsEnabled {
-		execDuration = time.Since(start)
+		execDuration = s.Clock.Now().Sub(start)
 	}
 
 	// extract number of points written from SELECT ... INTO result
@@ -393,6 +427,10 @@ func (s *Service) ExecuteContinuousQuery(dbi *meta.DatabaseInfo, cqi *meta.Conti
 		written = s.Values[0][1].(int64)
 	}
 
+	if s.lateArrivalHorizon > 0 {
+		s.markComputed(id, cq, interval, offset, endTime)
+	}
+
 	if s.loggingEnabled {
 		s.Logger.Info(fmt.Sprintf("finished continuous query %s, %d points(s) written (%v to %v) in %s", cq.Info.Name, written, startTime, endTime, execDuration))
 	}
@@ -400,7 +438,7 @@ func (s *Service) ExecuteContinuousQuery(dbi *meta.DatabaseInfo, cqi *meta.Conti
 	if s.queryStatsEnabled && s.Monitor.Enabled() {
 		tags := map[string]string{"db": dbi.Name, "cq": cq.Info.Name}
 		fields := map[string]interface{}{"durationNs": int64(execDuration), "pointsWrittenOK": written, "startTime": startTime.UnixNano(), "endTime": endTime.UnixNano()}
-		p, _ := models.NewPoint("cq_query", models.NewTags(tags), fields, time.Now())
+		p, _ := models.NewPoint("cq_query", models.NewTags(tags), fields, s.Clock.Now())
 		s.Monitor.WritePoints(models.Points{p})
 	}
 
@@ -420,6 +458,7 @@ func (s *Service) runContinuousQueryAndWriteResult(cq *ContinuousQuery) *query.R
 	// Execute the SELECT.
 	ch := s.QueryExecutor.ExecuteQuery(q, query.ExecutionOptions{
 		Database: cq.Database,
+		Priority: query.BatchPriority,
 	}, closing)
 
 	// There is only one statement, so we will only ever receive one result
@@ -8,7 +8,9 @@ import (
 	"testing"
 	"time"
 
+	"github.com/influxdata/influxdb/coordinator"
 	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/pkg/clock"
 	"github.com/influxdata/influxdb/query"
 	"github.com/influxdata/influxdb/services/meta"
 	"github.com/influxdata/influxql"
@@ -466,6 +468,72 @@ func TestExecuteContinuousQuery_TimeRange(t *testing.T) {
 	}
 }
 
+// Ensure windows that receive points after they were computed are recomputed.
+func TestContinuousQueryService_LateArrivals(t *testing.T) {
+	now := mustParseTime(t, "2000-01-01T00:10:00Z")
+
+	s := NewTestService(t)
+	s.Clock = clock.NewMock(now)
+	s.lateArrivalHorizon = 30 * time.Minute
+	mc := NewMetaClient(t)
+	mc.CreateDatabase("db", "rp")
+	mc.CreateContinuousQuery("db", "cq", `CREATE CONTINUOUS QUERY cq ON db BEGIN SELECT mean(value) INTO cpu_mean FROM cpu GROUP BY time(1m) END`)
+	s.MetaClient = mc
+
+	var ranges []string
+	s.QueryExecutor.StatementExecutor = &StatementExecutor{
+		ExecuteStatementFn: func(stmt influxql.Statement, ctx query.ExecutionContext) error {
+			s := stmt.(*influxql.SelectStatement)
+			_, timeRange, err := influxql.ConditionExpr(s.Condition, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ranges = append(ranges, fmt.Sprintf("%s-%s", timeRange.Min.Format("15:04"), timeRange.Max.Add(time.Nanosecond).Format("15:04")))
+			ctx.Results <- &query.Result{}
+			return nil
+		},
+	}
+
+	db := mc.Database("db")
+	if ok, err := s.ExecuteContinuousQuery(db, &db.ContinuousQueries[0], now); !ok || err != nil {
+		t.Fatalf("ExecuteContinuousQuery failed, ok=%t, err=%v", ok, err)
+	}
+
+	// Points in computed windows are late unless they are past the horizon,
+	// written to another measurement or written by the CQ itself.
+	points, err := models.ParsePointsString("cpu value=1 " + fmt.Sprint(mustParseTime(t, "2000-01-01T00:05:30Z").UnixNano()) +
+		"\ncpu value=1 " + fmt.Sprint(mustParseTime(t, "2000-01-01T00:06:10Z").UnixNano()) +
+		"\ncpu value=1 " + fmt.Sprint(mustParseTime(t, "2000-01-01T00:08:00Z").UnixNano()) +
+		"\ncpu value=1 " + fmt.Sprint(mustParseTime(t, "1999-12-31T23:00:00Z").UnixNano()) +
+		"\nmem value=1 " + fmt.Sprint(mustParseTime(t, "2000-01-01T00:01:00Z").UnixNano()) +
+		"\ncpu value=1 " + fmt.Sprint(mustParseTime(t, "2000-01-01T00:11:00Z").UnixNano()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.markLateArrivals(&coordinator.WritePointsRequest{Database: "db", RetentionPolicy: "rp", Points: points})
+
+	results, err := models.ParsePointsString("cpu_mean mean=1 " + fmt.Sprint(mustParseTime(t, "2000-01-01T00:02:00Z").UnixNano()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.markLateArrivals(&coordinator.WritePointsRequest{Database: "db", RetentionPolicy: "rp", Points: results})
+
+	ranges = nil
+	if err := s.recomputeLateWindows(db, &db.ContinuousQueries[0], now); err != nil {
+		t.Fatal(err)
+	} else if got, exp := fmt.Sprint(ranges), "[00:05-00:07 00:08-00:09]"; got != exp {
+		t.Fatalf("unexpected recomputed ranges: got %s, exp %s", got, exp)
+	}
+
+	// Windows are only recomputed once.
+	ranges = nil
+	if err := s.recomputeLateWindows(db, &db.ContinuousQueries[0], now); err != nil {
+		t.Fatal(err)
+	} else if len(ranges) != 0 {
+		t.Fatalf("unexpected recomputed ranges: %v", ranges)
+	}
+}
+
 // Test the time range for different CQ durations.
 func TestExecuteContinuousQuery_TimeZone(t *testing.T) {
 	type test struct {
@@ -786,7 +854,7 @@ func (ms *MetaClient) CreateDatabase(name, defaultRetentionPolicy string) error
 
 	// Create database.
 	ms.DatabaseInfos = append(ms.DatabaseInfos, meta.DatabaseInfo{
-		Name: name,
+		Name:                   name,
 		DefaultRetentionPolicy: defaultRetentionPolicy,
 	})
 
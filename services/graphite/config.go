@@ -7,6 +7,7 @@ import (
 
 	"github.com/influxdata/influxdb/models"
 	"github.com/influxdata/influxdb/monitor/diagnostics"
+	"github.com/influxdata/influxdb/pkg/ipfilter"
 	"github.com/influxdata/influxdb/toml"
 )
 
@@ -65,6 +66,10 @@ type Config struct {
 	Tags             []string      `toml:"tags"`
 	Separator        string        `toml:"separator"`
 	UDPReadBuffer    int           `toml:"udp-read-buffer"`
+	MaxConnections   int           `toml:"max-connections"`
+	IdleTimeout      toml.Duration `toml:"idle-timeout"`
+	AllowedNetworks  []string      `toml:"allowed-networks"`
+	DeniedNetworks   []string      `toml:"denied-networks"`
 }
 
 // NewConfig returns a new instance of Config with defaults.
@@ -125,7 +130,7 @@ func (c *Config) DefaultTags() models.Tags {
 	return models.NewTags(m)
 }
 
-// Validate validates the config's templates and tags.
+// Validate validates the config's templates, tags and networks.
 func (c *Config) Validate() error {
 	if err := c.validateTemplates(); err != nil {
 		return err
@@ -135,6 +140,10 @@ func (c *Config) Validate() error {
 		return err
 	}
 
+	if _, err := ipfilter.New(c.AllowedNetworks, c.DeniedNetworks); err != nil {
+		return err
+	}
+
 	return nil
 }
 
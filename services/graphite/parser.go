@@ -125,11 +125,11 @@ func (p *Parser) Parse(line string) (models.Point, error) {
 		return nil, &UnsupportedValueError{Field: fields[0], Value: v}
 	}
 
-	fieldValues := map[string]interface{}{}
+	var fieldValues models.FieldsBuilder
 	if field != "" {
-		fieldValues[field] = v
+		fieldValues.AddFloat(field, v)
 	} else {
-		fieldValues["value"] = v
+		fieldValues.AddFloat("value", v)
 	}
 
 	// If no 3rd field, use now as timestamp
@@ -159,7 +159,7 @@ func (p *Parser) Parse(line string) (models.Point, error) {
 			tags[string(t.Key)] = string(t.Value)
 		}
 	}
-	return models.NewPoint(measurement, models.NewTags(tags), fieldValues, timestamp)
+	return models.NewPointWithFields(measurement, models.NewTags(tags), &fieldValues, timestamp)
 }
 
 // ApplyTemplate extracts the template fields from the given line and
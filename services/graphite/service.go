@@ -13,6 +13,7 @@ import (
 
 	"github.com/influxdata/influxdb/models"
 	"github.com/influxdata/influxdb/monitor/diagnostics"
+	"github.com/influxdata/influxdb/pkg/ipfilter"
 	"github.com/influxdata/influxdb/services/meta"
 	"github.com/influxdata/influxdb/tsdb"
 	"github.com/uber-go/zap"
@@ -31,6 +32,7 @@ const (
 	statBatchesTransmitFail = "batchesTxFail"
 	statConnectionsActive   = "connsActive"
 	statConnectionsHandled  = "connsHandled"
+	statConnectionsRejected = "connsRejected"
 )
 
 type tcpConnection struct {
@@ -52,6 +54,9 @@ type Service struct {
 	batchPending    int
 	batchTimeout    time.Duration
 	udpReadBuffer   int
+	maxConnections  int
+	idleTimeout     time.Duration
+	filter          *ipfilter.Filter
 
 	batcher *tsdb.PointBatcher
 	parser  *Parser
@@ -94,6 +99,11 @@ func NewService(c Config) (*Service, error) {
 	// Use defaults where necessary.
 	d := c.WithDefaults()
 
+	filter, err := ipfilter.New(d.AllowedNetworks, d.DeniedNetworks)
+	if err != nil {
+		return nil, err
+	}
+
 	s := Service{
 		bindAddress:     d.BindAddress,
 		database:        d.Database,
@@ -102,6 +112,9 @@ func NewService(c Config) (*Service, error) {
 		batchSize:       d.BatchSize,
 		batchPending:    d.BatchPending,
 		udpReadBuffer:   d.UDPReadBuffer,
+		maxConnections:  d.MaxConnections,
+		idleTimeout:     time.Duration(d.IdleTimeout),
+		filter:          filter,
 		batchTimeout:    time.Duration(d.BatchTimeout),
 		logger:          zap.New(zap.NullEncoder()),
 		stats:           &Statistics{},
@@ -277,6 +290,7 @@ type Statistics struct {
 	BatchesTransmitFail int64
 	ActiveConnections   int64
 	HandledConnections  int64
+	RejectedConnections int64
 }
 
 // Statistics returns statistics for periodic monitoring.
@@ -294,6 +308,7 @@ func (s *Service) Statistics(tags map[string]string) []models.Statistic {
 			statBatchesTransmitFail: atomic.LoadInt64(&s.stats.BatchesTransmitFail),
 			statConnectionsActive:   atomic.LoadInt64(&s.stats.ActiveConnections),
 			statConnectionsHandled:  atomic.LoadInt64(&s.stats.HandledConnections),
+			statConnectionsRejected: atomic.LoadInt64(&s.stats.RejectedConnections),
 		},
 	}}
 }
@@ -325,6 +340,21 @@ func (s *Service) openTCPServer() (net.Addr, error) {
 				continue
 			}
 
+			// Drop connections from outside of the allowed networks.
+			if !s.filter.AllowedAddr(conn.RemoteAddr()) {
+				atomic.AddInt64(&s.stats.RejectedConnections, 1)
+				conn.Close()
+				continue
+			}
+
+			// Drop connections over the limit.
+			if n := atomic.AddInt64(&s.stats.ActiveConnections, 1); s.maxConnections > 0 && n > int64(s.maxConnections) {
+				atomic.AddInt64(&s.stats.ActiveConnections, -1)
+				atomic.AddInt64(&s.stats.RejectedConnections, 1)
+				conn.Close()
+				continue
+			}
+
 			s.wg.Add(1)
 			go s.handleTCPConnection(conn)
 		}
@@ -338,13 +368,17 @@ func (s *Service) handleTCPConnection(conn net.Conn) {
 	defer conn.Close()
 	defer atomic.AddInt64(&s.stats.ActiveConnections, -1)
 	defer s.untrackConnection(conn)
-	atomic.AddInt64(&s.stats.ActiveConnections, 1)
 	atomic.AddInt64(&s.stats.HandledConnections, 1)
 	s.trackConnection(conn)
 
 	reader := bufio.NewReader(conn)
 
 	for {
+		// Close connections that stay idle.
+		if s.idleTimeout > 0 {
+			conn.SetReadDeadline(time.Now().Add(s.idleTimeout))
+		}
+
 		// Read up to the next newline.
 		buf, err := reader.ReadBytes('\n')
 		if err != nil {
@@ -399,10 +433,12 @@ func (s *Service) openUDPServer() (net.Addr, error) {
 	go func() {
 		defer s.wg.Done()
 		for {
-			n, _, err := s.udpConn.ReadFromUDP(buf)
+			n, addr, err := s.udpConn.ReadFromUDP(buf)
 			if err != nil {
 				s.udpConn.Close()
 				return
+			} else if !s.filter.Allowed(addr.IP) {
+				continue
 			}
 
 			lines := strings.Split(string(buf[:n]), "\n")
@@ -3,9 +3,11 @@ package graphite
 import (
 	"errors"
 	"fmt"
+	"io"
 	"net"
 	"os"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -194,6 +196,80 @@ func Test_Service_TCP(t *testing.T) {
 	wg.Wait()
 }
 
+// Ensure connections over the limit are dropped and idle connections closed.
+func Test_Service_TCP_Limits(t *testing.T) {
+	t.Parallel()
+
+	config := Config{}
+	config.BindAddress = "127.0.0.1:0"
+	config.MaxConnections = 1
+	config.IdleTimeout = toml.Duration(100 * time.Millisecond)
+
+	service := NewTestService(&config)
+	if err := service.Service.Open(); err != nil {
+		t.Fatalf("failed to open Graphite service: %s", err.Error())
+	}
+	defer service.Service.Close()
+
+	conn, err := net.Dial("tcp", service.Service.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	// Wait for the first connection to be accepted.
+	for atomic.LoadInt64(&service.Service.stats.ActiveConnections) != 1 {
+		time.Sleep(time.Millisecond)
+	}
+
+	// A second connection is closed by the server.
+	conn2, err := net.Dial("tcp", service.Service.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn2.Close()
+	conn2.SetReadDeadline(time.Now().Add(5 * time.Second))
+	if _, err := conn2.Read(make([]byte, 1)); err != io.EOF {
+		t.Fatalf("unexpected error: %v", err)
+	} else if n := atomic.LoadInt64(&service.Service.stats.RejectedConnections); n != 1 {
+		t.Fatalf("unexpected rejected connections: %d", n)
+	}
+
+	// The first connection is closed once it stays idle.
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	if _, err := conn.Read(make([]byte, 1)); err != io.EOF {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func Test_Service_TCP_DeniedNetworks(t *testing.T) {
+	t.Parallel()
+
+	config := Config{}
+	config.BindAddress = "127.0.0.1:0"
+	config.DeniedNetworks = []string{"127.0.0.0/8"}
+
+	service := NewTestService(&config)
+	if err := service.Service.Open(); err != nil {
+		t.Fatalf("failed to open Graphite service: %s", err.Error())
+	}
+	defer service.Service.Close()
+
+	conn, err := net.Dial("tcp", service.Service.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	// The connection is closed by the server.
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	if _, err := conn.Read(make([]byte, 1)); err != io.EOF {
+		t.Fatalf("unexpected error: %v", err)
+	} else if n := atomic.LoadInt64(&service.Service.stats.RejectedConnections); n != 1 {
+		t.Fatalf("unexpected rejected connections: %d", n)
+	}
+}
+
 func Test_Service_UDP(t *testing.T) {
 	t.Parallel()
 
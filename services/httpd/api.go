@@ -0,0 +1,163 @@
+package httpd
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/query/pipeline"
+	"github.com/influxdata/influxdb/query/sqlcompat"
+	"github.com/influxdata/influxdb/services/meta"
+)
+
+// APIVersions lists the versions of the HTTP API served under /api/<version>.
+// The unversioned /query and /write endpoints are aliases of version 1 so
+// existing clients keep working as later versions change request formats.
+var APIVersions = []string{"v1", "v2"}
+
+// v1Routes returns the query and write routes of version 1 of the API.
+func (h *Handler) v1Routes() []Route {
+	return []Route{
+		{"query-options", "OPTIONS", "/query", false, true, h.serveOptions},
+		{"query", "GET", "/query", true, true, h.serveQuery},
+		{"query", "POST", "/query", true, true, h.serveQuery},
+		{"write-options", "OPTIONS", "/write", false, true, h.serveOptions},
+		{"write", "POST", "/write", true, true, h.serveWrite},
+		{"query-cursor", "GET", "/query/cursors/:id", true, true, h.serveQueryCursor},
+		{"query-cursor-close", "DELETE", "/query/cursors/:id", false, true, h.serveCloseQueryCursor},
+	}
+}
+
+// v2Routes returns the routes of version 2 of the API. Version 2 addresses
+// data by bucket rather than by database and retention policy. The pipeline
+// query endpoint is only served when it is enabled in the config.
+func (h *Handler) v2Routes() []Route {
+	routes := []Route{
+		{"write-options", "OPTIONS", "/write", false, true, h.serveOptions},
+		{"write", "POST", "/write", true, true, h.serveWriteV2},
+	}
+	if h.Config.PipelineEnabled {
+		routes = append(routes,
+			Route{"query-options", "OPTIONS", "/query", false, true, h.serveOptions},
+			Route{"pipeline-query", "POST", "/query", true, true, h.servePipelineQuery},
+		)
+	}
+	return routes
+}
+
+// isAPIPath returns true if path is the unversioned pattern or the pattern
+// of one of the API versions.
+func isAPIPath(path, pattern string) bool {
+	if path == pattern {
+		return true
+	}
+	for _, version := range APIVersions {
+		if path == "/api/"+version+pattern {
+			return true
+		}
+	}
+	return false
+}
+
+// versionedRoutes returns the routes of every API version with their
+// patterns prefixed by /api/<version>.
+func (h *Handler) versionedRoutes() []Route {
+	var routes []Route
+	for _, version := range APIVersions {
+		var rs []Route
+		switch version {
+		case "v1":
+			rs = h.v1Routes()
+		case "v2":
+			rs = h.v2Routes()
+		}
+		for _, r := range rs {
+			r.Pattern = "/api/" + version + r.Pattern
+			routes = append(routes, r)
+		}
+	}
+	return routes
+}
+
+// serveWriteV2 receives line protocol for a bucket. A bucket names a
+// database, optionally followed by a slash and a retention policy. The
+// organization parameter is accepted for compatibility and ignored.
+func (h *Handler) serveWriteV2(w http.ResponseWriter, r *http.Request, user meta.User) {
+	q := r.URL.Query()
+	bucket := q.Get("bucket")
+	if bucket == "" {
+		h.httpError(w, "bucket is required", http.StatusBadRequest)
+		return
+	}
+
+	db, rp := bucket, ""
+	if i := strings.IndexByte(bucket, '/'); i >= 0 {
+		db, rp = bucket[:i], bucket[i+1:]
+	}
+	q.Set("db", db)
+	q.Set("rp", rp)
+	q.Del("bucket")
+	q.Del("org")
+	r.URL.RawQuery = q.Encode()
+
+	h.serveWrite(w, r, user)
+}
+
+// servePipelineQuery compiles the pipeline query in the request body to
+// InfluxQL and executes it against the database named by from(). The response
+// has the same format as the /query endpoint and accepts the same epoch and
+// chunking parameters.
+func (h *Handler) servePipelineQuery(w http.ResponseWriter, r *http.Request, user meta.User) {
+	rd := r.Body
+	if h.Config.MaxBodySize > 0 {
+		if r.ContentLength > int64(h.Config.MaxBodySize) {
+			h.httpError(w, http.StatusText(http.StatusRequestEntityTooLarge), http.StatusRequestEntityTooLarge)
+			return
+		}
+		rd = truncateReader(rd, int64(h.Config.MaxBodySize))
+	}
+
+	body, err := ioutil.ReadAll(rd)
+	if err == errTruncated {
+		h.httpError(w, http.StatusText(http.StatusRequestEntityTooLarge), http.StatusRequestEntityTooLarge)
+		return
+	} else if err != nil {
+		h.httpError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	pq, err := pipeline.Compile(string(body))
+	if err != nil {
+		h.httpCodedError(w, influxdb.NewError(influxdb.ErrorCodeInvalidQuery, "error parsing pipeline: "+err.Error()), http.StatusBadRequest)
+		return
+	}
+
+	q := r.URL.Query()
+	q.Set("q", pq.String())
+	q.Set("db", pq.Database)
+	r.URL.RawQuery = q.Encode()
+	r.Form = nil
+
+	h.serveQuery(w, r, user)
+}
+
+// serveSQLQuery translates the SQL SELECT statement in the "q" parameter to
+// InfluxQL and executes it. Only read queries can be expressed in the SQL
+// dialect, so the endpoint never modifies data.
+func (h *Handler) serveSQLQuery(w http.ResponseWriter, r *http.Request, user meta.User) {
+	sql := strings.TrimSpace(r.FormValue("q"))
+	if sql == "" {
+		h.httpError(w, `missing required parameter "q"`, http.StatusBadRequest)
+		return
+	}
+
+	stmt, err := sqlcompat.Translate(sql)
+	if err != nil {
+		h.httpCodedError(w, influxdb.NewError(influxdb.ErrorCodeInvalidQuery, "error parsing query: "+err.Error()), http.StatusBadRequest)
+		return
+	}
+	r.Form.Set("q", stmt.String())
+
+	h.serveQuery(w, r, user)
+}
@@ -0,0 +1,149 @@
+package httpd
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/influxdata/influxdb/services/cdc"
+	"github.com/influxdata/influxdb/services/meta"
+	"github.com/influxdata/influxql"
+)
+
+const (
+	// DefaultChangesLimit is the default number of writes returned by a
+	// request to the change stream.
+	DefaultChangesLimit = 100
+
+	// DefaultChangesTimeout is the default time a request to the change
+	// stream waits for a write.
+	DefaultChangesTimeout = 30 * time.Second
+)
+
+// changesResponse is the body returned by the change stream endpoint.
+type changesResponse struct {
+	Database  string   `json:"database"`
+	Changes   []change `json:"changes"`
+	Next      uint64   `json:"next"`
+	Truncated bool     `json:"truncated,omitempty"`
+}
+
+// change is a committed write encoded as line protocol with nanosecond
+// timestamps.
+type change struct {
+	Seq             uint64 `json:"seq"`
+	RetentionPolicy string `json:"retention_policy"`
+	Points          string `json:"points"`
+	Dropped         bool   `json:"dropped,omitempty"`
+}
+
+// serveChanges long-polls the stream of writes committed to a database. A
+// consumer passes the "next" value of each response as the "since"
+// parameter of its next request. A truncated response means writes were
+// missed, either before the oldest retained write the changes start at or
+// as changes marked dropped.
+func (h *Handler) serveChanges(w http.ResponseWriter, r *http.Request, user meta.User) {
+	if h.ChangeStream == nil {
+		h.httpError(w, "change stream is not enabled", http.StatusNotFound)
+		return
+	}
+
+	q := r.URL.Query()
+	db := q.Get("db")
+	if db == "" {
+		h.httpError(w, "database is required", http.StatusBadRequest)
+		return
+	}
+	if h.Config.AuthEnabled && (user == nil || !user.AuthorizeDatabase(influxql.ReadPrivilege, db)) {
+		h.httpError(w, "user is not authorized to read from database "+strconv.Quote(db), http.StatusForbidden)
+		return
+	}
+
+	var since uint64
+	if s := q.Get("since"); s != "" {
+		n, err := strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			h.httpError(w, "invalid since: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		since = n
+	}
+
+	limit := DefaultChangesLimit
+	if s := q.Get("limit"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n <= 0 {
+			h.httpError(w, "limit must be a positive integer", http.StatusBadRequest)
+			return
+		}
+		limit = n
+	}
+
+	timeout := DefaultChangesTimeout
+	if s := q.Get("timeout"); s != "" {
+		d, err := time.ParseDuration(s)
+		if err != nil || d < 0 {
+			h.httpError(w, "invalid timeout: "+s, http.StatusBadRequest)
+			return
+		}
+		timeout = d
+	}
+
+	// Stop waiting if the client disconnects.
+	var done <-chan struct{}
+	if notifier, ok := w.(http.CloseNotifier); ok {
+		notify := notifier.CloseNotify()
+		ch := make(chan struct{})
+		finished := make(chan struct{})
+		defer close(finished)
+		go func() {
+			select {
+			case <-notify:
+				close(ch)
+			case <-finished:
+			}
+		}()
+		done = ch
+	}
+
+	changes, truncated := h.ChangeStream.Changes(db, since, limit, timeout, done)
+	resp := changesResponse{
+		Database:  db,
+		Changes:   make([]change, 0, len(changes)),
+		Next:      since,
+		Truncated: truncated,
+	}
+	if truncated {
+		resp.Next = 0
+	}
+	for _, c := range changes {
+		resp.Changes = append(resp.Changes, encodeChange(c))
+		resp.Next = c.Seq
+	}
+
+	b, err := json.Marshal(resp)
+	if err != nil {
+		h.httpError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Add("Content-Type", "application/json")
+	h.writeHeader(w, http.StatusOK)
+	w.Write(b)
+	w.Write([]byte("\n"))
+}
+
+// encodeChange encodes the points of a change as line protocol.
+func encodeChange(c cdc.Change) change {
+	lines := make([]string, len(c.Points))
+	for i, p := range c.Points {
+		lines[i] = p.String()
+	}
+	return change{
+		Seq:             c.Seq,
+		RetentionPolicy: c.RetentionPolicy,
+		Points:          strings.Join(lines, "\n"),
+		Dropped:         c.Dropped,
+	}
+}
@@ -0,0 +1,137 @@
+package httpd
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/query"
+	"github.com/influxdata/influxql"
+)
+
+// queryCoalescer executes identical queries that arrive concurrently once
+// and shares the results with every request waiting on them. Dashboards
+// commonly refresh many panels with the same query at the same moment.
+type queryCoalescer struct {
+	mu    sync.Mutex
+	calls map[string]*coalescedQuery
+}
+
+// coalescedQuery is a query executed on behalf of one or more requests.
+type coalescedQuery struct {
+	done    chan struct{} // closed once results holds every result
+	closing chan struct{} // closed to abort the query when no request waits on it
+	waiters int
+	results []*query.Result
+}
+
+func newQueryCoalescer() *queryCoalescer {
+	return &queryCoalescer{calls: make(map[string]*coalescedQuery)}
+}
+
+// execute returns the results of the query identified by key, starting it
+// with fn unless an identical query is already executing. Each request
+// receives its own copy of the results because they are modified while the
+// response is written. A request stops waiting when closing is closed, and
+// the query is aborted once every request has stopped waiting.
+func (c *queryCoalescer) execute(key string, closing <-chan struct{}, fn func(closing chan struct{}) <-chan *query.Result) (results <-chan *query.Result, shared bool) {
+	c.mu.Lock()
+	call, shared := c.calls[key]
+	if !shared {
+		call = &coalescedQuery{done: make(chan struct{}), closing: make(chan struct{})}
+		c.calls[key] = call
+		go c.run(key, call, fn(call.closing))
+	}
+	call.waiters++
+	c.mu.Unlock()
+
+	out := make(chan *query.Result)
+	go func() {
+		defer close(out)
+		select {
+		case <-call.done:
+		case <-closing:
+			c.leave(key, call)
+			return
+		}
+		for _, r := range call.results {
+			out <- copyResult(r)
+		}
+	}()
+	return out, shared
+}
+
+// run collects the results of a query and releases its waiters.
+func (c *queryCoalescer) run(key string, call *coalescedQuery, results <-chan *query.Result) {
+	for r := range results {
+		if r != nil {
+			call.results = append(call.results, r)
+		}
+	}
+
+	c.mu.Lock()
+	if c.calls[key] == call {
+		delete(c.calls, key)
+	}
+	c.mu.Unlock()
+	close(call.done)
+}
+
+// leave removes a waiter from a query and aborts the query if it was the
+// last one.
+func (c *queryCoalescer) leave(key string, call *coalescedQuery) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	call.waiters--
+	if call.waiters == 0 && c.calls[key] == call {
+		delete(c.calls, key)
+		close(call.closing)
+	}
+}
+
+// coalesceKey returns the key identifying a query for coalescing, or false
+// if the query must not be shared. Only read queries are shared and, when
+// authentication is enabled, only between requests of the same user since
+// users may be authorized to see different series.
+func coalesceKey(q *influxql.Query, opts query.ExecutionOptions, user string) (string, bool) {
+	for _, stmt := range q.Statements {
+		if sel, ok := stmt.(*influxql.SelectStatement); !ok || sel.Target != nil {
+			return "", false
+		}
+	}
+	return strings.Join([]string{
+		opts.Database,
+		user,
+		strconv.FormatUint(opts.NodeID, 10),
+		strconv.FormatBool(opts.IncludeStats),
+		strconv.FormatBool(opts.AbortOnError),
+		opts.Timeout.String(),
+		q.String(),
+	}, "\x00"), true
+}
+
+// copyResult returns a copy of a result that can be modified without
+// affecting the original.
+func copyResult(r *query.Result) *query.Result {
+	other := *r
+	if r.Messages != nil {
+		other.Messages = append([]*query.Message(nil), r.Messages...)
+	}
+	if r.Stats != nil {
+		stats := *r.Stats
+		other.Stats = &stats
+	}
+	if r.Series != nil {
+		other.Series = make(models.Rows, len(r.Series))
+		for i, row := range r.Series {
+			cp := *row
+			cp.Values = make([][]interface{}, len(row.Values))
+			for j, values := range row.Values {
+				cp.Values[j] = append([]interface{}(nil), values...)
+			}
+			other.Series[i] = &cp
+		}
+	}
+	return &other
+}
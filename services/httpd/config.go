@@ -1,6 +1,14 @@
 package httpd
 
-import "github.com/influxdata/influxdb/monitor/diagnostics"
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/influxdata/influxdb/monitor/diagnostics"
+	"github.com/influxdata/influxdb/pkg/ipfilter"
+	"github.com/influxdata/influxdb/toml"
+)
 
 const (
 	// DefaultBindAddress is the default address to bind to.
@@ -14,6 +22,18 @@ const (
 
 	// DefaultMaxBodySize is the default maximum size of a client request body, in bytes. Specify 0 for no limit.
 	DefaultMaxBodySize = 25e6
+
+	// DefaultCursorTimeout is the default time a query cursor is kept open
+	// between requests for its results.
+	DefaultCursorTimeout = time.Minute
+
+	// DefaultMaxOpenCursors is the default maximum number of query cursors
+	// that can be open at once.
+	DefaultMaxOpenCursors = 100
+
+	// DefaultWatermarkSessionTimeout is the default time after its last write
+	// that a write session stops holding back the watermark of a database.
+	DefaultWatermarkSessionTimeout = 5 * time.Minute
 )
 
 // Config represents a configuration for a HTTP service.
@@ -34,6 +54,71 @@ type Config struct {
 	UnixSocketEnabled  bool   `toml:"unix-socket-enabled"`
 	BindSocket         string `toml:"bind-socket"`
 	MaxBodySize        int    `toml:"max-body-size"`
+
+	// Timeouts of the connections of clients. ReadHeaderTimeout limits how
+	// long a client may take to send the headers of a request, which stops
+	// slow clients from holding connections open. Zero disables a timeout.
+	ReadTimeout       toml.Duration `toml:"read-timeout"`
+	ReadHeaderTimeout toml.Duration `toml:"read-header-timeout"`
+	WriteTimeout      toml.Duration `toml:"write-timeout"`
+	IdleTimeout       toml.Duration `toml:"idle-timeout"`
+
+	// MaxHeaderBytes is the maximum size of the headers of a request. Zero
+	// uses the default of the net/http package.
+	MaxHeaderBytes int `toml:"max-header-bytes"`
+
+	// QueryStatsEnabled records the fingerprint, duration, number of rows
+	// returned and failure of each query in the monitor database.
+	QueryStatsEnabled bool `toml:"query-stats-enabled"`
+
+	// QueryCoalescingEnabled executes identical read queries that arrive
+	// concurrently once and returns the results to every request.
+	QueryCoalescingEnabled bool `toml:"query-coalescing-enabled"`
+
+	// CursorTimeout is how long the remaining results of a paged query are
+	// kept after the last request for them. Zero uses DefaultCursorTimeout.
+	CursorTimeout toml.Duration `toml:"cursor-timeout"`
+
+	// MaxOpenCursors is the maximum number of paged queries whose cursors
+	// can be open at once. Further paged queries are rejected with 429 Too
+	// Many Requests. Zero means no limit.
+	MaxOpenCursors int `toml:"max-open-cursors"`
+
+	// WatermarkSessionTimeout is how long after its last write a write
+	// session holds back the watermark of a database. Zero uses
+	// DefaultWatermarkSessionTimeout.
+	WatermarkSessionTimeout toml.Duration `toml:"watermark-session-timeout"`
+
+	// PipelineEnabled serves the experimental pipeline query language at
+	// /api/v2/query.
+	PipelineEnabled bool `toml:"pipeline-enabled"`
+
+	// SeriesKeyDiagnosticsEnabled counts and logs written points whose
+	// series keys are not in canonical form.
+	SeriesKeyDiagnosticsEnabled bool `toml:"series-key-diagnostics-enabled"`
+
+	// JSONWriteEnabled accepts writes in the deprecated JSON format of old
+	// clients at /write when they are sent as application/json.
+	JSONWriteEnabled bool `toml:"json-write-enabled"`
+
+	// AllowedNetworks and DeniedNetworks restrict the addresses that
+	// clients connect from. See ipfilter.New.
+	AllowedNetworks []string `toml:"allowed-networks"`
+	DeniedNetworks  []string `toml:"denied-networks"`
+
+	// QueryTemplates are named queries that clients may execute by name
+	// using the "template" parameter of the /query endpoint. They are only
+	// defined here since the InfluxQL grammar comes from the influxql
+	// package and has no statement to create them at runtime.
+	QueryTemplates []QueryTemplate `toml:"query-template"`
+}
+
+// QueryTemplate is a query stored in the server configuration that can be
+// executed by name. The query may reference bound parameters (e.g. $host)
+// which are supplied by the client through the "params" parameter.
+type QueryTemplate struct {
+	Name  string `toml:"name"`
+	Query string `toml:"query"`
 }
 
 // NewConfig returns a new Config with default settings.
@@ -50,7 +135,43 @@ func NewConfig() Config {
 		UnixSocketEnabled: false,
 		BindSocket:        DefaultBindSocket,
 		MaxBodySize:       DefaultMaxBodySize,
+		CursorTimeout:     toml.Duration(DefaultCursorTimeout),
+		MaxOpenCursors:    DefaultMaxOpenCursors,
+
+		WatermarkSessionTimeout: toml.Duration(DefaultWatermarkSessionTimeout),
+	}
+}
+
+// Validate returns an error if the config is invalid.
+func (c Config) Validate() error {
+	if c.CursorTimeout < 0 {
+		return errors.New("cursor-timeout must not be negative")
+	} else if c.MaxOpenCursors < 0 {
+		return errors.New("max-open-cursors must not be negative")
+	} else if c.WatermarkSessionTimeout < 0 {
+		return errors.New("watermark-session-timeout must not be negative")
+	} else if c.ReadTimeout < 0 || c.ReadHeaderTimeout < 0 || c.WriteTimeout < 0 || c.IdleTimeout < 0 {
+		return errors.New("connection timeouts must not be negative")
+	} else if c.MaxHeaderBytes < 0 {
+		return errors.New("max-header-bytes must not be negative")
+	} else if _, err := ipfilter.New(c.AllowedNetworks, c.DeniedNetworks); err != nil {
+		return err
+	}
+
+	names := make(map[string]struct{}, len(c.QueryTemplates))
+	for i, t := range c.QueryTemplates {
+		if t.Name == "" {
+			return fmt.Errorf("missing query template name at position: %d", i)
+		} else if t.Query == "" {
+			return fmt.Errorf("missing query for query template: %q", t.Name)
+		}
+
+		if _, ok := names[t.Name]; ok {
+			return fmt.Errorf("duplicate query template: %q", t.Name)
+		}
+		names[t.Name] = struct{}{}
 	}
+	return nil
 }
 
 // Diagnostics returns a diagnostics representation of a subset of the Config.
@@ -62,10 +183,24 @@ func (c Config) Diagnostics() (*diagnostics.Diagnostics, error) {
 	}
 
 	return diagnostics.RowFromMap(map[string]interface{}{
-		"enabled":              true,
-		"bind-address":         c.BindAddress,
-		"https-enabled":        c.HTTPSEnabled,
-		"max-row-limit":        c.MaxRowLimit,
-		"max-connection-limit": c.MaxConnectionLimit,
+		"enabled":                        true,
+		"bind-address":                   c.BindAddress,
+		"https-enabled":                  c.HTTPSEnabled,
+		"max-row-limit":                  c.MaxRowLimit,
+		"max-connection-limit":           c.MaxConnectionLimit,
+		"read-timeout":                   c.ReadTimeout,
+		"read-header-timeout":            c.ReadHeaderTimeout,
+		"write-timeout":                  c.WriteTimeout,
+		"idle-timeout":                   c.IdleTimeout,
+		"max-header-bytes":               c.MaxHeaderBytes,
+		"query-templates":                len(c.QueryTemplates),
+		"query-stats-enabled":            c.QueryStatsEnabled,
+		"pipeline-enabled":               c.PipelineEnabled,
+		"query-coalescing-enabled":       c.QueryCoalescingEnabled,
+		"cursor-timeout":                 c.CursorTimeout,
+		"max-open-cursors":               c.MaxOpenCursors,
+		"watermark-session-timeout":      c.WatermarkSessionTimeout,
+		"series-key-diagnostics-enabled": c.SeriesKeyDiagnosticsEnabled,
+		"json-write-enabled":             c.JSONWriteEnabled,
 	}), nil
 }
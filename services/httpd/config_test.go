@@ -49,6 +49,40 @@ max-body-size = 100
 	}
 }
 
+func TestConfig_Parse_QueryTemplates(t *testing.T) {
+	var c httpd.Config
+	if _, err := toml.Decode(`
+[[query-template]]
+name = "cpu"
+query = "SELECT * FROM cpu WHERE host = $host"
+`, &c); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(c.QueryTemplates) != 1 {
+		t.Fatalf("unexpected query templates: %v", c.QueryTemplates)
+	} else if c.QueryTemplates[0].Name != "cpu" {
+		t.Fatalf("unexpected query template name: %s", c.QueryTemplates[0].Name)
+	} else if c.QueryTemplates[0].Query != "SELECT * FROM cpu WHERE host = $host" {
+		t.Fatalf("unexpected query template query: %s", c.QueryTemplates[0].Query)
+	} else if err := c.Validate(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+}
+
+func TestConfig_Validate_QueryTemplates(t *testing.T) {
+	c := httpd.NewConfig()
+	c.QueryTemplates = []httpd.QueryTemplate{{Name: "cpu", Query: "SELECT * FROM cpu"}, {Name: "cpu", Query: "SELECT * FROM mem"}}
+	if err := c.Validate(); err == nil || err.Error() != `duplicate query template: "cpu"` {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	c.QueryTemplates = []httpd.QueryTemplate{{Name: "cpu"}}
+	if err := c.Validate(); err == nil || err.Error() != `missing query for query template: "cpu"` {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
 func TestConfig_WriteTracing(t *testing.T) {
 	c := httpd.Config{WriteTracing: true}
 	s := httpd.NewService(c)
@@ -0,0 +1,245 @@
+package httpd
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/influxdata/influxdb/query"
+	"github.com/influxdata/influxdb/services/meta"
+)
+
+// queryCursor holds the remaining results of a paged query between the
+// requests that fetch them. The query keeps executing in the background and
+// produces the next chunk only once the previous one has been fetched.
+type queryCursor struct {
+	id      string
+	user    string
+	epoch   string
+	tabular bool
+
+	results   <-chan *query.Result
+	next      *query.Result
+	closing   chan struct{}
+	closeOnce sync.Once
+	timer     *time.Timer
+}
+
+// fetch returns the next result of the cursor and whether more results
+// remain. It reads one result ahead so the last page has no cursor.
+func (c *queryCursor) fetch() (*query.Result, bool) {
+	r := c.next
+	c.next = nil
+	for next := range c.results {
+		if next != nil {
+			c.next = next
+			break
+		}
+	}
+	return r, c.next != nil
+}
+
+// abort stops the query of the cursor and discards its remaining results.
+// It is safe to call more than once.
+func (c *queryCursor) abort() {
+	c.closeOnce.Do(func() {
+		close(c.closing)
+		go func() {
+			for range c.results {
+			}
+		}()
+	})
+}
+
+var (
+	// errTooManyCursors is returned when the maximum number of cursors is open.
+	errTooManyCursors = errors.New("too many open cursors")
+
+	// errCursorsClosed is returned when a cursor is opened after shutdown.
+	errCursorsClosed = errors.New("cursors closed")
+)
+
+// cursorStore holds the open cursors of paged queries. A cursor that is not
+// fetched from within the timeout is closed and its query aborted.
+type cursorStore struct {
+	mu      sync.Mutex
+	cursors map[string]*queryCursor // cursors waiting for their next fetch
+	all     map[string]*queryCursor // open cursors, including those being fetched from
+	closed  bool
+
+	timeout time.Duration
+	maxN    int
+}
+
+func newCursorStore(timeout time.Duration, maxN int) *cursorStore {
+	if timeout == 0 {
+		timeout = DefaultCursorTimeout
+	}
+	return &cursorStore{
+		cursors: make(map[string]*queryCursor),
+		all:     make(map[string]*queryCursor),
+		timeout: timeout,
+		maxN:    maxN,
+	}
+}
+
+// open returns a cursor over results. The query is aborted by closing
+// closing. Only user may fetch from the cursor. If the maximum number of
+// cursors is already open or the store is closed, the query is aborted and
+// an error is returned.
+func (s *cursorStore) open(results <-chan *query.Result, closing chan struct{}, user string) (*queryCursor, error) {
+	c := &queryCursor{
+		user:    user,
+		results: results,
+		closing: closing,
+	}
+
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		c.abort()
+		return nil, err
+	}
+	c.id = hex.EncodeToString(b[:])
+
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		c.abort()
+		return nil, errCursorsClosed
+	} else if s.maxN > 0 && len(s.all) >= s.maxN {
+		s.mu.Unlock()
+		c.abort()
+		return nil, errTooManyCursors
+	}
+	s.all[c.id] = c
+	s.mu.Unlock()
+
+	c.fetch()
+	return c, nil
+}
+
+// remove forgets a cursor whose results have all been fetched or whose
+// query was aborted.
+func (s *cursorStore) remove(c *queryCursor) {
+	s.mu.Lock()
+	delete(s.all, c.id)
+	s.mu.Unlock()
+}
+
+// closeAll aborts the queries of all open cursors. Cursors cannot be created
+// once the store is closed.
+func (s *cursorStore) closeAll() {
+	s.mu.Lock()
+	cursors := s.all
+	for _, c := range s.cursors {
+		c.timer.Stop()
+	}
+	s.cursors = make(map[string]*queryCursor)
+	s.all = make(map[string]*queryCursor)
+	s.closed = true
+	s.mu.Unlock()
+
+	for _, c := range cursors {
+		c.abort()
+	}
+}
+
+// take removes the cursor with the given id from the store so that the
+// caller has exclusive use of it. It returns nil if there is no such cursor
+// for user.
+func (s *cursorStore) take(id, user string) *queryCursor {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c := s.cursors[id]
+	if c == nil || c.user != user {
+		return nil
+	}
+	delete(s.cursors, id)
+	c.timer.Stop()
+	return c
+}
+
+// put returns a cursor to the store and restarts its expiry. It returns
+// false and aborts the cursor if the store was closed in the meantime.
+func (s *cursorStore) put(c *queryCursor) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.all[c.id]; !ok {
+		c.abort()
+		return false
+	}
+	s.cursors[c.id] = c
+	c.timer = time.AfterFunc(s.timeout, func() {
+		s.mu.Lock()
+		expired := s.cursors[c.id] == c
+		if expired {
+			delete(s.cursors, c.id)
+			delete(s.all, c.id)
+		}
+		s.mu.Unlock()
+
+		if expired {
+			c.abort()
+		}
+	})
+	return true
+}
+
+// writeCursorPage writes the next result of a cursor. The cursor is kept
+// for the next request if more results remain and closed otherwise.
+func (h *Handler) writeCursorPage(w ResponseWriter, c *queryCursor) {
+	resp := Response{Results: make([]*query.Result, 0, 1)}
+	r, more := c.fetch()
+	if r != nil {
+		if c.epoch != "" {
+			convertToEpoch(r, c.epoch)
+		}
+		if c.tabular {
+			flattenResult(r)
+		}
+		resp.Results = append(resp.Results, r)
+	}
+	if more && h.cursors.put(c) {
+		resp.Cursor = c.id
+		w.Header().Set("X-Influxdb-Cursor", c.id)
+	} else if !more {
+		h.cursors.remove(c)
+	}
+
+	h.writeHeader(w, http.StatusOK)
+	n, _ := w.WriteResponse(resp)
+	atomic.AddInt64(&h.stats.QueryRequestBytesTransmitted, int64(n))
+}
+
+// serveQueryCursor returns the next page of results of a paged query.
+func (h *Handler) serveQueryCursor(w http.ResponseWriter, r *http.Request, user meta.User) {
+	atomic.AddInt64(&h.stats.QueryRequests, 1)
+	rw, ok := w.(ResponseWriter)
+	if !ok {
+		rw = NewResponseWriter(w, r)
+	}
+
+	c := h.cursors.take(r.URL.Query().Get(":id"), h.requestUser(user))
+	if c == nil {
+		h.httpError(rw, "cursor not found", http.StatusNotFound)
+		return
+	}
+	h.writeCursorPage(rw, c)
+}
+
+// serveCloseQueryCursor closes a cursor before all of its results have been
+// fetched and aborts its query.
+func (h *Handler) serveCloseQueryCursor(w http.ResponseWriter, r *http.Request, user meta.User) {
+	c := h.cursors.take(r.URL.Query().Get(":id"), h.requestUser(user))
+	if c == nil {
+		h.httpError(w, "cursor not found", http.StatusNotFound)
+		return
+	}
+	c.abort()
+	h.cursors.remove(c)
+	h.writeHeader(w, http.StatusNoContent)
+}
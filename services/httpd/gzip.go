@@ -65,9 +65,10 @@ func (w *lazyGzipResponseWriter) Write(p []byte) (int, error) {
 }
 
 func (w *lazyGzipResponseWriter) Flush() {
-	// Flush writer, if supported
+	// Flush the compressed data written so far. gzip.Writer returns an
+	// error from Flush, so it does not match the http.Flusher signature.
 	if f, ok := w.Writer.(interface {
-		Flush()
+		Flush() error
 	}); ok {
 		f.Flush()
 	}
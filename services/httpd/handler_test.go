@@ -2,6 +2,7 @@ package httpd_test
 
 import (
 	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -13,6 +14,8 @@ import (
 	"net/url"
 	"reflect"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -21,10 +24,15 @@ import (
 	"github.com/golang/snappy"
 	"github.com/influxdata/influxdb/internal"
 	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/monitor"
+	"github.com/influxdata/influxdb/monitor/diagnostics"
 	"github.com/influxdata/influxdb/prometheus/remote"
 	"github.com/influxdata/influxdb/query"
+	"github.com/influxdata/influxdb/services/cdc"
 	"github.com/influxdata/influxdb/services/httpd"
 	"github.com/influxdata/influxdb/services/meta"
+	"github.com/influxdata/influxdb/toml"
+	"github.com/influxdata/influxdb/tsdb"
 	"github.com/influxdata/influxql"
 )
 
@@ -51,6 +59,503 @@ func TestHandler_Query(t *testing.T) {
 	}
 }
 
+// Ensure the handler executes a configured query template with bound parameters.
+func TestHandler_Query_Template(t *testing.T) {
+	config := httpd.NewConfig()
+	config.QueryTemplates = []httpd.QueryTemplate{
+		{Name: "cpu", Query: "SELECT * FROM cpu WHERE host = $host"},
+	}
+	h := NewHandlerWithConfig(config)
+	h.StatementExecutor.ExecuteStatementFn = func(stmt influxql.Statement, ctx query.ExecutionContext) error {
+		if stmt.String() != `SELECT * FROM cpu WHERE host = 'server01'` {
+			t.Fatalf("unexpected query: %s", stmt.String())
+		}
+		ctx.Results <- &query.Result{StatementID: 0, Series: models.Rows([]*models.Row{{Name: "cpu"}})}
+		return nil
+	}
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, MustNewJSONRequest("GET", "/query?db=foo&template=cpu&params="+url.QueryEscape(`{"host":"server01"}`), nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("unexpected status: %d", w.Code)
+	} else if body := strings.TrimSpace(w.Body.String()); body != `{"results":[{"statement_id":0,"series":[{"name":"cpu"}]}]}` {
+		t.Fatalf("unexpected body: %s", body)
+	}
+
+	// Ensure an unknown template is rejected.
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, MustNewJSONRequest("GET", "/query?db=foo&template=mem", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("unexpected status: %d", w.Code)
+	} else if body := strings.TrimSpace(w.Body.String()); body != `{"error":"query template not found: \"mem\""}` {
+		t.Fatalf("unexpected body: %s", body)
+	}
+}
+
+// Ensure the handler records each query in the monitor database when enabled.
+func TestHandler_Query_MonitorStats(t *testing.T) {
+	config := httpd.NewConfig()
+	config.QueryStatsEnabled = true
+	h := NewHandlerWithConfig(config)
+	h.StatementExecutor.ExecuteStatementFn = func(stmt influxql.Statement, ctx query.ExecutionContext) error {
+		ctx.Results <- &query.Result{StatementID: 0, Series: models.Rows([]*models.Row{{Name: "cpu", Values: [][]interface{}{{1}, {2}}}})}
+		ctx.Results <- &query.Result{StatementID: 0, Series: models.Rows([]*models.Row{{Name: "mem", Values: [][]interface{}{{3}}}})}
+		return nil
+	}
+	points := make(chan models.Point, 2)
+	h.Handler.Monitor = &HandlerMonitor{
+		WritePointsFn: func(p models.Points) error {
+			for _, p := range p {
+				points <- p
+			}
+			return nil
+		},
+	}
+	next := func() models.Point {
+		select {
+		case p := <-points:
+			return p
+		case <-time.After(time.Second):
+			t.Fatal("timeout waiting for query statistics")
+			return nil
+		}
+	}
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, MustNewJSONRequest("GET", "/query?db=foo&q=SELECT+*+FROM+cpu+WHERE+host%3D%27a%27", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("unexpected status: %d", w.Code)
+	}
+
+	p := next()
+	if string(p.Name()) != "httpd_query" {
+		t.Fatalf("unexpected measurement: %s", p.Name())
+	} else if db := p.Tags().GetString("db"); db != "foo" {
+		t.Fatalf("unexpected db: %s", db)
+	} else if p.Tags().GetString("fingerprint") == "" {
+		t.Fatal("expected fingerprint")
+	}
+	fields, err := p.Fields()
+	if err != nil {
+		t.Fatal(err)
+	} else if fields["rows"] != int64(3) {
+		t.Fatalf("unexpected rows: %v", fields["rows"])
+	} else if fields["statement"] != "SELECT * FROM cpu WHERE host = $_" {
+		t.Fatalf("unexpected statement: %v", fields["statement"])
+	} else if fields["failed"] != false {
+		t.Fatalf("unexpected failed: %v", fields["failed"])
+	}
+
+	// Ensure a query refused before it executes is recorded as failed.
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, MustNewJSONRequest("GET", "/query?db=foo&q=SELECT+*+FROM+cpu&priority=bogus", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("unexpected status: %d", w.Code)
+	}
+
+	if fields, err := next().Fields(); err != nil {
+		t.Fatal(err)
+	} else if fields["rows"] != int64(0) {
+		t.Fatalf("unexpected rows: %v", fields["rows"])
+	} else if fields["failed"] != true {
+		t.Fatalf("unexpected failed: %v", fields["failed"])
+	}
+}
+
+// Ensure a pipeline query is compiled to InfluxQL and only served when enabled.
+func TestHandler_Query_Pipeline(t *testing.T) {
+	const text = `from(bucket: "foo/bar") |> range(start: -1h) |> filter(fn: (r) => r._measurement == "cpu" and r.host == "a")`
+
+	// The endpoint is disabled by default.
+	h := NewHandler(false)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, MustNewJSONRequest("POST", "/api/v2/query", strings.NewReader(text)))
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("unexpected status: %d", w.Code)
+	}
+
+	config := httpd.NewConfig()
+	config.PipelineEnabled = true
+	h = NewHandlerWithConfig(config)
+	h.StatementExecutor.ExecuteStatementFn = func(stmt influxql.Statement, ctx query.ExecutionContext) error {
+		if exp := `SELECT * FROM foo.bar.cpu WHERE host = 'a' AND time >= now() - 1h`; stmt.String() != exp {
+			t.Errorf("unexpected statement:\n got %s\n exp %s", stmt, exp)
+		} else if ctx.Database != "foo" {
+			t.Errorf("unexpected database: %s", ctx.Database)
+		}
+		ctx.Results <- &query.Result{StatementID: 0, Series: models.Rows([]*models.Row{{Name: "cpu"}})}
+		return nil
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, MustNewJSONRequest("POST", "/api/v2/query", strings.NewReader(text)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("unexpected status: %d", w.Code)
+	} else if body := strings.TrimSpace(w.Body.String()); body != `{"results":[{"statement_id":0,"series":[{"name":"cpu"}]}]}` {
+		t.Fatalf("unexpected body: %s", body)
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, MustNewJSONRequest("POST", "/api/v2/query", strings.NewReader(`from(bucket: "foo")`)))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("unexpected status: %d", w.Code)
+	}
+
+	// Bodies over the maximum size are rejected.
+	h.Config.MaxBodySize = len(text) - 1
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, MustNewJSONRequest("POST", "/api/v2/query", strings.NewReader(text)))
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("unexpected status: %d", w.Code)
+	}
+}
+
+// Ensure a SQL query is translated to InfluxQL before it is executed.
+func TestHandler_Query_SQL(t *testing.T) {
+	h := NewHandler(false)
+	h.StatementExecutor.ExecuteStatementFn = func(stmt influxql.Statement, ctx query.ExecutionContext) error {
+		if exp := `SELECT mean(value) FROM cpu GROUP BY time(1m), host`; stmt.String() != exp {
+			t.Errorf("unexpected statement:\n got %s\n exp %s", stmt, exp)
+		}
+		ctx.Results <- &query.Result{StatementID: 0, Series: models.Rows([]*models.Row{{Name: "cpu"}})}
+		return nil
+	}
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, MustNewJSONRequest("GET", "/api/v1/sql?db=foo&q="+url.QueryEscape(`SELECT time_bucket('1 minute', time) AS t, host, avg(value) FROM cpu GROUP BY t, host`), nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("unexpected status: %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, MustNewJSONRequest("GET", "/api/v1/sql?db=foo&q=DROP+DATABASE+foo", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("unexpected status: %d", w.Code)
+	}
+}
+
+// Ensure the series of a result are merged into a single table when requested.
+func TestHandler_Query_Tabular(t *testing.T) {
+	h := NewHandler(false)
+	h.StatementExecutor.ExecuteStatementFn = func(stmt influxql.Statement, ctx query.ExecutionContext) error {
+		ctx.Results <- &query.Result{StatementID: 0, Series: models.Rows([]*models.Row{
+			{Name: "cpu", Tags: map[string]string{"host": "a"}, Columns: []string{"time", "value"}, Values: [][]interface{}{{1, 2}}},
+			{Name: "cpu", Tags: map[string]string{"host": "b", "region": "us"}, Columns: []string{"time", "value", "idle"}, Values: [][]interface{}{{3, 4, 5}}},
+		})}
+		ctx.Results <- &query.Result{StatementID: 1, Series: models.Rows([]*models.Row{
+			{Name: "cpu", Columns: []string{"key"}, Values: [][]interface{}{{"host"}}},
+			{Name: "mem", Columns: []string{"key"}, Values: [][]interface{}{{"region"}}},
+		})}
+		return nil
+	}
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, MustNewJSONRequest("GET", "/query?db=foo&tabular=true&q=SELECT+*+FROM+cpu+GROUP+BY+*", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("unexpected status: %d", w.Code)
+	} else if body := strings.TrimSpace(w.Body.String()); body != `{"results":[`+
+		`{"statement_id":0,"series":[{"name":"cpu","columns":["time","host","region","value","idle"],"values":[[1,"a",null,2,null],[3,"b","us",4,5]]}]},`+
+		`{"statement_id":1,"series":[{"columns":["name","key"],"values":[["cpu","host"],["mem","region"]]}]}]}` {
+		t.Fatalf("unexpected body: %s", body)
+	}
+}
+
+// Ensure identical concurrent queries are executed once when coalescing is enabled.
+func TestHandler_Query_Coalesce(t *testing.T) {
+	config := httpd.NewConfig()
+	config.QueryCoalescingEnabled = true
+	h := NewHandlerWithConfig(config)
+
+	var executed int64
+	release := make(chan struct{})
+	h.StatementExecutor.ExecuteStatementFn = func(stmt influxql.Statement, ctx query.ExecutionContext) error {
+		atomic.AddInt64(&executed, 1)
+		<-release
+		ctx.Results <- &query.Result{StatementID: 0, Series: models.Rows([]*models.Row{{Name: "cpu", Columns: []string{"time", "value"}, Values: [][]interface{}{{time.Unix(0, 0).UTC(), 1}}}})}
+		return nil
+	}
+
+	const n = 5
+	var wg sync.WaitGroup
+	bodies := make([]string, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			url := "/query?db=foo&q=SELECT+*+FROM+cpu"
+			if i == 0 {
+				url += "&epoch=s"
+			}
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, MustNewJSONRequest("GET", url, nil))
+			bodies[i] = strings.TrimSpace(w.Body.String())
+		}(i)
+	}
+
+	// Release the query once every request has joined it.
+	for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(time.Millisecond) {
+		if h.Handler.Statistics(nil)[0].Values["queryReqCoalesced"] == int64(n-1) {
+			break
+		} else if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for requests to coalesce")
+		}
+	}
+	close(release)
+	wg.Wait()
+
+	if executed != 1 {
+		t.Fatalf("unexpected number of executions: %d", executed)
+	}
+	// Each request formats its own copy of the results.
+	if exp := `{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["time","value"],"values":[[0,1]]}]}]}`; bodies[0] != exp {
+		t.Fatalf("unexpected body: %s", bodies[0])
+	}
+	for _, body := range bodies[1:] {
+		if exp := `{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["time","value"],"values":[["1970-01-01T00:00:00Z",1]]}]}]}`; body != exp {
+			t.Fatalf("unexpected body: %s", body)
+		}
+	}
+}
+
+// Ensure the results of a paged query are fetched chunk by chunk through a cursor.
+func TestHandler_Query_Cursor(t *testing.T) {
+	h := NewHandler(false)
+	h.StatementExecutor.ExecuteStatementFn = func(stmt influxql.Statement, ctx query.ExecutionContext) error {
+		for i := 0; i < 3; i++ {
+			ctx.Results <- &query.Result{StatementID: 0, Series: models.Rows([]*models.Row{{Name: "cpu", Columns: []string{"value"}, Values: [][]interface{}{{i}}}})}
+		}
+		return nil
+	}
+
+	var resp httpd.Response
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, MustNewJSONRequest("GET", "/query?db=foo&cursor=true&chunk_size=1&q=SELECT+*+FROM+cpu", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("unexpected status: %d", w.Code)
+	} else if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	} else if resp.Cursor == "" || w.Header().Get("X-Influxdb-Cursor") != resp.Cursor {
+		t.Fatalf("unexpected cursor: %q", resp.Cursor)
+	}
+	cursor := resp.Cursor
+
+	for i, exp := range []string{
+		`{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["value"],"values":[[1]]}]}],"cursor":"` + cursor + `"}`,
+		`{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["value"],"values":[[2]]}]}]}`,
+	} {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, MustNewJSONRequest("GET", "/query/cursors/"+cursor, nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("%d. unexpected status: %d", i, w.Code)
+		} else if body := strings.TrimSpace(w.Body.String()); body != exp {
+			t.Fatalf("%d. unexpected body: %s", i, body)
+		}
+	}
+
+	// The cursor is closed once all results are returned.
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, MustNewJSONRequest("GET", "/query/cursors/"+cursor, nil))
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("unexpected status: %d", w.Code)
+	}
+}
+
+// Ensure cursors can be closed early and expire when they are not used.
+func TestHandler_Query_CursorClose(t *testing.T) {
+	config := httpd.NewConfig()
+	config.CursorTimeout = toml.Duration(50 * time.Millisecond)
+	h := NewHandlerWithConfig(config)
+	h.StatementExecutor.ExecuteStatementFn = func(stmt influxql.Statement, ctx query.ExecutionContext) error {
+		for i := 0; i < 3; i++ {
+			if err := ctx.Send(&query.Result{StatementID: 0, Series: models.Rows([]*models.Row{{Name: "cpu", Columns: []string{"value"}, Values: [][]interface{}{{i}}}})}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	open := func() string {
+		var resp httpd.Response
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, MustNewJSONRequest("GET", "/query?db=foo&cursor=true&q=SELECT+*+FROM+cpu", nil))
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatal(err)
+		} else if resp.Cursor == "" {
+			t.Fatal("expected cursor")
+		}
+		return resp.Cursor
+	}
+
+	cursor := open()
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, MustNewRequest("DELETE", "/query/cursors/"+cursor, nil))
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("unexpected status: %d", w.Code)
+	}
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, MustNewJSONRequest("GET", "/query/cursors/"+cursor, nil))
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("unexpected status: %d", w.Code)
+	}
+
+	cursor = open()
+	time.Sleep(100 * time.Millisecond)
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, MustNewJSONRequest("GET", "/query/cursors/"+cursor, nil))
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("unexpected status: %d", w.Code)
+	}
+}
+
+// Ensure paged queries are rejected once the maximum number of cursors is open
+// and that all cursors are aborted when the service is closed.
+func TestService_Query_CursorLimit(t *testing.T) {
+	config := httpd.NewConfig()
+	config.MaxOpenCursors = 2
+	s := httpd.NewService(config)
+
+	var mu sync.Mutex
+	var aborted int
+	var se HandlerStatementExecutor
+	se.ExecuteStatementFn = func(stmt influxql.Statement, ctx query.ExecutionContext) error {
+		for i := 0; ; i++ {
+			if err := ctx.Send(&query.Result{StatementID: 0, Series: models.Rows([]*models.Row{{Name: "cpu", Columns: []string{"value"}, Values: [][]interface{}{{i}}}})}); err != nil {
+				mu.Lock()
+				aborted++
+				mu.Unlock()
+				return err
+			}
+		}
+	}
+	s.Handler.QueryExecutor = query.NewQueryExecutor()
+	s.Handler.QueryExecutor.StatementExecutor = &se
+
+	open := func() (int, string) {
+		var resp httpd.Response
+		w := httptest.NewRecorder()
+		s.Handler.ServeHTTP(w, MustNewJSONRequest("GET", "/query?db=foo&cursor=true&chunk_size=1&q=SELECT+*+FROM+cpu", nil))
+		if w.Code == http.StatusOK {
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return w.Code, resp.Cursor
+	}
+
+	var cursors []string
+	for i := 0; i < 2; i++ {
+		if code, cursor := open(); code != http.StatusOK || cursor == "" {
+			t.Fatalf("%d. unexpected status: %d", i, code)
+		} else {
+			cursors = append(cursors, cursor)
+		}
+	}
+	if code, _ := open(); code != http.StatusTooManyRequests {
+		t.Fatalf("unexpected status: %d", code)
+	}
+
+	// Closing a cursor makes room for another.
+	w := httptest.NewRecorder()
+	s.Handler.ServeHTTP(w, MustNewRequest("DELETE", "/query/cursors/"+cursors[0], nil))
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("unexpected status: %d", w.Code)
+	}
+	code, cursor := open()
+	if code != http.StatusOK {
+		t.Fatalf("unexpected status: %d", code)
+	}
+	cursors = append(cursors[1:], cursor)
+
+	// Closing the service aborts the queries of the remaining cursors. With
+	// the rejected and the closed one, all four queries are then aborted.
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	for _, cursor := range cursors {
+		w := httptest.NewRecorder()
+		s.Handler.ServeHTTP(w, MustNewJSONRequest("GET", "/query/cursors/"+cursor, nil))
+		if w.Code != http.StatusNotFound {
+			t.Fatalf("unexpected status: %d", w.Code)
+		}
+	}
+
+	timeout := time.After(time.Second)
+	for {
+		mu.Lock()
+		n := aborted
+		mu.Unlock()
+		if n == 4 {
+			break
+		}
+		select {
+		case <-timeout:
+			t.Fatalf("unexpected number of aborted queries: %d", n)
+		case <-time.After(time.Millisecond):
+		}
+	}
+}
+
+// Ensure the handler requests statistics and merges them into the buffered result.
+func TestHandler_Query_Stats(t *testing.T) {
+	h := NewHandler(false)
+	h.StatementExecutor.ExecuteStatementFn = func(stmt influxql.Statement, ctx query.ExecutionContext) error {
+		if !ctx.IncludeStats {
+			t.Fatal("expected stats to be requested")
+		}
+		ctx.Results <- &query.Result{StatementID: 0, Series: models.Rows([]*models.Row{{Name: "cpu", Values: [][]interface{}{{1}}}}), Partial: true}
+		ctx.Results <- &query.Result{StatementID: 0, Series: models.Rows([]*models.Row{{Name: "mem", Values: [][]interface{}{{2}}}}), Stats: &query.ResultStats{SeriesN: 2, ValuesN: 2, ScannedSeriesN: 3, ScannedPointN: 10}}
+		return nil
+	}
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, MustNewJSONRequest("GET", "/query?db=foo&q=SELECT+*+FROM+bar&stats=true", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("unexpected status: %d", w.Code)
+	} else if body := strings.TrimSpace(w.Body.String()); body != `{"results":[{"statement_id":0,"series":[{"name":"cpu","values":[[1]]},{"name":"mem","values":[[2]]}],"stats":{"series":2,"values":2,"scanned_series":3,"scanned_points":10}}]}` {
+		t.Fatalf("unexpected body: %s", body)
+	}
+}
+
+// Ensure the statistics of a result cut off by the row limit are kept and
+// count the returned rows.
+func TestHandler_Query_Stats_MaxRowLimit(t *testing.T) {
+	for _, tt := range []struct {
+		name  string
+		first [][]interface{}
+	}{
+		{name: "truncated", first: [][]interface{}{{1}, {2}}},
+		{name: "at limit", first: [][]interface{}{{1}}},
+	} {
+		config := httpd.NewConfig()
+		config.MaxRowLimit = 1
+		h := NewHandlerWithConfig(config)
+		h.StatementExecutor.ExecuteStatementFn = func(stmt influxql.Statement, ctx query.ExecutionContext) error {
+			ctx.Results <- &query.Result{StatementID: 0, Series: models.Rows([]*models.Row{{Name: "cpu", Values: tt.first}}), Partial: true}
+			ctx.Results <- &query.Result{StatementID: 0, Series: models.Rows([]*models.Row{{Name: "mem", Values: [][]interface{}{{3}}}}), Stats: &query.ResultStats{SeriesN: 2, ValuesN: 3, ScannedSeriesN: 3, ScannedPointN: 10}}
+			return nil
+		}
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, MustNewJSONRequest("GET", "/query?db=foo&q=SELECT+*+FROM+bar&stats=true", nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: unexpected status: %d", tt.name, w.Code)
+		}
+
+		var resp struct {
+			Results []struct {
+				Stats *query.ResultStats `json:"stats"`
+			} `json:"results"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("%s: %s", tt.name, err)
+		} else if len(resp.Results) != 1 || resp.Results[0].Stats == nil {
+			t.Fatalf("%s: unexpected body: %s", tt.name, w.Body.String())
+		} else if exp := (query.ResultStats{SeriesN: 1, ValuesN: 1, ScannedSeriesN: 3, ScannedPointN: 10, Truncated: true}); *resp.Results[0].Stats != exp {
+			t.Fatalf("%s: unexpected stats: %+v", tt.name, *resp.Results[0].Stats)
+		}
+	}
+}
+
 // Ensure the handler returns results from a query passed as a file.
 func TestHandler_Query_File(t *testing.T) {
 	h := NewHandler(false)
@@ -235,6 +740,54 @@ func TestHandler_Query_Auth(t *testing.T) {
 	}
 }
 
+// Ensure a query without a database or epoch uses the settings of the user.
+func TestHandler_Query_UserSettings(t *testing.T) {
+	h := NewHandler(true)
+	h.MetaClient.AdminUserExistsFn = func() bool { return true }
+	h.MetaClient.AuthenticateFn = func(u, p string) (meta.User, error) {
+		return &meta.UserInfo{
+			Name:     u,
+			Admin:    true,
+			Settings: meta.UserSettings{Database: "db0", Precision: "s"},
+		}, nil
+	}
+	h.QueryAuthorizer.AuthorizeQueryFn = func(u meta.User, query *influxql.Query, database string) error {
+		return nil
+	}
+
+	var database string
+	h.StatementExecutor.ExecuteStatementFn = func(stmt influxql.Statement, ctx query.ExecutionContext) error {
+		database = ctx.Database
+		ctx.Results <- &query.Result{StatementID: 0, Series: models.Rows([]*models.Row{{
+			Name:    "cpu",
+			Columns: []string{"time", "value"},
+			Values:  [][]interface{}{{time.Unix(10, 0).UTC(), 1.0}},
+		}})}
+		return nil
+	}
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, MustNewJSONRequest("GET", "/query?u=user1&p=abcd&q=SELECT+*+FROM+cpu", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("unexpected status: %d", w.Code)
+	} else if database != "db0" {
+		t.Fatalf("unexpected db: %s", database)
+	} else if body := strings.TrimSpace(w.Body.String()); body != `{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["time","value"],"values":[[10,1]]}]}]}` {
+		t.Fatalf("unexpected body: %s", body)
+	}
+
+	// Parameters of the request take precedence.
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, MustNewJSONRequest("GET", "/query?u=user1&p=abcd&db=db1&epoch=ms&q=SELECT+*+FROM+cpu", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("unexpected status: %d", w.Code)
+	} else if database != "db1" {
+		t.Fatalf("unexpected db: %s", database)
+	} else if body := strings.TrimSpace(w.Body.String()); body != `{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["time","value"],"values":[[10000,1]]}]}]}` {
+		t.Fatalf("unexpected body: %s", body)
+	}
+}
+
 // Ensure the handler returns results from a query (including nil results).
 func TestHandler_QueryRegex(t *testing.T) {
 	h := NewHandler(false)
@@ -311,6 +864,50 @@ func TestHandler_Query_Chunked(t *testing.T) {
 	}
 }
 
+// Ensure each chunk is sent to the client before the next result is produced.
+// The default client requests a gzip response, so the compressed stream must
+// be flushed after each chunk.
+func TestHandler_Query_Chunked_Streaming(t *testing.T) {
+	next := make(chan struct{})
+	h := NewHandler(false)
+	h.StatementExecutor.ExecuteStatementFn = func(stmt influxql.Statement, ctx query.ExecutionContext) error {
+		ctx.Results <- &query.Result{StatementID: 0, Series: models.Rows([]*models.Row{{Name: "series0"}}), Partial: true}
+		select {
+		case <-next:
+		case <-time.After(5 * time.Second):
+			t.Error("first chunk was not received")
+		}
+		ctx.Results <- &query.Result{StatementID: 0, Series: models.Rows([]*models.Row{{Name: "series1"}})}
+		return nil
+	}
+
+	s := httptest.NewServer(h)
+	defer s.Close()
+
+	resp, err := http.Get(s.URL + "/query?db=foo&q=SELECT+*+FROM+bar&chunked=true&chunk_size=1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	// The first chunk arrives while the second result is still pending.
+	dec := json.NewDecoder(resp.Body)
+	var chunk httpd.Response
+	if err := dec.Decode(&chunk); err != nil {
+		t.Fatal(err)
+	} else if len(chunk.Results) != 1 || chunk.Results[0].Series[0].Name != "series0" {
+		t.Fatalf("unexpected first chunk: %+v", chunk)
+	}
+	close(next)
+
+	chunk = httpd.Response{}
+	if err := dec.Decode(&chunk); err != nil {
+		t.Fatal(err)
+	} else if len(chunk.Results) != 1 || chunk.Results[0].Series[0].Name != "series1" {
+		t.Fatalf("unexpected second chunk: %+v", chunk)
+	}
+}
+
 // Ensure the handler can accept an async query.
 func TestHandler_Query_Async(t *testing.T) {
 	done := make(chan struct{})
@@ -365,8 +962,10 @@ func TestHandler_Query_ErrInvalidQuery(t *testing.T) {
 	h.ServeHTTP(w, MustNewJSONRequest("GET", "/query?q=SELECT", nil))
 	if w.Code != http.StatusBadRequest {
 		t.Fatalf("unexpected status: %d", w.Code)
-	} else if body := strings.TrimSpace(w.Body.String()); body != `{"error":"error parsing query: found EOF, expected identifier, string, number, bool at line 1, char 8"}` {
+	} else if body := strings.TrimSpace(w.Body.String()); body != `{"error":"error parsing query: found EOF, expected identifier, string, number, bool at line 1, char 8","code":"invalid_query"}` {
 		t.Fatalf("unexpected body: %s", body)
+	} else if code := w.Header().Get("X-InfluxDB-Error-Code"); code != "invalid_query" {
+		t.Fatalf("unexpected error code header: %s", code)
 	}
 }
 
@@ -462,6 +1061,22 @@ func TestHandler_Query_ErrResult(t *testing.T) {
 	}
 }
 
+// Ensure the handler includes the error code of a statement error.
+func TestHandler_Query_ErrResult_Code(t *testing.T) {
+	h := NewHandler(false)
+	h.StatementExecutor.ExecuteStatementFn = func(stmt influxql.Statement, ctx query.ExecutionContext) error {
+		return query.ErrDatabaseNotFound("foo")
+	}
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, MustNewJSONRequest("GET", "/query?db=foo&q=SHOW+SERIES+from+bin", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("unexpected status: %d", w.Code)
+	} else if body := strings.TrimSpace(w.Body.String()); body != `{"results":[{"statement_id":0,"error":"database not found: foo","code":"database_not_found"}]}` {
+		t.Fatalf("unexpected body: %s", body)
+	}
+}
+
 // Ensure that closing the HTTP connection causes the query to be interrupted.
 func TestHandler_Query_CloseNotify(t *testing.T) {
 	// Avoid leaking a goroutine when this fails.
@@ -817,6 +1432,334 @@ func TestHandler_Write_NegativeMaxBodySize(t *testing.T) {
 	}
 }
 
+// Ensure points with non-canonical series keys are counted when series key
+// diagnostics are enabled.
+func TestHandler_Write_SeriesKeyDiagnostics(t *testing.T) {
+	h := NewHandler(false)
+	h.Config.SeriesKeyDiagnosticsEnabled = true
+	h.MetaClient.DatabaseFn = func(name string) *meta.DatabaseInfo {
+		return &meta.DatabaseInfo{}
+	}
+	h.PointsWriter.WritePointsFn = func(_, _ string, _ models.ConsistencyLevel, _ meta.User, _ []models.Point) error {
+		return nil
+	}
+
+	b := bytes.NewBufferString("c\\=pu,host=a n=1\nc=pu,host=a n=1\ncpu,region=b,host=a n=1")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, MustNewRequest("POST", "/write?db=foo", b))
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("unexpected status: %d", w.Code)
+	}
+
+	stats := h.Statistics(nil)
+	if got := stats[0].Values["nonCanonicalKeys"]; got != int64(1) {
+		t.Fatalf("unexpected non-canonical keys: %v", got)
+	}
+}
+
+// Ensure writes in the deprecated JSON format are accepted when enabled.
+func TestHandler_Write_JSON(t *testing.T) {
+	h := NewHandler(false)
+	h.Config.JSONWriteEnabled = true
+	h.MetaClient.DatabaseFn = func(name string) *meta.DatabaseInfo {
+		return &meta.DatabaseInfo{}
+	}
+	var database, rp string
+	var points []models.Point
+	h.PointsWriter.WritePointsFn = func(db, r string, _ models.ConsistencyLevel, _ meta.User, p []models.Point) error {
+		database, rp, points = db, r, p
+		return nil
+	}
+
+	body := `{"database":"db0","retentionPolicy":"rp0","tags":{"host":"a"},"time":10,"precision":"s","points":[` +
+		`{"measurement":"cpu","fields":{"value":1}},` +
+		`{"measurement":"cpu","tags":{"host":"b"},"time":"1970-01-01T00:00:20Z","fields":{"value":2}}]}`
+	r := MustNewRequest("POST", "/write", strings.NewReader(body))
+	r.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("unexpected status: %d: %s", w.Code, w.Body.String())
+	} else if database != "db0" || rp != "rp0" {
+		t.Fatalf("unexpected database and retention policy: %s.%s", database, rp)
+	} else if len(points) != 2 {
+		t.Fatalf("unexpected points: %v", points)
+	} else if got := points[0].String(); got != "cpu,host=a value=1 10000000000" {
+		t.Fatalf("unexpected point: %s", got)
+	} else if got := points[1].String(); got != "cpu,host=b value=2 20000000000" {
+		t.Fatalf("unexpected point: %s", got)
+	}
+
+	stats := h.Statistics(nil)
+	if got := stats[0].Values["jsonWriteReq"]; got != int64(1) {
+		t.Fatalf("unexpected JSON write requests: %v", got)
+	} else if got := stats[0].Values["jsonPointsWritten"]; got != int64(2) {
+		t.Fatalf("unexpected JSON points: %v", got)
+	}
+
+	// Without the option, the body is parsed as line protocol.
+	h.Config.JSONWriteEnabled = false
+	r = MustNewRequest("POST", "/write?db=db0", strings.NewReader(body))
+	r.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("unexpected status: %d", w.Code)
+	}
+}
+
+// Ensure an unknown write precision is rejected before any points are written.
+func TestHandler_Write_InvalidPrecision(t *testing.T) {
+	h := NewHandler(false)
+	h.MetaClient.DatabaseFn = func(name string) *meta.DatabaseInfo {
+		return &meta.DatabaseInfo{}
+	}
+	h.PointsWriter.WritePointsFn = func(_, _ string, _ models.ConsistencyLevel, _ meta.User, _ []models.Point) error {
+		t.Fatal("WritePoints: unexpected call")
+		return nil
+	}
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, MustNewRequest("POST", "/write?db=foo&precision=x", bytes.NewReader([]byte(`foo n=1 1`))))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("unexpected status: %d", w.Code)
+	}
+	if body := w.Body.String(); !strings.Contains(body, `invalid precision \"x\"`) {
+		t.Fatalf("unexpected body: %s", body)
+	}
+}
+
+// Ensure a partial write reports the rejected lines when details are requested.
+func TestHandler_Write_RejectedDetails(t *testing.T) {
+	h := NewHandler(false)
+	h.MetaClient.DatabaseFn = func(name string) *meta.DatabaseInfo {
+		return &meta.DatabaseInfo{}
+	}
+	var n int
+	h.PointsWriter.WritePointsFn = func(_, _ string, _ models.ConsistencyLevel, _ meta.User, points []models.Point) error {
+		n = len(points)
+		return nil
+	}
+
+	body := "cpu value=1\ncpu value=\n\ncpu value=3\ncpu value=4 x\n"
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, MustNewRequest("POST", "/write?db=foo&details=true", strings.NewReader(body)))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("unexpected status: %d", w.Code)
+	} else if n != 2 {
+		t.Fatalf("unexpected number of points written: %d", n)
+	}
+
+	var resp httpd.Response
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	} else if resp.Err == nil || !strings.HasPrefix(resp.Err.Error(), "partial write:") {
+		t.Fatalf("unexpected error: %v", resp.Err)
+	}
+	if got, exp := resp.Rejected, []httpd.RejectedPoint{
+		{Line: 2, Error: "missing field value"},
+		{Line: 5, Error: "bad timestamp"},
+	}; !reflect.DeepEqual(got, exp) {
+		t.Fatalf("unexpected rejected points:\n got %+v\n exp %+v", got, exp)
+	}
+
+	// Details are only included when requested.
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, MustNewRequest("POST", "/write?db=foo", strings.NewReader(body)))
+	if strings.Contains(w.Body.String(), "rejected") {
+		t.Fatalf("unexpected body: %s", w.Body.String())
+	}
+}
+
+// Ensure the change stream returns committed writes to authorized users.
+func TestHandler_Changes(t *testing.T) {
+	h := NewHandler(true)
+	h.MetaClient.AdminUserExistsFn = func() bool { return true }
+	h.MetaClient.AuthenticateFn = func(name, password string) (meta.User, error) {
+		return &meta.UserInfo{Name: name, Privileges: map[string]influxql.Privilege{"db0": influxql.ReadPrivilege}}, nil
+	}
+
+	// The endpoint is only served when the change stream is enabled.
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, MustNewRequest("GET", "/api/v1/changes?db=db0&u=user&p=pass", nil))
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("unexpected status: %d", w.Code)
+	}
+
+	h.Handler.ChangeStream = &HandlerChangeStream{
+		ChangesFn: func(database string, since uint64, limit int, timeout time.Duration, done <-chan struct{}) ([]cdc.Change, bool) {
+			if database != "db0" || since != 4 || limit != 2 || timeout != time.Second {
+				t.Fatalf("unexpected arguments: %s %d %d %s", database, since, limit, timeout)
+			}
+			points, _ := models.ParsePointsString("cpu value=1 1\ncpu value=2 2")
+			return []cdc.Change{{Seq: 5, RetentionPolicy: "autogen", Points: points}}, false
+		},
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, MustNewRequest("GET", "/api/v1/changes?db=db0&since=4&limit=2&timeout=1s&u=user&p=pass", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("unexpected status: %d", w.Code)
+	} else if body := strings.TrimSpace(w.Body.String()); body != `{"database":"db0","changes":[{"seq":5,"retention_policy":"autogen","points":"cpu value=1 1\ncpu value=2 2"}],"next":5}` {
+		t.Fatalf("unexpected body: %s", body)
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, MustNewRequest("GET", "/api/v1/changes?db=db1&u=user&p=pass", nil))
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("unexpected status: %d", w.Code)
+	}
+}
+
+// Ensure the watermark of a database is the oldest of the newest timestamps
+// written by each session.
+func TestHandler_Watermark(t *testing.T) {
+	h := NewHandler(false)
+	h.MetaClient.DatabaseFn = func(name string) *meta.DatabaseInfo {
+		return &meta.DatabaseInfo{}
+	}
+	h.PointsWriter.WritePointsFn = func(_, _ string, _ models.ConsistencyLevel, _ meta.User, _ []models.Point) error {
+		return nil
+	}
+
+	watermark := func() string {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, MustNewRequest("GET", "/api/v1/watermark?db=db0", nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("unexpected status: %d", w.Code)
+		}
+		return strings.TrimSpace(w.Body.String())
+	}
+	write := func(session, body string) {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, MustNewRequest("POST", "/write?db=db0&precision=s&session="+session, strings.NewReader(body)))
+		if w.Code != http.StatusNoContent {
+			t.Fatalf("unexpected status: %d", w.Code)
+		}
+	}
+
+	if body := watermark(); body != `{"database":"db0","sessions":0}` {
+		t.Fatalf("unexpected body: %s", body)
+	}
+
+	write("a", "cpu value=1 10\ncpu value=2 20")
+	write("b", "cpu value=1 5")
+	if body := watermark(); body != `{"database":"db0","watermark":"1970-01-01T00:00:05Z","sessions":2}` {
+		t.Fatalf("unexpected body: %s", body)
+	}
+
+	write("b", "cpu value=1 30")
+	if body := watermark(); body != `{"database":"db0","watermark":"1970-01-01T00:00:20Z","sessions":2}` {
+		t.Fatalf("unexpected body: %s", body)
+	}
+
+	// The watermark does not move back for a session with older points.
+	write("c", "cpu value=1 1")
+	if body := watermark(); body != `{"database":"db0","watermark":"1970-01-01T00:00:20Z","sessions":3}` {
+		t.Fatalf("unexpected body: %s", body)
+	}
+}
+
+// Ensure the versioned write endpoints write to the requested database.
+func TestHandler_Write_Versioned(t *testing.T) {
+	for _, tt := range []struct {
+		url    string
+		db, rp string
+	}{
+		{url: "/api/v1/write?db=foo&rp=bar", db: "foo", rp: "bar"},
+		{url: "/api/v2/write?org=myorg&bucket=foo", db: "foo"},
+		{url: "/api/v2/write?org=myorg&bucket=foo/bar&precision=s", db: "foo", rp: "bar"},
+	} {
+		h := NewHandler(false)
+		h.MetaClient.DatabaseFn = func(name string) *meta.DatabaseInfo {
+			return &meta.DatabaseInfo{}
+		}
+		var db, rp string
+		h.PointsWriter.WritePointsFn = func(database, retentionPolicy string, _ models.ConsistencyLevel, _ meta.User, _ []models.Point) error {
+			db, rp = database, retentionPolicy
+			return nil
+		}
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, MustNewRequest("POST", tt.url, strings.NewReader("cpu value=1 1")))
+		if w.Code != http.StatusNoContent {
+			t.Fatalf("%s: unexpected status: %d", tt.url, w.Code)
+		} else if db != tt.db || rp != tt.rp {
+			t.Fatalf("%s: unexpected destination: db=%q rp=%q", tt.url, db, rp)
+		}
+	}
+
+	// A v2 write requires a bucket.
+	h := NewHandler(false)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, MustNewRequest("POST", "/api/v2/write?db=foo", strings.NewReader("cpu value=1 1")))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("unexpected status: %d", w.Code)
+	}
+}
+
+// Ensure writes authenticated with an API token are limited to the token's privileges.
+func TestHandler_Write_TokenAuthentication(t *testing.T) {
+	h := NewHandler(true)
+	h.MetaClient.AdminUserExistsFn = func() bool { return true }
+	h.MetaClient.DatabaseFn = func(name string) *meta.DatabaseInfo {
+		return &meta.DatabaseInfo{}
+	}
+	h.MetaClient.AuthenticateTokenFn = func(token string) (meta.User, error) {
+		if token != "secret" {
+			return nil, meta.ErrAuthenticate
+		}
+		return &meta.TokenInfo{Name: "telegraf", Privileges: map[string]influxql.Privilege{"db0": influxql.WritePrivilege}}, nil
+	}
+	h.PointsWriter.WritePointsFn = func(_, _ string, _ models.ConsistencyLevel, _ meta.User, _ []models.Point) error {
+		return nil
+	}
+
+	for _, tt := range []struct {
+		token string
+		db    string
+		code  int
+	}{
+		{token: "secret", db: "db0", code: http.StatusNoContent},
+		{token: "secret", db: "db1", code: http.StatusForbidden},
+		{token: "wrong", db: "db0", code: http.StatusUnauthorized},
+	} {
+		req := MustNewRequest("POST", "/write?db="+tt.db, strings.NewReader("cpu value=1 1"))
+		req.Header.Set("Authorization", "Token "+tt.token)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		if w.Code != tt.code {
+			t.Fatalf("token=%s db=%s: unexpected status: %d", tt.token, tt.db, w.Code)
+		}
+	}
+
+	// Prometheus remote writes are authorized by the token's privileges too.
+	data, err := proto.Marshal(&remote.WriteRequest{
+		Timeseries: []*remote.TimeSeries{{
+			Labels:  []*remote.LabelPair{{Name: "host", Value: "a"}},
+			Samples: []*remote.Sample{{TimestampMs: 1, Value: 1}},
+		}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range []struct {
+		db   string
+		code int
+	}{
+		{db: "db0", code: http.StatusNoContent},
+		{db: "db1", code: http.StatusForbidden},
+	} {
+		req := MustNewRequest("POST", "/api/v1/prom/write?db="+tt.db, bytes.NewReader(snappy.Encode(nil, data)))
+		req.Header.Set("Authorization", "Token secret")
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		if w.Code != tt.code {
+			t.Fatalf("prom write db=%s: unexpected status: %d", tt.db, w.Code)
+		}
+	}
+}
+
 // Ensure X-Forwarded-For header writes the correct log message.
 func TestHandler_XForwardedFor(t *testing.T) {
 	var buf bytes.Buffer
@@ -907,6 +1850,7 @@ type Handler struct {
 	StatementExecutor HandlerStatementExecutor
 	QueryAuthorizer   HandlerQueryAuthorizer
 	PointsWriter      HandlerPointsWriter
+	Store             HandlerStore
 }
 
 // NewHandler returns a new instance of Handler.
@@ -914,7 +1858,11 @@ func NewHandler(requireAuthentication bool) *Handler {
 	config := httpd.NewConfig()
 	config.AuthEnabled = requireAuthentication
 	config.SharedSecret = "super secret key"
+	return NewHandlerWithConfig(config)
+}
 
+// NewHandlerWithConfig returns a new instance of Handler using the given config.
+func NewHandlerWithConfig(config httpd.Config) *Handler {
 	h := &Handler{
 		Handler: httpd.NewHandler(config),
 	}
@@ -926,6 +1874,7 @@ func NewHandler(requireAuthentication bool) *Handler {
 	h.Handler.QueryExecutor.StatementExecutor = &h.StatementExecutor
 	h.Handler.QueryAuthorizer = &h.QueryAuthorizer
 	h.Handler.PointsWriter = &h.PointsWriter
+	h.Handler.Store = &h.Store
 	h.Handler.Version = "0.0.0"
 	h.Handler.BuildType = "OSS"
 	return h
@@ -940,6 +1889,34 @@ func (e *HandlerStatementExecutor) ExecuteStatement(stmt influxql.Statement, ctx
 	return e.ExecuteStatementFn(stmt, ctx)
 }
 
+// HandlerChangeStream is a mock implementation of Handler.ChangeStream.
+type HandlerChangeStream struct {
+	ChangesFn func(database string, since uint64, limit int, timeout time.Duration, done <-chan struct{}) ([]cdc.Change, bool)
+}
+
+func (s *HandlerChangeStream) Changes(database string, since uint64, limit int, timeout time.Duration, done <-chan struct{}) ([]cdc.Change, bool) {
+	return s.ChangesFn(database, since, limit, timeout, done)
+}
+
+// HandlerMonitor is a mock implementation of Handler.Monitor.
+type HandlerMonitor struct {
+	WritePointsFn func(models.Points) error
+}
+
+func (m *HandlerMonitor) Statistics(tags map[string]string) ([]*monitor.Statistic, error) {
+	return nil, nil
+}
+
+func (m *HandlerMonitor) Diagnostics() (map[string]*diagnostics.Diagnostics, error) {
+	return nil, nil
+}
+
+func (m *HandlerMonitor) Enabled() bool { return true }
+
+func (m *HandlerMonitor) WritePoints(p models.Points) error {
+	return m.WritePointsFn(p)
+}
+
 // HandlerQueryAuthorizer is a mock implementation of Handler.QueryAuthorizer.
 type HandlerQueryAuthorizer struct {
 	AuthorizeQueryFn func(u meta.User, query *influxql.Query, database string) error
@@ -957,6 +1934,20 @@ func (h *HandlerPointsWriter) WritePoints(database, retentionPolicy string, cons
 	return h.WritePointsFn(database, retentionPolicy, consistencyLevel, user, points)
 }
 
+// HandlerStore is a mock implementation of Handler.Store.
+type HandlerStore struct {
+	MeasurementCardinalitiesFn func(database string, n int) ([]tsdb.MeasurementCardinality, error)
+	UsageFn                    func(database string) (tsdb.DatabaseUsage, error)
+}
+
+func (s *HandlerStore) MeasurementCardinalities(database string, n int) ([]tsdb.MeasurementCardinality, error) {
+	return s.MeasurementCardinalitiesFn(database, n)
+}
+
+func (s *HandlerStore) Usage(database string) (tsdb.DatabaseUsage, error) {
+	return s.UsageFn(database)
+}
+
 // MustNewRequest returns a new HTTP request. Panic on error.
 func MustNewRequest(method, urlStr string, body io.Reader) *http.Request {
 	r, err := http.NewRequest(method, urlStr, body)
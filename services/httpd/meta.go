@@ -0,0 +1,959 @@
+package httpd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/query"
+	"github.com/influxdata/influxdb/services/meta"
+	"github.com/influxdata/influxdb/tsdb"
+	"github.com/influxdata/influxql"
+)
+
+// metaRoutes returns the routes of the meta API. The meta API exposes
+// databases, retention policies, continuous queries, users and shard groups
+// as JSON resources so they can be administered without building InfluxQL
+// strings. Changes are executed as statements through the query executor so
+// they have the same effect and authorization as the equivalent query.
+func (h *Handler) metaRoutes() []Route {
+	return []Route{
+		{"meta-databases", "GET", "/api/v1/meta/databases", true, true, h.serveMetaDatabases},
+		{"meta-databases", "POST", "/api/v1/meta/databases", true, true, h.serveMetaCreateDatabase},
+		{"meta-database", "GET", "/api/v1/meta/databases/:db", true, true, h.serveMetaDatabase},
+		{"meta-database", "DELETE", "/api/v1/meta/databases/:db", true, true, h.serveMetaDropDatabase},
+		{"meta-database-clone", "POST", "/api/v1/meta/databases/:db/clone", true, true, h.serveMetaCloneDatabase},
+		{"meta-database-undrop", "POST", "/api/v1/meta/databases/:db/undrop", true, true, h.serveMetaUndropDatabase},
+		{"meta-trash", "GET", "/api/v1/meta/trash", true, true, h.serveMetaTrash},
+		{"meta-retention-policies", "GET", "/api/v1/meta/databases/:db/retention-policies", true, true, h.serveMetaRetentionPolicies},
+		{"meta-retention-policies", "POST", "/api/v1/meta/databases/:db/retention-policies", true, true, h.serveMetaCreateRetentionPolicy},
+		{"meta-retention-policy", "POST", "/api/v1/meta/databases/:db/retention-policies/:rp", true, true, h.serveMetaUpdateRetentionPolicy},
+		{"meta-retention-policy", "DELETE", "/api/v1/meta/databases/:db/retention-policies/:rp", true, true, h.serveMetaDropRetentionPolicy},
+		{"meta-shard-groups", "GET", "/api/v1/meta/databases/:db/retention-policies/:rp/shard-groups", true, true, h.serveMetaShardGroups},
+		{"meta-quota", "GET", "/api/v1/meta/databases/:db/quota", true, true, h.serveMetaQuota},
+		{"meta-quota", "POST", "/api/v1/meta/databases/:db/quota", true, true, h.serveMetaSetQuota},
+		{"meta-quota", "DELETE", "/api/v1/meta/databases/:db/quota", true, true, h.serveMetaDropQuota},
+		{"meta-cardinality", "GET", "/api/v1/meta/databases/:db/cardinality", true, true, h.serveMetaCardinality},
+		{"meta-continuous-queries", "GET", "/api/v1/meta/databases/:db/continuous-queries", true, true, h.serveMetaContinuousQueries},
+		{"meta-continuous-queries", "POST", "/api/v1/meta/databases/:db/continuous-queries", true, true, h.serveMetaCreateContinuousQuery},
+		{"meta-continuous-query", "DELETE", "/api/v1/meta/databases/:db/continuous-queries/:cq", true, true, h.serveMetaDropContinuousQuery},
+		{"meta-users", "GET", "/api/v1/meta/users", true, true, h.serveMetaUsers},
+		{"meta-users", "POST", "/api/v1/meta/users", true, true, h.serveMetaCreateUser},
+		{"meta-user", "POST", "/api/v1/meta/users/:user", true, true, h.serveMetaUpdateUser},
+		{"meta-user", "DELETE", "/api/v1/meta/users/:user", true, true, h.serveMetaDropUser},
+		{"meta-user-settings", "POST", "/api/v1/meta/users/:user/settings", true, true, h.serveMetaSetUserSettings},
+		{"meta-tokens", "GET", "/api/v1/meta/tokens", true, true, h.serveMetaTokens},
+		{"meta-tokens", "POST", "/api/v1/meta/tokens", true, true, h.serveMetaCreateToken},
+		{"meta-token", "DELETE", "/api/v1/meta/tokens/:token", true, true, h.serveMetaDropToken},
+		{"meta-batch", "POST", "/api/v1/meta/batch", true, true, h.serveMetaBatch},
+	}
+}
+
+// MetaDatabase is the meta API representation of a database.
+type MetaDatabase struct {
+	Name                   string                `json:"name"`
+	DefaultRetentionPolicy string                `json:"default_retention_policy,omitempty"`
+	RetentionPolicies      []MetaRetentionPolicy `json:"retention_policies"`
+	ContinuousQueries      []MetaContinuousQuery `json:"continuous_queries"`
+	Quota                  *MetaQuota            `json:"quota,omitempty"`
+}
+
+// MetaRetentionPolicy is the meta API representation of a retention policy.
+// Durations are formatted as InfluxQL durations, with "INF" for a policy
+// that keeps data forever.
+type MetaRetentionPolicy struct {
+	Name               string `json:"name"`
+	Duration           string `json:"duration,omitempty"`
+	ShardGroupDuration string `json:"shard_duration,omitempty"`
+	ReplicaN           *int   `json:"replication,omitempty"`
+	Default            bool   `json:"default,omitempty"`
+}
+
+// MetaContinuousQuery is the meta API representation of a continuous query.
+// When creating a continuous query, Query holds the SELECT statement it runs.
+type MetaContinuousQuery struct {
+	Name          string `json:"name"`
+	Query         string `json:"query"`
+	ResampleEvery string `json:"resample_every,omitempty"`
+	ResampleFor   string `json:"resample_for,omitempty"`
+}
+
+// MetaUser is the meta API representation of a user.
+type MetaUser struct {
+	Name     string `json:"name,omitempty"`
+	Password string `json:"password,omitempty"`
+	Admin    *bool  `json:"admin,omitempty"`
+
+	Settings *MetaUserSettings `json:"settings,omitempty"`
+}
+
+// MetaUserSettings is the meta API representation of the defaults applied
+// to the requests of a user that omit them.
+type MetaUserSettings struct {
+	DefaultDatabase        string `json:"default_database,omitempty"`
+	DefaultRetentionPolicy string `json:"default_retention_policy,omitempty"`
+	Precision              string `json:"precision,omitempty"`
+}
+
+// MetaToken is the meta API representation of an API token. Privileges map
+// database names to "read", "write" or "all". The token secret is only
+// returned when the token is created.
+type MetaToken struct {
+	Name       string            `json:"name"`
+	Token      string            `json:"token,omitempty"`
+	Privileges map[string]string `json:"privileges"`
+}
+
+// MetaBatch is a request of the meta API to execute statements once for each
+// database whose name matches the Databases regular expression. Every
+// occurrence of $database in Query is replaced by the quoted name of the
+// database before it is parsed, for example:
+//
+//	ALTER RETENTION POLICY autogen ON $database DURATION 30d
+//
+// Statements without an ON clause run with the database as their default.
+// With DryRun set, the matching databases are returned without executing
+// anything.
+type MetaBatch struct {
+	Databases string `json:"databases"`
+	Query     string `json:"query"`
+	DryRun    bool   `json:"dry_run,omitempty"`
+}
+
+// MetaBatchResult is the outcome of a batch for one database.
+type MetaBatchResult struct {
+	Database string `json:"database"`
+	Error    string `json:"error,omitempty"`
+}
+
+// MetaShardGroup is the meta API representation of a shard group.
+type MetaShardGroup struct {
+	ID        uint64    `json:"id"`
+	StartTime time.Time `json:"start_time"`
+	EndTime   time.Time `json:"end_time"`
+	Shards    []uint64  `json:"shards"`
+}
+
+// MetaQuota is the meta API representation of the quota of a database. A
+// zero limit is unlimited. Usage is the current usage of the database on the
+// node and is ignored when setting a quota.
+type MetaQuota struct {
+	MaxBytes           int64               `json:"max_bytes"`
+	MaxSeries          int64               `json:"max_series"`
+	MaxWritesPerSecond int64               `json:"max_writes_per_second"`
+	Usage              *tsdb.DatabaseUsage `json:"usage,omitempty"`
+}
+
+// MetaMeasurementCardinality is the meta API representation of the series
+// cardinality of a measurement and the value cardinality of its tag keys.
+type MetaMeasurementCardinality struct {
+	Name    string                  `json:"name"`
+	Series  int                     `json:"series"`
+	TagKeys []MetaTagKeyCardinality `json:"tag_keys"`
+}
+
+// MetaTagKeyCardinality is the meta API representation of the number of
+// distinct values of a tag key.
+type MetaTagKeyCardinality struct {
+	Key    string `json:"key"`
+	Values int    `json:"values"`
+}
+
+func newMetaDatabase(di *meta.DatabaseInfo) MetaDatabase {
+	db := MetaDatabase{
+		Name:                   di.Name,
+		DefaultRetentionPolicy: di.DefaultRetentionPolicy,
+		RetentionPolicies:      make([]MetaRetentionPolicy, 0, len(di.RetentionPolicies)),
+		ContinuousQueries:      make([]MetaContinuousQuery, 0, len(di.ContinuousQueries)),
+	}
+	for i := range di.RetentionPolicies {
+		db.RetentionPolicies = append(db.RetentionPolicies, newMetaRetentionPolicy(di, &di.RetentionPolicies[i]))
+	}
+	for _, cqi := range di.ContinuousQueries {
+		db.ContinuousQueries = append(db.ContinuousQueries, MetaContinuousQuery{Name: cqi.Name, Query: cqi.Query})
+	}
+	if q := di.Quota; q != nil {
+		db.Quota = &MetaQuota{MaxBytes: q.MaxBytes, MaxSeries: q.MaxSeries, MaxWritesPerSecond: q.MaxWritesPerSecond}
+	}
+	return db
+}
+
+func newMetaRetentionPolicy(di *meta.DatabaseInfo, rpi *meta.RetentionPolicyInfo) MetaRetentionPolicy {
+	replicaN := rpi.ReplicaN
+	return MetaRetentionPolicy{
+		Name:               rpi.Name,
+		Duration:           formatMetaDuration(rpi.Duration),
+		ShardGroupDuration: formatMetaDuration(rpi.ShardGroupDuration),
+		ReplicaN:           &replicaN,
+		Default:            di.DefaultRetentionPolicy == rpi.Name,
+	}
+}
+
+// formatMetaDuration formats d as an InfluxQL duration. A zero duration is
+// formatted as "INF".
+func formatMetaDuration(d time.Duration) string {
+	if d == 0 {
+		return "INF"
+	}
+	return influxql.FormatDuration(d)
+}
+
+// parseMetaDuration parses an InfluxQL duration or "INF".
+func parseMetaDuration(s string) (time.Duration, error) {
+	if strings.EqualFold(s, "INF") {
+		return 0, nil
+	}
+	return influxql.ParseDuration(s)
+}
+
+func (h *Handler) serveMetaDatabases(w http.ResponseWriter, r *http.Request, user meta.User) {
+	if !h.authorizeMeta(w, user) {
+		return
+	}
+
+	dbs := h.MetaClient.Databases()
+	resp := make([]MetaDatabase, 0, len(dbs))
+	for i := range dbs {
+		resp = append(resp, newMetaDatabase(&dbs[i]))
+	}
+	h.writeMetaResponse(w, http.StatusOK, resp)
+}
+
+func (h *Handler) serveMetaDatabase(w http.ResponseWriter, r *http.Request, user meta.User) {
+	if !h.authorizeMeta(w, user) {
+		return
+	}
+
+	di := h.metaDatabase(w, r)
+	if di == nil {
+		return
+	}
+	h.writeMetaResponse(w, http.StatusOK, newMetaDatabase(di))
+}
+
+func (h *Handler) serveMetaCreateDatabase(w http.ResponseWriter, r *http.Request, user meta.User) {
+	var db MetaDatabase
+	if !h.decodeMetaRequest(w, r, &db) {
+		return
+	} else if db.Name == "" {
+		h.httpError(w, "database name required", http.StatusBadRequest)
+		return
+	}
+	h.executeMetaStatement(w, user, "", http.StatusCreated, &influxql.CreateDatabaseStatement{Name: db.Name})
+}
+
+func (h *Handler) serveMetaDropDatabase(w http.ResponseWriter, r *http.Request, user meta.User) {
+	db := r.URL.Query().Get(":db")
+	h.executeMetaStatement(w, user, "", http.StatusNoContent, &influxql.DropDatabaseStatement{Name: db})
+}
+
+// serveMetaCloneDatabase creates a copy of a database under the name of
+// the request body. The data of the copy is hard-linked to the original.
+func (h *Handler) serveMetaCloneDatabase(w http.ResponseWriter, r *http.Request, user meta.User) {
+	if h.DatabaseCloner == nil {
+		h.httpError(w, "database cloning is not enabled", http.StatusNotFound)
+		return
+	} else if !h.authorizeMeta(w, user) {
+		return
+	}
+
+	var db MetaDatabase
+	if !h.decodeMetaRequest(w, r, &db) {
+		return
+	} else if db.Name == "" {
+		h.httpError(w, "database name required", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.DatabaseCloner.CloneDatabase(r.URL.Query().Get(":db"), db.Name); err == meta.ErrDatabaseExists {
+		h.httpError(w, err.Error(), http.StatusConflict)
+		return
+	} else if err != nil {
+		h.httpCodedError(w, err, errorStatus(err))
+		return
+	}
+
+	di := h.MetaClient.Database(db.Name)
+	if di == nil {
+		err := influxdb.ErrDatabaseNotFound(db.Name)
+		h.httpCodedError(w, err, errorStatus(err))
+		return
+	}
+	h.writeMetaResponse(w, http.StatusCreated, newMetaDatabase(di))
+}
+
+// serveMetaUndropDatabase recreates the most recently dropped database with
+// the name of the route from the trash. There is no UNDROP statement because
+// the InfluxQL parser is maintained outside of this repository.
+func (h *Handler) serveMetaUndropDatabase(w http.ResponseWriter, r *http.Request, user meta.User) {
+	if h.DatabaseTrash == nil {
+		h.httpError(w, "database trash is not enabled", http.StatusNotFound)
+		return
+	} else if !h.authorizeMeta(w, user) {
+		return
+	}
+
+	db := r.URL.Query().Get(":db")
+	if err := h.DatabaseTrash.UndropDatabase(db); err == meta.ErrDatabaseExists {
+		h.httpError(w, err.Error(), http.StatusConflict)
+		return
+	} else if err != nil {
+		h.httpCodedError(w, err, errorStatus(err))
+		return
+	}
+
+	di := h.MetaClient.Database(db)
+	if di == nil {
+		err := influxdb.ErrDatabaseNotFound(db)
+		h.httpCodedError(w, err, errorStatus(err))
+		return
+	}
+	h.writeMetaResponse(w, http.StatusCreated, newMetaDatabase(di))
+}
+
+// serveMetaTrash returns the dropped databases that can be undropped.
+func (h *Handler) serveMetaTrash(w http.ResponseWriter, r *http.Request, user meta.User) {
+	if h.DatabaseTrash == nil {
+		h.httpError(w, "database trash is not enabled", http.StatusNotFound)
+		return
+	} else if !h.authorizeMeta(w, user) {
+		return
+	}
+
+	a, err := h.DatabaseTrash.TrashedDatabases()
+	if err != nil {
+		h.httpError(w, err.Error(), http.StatusInternalServerError)
+		return
+	} else if a == nil {
+		a = []tsdb.TrashedDatabase{}
+	}
+	h.writeMetaResponse(w, http.StatusOK, a)
+}
+
+func (h *Handler) serveMetaRetentionPolicies(w http.ResponseWriter, r *http.Request, user meta.User) {
+	if !h.authorizeMeta(w, user) {
+		return
+	}
+
+	di := h.metaDatabase(w, r)
+	if di == nil {
+		return
+	}
+	h.writeMetaResponse(w, http.StatusOK, newMetaDatabase(di).RetentionPolicies)
+}
+
+func (h *Handler) serveMetaCreateRetentionPolicy(w http.ResponseWriter, r *http.Request, user meta.User) {
+	var rp MetaRetentionPolicy
+	if !h.decodeMetaRequest(w, r, &rp) {
+		return
+	} else if rp.Name == "" {
+		h.httpError(w, "retention policy name required", http.StatusBadRequest)
+		return
+	} else if rp.Duration == "" {
+		h.httpError(w, "retention policy duration required", http.StatusBadRequest)
+		return
+	}
+
+	stmt := &influxql.CreateRetentionPolicyStatement{
+		Name:        rp.Name,
+		Database:    r.URL.Query().Get(":db"),
+		Replication: 1,
+		Default:     rp.Default,
+	}
+
+	var err error
+	if stmt.Duration, err = parseMetaDuration(rp.Duration); err != nil {
+		h.httpError(w, "invalid duration: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if rp.ShardGroupDuration != "" {
+		if stmt.ShardGroupDuration, err = influxql.ParseDuration(rp.ShardGroupDuration); err != nil {
+			h.httpError(w, "invalid shard duration: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	if rp.ReplicaN != nil {
+		stmt.Replication = *rp.ReplicaN
+	}
+	h.executeMetaStatement(w, user, "", http.StatusCreated, stmt)
+}
+
+func (h *Handler) serveMetaUpdateRetentionPolicy(w http.ResponseWriter, r *http.Request, user meta.User) {
+	var rp MetaRetentionPolicy
+	if !h.decodeMetaRequest(w, r, &rp) {
+		return
+	}
+
+	stmt := &influxql.AlterRetentionPolicyStatement{
+		Name:        r.URL.Query().Get(":rp"),
+		Database:    r.URL.Query().Get(":db"),
+		Replication: rp.ReplicaN,
+		Default:     rp.Default,
+	}
+	if rp.Duration != "" {
+		d, err := parseMetaDuration(rp.Duration)
+		if err != nil {
+			h.httpError(w, "invalid duration: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		stmt.Duration = &d
+	}
+	if rp.ShardGroupDuration != "" {
+		d, err := influxql.ParseDuration(rp.ShardGroupDuration)
+		if err != nil {
+			h.httpError(w, "invalid shard duration: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		stmt.ShardGroupDuration = &d
+	}
+	h.executeMetaStatement(w, user, "", http.StatusNoContent, stmt)
+}
+
+func (h *Handler) serveMetaDropRetentionPolicy(w http.ResponseWriter, r *http.Request, user meta.User) {
+	stmt := &influxql.DropRetentionPolicyStatement{
+		Name:     r.URL.Query().Get(":rp"),
+		Database: r.URL.Query().Get(":db"),
+	}
+	h.executeMetaStatement(w, user, "", http.StatusNoContent, stmt)
+}
+
+func (h *Handler) serveMetaShardGroups(w http.ResponseWriter, r *http.Request, user meta.User) {
+	if !h.authorizeMeta(w, user) {
+		return
+	}
+
+	di := h.metaDatabase(w, r)
+	if di == nil {
+		return
+	}
+	rp := r.URL.Query().Get(":rp")
+	rpi := di.RetentionPolicy(rp)
+	if rpi == nil {
+		err := influxdb.ErrRetentionPolicyNotFound(rp)
+		h.httpCodedError(w, err, errorStatus(err))
+		return
+	}
+
+	groups := make([]MetaShardGroup, 0, len(rpi.ShardGroups))
+	for _, sgi := range rpi.ShardGroups {
+		if sgi.Deleted() {
+			continue
+		}
+		sg := MetaShardGroup{
+			ID:        sgi.ID,
+			StartTime: sgi.StartTime.UTC(),
+			EndTime:   sgi.EndTime.UTC(),
+			Shards:    make([]uint64, 0, len(sgi.Shards)),
+		}
+		for _, si := range sgi.Shards {
+			sg.Shards = append(sg.Shards, si.ID)
+		}
+		groups = append(groups, sg)
+	}
+	h.writeMetaResponse(w, http.StatusOK, groups)
+}
+
+// DefaultMetaCardinalityN is the default number of measurements and tag keys
+// returned by the cardinality report.
+const DefaultMetaCardinalityN = 10
+
+// serveMetaQuota returns the quota of a database with its current usage. A
+// database without a quota has zero limits.
+func (h *Handler) serveMetaQuota(w http.ResponseWriter, r *http.Request, user meta.User) {
+	if !h.authorizeMeta(w, user) {
+		return
+	}
+
+	di := h.metaDatabase(w, r)
+	if di == nil {
+		return
+	}
+
+	u, err := h.Store.Usage(di.Name)
+	if err != nil {
+		h.httpError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	q := MetaQuota{Usage: &u}
+	if di.Quota != nil {
+		q.MaxBytes, q.MaxSeries, q.MaxWritesPerSecond = di.Quota.MaxBytes, di.Quota.MaxSeries, di.Quota.MaxWritesPerSecond
+	}
+	h.writeMetaResponse(w, http.StatusOK, q)
+}
+
+func (h *Handler) serveMetaSetQuota(w http.ResponseWriter, r *http.Request, user meta.User) {
+	if !h.authorizeMeta(w, user) {
+		return
+	}
+
+	var q MetaQuota
+	if !h.decodeMetaRequest(w, r, &q) {
+		return
+	}
+	q.Usage = nil
+
+	db := r.URL.Query().Get(":db")
+	if err := h.MetaClient.SetQuota(db, &meta.QuotaInfo{
+		MaxBytes:           q.MaxBytes,
+		MaxSeries:          q.MaxSeries,
+		MaxWritesPerSecond: q.MaxWritesPerSecond,
+	}); err == meta.ErrQuotaNegative {
+		h.httpError(w, err.Error(), http.StatusBadRequest)
+		return
+	} else if err != nil {
+		h.httpCodedError(w, err, errorStatus(err))
+		return
+	}
+	h.writeMetaResponse(w, http.StatusOK, q)
+}
+
+func (h *Handler) serveMetaDropQuota(w http.ResponseWriter, r *http.Request, user meta.User) {
+	if !h.authorizeMeta(w, user) {
+		return
+	}
+
+	if err := h.MetaClient.SetQuota(r.URL.Query().Get(":db"), nil); err != nil {
+		h.httpCodedError(w, err, errorStatus(err))
+		return
+	}
+	h.writeHeader(w, http.StatusNoContent)
+}
+
+// serveMetaCardinality returns the measurements of a database with the most
+// series and, for each of them, the tag keys with the most values. The n
+// parameter sets the number of measurements and tag keys to return.
+func (h *Handler) serveMetaCardinality(w http.ResponseWriter, r *http.Request, user meta.User) {
+	if !h.authorizeMeta(w, user) {
+		return
+	}
+
+	di := h.metaDatabase(w, r)
+	if di == nil {
+		return
+	}
+
+	n := DefaultMetaCardinalityN
+	if s := r.FormValue("n"); s != "" {
+		v, err := strconv.Atoi(s)
+		if err != nil || v < 0 {
+			h.httpError(w, "n must be a non-negative integer", http.StatusBadRequest)
+			return
+		}
+		n = v
+	}
+
+	a, err := h.Store.MeasurementCardinalities(di.Name, n)
+	if err != nil {
+		h.httpError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	resp := make([]MetaMeasurementCardinality, 0, len(a))
+	for _, mc := range a {
+		m := MetaMeasurementCardinality{
+			Name:    mc.Name,
+			Series:  mc.SeriesN,
+			TagKeys: make([]MetaTagKeyCardinality, 0, len(mc.TagKeys)),
+		}
+		for _, tk := range mc.TagKeys {
+			m.TagKeys = append(m.TagKeys, MetaTagKeyCardinality{Key: tk.Key, Values: tk.ValuesN})
+		}
+		resp = append(resp, m)
+	}
+	h.writeMetaResponse(w, http.StatusOK, resp)
+}
+
+func (h *Handler) serveMetaContinuousQueries(w http.ResponseWriter, r *http.Request, user meta.User) {
+	if !h.authorizeMeta(w, user) {
+		return
+	}
+
+	di := h.metaDatabase(w, r)
+	if di == nil {
+		return
+	}
+	h.writeMetaResponse(w, http.StatusOK, newMetaDatabase(di).ContinuousQueries)
+}
+
+func (h *Handler) serveMetaCreateContinuousQuery(w http.ResponseWriter, r *http.Request, user meta.User) {
+	var cq MetaContinuousQuery
+	if !h.decodeMetaRequest(w, r, &cq) {
+		return
+	} else if cq.Name == "" {
+		h.httpError(w, "continuous query name required", http.StatusBadRequest)
+		return
+	}
+
+	stmt, err := influxql.ParseStatement(cq.Query)
+	if err != nil {
+		h.httpCodedError(w, influxdb.NewError(influxdb.ErrorCodeInvalidQuery, "error parsing query: "+err.Error()), http.StatusBadRequest)
+		return
+	}
+	source, ok := stmt.(*influxql.SelectStatement)
+	if !ok {
+		h.httpCodedError(w, influxdb.NewError(influxdb.ErrorCodeInvalidQuery, "continuous query must be a SELECT statement"), http.StatusBadRequest)
+		return
+	}
+
+	db := r.URL.Query().Get(":db")
+	create := &influxql.CreateContinuousQueryStatement{
+		Name:     cq.Name,
+		Database: db,
+		Source:   source,
+	}
+	if cq.ResampleEvery != "" {
+		if create.ResampleEvery, err = influxql.ParseDuration(cq.ResampleEvery); err != nil {
+			h.httpError(w, "invalid resample every duration: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	if cq.ResampleFor != "" {
+		if create.ResampleFor, err = influxql.ParseDuration(cq.ResampleFor); err != nil {
+			h.httpError(w, "invalid resample for duration: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	h.executeMetaStatement(w, user, db, http.StatusCreated, create)
+}
+
+func (h *Handler) serveMetaDropContinuousQuery(w http.ResponseWriter, r *http.Request, user meta.User) {
+	stmt := &influxql.DropContinuousQueryStatement{
+		Name:     r.URL.Query().Get(":cq"),
+		Database: r.URL.Query().Get(":db"),
+	}
+	h.executeMetaStatement(w, user, "", http.StatusNoContent, stmt)
+}
+
+func (h *Handler) serveMetaUsers(w http.ResponseWriter, r *http.Request, user meta.User) {
+	if !h.authorizeMeta(w, user) {
+		return
+	}
+
+	users := h.MetaClient.Users()
+	resp := make([]MetaUser, 0, len(users))
+	for _, ui := range users {
+		admin := ui.Admin
+		mu := MetaUser{Name: ui.Name, Admin: &admin}
+		if s := ui.Settings; s != (meta.UserSettings{}) {
+			mu.Settings = &MetaUserSettings{
+				DefaultDatabase:        s.Database,
+				DefaultRetentionPolicy: s.RetentionPolicy,
+				Precision:              s.Precision,
+			}
+		}
+		resp = append(resp, mu)
+	}
+	h.writeMetaResponse(w, http.StatusOK, resp)
+}
+
+func (h *Handler) serveMetaCreateUser(w http.ResponseWriter, r *http.Request, user meta.User) {
+	var u MetaUser
+	if !h.decodeMetaRequest(w, r, &u) {
+		return
+	} else if u.Name == "" {
+		h.httpError(w, "user name required", http.StatusBadRequest)
+		return
+	} else if u.Password == "" {
+		h.httpError(w, "user password required", http.StatusBadRequest)
+		return
+	}
+
+	stmt := &influxql.CreateUserStatement{Name: u.Name, Password: u.Password}
+	if u.Admin != nil {
+		stmt.Admin = *u.Admin
+	}
+	h.executeMetaStatement(w, user, "", http.StatusCreated, stmt)
+}
+
+func (h *Handler) serveMetaUpdateUser(w http.ResponseWriter, r *http.Request, user meta.User) {
+	var u MetaUser
+	if !h.decodeMetaRequest(w, r, &u) {
+		return
+	}
+
+	name := r.URL.Query().Get(":user")
+	var stmts influxql.Statements
+	if u.Password != "" {
+		stmts = append(stmts, &influxql.SetPasswordUserStatement{Name: name, Password: u.Password})
+	}
+	if u.Admin != nil {
+		if *u.Admin {
+			stmts = append(stmts, &influxql.GrantAdminStatement{User: name})
+		} else {
+			stmts = append(stmts, &influxql.RevokeAdminStatement{User: name})
+		}
+	}
+	if len(stmts) == 0 {
+		h.httpError(w, "password or admin required", http.StatusBadRequest)
+		return
+	}
+	h.executeMetaStatement(w, user, "", http.StatusNoContent, stmts...)
+}
+
+// serveMetaSetUserSettings replaces the defaults used by the requests of a
+// user that do not set a database, retention policy or precision.
+func (h *Handler) serveMetaSetUserSettings(w http.ResponseWriter, r *http.Request, user meta.User) {
+	if !h.authorizeMeta(w, user) {
+		return
+	}
+
+	var s MetaUserSettings
+	if !h.decodeMetaRequest(w, r, &s) {
+		return
+	}
+
+	if err := h.MetaClient.SetUserSettings(r.URL.Query().Get(":user"), meta.UserSettings{
+		Database:        s.DefaultDatabase,
+		RetentionPolicy: s.DefaultRetentionPolicy,
+		Precision:       s.Precision,
+	}); err == meta.ErrUserSettingsPrecision {
+		h.httpError(w, err.Error(), http.StatusBadRequest)
+		return
+	} else if err == meta.ErrUserNotFound {
+		h.httpError(w, err.Error(), http.StatusNotFound)
+		return
+	} else if err != nil {
+		h.httpCodedError(w, err, errorStatus(err))
+		return
+	}
+	h.writeMetaResponse(w, http.StatusOK, s)
+}
+
+func (h *Handler) serveMetaDropUser(w http.ResponseWriter, r *http.Request, user meta.User) {
+	stmt := &influxql.DropUserStatement{Name: r.URL.Query().Get(":user")}
+	h.executeMetaStatement(w, user, "", http.StatusNoContent, stmt)
+}
+
+func (h *Handler) serveMetaTokens(w http.ResponseWriter, r *http.Request, user meta.User) {
+	if !h.authorizeMeta(w, user) {
+		return
+	}
+
+	tokens := h.MetaClient.Tokens()
+	resp := make([]MetaToken, 0, len(tokens))
+	for _, ti := range tokens {
+		t := MetaToken{Name: ti.Name, Privileges: make(map[string]string, len(ti.Privileges))}
+		for db, p := range ti.Privileges {
+			t.Privileges[db] = formatMetaPrivilege(p)
+		}
+		resp = append(resp, t)
+	}
+	h.writeMetaResponse(w, http.StatusOK, resp)
+}
+
+func (h *Handler) serveMetaCreateToken(w http.ResponseWriter, r *http.Request, user meta.User) {
+	if !h.authorizeMeta(w, user) {
+		return
+	}
+
+	var t MetaToken
+	if !h.decodeMetaRequest(w, r, &t) {
+		return
+	} else if t.Name == "" {
+		h.httpError(w, "token name required", http.StatusBadRequest)
+		return
+	}
+
+	privileges := make(map[string]influxql.Privilege, len(t.Privileges))
+	for db, s := range t.Privileges {
+		p, err := parseMetaPrivilege(s)
+		if err != nil {
+			h.httpError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		privileges[db] = p
+	}
+
+	token, err := h.MetaClient.CreateToken(t.Name, privileges)
+	if err != nil {
+		h.httpError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	t.Token = token
+	if t.Privileges == nil {
+		t.Privileges = map[string]string{}
+	}
+	h.writeMetaResponse(w, http.StatusCreated, t)
+}
+
+func (h *Handler) serveMetaDropToken(w http.ResponseWriter, r *http.Request, user meta.User) {
+	if !h.authorizeMeta(w, user) {
+		return
+	}
+
+	if err := h.MetaClient.DropToken(r.URL.Query().Get(":token")); err == meta.ErrTokenNotFound {
+		h.httpError(w, err.Error(), http.StatusNotFound)
+		return
+	} else if err != nil {
+		h.httpError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	h.writeHeader(w, http.StatusNoContent)
+}
+
+// parseMetaPrivilege parses a privilege of the meta API.
+func parseMetaPrivilege(s string) (influxql.Privilege, error) {
+	switch strings.ToLower(s) {
+	case "read":
+		return influxql.ReadPrivilege, nil
+	case "write":
+		return influxql.WritePrivilege, nil
+	case "all":
+		return influxql.AllPrivileges, nil
+	}
+	return influxql.NoPrivileges, fmt.Errorf("invalid privilege %q (use read, write or all)", s)
+}
+
+// formatMetaPrivilege formats a privilege for the meta API.
+func formatMetaPrivilege(p influxql.Privilege) string {
+	switch p {
+	case influxql.ReadPrivilege:
+		return "read"
+	case influxql.WritePrivilege:
+		return "write"
+	case influxql.AllPrivileges:
+		return "all"
+	}
+	return "none"
+}
+
+// authorizeMeta ensures the user may read from the meta API. Reading requires
+// admin privileges when authentication is enabled. It writes an error to the
+// client and returns false if the user is not authorized.
+func (h *Handler) authorizeMeta(w http.ResponseWriter, user meta.User) bool {
+	if !h.Config.AuthEnabled || (user != nil && user.IsAdmin()) {
+		return true
+	}
+	h.httpCodedError(w, influxdb.NewError(influxdb.ErrorCodeUnauthorized, "admin privilege required"), http.StatusForbidden)
+	return false
+}
+
+// serveMetaBatch executes a query for each database matching a pattern. A
+// failure on one database does not stop the others; the result of each
+// database is returned in the order of their names.
+func (h *Handler) serveMetaBatch(w http.ResponseWriter, r *http.Request, user meta.User) {
+	if !h.authorizeMeta(w, user) {
+		return
+	}
+
+	var b MetaBatch
+	if !h.decodeMetaRequest(w, r, &b) {
+		return
+	} else if b.Databases == "" {
+		h.httpError(w, "databases pattern required", http.StatusBadRequest)
+		return
+	} else if b.Query == "" {
+		h.httpError(w, "query required", http.StatusBadRequest)
+		return
+	}
+
+	re, err := regexp.Compile(b.Databases)
+	if err != nil {
+		h.httpError(w, "invalid databases pattern: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var names []string
+	for _, di := range h.MetaClient.Databases() {
+		if re.MatchString(di.Name) {
+			names = append(names, di.Name)
+		}
+	}
+	sort.Strings(names)
+
+	resp := make([]MetaBatchResult, 0, len(names))
+	for _, name := range names {
+		res := MetaBatchResult{Database: name}
+		if !b.DryRun {
+			qs := strings.Replace(b.Query, "$database", influxql.QuoteIdent(name), -1)
+			if q, err := influxql.ParseQuery(qs); err != nil {
+				res.Error = err.Error()
+			} else if err := h.executeMetaQuery(user, name, q); err != nil {
+				res.Error = err.Error()
+			}
+		}
+		resp = append(resp, res)
+	}
+	h.writeMetaResponse(w, http.StatusOK, resp)
+}
+
+// metaDatabase returns the database named in the request path. It writes an
+// error to the client and returns nil if the database does not exist.
+func (h *Handler) metaDatabase(w http.ResponseWriter, r *http.Request) *meta.DatabaseInfo {
+	name := r.URL.Query().Get(":db")
+	di := h.MetaClient.Database(name)
+	if di == nil {
+		err := influxdb.ErrDatabaseNotFound(name)
+		h.httpCodedError(w, err, errorStatus(err))
+	}
+	return di
+}
+
+// decodeMetaRequest decodes the JSON request body into v. It writes an error
+// to the client and returns false if the body cannot be decoded.
+func (h *Handler) decodeMetaRequest(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		h.httpError(w, "error parsing request body: "+err.Error(), http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+// executeMetaStatement authorizes and executes the statements on behalf of
+// the user and writes the given status to the client on success. Execution
+// stops at the first statement that fails.
+func (h *Handler) executeMetaStatement(w http.ResponseWriter, user meta.User, db string, status int, stmts ...influxql.Statement) {
+	if err := h.executeMetaQuery(user, db, &influxql.Query{Statements: stmts}); err != nil {
+		// Errors without a code are validation errors from the meta store.
+		code := http.StatusBadRequest
+		if influxdb.ErrorCodeOf(err) != "" {
+			code = errorStatus(err)
+		}
+		h.httpCodedError(w, err, code)
+		return
+	}
+	h.writeHeader(w, status)
+}
+
+// executeMetaQuery authorizes and executes q on behalf of the user with db as
+// the default database. It returns the error of the first statement that
+// fails.
+func (h *Handler) executeMetaQuery(user meta.User, db string, q *influxql.Query) error {
+	opts := query.ExecutionOptions{
+		Database:     db,
+		AbortOnError: true,
+	}
+	if h.Config.AuthEnabled {
+		if err := h.QueryAuthorizer.AuthorizeQuery(user, q, db); err != nil {
+			return influxdb.NewError(influxdb.ErrorCodeUnauthorized, "error authorizing query: "+err.Error())
+		}
+		opts.Authorizer = user
+	} else {
+		opts.Authorizer = query.OpenAuthorizer{}
+	}
+
+	closing := make(chan struct{})
+	defer close(closing)
+
+	var err error
+	for r := range h.QueryExecutor.ExecuteQuery(q, opts, closing) {
+		if r.Err != nil && err == nil {
+			err = r.Err
+		}
+	}
+	return err
+}
+
+// writeMetaResponse writes v to the client as JSON with the given status.
+func (h *Handler) writeMetaResponse(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	h.writeHeader(w, status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,414 @@
+package httpd_test
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/query"
+	"github.com/influxdata/influxdb/services/meta"
+	"github.com/influxdata/influxdb/tsdb"
+	"github.com/influxdata/influxql"
+)
+
+// Ensure the meta API lists databases and their retention policies.
+func TestHandler_Meta_Databases(t *testing.T) {
+	h := NewHandler(false)
+	h.MetaClient.DatabasesFn = func() []meta.DatabaseInfo {
+		return []meta.DatabaseInfo{{
+			Name:                   "db0",
+			DefaultRetentionPolicy: "autogen",
+			RetentionPolicies: []meta.RetentionPolicyInfo{
+				{Name: "autogen", ReplicaN: 1, ShardGroupDuration: 7 * 24 * time.Hour},
+				{Name: "rp0", ReplicaN: 1, Duration: 24 * time.Hour, ShardGroupDuration: time.Hour},
+			},
+			ContinuousQueries: []meta.ContinuousQueryInfo{
+				{Name: "cq0", Query: `CREATE CONTINUOUS QUERY cq0 ON db0 BEGIN SELECT count(value) INTO cpu_count FROM cpu GROUP BY time(1h) END`},
+			},
+		}}
+	}
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, MustNewRequest("GET", "/api/v1/meta/databases", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("unexpected status: %d", w.Code)
+	} else if body := strings.TrimSpace(w.Body.String()); body != `[{"name":"db0","default_retention_policy":"autogen","retention_policies":[{"name":"autogen","duration":"INF","shard_duration":"1w","replication":1,"default":true},{"name":"rp0","duration":"1d","shard_duration":"1h","replication":1}],"continuous_queries":[{"name":"cq0","query":"CREATE CONTINUOUS QUERY cq0 ON db0 BEGIN SELECT count(value) INTO cpu_count FROM cpu GROUP BY time(1h) END"}]}]` {
+		t.Fatalf("unexpected body: %s", body)
+	}
+}
+
+// Ensure the meta API returns a 404 for an unknown database.
+func TestHandler_Meta_DatabaseNotFound(t *testing.T) {
+	h := NewHandler(false)
+	h.MetaClient.DatabaseFn = func(name string) *meta.DatabaseInfo { return nil }
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, MustNewRequest("GET", "/api/v1/meta/databases/db0/retention-policies", nil))
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("unexpected status: %d", w.Code)
+	} else if body := strings.TrimSpace(w.Body.String()); body != `{"error":"database not found: db0","code":"database_not_found"}` {
+		t.Fatalf("unexpected body: %s", body)
+	}
+}
+
+// Ensure the meta API clones databases.
+func TestHandler_Meta_CloneDatabase(t *testing.T) {
+	h := NewHandler(false)
+	var src, dst string
+	h.Handler.DatabaseCloner = &HandlerDatabaseCloner{
+		CloneDatabaseFn: func(s, d string) error {
+			if d == "db0" {
+				return meta.ErrDatabaseExists
+			}
+			src, dst = s, d
+			return nil
+		},
+	}
+	h.MetaClient.DatabaseFn = func(name string) *meta.DatabaseInfo {
+		return &meta.DatabaseInfo{Name: name}
+	}
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, MustNewRequest("POST", "/api/v1/meta/databases/db0/clone", strings.NewReader(`{"name":"staging"}`)))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("unexpected status: %d", w.Code)
+	} else if src != "db0" || dst != "staging" {
+		t.Fatalf("unexpected clone: %s to %s", src, dst)
+	} else if body := strings.TrimSpace(w.Body.String()); body != `{"name":"staging","retention_policies":[],"continuous_queries":[]}` {
+		t.Fatalf("unexpected body: %s", body)
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, MustNewRequest("POST", "/api/v1/meta/databases/staging/clone", strings.NewReader(`{"name":"db0"}`)))
+	if w.Code != http.StatusConflict {
+		t.Fatalf("unexpected status: %d", w.Code)
+	}
+}
+
+func TestHandler_Meta_UndropDatabase(t *testing.T) {
+	h := NewHandler(false)
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, MustNewRequest("GET", "/api/v1/meta/trash", nil))
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("unexpected status: %d", w.Code)
+	}
+
+	var undropped string
+	droppedAt := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	h.Handler.DatabaseTrash = &HandlerDatabaseTrash{
+		TrashedDatabasesFn: func() ([]tsdb.TrashedDatabase, error) {
+			return []tsdb.TrashedDatabase{{ID: "1", Name: "db0", DroppedAt: droppedAt, ExpiresAt: droppedAt.Add(time.Hour), Info: []byte("info")}}, nil
+		},
+		UndropDatabaseFn: func(name string) error {
+			if name == "db1" {
+				return meta.ErrDatabaseExists
+			}
+			undropped = name
+			return nil
+		},
+	}
+	h.MetaClient.DatabaseFn = func(name string) *meta.DatabaseInfo {
+		return &meta.DatabaseInfo{Name: name}
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, MustNewRequest("GET", "/api/v1/meta/trash", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("unexpected status: %d", w.Code)
+	} else if body := strings.TrimSpace(w.Body.String()); body != `[{"id":"1","name":"db0","dropped_at":"2000-01-01T00:00:00Z","expires_at":"2000-01-01T01:00:00Z"}]` {
+		t.Fatalf("unexpected body: %s", body)
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, MustNewRequest("POST", "/api/v1/meta/databases/db0/undrop", nil))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("unexpected status: %d", w.Code)
+	} else if undropped != "db0" {
+		t.Fatalf("unexpected undropped database: %s", undropped)
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, MustNewRequest("POST", "/api/v1/meta/databases/db1/undrop", nil))
+	if w.Code != http.StatusConflict {
+		t.Fatalf("unexpected status: %d", w.Code)
+	}
+}
+
+// Ensure the meta API executes changes as statements.
+func TestHandler_Meta_CreateRetentionPolicy(t *testing.T) {
+	h := NewHandler(false)
+	h.StatementExecutor.ExecuteStatementFn = func(stmt influxql.Statement, ctx query.ExecutionContext) error {
+		if exp := `CREATE RETENTION POLICY rp0 ON db0 DURATION 2d REPLICATION 2 SHARD DURATION 1h DEFAULT`; stmt.String() != exp {
+			t.Fatalf("unexpected statement: %s", stmt)
+		}
+		return nil
+	}
+
+	body := bytes.NewBufferString(`{"name":"rp0","duration":"2d","shard_duration":"1h","replication":2,"default":true}`)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, MustNewRequest("POST", "/api/v1/meta/databases/db0/retention-policies", body))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("unexpected status: %d: %s", w.Code, w.Body.String())
+	}
+}
+
+// Ensure the meta API reports statement errors as client errors.
+func TestHandler_Meta_UpdateRetentionPolicy_Error(t *testing.T) {
+	h := NewHandler(false)
+	h.StatementExecutor.ExecuteStatementFn = func(stmt influxql.Statement, ctx query.ExecutionContext) error {
+		if exp := `ALTER RETENTION POLICY rp0 ON db0 DURATION 0s DEFAULT`; stmt.String() != exp {
+			t.Fatalf("unexpected statement: %s", stmt)
+		}
+		return meta.ErrIncompatibleDurations
+	}
+
+	body := bytes.NewBufferString(`{"duration":"INF","default":true}`)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, MustNewRequest("POST", "/api/v1/meta/databases/db0/retention-policies/rp0", body))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("unexpected status: %d", w.Code)
+	} else if body := strings.TrimSpace(w.Body.String()); body != `{"error":"retention policy duration must be greater than the shard duration"}` {
+		t.Fatalf("unexpected body: %s", body)
+	}
+}
+
+// Ensure the meta API applies a batch to every matching database.
+func TestHandler_Meta_Batch(t *testing.T) {
+	h := NewHandler(false)
+	h.MetaClient.DatabasesFn = func() []meta.DatabaseInfo {
+		return []meta.DatabaseInfo{{Name: "tenant_b"}, {Name: "other"}, {Name: "tenant_a"}}
+	}
+	var stmts []string
+	h.StatementExecutor.ExecuteStatementFn = func(stmt influxql.Statement, ctx query.ExecutionContext) error {
+		stmts = append(stmts, stmt.String())
+		if ctx.Database == "tenant_b" {
+			return errors.New("marker")
+		}
+		return nil
+	}
+
+	body := strings.NewReader(`{"databases":"^tenant_","query":"ALTER RETENTION POLICY autogen ON $database DURATION 30d"}`)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, MustNewRequest("POST", "/api/v1/meta/batch", body))
+	if w.Code != http.StatusOK {
+		t.Fatalf("unexpected status: %d", w.Code)
+	} else if body := strings.TrimSpace(w.Body.String()); body != `[{"database":"tenant_a"},{"database":"tenant_b","error":"marker"}]` {
+		t.Fatalf("unexpected body: %s", body)
+	} else if exp := []string{
+		`ALTER RETENTION POLICY autogen ON tenant_a DURATION 30d`,
+		`ALTER RETENTION POLICY autogen ON tenant_b DURATION 30d`,
+	}; !reflect.DeepEqual(stmts, exp) {
+		t.Fatalf("unexpected statements: %v", stmts)
+	}
+
+	// A dry run only lists the databases.
+	stmts = nil
+	body = strings.NewReader(`{"databases":"^tenant_","query":"DROP DATABASE $database","dry_run":true}`)
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, MustNewRequest("POST", "/api/v1/meta/batch", body))
+	if w.Code != http.StatusOK {
+		t.Fatalf("unexpected status: %d", w.Code)
+	} else if body := strings.TrimSpace(w.Body.String()); body != `[{"database":"tenant_a"},{"database":"tenant_b"}]` {
+		t.Fatalf("unexpected body: %s", body)
+	} else if len(stmts) != 0 {
+		t.Fatalf("unexpected statements: %v", stmts)
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, MustNewRequest("POST", "/api/v1/meta/batch", strings.NewReader(`{"databases":"(","query":"DROP DATABASE $database"}`)))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("unexpected status: %d", w.Code)
+	}
+}
+
+// Ensure reading from the meta API requires admin privileges.
+func TestHandler_Meta_Users_Unauthorized(t *testing.T) {
+	h := NewHandler(true)
+	h.MetaClient.AdminUserExistsFn = func() bool { return true }
+	h.MetaClient.AuthenticateFn = func(u, p string) (meta.User, error) {
+		return &meta.UserInfo{Name: u}, nil
+	}
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, MustNewRequest("GET", "/api/v1/meta/users?u=user1&p=abcd", nil))
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("unexpected status: %d", w.Code)
+	} else if body := strings.TrimSpace(w.Body.String()); body != `{"error":"admin privilege required","code":"unauthorized"}` {
+		t.Fatalf("unexpected body: %s", body)
+	}
+}
+
+// Ensure the meta API reports the measurements with the most series.
+func TestHandler_Meta_Cardinality(t *testing.T) {
+	h := NewHandler(false)
+	h.MetaClient.DatabaseFn = func(name string) *meta.DatabaseInfo {
+		return &meta.DatabaseInfo{Name: name}
+	}
+	h.Store.MeasurementCardinalitiesFn = func(database string, n int) ([]tsdb.MeasurementCardinality, error) {
+		if database != "db0" {
+			t.Fatalf("unexpected database: %s", database)
+		} else if n != 5 {
+			t.Fatalf("unexpected n: %d", n)
+		}
+		return []tsdb.MeasurementCardinality{
+			{Name: "cpu", SeriesN: 10, TagKeys: []tsdb.TagKeyCardinality{{Key: "host", ValuesN: 10}, {Key: "region", ValuesN: 2}}},
+			{Name: "mem", SeriesN: 5},
+		}, nil
+	}
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, MustNewRequest("GET", "/api/v1/meta/databases/db0/cardinality?n=5", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("unexpected status: %d", w.Code)
+	} else if body := strings.TrimSpace(w.Body.String()); body != `[{"name":"cpu","series":10,"tag_keys":[{"key":"host","values":10},{"key":"region","values":2}]},{"name":"mem","series":5,"tag_keys":[]}]` {
+		t.Fatalf("unexpected body: %s", body)
+	}
+}
+
+// Ensure the meta API sets quotas and reports usage.
+func TestHandler_Meta_Quota(t *testing.T) {
+	h := NewHandler(false)
+	var quota *meta.QuotaInfo
+	h.MetaClient.DatabaseFn = func(name string) *meta.DatabaseInfo {
+		return &meta.DatabaseInfo{Name: name, Quota: quota}
+	}
+	h.MetaClient.SetQuotaFn = func(database string, q *meta.QuotaInfo) error {
+		if q != nil && q.MaxSeries < 0 {
+			return meta.ErrQuotaNegative
+		}
+		quota = q
+		return nil
+	}
+	h.Store.UsageFn = func(database string) (tsdb.DatabaseUsage, error) {
+		return tsdb.DatabaseUsage{Bytes: 2048, Series: 10}, nil
+	}
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, MustNewRequest("POST", "/api/v1/meta/databases/db0/quota", strings.NewReader(`{"max_series":100,"max_writes_per_second":1000}`)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("unexpected status: %d", w.Code)
+	} else if quota == nil || quota.MaxSeries != 100 || quota.MaxWritesPerSecond != 1000 {
+		t.Fatalf("unexpected quota: %+v", quota)
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, MustNewRequest("GET", "/api/v1/meta/databases/db0/quota", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("unexpected status: %d", w.Code)
+	} else if body := strings.TrimSpace(w.Body.String()); body != `{"max_bytes":0,"max_series":100,"max_writes_per_second":1000,"usage":{"bytes":2048,"series":10}}` {
+		t.Fatalf("unexpected body: %s", body)
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, MustNewRequest("POST", "/api/v1/meta/databases/db0/quota", strings.NewReader(`{"max_series":-1}`)))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("unexpected status: %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, MustNewRequest("DELETE", "/api/v1/meta/databases/db0/quota", nil))
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("unexpected status: %d", w.Code)
+	} else if quota != nil {
+		t.Fatalf("unexpected quota: %+v", quota)
+	}
+}
+
+// Ensure the meta API sets and lists the settings of a user.
+func TestHandler_Meta_UserSettings(t *testing.T) {
+	h := NewHandler(false)
+	users := []meta.UserInfo{{Name: "user1"}}
+	h.MetaClient.UsersFn = func() []meta.UserInfo { return users }
+	h.MetaClient.SetUserSettingsFn = func(name string, s meta.UserSettings) error {
+		if name != "user1" {
+			return meta.ErrUserNotFound
+		} else if s.Precision == "x" {
+			return meta.ErrUserSettingsPrecision
+		}
+		users[0].Settings = s
+		return nil
+	}
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, MustNewRequest("POST", "/api/v1/meta/users/user1/settings", strings.NewReader(`{"default_database":"db0","precision":"s"}`)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("unexpected status: %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, MustNewRequest("GET", "/api/v1/meta/users", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("unexpected status: %d", w.Code)
+	} else if body := strings.TrimSpace(w.Body.String()); body != `[{"name":"user1","admin":false,"settings":{"default_database":"db0","precision":"s"}}]` {
+		t.Fatalf("unexpected body: %s", body)
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, MustNewRequest("POST", "/api/v1/meta/users/user1/settings", strings.NewReader(`{"precision":"x"}`)))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("unexpected status: %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, MustNewRequest("POST", "/api/v1/meta/users/user2/settings", strings.NewReader(`{}`)))
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("unexpected status: %d", w.Code)
+	}
+}
+
+// Ensure the meta API creates tokens and returns the secret once.
+func TestHandler_Meta_CreateToken(t *testing.T) {
+	h := NewHandler(false)
+	h.MetaClient.CreateTokenFn = func(name string, privileges map[string]influxql.Privilege) (string, error) {
+		if name != "telegraf" {
+			t.Fatalf("unexpected name: %s", name)
+		} else if len(privileges) != 1 || privileges["db0"] != influxql.WritePrivilege {
+			t.Fatalf("unexpected privileges: %v", privileges)
+		}
+		return "secret", nil
+	}
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, MustNewRequest("POST", "/api/v1/meta/tokens", strings.NewReader(`{"name":"telegraf","privileges":{"db0":"write"}}`)))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("unexpected status: %d", w.Code)
+	} else if body := strings.TrimSpace(w.Body.String()); body != `{"name":"telegraf","token":"secret","privileges":{"db0":"write"}}` {
+		t.Fatalf("unexpected body: %s", body)
+	}
+
+	// Unknown privileges are rejected.
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, MustNewRequest("POST", "/api/v1/meta/tokens", strings.NewReader(`{"name":"telegraf","privileges":{"db0":"admin"}}`)))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("unexpected status: %d", w.Code)
+	}
+}
+
+// HandlerDatabaseCloner is a mock implementation of Handler.DatabaseCloner.
+type HandlerDatabaseCloner struct {
+	CloneDatabaseFn func(src, dst string) error
+}
+
+func (c *HandlerDatabaseCloner) CloneDatabase(src, dst string) error {
+	return c.CloneDatabaseFn(src, dst)
+}
+
+// HandlerDatabaseTrash is a mock implementation of Handler.DatabaseTrash.
+type HandlerDatabaseTrash struct {
+	TrashedDatabasesFn func() ([]tsdb.TrashedDatabase, error)
+	UndropDatabaseFn   func(name string) error
+}
+
+func (t *HandlerDatabaseTrash) TrashedDatabases() ([]tsdb.TrashedDatabase, error) {
+	return t.TrashedDatabasesFn()
+}
+
+func (t *HandlerDatabaseTrash) UndropDatabase(name string) error {
+	return t.UndropDatabaseFn(name)
+}
@@ -0,0 +1,73 @@
+package httpd
+
+import (
+	"sync"
+
+	"github.com/influxdata/influxdb/models"
+)
+
+// queryStatsBufferSize is the number of query statistics that can wait to be
+// written to the monitor database.
+const queryStatsBufferSize = 1000
+
+// queryStatsWriter writes the statistics of queries to the monitor database
+// in the background so that a query never waits on the write. Statistics are
+// dropped while the buffer is full.
+type queryStatsWriter struct {
+	points  chan models.Point
+	once    sync.Once
+	closing chan struct{}
+	wg      sync.WaitGroup
+}
+
+func newQueryStatsWriter() *queryStatsWriter {
+	return &queryStatsWriter{
+		points:  make(chan models.Point, queryStatsBufferSize),
+		closing: make(chan struct{}),
+	}
+}
+
+// add queues p to be written with write. The first call starts the writer.
+// It returns false if the buffer is full.
+func (w *queryStatsWriter) add(p models.Point, write func(models.Points) error) bool {
+	w.once.Do(func() {
+		w.wg.Add(1)
+		go w.run(write)
+	})
+
+	select {
+	case w.points <- p:
+		return true
+	default:
+		return false
+	}
+}
+
+// run writes the queued points until the writer is closed. Points queued
+// while a write is in progress are written together in the next one.
+func (w *queryStatsWriter) run(write func(models.Points) error) {
+	defer w.wg.Done()
+	for {
+		select {
+		case p := <-w.points:
+			batch := models.Points{p}
+			for n := len(w.points); n > 0; n-- {
+				batch = append(batch, <-w.points)
+			}
+			write(batch)
+		case <-w.closing:
+			return
+		}
+	}
+}
+
+// close stops the writer and discards the statistics still queued.
+func (w *queryStatsWriter) close() {
+	w.once.Do(func() {})
+	select {
+	case <-w.closing:
+	default:
+		close(w.closing)
+	}
+	w.wg.Wait()
+}
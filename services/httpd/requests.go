@@ -131,9 +131,9 @@ func (rt *RequestTracker) Add(req *http.Request, user meta.User) {
 	// Add the request info to the profiles.
 	for p := rt.profiles.Front(); p != nil; p = p.Next() {
 		profile := p.Value.(*RequestProfile)
-		if req.URL.Path == "/query" {
+		if isAPIPath(req.URL.Path, "/query") {
 			profile.AddQuery(info)
-		} else if req.URL.Path == "/write" {
+		} else if isAPIPath(req.URL.Path, "/write") {
 			profile.AddWrite(info)
 		}
 	}
@@ -0,0 +1,38 @@
+package httpd_test
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/influxdata/influxdb/services/httpd"
+)
+
+// Ensure only the query and write endpoints are counted as queries and writes.
+func TestRequestTracker_Add(t *testing.T) {
+	rt := httpd.NewRequestTracker()
+	profile := rt.TrackRequests()
+	defer profile.Stop()
+
+	for _, path := range []string{
+		"/query",
+		"/api/v1/query",
+		"/write",
+		"/api/v1/write",
+		"/api/v2/write",
+		"/api/v1/prom/write",
+		"/api/v1/prom/read",
+	} {
+		req := httptest.NewRequest("POST", path, nil)
+		req.RemoteAddr = "127.0.0.1:8086"
+		rt.Add(req, nil)
+	}
+
+	st := profile.Requests[httpd.RequestInfo{IPAddr: "127.0.0.1"}]
+	if st == nil {
+		t.Fatal("expected requests to be tracked")
+	} else if st.Queries != 2 {
+		t.Fatalf("unexpected queries: %d", st.Queries)
+	} else if st.Writes != 3 {
+		t.Fatalf("unexpected writes: %d", st.Writes)
+	}
+}
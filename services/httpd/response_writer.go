@@ -1,14 +1,19 @@
 package httpd
 
 import (
+	"bytes"
 	"encoding/csv"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"strconv"
 	"time"
 
+	"github.com/influxdata/influxdb"
 	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/pkg/parquet"
 	"github.com/tinylib/msgp/msgp"
 )
 
@@ -20,18 +25,40 @@ type ResponseWriter interface {
 	http.ResponseWriter
 }
 
-// NewResponseWriter creates a new ResponseWriter based on the Accept header
-// in the request that wraps the ResponseWriter.
+// formatContentTypes maps the values of the format parameter to the content
+// types they request.
+var formatContentTypes = map[string]string{
+	"csv":     "text/csv",
+	"json":    "application/json",
+	"msgpack": "application/x-msgpack",
+	"parquet": parquetContentType,
+}
+
+// acceptedContentType returns the content type requested by the format
+// parameter of the request or, if there is none, by its Accept header.
+func acceptedContentType(r *http.Request) string {
+	if typ, ok := formatContentTypes[r.URL.Query().Get("format")]; ok {
+		return typ
+	}
+	return r.Header.Get("Accept")
+}
+
+// NewResponseWriter creates a new ResponseWriter based on the format
+// parameter or the Accept header in the request that wraps the
+// ResponseWriter.
 func NewResponseWriter(w http.ResponseWriter, r *http.Request) ResponseWriter {
 	pretty := r.URL.Query().Get("pretty") == "true"
 	rw := &responseWriter{ResponseWriter: w}
-	switch r.Header.Get("Accept") {
+	switch acceptedContentType(r) {
 	case "application/csv", "text/csv":
 		w.Header().Add("Content-Type", "text/csv")
 		rw.formatter = &csvFormatter{statementID: -1, Writer: w}
 	case "application/x-msgpack":
 		w.Header().Add("Content-Type", "application/x-msgpack")
 		rw.formatter = &msgpackFormatter{Writer: w}
+	case parquetContentType:
+		w.Header().Add("Content-Type", parquetContentType)
+		rw.formatter = &parquetFormatter{Writer: w}
 	case "application/json":
 		fallthrough
 	default:
@@ -193,6 +220,143 @@ func (w *csvFormatter) WriteResponse(resp Response) (n int, err error) {
 	return n, nil
 }
 
+// parquetContentType is the media type of Apache Parquet files.
+const parquetContentType = "application/vnd.apache.parquet"
+
+// parquetFormatter writes each response as a Parquet file containing the
+// flattened series of its result. Errors are written as a file with a single
+// error column, like the CSV formatter does.
+type parquetFormatter struct {
+	io.Writer
+}
+
+func (f *parquetFormatter) WriteResponse(resp Response) (n int, err error) {
+	table, err := parquetTable(resp)
+	if err != nil {
+		table = &models.Row{Columns: []string{"error"}, Values: [][]interface{}{{err.Error()}}}
+	}
+
+	columns := make([]parquet.Column, len(table.Columns))
+	for i, name := range table.Columns {
+		columns[i] = parquet.Column{Name: name, Type: parquetColumnType(name, table.Values, i)}
+	}
+
+	var buf bytes.Buffer
+	pw := parquet.NewWriter(&buf, columns)
+	row := make([]interface{}, len(columns))
+	for _, values := range table.Values {
+		for i, v := range values {
+			row[i] = parquetValue(columns[i].Type, v)
+		}
+		if err := pw.Write(row); err != nil {
+			return 0, err
+		}
+	}
+	if err := pw.Close(); err != nil {
+		return 0, err
+	}
+	return f.Write(buf.Bytes())
+}
+
+// parquetTable returns the single table of a response. A file only holds one
+// table, so at most one statement may return series.
+func parquetTable(resp Response) (*models.Row, error) {
+	if resp.Err != nil {
+		return nil, resp.Err
+	}
+
+	var table *models.Row
+	for _, result := range resp.Results {
+		if result.Err != nil {
+			return nil, result.Err
+		} else if len(result.Series) == 0 {
+			continue
+		} else if table != nil {
+			return nil, errors.New("parquet responses may only contain the results of one statement")
+		}
+		flattenResult(result)
+		table = result.Series[0]
+	}
+	if table == nil {
+		table = &models.Row{Columns: []string{"time"}}
+	}
+	return table, nil
+}
+
+// parquetColumnType returns the column type that holds every value of a
+// column. Mixed numbers are written as doubles and any other mix of types as
+// strings.
+func parquetColumnType(name string, values [][]interface{}, i int) parquet.Type {
+	var typ parquet.Type
+	var seen bool
+	for _, row := range values {
+		var t parquet.Type
+		switch row[i].(type) {
+		case time.Time:
+			t = parquet.Timestamp
+		case bool:
+			t = parquet.Boolean
+		case int64:
+			t = parquet.Int64
+		case uint64:
+			t = parquet.Uint64
+		case float64:
+			t = parquet.Double
+		case string:
+			t = parquet.String
+		default:
+			continue
+		}
+
+		if !seen {
+			typ, seen = t, true
+		} else if t != typ {
+			if isParquetNumber(t) && isParquetNumber(typ) {
+				typ = parquet.Double
+			} else {
+				return parquet.String
+			}
+		}
+	}
+	if !seen {
+		if name == "time" {
+			return parquet.Timestamp
+		}
+		return parquet.String
+	}
+	return typ
+}
+
+func isParquetNumber(t parquet.Type) bool {
+	return t == parquet.Int64 || t == parquet.Uint64 || t == parquet.Double
+}
+
+// parquetValue converts v to a value of a column of type typ.
+func parquetValue(typ parquet.Type, v interface{}) interface{} {
+	switch v := v.(type) {
+	case nil, *float64, *int64, *string, *bool:
+		return nil
+	case int64:
+		if typ == parquet.Double {
+			return float64(v)
+		}
+	case uint64:
+		if typ == parquet.Double {
+			return float64(v)
+		}
+	case time.Time:
+		if typ == parquet.String {
+			return v.UTC().Format(time.RFC3339Nano)
+		}
+	}
+	if typ == parquet.String {
+		if _, ok := v.(string); !ok {
+			return fmt.Sprint(v)
+		}
+	}
+	return v
+}
+
 type msgpackFormatter struct {
 	io.Writer
 }
@@ -205,19 +369,16 @@ func (f *msgpackFormatter) WriteResponse(resp Response) (n int, err error) {
 	enc := msgp.NewWriter(f.Writer)
 	defer enc.Flush()
 
-	enc.WriteMapHeader(1)
 	if resp.Err != nil {
-		enc.WriteString("error")
-		enc.WriteString(err.Error())
+		writeMsgpackError(enc, resp.Err)
 		return 0, nil
 	} else {
+		enc.WriteMapHeader(1)
 		enc.WriteString("results")
 		enc.WriteArrayHeader(uint32(len(resp.Results)))
 		for _, result := range resp.Results {
 			if result.Err != nil {
-				enc.WriteMapHeader(1)
-				enc.WriteString("error")
-				enc.WriteString(result.Err.Error())
+				writeMsgpackError(enc, result.Err)
 				continue
 			}
 
@@ -228,6 +389,9 @@ func (f *msgpackFormatter) WriteResponse(resp Response) (n int, err error) {
 			if result.Partial {
 				sz++
 			}
+			if result.Stats != nil {
+				sz++
+			}
 			enc.WriteMapHeader(uint32(sz))
 			enc.WriteString("statement_id")
 			enc.WriteInt(result.StatementID)
@@ -290,7 +454,42 @@ func (f *msgpackFormatter) WriteResponse(resp Response) (n int, err error) {
 				enc.WriteString("partial")
 				enc.WriteBool(true)
 			}
+			if stats := result.Stats; stats != nil {
+				sz := 4
+				if stats.Truncated {
+					sz++
+				}
+				enc.WriteString("stats")
+				enc.WriteMapHeader(uint32(sz))
+				enc.WriteString("series")
+				enc.WriteInt(stats.SeriesN)
+				enc.WriteString("values")
+				enc.WriteInt(stats.ValuesN)
+				enc.WriteString("scanned_series")
+				enc.WriteInt(stats.ScannedSeriesN)
+				enc.WriteString("scanned_points")
+				enc.WriteInt(stats.ScannedPointN)
+				if stats.Truncated {
+					enc.WriteString("truncated")
+					enc.WriteBool(true)
+				}
+			}
 		}
 	}
 	return 0, nil
 }
+
+// writeMsgpackError encodes err as a map with the error message and, when
+// known, its error code.
+func writeMsgpackError(enc *msgp.Writer, err error) {
+	code := influxdb.ErrorCodeOf(err)
+	if code == "" {
+		enc.WriteMapHeader(1)
+	} else {
+		enc.WriteMapHeader(2)
+		enc.WriteString("code")
+		enc.WriteString(string(code))
+	}
+	enc.WriteString("error")
+	enc.WriteString(err.Error())
+}
@@ -68,6 +68,95 @@ cpu,"host=server01,region=uswest",70,9223372036854775808
 	}
 }
 
+// Ensure the format parameter takes precedence over the Accept header.
+func TestResponseWriter_FormatParam(t *testing.T) {
+	header := make(http.Header)
+	header.Set("Accept", "application/json")
+	r := &http.Request{
+		Header: header,
+		URL:    &url.URL{RawQuery: "format=csv"},
+	}
+	w := httptest.NewRecorder()
+
+	writer := httpd.NewResponseWriter(w, r)
+	writer.WriteResponse(httpd.Response{
+		Results: []*query.Result{
+			{
+				StatementID: 0,
+				Series: []*models.Row{
+					{
+						Name:    "cpu",
+						Columns: []string{"time", "value"},
+						Values: [][]interface{}{
+							{time.Unix(0, 10), float64(2.5)},
+						},
+					},
+				},
+			},
+		},
+	})
+
+	if got, want := w.Header().Get("Content-Type"), "text/csv"; got != want {
+		t.Errorf("unexpected content type: got=%s want=%s", got, want)
+	}
+	if got, want := w.Body.String(), "name,tags,time,value\ncpu,,10,2.5\n"; got != want {
+		t.Errorf("unexpected output:\n\ngot=%v\nwant=%s", got, want)
+	}
+}
+
+func TestResponseWriter_Parquet(t *testing.T) {
+	header := make(http.Header)
+	header.Set("Accept", "application/vnd.apache.parquet")
+	r := &http.Request{
+		Header: header,
+		URL:    &url.URL{},
+	}
+	w := httptest.NewRecorder()
+
+	writer := httpd.NewResponseWriter(w, r)
+	writer.WriteResponse(httpd.Response{
+		Results: []*query.Result{
+			{
+				StatementID: 0,
+				Series: []*models.Row{
+					{
+						Name:    "cpu",
+						Tags:    map[string]string{"host": "server01"},
+						Columns: []string{"time", "value"},
+						Values: [][]interface{}{
+							{time.Unix(0, 10), float64(2.5)},
+							{time.Unix(0, 20), int64(5)},
+						},
+					},
+					{
+						Name:    "cpu",
+						Tags:    map[string]string{"host": "server02"},
+						Columns: []string{"time", "value"},
+						Values: [][]interface{}{
+							{time.Unix(0, 30), nil},
+						},
+					},
+				},
+			},
+		},
+	})
+
+	if got := w.Header().Get("Content-Type"); got != "application/vnd.apache.parquet" {
+		t.Fatalf("unexpected content type: %s", got)
+	}
+	body := w.Body.Bytes()
+	if !bytes.HasPrefix(body, []byte("PAR1")) || !bytes.HasSuffix(body, []byte("PAR1")) {
+		t.Fatalf("unexpected output: %q", body)
+	}
+
+	// The series are flattened into a time, host and value column.
+	for _, col := range []string{"time", "host", "value"} {
+		if !bytes.Contains(body, []byte(col)) {
+			t.Fatalf("missing %q in output", col)
+		}
+	}
+}
+
 func TestResponseWriter_MessagePack(t *testing.T) {
 	header := make(http.Header)
 	header.Set("Accept", "application/x-msgpack")
@@ -14,6 +14,8 @@ import (
 	"time"
 
 	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/pkg/bindaddr"
+	"github.com/influxdata/influxdb/pkg/ipfilter"
 	"github.com/uber-go/zap"
 )
 
@@ -21,6 +23,7 @@ import (
 const (
 	statRequest                      = "req"                  // Number of HTTP requests served.
 	statQueryRequest                 = "queryReq"             // Number of query requests served.
+	statQueryRequestCoalesced        = "queryReqCoalesced"    // Number of query requests served by sharing an identical query.
 	statWriteRequest                 = "writeReq"             // Number of write requests serverd.
 	statPingRequest                  = "pingReq"              // Number of ping requests served.
 	statStatusRequest                = "statusReq"            // Number of status requests served.
@@ -29,6 +32,7 @@ const (
 	statPointsWrittenOK              = "pointsWrittenOK"      // Number of points written OK.
 	statPointsWrittenDropped         = "pointsWrittenDropped" // Number of points dropped by the storage engine.
 	statPointsWrittenFail            = "pointsWrittenFail"    // Number of points that failed to be written.
+	statNonCanonicalKeys             = "nonCanonicalKeys"     // Number of points written with a non-canonical series key.
 	statAuthFail                     = "authFail"             // Number of authentication failures.
 	statRequestDuration              = "reqDurationNs"        // Number of (wall-time) nanoseconds spent inside requests.
 	statQueryRequestDuration         = "queryReqDurationNs"   // Number of (wall-time) nanoseconds spent inside query requests.
@@ -38,6 +42,9 @@ const (
 	statClientError                  = "clientError"          // Number of HTTP responses due to client error.
 	statServerError                  = "serverError"          // Number of HTTP responses due to server error.
 	statRecoveredPanics              = "recoveredPanics"      // Number of panics recovered by HTTP handler.
+	statJSONWriteRequest             = "jsonWriteReq"         // Number of write requests in the deprecated JSON format.
+	statJSONPointsWritten            = "jsonPointsWritten"    // Number of points received in the deprecated JSON format.
+	statQueryStatsDropped            = "queryStatsDropped"    // Number of query statistics dropped because the buffer was full.
 
 	// Prometheus stats
 	statPromWriteRequest = "promWriteReq" // Number of write requests to the promtheus endpoint
@@ -54,24 +61,49 @@ type Service struct {
 	limit int
 	err   chan error
 
+	// Networks that clients may connect from.
+	allowedNetworks []string
+	deniedNetworks  []string
+
+	// Settings of the HTTP server.
+	readTimeout       time.Duration
+	readHeaderTimeout time.Duration
+	writeTimeout      time.Duration
+	idleTimeout       time.Duration
+	maxHeaderBytes    int
+
 	unixSocket         bool
 	bindSocket         string
 	unixSocketListener net.Listener
 
 	Handler *Handler
 
+	// Listener is served instead of listening on the bind address if it is
+	// set before the service is opened, such as a socket passed by systemd.
+	Listener net.Listener
+
 	Logger zap.Logger
 }
 
 // NewService returns a new instance of Service.
 func NewService(c Config) *Service {
 	s := &Service{
-		addr:       c.BindAddress,
-		https:      c.HTTPSEnabled,
-		cert:       c.HTTPSCertificate,
-		key:        c.HTTPSPrivateKey,
-		limit:      c.MaxConnectionLimit,
-		err:        make(chan error),
+		addr:  c.BindAddress,
+		https: c.HTTPSEnabled,
+		cert:  c.HTTPSCertificate,
+		key:   c.HTTPSPrivateKey,
+		limit: c.MaxConnectionLimit,
+		err:   make(chan error),
+
+		allowedNetworks: c.AllowedNetworks,
+		deniedNetworks:  c.DeniedNetworks,
+
+		readTimeout:       time.Duration(c.ReadTimeout),
+		readHeaderTimeout: time.Duration(c.ReadHeaderTimeout),
+		writeTimeout:      time.Duration(c.WriteTimeout),
+		idleTimeout:       time.Duration(c.IdleTimeout),
+		maxHeaderBytes:    c.MaxHeaderBytes,
+
 		unixSocket: c.UnixSocketEnabled,
 		bindSocket: c.BindSocket,
 		Handler:    NewHandler(c),
@@ -89,31 +121,34 @@ func (s *Service) Open() error {
 	s.Logger.Info("Starting HTTP service")
 	s.Logger.Info(fmt.Sprint("Authentication enabled:", s.Handler.Config.AuthEnabled))
 
-	// Open listener.
+	filter, err := ipfilter.New(s.allowedNetworks, s.deniedNetworks)
+	if err != nil {
+		return err
+	}
+
+	// Open listener, unless one was passed in.
+	listener := s.Listener
+	if listener == nil {
+		if listener, err = bindaddr.Listen("tcp", s.addr); err != nil {
+			return err
+		}
+	}
+
 	if s.https {
 		cert, err := tls.LoadX509KeyPair(s.cert, s.key)
 		if err != nil {
+			listener.Close()
 			return err
 		}
-
-		listener, err := tls.Listen("tcp", s.addr, &tls.Config{
+		listener = tls.NewListener(listener, &tls.Config{
 			Certificates: []tls.Certificate{cert},
 		})
-		if err != nil {
-			return err
-		}
 
 		s.Logger.Info(fmt.Sprint("Listening on HTTPS:", listener.Addr().String()))
-		s.ln = listener
 	} else {
-		listener, err := net.Listen("tcp", s.addr)
-		if err != nil {
-			return err
-		}
-
 		s.Logger.Info(fmt.Sprint("Listening on HTTP:", listener.Addr().String()))
-		s.ln = listener
 	}
+	s.ln = listener
 
 	// Open unix socket listener.
 	if s.unixSocket {
@@ -138,6 +173,9 @@ func (s *Service) Open() error {
 		go s.serveUnixSocket()
 	}
 
+	// Drop the connections of clients outside of the allowed networks.
+	s.ln = filter.Listener(s.ln)
+
 	// Enforce a connection limit if one has been given.
 	if s.limit > 0 {
 		s.ln = LimitListener(s.ln, s.limit)
@@ -163,6 +201,12 @@ func (s *Service) Open() error {
 
 // Close closes the underlying listener.
 func (s *Service) Close() error {
+	// Abort the queries of paged results that are still waiting to be fetched.
+	s.Handler.cursors.closeAll()
+
+	// Stop writing query statistics to the monitor database.
+	s.Handler.queryStats.close()
+
 	if s.ln != nil {
 		if err := s.ln.Close(); err != nil {
 			return err
@@ -216,9 +260,18 @@ func (s *Service) serveUnixSocket() {
 
 // serve serves the handler from the listener.
 func (s *Service) serve(listener net.Listener) {
+	srv := &http.Server{
+		Handler:           s.Handler,
+		ReadTimeout:       s.readTimeout,
+		ReadHeaderTimeout: s.readHeaderTimeout,
+		WriteTimeout:      s.writeTimeout,
+		IdleTimeout:       s.idleTimeout,
+		MaxHeaderBytes:    s.maxHeaderBytes,
+	}
+
 	// The listener was closed so exit
 	// See https://github.com/golang/go/issues/4373
-	err := http.Serve(listener, s.Handler)
+	err := srv.Serve(listener)
 	if err != nil && !strings.Contains(err.Error(), "closed") {
 		s.err <- fmt.Errorf("listener failed: addr=%s, err=%s", s.Addr(), err)
 	}
@@ -0,0 +1,90 @@
+package httpd
+
+import (
+	"sort"
+
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/query"
+)
+
+// flattenResult merges the series of a result into a single table for
+// clients that cannot consume nested series, such as generic SQL drivers and
+// CSV pipelines. The table has a time column when the series have one,
+// followed by a column for each tag key and then the remaining columns of
+// the series. A name column is added first when the series come from more
+// than one measurement. Cells a series has no value for are null.
+func flattenResult(r *query.Result) {
+	if len(r.Series) <= 1 && (len(r.Series) == 0 || len(r.Series[0].Tags) == 0) {
+		return
+	}
+
+	var (
+		hasTime, partial bool
+		columns          []string
+		tagKeys          []string
+		seenColumns      = make(map[string]struct{})
+		seenTags         = make(map[string]struct{})
+		names            = make(map[string]struct{})
+	)
+	for _, row := range r.Series {
+		names[row.Name] = struct{}{}
+		partial = partial || row.Partial
+		for _, col := range row.Columns {
+			if col == "time" {
+				hasTime = true
+			} else if _, ok := seenColumns[col]; !ok {
+				seenColumns[col] = struct{}{}
+				columns = append(columns, col)
+			}
+		}
+		for k := range row.Tags {
+			if _, ok := seenTags[k]; !ok {
+				seenTags[k] = struct{}{}
+				tagKeys = append(tagKeys, k)
+			}
+		}
+	}
+	sort.Strings(tagKeys)
+
+	var header []string
+	if len(names) > 1 {
+		header = append(header, "name")
+	}
+	if hasTime {
+		header = append(header, "time")
+	}
+	tagOffset := len(header)
+	header = append(header, tagKeys...)
+	header = append(header, columns...)
+
+	index := make(map[string]int, len(columns)+1)
+	for i, col := range header[tagOffset+len(tagKeys):] {
+		index[col] = tagOffset + len(tagKeys) + i
+	}
+	if hasTime {
+		index["time"] = tagOffset - 1
+	}
+
+	table := &models.Row{Columns: header, Partial: partial}
+	if len(names) == 1 {
+		table.Name = r.Series[0].Name
+	}
+	for _, row := range r.Series {
+		for _, values := range row.Values {
+			out := make([]interface{}, len(header))
+			if len(names) > 1 {
+				out[0] = row.Name
+			}
+			for i, k := range tagKeys {
+				if v, ok := row.Tags[k]; ok {
+					out[tagOffset+i] = v
+				}
+			}
+			for i, v := range values {
+				out[index[row.Columns[i]]] = v
+			}
+			table.Values = append(table.Values, out)
+		}
+	}
+	r.Series = models.Rows{table}
+}
@@ -0,0 +1,176 @@
+package httpd
+
+import (
+	"encoding/json"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/services/meta"
+	"github.com/influxdata/influxql"
+)
+
+// writeSession is a client that writes to a database, named by the
+// "session" parameter of its writes or by its address.
+type writeSession struct {
+	maxTime   int64
+	lastWrite time.Time
+}
+
+// databaseWatermark tracks the write sessions of a database.
+type databaseWatermark struct {
+	sessions  map[string]*writeSession
+	maxTime   int64
+	watermark int64
+}
+
+// watermarkTracker tracks how far the writes to each database are complete.
+// The watermark of a database is the oldest of the newest timestamps written
+// by each session that wrote within the timeout, or the newest timestamp
+// written at all once no session is active. A batch job can process a time
+// window once the watermark has passed its end. The watermark never moves
+// back, so the points of a session that starts with older timestamps are late.
+type watermarkTracker struct {
+	mu      sync.Mutex
+	dbs     map[string]*databaseWatermark
+	timeout time.Duration
+	now     func() time.Time
+}
+
+func newWatermarkTracker(timeout time.Duration) *watermarkTracker {
+	if timeout <= 0 {
+		timeout = DefaultWatermarkSessionTimeout
+	}
+	return &watermarkTracker{
+		dbs:     make(map[string]*databaseWatermark),
+		timeout: timeout,
+		now:     time.Now,
+	}
+}
+
+// observe records the points written to a database by a session.
+func (t *watermarkTracker) observe(database, session string, points []models.Point) {
+	if len(points) == 0 {
+		return
+	}
+
+	maxTime := int64(math.MinInt64)
+	for _, p := range points {
+		if ts := p.UnixNano(); ts > maxTime {
+			maxTime = ts
+		}
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	db := t.dbs[database]
+	if db == nil {
+		db = &databaseWatermark{
+			sessions:  make(map[string]*writeSession),
+			maxTime:   math.MinInt64,
+			watermark: math.MinInt64,
+		}
+		t.dbs[database] = db
+	}
+	if maxTime > db.maxTime {
+		db.maxTime = maxTime
+	}
+
+	s := db.sessions[session]
+	if s == nil {
+		s = &writeSession{maxTime: math.MinInt64}
+		db.sessions[session] = s
+	}
+	if maxTime > s.maxTime {
+		s.maxTime = maxTime
+	}
+	s.lastWrite = t.now()
+}
+
+// watermark returns the watermark of a database and the number of active
+// write sessions. It returns false if nothing was written to the database.
+func (t *watermarkTracker) watermark(database string) (int64, int, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	db := t.dbs[database]
+	if db == nil {
+		return 0, 0, false
+	}
+
+	// Forget the sessions that stopped writing.
+	now := t.now()
+	wm := int64(math.MaxInt64)
+	for name, s := range db.sessions {
+		if now.Sub(s.lastWrite) > t.timeout {
+			delete(db.sessions, name)
+			continue
+		}
+		if s.maxTime < wm {
+			wm = s.maxTime
+		}
+	}
+	if len(db.sessions) == 0 {
+		wm = db.maxTime
+	}
+
+	if wm > db.watermark {
+		db.watermark = wm
+	}
+	return db.watermark, len(db.sessions), true
+}
+
+// writeSessionName returns the session of a write request: the "session"
+// parameter if it is set, or the host of the client otherwise.
+func writeSessionName(r *http.Request) string {
+	if s := r.URL.Query().Get("session"); s != "" {
+		return s
+	}
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		return host
+	}
+	return r.RemoteAddr
+}
+
+// watermarkResponse is the response of the watermark endpoint.
+type watermarkResponse struct {
+	Database  string `json:"database"`
+	Watermark string `json:"watermark,omitempty"`
+	Sessions  int    `json:"sessions"`
+}
+
+// serveWatermark returns the time up to which the writes to a database are
+// complete. The watermark is omitted if nothing was written to the database
+// since the server started.
+func (h *Handler) serveWatermark(w http.ResponseWriter, r *http.Request, user meta.User) {
+	db := r.URL.Query().Get("db")
+	if db == "" {
+		h.httpError(w, "database is required", http.StatusBadRequest)
+		return
+	}
+	if h.Config.AuthEnabled && (user == nil || !user.AuthorizeDatabase(influxql.ReadPrivilege, db)) {
+		h.httpError(w, "user is not authorized to read from database "+strconv.Quote(db), http.StatusForbidden)
+		return
+	}
+
+	resp := watermarkResponse{Database: db}
+	if wm, sessions, ok := h.watermarks.watermark(db); ok {
+		resp.Watermark = time.Unix(0, wm).UTC().Format(time.RFC3339Nano)
+		resp.Sessions = sessions
+	}
+
+	b, err := json.Marshal(resp)
+	if err != nil {
+		h.httpError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Add("Content-Type", "application/json")
+	h.writeHeader(w, http.StatusOK)
+	w.Write(b)
+	w.Write([]byte("\n"))
+}
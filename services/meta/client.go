@@ -6,6 +6,9 @@ import (
 	"bytes"
 	crand "crypto/rand"
 	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
@@ -29,6 +32,9 @@ const (
 	// SaltBytes is the number of bytes used for salts.
 	SaltBytes = 32
 
+	// TokenBytes is the number of random bytes in an API token's secret.
+	TokenBytes = 32
+
 	metaFile = "meta.db"
 
 	// ShardGroupDeletedExpiration is the amount of time before a shard group info will be removed from cached
@@ -60,6 +66,7 @@ type Client struct {
 	path string
 
 	retentionAutoCreate bool
+	passwordHashCost    int
 }
 
 type authUser struct {
@@ -81,6 +88,7 @@ func NewClient(config *Config) *Client {
 		authCache:           make(map[string]authUser, 0),
 		path:                config.Dir,
 		retentionAutoCreate: config.RetentionAutoCreate,
+		passwordHashCost:    config.PasswordHashCost,
 	}
 }
 
@@ -356,6 +364,24 @@ func (c *Client) UpdateRetentionPolicy(database, name string, rpu *RetentionPoli
 	return nil
 }
 
+// SetQuota sets the quota of a database. A nil quota removes it.
+func (c *Client) SetQuota(database string, q *QuotaInfo) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	data := c.cacheData.Clone()
+
+	if err := data.SetQuota(database, q); err != nil {
+		return err
+	}
+
+	if err := c.commit(data); err != nil {
+		return err
+	}
+
+	return nil
+}
+
 // Users returns a slice of UserInfo representing the currently known users.
 func (c *Client) Users() []UserInfo {
 	c.mu.RLock()
@@ -383,9 +409,20 @@ func (c *Client) User(name string) (User, error) {
 	return nil, ErrUserNotFound
 }
 
-// bcryptCost is the cost associated with generating password with bcrypt.
-// This setting is lowered during testing to improve test suite performance.
-var bcryptCost = bcrypt.DefaultCost
+// bcryptCost overrides the cost of the bcrypt hashes of passwords when it is
+// set. It is set during testing to improve test suite performance.
+var bcryptCost int
+
+// hashPassword returns the bcrypt hash of password with the configured cost.
+func (c *Client) hashPassword(password string) ([]byte, error) {
+	cost := c.passwordHashCost
+	if bcryptCost != 0 {
+		cost = bcryptCost
+	} else if cost == 0 {
+		cost = bcrypt.DefaultCost
+	}
+	return bcrypt.GenerateFromPassword([]byte(password), cost)
+}
 
 // hashWithSalt returns a salted hash of password using salt.
 func (c *Client) hashWithSalt(salt []byte, password string) []byte {
@@ -421,7 +458,7 @@ func (c *Client) CreateUser(name, password string, admin bool) (User, error) {
 	}
 
 	// Hash the password before serializing it.
-	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcryptCost)
+	hash, err := c.hashPassword(password)
 	if err != nil {
 		return nil, err
 	}
@@ -447,7 +484,7 @@ func (c *Client) UpdateUser(name, password string) error {
 	data := c.cacheData.Clone()
 
 	// Hash the password before serializing it.
-	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcryptCost)
+	hash, err := c.hashPassword(password)
 	if err != nil {
 		return err
 	}
@@ -465,6 +502,24 @@ func (c *Client) UpdateUser(name, password string) error {
 	return nil
 }
 
+// SetUserSettings replaces the settings of the user with the given name.
+func (c *Client) SetUserSettings(name string, s UserSettings) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	data := c.cacheData.Clone()
+
+	if err := data.SetUserSettings(name, s); err != nil {
+		return err
+	}
+
+	if err := c.commit(data); err != nil {
+		return err
+	}
+
+	return nil
+}
+
 // DropUser removes the user with the given name.
 func (c *Client) DropUser(name string) error {
 	c.mu.Lock()
@@ -597,6 +652,81 @@ func (c *Client) UserCount() int {
 	return len(c.cacheData.Users)
 }
 
+// Tokens returns a list of all API tokens.
+func (c *Client) Tokens() []TokenInfo {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.cacheData.CloneTokens()
+}
+
+// CreateToken creates an API token limited to the given privileges and
+// returns its secret. Only a hash of the secret is stored, so the secret
+// cannot be retrieved again.
+func (c *Client) CreateToken(name string, privileges map[string]influxql.Privilege) (string, error) {
+	secret := make([]byte, TokenBytes)
+	if _, err := io.ReadFull(crand.Reader, secret); err != nil {
+		return "", err
+	}
+	token := base64.RawURLEncoding.EncodeToString(secret)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	data := c.cacheData.Clone()
+
+	if err := data.CreateToken(name, hashToken(token), privileges); err != nil {
+		return "", err
+	}
+
+	if err := c.commit(data); err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+// DropToken revokes the API token with the given name.
+func (c *Client) DropToken(name string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	data := c.cacheData.Clone()
+
+	if err := data.DropToken(name); err != nil {
+		return err
+	}
+
+	if err := c.commit(data); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// AuthenticateToken returns the API token with the given secret.
+func (c *Client) AuthenticateToken(token string) (User, error) {
+	hash := []byte(hashToken(token))
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	for i := range c.cacheData.Tokens {
+		ti := &c.cacheData.Tokens[i]
+		if subtle.ConstantTimeCompare([]byte(ti.Hash), hash) == 1 {
+			return ti, nil
+		}
+	}
+	return nil, ErrAuthenticate
+}
+
+// hashToken returns the hex-encoded SHA-256 hash of a token's secret. Token
+// secrets are random, so they do not need a salt or a slow hash.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
 // ShardIDs returns a list of all shard ids.
 func (c *Client) ShardIDs() []uint64 {
 	c.mu.RLock()
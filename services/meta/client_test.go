@@ -759,6 +759,62 @@ func TestMetaClient_UpdateUser(t *testing.T) {
 	}
 }
 
+func TestMetaClient_Tokens(t *testing.T) {
+	t.Parallel()
+
+	cfg := newConfig()
+	defer os.RemoveAll(cfg.Dir)
+
+	c := meta.NewClient(cfg)
+	if err := c.Open(); err != nil {
+		t.Fatal(err)
+	}
+
+	token, err := c.CreateToken("telegraf", map[string]influxql.Privilege{"db0": influxql.WritePrivilege})
+	if err != nil {
+		t.Fatal(err)
+	} else if token == "" {
+		t.Fatal("expected token secret")
+	}
+
+	// Creating a token with the same name should fail.
+	if _, err := c.CreateToken("telegraf", nil); err != meta.ErrTokenExists {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The token is persisted and only grants its own privileges.
+	c.Close()
+	c = meta.NewClient(cfg)
+	if err := c.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	u, err := c.AuthenticateToken(token)
+	if err != nil {
+		t.Fatal(err)
+	} else if u.ID() != "telegraf" || u.IsAdmin() {
+		t.Fatalf("unexpected token: %s admin=%v", u.ID(), u.IsAdmin())
+	} else if !u.AuthorizeDatabase(influxql.WritePrivilege, "db0") {
+		t.Fatal("expected write privilege on db0")
+	} else if u.AuthorizeDatabase(influxql.ReadPrivilege, "db0") || u.AuthorizeDatabase(influxql.WritePrivilege, "db1") {
+		t.Fatal("unexpected privilege")
+	}
+
+	if u, err := c.AuthenticateToken("bad"); u != nil || err != meta.ErrAuthenticate {
+		t.Fatalf("authentication should fail with %s", meta.ErrAuthenticate)
+	}
+
+	if err := c.DropToken("telegraf"); err != nil {
+		t.Fatal(err)
+	} else if err := c.DropToken("telegraf"); err != meta.ErrTokenNotFound {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if u, err := c.AuthenticateToken(token); u != nil || err != meta.ErrAuthenticate {
+		t.Fatalf("authentication should fail with %s after the token is dropped", meta.ErrAuthenticate)
+	}
+}
+
 func TestMetaClient_ContinuousQueries(t *testing.T) {
 	t.Parallel()
 
@@ -2,9 +2,11 @@ package meta
 
 import (
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/influxdata/influxdb/monitor/diagnostics"
+	"golang.org/x/crypto/bcrypt"
 )
 
 const (
@@ -13,6 +15,10 @@ const (
 
 	// DefaultLoggingEnabled determines if log messages are printed for the meta service.
 	DefaultLoggingEnabled = true
+
+	// DefaultPasswordHashCost is the default cost of the bcrypt hashes of
+	// user passwords.
+	DefaultPasswordHashCost = bcrypt.DefaultCost
 )
 
 // Config represents the meta configuration.
@@ -21,6 +27,10 @@ type Config struct {
 
 	RetentionAutoCreate bool `toml:"retention-autocreate"`
 	LoggingEnabled      bool `toml:"logging-enabled"`
+
+	// PasswordHashCost is the cost of the bcrypt hashes that user passwords
+	// are stored as. Each increment doubles the time to hash a password.
+	PasswordHashCost int `toml:"password-hash-cost"`
 }
 
 // NewConfig builds a new configuration with default values.
@@ -28,6 +38,7 @@ func NewConfig() *Config {
 	return &Config{
 		RetentionAutoCreate: true,
 		LoggingEnabled:      DefaultLoggingEnabled,
+		PasswordHashCost:    DefaultPasswordHashCost,
 	}
 }
 
@@ -36,12 +47,16 @@ func (c *Config) Validate() error {
 	if c.Dir == "" {
 		return errors.New("Meta.Dir must be specified")
 	}
+	if c.PasswordHashCost < bcrypt.MinCost || c.PasswordHashCost > bcrypt.MaxCost {
+		return fmt.Errorf("Meta.PasswordHashCost must be between %d and %d", bcrypt.MinCost, bcrypt.MaxCost)
+	}
 	return nil
 }
 
 // Diagnostics returns a diagnostics representation of a subset of the Config.
 func (c *Config) Diagnostics() (*diagnostics.Diagnostics, error) {
 	return diagnostics.RowFromMap(map[string]interface{}{
-		"dir": c.Dir,
+		"dir":                c.Dir,
+		"password-hash-cost": c.PasswordHashCost,
 	}), nil
 }
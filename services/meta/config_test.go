@@ -24,3 +24,20 @@ logging-enabled = false
 		t.Fatalf("unexpected logging enabled: %v", c.LoggingEnabled)
 	}
 }
+
+func TestConfig_Validate_PasswordHashCost(t *testing.T) {
+	c := meta.NewConfig()
+	c.Dir = "/tmp/foo"
+	if _, err := toml.Decode(`password-hash-cost = 12`, c); err != nil {
+		t.Fatal(err)
+	} else if c.PasswordHashCost != 12 {
+		t.Fatalf("unexpected password hash cost: %d", c.PasswordHashCost)
+	} else if err := c.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	c.PasswordHashCost = 32
+	if err := c.Validate(); err == nil {
+		t.Fatal("expected error for password hash cost above the maximum")
+	}
+}
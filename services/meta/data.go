@@ -41,6 +41,7 @@ type Data struct {
 	ClusterID uint64
 	Databases []DatabaseInfo
 	Users     []UserInfo
+	Tokens    []TokenInfo
 
 	// adminUserExists provides a constant time mechanism for determining
 	// if there is at least one admin user.
@@ -98,12 +99,33 @@ func (data *Data) DropDatabase(name string) error {
 			for i := range data.Users {
 				delete(data.Users[i].Privileges, name)
 			}
+			for i := range data.Tokens {
+				delete(data.Tokens[i].Privileges, name)
+			}
 			break
 		}
 	}
 	return nil
 }
 
+// SetQuota sets the quota of a database. A nil quota removes it.
+func (data *Data) SetQuota(database string, q *QuotaInfo) error {
+	di := data.Database(database)
+	if di == nil {
+		return influxdb.ErrDatabaseNotFound(database)
+	}
+
+	if q != nil {
+		if q.MaxBytes < 0 || q.MaxSeries < 0 || q.MaxWritesPerSecond < 0 {
+			return ErrQuotaNegative
+		}
+		other := *q
+		q = &other
+	}
+	di.Quota = q
+	return nil
+}
+
 // RetentionPolicy returns a retention policy for a database by name.
 func (data *Data) RetentionPolicy(database, name string) (*RetentionPolicyInfo, error) {
 	di := data.Database(database)
@@ -240,8 +262,11 @@ func (data *Data) UpdateRetentionPolicy(database, name string, rpu *RetentionPol
 		return ErrIncompatibleDurations
 	}
 
-	// Update fields.
+	// Update fields. A renamed default policy remains the default.
 	if rpu.Name != nil {
+		if di.DefaultRetentionPolicy == rpi.Name {
+			di.DefaultRetentionPolicy = *rpu.Name
+		}
 		rpi.Name = *rpu.Name
 	}
 	if rpu.Duration != nil {
@@ -546,6 +571,9 @@ func (data *Data) CreateUser(name, hash string, admin bool) error {
 		return ErrUsernameRequired
 	} else if data.User(name) != nil {
 		return ErrUserExists
+	} else if data.Token(name) != nil {
+		// Users and tokens share a namespace of request user IDs.
+		return ErrUserTokenConflict
 	}
 
 	// Append new user.
@@ -592,6 +620,20 @@ func (data *Data) UpdateUser(name, hash string) error {
 	return ErrUserNotFound
 }
 
+// SetUserSettings replaces the settings of an existing user.
+func (data *Data) SetUserSettings(name string, s UserSettings) error {
+	if models.ValidatePrecision(s.Precision) != nil {
+		return ErrUserSettingsPrecision
+	}
+	for i := range data.Users {
+		if data.Users[i].Name == name {
+			data.Users[i].Settings = s
+			return nil
+		}
+	}
+	return ErrUserNotFound
+}
+
 // CloneUsers returns a copy of the user infos.
 func (data *Data) CloneUsers() []UserInfo {
 	if len(data.Users) == 0 {
@@ -605,6 +647,62 @@ func (data *Data) CloneUsers() []UserInfo {
 	return users
 }
 
+// Token returns a token by name.
+func (data *Data) Token(name string) *TokenInfo {
+	for i := range data.Tokens {
+		if data.Tokens[i].Name == name {
+			return &data.Tokens[i]
+		}
+	}
+	return nil
+}
+
+// CreateToken creates a new API token. The hash identifies the token's
+// secret and the privileges are the only access the token is granted.
+func (data *Data) CreateToken(name, hash string, privileges map[string]influxql.Privilege) error {
+	if name == "" {
+		return ErrTokenNameRequired
+	} else if data.Token(name) != nil {
+		return ErrTokenExists
+	} else if data.User(name) != nil {
+		// Users and tokens share a namespace of request user IDs.
+		return ErrUserTokenConflict
+	}
+
+	ti := TokenInfo{Name: name, Hash: hash}
+	if len(privileges) > 0 {
+		ti.Privileges = make(map[string]influxql.Privilege, len(privileges))
+		for database, p := range privileges {
+			ti.Privileges[database] = p
+		}
+	}
+	data.Tokens = append(data.Tokens, ti)
+	return nil
+}
+
+// DropToken removes an existing token by name.
+func (data *Data) DropToken(name string) error {
+	for i := range data.Tokens {
+		if data.Tokens[i].Name == name {
+			data.Tokens = append(data.Tokens[:i], data.Tokens[i+1:]...)
+			return nil
+		}
+	}
+	return ErrTokenNotFound
+}
+
+// CloneTokens returns a copy of the token infos.
+func (data *Data) CloneTokens() []TokenInfo {
+	if len(data.Tokens) == 0 {
+		return nil
+	}
+	tokens := make([]TokenInfo, len(data.Tokens))
+	for i := range data.Tokens {
+		tokens[i] = data.Tokens[i].clone()
+	}
+	return tokens
+}
+
 // SetPrivilege sets a privilege for a user on a database.
 func (data *Data) SetPrivilege(name, database string, p influxql.Privilege) error {
 	ui := data.user(name)
@@ -676,6 +774,7 @@ func (data *Data) Clone() *Data {
 
 	other.Databases = data.CloneDatabases()
 	other.Users = data.CloneUsers()
+	other.Tokens = data.CloneTokens()
 
 	return &other
 }
@@ -704,6 +803,11 @@ func (data *Data) marshal() *internal.Data {
 		pb.Users[i] = data.Users[i].marshal()
 	}
 
+	pb.Tokens = make([]*internal.TokenInfo, len(data.Tokens))
+	for i := range data.Tokens {
+		pb.Tokens[i] = data.Tokens[i].marshal()
+	}
+
 	return pb
 }
 
@@ -726,6 +830,14 @@ func (data *Data) unmarshal(pb *internal.Data) {
 		data.Users[i].unmarshal(x)
 	}
 
+	data.Tokens = nil
+	if len(pb.GetTokens()) > 0 {
+		data.Tokens = make([]TokenInfo, len(pb.GetTokens()))
+		for i, x := range pb.GetTokens() {
+			data.Tokens[i].unmarshal(x)
+		}
+	}
+
 	// Exhaustively determine if there is an admin user. The marshalled cache
 	// value may not be correct.
 	data.adminUserExists = data.hasAdminUser()
@@ -782,6 +894,7 @@ type DatabaseInfo struct {
 	DefaultRetentionPolicy string
 	RetentionPolicies      []RetentionPolicyInfo
 	ContinuousQueries      []ContinuousQueryInfo
+	Quota                  *QuotaInfo
 }
 
 // RetentionPolicy returns a retention policy by name.
@@ -845,6 +958,11 @@ func (di DatabaseInfo) clone() DatabaseInfo {
 		}
 	}
 
+	if di.Quota != nil {
+		q := *di.Quota
+		other.Quota = &q
+	}
+
 	return other
 }
 
@@ -863,6 +981,10 @@ func (di DatabaseInfo) marshal() *internal.DatabaseInfo {
 	for i := range di.ContinuousQueries {
 		pb.ContinuousQueries[i] = di.ContinuousQueries[i].marshal()
 	}
+
+	if di.Quota != nil {
+		pb.Quota = di.Quota.marshal()
+	}
 	return pb
 }
 
@@ -884,6 +1006,35 @@ func (di *DatabaseInfo) unmarshal(pb *internal.DatabaseInfo) {
 			di.ContinuousQueries[i].unmarshal(x)
 		}
 	}
+
+	if pb.Quota != nil {
+		di.Quota = &QuotaInfo{}
+		di.Quota.unmarshal(pb.GetQuota())
+	}
+}
+
+// QuotaInfo represents the limits on the resources of a database. A zero
+// limit is unlimited.
+type QuotaInfo struct {
+	MaxBytes           int64
+	MaxSeries          int64
+	MaxWritesPerSecond int64
+}
+
+// marshal serializes to a protobuf representation.
+func (qi QuotaInfo) marshal() *internal.QuotaInfo {
+	return &internal.QuotaInfo{
+		MaxBytes:           proto.Int64(qi.MaxBytes),
+		MaxSeries:          proto.Int64(qi.MaxSeries),
+		MaxWritesPerSecond: proto.Int64(qi.MaxWritesPerSecond),
+	}
+}
+
+// unmarshal deserializes from a protobuf representation.
+func (qi *QuotaInfo) unmarshal(pb *internal.QuotaInfo) {
+	qi.MaxBytes = pb.GetMaxBytes()
+	qi.MaxSeries = pb.GetMaxSeries()
+	qi.MaxWritesPerSecond = pb.GetMaxWritesPerSecond()
 }
 
 // RetentionPolicySpec represents the specification for a new retention policy.
@@ -1448,6 +1599,23 @@ type UserInfo struct {
 
 	// Map of database name to granted privilege.
 	Privileges map[string]influxql.Privilege
+
+	// Defaults for the requests of the user.
+	Settings UserSettings
+}
+
+// UserSettings are the defaults applied to the requests of a user that do
+// not set them themselves.
+type UserSettings struct {
+	// Database is used by queries and writes without a database.
+	Database string
+
+	// RetentionPolicy is used by writes without a retention policy.
+	RetentionPolicy string
+
+	// Precision is used by writes without a precision and as the epoch of
+	// the timestamps returned by queries.
+	Precision string
 }
 
 type User interface {
@@ -1505,6 +1673,12 @@ func (ui UserInfo) marshal() *internal.UserInfo {
 		Admin: proto.Bool(ui.Admin),
 	}
 
+	if s := ui.Settings; s != (UserSettings{}) {
+		pb.DefaultDatabase = proto.String(s.Database)
+		pb.DefaultRetentionPolicy = proto.String(s.RetentionPolicy)
+		pb.Precision = proto.String(s.Precision)
+	}
+
 	for database, privilege := range ui.Privileges {
 		pb.Privileges = append(pb.Privileges, &internal.UserPrivilege{
 			Database:  proto.String(database),
@@ -1520,6 +1694,11 @@ func (ui *UserInfo) unmarshal(pb *internal.UserInfo) {
 	ui.Name = pb.GetName()
 	ui.Hash = pb.GetHash()
 	ui.Admin = pb.GetAdmin()
+	ui.Settings = UserSettings{
+		Database:        pb.GetDefaultDatabase(),
+		RetentionPolicy: pb.GetDefaultRetentionPolicy(),
+		Precision:       pb.GetPrecision(),
+	}
 
 	ui.Privileges = make(map[string]influxql.Privilege)
 	for _, p := range pb.GetPrivileges() {
@@ -1527,6 +1706,91 @@ func (ui *UserInfo) unmarshal(pb *internal.UserInfo) {
 	}
 }
 
+// TokenInfo represents metadata about an API token. A token authenticates
+// requests without a password and is limited to the privileges it carries;
+// it never has admin privileges.
+type TokenInfo struct {
+	// Token's name.
+	Name string
+
+	// Hex-encoded SHA-256 hash of the token's secret.
+	Hash string
+
+	// Map of database name to granted privilege.
+	Privileges map[string]influxql.Privilege
+}
+
+// ID returns the name of the token.
+func (ti *TokenInfo) ID() string {
+	return ti.Name
+}
+
+// IsAdmin returns false. Tokens cannot hold admin privileges.
+func (ti *TokenInfo) IsAdmin() bool {
+	return false
+}
+
+// AuthorizeDatabase returns true if the token is authorized for the given privilege on the given database.
+func (ti *TokenInfo) AuthorizeDatabase(privilege influxql.Privilege, database string) bool {
+	if privilege == influxql.NoPrivileges {
+		return true
+	}
+	p, ok := ti.Privileges[database]
+	return ok && (p == privilege || p == influxql.AllPrivileges)
+}
+
+// AuthorizeSeriesRead is used to limit access per-series (enterprise only)
+func (ti *TokenInfo) AuthorizeSeriesRead(database string, measurement []byte, tags models.Tags) bool {
+	return true
+}
+
+// AuthorizeSeriesWrite is used to limit access per-series (enterprise only)
+func (ti *TokenInfo) AuthorizeSeriesWrite(database string, measurement []byte, tags models.Tags) bool {
+	return true
+}
+
+// clone returns a deep copy of ti.
+func (ti TokenInfo) clone() TokenInfo {
+	other := ti
+
+	if ti.Privileges != nil {
+		other.Privileges = make(map[string]influxql.Privilege)
+		for k, v := range ti.Privileges {
+			other.Privileges[k] = v
+		}
+	}
+
+	return other
+}
+
+// marshal serializes to a protobuf representation.
+func (ti TokenInfo) marshal() *internal.TokenInfo {
+	pb := &internal.TokenInfo{
+		Name: proto.String(ti.Name),
+		Hash: proto.String(ti.Hash),
+	}
+
+	for database, privilege := range ti.Privileges {
+		pb.Privileges = append(pb.Privileges, &internal.UserPrivilege{
+			Database:  proto.String(database),
+			Privilege: proto.Int32(int32(privilege)),
+		})
+	}
+
+	return pb
+}
+
+// unmarshal deserializes from a protobuf representation.
+func (ti *TokenInfo) unmarshal(pb *internal.TokenInfo) {
+	ti.Name = pb.GetName()
+	ti.Hash = pb.GetHash()
+
+	ti.Privileges = make(map[string]influxql.Privilege)
+	for _, p := range pb.GetPrivileges() {
+		ti.Privileges[p.GetDatabase()] = influxql.Privilege(p.GetPrivilege())
+	}
+}
+
 // Lease represents a lease held on a resource.
 type Lease struct {
 	Name       string    `json:"name"`
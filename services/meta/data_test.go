@@ -111,6 +111,61 @@ func Test_Data_CreateRetentionPolicy(t *testing.T) {
 	}
 }
 
+func Test_Data_UpdateRetentionPolicy(t *testing.T) {
+	data := meta.Data{}
+	if err := data.CreateDatabase("foo"); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"rp0", "rp1"} {
+		if err := data.CreateRetentionPolicy("foo", &meta.RetentionPolicyInfo{
+			Name:     name,
+			ReplicaN: 1,
+			Duration: 24 * time.Hour,
+		}, name == "rp0"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Make rp1 the default and give it an infinite duration.
+	inf := time.Duration(0)
+	if err := data.UpdateRetentionPolicy("foo", "rp1", &meta.RetentionPolicyUpdate{Duration: &inf}, true); err != nil {
+		t.Fatal(err)
+	} else if di := data.Database("foo"); di.DefaultRetentionPolicy != "rp1" {
+		t.Fatalf("unexpected default retention policy: %s", di.DefaultRetentionPolicy)
+	} else if rpi := di.RetentionPolicy("rp1"); rpi.Duration != 0 {
+		t.Fatalf("unexpected duration: %s", rpi.Duration)
+	}
+
+	// Renaming the default policy keeps it as the default.
+	name := "forever"
+	if err := data.UpdateRetentionPolicy("foo", "rp1", &meta.RetentionPolicyUpdate{Name: &name}, false); err != nil {
+		t.Fatal(err)
+	} else if di := data.Database("foo"); di.DefaultRetentionPolicy != "forever" {
+		t.Fatalf("unexpected default retention policy: %s", di.DefaultRetentionPolicy)
+	}
+}
+
+// Ensure shard groups of a retention policy with an infinite duration never
+// expire, and deleted shard groups are not reported again.
+func TestRetentionPolicyInfo_ExpiredShardGroups(t *testing.T) {
+	now := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	groups := []meta.ShardGroupInfo{
+		{ID: 1, StartTime: now.Add(-49 * time.Hour), EndTime: now.Add(-48 * time.Hour)},
+		{ID: 2, StartTime: now.Add(-49 * time.Hour), EndTime: now.Add(-48 * time.Hour), DeletedAt: now.Add(-time.Hour)},
+		{ID: 3, StartTime: now.Add(-time.Hour), EndTime: now},
+	}
+
+	rpi := meta.RetentionPolicyInfo{Name: "rp0", Duration: 24 * time.Hour, ShardGroups: groups}
+	if expired := rpi.ExpiredShardGroups(now); len(expired) != 1 || expired[0].ID != 1 {
+		t.Fatalf("unexpected expired shard groups: %v", expired)
+	}
+
+	rpi = meta.RetentionPolicyInfo{Name: "forever", ShardGroups: groups}
+	if expired := rpi.ExpiredShardGroups(now); len(expired) != 0 {
+		t.Fatalf("unexpected expired shard groups: %v", expired)
+	}
+}
+
 func TestData_AdminUserExists(t *testing.T) {
 	data := meta.Data{}
 
@@ -215,6 +270,105 @@ func TestData_SetPrivilege(t *testing.T) {
 	}
 }
 
+func TestData_DropDatabase_TokenPrivileges(t *testing.T) {
+	data := meta.Data{}
+	if err := data.CreateDatabase("db0"); err != nil {
+		t.Fatal(err)
+	}
+	if err := data.CreateToken("token1", "hash", map[string]influxql.Privilege{"db0": influxql.ReadPrivilege, "db1": influxql.WritePrivilege}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := data.DropDatabase("db0"); err != nil {
+		t.Fatal(err)
+	}
+	if got := data.Token("token1").Privileges; len(got) != 1 || got["db1"] != influxql.WritePrivilege {
+		t.Fatalf("unexpected privileges: %v", got)
+	}
+}
+
+// Ensure tokens and users cannot share a name.
+func TestData_CreateToken_UserConflict(t *testing.T) {
+	data := meta.Data{}
+	if err := data.CreateUser("admin", "hash", true); err != nil {
+		t.Fatal(err)
+	} else if err := data.CreateToken("token1", "hash", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := data.CreateToken("admin", "hash", map[string]influxql.Privilege{"db0": influxql.WritePrivilege}); err != meta.ErrUserTokenConflict {
+		t.Fatalf("unexpected error: %v", err)
+	} else if err := data.CreateUser("token1", "hash", false); err != meta.ErrUserTokenConflict {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestData_SetUserSettings(t *testing.T) {
+	data := meta.Data{}
+	if err := data.CreateUser("user1", "abcd", false); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := data.SetUserSettings("user2", meta.UserSettings{}); err != meta.ErrUserNotFound {
+		t.Fatalf("unexpected error: %v", err)
+	} else if err := data.SetUserSettings("user1", meta.UserSettings{Precision: "x"}); err != meta.ErrUserSettingsPrecision {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	s := meta.UserSettings{Database: "db0", RetentionPolicy: "rp0", Precision: "ms"}
+	if err := data.SetUserSettings("user1", s); err != nil {
+		t.Fatal(err)
+	}
+
+	// Settings are persisted with the user.
+	b, err := data.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var other meta.Data
+	if err := other.UnmarshalBinary(b); err != nil {
+		t.Fatal(err)
+	} else if got := other.User("user1").(*meta.UserInfo).Settings; got != s {
+		t.Fatalf("unexpected settings: %+v", got)
+	}
+}
+
+func TestData_SetQuota(t *testing.T) {
+	data := meta.Data{}
+	if err := data.CreateDatabase("db0"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := data.SetQuota("db1", &meta.QuotaInfo{MaxBytes: 1}); influxdb.ErrorCodeOf(err) != influxdb.ErrorCodeDatabaseNotFound {
+		t.Fatalf("unexpected error: %v", err)
+	} else if err := data.SetQuota("db0", &meta.QuotaInfo{MaxSeries: -1}); err != meta.ErrQuotaNegative {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	q := &meta.QuotaInfo{MaxBytes: 1 << 30, MaxSeries: 1000, MaxWritesPerSecond: 500}
+	if err := data.SetQuota("db0", q); err != nil {
+		t.Fatal(err)
+	}
+
+	// Quotas are persisted with the database.
+	b, err := data.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var other meta.Data
+	if err := other.UnmarshalBinary(b); err != nil {
+		t.Fatal(err)
+	} else if got := other.Database("db0").Quota; !reflect.DeepEqual(got, q) {
+		t.Fatalf("unexpected quota: %+v", got)
+	}
+
+	if err := data.SetQuota("db0", nil); err != nil {
+		t.Fatal(err)
+	} else if got := data.Database("db0").Quota; got != nil {
+		t.Fatalf("unexpected quota: %+v", got)
+	}
+}
+
 func TestUserInfo_AuthorizeDatabase(t *testing.T) {
 	emptyUser := &meta.UserInfo{}
 	if !emptyUser.AuthorizeDatabase(influxql.NoPrivileges, "anydb") {
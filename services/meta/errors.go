@@ -25,6 +25,9 @@ var (
 
 	// ErrInvalidName is returned when attempting to create a database or retention policy with an invalid name
 	ErrInvalidName = errors.New("invalid name")
+
+	// ErrQuotaNegative is returned when setting a quota with a negative limit.
+	ErrQuotaNegative = errors.New("quota limits must not be negative")
 )
 
 var (
@@ -112,4 +115,23 @@ var (
 
 	// ErrAuthenticate is returned when authentication fails.
 	ErrAuthenticate = errors.New("authentication failed")
+
+	// ErrUserSettingsPrecision is returned when setting an unknown default
+	// precision for a user.
+	ErrUserSettingsPrecision = errors.New("invalid precision (use n, u, ms, s, m or h)")
+)
+
+var (
+	// ErrTokenExists is returned when creating an already existing token.
+	ErrTokenExists = errors.New("token already exists")
+
+	// ErrTokenNotFound is returned when dropping a token that doesn't exist.
+	ErrTokenNotFound = errors.New("token not found")
+
+	// ErrTokenNameRequired is returned when creating a token without a name.
+	ErrTokenNameRequired = errors.New("token name required")
+
+	// ErrUserTokenConflict is returned when creating a token or a user with
+	// the name of an existing user or token.
+	ErrUserTokenConflict = errors.New("a user or token with this name already exists")
 )
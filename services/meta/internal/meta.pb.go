@@ -12,6 +12,7 @@ It has these top-level messages:
 	Data
 	NodeInfo
 	DatabaseInfo
+	QuotaInfo
 	RetentionPolicySpec
 	RetentionPolicyInfo
 	ShardGroupInfo
@@ -21,6 +22,7 @@ It has these top-level messages:
 	ContinuousQueryInfo
 	UserInfo
 	UserPrivilege
+	TokenInfo
 	Command
 	CreateNodeCommand
 	DeleteNodeCommand
@@ -183,7 +185,7 @@ func (x *Command_Type) UnmarshalJSON(data []byte) error {
 	*x = Command_Type(value)
 	return nil
 }
-func (Command_Type) EnumDescriptor() ([]byte, []int) { return fileDescriptorMeta, []int{12, 0} }
+func (Command_Type) EnumDescriptor() ([]byte, []int) { return fileDescriptorMeta, []int{14, 0} }
 
 type Data struct {
 	Term            *uint64         `protobuf:"varint,1,req,name=Term" json:"Term,omitempty"`
@@ -196,9 +198,10 @@ type Data struct {
 	MaxShardGroupID *uint64         `protobuf:"varint,8,req,name=MaxShardGroupID" json:"MaxShardGroupID,omitempty"`
 	MaxShardID      *uint64         `protobuf:"varint,9,req,name=MaxShardID" json:"MaxShardID,omitempty"`
 	// added for 0.10.0
-	DataNodes        []*NodeInfo `protobuf:"bytes,10,rep,name=DataNodes" json:"DataNodes,omitempty"`
-	MetaNodes        []*NodeInfo `protobuf:"bytes,11,rep,name=MetaNodes" json:"MetaNodes,omitempty"`
-	XXX_unrecognized []byte      `json:"-"`
+	DataNodes        []*NodeInfo  `protobuf:"bytes,10,rep,name=DataNodes" json:"DataNodes,omitempty"`
+	MetaNodes        []*NodeInfo  `protobuf:"bytes,11,rep,name=MetaNodes" json:"MetaNodes,omitempty"`
+	Tokens           []*TokenInfo `protobuf:"bytes,12,rep,name=Tokens" json:"Tokens,omitempty"`
+	XXX_unrecognized []byte       `json:"-"`
 }
 
 func (m *Data) Reset()                    { *m = Data{} }
@@ -283,6 +286,13 @@ func (m *Data) GetMetaNodes() []*NodeInfo {
 	return nil
 }
 
+func (m *Data) GetTokens() []*TokenInfo {
+	if m != nil {
+		return m.Tokens
+	}
+	return nil
+}
+
 type NodeInfo struct {
 	ID               *uint64 `protobuf:"varint,1,req,name=ID" json:"ID,omitempty"`
 	Host             *string `protobuf:"bytes,2,req,name=Host" json:"Host,omitempty"`
@@ -321,6 +331,7 @@ type DatabaseInfo struct {
 	DefaultRetentionPolicy *string                `protobuf:"bytes,2,req,name=DefaultRetentionPolicy" json:"DefaultRetentionPolicy,omitempty"`
 	RetentionPolicies      []*RetentionPolicyInfo `protobuf:"bytes,3,rep,name=RetentionPolicies" json:"RetentionPolicies,omitempty"`
 	ContinuousQueries      []*ContinuousQueryInfo `protobuf:"bytes,4,rep,name=ContinuousQueries" json:"ContinuousQueries,omitempty"`
+	Quota                  *QuotaInfo             `protobuf:"bytes,5,opt,name=Quota" json:"Quota,omitempty"`
 	XXX_unrecognized       []byte                 `json:"-"`
 }
 
@@ -357,6 +368,46 @@ func (m *DatabaseInfo) GetContinuousQueries() []*ContinuousQueryInfo {
 	return nil
 }
 
+func (m *DatabaseInfo) GetQuota() *QuotaInfo {
+	if m != nil {
+		return m.Quota
+	}
+	return nil
+}
+
+type QuotaInfo struct {
+	MaxBytes           *int64 `protobuf:"varint,1,opt,name=MaxBytes" json:"MaxBytes,omitempty"`
+	MaxSeries          *int64 `protobuf:"varint,2,opt,name=MaxSeries" json:"MaxSeries,omitempty"`
+	MaxWritesPerSecond *int64 `protobuf:"varint,3,opt,name=MaxWritesPerSecond" json:"MaxWritesPerSecond,omitempty"`
+	XXX_unrecognized   []byte `json:"-"`
+}
+
+func (m *QuotaInfo) Reset()                    { *m = QuotaInfo{} }
+func (m *QuotaInfo) String() string            { return proto.CompactTextString(m) }
+func (*QuotaInfo) ProtoMessage()               {}
+func (*QuotaInfo) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{3} }
+
+func (m *QuotaInfo) GetMaxBytes() int64 {
+	if m != nil && m.MaxBytes != nil {
+		return *m.MaxBytes
+	}
+	return 0
+}
+
+func (m *QuotaInfo) GetMaxSeries() int64 {
+	if m != nil && m.MaxSeries != nil {
+		return *m.MaxSeries
+	}
+	return 0
+}
+
+func (m *QuotaInfo) GetMaxWritesPerSecond() int64 {
+	if m != nil && m.MaxWritesPerSecond != nil {
+		return *m.MaxWritesPerSecond
+	}
+	return 0
+}
+
 type RetentionPolicySpec struct {
 	Name               *string `protobuf:"bytes,1,opt,name=Name" json:"Name,omitempty"`
 	Duration           *int64  `protobuf:"varint,2,opt,name=Duration" json:"Duration,omitempty"`
@@ -368,7 +419,7 @@ type RetentionPolicySpec struct {
 func (m *RetentionPolicySpec) Reset()                    { *m = RetentionPolicySpec{} }
 func (m *RetentionPolicySpec) String() string            { return proto.CompactTextString(m) }
 func (*RetentionPolicySpec) ProtoMessage()               {}
-func (*RetentionPolicySpec) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{3} }
+func (*RetentionPolicySpec) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{4} }
 
 func (m *RetentionPolicySpec) GetName() string {
 	if m != nil && m.Name != nil {
@@ -411,7 +462,7 @@ type RetentionPolicyInfo struct {
 func (m *RetentionPolicyInfo) Reset()                    { *m = RetentionPolicyInfo{} }
 func (m *RetentionPolicyInfo) String() string            { return proto.CompactTextString(m) }
 func (*RetentionPolicyInfo) ProtoMessage()               {}
-func (*RetentionPolicyInfo) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{4} }
+func (*RetentionPolicyInfo) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{5} }
 
 func (m *RetentionPolicyInfo) GetName() string {
 	if m != nil && m.Name != nil {
@@ -468,7 +519,7 @@ type ShardGroupInfo struct {
 func (m *ShardGroupInfo) Reset()                    { *m = ShardGroupInfo{} }
 func (m *ShardGroupInfo) String() string            { return proto.CompactTextString(m) }
 func (*ShardGroupInfo) ProtoMessage()               {}
-func (*ShardGroupInfo) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{5} }
+func (*ShardGroupInfo) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{6} }
 
 func (m *ShardGroupInfo) GetID() uint64 {
 	if m != nil && m.ID != nil {
@@ -522,7 +573,7 @@ type ShardInfo struct {
 func (m *ShardInfo) Reset()                    { *m = ShardInfo{} }
 func (m *ShardInfo) String() string            { return proto.CompactTextString(m) }
 func (*ShardInfo) ProtoMessage()               {}
-func (*ShardInfo) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{6} }
+func (*ShardInfo) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{7} }
 
 func (m *ShardInfo) GetID() uint64 {
 	if m != nil && m.ID != nil {
@@ -555,7 +606,7 @@ type SubscriptionInfo struct {
 func (m *SubscriptionInfo) Reset()                    { *m = SubscriptionInfo{} }
 func (m *SubscriptionInfo) String() string            { return proto.CompactTextString(m) }
 func (*SubscriptionInfo) ProtoMessage()               {}
-func (*SubscriptionInfo) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{7} }
+func (*SubscriptionInfo) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{8} }
 
 func (m *SubscriptionInfo) GetName() string {
 	if m != nil && m.Name != nil {
@@ -586,7 +637,7 @@ type ShardOwner struct {
 func (m *ShardOwner) Reset()                    { *m = ShardOwner{} }
 func (m *ShardOwner) String() string            { return proto.CompactTextString(m) }
 func (*ShardOwner) ProtoMessage()               {}
-func (*ShardOwner) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{8} }
+func (*ShardOwner) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{9} }
 
 func (m *ShardOwner) GetNodeID() uint64 {
 	if m != nil && m.NodeID != nil {
@@ -604,7 +655,7 @@ type ContinuousQueryInfo struct {
 func (m *ContinuousQueryInfo) Reset()                    { *m = ContinuousQueryInfo{} }
 func (m *ContinuousQueryInfo) String() string            { return proto.CompactTextString(m) }
 func (*ContinuousQueryInfo) ProtoMessage()               {}
-func (*ContinuousQueryInfo) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{9} }
+func (*ContinuousQueryInfo) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{10} }
 
 func (m *ContinuousQueryInfo) GetName() string {
 	if m != nil && m.Name != nil {
@@ -621,17 +672,20 @@ func (m *ContinuousQueryInfo) GetQuery() string {
 }
 
 type UserInfo struct {
-	Name             *string          `protobuf:"bytes,1,req,name=Name" json:"Name,omitempty"`
-	Hash             *string          `protobuf:"bytes,2,req,name=Hash" json:"Hash,omitempty"`
-	Admin            *bool            `protobuf:"varint,3,req,name=Admin" json:"Admin,omitempty"`
-	Privileges       []*UserPrivilege `protobuf:"bytes,4,rep,name=Privileges" json:"Privileges,omitempty"`
-	XXX_unrecognized []byte           `json:"-"`
+	Name                   *string          `protobuf:"bytes,1,req,name=Name" json:"Name,omitempty"`
+	Hash                   *string          `protobuf:"bytes,2,req,name=Hash" json:"Hash,omitempty"`
+	Admin                  *bool            `protobuf:"varint,3,req,name=Admin" json:"Admin,omitempty"`
+	Privileges             []*UserPrivilege `protobuf:"bytes,4,rep,name=Privileges" json:"Privileges,omitempty"`
+	DefaultDatabase        *string          `protobuf:"bytes,5,opt,name=DefaultDatabase" json:"DefaultDatabase,omitempty"`
+	DefaultRetentionPolicy *string          `protobuf:"bytes,6,opt,name=DefaultRetentionPolicy" json:"DefaultRetentionPolicy,omitempty"`
+	Precision              *string          `protobuf:"bytes,7,opt,name=Precision" json:"Precision,omitempty"`
+	XXX_unrecognized       []byte           `json:"-"`
 }
 
 func (m *UserInfo) Reset()                    { *m = UserInfo{} }
 func (m *UserInfo) String() string            { return proto.CompactTextString(m) }
 func (*UserInfo) ProtoMessage()               {}
-func (*UserInfo) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{10} }
+func (*UserInfo) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{11} }
 
 func (m *UserInfo) GetName() string {
 	if m != nil && m.Name != nil {
@@ -661,6 +715,27 @@ func (m *UserInfo) GetPrivileges() []*UserPrivilege {
 	return nil
 }
 
+func (m *UserInfo) GetDefaultDatabase() string {
+	if m != nil && m.DefaultDatabase != nil {
+		return *m.DefaultDatabase
+	}
+	return ""
+}
+
+func (m *UserInfo) GetDefaultRetentionPolicy() string {
+	if m != nil && m.DefaultRetentionPolicy != nil {
+		return *m.DefaultRetentionPolicy
+	}
+	return ""
+}
+
+func (m *UserInfo) GetPrecision() string {
+	if m != nil && m.Precision != nil {
+		return *m.Precision
+	}
+	return ""
+}
+
 type UserPrivilege struct {
 	Database         *string `protobuf:"bytes,1,req,name=Database" json:"Database,omitempty"`
 	Privilege        *int32  `protobuf:"varint,2,req,name=Privilege" json:"Privilege,omitempty"`
@@ -670,7 +745,7 @@ type UserPrivilege struct {
 func (m *UserPrivilege) Reset()                    { *m = UserPrivilege{} }
 func (m *UserPrivilege) String() string            { return proto.CompactTextString(m) }
 func (*UserPrivilege) ProtoMessage()               {}
-func (*UserPrivilege) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{11} }
+func (*UserPrivilege) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{12} }
 
 func (m *UserPrivilege) GetDatabase() string {
 	if m != nil && m.Database != nil {
@@ -686,6 +761,39 @@ func (m *UserPrivilege) GetPrivilege() int32 {
 	return 0
 }
 
+type TokenInfo struct {
+	Name             *string          `protobuf:"bytes,1,req,name=Name" json:"Name,omitempty"`
+	Hash             *string          `protobuf:"bytes,2,req,name=Hash" json:"Hash,omitempty"`
+	Privileges       []*UserPrivilege `protobuf:"bytes,3,rep,name=Privileges" json:"Privileges,omitempty"`
+	XXX_unrecognized []byte           `json:"-"`
+}
+
+func (m *TokenInfo) Reset()                    { *m = TokenInfo{} }
+func (m *TokenInfo) String() string            { return proto.CompactTextString(m) }
+func (*TokenInfo) ProtoMessage()               {}
+func (*TokenInfo) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{13} }
+
+func (m *TokenInfo) GetName() string {
+	if m != nil && m.Name != nil {
+		return *m.Name
+	}
+	return ""
+}
+
+func (m *TokenInfo) GetHash() string {
+	if m != nil && m.Hash != nil {
+		return *m.Hash
+	}
+	return ""
+}
+
+func (m *TokenInfo) GetPrivileges() []*UserPrivilege {
+	if m != nil {
+		return m.Privileges
+	}
+	return nil
+}
+
 type Command struct {
 	Type                         *Command_Type `protobuf:"varint,1,req,name=type,enum=meta.Command_Type" json:"type,omitempty"`
 	proto.XXX_InternalExtensions `json:"-"`
@@ -695,7 +803,7 @@ type Command struct {
 func (m *Command) Reset()                    { *m = Command{} }
 func (m *Command) String() string            { return proto.CompactTextString(m) }
 func (*Command) ProtoMessage()               {}
-func (*Command) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{12} }
+func (*Command) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{14} }
 
 var extRange_Command = []proto.ExtensionRange{
 	{Start: 100, End: 536870911},
@@ -723,7 +831,7 @@ type CreateNodeCommand struct {
 func (m *CreateNodeCommand) Reset()                    { *m = CreateNodeCommand{} }
 func (m *CreateNodeCommand) String() string            { return proto.CompactTextString(m) }
 func (*CreateNodeCommand) ProtoMessage()               {}
-func (*CreateNodeCommand) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{13} }
+func (*CreateNodeCommand) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{15} }
 
 func (m *CreateNodeCommand) GetHost() string {
 	if m != nil && m.Host != nil {
@@ -756,7 +864,7 @@ type DeleteNodeCommand struct {
 func (m *DeleteNodeCommand) Reset()                    { *m = DeleteNodeCommand{} }
 func (m *DeleteNodeCommand) String() string            { return proto.CompactTextString(m) }
 func (*DeleteNodeCommand) ProtoMessage()               {}
-func (*DeleteNodeCommand) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{14} }
+func (*DeleteNodeCommand) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{16} }
 
 func (m *DeleteNodeCommand) GetID() uint64 {
 	if m != nil && m.ID != nil {
@@ -789,7 +897,7 @@ type CreateDatabaseCommand struct {
 func (m *CreateDatabaseCommand) Reset()                    { *m = CreateDatabaseCommand{} }
 func (m *CreateDatabaseCommand) String() string            { return proto.CompactTextString(m) }
 func (*CreateDatabaseCommand) ProtoMessage()               {}
-func (*CreateDatabaseCommand) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{15} }
+func (*CreateDatabaseCommand) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{17} }
 
 func (m *CreateDatabaseCommand) GetName() string {
 	if m != nil && m.Name != nil {
@@ -821,7 +929,7 @@ type DropDatabaseCommand struct {
 func (m *DropDatabaseCommand) Reset()                    { *m = DropDatabaseCommand{} }
 func (m *DropDatabaseCommand) String() string            { return proto.CompactTextString(m) }
 func (*DropDatabaseCommand) ProtoMessage()               {}
-func (*DropDatabaseCommand) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{16} }
+func (*DropDatabaseCommand) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{18} }
 
 func (m *DropDatabaseCommand) GetName() string {
 	if m != nil && m.Name != nil {
@@ -848,7 +956,7 @@ func (m *CreateRetentionPolicyCommand) Reset()         { *m = CreateRetentionPol
 func (m *CreateRetentionPolicyCommand) String() string { return proto.CompactTextString(m) }
 func (*CreateRetentionPolicyCommand) ProtoMessage()    {}
 func (*CreateRetentionPolicyCommand) Descriptor() ([]byte, []int) {
-	return fileDescriptorMeta, []int{17}
+	return fileDescriptorMeta, []int{19}
 }
 
 func (m *CreateRetentionPolicyCommand) GetDatabase() string {
@@ -882,7 +990,7 @@ type DropRetentionPolicyCommand struct {
 func (m *DropRetentionPolicyCommand) Reset()                    { *m = DropRetentionPolicyCommand{} }
 func (m *DropRetentionPolicyCommand) String() string            { return proto.CompactTextString(m) }
 func (*DropRetentionPolicyCommand) ProtoMessage()               {}
-func (*DropRetentionPolicyCommand) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{18} }
+func (*DropRetentionPolicyCommand) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{20} }
 
 func (m *DropRetentionPolicyCommand) GetDatabase() string {
 	if m != nil && m.Database != nil {
@@ -916,7 +1024,7 @@ func (m *SetDefaultRetentionPolicyCommand) Reset()         { *m = SetDefaultRete
 func (m *SetDefaultRetentionPolicyCommand) String() string { return proto.CompactTextString(m) }
 func (*SetDefaultRetentionPolicyCommand) ProtoMessage()    {}
 func (*SetDefaultRetentionPolicyCommand) Descriptor() ([]byte, []int) {
-	return fileDescriptorMeta, []int{19}
+	return fileDescriptorMeta, []int{21}
 }
 
 func (m *SetDefaultRetentionPolicyCommand) GetDatabase() string {
@@ -954,7 +1062,7 @@ func (m *UpdateRetentionPolicyCommand) Reset()         { *m = UpdateRetentionPol
 func (m *UpdateRetentionPolicyCommand) String() string { return proto.CompactTextString(m) }
 func (*UpdateRetentionPolicyCommand) ProtoMessage()    {}
 func (*UpdateRetentionPolicyCommand) Descriptor() ([]byte, []int) {
-	return fileDescriptorMeta, []int{20}
+	return fileDescriptorMeta, []int{22}
 }
 
 func (m *UpdateRetentionPolicyCommand) GetDatabase() string {
@@ -1010,7 +1118,7 @@ type CreateShardGroupCommand struct {
 func (m *CreateShardGroupCommand) Reset()                    { *m = CreateShardGroupCommand{} }
 func (m *CreateShardGroupCommand) String() string            { return proto.CompactTextString(m) }
 func (*CreateShardGroupCommand) ProtoMessage()               {}
-func (*CreateShardGroupCommand) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{21} }
+func (*CreateShardGroupCommand) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{23} }
 
 func (m *CreateShardGroupCommand) GetDatabase() string {
 	if m != nil && m.Database != nil {
@@ -1051,7 +1159,7 @@ type DeleteShardGroupCommand struct {
 func (m *DeleteShardGroupCommand) Reset()                    { *m = DeleteShardGroupCommand{} }
 func (m *DeleteShardGroupCommand) String() string            { return proto.CompactTextString(m) }
 func (*DeleteShardGroupCommand) ProtoMessage()               {}
-func (*DeleteShardGroupCommand) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{22} }
+func (*DeleteShardGroupCommand) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{24} }
 
 func (m *DeleteShardGroupCommand) GetDatabase() string {
 	if m != nil && m.Database != nil {
@@ -1093,7 +1201,7 @@ func (m *CreateContinuousQueryCommand) Reset()         { *m = CreateContinuousQu
 func (m *CreateContinuousQueryCommand) String() string { return proto.CompactTextString(m) }
 func (*CreateContinuousQueryCommand) ProtoMessage()    {}
 func (*CreateContinuousQueryCommand) Descriptor() ([]byte, []int) {
-	return fileDescriptorMeta, []int{23}
+	return fileDescriptorMeta, []int{25}
 }
 
 func (m *CreateContinuousQueryCommand) GetDatabase() string {
@@ -1134,7 +1242,7 @@ type DropContinuousQueryCommand struct {
 func (m *DropContinuousQueryCommand) Reset()                    { *m = DropContinuousQueryCommand{} }
 func (m *DropContinuousQueryCommand) String() string            { return proto.CompactTextString(m) }
 func (*DropContinuousQueryCommand) ProtoMessage()               {}
-func (*DropContinuousQueryCommand) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{24} }
+func (*DropContinuousQueryCommand) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{26} }
 
 func (m *DropContinuousQueryCommand) GetDatabase() string {
 	if m != nil && m.Database != nil {
@@ -1168,7 +1276,7 @@ type CreateUserCommand struct {
 func (m *CreateUserCommand) Reset()                    { *m = CreateUserCommand{} }
 func (m *CreateUserCommand) String() string            { return proto.CompactTextString(m) }
 func (*CreateUserCommand) ProtoMessage()               {}
-func (*CreateUserCommand) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{25} }
+func (*CreateUserCommand) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{27} }
 
 func (m *CreateUserCommand) GetName() string {
 	if m != nil && m.Name != nil {
@@ -1207,7 +1315,7 @@ type DropUserCommand struct {
 func (m *DropUserCommand) Reset()                    { *m = DropUserCommand{} }
 func (m *DropUserCommand) String() string            { return proto.CompactTextString(m) }
 func (*DropUserCommand) ProtoMessage()               {}
-func (*DropUserCommand) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{26} }
+func (*DropUserCommand) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{28} }
 
 func (m *DropUserCommand) GetName() string {
 	if m != nil && m.Name != nil {
@@ -1233,7 +1341,7 @@ type UpdateUserCommand struct {
 func (m *UpdateUserCommand) Reset()                    { *m = UpdateUserCommand{} }
 func (m *UpdateUserCommand) String() string            { return proto.CompactTextString(m) }
 func (*UpdateUserCommand) ProtoMessage()               {}
-func (*UpdateUserCommand) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{27} }
+func (*UpdateUserCommand) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{29} }
 
 func (m *UpdateUserCommand) GetName() string {
 	if m != nil && m.Name != nil {
@@ -1267,7 +1375,7 @@ type SetPrivilegeCommand struct {
 func (m *SetPrivilegeCommand) Reset()                    { *m = SetPrivilegeCommand{} }
 func (m *SetPrivilegeCommand) String() string            { return proto.CompactTextString(m) }
 func (*SetPrivilegeCommand) ProtoMessage()               {}
-func (*SetPrivilegeCommand) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{28} }
+func (*SetPrivilegeCommand) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{30} }
 
 func (m *SetPrivilegeCommand) GetUsername() string {
 	if m != nil && m.Username != nil {
@@ -1306,7 +1414,7 @@ type SetDataCommand struct {
 func (m *SetDataCommand) Reset()                    { *m = SetDataCommand{} }
 func (m *SetDataCommand) String() string            { return proto.CompactTextString(m) }
 func (*SetDataCommand) ProtoMessage()               {}
-func (*SetDataCommand) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{29} }
+func (*SetDataCommand) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{31} }
 
 func (m *SetDataCommand) GetData() *Data {
 	if m != nil {
@@ -1332,7 +1440,7 @@ type SetAdminPrivilegeCommand struct {
 func (m *SetAdminPrivilegeCommand) Reset()                    { *m = SetAdminPrivilegeCommand{} }
 func (m *SetAdminPrivilegeCommand) String() string            { return proto.CompactTextString(m) }
 func (*SetAdminPrivilegeCommand) ProtoMessage()               {}
-func (*SetAdminPrivilegeCommand) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{30} }
+func (*SetAdminPrivilegeCommand) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{32} }
 
 func (m *SetAdminPrivilegeCommand) GetUsername() string {
 	if m != nil && m.Username != nil {
@@ -1365,7 +1473,7 @@ type UpdateNodeCommand struct {
 func (m *UpdateNodeCommand) Reset()                    { *m = UpdateNodeCommand{} }
 func (m *UpdateNodeCommand) String() string            { return proto.CompactTextString(m) }
 func (*UpdateNodeCommand) ProtoMessage()               {}
-func (*UpdateNodeCommand) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{31} }
+func (*UpdateNodeCommand) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{33} }
 
 func (m *UpdateNodeCommand) GetID() uint64 {
 	if m != nil && m.ID != nil {
@@ -1401,7 +1509,7 @@ type CreateSubscriptionCommand struct {
 func (m *CreateSubscriptionCommand) Reset()                    { *m = CreateSubscriptionCommand{} }
 func (m *CreateSubscriptionCommand) String() string            { return proto.CompactTextString(m) }
 func (*CreateSubscriptionCommand) ProtoMessage()               {}
-func (*CreateSubscriptionCommand) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{32} }
+func (*CreateSubscriptionCommand) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{34} }
 
 func (m *CreateSubscriptionCommand) GetName() string {
 	if m != nil && m.Name != nil {
@@ -1456,7 +1564,7 @@ type DropSubscriptionCommand struct {
 func (m *DropSubscriptionCommand) Reset()                    { *m = DropSubscriptionCommand{} }
 func (m *DropSubscriptionCommand) String() string            { return proto.CompactTextString(m) }
 func (*DropSubscriptionCommand) ProtoMessage()               {}
-func (*DropSubscriptionCommand) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{33} }
+func (*DropSubscriptionCommand) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{35} }
 
 func (m *DropSubscriptionCommand) GetName() string {
 	if m != nil && m.Name != nil {
@@ -1496,7 +1604,7 @@ type RemovePeerCommand struct {
 func (m *RemovePeerCommand) Reset()                    { *m = RemovePeerCommand{} }
 func (m *RemovePeerCommand) String() string            { return proto.CompactTextString(m) }
 func (*RemovePeerCommand) ProtoMessage()               {}
-func (*RemovePeerCommand) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{34} }
+func (*RemovePeerCommand) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{36} }
 
 func (m *RemovePeerCommand) GetID() uint64 {
 	if m != nil && m.ID != nil {
@@ -1530,7 +1638,7 @@ type CreateMetaNodeCommand struct {
 func (m *CreateMetaNodeCommand) Reset()                    { *m = CreateMetaNodeCommand{} }
 func (m *CreateMetaNodeCommand) String() string            { return proto.CompactTextString(m) }
 func (*CreateMetaNodeCommand) ProtoMessage()               {}
-func (*CreateMetaNodeCommand) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{35} }
+func (*CreateMetaNodeCommand) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{37} }
 
 func (m *CreateMetaNodeCommand) GetHTTPAddr() string {
 	if m != nil && m.HTTPAddr != nil {
@@ -1570,7 +1678,7 @@ type CreateDataNodeCommand struct {
 func (m *CreateDataNodeCommand) Reset()                    { *m = CreateDataNodeCommand{} }
 func (m *CreateDataNodeCommand) String() string            { return proto.CompactTextString(m) }
 func (*CreateDataNodeCommand) ProtoMessage()               {}
-func (*CreateDataNodeCommand) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{36} }
+func (*CreateDataNodeCommand) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{38} }
 
 func (m *CreateDataNodeCommand) GetHTTPAddr() string {
 	if m != nil && m.HTTPAddr != nil {
@@ -1604,7 +1712,7 @@ type UpdateDataNodeCommand struct {
 func (m *UpdateDataNodeCommand) Reset()                    { *m = UpdateDataNodeCommand{} }
 func (m *UpdateDataNodeCommand) String() string            { return proto.CompactTextString(m) }
 func (*UpdateDataNodeCommand) ProtoMessage()               {}
-func (*UpdateDataNodeCommand) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{37} }
+func (*UpdateDataNodeCommand) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{39} }
 
 func (m *UpdateDataNodeCommand) GetID() uint64 {
 	if m != nil && m.ID != nil {
@@ -1643,7 +1751,7 @@ type DeleteMetaNodeCommand struct {
 func (m *DeleteMetaNodeCommand) Reset()                    { *m = DeleteMetaNodeCommand{} }
 func (m *DeleteMetaNodeCommand) String() string            { return proto.CompactTextString(m) }
 func (*DeleteMetaNodeCommand) ProtoMessage()               {}
-func (*DeleteMetaNodeCommand) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{38} }
+func (*DeleteMetaNodeCommand) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{40} }
 
 func (m *DeleteMetaNodeCommand) GetID() uint64 {
 	if m != nil && m.ID != nil {
@@ -1668,7 +1776,7 @@ type DeleteDataNodeCommand struct {
 func (m *DeleteDataNodeCommand) Reset()                    { *m = DeleteDataNodeCommand{} }
 func (m *DeleteDataNodeCommand) String() string            { return proto.CompactTextString(m) }
 func (*DeleteDataNodeCommand) ProtoMessage()               {}
-func (*DeleteDataNodeCommand) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{39} }
+func (*DeleteDataNodeCommand) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{41} }
 
 func (m *DeleteDataNodeCommand) GetID() uint64 {
 	if m != nil && m.ID != nil {
@@ -1695,7 +1803,7 @@ type Response struct {
 func (m *Response) Reset()                    { *m = Response{} }
 func (m *Response) String() string            { return proto.CompactTextString(m) }
 func (*Response) ProtoMessage()               {}
-func (*Response) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{40} }
+func (*Response) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{42} }
 
 func (m *Response) GetOK() bool {
 	if m != nil && m.OK != nil {
@@ -1730,7 +1838,7 @@ type SetMetaNodeCommand struct {
 func (m *SetMetaNodeCommand) Reset()                    { *m = SetMetaNodeCommand{} }
 func (m *SetMetaNodeCommand) String() string            { return proto.CompactTextString(m) }
 func (*SetMetaNodeCommand) ProtoMessage()               {}
-func (*SetMetaNodeCommand) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{41} }
+func (*SetMetaNodeCommand) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{43} }
 
 func (m *SetMetaNodeCommand) GetHTTPAddr() string {
 	if m != nil && m.HTTPAddr != nil {
@@ -1769,7 +1877,7 @@ type DropShardCommand struct {
 func (m *DropShardCommand) Reset()                    { *m = DropShardCommand{} }
 func (m *DropShardCommand) String() string            { return proto.CompactTextString(m) }
 func (*DropShardCommand) ProtoMessage()               {}
-func (*DropShardCommand) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{42} }
+func (*DropShardCommand) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{44} }
 
 func (m *DropShardCommand) GetID() uint64 {
 	if m != nil && m.ID != nil {
@@ -1790,6 +1898,7 @@ func init() {
 	proto.RegisterType((*Data)(nil), "meta.Data")
 	proto.RegisterType((*NodeInfo)(nil), "meta.NodeInfo")
 	proto.RegisterType((*DatabaseInfo)(nil), "meta.DatabaseInfo")
+	proto.RegisterType((*QuotaInfo)(nil), "meta.QuotaInfo")
 	proto.RegisterType((*RetentionPolicySpec)(nil), "meta.RetentionPolicySpec")
 	proto.RegisterType((*RetentionPolicyInfo)(nil), "meta.RetentionPolicyInfo")
 	proto.RegisterType((*ShardGroupInfo)(nil), "meta.ShardGroupInfo")
@@ -1799,6 +1908,7 @@ func init() {
 	proto.RegisterType((*ContinuousQueryInfo)(nil), "meta.ContinuousQueryInfo")
 	proto.RegisterType((*UserInfo)(nil), "meta.UserInfo")
 	proto.RegisterType((*UserPrivilege)(nil), "meta.UserPrivilege")
+	proto.RegisterType((*TokenInfo)(nil), "meta.TokenInfo")
 	proto.RegisterType((*Command)(nil), "meta.Command")
 	proto.RegisterType((*CreateNodeCommand)(nil), "meta.CreateNodeCommand")
 	proto.RegisterType((*DeleteNodeCommand)(nil), "meta.DeleteNodeCommand")
@@ -1865,107 +1975,113 @@ func init() {
 func init() { proto.RegisterFile("internal/meta.proto", fileDescriptorMeta) }
 
 var fileDescriptorMeta = []byte{
-	// 1617 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0x9c, 0x58, 0x5b, 0x6f, 0x1b, 0xc5,
-	0x17, 0xd7, 0xda, 0x6b, 0xc7, 0x7b, 0x62, 0x27, 0xf6, 0x38, 0x97, 0x4d, 0x9b, 0xa4, 0xee, 0xe8,
-	0x7f, 0xf1, 0xff, 0x2f, 0x51, 0x24, 0x2b, 0x15, 0x42, 0x5c, 0xdb, 0xb8, 0xa5, 0x11, 0x4a, 0x1a,
-	0x62, 0x17, 0xde, 0xaa, 0x6e, 0xed, 0x49, 0xb3, 0x60, 0xef, 0x9a, 0xdd, 0x75, 0xd3, 0x50, 0x68,
-	0x03, 0x12, 0x42, 0x20, 0x21, 0xc1, 0x0b, 0x2f, 0x3c, 0xf1, 0xc6, 0x37, 0x40, 0x3c, 0xf0, 0x29,
-	0xf8, 0x42, 0x68, 0x66, 0xf6, 0x32, 0xbb, 0x3b, 0xb3, 0x69, 0xfb, 0x66, 0xcf, 0x39, 0x73, 0x7e,
-	0xbf, 0x39, 0xb7, 0x39, 0xb3, 0xd0, 0xb6, 0x9d, 0x80, 0x78, 0x8e, 0x35, 0x79, 0x7d, 0x4a, 0x02,
-	0xeb, 0xda, 0xcc, 0x73, 0x03, 0x17, 0xe9, 0xf4, 0x37, 0xfe, 0xad, 0x04, 0x7a, 0xdf, 0x0a, 0x2c,
-	0x54, 0x07, 0x7d, 0x48, 0xbc, 0xa9, 0xa9, 0x75, 0x4a, 0x5d, 0x1d, 0x35, 0xa0, 0xb2, 0xe7, 0x8c,
-	0xc9, 0x13, 0xb3, 0xc4, 0xfe, 0xb6, 0xc0, 0xd8, 0x9d, 0xcc, 0xfd, 0x80, 0x78, 0x7b, 0x7d, 0xb3,
-	0xcc, 0x96, 0xb6, 0xa0, 0x72, 0xe0, 0x8e, 0x89, 0x6f, 0xea, 0x9d, 0x72, 0x77, 0xb1, 0xb7, 0x74,
-	0x8d, 0x99, 0xa6, 0x4b, 0x7b, 0xce, 0xb1, 0x8b, 0xfe, 0x0d, 0x06, 0x35, 0xfb, 0xd0, 0xf2, 0x89,
-	0x6f, 0x56, 0x98, 0x0a, 0xe2, 0x2a, 0xd1, 0x32, 0x53, 0xdb, 0x82, 0xca, 0x3d, 0x9f, 0x78, 0xbe,
-	0x59, 0x15, 0xad, 0xd0, 0x25, 0x26, 0x6e, 0x81, 0xb1, 0x6f, 0x3d, 0x61, 0x46, 0xfb, 0xe6, 0x02,
-	0xc3, 0x5d, 0x87, 0xe5, 0x7d, 0xeb, 0xc9, 0xe0, 0xc4, 0xf2, 0xc6, 0x1f, 0x78, 0xee, 0x7c, 0xb6,
-	0xd7, 0x37, 0x6b, 0x4c, 0x80, 0x00, 0x22, 0xc1, 0x5e, 0xdf, 0x34, 0xd8, 0xda, 0x55, 0xce, 0x82,
-	0x13, 0x05, 0x29, 0xd1, 0xab, 0x60, 0xec, 0x93, 0x48, 0x65, 0x51, 0xa6, 0x82, 0xaf, 0x43, 0x2d,
-	0x56, 0x07, 0x28, 0xed, 0xf5, 0x43, 0x27, 0xd5, 0x41, 0xbf, 0xe3, 0xfa, 0x01, 0xf3, 0x91, 0x81,
-	0x96, 0x61, 0x61, 0xb8, 0x7b, 0xc8, 0x16, 0xca, 0x1d, 0xad, 0x6b, 0xe0, 0xdf, 0x35, 0xa8, 0xa7,
-	0x0e, 0x5b, 0x07, 0xfd, 0xc0, 0x9a, 0x12, 0xb6, 0xdb, 0x40, 0xdb, 0xb0, 0xd6, 0x27, 0xc7, 0xd6,
-	0x7c, 0x12, 0x1c, 0x91, 0x80, 0x38, 0x81, 0xed, 0x3a, 0x87, 0xee, 0xc4, 0x1e, 0x9d, 0x85, 0xf6,
-	0x76, 0xa0, 0x95, 0x16, 0xd8, 0xc4, 0x37, 0xcb, 0x8c, 0xe0, 0x06, 0x27, 0x98, 0xd9, 0xc7, 0x30,
-	0x76, 0xa0, 0xb5, 0xeb, 0x3a, 0x81, 0xed, 0xcc, 0xdd, 0xb9, 0xff, 0xd1, 0x9c, 0x78, 0x76, 0x1c,
-	0xa2, 0x70, 0x57, 0x5a, 0xcc, 0x76, 0xe1, 0x11, 0xb4, 0x33, 0xc6, 0x06, 0x33, 0x32, 0x12, 0x08,
-	0x6b, 0x5d, 0x03, 0x35, 0xa1, 0xd6, 0x9f, 0x7b, 0x16, 0xd5, 0x31, 0x4b, 0x1d, 0xad, 0x5b, 0x46,
-	0x97, 0x00, 0x25, 0x81, 0x88, 0x65, 0x65, 0x26, 0x6b, 0x42, 0xed, 0x88, 0xcc, 0x26, 0xf6, 0xc8,
-	0x3a, 0x30, 0xf5, 0x8e, 0xd6, 0x6d, 0xe0, 0xbf, 0xb4, 0x1c, 0x8a, 0xc4, 0x2d, 0x69, 0x94, 0x52,
-	0x01, 0x4a, 0x29, 0x87, 0x52, 0xea, 0x36, 0xd0, 0xff, 0x60, 0x31, 0xd1, 0x8e, 0x52, 0x6f, 0x85,
-	0x1f, 0x5d, 0xc8, 0x1a, 0x0a, 0xfc, 0x1a, 0x34, 0x06, 0xf3, 0x87, 0xfe, 0xc8, 0xb3, 0x67, 0xd4,
-	0x64, 0x94, 0x84, 0x6b, 0xa1, 0xb2, 0x20, 0x62, 0x4e, 0xfa, 0x5e, 0x83, 0xa5, 0x8c, 0x05, 0x31,
-	0x1b, 0x5a, 0x60, 0x0c, 0x02, 0xcb, 0x0b, 0x86, 0xf6, 0x94, 0x84, 0xcc, 0x97, 0x61, 0xe1, 0x96,
-	0x33, 0x66, 0x0b, 0x9c, 0x6e, 0x0b, 0x8c, 0x3e, 0x99, 0x90, 0x80, 0x8c, 0x6f, 0x04, 0x8c, 0x6f,
-	0x19, 0x5d, 0x81, 0x2a, 0x33, 0x1a, 0x51, 0x5d, 0x16, 0xa8, 0x32, 0x8c, 0x36, 0x2c, 0x0e, 0xbd,
-	0xb9, 0x33, 0xb2, 0xf8, 0xae, 0x2a, 0xf5, 0x2e, 0xbe, 0x0b, 0x46, 0xa2, 0x21, 0xb2, 0x58, 0x81,
-	0xda, 0xdd, 0x53, 0x87, 0xd6, 0xa9, 0x6f, 0x96, 0x3a, 0xe5, 0xae, 0x7e, 0xb3, 0x64, 0x6a, 0xa8,
-	0x03, 0x55, 0xb6, 0x1a, 0x25, 0x50, 0x53, 0x00, 0x61, 0x02, 0xdc, 0x87, 0x66, 0xf6, 0xc0, 0x99,
-	0xc0, 0xd4, 0x41, 0xdf, 0x77, 0xc7, 0x24, 0xcc, 0xce, 0x15, 0xa8, 0xf7, 0x89, 0x1f, 0xd8, 0x8e,
-	0xc5, 0x5d, 0x47, 0xed, 0x1a, 0x78, 0x13, 0x20, 0xb1, 0x89, 0x96, 0xa0, 0x1a, 0x96, 0x2e, 0xe3,
-	0x86, 0x7b, 0xd0, 0x96, 0x24, 0x5f, 0x06, 0xa6, 0x01, 0x15, 0x26, 0xe2, 0x38, 0xf8, 0x3e, 0xd4,
-	0xe2, 0x6e, 0x90, 0xe3, 0x73, 0xc7, 0xf2, 0x4f, 0x42, 0x3e, 0x0d, 0xa8, 0xdc, 0x18, 0x4f, 0x6d,
-	0x9e, 0x17, 0x35, 0xf4, 0x5f, 0x80, 0x43, 0xcf, 0x7e, 0x6c, 0x4f, 0xc8, 0xa3, 0x38, 0xff, 0xdb,
-	0x49, 0x73, 0x89, 0x65, 0x78, 0x07, 0x1a, 0xa9, 0x05, 0x96, 0x7f, 0x61, 0xd1, 0x86, 0x40, 0x2d,
-	0x30, 0x62, 0x31, 0x43, 0xab, 0xe0, 0xbf, 0xab, 0xb0, 0xb0, 0xeb, 0x4e, 0xa7, 0x96, 0x33, 0x46,
-	0x1d, 0xd0, 0x83, 0xb3, 0x19, 0x57, 0x5e, 0x8a, 0x9a, 0x5c, 0x28, 0xbc, 0x36, 0x3c, 0x9b, 0x11,
-	0xfc, 0x6b, 0x15, 0x74, 0xfa, 0x03, 0xad, 0x42, 0x6b, 0xd7, 0x23, 0x56, 0x40, 0xa8, 0x5b, 0x42,
-	0x95, 0xa6, 0x46, 0x97, 0x79, 0x56, 0x88, 0xcb, 0x25, 0xb4, 0x01, 0xab, 0x5c, 0x3b, 0xe2, 0x13,
-	0x89, 0xca, 0x68, 0x1d, 0xda, 0x7d, 0xcf, 0x9d, 0x65, 0x05, 0x3a, 0xea, 0xc0, 0x26, 0xdf, 0x93,
-	0x29, 0xb4, 0x48, 0xa3, 0x82, 0xb6, 0xe1, 0x12, 0xdd, 0xaa, 0x90, 0x57, 0xd1, 0xbf, 0xa0, 0x33,
-	0x20, 0x81, 0xbc, 0x33, 0x45, 0x5a, 0x0b, 0x14, 0xe7, 0xde, 0x6c, 0xac, 0xc6, 0xa9, 0xa1, 0xcb,
-	0xb0, 0xce, 0x99, 0x24, 0x25, 0x13, 0x09, 0x0d, 0x2a, 0xe4, 0x27, 0xce, 0x0b, 0x21, 0x39, 0x43,
-	0x26, 0x59, 0x22, 0x8d, 0xc5, 0xe8, 0x0c, 0x0a, 0x79, 0x3d, 0xf1, 0x33, 0x0d, 0x6d, 0xb4, 0xdc,
-	0x40, 0x6d, 0x58, 0xa6, 0xdb, 0xc4, 0xc5, 0x25, 0xaa, 0xcb, 0x4f, 0x22, 0x2e, 0x2f, 0x53, 0x0f,
-	0x0f, 0x48, 0x10, 0xc7, 0x3d, 0x12, 0x34, 0x11, 0x82, 0x25, 0xea, 0x1f, 0x2b, 0xb0, 0xa2, 0xb5,
-	0x16, 0xda, 0x04, 0x73, 0x40, 0x02, 0x96, 0x7f, 0xb9, 0x1d, 0x28, 0x41, 0x10, 0xc3, 0xdb, 0x46,
-	0x5b, 0xb0, 0x11, 0x3a, 0x48, 0xa8, 0xbb, 0x48, 0xbc, 0xca, 0x5c, 0xe4, 0xb9, 0x33, 0x99, 0x70,
-	0x8d, 0x9a, 0x3c, 0x22, 0x53, 0xf7, 0x31, 0x39, 0x24, 0x09, 0xe9, 0xf5, 0x24, 0x63, 0xa2, 0x1b,
-	0x2d, 0x12, 0x99, 0xe9, 0x64, 0x12, 0x45, 0x1b, 0x54, 0xc4, 0xf9, 0x65, 0x45, 0x97, 0xa8, 0x88,
-	0xc7, 0x29, 0x6b, 0xf0, 0x72, 0x22, 0xca, 0xee, 0xda, 0x44, 0x6b, 0x80, 0x06, 0x24, 0xc8, 0x6e,
-	0xd9, 0x42, 0x2b, 0xd0, 0x64, 0x47, 0xa2, 0x31, 0x8f, 0x56, 0xb7, 0xff, 0x5f, 0xab, 0x8d, 0x9b,
-	0xe7, 0xe7, 0xe7, 0xe7, 0x25, 0x7c, 0x22, 0x29, 0x8f, 0xf8, 0x92, 0x8d, 0x8b, 0xfe, 0xc8, 0x72,
-	0xc6, 0x7c, 0x2c, 0xe9, 0xbd, 0x01, 0x0b, 0xa3, 0x50, 0xad, 0x91, 0xaa, 0x3b, 0x93, 0x74, 0xb4,
-	0xee, 0x62, 0x6f, 0x3d, 0x5c, 0xcc, 0x1a, 0xc5, 0x8f, 0x24, 0x15, 0x97, 0x6a, 0xa3, 0x0d, 0xa8,
-	0xdc, 0x76, 0xbd, 0x11, 0xaf, 0xf7, 0x5a, 0x01, 0xd0, 0xb1, 0x08, 0x94, 0xb3, 0x89, 0x7f, 0xd1,
-	0x14, 0x45, 0x9c, 0x69, 0x66, 0x3d, 0x58, 0xce, 0x4f, 0x01, 0x5a, 0xe1, 0x55, 0xdf, 0x7b, 0x4b,
-	0x49, 0xea, 0x11, 0xdb, 0x7a, 0x59, 0x3c, 0x7d, 0x06, 0x1e, 0xdf, 0x97, 0x76, 0x90, 0x34, 0xab,
-	0xde, 0x9b, 0x4a, 0x84, 0x13, 0x91, 0x9c, 0xc4, 0x10, 0x1d, 0x7e, 0x0a, 0x3b, 0x91, 0xa4, 0xcf,
-	0x4a, 0x7d, 0x50, 0x2a, 0xf6, 0xc1, 0x4d, 0x25, 0x43, 0x9b, 0x31, 0xc4, 0xa2, 0x0f, 0xe4, 0x4c,
-	0xf0, 0xb3, 0xa2, 0x8e, 0x28, 0xe1, 0x19, 0xf9, 0x88, 0x5d, 0x3c, 0xbd, 0xf7, 0x95, 0x0c, 0x3e,
-	0x65, 0x0c, 0x3a, 0x89, 0x8f, 0x14, 0xf8, 0x3f, 0x68, 0x17, 0xb7, 0xdc, 0x0b, 0x69, 0xdc, 0x56,
-	0xd2, 0xf8, 0x8c, 0xd1, 0xf8, 0x4f, 0x78, 0xe3, 0x5f, 0x80, 0x83, 0xff, 0xd0, 0x8a, 0x3b, 0xfb,
-	0x45, 0x44, 0xe8, 0xcc, 0x73, 0x40, 0x4e, 0xd9, 0x42, 0x39, 0x37, 0x36, 0xea, 0xb9, 0xd1, 0xb0,
-	0x42, 0x47, 0xc3, 0x82, 0x30, 0x4e, 0xc4, 0x30, 0x16, 0x11, 0xc3, 0x3f, 0x6a, 0xca, 0x1b, 0x47,
-	0x42, 0x7a, 0x09, 0xaa, 0xa9, 0x69, 0xbb, 0x05, 0x06, 0x9d, 0xd3, 0xfc, 0xc0, 0x9a, 0xce, 0xf8,
-	0xb0, 0xd6, 0x7b, 0x47, 0x49, 0x6a, 0xca, 0x48, 0x6d, 0x89, 0xb9, 0x95, 0xc3, 0xc4, 0x3f, 0x69,
-	0xca, 0x4b, 0xee, 0x05, 0xf8, 0xac, 0x40, 0x3d, 0xf5, 0xc6, 0x61, 0x8f, 0xae, 0x02, 0x4a, 0x8e,
-	0x48, 0x49, 0x01, 0x8b, 0x7f, 0xd6, 0x8a, 0xaf, 0xd6, 0x0b, 0x83, 0x1b, 0x0f, 0x67, 0x65, 0x96,
-	0x74, 0xea, 0xb0, 0xb9, 0xf9, 0xea, 0x93, 0x43, 0x46, 0xd5, 0xf7, 0x6a, 0x84, 0x0a, 0xaa, 0x6f,
-	0x96, 0xad, 0x3e, 0x05, 0xfe, 0xa9, 0x64, 0x56, 0x78, 0x89, 0x49, 0xb3, 0xe0, 0x6a, 0xf8, 0x3c,
-	0x7f, 0x07, 0x09, 0x18, 0xf8, 0xe3, 0xdc, 0x34, 0x92, 0xe9, 0xbe, 0xd7, 0x95, 0x96, 0x3d, 0x66,
-	0x79, 0x35, 0x39, 0x9b, 0x68, 0xf7, 0x44, 0x32, 0xd0, 0x14, 0x1d, 0xa8, 0xe0, 0x04, 0xbe, 0x78,
-	0x82, 0x9c, 0x51, 0xfc, 0x9d, 0x26, 0x1d, 0x92, 0x68, 0xd0, 0xa8, 0x9a, 0x93, 0x7e, 0xd4, 0x45,
-	0x61, 0x2c, 0xe5, 0x87, 0x6a, 0xea, 0xc9, 0x4a, 0xc1, 0x6d, 0x13, 0x88, 0xb7, 0x8d, 0x04, 0x11,
-	0x3f, 0xc8, 0x0e, 0x65, 0xc8, 0xe4, 0x9f, 0x35, 0x18, 0xfe, 0x62, 0x0f, 0x92, 0x4f, 0x0f, 0xbd,
-	0x1d, 0x25, 0xcc, 0x9c, 0xc1, 0xac, 0x24, 0x9d, 0x32, 0xb1, 0x87, 0x9f, 0xaa, 0x47, 0x3c, 0xc9,
-	0x79, 0xe3, 0x1c, 0xe1, 0xe3, 0xc3, 0xbb, 0x4a, 0xc8, 0xc7, 0x0c, 0x72, 0x3b, 0x86, 0x94, 0x02,
-	0xe0, 0x63, 0xc9, 0x04, 0xa9, 0xfe, 0x12, 0x51, 0x10, 0xd0, 0xd3, 0x7c, 0x40, 0xc5, 0x69, 0xe5,
-	0x4f, 0xad, 0x60, 0x26, 0x95, 0xbc, 0xd3, 0xd3, 0x21, 0x5d, 0xcf, 0xdf, 0xdf, 0xe5, 0xd4, 0xcb,
-	0x51, 0x97, 0xbe, 0x1c, 0xe9, 0xb3, 0xd7, 0xe8, 0xbd, 0xa7, 0xe4, 0x7c, 0xc6, 0x38, 0x5f, 0x49,
-	0x35, 0xdb, 0x3c, 0x3b, 0xda, 0xdb, 0x54, 0x03, 0xf3, 0x2b, 0x33, 0x2f, 0xe8, 0xb7, 0x5f, 0xa4,
-	0xfa, 0xad, 0x1c, 0x97, 0xc6, 0x2d, 0x37, 0xa6, 0xc7, 0x71, 0xd3, 0x78, 0xdc, 0x6e, 0x8c, 0xc7,
-	0xde, 0x85, 0x71, 0x7b, 0x2a, 0xc6, 0x2d, 0x67, 0x12, 0x7f, 0xab, 0x29, 0x06, 0x7f, 0x7a, 0xd6,
-	0x3b, 0xc3, 0xe1, 0x21, 0x03, 0xd1, 0x84, 0xcf, 0x54, 0x09, 0x6a, 0x3c, 0x52, 0xf3, 0x1b, 0x46,
-	0x3d, 0x54, 0x7e, 0x99, 0x1f, 0x2a, 0x33, 0x68, 0xf8, 0x54, 0xf1, 0xc8, 0x78, 0x01, 0x1a, 0x05,
-	0xc0, 0x5f, 0xc9, 0xa7, 0x59, 0x11, 0xf8, 0xb9, 0xe2, 0x09, 0xf3, 0xa2, 0x9f, 0xeb, 0x8a, 0x09,
-	0x3c, 0x13, 0x09, 0x48, 0x71, 0xf0, 0x03, 0xc5, 0x43, 0x49, 0x24, 0x50, 0x80, 0xf0, 0x5c, 0x44,
-	0x90, 0x1a, 0xc2, 0x96, 0xe2, 0xbd, 0x95, 0x42, 0x78, 0x5b, 0x89, 0x70, 0xae, 0xe5, 0x21, 0xb2,
-	0x87, 0xd8, 0xa1, 0x73, 0x99, 0x3f, 0x73, 0x1d, 0x9f, 0x50, 0xab, 0x77, 0x3f, 0x64, 0x56, 0x6b,
-	0xb4, 0x9b, 0xdd, 0xf2, 0x3c, 0xd7, 0x63, 0x4f, 0x12, 0x23, 0xf9, 0x36, 0x4c, 0xe7, 0x3b, 0x1d,
-	0x9f, 0x6b, 0xb2, 0xe7, 0xde, 0xcb, 0x67, 0x9e, 0xba, 0xfd, 0x7f, 0xcd, 0xb9, 0x9b, 0x71, 0x97,
-	0xcc, 0xfa, 0xe6, 0x93, 0xfc, 0xc3, 0x32, 0xe5, 0x16, 0x75, 0x61, 0x7d, 0xc3, 0x4d, 0xaf, 0x09,
-	0x75, 0x2c, 0x18, 0xf9, 0x27, 0x00, 0x00, 0xff, 0xff, 0x5d, 0xc6, 0xc9, 0x45, 0x39, 0x17, 0x00,
-	0x00,
+	// 1725 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x9c, 0x58, 0xdd, 0x6f, 0x1b, 0xc7,
+	0x11, 0xc7, 0x91, 0x47, 0x8a, 0x37, 0x22, 0x45, 0x71, 0xa9, 0x8f, 0x93, 0x2d, 0xc9, 0xf4, 0xa2,
+	0x1f, 0x6c, 0x81, 0xba, 0x00, 0x21, 0xa3, 0x28, 0xfa, 0x69, 0x8b, 0x76, 0x2d, 0xb4, 0x92, 0x69,
+	0x91, 0xae, 0xdf, 0x0a, 0x9f, 0xc9, 0x95, 0x75, 0x35, 0x79, 0xc7, 0xde, 0x1d, 0x2d, 0xa9, 0x6e,
+	0x6d, 0xb5, 0x40, 0x10, 0x24, 0x40, 0x80, 0xe4, 0x25, 0x2f, 0xf9, 0x07, 0x82, 0xfc, 0x03, 0x41,
+	0x1e, 0xf2, 0x92, 0xa7, 0xbc, 0xe7, 0x1f, 0x0a, 0x76, 0xf7, 0x3e, 0xf6, 0xee, 0x76, 0x4f, 0xb6,
+	0xdf, 0xc8, 0x99, 0xd9, 0xf9, 0xfd, 0x76, 0x66, 0x67, 0x76, 0xf6, 0xa0, 0x6d, 0x3b, 0x01, 0xf1,
+	0x1c, 0x6b, 0xfa, 0xeb, 0x19, 0x09, 0xac, 0x5b, 0x73, 0xcf, 0x0d, 0x5c, 0xa4, 0xd3, 0xdf, 0xf8,
+	0xbb, 0x12, 0xe8, 0x7d, 0x2b, 0xb0, 0x50, 0x1d, 0xf4, 0x11, 0xf1, 0x66, 0xa6, 0xd6, 0x29, 0x75,
+	0x75, 0xd4, 0x80, 0xca, 0x81, 0x33, 0x21, 0xe7, 0x66, 0x89, 0xfd, 0x6d, 0x81, 0xb1, 0x3f, 0x5d,
+	0xf8, 0x01, 0xf1, 0x0e, 0xfa, 0x66, 0x99, 0x89, 0x76, 0xa0, 0x72, 0xe4, 0x4e, 0x88, 0x6f, 0xea,
+	0x9d, 0x72, 0x77, 0xb9, 0xb7, 0x72, 0x8b, 0xb9, 0xa6, 0xa2, 0x03, 0xe7, 0xc4, 0x45, 0x3f, 0x05,
+	0x83, 0xba, 0x7d, 0x66, 0xf9, 0xc4, 0x37, 0x2b, 0xcc, 0x04, 0x71, 0x93, 0x48, 0xcc, 0xcc, 0x76,
+	0xa0, 0xf2, 0xd8, 0x27, 0x9e, 0x6f, 0x56, 0x45, 0x2f, 0x54, 0xc4, 0xd4, 0x2d, 0x30, 0x0e, 0xad,
+	0x73, 0xe6, 0xb4, 0x6f, 0x2e, 0x31, 0xdc, 0x4d, 0x68, 0x1e, 0x5a, 0xe7, 0xc3, 0x53, 0xcb, 0x9b,
+	0xfc, 0xc5, 0x73, 0x17, 0xf3, 0x83, 0xbe, 0x59, 0x63, 0x0a, 0x04, 0x10, 0x29, 0x0e, 0xfa, 0xa6,
+	0xc1, 0x64, 0x37, 0x39, 0x0b, 0x4e, 0x14, 0xa4, 0x44, 0x6f, 0x82, 0x71, 0x48, 0x22, 0x93, 0x65,
+	0xa9, 0xc9, 0x0d, 0xa8, 0x8e, 0xdc, 0x17, 0xc4, 0xf1, 0xcd, 0x3a, 0xd3, 0x37, 0xb9, 0x9e, 0xc9,
+	0xa8, 0x01, 0xbe, 0x0d, 0xb5, 0xd8, 0x18, 0xa0, 0x74, 0xd0, 0x0f, 0xa3, 0x58, 0x07, 0xfd, 0x81,
+	0xeb, 0x07, 0x2c, 0x88, 0x06, 0x6a, 0xc2, 0xd2, 0x68, 0x7f, 0xc0, 0x04, 0xe5, 0x8e, 0xd6, 0x35,
+	0xf0, 0xf7, 0x1a, 0xd4, 0x53, 0xd1, 0xa8, 0x83, 0x7e, 0x64, 0xcd, 0x08, 0x5b, 0x6d, 0xa0, 0x5d,
+	0xd8, 0xe8, 0x93, 0x13, 0x6b, 0x31, 0x0d, 0x8e, 0x49, 0x40, 0x9c, 0xc0, 0x76, 0x9d, 0x81, 0x3b,
+	0xb5, 0xc7, 0x17, 0xa1, 0xbf, 0x3d, 0x68, 0xa5, 0x15, 0x36, 0xf1, 0xcd, 0x32, 0x63, 0xb8, 0xc5,
+	0x19, 0x66, 0xd6, 0x31, 0x8c, 0x3d, 0x68, 0xed, 0xbb, 0x4e, 0x60, 0x3b, 0x0b, 0x77, 0xe1, 0x3f,
+	0x5a, 0x10, 0xcf, 0x8e, 0x73, 0x18, 0xae, 0x4a, 0xab, 0xf9, 0xaa, 0x5d, 0xa8, 0x3c, 0x5a, 0xb8,
+	0x81, 0x65, 0x56, 0x3a, 0x5a, 0x12, 0x01, 0x26, 0x62, 0x11, 0xf8, 0x1b, 0x18, 0xf1, 0x1f, 0xb4,
+	0x0a, 0xb5, 0x43, 0xeb, 0xfc, 0xee, 0x45, 0x40, 0x7c, 0x53, 0xeb, 0x68, 0xdd, 0x72, 0x98, 0xc7,
+	0x21, 0x07, 0x2b, 0x31, 0xd1, 0x35, 0x40, 0x87, 0xd6, 0xf9, 0x13, 0xcf, 0x0e, 0x88, 0x3f, 0x20,
+	0xde, 0x90, 0x8c, 0x5d, 0x67, 0xc2, 0x02, 0x53, 0xc6, 0x63, 0x68, 0x67, 0xa8, 0x0f, 0xe7, 0x64,
+	0x2c, 0x84, 0x47, 0xeb, 0x1a, 0x14, 0xa5, 0xbf, 0xf0, 0x2c, 0x6a, 0x93, 0xb8, 0x4c, 0xce, 0x45,
+	0xac, 0x63, 0x2e, 0xa9, 0xf5, 0x31, 0x99, 0x4f, 0xed, 0xb1, 0x75, 0x64, 0xea, 0x1d, 0xad, 0xdb,
+	0xc0, 0xdf, 0x6a, 0x39, 0x14, 0x49, 0x12, 0xd2, 0x28, 0xa5, 0x02, 0x94, 0x52, 0x0e, 0xa5, 0xd4,
+	0x6d, 0xa0, 0x5f, 0xc0, 0x72, 0x62, 0x1d, 0x55, 0xc2, 0x1a, 0x0f, 0x9f, 0x70, 0x88, 0x29, 0xf0,
+	0xaf, 0xa0, 0x31, 0x5c, 0x3c, 0xf3, 0xc7, 0x9e, 0x3d, 0xa7, 0x2e, 0xa3, 0x9a, 0xd8, 0x08, 0x8d,
+	0x05, 0x15, 0x0b, 0xf9, 0x47, 0x1a, 0xac, 0x64, 0x3c, 0x88, 0x67, 0xaf, 0x05, 0xc6, 0x30, 0xb0,
+	0xbc, 0x60, 0x64, 0xcf, 0x48, 0xc8, 0xbc, 0x09, 0x4b, 0xf7, 0x9c, 0x09, 0x13, 0x70, 0xba, 0x2d,
+	0x30, 0xfa, 0x64, 0x4a, 0x02, 0x32, 0xb9, 0x13, 0x30, 0xbe, 0x65, 0x7a, 0xd6, 0x99, 0xd3, 0x88,
+	0x6a, 0x53, 0xa0, 0xca, 0x30, 0xda, 0xb0, 0x3c, 0xf2, 0x16, 0xce, 0xd8, 0xe2, 0xab, 0xaa, 0x2c,
+	0x61, 0x0f, 0xc1, 0x48, 0x2c, 0x44, 0x16, 0x6b, 0x50, 0x7b, 0x78, 0xe6, 0xd0, 0xb6, 0x41, 0xf3,
+	0x5e, 0xee, 0xea, 0x77, 0x4b, 0xa6, 0x86, 0x3a, 0x50, 0x65, 0xd2, 0xe8, 0xb8, 0xae, 0x0a, 0x20,
+	0x4c, 0x81, 0xfb, 0xb0, 0x9a, 0xdd, 0x70, 0x26, 0x31, 0x75, 0xd0, 0x0f, 0xdd, 0x09, 0x09, 0x6b,
+	0x61, 0x0d, 0xea, 0x7d, 0xe2, 0x07, 0xb6, 0x63, 0xf1, 0xd0, 0x51, 0xbf, 0x06, 0xde, 0x06, 0x48,
+	0x7c, 0xa2, 0x15, 0xa8, 0x86, 0x9d, 0x84, 0x71, 0xc3, 0x3d, 0x68, 0xcb, 0x8e, 0x7a, 0x1a, 0xa6,
+	0x41, 0x0f, 0x3e, 0xf1, 0xc2, 0x9a, 0xc3, 0x5f, 0x69, 0x50, 0x8b, 0xbb, 0x53, 0x8e, 0xd0, 0x03,
+	0xcb, 0x3f, 0x0d, 0x09, 0x35, 0xa0, 0x72, 0x67, 0x32, 0xb3, 0xf9, 0xc1, 0xa8, 0xa1, 0x9f, 0x03,
+	0x0c, 0x3c, 0xfb, 0xa5, 0x3d, 0x25, 0xcf, 0xe3, 0x72, 0x6b, 0x27, 0xcd, 0x2e, 0xd6, 0xd1, 0xf6,
+	0x16, 0x16, 0x7d, 0xd4, 0x19, 0x58, 0xc9, 0x15, 0x75, 0x83, 0x2a, 0xd3, 0xb7, 0xc0, 0x18, 0x78,
+	0x64, 0x6c, 0xfb, 0xf4, 0x34, 0x2e, 0xb1, 0xfe, 0xb2, 0x07, 0x8d, 0xb4, 0x73, 0x7a, 0x98, 0x23,
+	0xaf, 0x9c, 0x34, 0x5b, 0x15, 0xaa, 0x19, 0xf3, 0x0a, 0x1e, 0x80, 0x11, 0x77, 0xb6, 0xc2, 0x2d,
+	0xa6, 0xf7, 0x54, 0x56, 0xee, 0x09, 0xff, 0x50, 0x85, 0xa5, 0x7d, 0x77, 0x36, 0xb3, 0x9c, 0x09,
+	0xea, 0x80, 0x1e, 0x5c, 0xcc, 0xb9, 0xc3, 0x95, 0xe8, 0x4a, 0x08, 0x95, 0xb7, 0x46, 0x17, 0x73,
+	0x82, 0xbf, 0xa8, 0x82, 0x4e, 0x7f, 0xa0, 0x75, 0x68, 0xed, 0x7b, 0xc4, 0x0a, 0x08, 0xcd, 0x5a,
+	0x68, 0xb2, 0xaa, 0x51, 0x31, 0x3f, 0xb4, 0xa2, 0xb8, 0x84, 0xb6, 0x60, 0x9d, 0x5b, 0x47, 0x3b,
+	0x8c, 0x54, 0x65, 0xb4, 0x09, 0xed, 0xbe, 0xe7, 0xce, 0xb3, 0x0a, 0x1d, 0x75, 0x60, 0x9b, 0xaf,
+	0xc9, 0x84, 0x34, 0xb2, 0xa8, 0xa0, 0x5d, 0xb8, 0x46, 0x97, 0x2a, 0xf4, 0x55, 0xf4, 0x13, 0xe8,
+	0x0c, 0x49, 0x20, 0x4f, 0x4c, 0x64, 0xb5, 0x44, 0x71, 0x1e, 0xcf, 0x27, 0x6a, 0x9c, 0x1a, 0xba,
+	0x0e, 0x9b, 0x9c, 0x49, 0x52, 0xd1, 0x91, 0xd2, 0xa0, 0x4a, 0xbe, 0xe3, 0xbc, 0x12, 0x92, 0x3d,
+	0x64, 0xce, 0x72, 0x64, 0xb1, 0x1c, 0xed, 0x41, 0xa1, 0xaf, 0x27, 0x71, 0xa6, 0x59, 0x8b, 0xc4,
+	0x0d, 0xd4, 0x86, 0x26, 0x5d, 0x26, 0x0a, 0x57, 0xa8, 0x2d, 0xdf, 0x89, 0x28, 0x6e, 0xd2, 0x08,
+	0x0f, 0x49, 0x10, 0x67, 0x3c, 0x52, 0xac, 0x22, 0x04, 0x2b, 0x34, 0x3e, 0x56, 0x60, 0x45, 0xb2,
+	0x16, 0xda, 0x06, 0x73, 0x48, 0x02, 0x56, 0x1d, 0xb9, 0x15, 0x28, 0x41, 0x10, 0xd3, 0xdb, 0x46,
+	0x3b, 0xb0, 0x15, 0x06, 0x48, 0x68, 0x0b, 0x91, 0x7a, 0x9d, 0x85, 0xc8, 0x73, 0xe7, 0x32, 0xe5,
+	0x06, 0x75, 0x79, 0x4c, 0x66, 0xee, 0x4b, 0x32, 0x20, 0x09, 0xe9, 0xcd, 0xe4, 0xc4, 0x44, 0xf7,
+	0x7f, 0xa4, 0x32, 0xd3, 0x87, 0x49, 0x54, 0x6d, 0x51, 0x15, 0xe7, 0x97, 0x55, 0x5d, 0xa3, 0x2a,
+	0x9e, 0xa7, 0xac, 0xc3, 0xeb, 0x89, 0x2a, 0xbb, 0x6a, 0x1b, 0x6d, 0x00, 0x1a, 0x92, 0x20, 0xbb,
+	0x64, 0x07, 0xad, 0xc1, 0x2a, 0xdb, 0x12, 0xcd, 0x79, 0x24, 0xdd, 0xfd, 0x65, 0xad, 0x36, 0x59,
+	0xbd, 0xbc, 0xbc, 0xbc, 0x2c, 0xe1, 0x53, 0x49, 0x79, 0xc4, 0x13, 0x47, 0x5c, 0xaf, 0xc7, 0x96,
+	0x33, 0xe1, 0x43, 0x5c, 0xef, 0x37, 0xb0, 0x34, 0x0e, 0xcd, 0x1a, 0xa9, 0xba, 0x33, 0x09, 0xbb,
+	0xd4, 0x37, 0x43, 0x61, 0xd6, 0x29, 0x7e, 0x2e, 0xa9, 0xb8, 0x54, 0x97, 0x6f, 0x40, 0xe5, 0xbe,
+	0xeb, 0x8d, 0x79, 0x07, 0xa9, 0x15, 0x00, 0x9d, 0x88, 0x40, 0x39, 0x9f, 0xf8, 0x73, 0x4d, 0x51,
+	0xc4, 0x99, 0x3e, 0xd4, 0x83, 0x66, 0x7e, 0x24, 0xd2, 0x0a, 0xe7, 0x9e, 0xde, 0xef, 0x94, 0xa4,
+	0x9e, 0xb3, 0xa5, 0xd7, 0xc5, 0xdd, 0x67, 0xe0, 0xf1, 0x3f, 0xa4, 0x1d, 0x24, 0xcd, 0xaa, 0xf7,
+	0x5b, 0x25, 0xc2, 0xa9, 0x48, 0x4e, 0xe2, 0x08, 0x7f, 0xa9, 0x15, 0x77, 0x22, 0x49, 0xe7, 0x96,
+	0xc6, 0xa0, 0x54, 0x1c, 0x83, 0xbb, 0x4a, 0x86, 0x36, 0x63, 0x88, 0xc5, 0x18, 0xc8, 0x99, 0xe0,
+	0xd7, 0x45, 0x1d, 0x51, 0xc2, 0x33, 0x8a, 0x11, 0xbb, 0x33, 0x7a, 0x7f, 0x56, 0x32, 0xf8, 0x27,
+	0x63, 0xd0, 0x49, 0x62, 0xa4, 0xc0, 0xff, 0x58, 0xbb, 0xba, 0xe5, 0x5e, 0x49, 0xe3, 0xbe, 0x92,
+	0xc6, 0x0b, 0x46, 0xe3, 0x67, 0x5c, 0x78, 0x15, 0x0e, 0xfe, 0x5a, 0x2b, 0xee, 0xec, 0x57, 0x11,
+	0xa1, 0x23, 0xd9, 0x11, 0x39, 0x63, 0x82, 0x72, 0x6e, 0xaa, 0xd5, 0x73, 0x93, 0x2b, 0x1d, 0x05,
+	0x1a, 0x05, 0x69, 0x9c, 0x8a, 0x69, 0x2c, 0x22, 0x86, 0x3f, 0xd1, 0x94, 0x37, 0x8e, 0x84, 0xf4,
+	0x0a, 0x54, 0x53, 0x4f, 0x8f, 0x16, 0x18, 0x74, 0x8c, 0xf4, 0x03, 0x6b, 0x36, 0xe7, 0xb3, 0x64,
+	0xef, 0x0f, 0x4a, 0x52, 0x33, 0x46, 0x6a, 0x47, 0x3c, 0x5b, 0x39, 0x4c, 0xfc, 0xa9, 0xa6, 0xbc,
+	0xe4, 0xde, 0x82, 0xcf, 0x1a, 0xd4, 0x53, 0x2f, 0x42, 0xf6, 0x44, 0x2d, 0xa0, 0xe4, 0x88, 0x94,
+	0x14, 0xb0, 0xf8, 0x33, 0xad, 0xf8, 0x6a, 0xbd, 0x32, 0xb9, 0xf1, 0xec, 0x48, 0xe9, 0x18, 0x05,
+	0x69, 0x73, 0xf3, 0xd5, 0x27, 0x87, 0x8c, 0xaa, 0xef, 0xfd, 0x08, 0x15, 0x54, 0xdf, 0x3c, 0x5b,
+	0x7d, 0x0a, 0xfc, 0x33, 0xc9, 0xac, 0xf0, 0x0e, 0x73, 0x70, 0xc1, 0xd5, 0xf0, 0xaf, 0xfc, 0x1d,
+	0x24, 0x60, 0xe0, 0xbf, 0xe7, 0xa6, 0x91, 0x4c, 0xf7, 0xbd, 0xad, 0xf4, 0xec, 0x31, 0xcf, 0xeb,
+	0xc9, 0xde, 0x44, 0xbf, 0xa7, 0x92, 0x81, 0xa6, 0x68, 0x43, 0x05, 0x3b, 0xf0, 0xc5, 0x1d, 0xe4,
+	0x9c, 0xe2, 0x0f, 0x35, 0xe9, 0x90, 0x44, 0x93, 0x46, 0xcd, 0x9c, 0xf4, 0x9b, 0x33, 0x4a, 0x63,
+	0x29, 0x3f, 0xa6, 0xd3, 0x48, 0x56, 0x0a, 0x6e, 0x9b, 0x40, 0xbc, 0x6d, 0x24, 0x88, 0xf8, 0x69,
+	0x76, 0x28, 0x43, 0x26, 0xff, 0x08, 0xc4, 0xf0, 0x97, 0x7b, 0x90, 0x7c, 0xa8, 0xe9, 0xed, 0x29,
+	0x61, 0x16, 0x1d, 0x4d, 0x78, 0xca, 0xa6, 0xfc, 0xe1, 0x57, 0xea, 0x11, 0x4f, 0xb2, 0xdf, 0xf8,
+	0x8c, 0xf0, 0xf1, 0xe1, 0x8f, 0x4a, 0xc8, 0x97, 0x0c, 0x72, 0x37, 0x86, 0x94, 0x02, 0xe0, 0x13,
+	0xc9, 0x04, 0xa9, 0xfe, 0x2c, 0x53, 0x90, 0xd0, 0xb3, 0x7c, 0x42, 0xc5, 0x69, 0xe5, 0x1b, 0xad,
+	0x60, 0x26, 0x95, 0x7c, 0x46, 0x48, 0xa7, 0x74, 0x33, 0x7f, 0x7f, 0x97, 0x53, 0x0f, 0x5b, 0x5d,
+	0xfa, 0xb0, 0xa5, 0xaf, 0x72, 0xa3, 0xf7, 0x27, 0x25, 0xe7, 0x0b, 0xc6, 0xf9, 0x46, 0xaa, 0xd9,
+	0xe6, 0xd9, 0xd1, 0xde, 0xa6, 0x1a, 0x98, 0xdf, 0x9b, 0x79, 0x41, 0xbf, 0xfd, 0x77, 0xaa, 0xdf,
+	0xca, 0x71, 0xf1, 0x89, 0x64, 0x4c, 0x8f, 0xf3, 0xa6, 0xf1, 0xbc, 0xdd, 0x99, 0x4c, 0xbc, 0x2b,
+	0xf3, 0xf6, 0x4a, 0xcc, 0x5b, 0xce, 0x25, 0xfe, 0x40, 0x53, 0x0c, 0xfe, 0x74, 0xaf, 0x0f, 0x46,
+	0xa3, 0x01, 0x03, 0xd1, 0x84, 0x6f, 0x76, 0x09, 0x6a, 0x3c, 0x52, 0xf3, 0x1b, 0x46, 0x3d, 0x54,
+	0xfe, 0x27, 0x3f, 0x54, 0x66, 0xd0, 0xf0, 0x99, 0xe2, 0x91, 0xf1, 0x16, 0x34, 0x0a, 0x80, 0xff,
+	0x2b, 0x9f, 0x66, 0x45, 0xe0, 0x37, 0x8a, 0x27, 0xcc, 0xdb, 0x7e, 0xbb, 0x2c, 0x26, 0xf0, 0x5a,
+	0x24, 0x20, 0xc5, 0xc1, 0x4f, 0x15, 0x0f, 0x25, 0x91, 0x40, 0x01, 0xc2, 0x1b, 0x11, 0x41, 0xea,
+	0x08, 0x5b, 0x8a, 0xf7, 0x56, 0x0a, 0xe1, 0xf7, 0x4a, 0x84, 0x4b, 0x2d, 0x0f, 0x91, 0xdd, 0xc4,
+	0x1e, 0x9d, 0xcb, 0xfc, 0xb9, 0xeb, 0xf8, 0x84, 0x7a, 0x7d, 0xf8, 0x57, 0xe6, 0xb5, 0x46, 0xbb,
+	0xd9, 0x3d, 0xcf, 0x73, 0x3d, 0xf6, 0x24, 0x31, 0x92, 0x2f, 0xe9, 0x74, 0xbe, 0xd3, 0xf1, 0xa5,
+	0x26, 0x7b, 0xee, 0xbd, 0xfb, 0xc9, 0x53, 0xb7, 0xff, 0xff, 0x71, 0xee, 0x66, 0xdc, 0x25, 0xb3,
+	0xb1, 0x79, 0x92, 0x7f, 0x58, 0xa6, 0xc2, 0xa2, 0x2e, 0xac, 0xff, 0x73, 0xd7, 0x1b, 0x42, 0x1d,
+	0x0b, 0x4e, 0x7e, 0x1c, 0x00, 0x38, 0x2e, 0x0c, 0x39, 0x67, 0x18, 0x00, 0x00,
 }
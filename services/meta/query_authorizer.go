@@ -58,6 +58,18 @@ func (u *UserInfo) AuthorizeQuery(database string, query *influxql.Query) error
 		return nil
 	}
 
+	return authorizeQuery(u, database, query)
+}
+
+// AuthorizeQuery returns an error if the token lacks a privilege required by
+// any statement in the query.
+func (ti *TokenInfo) AuthorizeQuery(database string, query *influxql.Query) error {
+	return authorizeQuery(ti, database, query)
+}
+
+// authorizeQuery checks the privileges required by each statement in the
+// query against a user that does not have admin privileges.
+func authorizeQuery(u User, database string, query *influxql.Query) error {
 	// Check each statement in the query.
 	for _, stmt := range query.Statements {
 		// Get the privileges required to execute the statement.
@@ -74,7 +86,7 @@ func (u *UserInfo) AuthorizeQuery(database string, query *influxql.Query) error
 				// privilege cannot be run.
 				return &ErrAuthorize{
 					Query:    query,
-					User:     u.Name,
+					User:     u.ID(),
 					Database: database,
 					Message:  fmt.Sprintf("statement '%s', requires admin privilege", stmt),
 				}
@@ -90,7 +102,7 @@ func (u *UserInfo) AuthorizeQuery(database string, query *influxql.Query) error
 			if !u.AuthorizeDatabase(p.Privilege, db) {
 				return &ErrAuthorize{
 					Query:    query,
-					User:     u.Name,
+					User:     u.ID(),
 					Database: database,
 					Message:  fmt.Sprintf("statement '%s', requires %s on %s", stmt, p.Privilege.String(), db),
 				}
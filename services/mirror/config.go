@@ -0,0 +1,107 @@
+package mirror
+
+import (
+	"errors"
+	"net/url"
+	"time"
+
+	"github.com/influxdata/influxdb/monitor/diagnostics"
+	"github.com/influxdata/influxdb/toml"
+)
+
+const (
+	// DefaultMaxQueueSize is the default size of the queue of writes waiting
+	// to be mirrored, in bytes.
+	DefaultMaxQueueSize = 1024 * 1024 * 1024
+
+	// DefaultRetryInterval is the default time to wait before retrying a
+	// failed write for the first time.
+	DefaultRetryInterval = time.Second
+
+	// DefaultHTTPTimeout is the default timeout of a write to the mirror.
+	DefaultHTTPTimeout = 30 * time.Second
+)
+
+// Config represents the configuration for mirroring writes to another server.
+type Config struct {
+	Enabled bool `toml:"enabled"`
+
+	// URL is the address of the server that writes are mirrored to.
+	URL      string `toml:"url"`
+	Username string `toml:"username"`
+	Password string `toml:"password"`
+
+	// Databases limits the mirrored writes to the named databases. All
+	// databases are mirrored if it is empty.
+	Databases []string `toml:"databases"`
+
+	// Dir is where writes are queued until the mirror accepts them.
+	Dir string `toml:"dir"`
+
+	// MaxQueueSize is the size the queue may grow to while the mirror is
+	// unavailable. Writes are dropped once the queue is full.
+	MaxQueueSize toml.Size `toml:"max-queue-size"`
+
+	// The wait before retrying a failed write starts at RetryInterval and
+	// doubles every time, up to a minute.
+	RetryInterval toml.Duration `toml:"retry-interval"`
+	HTTPTimeout   toml.Duration `toml:"http-timeout"`
+}
+
+// NewConfig returns a new Config with defaults.
+func NewConfig() Config {
+	return Config{
+		Enabled:       false,
+		MaxQueueSize:  DefaultMaxQueueSize,
+		RetryInterval: toml.Duration(DefaultRetryInterval),
+		HTTPTimeout:   toml.Duration(DefaultHTTPTimeout),
+	}
+}
+
+// Validate returns an error if the Config is invalid.
+func (c Config) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+
+	if c.URL == "" {
+		return errors.New("url must be specified")
+	} else if u, err := url.Parse(c.URL); err != nil {
+		return err
+	} else if u.Scheme != "http" && u.Scheme != "https" {
+		return errors.New("url must use the http or https scheme")
+	}
+
+	if c.Dir == "" {
+		return errors.New("dir must be specified")
+	}
+	if c.MaxQueueSize == 0 {
+		return errors.New("max-queue-size must be greater than 0")
+	}
+	if c.RetryInterval <= 0 {
+		return errors.New("retry-interval must be greater than 0")
+	}
+	if c.HTTPTimeout <= 0 {
+		return errors.New("http-timeout must be greater than 0")
+	}
+	return nil
+}
+
+// Diagnostics returns a diagnostics representation of a subset of the Config.
+func (c Config) Diagnostics() (*diagnostics.Diagnostics, error) {
+	if !c.Enabled {
+		return diagnostics.RowFromMap(map[string]interface{}{
+			"enabled": false,
+		}), nil
+	}
+
+	return diagnostics.RowFromMap(map[string]interface{}{
+		"enabled":        true,
+		"url":            c.URL,
+		"databases":      c.Databases,
+		"dir":            c.Dir,
+		"max-queue-size": c.MaxQueueSize,
+		"retry-interval": c.RetryInterval,
+		"http-timeout":   c.HTTPTimeout,
+	}), nil
+}
@@ -0,0 +1,290 @@
+package mirror
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// defaultSegmentSize is the size at which the queue starts a new segment.
+const defaultSegmentSize = 10 * 1024 * 1024
+
+// headFile is the name of the file that holds the position of the first
+// record of the queue.
+const headFile = "head"
+
+// errQueueFull is returned when a record does not fit into the queue.
+var errQueueFull = errors.New("queue is full")
+
+// segment is a file of the queue. Its name is its id in hexadecimal.
+type segment struct {
+	id   uint64
+	size int64
+}
+
+// queue is a first-in, first-out queue of records that are kept in segment
+// files so that they survive restarts. Records are removed once they have
+// been peeked and advanced past. A record may be peeked again after a
+// restart if the head position was not yet saved, so consumers must
+// tolerate duplicates.
+type queue struct {
+	dir            string
+	maxSize        int64
+	maxSegmentSize int64
+
+	mu         sync.Mutex
+	segments   []segment // oldest first; the last one is appended to
+	head       *os.File  // the first segment
+	headOffset int64     // offset of the first record in the first segment
+	peeked     int64     // size of the record returned by the last peek
+	tail       *os.File  // the last segment
+}
+
+// openQueue opens the queue in dir, creating the directory if needed.
+func openQueue(dir string, maxSize, maxSegmentSize int64) (*queue, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+
+	q := &queue{
+		dir:            dir,
+		maxSize:        maxSize,
+		maxSegmentSize: maxSegmentSize,
+	}
+
+	fis, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, fi := range fis {
+		id, err := strconv.ParseUint(fi.Name(), 16, 64)
+		if err != nil || fi.IsDir() {
+			continue
+		}
+		q.segments = append(q.segments, segment{id: id, size: fi.Size()})
+	}
+	sort.Slice(q.segments, func(i, j int) bool { return q.segments[i].id < q.segments[j].id })
+
+	// Remove the segments that were consumed before the head was saved.
+	if id, offset, err := q.readHead(); err != nil {
+		return nil, err
+	} else {
+		for len(q.segments) > 0 && q.segments[0].id < id {
+			if err := os.Remove(q.path(q.segments[0].id)); err != nil {
+				return nil, err
+			}
+			q.segments = q.segments[1:]
+		}
+		if len(q.segments) > 0 && q.segments[0].id == id {
+			q.headOffset = offset
+		}
+	}
+
+	// Always append to a new segment, so a record that was partially written
+	// when the process stopped is only ever at the end of an old segment.
+	var id uint64 = 1
+	if n := len(q.segments); n > 0 {
+		id = q.segments[n-1].id + 1
+	}
+	if err := q.newSegment(id); err != nil {
+		q.close()
+		return nil, err
+	}
+	return q, nil
+}
+
+func (q *queue) path(id uint64) string {
+	return filepath.Join(q.dir, fmt.Sprintf("%016x", id))
+}
+
+// readHead returns the position of the first record, or a zero position if
+// none was saved.
+func (q *queue) readHead() (uint64, int64, error) {
+	b, err := ioutil.ReadFile(filepath.Join(q.dir, headFile))
+	if os.IsNotExist(err) {
+		return 0, 0, nil
+	} else if err != nil {
+		return 0, 0, err
+	} else if len(b) != 16 {
+		return 0, 0, fmt.Errorf("invalid queue head: %d bytes", len(b))
+	}
+	return binary.BigEndian.Uint64(b[:8]), int64(binary.BigEndian.Uint64(b[8:])), nil
+}
+
+// writeHead saves the position of the first record.
+func (q *queue) writeHead() error {
+	var b [16]byte
+	binary.BigEndian.PutUint64(b[:8], q.segments[0].id)
+	binary.BigEndian.PutUint64(b[8:], uint64(q.headOffset))
+
+	path := filepath.Join(q.dir, headFile)
+	if err := ioutil.WriteFile(path+".tmp", b[:], 0600); err != nil {
+		return err
+	}
+	return os.Rename(path+".tmp", path)
+}
+
+// newSegment starts appending to a new segment.
+func (q *queue) newSegment(id uint64) error {
+	f, err := os.OpenFile(q.path(id), os.O_CREATE|os.O_RDWR|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	if q.tail != nil && q.tail != q.head {
+		if err := q.tail.Close(); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	q.tail = f
+	q.segments = append(q.segments, segment{id: id})
+	return nil
+}
+
+// size returns the number of bytes in the queue.
+func (q *queue) size() int64 {
+	var n int64
+	for _, s := range q.segments {
+		n += s.size
+	}
+	return n - q.headOffset
+}
+
+// Size returns the number of bytes in the queue.
+func (q *queue) Size() int64 {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.size()
+}
+
+// Append adds a record to the end of the queue and syncs it to disk.
+func (q *queue) Append(b []byte) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	n := int64(4 + len(b))
+	if q.size()+n > q.maxSize {
+		return errQueueFull
+	}
+
+	last := &q.segments[len(q.segments)-1]
+	if last.size > 0 && last.size+n > q.maxSegmentSize {
+		if err := q.newSegment(last.id + 1); err != nil {
+			return err
+		}
+		last = &q.segments[len(q.segments)-1]
+	}
+
+	buf := make([]byte, n)
+	binary.BigEndian.PutUint32(buf, uint32(len(b)))
+	copy(buf[4:], b)
+	if _, err := q.tail.Write(buf); err != nil {
+		return err
+	}
+	last.size += n
+	return q.tail.Sync()
+}
+
+// Peek returns the first record of the queue without removing it. It
+// returns io.EOF if the queue is empty.
+func (q *queue) Peek() ([]byte, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for {
+		if q.head == nil {
+			if len(q.segments) == 1 {
+				q.head = q.tail
+			} else {
+				f, err := os.Open(q.path(q.segments[0].id))
+				if err != nil {
+					return nil, err
+				}
+				q.head = f
+			}
+		}
+
+		b, err := readRecord(q.head, q.headOffset)
+		if err == nil {
+			q.peeked = int64(4 + len(b))
+			return b, nil
+		} else if err != io.EOF && err != io.ErrUnexpectedEOF {
+			return nil, err
+		} else if len(q.segments) == 1 {
+			return nil, io.EOF
+		}
+
+		// The first segment is consumed, or ends with a partial record.
+		if err := q.head.Close(); err != nil {
+			return nil, err
+		}
+		q.head = nil
+		if err := os.Remove(q.path(q.segments[0].id)); err != nil {
+			return nil, err
+		}
+		q.segments = q.segments[1:]
+		q.headOffset = 0
+		if err := q.writeHead(); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// Advance removes the record returned by the last call to Peek.
+func (q *queue) Advance() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.peeked == 0 {
+		return nil
+	}
+	q.headOffset += q.peeked
+	q.peeked = 0
+	return q.writeHead()
+}
+
+// readRecord reads the record at offset of f.
+func readRecord(f *os.File, offset int64) ([]byte, error) {
+	var hdr [4]byte
+	if n, err := f.ReadAt(hdr[:], offset); err == io.EOF && n > 0 {
+		return nil, io.ErrUnexpectedEOF
+	} else if err != nil {
+		return nil, err
+	}
+
+	b := make([]byte, binary.BigEndian.Uint32(hdr[:]))
+	if _, err := f.ReadAt(b, offset+4); err == io.EOF {
+		return nil, io.ErrUnexpectedEOF
+	} else if err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// Close closes the files of the queue.
+func (q *queue) Close() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.close()
+}
+
+func (q *queue) close() error {
+	var err error
+	if q.head != nil && q.head != q.tail {
+		err = q.head.Close()
+	}
+	if q.tail != nil {
+		if e := q.tail.Close(); e != nil && err == nil {
+			err = e
+		}
+	}
+	q.head, q.tail = nil, nil
+	return err
+}
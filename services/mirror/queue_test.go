@@ -0,0 +1,74 @@
+package mirror
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestQueue(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mirror")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// Small segments so that records span several of them.
+	q, err := openQueue(dir, 64, 16)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := q.Peek(); err != io.EOF {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, s := range []string{"aaaaaaaaaa", "bbbbbbbbbb", "cccccccccc", "dddddddddd"} {
+		if err := q.Append([]byte(s)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := q.Append([]byte("eeeeeeeeee")); err != errQueueFull {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if b, err := q.Peek(); err != nil || string(b) != "aaaaaaaaaa" {
+		t.Fatalf("unexpected record: %q, %v", b, err)
+	} else if err := q.Advance(); err != nil {
+		t.Fatal(err)
+	}
+	if b, err := q.Peek(); err != nil || string(b) != "bbbbbbbbbb" {
+		t.Fatalf("unexpected record: %q, %v", b, err)
+	}
+
+	// Records that were not advanced past are kept across a restart.
+	if err := q.Close(); err != nil {
+		t.Fatal(err)
+	}
+	q, err = openQueue(dir, 64, 16)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer q.Close()
+	if n := q.Size(); n != 42 {
+		t.Fatalf("unexpected size: %d", n)
+	}
+	for _, exp := range []string{"bbbbbbbbbb", "cccccccccc", "dddddddddd"} {
+		if b, err := q.Peek(); err != nil || string(b) != exp {
+			t.Fatalf("unexpected record: %q, %v", b, err)
+		} else if err := q.Advance(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := q.Peek(); err != io.EOF {
+		t.Fatalf("unexpected error: %v", err)
+	} else if n := q.Size(); n != 0 {
+		t.Fatalf("unexpected size: %d", n)
+	}
+
+	// Consumed segments are removed.
+	if fis, err := ioutil.ReadDir(dir); err != nil {
+		t.Fatal(err)
+	} else if len(fis) != 2 {
+		t.Fatalf("unexpected files: %d", len(fis))
+	}
+}
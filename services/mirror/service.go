@@ -0,0 +1,331 @@
+// Package mirror copies the writes accepted by the server to another server,
+// so that a new server can be filled with the same data before queries are
+// moved to it.
+package mirror // import "github.com/influxdata/influxdb/services/mirror"
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/influxdata/influxdb/coordinator"
+	"github.com/influxdata/influxdb/models"
+	"github.com/uber-go/zap"
+)
+
+// Statistics for the mirror.
+const (
+	statWritesQueued  = "writesQueued"
+	statWritesDropped = "writesDropped"
+	statWritesOK      = "writesOk"
+	statWritesFailed  = "writesFailed"
+	statWritesRetried = "writesRetried"
+	statQueueBytes    = "queueBytes"
+)
+
+// maxRetryInterval is the longest time the mirror waits before a retry.
+const maxRetryInterval = time.Minute
+
+// Service queues the writes committed to the server on disk and sends them
+// to the mirror in order. Writes the mirror rejects are dropped, and writes
+// that fail for any other reason are retried until they succeed.
+type Service struct {
+	Logger zap.Logger
+
+	config    Config
+	url       *url.URL
+	databases map[string]struct{}
+	client    *http.Client
+	points    chan *coordinator.WritePointsRequest
+
+	mu      sync.Mutex
+	queue   *queue
+	notify  chan struct{} // signalled when a write is queued
+	closing chan struct{}
+	wg      sync.WaitGroup
+	stats   *Statistics
+}
+
+// NewService returns a new instance of the mirror.
+func NewService(c Config) *Service {
+	s := &Service{
+		Logger: zap.New(zap.NullEncoder()),
+		config: c,
+		client: &http.Client{Timeout: time.Duration(c.HTTPTimeout)},
+		points: make(chan *coordinator.WritePointsRequest, 100),
+		notify: make(chan struct{}, 1),
+		stats:  &Statistics{},
+	}
+	if len(c.Databases) > 0 {
+		s.databases = make(map[string]struct{}, len(c.Databases))
+		for _, db := range c.Databases {
+			s.databases[db] = struct{}{}
+		}
+	}
+	return s
+}
+
+// WithLogger sets the logger for the service.
+func (s *Service) WithLogger(log zap.Logger) {
+	s.Logger = log.With(zap.String("service", "mirror"))
+}
+
+// Open opens the queue and starts mirroring writes.
+func (s *Service) Open() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closing != nil {
+		return nil
+	}
+
+	u, err := url.Parse(s.config.URL)
+	if err != nil {
+		return err
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/write"
+	s.url = u
+
+	q, err := openQueue(s.config.Dir, int64(s.config.MaxQueueSize), defaultSegmentSize)
+	if err != nil {
+		return err
+	}
+	s.queue = q
+
+	s.Logger.Info(fmt.Sprintf("Starting mirror to %s", s.config.URL))
+	s.closing = make(chan struct{})
+	s.wg.Add(2)
+	go s.enqueue()
+	go s.send()
+	return nil
+}
+
+// Close stops mirroring writes. Queued writes are sent once the service is
+// opened again.
+func (s *Service) Close() error {
+	s.mu.Lock()
+	if s.closing == nil {
+		s.mu.Unlock()
+		return nil
+	}
+	close(s.closing)
+	s.mu.Unlock()
+
+	s.wg.Wait()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closing = nil
+	return s.queue.Close()
+}
+
+// Points returns the channel committed writes are sent to.
+func (s *Service) Points() chan<- *coordinator.WritePointsRequest {
+	return s.points
+}
+
+// Statistics maintains the statistics for the mirror.
+type Statistics struct {
+	WritesQueued  int64
+	WritesDropped int64
+	WritesOK      int64
+	WritesFailed  int64
+	WritesRetried int64
+}
+
+// Statistics returns statistics for periodic monitoring.
+func (s *Service) Statistics(tags map[string]string) []models.Statistic {
+	var queueBytes int64
+	s.mu.Lock()
+	if s.closing != nil {
+		queueBytes = s.queue.Size()
+	}
+	s.mu.Unlock()
+
+	return []models.Statistic{{
+		Name: "mirror",
+		Tags: tags,
+		Values: map[string]interface{}{
+			statWritesQueued:  atomic.LoadInt64(&s.stats.WritesQueued),
+			statWritesDropped: atomic.LoadInt64(&s.stats.WritesDropped),
+			statWritesOK:      atomic.LoadInt64(&s.stats.WritesOK),
+			statWritesFailed:  atomic.LoadInt64(&s.stats.WritesFailed),
+			statWritesRetried: atomic.LoadInt64(&s.stats.WritesRetried),
+			statQueueBytes:    queueBytes,
+		},
+	}}
+}
+
+// enqueue adds committed writes to the queue.
+func (s *Service) enqueue() {
+	defer s.wg.Done()
+	for {
+		select {
+		case <-s.closing:
+			return
+		case req := <-s.points:
+			if len(req.Points) == 0 {
+				continue
+			} else if _, ok := s.databases[req.Database]; s.databases != nil && !ok {
+				continue
+			}
+
+			if err := s.queue.Append(encodeWrite(req)); err == errQueueFull {
+				atomic.AddInt64(&s.stats.WritesDropped, 1)
+				continue
+			} else if err != nil {
+				s.Logger.Info(fmt.Sprintf("failed to queue write to mirror: %s", err))
+				atomic.AddInt64(&s.stats.WritesDropped, 1)
+				continue
+			}
+			atomic.AddInt64(&s.stats.WritesQueued, 1)
+
+			select {
+			case s.notify <- struct{}{}:
+			default:
+			}
+		}
+	}
+}
+
+// send writes the queued writes to the mirror in order.
+func (s *Service) send() {
+	defer s.wg.Done()
+
+	wait := time.Duration(s.config.RetryInterval)
+	for {
+		b, err := s.queue.Peek()
+		if err == io.EOF {
+			select {
+			case <-s.closing:
+				return
+			case <-s.notify:
+				continue
+			}
+		} else if err != nil {
+			s.Logger.Info(fmt.Sprintf("failed to read queued write: %s", err))
+			if !s.sleep(wait) {
+				return
+			}
+			continue
+		}
+
+		if err := s.write(b); err == nil {
+			atomic.AddInt64(&s.stats.WritesOK, 1)
+			wait = time.Duration(s.config.RetryInterval)
+		} else if rejected, ok := err.(rejectedError); ok {
+			s.Logger.Info(fmt.Sprintf("mirror rejected write: %s", rejected))
+			atomic.AddInt64(&s.stats.WritesFailed, 1)
+		} else {
+			s.Logger.Info(fmt.Sprintf("failed to write to mirror, retrying in %s: %s", wait, err))
+			atomic.AddInt64(&s.stats.WritesRetried, 1)
+			if !s.sleep(wait) {
+				return
+			}
+			if wait *= 2; wait > maxRetryInterval {
+				wait = maxRetryInterval
+			}
+			continue
+		}
+
+		if err := s.queue.Advance(); err != nil {
+			s.Logger.Info(fmt.Sprintf("failed to remove write from queue: %s", err))
+		}
+	}
+}
+
+// sleep waits for d, and returns false if the service closes before.
+func (s *Service) sleep(d time.Duration) bool {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-s.closing:
+		return false
+	case <-t.C:
+		return true
+	}
+}
+
+// rejectedError is returned for a write that the mirror will never accept.
+type rejectedError string
+
+func (e rejectedError) Error() string { return string(e) }
+
+// write sends a queued write to the mirror.
+func (s *Service) write(b []byte) error {
+	db, rp, body, err := decodeWrite(b)
+	if err != nil {
+		return rejectedError(err.Error())
+	}
+
+	u := *s.url
+	params := url.Values{"db": {db}, "precision": {"n"}}
+	if rp != "" {
+		params.Set("rp", rp)
+	}
+	u.RawQuery = params.Encode()
+
+	req, err := http.NewRequest("POST", u.String(), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	if s.config.Username != "" {
+		req.SetBasicAuth(s.config.Username, s.config.Password)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	msg, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+
+	switch {
+	case resp.StatusCode/100 == 2:
+		return nil
+	case resp.StatusCode == http.StatusBadRequest:
+		// Points the mirror cannot parse or store would fail every retry.
+		return rejectedError(fmt.Sprintf("%s: %s", resp.Status, bytes.TrimSpace(msg)))
+	default:
+		return fmt.Errorf("%s: %s", resp.Status, bytes.TrimSpace(msg))
+	}
+}
+
+// encodeWrite encodes a write as the database and retention policy followed
+// by its points in line protocol.
+func encodeWrite(req *coordinator.WritePointsRequest) []byte {
+	var buf []byte
+	for _, s := range []string{req.Database, req.RetentionPolicy} {
+		var n [binary.MaxVarintLen64]byte
+		buf = append(buf, n[:binary.PutUvarint(n[:], uint64(len(s)))]...)
+		buf = append(buf, s...)
+	}
+	for _, p := range req.Points {
+		buf = p.AppendString(buf)
+		buf = append(buf, '\n')
+	}
+	return buf
+}
+
+// decodeWrite decodes a write encoded by encodeWrite.
+func decodeWrite(b []byte) (db, rp string, points []byte, err error) {
+	var fields [2]string
+	for i := range fields {
+		n, sz := binary.Uvarint(b)
+		if sz <= 0 || uint64(len(b)-sz) < n {
+			return "", "", nil, errors.New("invalid queued write")
+		}
+		fields[i] = string(b[sz : sz+int(n)])
+		b = b[sz+int(n):]
+	}
+	return fields[0], fields[1], b, nil
+}
@@ -0,0 +1,106 @@
+package mirror_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/coordinator"
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/services/mirror"
+	"github.com/influxdata/influxdb/toml"
+)
+
+func TestService_Mirror(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mirror")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	var mu sync.Mutex
+	var writes []string
+	var failures int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		// The first write fails and is retried.
+		if failures++; failures == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if r.URL.Path != "/write" || r.URL.Query().Get("precision") != "n" {
+			t.Errorf("unexpected request: %s", r.URL)
+		} else if u, p, _ := r.BasicAuth(); u != "admin" || p != "secret" {
+			t.Errorf("unexpected credentials: %s:%s", u, p)
+		}
+		body, _ := ioutil.ReadAll(r.Body)
+		writes = append(writes, r.URL.Query().Get("db")+"."+r.URL.Query().Get("rp")+" "+string(body))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer ts.Close()
+
+	c := mirror.NewConfig()
+	c.Enabled = true
+	c.URL = ts.URL
+	c.Username = "admin"
+	c.Password = "secret"
+	c.Databases = []string{"db0"}
+	c.Dir = dir
+	c.RetryInterval = toml.Duration(10 * time.Millisecond)
+	s := mirror.NewService(c)
+	if err := s.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	write := func(db, line string) {
+		points, err := models.ParsePointsString(line)
+		if err != nil {
+			t.Fatal(err)
+		}
+		s.Points() <- &coordinator.WritePointsRequest{
+			Database:        db,
+			RetentionPolicy: "autogen",
+			Points:          points,
+		}
+	}
+	write("db0", "cpu value=1 1\ncpu value=2 2")
+	write("db1", "cpu value=3 3")
+	write("db0", "mem value=4 4")
+
+	exp := []string{
+		"db0.autogen cpu value=1 1\ncpu value=2 2\n",
+		"db0.autogen mem value=4 4\n",
+	}
+	timeout := time.Now().Add(5 * time.Second)
+	for {
+		if s.Statistics(nil)[0].Values["writesOk"] == int64(len(exp)) {
+			break
+		} else if time.Now().After(timeout) {
+			t.Fatal("timed out waiting for writes")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(writes) != len(exp) {
+		t.Fatalf("unexpected writes: %q", writes)
+	}
+	for i := range exp {
+		if writes[i] != exp[i] {
+			t.Fatalf("unexpected write %d: %q", i, writes[i])
+		}
+	}
+
+	stats := s.Statistics(nil)[0].Values
+	if stats["writesRetried"] != int64(1) {
+		t.Fatalf("unexpected statistics: %v", stats)
+	}
+}
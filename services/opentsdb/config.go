@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"github.com/influxdata/influxdb/monitor/diagnostics"
+	"github.com/influxdata/influxdb/pkg/ipfilter"
 	"github.com/influxdata/influxdb/toml"
 )
 
@@ -46,6 +47,10 @@ type Config struct {
 	BatchPending     int           `toml:"batch-pending"`
 	BatchTimeout     toml.Duration `toml:"batch-timeout"`
 	LogPointErrors   bool          `toml:"log-point-errors"`
+	MaxConnections   int           `toml:"max-connections"`
+	IdleTimeout      toml.Duration `toml:"idle-timeout"`
+	AllowedNetworks  []string      `toml:"allowed-networks"`
+	DeniedNetworks   []string      `toml:"denied-networks"`
 }
 
 // NewConfig returns a new config for the service.
@@ -64,6 +69,12 @@ func NewConfig() Config {
 	}
 }
 
+// Validate returns an error if the config is invalid.
+func (c *Config) Validate() error {
+	_, err := ipfilter.New(c.AllowedNetworks, c.DeniedNetworks)
+	return err
+}
+
 // WithDefaults takes the given config and returns a new config with any required
 // default values set.
 func (c *Config) WithDefaults() *Config {
@@ -102,6 +102,7 @@ func (h *Handler) servePut(w http.ResponseWriter, r *http.Request) {
 
 	// Convert points into TSDB points.
 	points := make([]models.Point, 0, len(dps))
+	var fields models.FieldsBuilder
 	for i := range dps {
 		p := dps[i]
 
@@ -114,7 +115,9 @@ func (h *Handler) servePut(w http.ResponseWriter, r *http.Request) {
 			ts = time.Unix(p.Time/1000, (p.Time%1000)*1000)
 		}
 
-		pt, err := models.NewPoint(p.Metric, models.NewTags(p.Tags), map[string]interface{}{"value": p.Value}, ts)
+		fields.Reset()
+		fields.AddFloat("value", p.Value)
+		pt, err := models.NewPointWithFields(p.Metric, models.NewTags(p.Tags), &fields, ts)
 		if err != nil {
 			h.Logger.Info(fmt.Sprintf("Dropping point %v: %v", p.Metric, err))
 			if h.stats != nil {
@@ -189,6 +192,21 @@ type readerConn struct {
 // Read implements the io.Reader interface.
 func (conn *readerConn) Read(b []byte) (n int, err error) { return conn.r.Read(b) }
 
+// countedConn is a connection counted against the maximum number of
+// connections. It calls release once when it is closed.
+type countedConn struct {
+	net.Conn
+	once    sync.Once
+	release func()
+}
+
+// Close closes the connection and releases it.
+func (conn *countedConn) Close() error {
+	err := conn.Conn.Close()
+	conn.once.Do(conn.release)
+	return err
+}
+
 // point represents an incoming JSON data point.
 type point struct {
 	Metric string            `json:"metric"`
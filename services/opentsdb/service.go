@@ -17,6 +17,7 @@ import (
 	"time"
 
 	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/pkg/ipfilter"
 	"github.com/influxdata/influxdb/services/meta"
 	"github.com/influxdata/influxdb/tsdb"
 	"github.com/uber-go/zap"
@@ -40,10 +41,13 @@ const (
 	statConnectionsActive        = "connsActive"
 	statConnectionsHandled       = "connsHandled"
 	statDroppedPointsInvalid     = "droppedPointsInvalid"
+	statConnectionsRejected      = "connsRejected"
 )
 
 // Service manages the listener and handler for an HTTP endpoint.
 type Service struct {
+	conns int64 // open connections counted against maxConnections
+
 	ln     net.Listener  // main listener
 	httpln *chanListener // http channel-based listener
 
@@ -72,6 +76,11 @@ type Service struct {
 	batchTimeout time.Duration
 	batcher      *tsdb.PointBatcher
 
+	// Limits of the connections of clients.
+	maxConnections int
+	idleTimeout    time.Duration
+	filter         *ipfilter.Filter
+
 	LogPointErrors bool
 	Logger         zap.Logger
 
@@ -84,6 +93,11 @@ func NewService(c Config) (*Service, error) {
 	// Use defaults where necessary.
 	d := c.WithDefaults()
 
+	filter, err := ipfilter.New(d.AllowedNetworks, d.DeniedNetworks)
+	if err != nil {
+		return nil, err
+	}
+
 	s := &Service{
 		tls:             d.TLSEnabled,
 		cert:            d.Certificate,
@@ -93,6 +107,9 @@ func NewService(c Config) (*Service, error) {
 		batchSize:       d.BatchSize,
 		batchPending:    d.BatchPending,
 		batchTimeout:    time.Duration(d.BatchTimeout),
+		maxConnections:  d.MaxConnections,
+		idleTimeout:     time.Duration(d.IdleTimeout),
+		filter:          filter,
 		Logger:          zap.New(zap.NullEncoder()),
 		LogPointErrors:  d.LogPointErrors,
 		stats:           &Statistics{},
@@ -252,6 +269,7 @@ type Statistics struct {
 	ActiveConnections        int64
 	HandledConnections       int64
 	InvalidDroppedPoints     int64
+	RejectedConnections      int64
 }
 
 // Statistics returns statistics for periodic monitoring.
@@ -275,6 +293,7 @@ func (s *Service) Statistics(tags map[string]string) []models.Statistic {
 			statBatchesTransmitFail:      atomic.LoadInt64(&s.stats.BatchesTransmitFail),
 			statConnectionsActive:        atomic.LoadInt64(&s.stats.ActiveConnections),
 			statConnectionsHandled:       atomic.LoadInt64(&s.stats.HandledConnections),
+			statConnectionsRejected:      atomic.LoadInt64(&s.stats.RejectedConnections),
 			statDroppedPointsInvalid:     atomic.LoadInt64(&s.stats.InvalidDroppedPoints),
 		},
 	}}
@@ -301,6 +320,25 @@ func (s *Service) serve() {
 			continue
 		}
 
+		// Drop connections from outside of the allowed networks.
+		if !s.filter.AllowedAddr(conn.RemoteAddr()) {
+			atomic.AddInt64(&s.stats.RejectedConnections, 1)
+			conn.Close()
+			continue
+		}
+
+		// Drop connections over the limit. HTTP and telnet connections
+		// count until they are closed.
+		if s.maxConnections > 0 {
+			if atomic.AddInt64(&s.conns, 1) > int64(s.maxConnections) {
+				atomic.AddInt64(&s.conns, -1)
+				atomic.AddInt64(&s.stats.RejectedConnections, 1)
+				conn.Close()
+				continue
+			}
+			conn = &countedConn{Conn: conn, release: func() { atomic.AddInt64(&s.conns, -1) }}
+		}
+
 		// Handle connection in separate goroutine.
 		go s.handleConn(conn)
 	}
@@ -312,6 +350,11 @@ func (s *Service) handleConn(conn net.Conn) {
 	atomic.AddInt64(&s.stats.ActiveConnections, 1)
 	atomic.AddInt64(&s.stats.HandledConnections, 1)
 
+	// Do not wait forever for the first request of the client.
+	if s.idleTimeout > 0 {
+		conn.SetReadDeadline(time.Now().Add(s.idleTimeout))
+	}
+
 	// Read header into buffer to check if it's HTTP.
 	var buf bytes.Buffer
 	r := bufio.NewReader(io.TeeReader(conn, &buf))
@@ -351,6 +394,11 @@ func (s *Service) handleTelnetConn(conn net.Conn) {
 	// Wrap connection in a text protocol reader.
 	r := textproto.NewReader(bufio.NewReader(conn))
 	for {
+		// Close connections that stay idle.
+		if s.idleTimeout > 0 {
+			conn.SetReadDeadline(time.Now().Add(s.idleTimeout))
+		}
+
 		line, err := r.ReadLine()
 		if err != nil {
 			if err != io.EOF {
@@ -419,7 +467,6 @@ func (s *Service) handleTelnetConn(conn net.Conn) {
 			tags[k] = parts[1]
 		}
 
-		fields := make(map[string]interface{})
 		fv, err := strconv.ParseFloat(valueStr, 64)
 		if err != nil {
 			atomic.AddInt64(&s.stats.TelnetBadFloat, 1)
@@ -428,9 +475,10 @@ func (s *Service) handleTelnetConn(conn net.Conn) {
 			}
 			continue
 		}
-		fields["value"] = fv
 
-		pt, err := models.NewPoint(measurement, models.NewTags(tags), fields, t)
+		var fields models.FieldsBuilder
+		fields.AddFloat("value", fv)
+		pt, err := models.NewPointWithFields(measurement, models.NewTags(tags), &fields, t)
 		if err != nil {
 			atomic.AddInt64(&s.stats.TelnetBadFloat, 1)
 			if s.LogPointErrors {
@@ -451,7 +499,11 @@ func (s *Service) serveHTTP() {
 		Logger:          s.Logger,
 		stats:           s.stats,
 	}
-	srv := &http.Server{Handler: handler}
+	srv := &http.Server{
+		Handler:           handler,
+		ReadHeaderTimeout: s.idleTimeout,
+		IdleTimeout:       s.idleTimeout,
+	}
 	srv.Serve(s.httpln)
 }
 
@@ -198,6 +198,46 @@ func TestService_Telnet(t *testing.T) {
 	}
 }
 
+// Ensure idle connections are closed and connections over the limit are rejected.
+func TestService_Telnet_Limits(t *testing.T) {
+	t.Parallel()
+
+	s := NewTestService("db0", "127.0.0.1:0")
+	s.Service.maxConnections = 1
+	s.Service.idleTimeout = 100 * time.Millisecond
+	if err := s.Service.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Service.Close()
+
+	conn, err := net.Dial("tcp", s.Service.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	// A second connection is closed by the server while the first is open.
+	other, err := net.Dial("tcp", s.Service.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer other.Close()
+	other.SetReadDeadline(time.Now().Add(5 * time.Second))
+	if _, err := other.Read(make([]byte, 1)); err == nil {
+		t.Fatal("expected rejected connection to be closed")
+	} else if e, ok := err.(net.Error); ok && e.Timeout() {
+		t.Fatal("rejected connection was not closed")
+	}
+
+	// The first connection is closed once it has been idle for too long.
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	if _, err := conn.Read(make([]byte, 1)); err == nil {
+		t.Fatal("expected idle connection to be closed")
+	} else if e, ok := err.(net.Error); ok && e.Timeout() {
+		t.Fatal("idle connection was not closed")
+	}
+}
+
 // Ensure a point can be written via the HTTP protocol.
 func TestService_HTTP(t *testing.T) {
 	t.Parallel()
@@ -6,6 +6,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/influxdata/influxdb/pkg/clock"
 	"github.com/uber-go/zap"
 )
 
@@ -16,6 +17,11 @@ type Service struct {
 
 	Logger zap.Logger
 
+	// Clock provides the time shard groups are precreated from and the
+	// interval between checks. Tests can replace it before the service is
+	// opened.
+	Clock clock.Clock
+
 	done chan struct{}
 	wg   sync.WaitGroup
 
@@ -30,6 +36,7 @@ func NewService(c Config) (*Service, error) {
 		checkInterval: time.Duration(c.CheckInterval),
 		advancePeriod: time.Duration(c.AdvancePeriod),
 		Logger:        zap.New(zap.NullEncoder()),
+		Clock:         clock.New(),
 	}
 
 	return &s, nil
@@ -75,8 +82,8 @@ func (s *Service) runPrecreation() {
 
 	for {
 		select {
-		case <-time.After(s.checkInterval):
-			if err := s.precreate(time.Now().UTC()); err != nil {
+		case <-s.Clock.After(s.checkInterval):
+			if err := s.precreate(s.Clock.Now().UTC()); err != nil {
 				s.Logger.Info(fmt.Sprintf("failed to precreate shards: %s", err.Error()))
 			}
 		case <-s.done:
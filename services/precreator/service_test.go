@@ -5,6 +5,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/influxdata/influxdb/pkg/clock"
 	"github.com/influxdata/influxdb/toml"
 )
 
@@ -45,6 +46,46 @@ func Test_ShardPrecreation(t *testing.T) {
 	return
 }
 
+// Ensure shards are precreated after each check interval.
+func Test_ShardPrecreation_CheckInterval(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	mock := clock.NewMock(now)
+
+	called := make(chan time.Time)
+	srv, err := NewService(Config{
+		CheckInterval: toml.Duration(10 * time.Minute),
+		AdvancePeriod: toml.Duration(30 * time.Minute),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv.Clock = mock
+	srv.MetaClient = metaClient{
+		PrecreateShardGroupsFn: func(v, u time.Time) error {
+			if u != v.Add(30*time.Minute) {
+				t.Errorf("unexpected cutoff: %s", u)
+			}
+			called <- v
+			return nil
+		},
+	}
+
+	if err := srv.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Close()
+
+	for i := 1; i <= 2; i++ {
+		mock.BlockUntil(1)
+		mock.Add(10 * time.Minute)
+		if v := <-called; !v.Equal(now.Add(time.Duration(i) * 10 * time.Minute)) {
+			t.Fatalf("unexpected time of check %d: %s", i, v)
+		}
+	}
+}
+
 // PointsWriter represents a mock impl of PointsWriter.
 type metaClient struct {
 	PrecreateShardGroupsFn func(now, cutoff time.Time) error
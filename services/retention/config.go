@@ -12,6 +12,10 @@ import (
 type Config struct {
 	Enabled       bool          `toml:"enabled"`
 	CheckInterval toml.Duration `toml:"check-interval"`
+
+	// DryRun reports the shard groups and shards that have expired without
+	// deleting them.
+	DryRun bool `toml:"dry-run"`
 }
 
 // NewConfig returns an instance of Config with defaults.
@@ -45,5 +49,6 @@ func (c Config) Diagnostics() (*diagnostics.Diagnostics, error) {
 	return diagnostics.RowFromMap(map[string]interface{}{
 		"enabled":        true,
 		"check-interval": c.CheckInterval,
+		"dry-run":        c.DryRun,
 	}), nil
 }
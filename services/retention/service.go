@@ -3,10 +3,13 @@ package retention // import "github.com/influxdata/influxdb/services/retention"
 
 import (
 	"fmt"
+	"sort"
 	"sync"
 	"time"
 
+	"github.com/influxdata/influxdb/pkg/clock"
 	"github.com/influxdata/influxdb/services/meta"
+	"github.com/influxdata/influxdb/tsdb"
 	"github.com/uber-go/zap"
 )
 
@@ -19,9 +22,14 @@ type Service struct {
 	}
 	TSDBStore interface {
 		ShardIDs() []uint64
+		Shard(id uint64) *tsdb.Shard
 		DeleteShard(shardID uint64) error
 	}
 
+	// Clock provides the time shard groups expire against and the ticks
+	// of the checks. Tests can replace it before the service is opened.
+	Clock clock.Clock
+
 	config Config
 	wg     sync.WaitGroup
 	done   chan struct{}
@@ -32,6 +40,7 @@ type Service struct {
 // NewService returns a configured retention policy enforcement service.
 func NewService(c Config) *Service {
 	return &Service{
+		Clock:  clock.New(),
 		config: c,
 		logger: zap.New(zap.NullEncoder()),
 	}
@@ -44,6 +53,9 @@ func (s *Service) Open() error {
 	}
 
 	s.logger.Info(fmt.Sprint("Starting retention policy enforcement service with check interval of ", s.config.CheckInterval))
+	if s.config.DryRun {
+		s.logger.Info("Retention policy enforcement is in dry-run mode; expired shards are reported but not deleted.")
+	}
 	s.done = make(chan struct{})
 
 	s.wg.Add(1)
@@ -71,7 +83,7 @@ func (s *Service) WithLogger(log zap.Logger) {
 }
 
 func (s *Service) run() {
-	ticker := time.NewTicker(time.Duration(s.config.CheckInterval))
+	ticker := s.Clock.NewTicker(time.Duration(s.config.CheckInterval))
 	defer ticker.Stop()
 	for {
 		select {
@@ -86,17 +98,28 @@ func (s *Service) run() {
 				rp string
 			}
 			deletedShardIDs := make(map[uint64]deletionInfo, 0)
+			summaries := make(map[deletionInfo]*deletionSummary)
+			summary := func(info deletionInfo) *deletionSummary {
+				if summaries[info] == nil {
+					summaries[info] = &deletionSummary{}
+				}
+				return summaries[info]
+			}
 
 			dbs := s.MetaClient.Databases()
 			for _, d := range dbs {
 				for _, r := range d.RetentionPolicies {
-					for _, g := range r.ExpiredShardGroups(time.Now().UTC()) {
-						if err := s.MetaClient.DeleteShardGroup(d.Name, r.Name, g.ID); err != nil {
+					for _, g := range r.ExpiredShardGroups(s.Clock.Now().UTC()) {
+						if s.config.DryRun {
+							s.logger.Info(fmt.Sprintf("Dry run: would delete shard group %d from database %s, retention policy %s.", g.ID, d.Name, r.Name))
+						} else if err := s.MetaClient.DeleteShardGroup(d.Name, r.Name, g.ID); err != nil {
 							s.logger.Info(fmt.Sprintf("Failed to delete shard group %d from database %s, retention policy %s: %v. Retry in %v.", g.ID, d.Name, r.Name, err, s.config.CheckInterval))
 							continue
+						} else {
+							s.logger.Info(fmt.Sprintf("Deleted shard group %d from database %s, retention policy %s.", g.ID, d.Name, r.Name))
 						}
-
-						s.logger.Info(fmt.Sprintf("Deleted shard group %d from database %s, retention policy %s.", g.ID, d.Name, r.Name))
+						sum := summary(deletionInfo{db: d.Name, rp: r.Name})
+						sum.shardGroupIDs = append(sum.shardGroupIDs, g.ID)
 
 						// Store all the shard IDs that may possibly need to be removed locally.
 						for _, sh := range g.Shards {
@@ -109,17 +132,61 @@ func (s *Service) run() {
 			// Remove shards if we store them locally
 			for _, id := range s.TSDBStore.ShardIDs() {
 				if info, ok := deletedShardIDs[id]; ok {
-					if err := s.TSDBStore.DeleteShard(id); err != nil {
+					var size int64
+					if sh := s.TSDBStore.Shard(id); sh != nil {
+						size, _ = sh.DiskSize()
+					}
+
+					if s.config.DryRun {
+						s.logger.Info(fmt.Sprintf("Dry run: would delete shard ID %d from database %s, retention policy %s, freeing %d bytes.", id, info.db, info.rp, size))
+					} else if err := s.TSDBStore.DeleteShard(id); err != nil {
 						s.logger.Error(fmt.Sprintf("Failed to delete shard ID %d from database %s, retention policy %s: %v. Will retry in %v", id, info.db, info.rp, err, s.config.CheckInterval))
 						continue
+					} else {
+						s.logger.Info(fmt.Sprintf("Shard ID %d from database %s, retention policy %s, deleted.", id, info.db, info.rp))
 					}
-					s.logger.Info(fmt.Sprintf("Shard ID %d from database %s, retention policy %s, deleted.", id, info.db, info.rp))
+					sum := summary(info)
+					sum.shardIDs = append(sum.shardIDs, id)
+					sum.bytes += size
 				}
 			}
 
-			if err := s.MetaClient.PruneShardGroups(); err != nil {
-				s.logger.Info(fmt.Sprintf("Problem pruning shard groups: %s. Will retry in %v", err, s.config.CheckInterval))
+			if !s.config.DryRun {
+				if err := s.MetaClient.PruneShardGroups(); err != nil {
+					s.logger.Info(fmt.Sprintf("Problem pruning shard groups: %s. Will retry in %v", err, s.config.CheckInterval))
+				}
 			}
+
+			// Log what the check deleted, so that missing data can be traced
+			// back to the retention policy that removed it.
+			verb, freed := "Deleted", "freed"
+			if s.config.DryRun {
+				verb, freed = "Dry run: would delete", "would be freed"
+			}
+			infos := make([]deletionInfo, 0, len(summaries))
+			for info := range summaries {
+				infos = append(infos, info)
+			}
+			sort.Slice(infos, func(i, j int) bool {
+				if infos[i].db != infos[j].db {
+					return infos[i].db < infos[j].db
+				}
+				return infos[i].rp < infos[j].rp
+			})
+			var total int64
+			for _, info := range infos {
+				sum := summaries[info]
+				s.logger.Info(fmt.Sprintf("%s shard groups %v and shards %v from database %s, retention policy %s, freeing %d bytes.", verb, sum.shardGroupIDs, sum.shardIDs, info.db, info.rp, sum.bytes))
+				total += sum.bytes
+			}
+			s.logger.Info(fmt.Sprintf("Retention policy shard deletion check complete: %d retention policies with expired data, %d bytes %s.", len(infos), total, freed))
 		}
 	}
 }
+
+// deletionSummary is what a check deleted from a retention policy.
+type deletionSummary struct {
+	shardGroupIDs []uint64
+	shardIDs      []uint64
+	bytes         int64
+}
@@ -2,8 +2,10 @@ package retention_test
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"reflect"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -12,6 +14,7 @@ import (
 	"github.com/influxdata/influxdb/services/meta"
 	"github.com/influxdata/influxdb/services/retention"
 	"github.com/influxdata/influxdb/toml"
+	"github.com/influxdata/influxdb/tsdb"
 	"github.com/uber-go/zap"
 )
 
@@ -57,6 +60,81 @@ func TestService_OpenClose(t *testing.T) {
 	}
 }
 
+// Ensure a dry run reports expired shard groups and shards without deleting them.
+func TestService_DryRun(t *testing.T) {
+	c := retention.NewConfig()
+	c.CheckInterval = toml.Duration(time.Millisecond)
+	c.DryRun = true
+	s := NewService(c)
+	errC := make(chan error, 1)
+
+	var checks int
+	s.MetaClient.DatabasesFn = func() []meta.DatabaseInfo {
+		// The first check is complete once the second one starts.
+		if checks++; checks == 2 {
+			select {
+			case errC <- nil:
+			default:
+			}
+		}
+		return []meta.DatabaseInfo{
+			{
+				Name: "db0",
+				RetentionPolicies: []meta.RetentionPolicyInfo{
+					{
+						Name:               "rp0",
+						Duration:           time.Hour,
+						ShardGroupDuration: time.Hour,
+						ShardGroups: []meta.ShardGroupInfo{
+							{
+								ID:        1,
+								StartTime: time.Date(1980, 1, 1, 0, 0, 0, 0, time.UTC),
+								EndTime:   time.Date(1980, 1, 1, 1, 0, 0, 0, time.UTC),
+								Shards:    []meta.ShardInfo{{ID: 2}},
+							},
+						},
+					},
+				},
+			},
+		}
+	}
+	s.MetaClient.DeleteShardGroupFn = func(database string, policy string, id uint64) error {
+		select {
+		case errC <- fmt.Errorf("unexpected deletion of shard group %d", id):
+		default:
+		}
+		return nil
+	}
+	s.MetaClient.PruneShardGroupsFn = func() error {
+		select {
+		case errC <- errors.New("unexpected pruning of shard groups"):
+		default:
+		}
+		return nil
+	}
+	s.TSDBStore.ShardIDsFn = func() []uint64 { return []uint64{2} }
+	s.TSDBStore.DeleteShardFn = func(id uint64) error {
+		select {
+		case errC <- fmt.Errorf("unexpected deletion of shard %d", id):
+		default:
+		}
+		return nil
+	}
+
+	if err := s.Open(); err != nil {
+		t.Fatal(err)
+	}
+	err := <-errC
+	s.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if exp := "Dry run: would delete shard groups [1] and shards [2] from database db0, retention policy rp0, freeing 0 bytes."; !strings.Contains(s.LogBuf.String(), exp) {
+		t.Fatalf("summary %q not logged:\n%s", exp, s.LogBuf.String())
+	}
+}
+
 // This reproduces https://github.com/influxdata/influxdb/issues/8819
 func TestService_8819_repro(t *testing.T) {
 	for i := 0; i < 1000; i++ {
@@ -228,6 +306,8 @@ func NewService(c retention.Config) *Service {
 	)
 	s.WithLogger(l)
 
+	s.TSDBStore.ShardFn = func(id uint64) *tsdb.Shard { return nil }
+
 	s.Service.MetaClient = s.MetaClient
 	s.Service.TSDBStore = s.TSDBStore
 	return s
@@ -20,6 +20,10 @@ const (
 
 	// DefaultWriteBufferSize is the default write buffer size for a Config.
 	DefaultWriteBufferSize = 1000
+
+	// DefaultRetryInterval is the default time to wait before retrying a
+	// failed write for the first time.
+	DefaultRetryInterval = time.Second
 )
 
 // Config represents a configuration of the subscriber service.
@@ -42,6 +46,11 @@ type Config struct {
 
 	// The number of in-flight writes buffered in the write channel.
 	WriteBufferSize int `toml:"write-buffer-size"`
+
+	// The number of times a failed write to a destination is retried. The
+	// wait before each retry starts at RetryInterval and doubles every time.
+	WriteRetries  int           `toml:"write-retries"`
+	RetryInterval toml.Duration `toml:"retry-interval"`
 }
 
 // NewConfig returns a new instance of a subscriber config.
@@ -53,6 +62,7 @@ func NewConfig() Config {
 		CaCerts:            "",
 		WriteConcurrency:   DefaultWriteConcurrency,
 		WriteBufferSize:    DefaultWriteBufferSize,
+		RetryInterval:      toml.Duration(DefaultRetryInterval),
 	}
 }
 
@@ -78,6 +88,14 @@ func (c Config) Validate() error {
 		return errors.New("write-concurrency must be greater than 0")
 	}
 
+	if c.WriteRetries < 0 {
+		return errors.New("write-retries must not be negative")
+	}
+
+	if c.WriteRetries > 0 && c.RetryInterval <= 0 {
+		return errors.New("retry-interval must be greater than 0")
+	}
+
 	return nil
 }
 
@@ -99,5 +117,7 @@ func (c Config) Diagnostics() (*diagnostics.Diagnostics, error) {
 		"http-timeout":      c.HTTPTimeout,
 		"write-concurrency": c.WriteConcurrency,
 		"write-buffer-size": c.WriteBufferSize,
+		"write-retries":     c.WriteRetries,
+		"retry-interval":    c.RetryInterval,
 	}), nil
 }
@@ -51,6 +51,11 @@ func (h *HTTP) WritePoints(p *coordinator.WritePointsRequest) (err error) {
 		bp.AddPoint(client.NewPointFrom(pt))
 	}
 	err = h.c.Write(bp)
+	if err != nil {
+		// Drop the kept-alive connections so that the next write connects
+		// again and resolves the address of the destination anew.
+		h.c.Close()
+	}
 	return
 }
 
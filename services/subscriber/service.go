@@ -22,6 +22,7 @@ const (
 	statCreateFailures = "createFailures"
 	statPointsWritten  = "pointsWritten"
 	statWriteFailures  = "writeFailures"
+	statPointsDropped  = "pointsDropped"
 )
 
 // PointsWriter is an interface for writing points to a subscription destination.
@@ -135,6 +136,7 @@ type Statistics struct {
 	CreateFailures int64
 	PointsWritten  int64
 	WriteFailures  int64
+	PointsDropped  int64
 }
 
 // Statistics returns statistics for periodic monitoring.
@@ -146,6 +148,7 @@ func (s *Service) Statistics(tags map[string]string) []models.Statistic {
 			statCreateFailures: atomic.LoadInt64(&s.stats.CreateFailures),
 			statPointsWritten:  atomic.LoadInt64(&s.stats.PointsWritten),
 			statWriteFailures:  atomic.LoadInt64(&s.stats.WriteFailures),
+			statPointsDropped:  atomic.LoadInt64(&s.stats.PointsDropped),
 		},
 	}}
 
@@ -206,6 +209,14 @@ func (s *Service) createSubscription(se subEntry, mode string, destinations []st
 		if err != nil {
 			return nil, fmt.Errorf("failed to create writer for destination: %s", dest)
 		}
+		if s.conf.WriteRetries > 0 {
+			w = &retryWriter{
+				w:        w,
+				retries:  s.conf.WriteRetries,
+				interval: time.Duration(s.conf.RetryInterval),
+				closing:  s.closing,
+			}
+		}
 		writers = append(writers, w)
 		stats = append(stats, writerStats{dest: dest})
 	}
@@ -249,7 +260,10 @@ func (s *Service) run() {
 					select {
 					case cw.writeRequests <- p:
 					default:
+						// The queue of the subscription is full.
 						atomic.AddInt64(&s.stats.WriteFailures, 1)
+						atomic.AddInt64(&s.stats.PointsDropped, int64(len(p.Points)))
+						atomic.AddInt64(cw.pointsDropped, int64(len(p.Points)))
 					}
 				}
 			}
@@ -304,6 +318,7 @@ func (s *Service) updateSubs(wg *sync.WaitGroup) {
 					pw:            sub,
 					pointsWritten: &s.stats.PointsWritten,
 					failures:      &s.stats.WriteFailures,
+					pointsDropped: new(int64),
 					logger:        s.Logger,
 				}
 				for i := 0; i < s.conf.WriteConcurrency; i++ {
@@ -356,6 +371,10 @@ type chanWriter struct {
 	pointsWritten *int64
 	failures      *int64
 	logger        zap.Logger
+
+	// pointsDropped counts the points of this subscription that were
+	// dropped because its queue was full.
+	pointsDropped *int64
 }
 
 // Close closes the chanWriter.
@@ -376,13 +395,49 @@ func (c chanWriter) Run() {
 }
 
 // Statistics returns statistics for periodic monitoring.
+// The points dropped by the subscription are reported for every destination.
 func (c chanWriter) Statistics(tags map[string]string) []models.Statistic {
 	if m, ok := c.pw.(monitor.Reporter); ok {
-		return m.Statistics(tags)
+		statistics := m.Statistics(tags)
+		dropped := atomic.LoadInt64(c.pointsDropped)
+		for i := range statistics {
+			statistics[i].Values[statPointsDropped] = dropped
+		}
+		return statistics
 	}
 	return []models.Statistic{}
 }
 
+// maxRetryInterval is the longest time a retryWriter waits before a retry.
+const maxRetryInterval = time.Minute
+
+// retryWriter retries the failed writes to a destination with an increasing
+// delay. The HTTP and UDP writers resolve the address of their destination
+// again when they reconnect, so writes reach a destination that moved.
+type retryWriter struct {
+	w        PointsWriter
+	retries  int
+	interval time.Duration
+	closing  <-chan struct{}
+}
+
+func (r *retryWriter) WritePoints(p *coordinator.WritePointsRequest) error {
+	err := r.w.WritePoints(p)
+	for i, d := 0, r.interval; err != nil && i < r.retries; i++ {
+		select {
+		case <-r.closing:
+			return err
+		case <-time.After(d):
+		}
+
+		if d *= 2; d > maxRetryInterval {
+			d = maxRetryInterval
+		}
+		err = r.w.WritePoints(p)
+	}
+	return err
+}
+
 // BalanceMode specifies what balance mode to use on a subscription.
 type BalanceMode int
 
@@ -1,13 +1,16 @@
 package subscriber_test
 
 import (
+	"errors"
 	"net/url"
 	"testing"
 	"time"
 
 	"github.com/influxdata/influxdb/coordinator"
+	"github.com/influxdata/influxdb/models"
 	"github.com/influxdata/influxdb/services/meta"
 	"github.com/influxdata/influxdb/services/subscriber"
+	"github.com/influxdata/influxdb/toml"
 )
 
 type MetaClient struct {
@@ -184,6 +187,182 @@ func TestService_ModeALL(t *testing.T) {
 	close(dataChanged)
 }
 
+func TestService_WriteRetries(t *testing.T) {
+	dataChanged := make(chan struct{})
+	ms := MetaClient{}
+	ms.WaitForDataChangedFn = func() chan struct{} {
+		return dataChanged
+	}
+	ms.DatabasesFn = func() []meta.DatabaseInfo {
+		return []meta.DatabaseInfo{
+			{
+				Name: "db0",
+				RetentionPolicies: []meta.RetentionPolicyInfo{
+					{
+						Name: "rp0",
+						Subscriptions: []meta.SubscriptionInfo{
+							{Name: "s0", Mode: "ALL", Destinations: []string{"udp://h0:9093"}},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	// The destination fails twice before it accepts the write.
+	prs := make(chan *coordinator.WritePointsRequest, 3)
+	urls := make(chan url.URL, 1)
+	var n int
+	newPointsWriter := func(u url.URL) (subscriber.PointsWriter, error) {
+		sub := Subscription{}
+		sub.WritePointsFn = func(p *coordinator.WritePointsRequest) error {
+			prs <- p
+			if n++; n < 3 {
+				return errors.New("connection refused")
+			}
+			return nil
+		}
+		urls <- u
+		return sub, nil
+	}
+
+	c := subscriber.NewConfig()
+	c.WriteRetries = 2
+	c.RetryInterval = toml.Duration(time.Millisecond)
+	s := subscriber.NewService(c)
+	s.MetaClient = ms
+	s.NewPointsWriter = newPointsWriter
+	s.Open()
+	defer s.Close()
+
+	// Signal that data has changed
+	dataChanged <- struct{}{}
+
+	select {
+	case <-urls:
+	case <-time.After(10 * time.Millisecond):
+		t.Fatal("expected urls")
+	}
+
+	expPR := &coordinator.WritePointsRequest{
+		Database:        "db0",
+		RetentionPolicy: "rp0",
+	}
+	s.Points() <- expPR
+
+	// Should get pr back three times.
+	for i := 0; i < 3; i++ {
+		var pr *coordinator.WritePointsRequest
+		select {
+		case pr = <-prs:
+		case <-time.After(time.Second):
+			t.Fatalf("expected points request: got %d exp 3", i)
+		}
+		if pr != expPR {
+			t.Errorf("unexpected points request: got %v, exp %v", pr, expPR)
+		}
+	}
+	close(dataChanged)
+}
+
+func TestService_PointsDropped(t *testing.T) {
+	dataChanged := make(chan struct{})
+	ms := MetaClient{}
+	ms.WaitForDataChangedFn = func() chan struct{} {
+		return dataChanged
+	}
+	ms.DatabasesFn = func() []meta.DatabaseInfo {
+		return []meta.DatabaseInfo{
+			{
+				Name: "db0",
+				RetentionPolicies: []meta.RetentionPolicyInfo{
+					{
+						Name: "rp0",
+						Subscriptions: []meta.SubscriptionInfo{
+							{Name: "s0", Mode: "ALL", Destinations: []string{"udp://h0:9093"}},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	// The destination blocks until it is released.
+	prs := make(chan *coordinator.WritePointsRequest, 2)
+	release := make(chan struct{})
+	urls := make(chan url.URL, 1)
+	newPointsWriter := func(u url.URL) (subscriber.PointsWriter, error) {
+		sub := Subscription{}
+		sub.WritePointsFn = func(p *coordinator.WritePointsRequest) error {
+			prs <- p
+			<-release
+			return nil
+		}
+		urls <- u
+		return sub, nil
+	}
+
+	c := subscriber.NewConfig()
+	c.WriteBufferSize = 1
+	c.WriteConcurrency = 1
+	s := subscriber.NewService(c)
+	s.MetaClient = ms
+	s.NewPointsWriter = newPointsWriter
+	s.Open()
+	defer s.Close()
+	defer close(release)
+
+	// Signal that data has changed
+	dataChanged <- struct{}{}
+
+	select {
+	case <-urls:
+	case <-time.After(10 * time.Millisecond):
+		t.Fatal("expected urls")
+	}
+
+	points, err := models.ParsePointsString("cpu value=1 1\ncpu value=2 2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	newPR := func() *coordinator.WritePointsRequest {
+		return &coordinator.WritePointsRequest{Database: "db0", RetentionPolicy: "rp0", Points: points}
+	}
+
+	// The first write blocks the destination, the second fills the queue
+	// and the third is dropped.
+	s.Points() <- newPR()
+	select {
+	case <-prs:
+	case <-time.After(time.Second):
+		t.Fatal("expected points request")
+	}
+	s.Points() <- newPR()
+	s.Points() <- newPR()
+
+	dropped := func() (service, subscription interface{}) {
+		for _, stat := range s.Statistics(nil) {
+			if stat.Tags["name"] == "s0" {
+				subscription = stat.Values["pointsDropped"]
+			} else {
+				service = stat.Values["pointsDropped"]
+			}
+		}
+		return service, subscription
+	}
+	deadline := time.Now().Add(time.Second)
+	for {
+		service, subscription := dropped()
+		if service == int64(2) && subscription == int64(2) {
+			break
+		} else if time.Now().After(deadline) {
+			t.Fatalf("unexpected dropped points: service=%v subscription=%v", service, subscription)
+		}
+		time.Sleep(time.Millisecond)
+	}
+	close(dataChanged)
+}
+
 func TestService_ModeANY(t *testing.T) {
 	dataChanged := make(chan struct{})
 	ms := MetaClient{}
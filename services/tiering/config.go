@@ -0,0 +1,90 @@
+package tiering
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/influxdata/influxdb/monitor/diagnostics"
+	"github.com/influxdata/influxdb/pkg/s3"
+	"github.com/influxdata/influxdb/toml"
+)
+
+const (
+	// DefaultCheckInterval is the default interval of the checks for cold shards.
+	DefaultCheckInterval = 30 * time.Minute
+
+	// DefaultColdAfter is the default time after the end of a shard group
+	// when its shards are moved to object storage.
+	DefaultColdAfter = 30 * 24 * time.Hour
+
+	// DefaultCacheMaxMemorySize is the default size of the cache of data
+	// read from object storage.
+	DefaultCacheMaxMemorySize = 256 * 1024 * 1024
+)
+
+// Config represents the configuration for the tiering service.
+type Config struct {
+	Enabled            bool          `toml:"enabled"`
+	CheckInterval      toml.Duration `toml:"check-interval"`
+	ColdAfter          toml.Duration `toml:"cold-after"`
+	CacheMaxMemorySize toml.Size     `toml:"cache-max-memory-size"`
+
+	// S3 is the bucket shards are moved to. It must remain configured while
+	// any shard is tiered, even if moving shards is disabled.
+	S3 s3.Config `toml:"s3"`
+}
+
+// NewConfig returns an instance of Config with defaults.
+func NewConfig() Config {
+	return Config{
+		CheckInterval:      toml.Duration(DefaultCheckInterval),
+		ColdAfter:          toml.Duration(DefaultColdAfter),
+		CacheMaxMemorySize: DefaultCacheMaxMemorySize,
+		S3:                 s3.NewConfig(),
+	}
+}
+
+// Configured returns true if an object store is configured.
+func (c Config) Configured() bool {
+	return c.Enabled || c.S3.Bucket != ""
+}
+
+// Validate returns an error if the Config is invalid.
+func (c Config) Validate() error {
+	if !c.Configured() {
+		return nil
+	}
+
+	if err := c.S3.WithEnvCredentials().Validate(); err != nil {
+		return fmt.Errorf("invalid tiering config: %v", err)
+	}
+	if !c.Enabled {
+		return nil
+	}
+
+	if c.CheckInterval <= 0 {
+		return errors.New("check-interval must be positive")
+	} else if c.ColdAfter <= 0 {
+		return errors.New("cold-after must be positive")
+	}
+	return nil
+}
+
+// Diagnostics returns a diagnostics representation of a subset of the Config.
+func (c Config) Diagnostics() (*diagnostics.Diagnostics, error) {
+	if !c.Configured() {
+		return diagnostics.RowFromMap(map[string]interface{}{
+			"enabled": false,
+		}), nil
+	}
+
+	return diagnostics.RowFromMap(map[string]interface{}{
+		"enabled":               c.Enabled,
+		"check-interval":        c.CheckInterval,
+		"cold-after":            c.ColdAfter,
+		"cache-max-memory-size": c.CacheMaxMemorySize,
+		"endpoint":              c.S3.Endpoint,
+		"bucket":                c.S3.Bucket,
+	}), nil
+}
@@ -0,0 +1,67 @@
+package tiering_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/influxdata/influxdb/services/tiering"
+)
+
+func TestConfig_Parse(t *testing.T) {
+	// Parse configuration.
+	var c tiering.Config
+	if _, err := toml.Decode(`
+enabled = true
+check-interval = "1m"
+cold-after = "48h"
+cache-max-memory-size = "64m"
+
+[s3]
+endpoint = "http://minio:9000"
+bucket = "influxdb"
+access-key-id = "id"
+secret-access-key = "secret"
+part-size = "5m"
+`, &c); err != nil {
+		t.Fatal(err)
+	}
+
+	// Validate configuration.
+	if !c.Enabled {
+		t.Fatalf("unexpected enabled state: %v", c.Enabled)
+	} else if time.Duration(c.CheckInterval) != time.Minute {
+		t.Fatalf("unexpected check interval: %v", c.CheckInterval)
+	} else if time.Duration(c.ColdAfter) != 48*time.Hour {
+		t.Fatalf("unexpected cold after: %v", c.ColdAfter)
+	} else if c.CacheMaxMemorySize != 64*1024*1024 {
+		t.Fatalf("unexpected cache max memory size: %v", c.CacheMaxMemorySize)
+	} else if c.S3.Bucket != "influxdb" {
+		t.Fatalf("unexpected bucket: %s", c.S3.Bucket)
+	} else if err := c.Validate(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestConfig_Validate(t *testing.T) {
+	c := tiering.NewConfig()
+	if err := c.Validate(); err != nil {
+		t.Fatalf("unexpected validation fail from NewConfig: %s", err)
+	}
+
+	c.Enabled = true
+	if err := c.Validate(); err == nil {
+		t.Fatal("expected error for missing bucket, got nil")
+	}
+
+	c.S3.Endpoint, c.S3.Bucket = "http://minio:9000", "influxdb"
+	c.S3.AccessKeyID, c.S3.SecretAccessKey = "id", "secret"
+	if err := c.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	c.ColdAfter = 0
+	if err := c.Validate(); err == nil {
+		t.Fatal("expected error for cold-after = 0, got nil")
+	}
+}
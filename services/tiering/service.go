@@ -0,0 +1,168 @@
+// Package tiering provides the service that moves the data of cold shards to
+// object storage.
+package tiering // import "github.com/influxdata/influxdb/services/tiering"
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/services/meta"
+	"github.com/influxdata/influxdb/tsdb"
+	"github.com/uber-go/zap"
+)
+
+// Statistics for the tiering service.
+const (
+	statShardsTiered  = "shardsTiered"
+	statFilesTiered   = "filesTiered"
+	statTierErrors    = "tierErrors"
+	statCacheHits     = "cacheHits"
+	statCacheMisses   = "cacheMisses"
+	statBytesFetched  = "bytesFetched"
+	statBytesUploaded = "bytesUploaded"
+)
+
+// Service moves the data files of shards whose shard group ended longer
+// than the cold-after duration ago to object storage. Only the index of each
+// file is kept on disk; data is fetched from the store when it is queried.
+type Service struct {
+	MetaClient interface {
+		Databases() []meta.DatabaseInfo
+	}
+	TSDBStore interface {
+		Shard(id uint64) *tsdb.Shard
+	}
+
+	// Store is the object store shards are moved to. It must also be set
+	// as the object store of the engine options of the TSDBStore.
+	Store *Store
+
+	config Config
+	wg     sync.WaitGroup
+	done   chan struct{}
+	stats  *Statistics
+
+	logger zap.Logger
+}
+
+// NewService returns a configured tiering service.
+func NewService(c Config) *Service {
+	return &Service{
+		config: c,
+		stats:  &Statistics{},
+		logger: zap.New(zap.NullEncoder()),
+	}
+}
+
+// Open starts moving cold shards.
+func (s *Service) Open() error {
+	if !s.config.Enabled || s.done != nil {
+		return nil
+	}
+
+	s.logger.Info(fmt.Sprint("Starting tiering service with check interval of ", s.config.CheckInterval))
+	s.done = make(chan struct{})
+
+	s.wg.Add(1)
+	go func() { defer s.wg.Done(); s.run() }()
+	return nil
+}
+
+// Close stops moving cold shards.
+func (s *Service) Close() error {
+	if !s.config.Enabled || s.done == nil {
+		return nil
+	}
+
+	s.logger.Info("Tiering service closing.")
+	close(s.done)
+
+	s.wg.Wait()
+	s.done = nil
+	return nil
+}
+
+// WithLogger sets the logger on the service.
+func (s *Service) WithLogger(log zap.Logger) {
+	s.logger = log.With(zap.String("service", "tiering"))
+}
+
+// Statistics maintains the statistics for the tiering service.
+type Statistics struct {
+	ShardsTiered int64
+	FilesTiered  int64
+	TierErrors   int64
+}
+
+// Statistics returns statistics for periodic monitoring.
+func (s *Service) Statistics(tags map[string]string) []models.Statistic {
+	values := map[string]interface{}{
+		statShardsTiered: atomic.LoadInt64(&s.stats.ShardsTiered),
+		statFilesTiered:  atomic.LoadInt64(&s.stats.FilesTiered),
+		statTierErrors:   atomic.LoadInt64(&s.stats.TierErrors),
+	}
+	if s.Store != nil {
+		values[statCacheHits] = atomic.LoadInt64(&s.Store.stats.CacheHits)
+		values[statCacheMisses] = atomic.LoadInt64(&s.Store.stats.CacheMisses)
+		values[statBytesFetched] = atomic.LoadInt64(&s.Store.stats.BytesFetched)
+		values[statBytesUploaded] = atomic.LoadInt64(&s.Store.stats.BytesUploaded)
+	}
+	return []models.Statistic{{
+		Name:   "tiering",
+		Tags:   tags,
+		Values: values,
+	}}
+}
+
+func (s *Service) run() {
+	ticker := time.NewTicker(time.Duration(s.config.CheckInterval))
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.done:
+			return
+
+		case <-ticker.C:
+			s.logger.Info("Tiering check for cold shards commencing.")
+			s.tierColdShards(time.Now().UTC().Add(-time.Duration(s.config.ColdAfter)))
+		}
+	}
+}
+
+// tierColdShards moves the shards of the shard groups that ended before
+// cutoff. Shards are moved once they are fully compacted; shards that were
+// already moved have no files left to move.
+func (s *Service) tierColdShards(cutoff time.Time) {
+	for _, d := range s.MetaClient.Databases() {
+		for _, r := range d.RetentionPolicies {
+			for _, g := range r.ShardGroups {
+				if g.Deleted() || g.EndTime.After(cutoff) {
+					continue
+				}
+
+				for _, sh := range g.Shards {
+					shard := s.TSDBStore.Shard(sh.ID)
+					if shard == nil || !shard.IsIdle() {
+						continue
+					}
+
+					n, err := shard.Tier()
+					if err != nil {
+						atomic.AddInt64(&s.stats.TierErrors, 1)
+						s.logger.Info(fmt.Sprintf("Failed to move shard ID %d from database %s, retention policy %s to object storage: %v. Will retry in %v", sh.ID, d.Name, r.Name, err, s.config.CheckInterval))
+						continue
+					} else if n == 0 {
+						continue
+					}
+
+					atomic.AddInt64(&s.stats.ShardsTiered, 1)
+					atomic.AddInt64(&s.stats.FilesTiered, int64(n))
+					s.logger.Info(fmt.Sprintf("Moved %d files of shard ID %d from database %s, retention policy %s to object storage.", n, sh.ID, d.Name, r.Name))
+				}
+			}
+		}
+	}
+}
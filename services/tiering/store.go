@@ -0,0 +1,148 @@
+package tiering
+
+import (
+	"container/list"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sync"
+	"sync/atomic"
+
+	"github.com/influxdata/influxdb/pkg/s3"
+)
+
+// chunkSize is the size of the ranges of objects that are fetched and
+// cached. Reading the blocks of a file in order fetches each range once.
+const chunkSize = 1024 * 1024
+
+// Store is an object store in an S3-compatible bucket that caches the
+// ranges of objects it reads in memory.
+type Store struct {
+	client *s3.Client
+
+	mu      sync.Mutex
+	chunks  map[chunkKey]*list.Element
+	lru     *list.List // most recently used first
+	size    int64
+	maxSize int64
+
+	stats *StoreStatistics
+}
+
+type chunkKey struct {
+	key   string
+	index int64
+}
+
+type chunk struct {
+	chunkKey
+	data []byte
+}
+
+// NewStore returns a store for the bucket of the config.
+func NewStore(c Config) *Store {
+	return &Store{
+		client:  s3.NewClient(c.S3.WithEnvCredentials()),
+		chunks:  make(map[chunkKey]*list.Element),
+		lru:     list.New(),
+		maxSize: int64(c.CacheMaxMemorySize),
+		stats:   &StoreStatistics{},
+	}
+}
+
+// StoreStatistics maintains the statistics for the store.
+type StoreStatistics struct {
+	CacheHits     int64
+	CacheMisses   int64
+	BytesFetched  int64
+	BytesUploaded int64
+}
+
+// Upload stores the content of r as the object key.
+func (s *Store) Upload(key string, r io.Reader) (int64, error) {
+	n, err := s.client.Upload(key, r)
+	atomic.AddInt64(&s.stats.BytesUploaded, n)
+	return n, err
+}
+
+// ReadAt reads len(p) bytes of the object key starting at off.
+func (s *Store) ReadAt(key string, p []byte, off int64) (int, error) {
+	var n int
+	for n < len(p) {
+		pos := off + int64(n)
+		data, err := s.chunk(key, pos/chunkSize)
+		if err != nil {
+			return n, err
+		}
+
+		i := pos % chunkSize
+		if i >= int64(len(data)) {
+			return n, io.EOF
+		}
+		n += copy(p[n:], data[i:])
+	}
+	return n, nil
+}
+
+// Delete removes the object key and its cached ranges.
+func (s *Store) Delete(key string) error {
+	s.mu.Lock()
+	for k, e := range s.chunks {
+		if k.key == key {
+			s.remove(e)
+		}
+	}
+	s.mu.Unlock()
+
+	return s.client.Delete(key)
+}
+
+// Copy copies the object src to dst without fetching it.
+func (s *Store) Copy(src, dst string) error {
+	return s.client.Copy(src, dst)
+}
+
+// chunk returns a range of an object from the cache or the bucket.
+func (s *Store) chunk(key string, index int64) ([]byte, error) {
+	k := chunkKey{key: key, index: index}
+	s.mu.Lock()
+	if e, ok := s.chunks[k]; ok {
+		s.lru.MoveToFront(e)
+		s.mu.Unlock()
+		atomic.AddInt64(&s.stats.CacheHits, 1)
+		return e.Value.(*chunk).data, nil
+	}
+	s.mu.Unlock()
+	atomic.AddInt64(&s.stats.CacheMisses, 1)
+
+	rc, err := s.client.GetRange(key, index*chunkSize, chunkSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	data, err := ioutil.ReadAll(io.LimitReader(rc, chunkSize+1))
+	if err != nil {
+		return nil, err
+	} else if len(data) > chunkSize {
+		return nil, fmt.Errorf("tiering: object store does not support range requests")
+	}
+	atomic.AddInt64(&s.stats.BytesFetched, int64(len(data)))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.chunks[k]; !ok && int64(len(data)) <= s.maxSize {
+		s.chunks[k] = s.lru.PushFront(&chunk{chunkKey: k, data: data})
+		s.size += int64(len(data))
+		for s.size > s.maxSize {
+			s.remove(s.lru.Back())
+		}
+	}
+	return data, nil
+}
+
+// remove evicts a range from the cache.
+func (s *Store) remove(e *list.Element) {
+	c := s.lru.Remove(e).(*chunk)
+	delete(s.chunks, c.chunkKey)
+	s.size -= int64(len(c.data))
+}
@@ -0,0 +1,80 @@
+package tiering
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+// Ensure ranges of objects are fetched once and evicted when the cache is full.
+func TestStore_ReadAt(t *testing.T) {
+	object := bytes.Repeat([]byte("0123456789abcdef"), 3*chunkSize/16)
+	var requests int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&requests, 1)
+		if r.URL.Path != "/bucket/db/rp/1/000000001-000000001.tsm" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		var start, end int
+		if _, err := fmt.Sscanf(r.Header.Get("Range"), "bytes=%d-%d", &start, &end); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if end >= len(object) {
+			end = len(object) - 1
+		}
+		w.WriteHeader(http.StatusPartialContent)
+		w.Write(object[start : end+1])
+	}))
+	defer srv.Close()
+
+	c := NewConfig()
+	c.S3.Endpoint, c.S3.Bucket = srv.URL, "bucket"
+	c.S3.AccessKeyID, c.S3.SecretAccessKey = "id", "secret"
+	c.CacheMaxMemorySize = 2 * chunkSize
+	s := NewStore(c)
+
+	read := func(off int64, n int) {
+		t.Helper()
+		p := make([]byte, n)
+		if _, err := s.ReadAt("db/rp/1/000000001-000000001.tsm", p, off); err != nil {
+			t.Fatal(err)
+		} else if !bytes.Equal(p, object[off:off+int64(n)]) {
+			t.Fatalf("unexpected data at offset %d", off)
+		}
+	}
+
+	// A read across two ranges fetches both.
+	read(chunkSize-10, 20)
+	if requests != 2 {
+		t.Fatalf("unexpected requests: %d", requests)
+	}
+
+	// Cached ranges are not fetched again.
+	read(5, 100)
+	read(chunkSize+5, 100)
+	if requests != 2 {
+		t.Fatalf("unexpected requests: %d", requests)
+	}
+
+	// The least recently used range is evicted.
+	read(2*chunkSize, 100)
+	read(chunkSize+5, 100)
+	if requests != 3 {
+		t.Fatalf("unexpected requests: %d", requests)
+	}
+	read(5, 100)
+	if requests != 4 {
+		t.Fatalf("unexpected requests: %d", requests)
+	} else if s.size != 2*chunkSize {
+		t.Fatalf("unexpected cache size: %d", s.size)
+	}
+
+	if _, err := s.ReadAt("missing", make([]byte, 1), 0); err == nil {
+		t.Fatal("expected error")
+	}
+}
@@ -0,0 +1,63 @@
+package udp
+
+import (
+	"github.com/influxdata/influxdb/models"
+)
+
+// coalesceKey identifies the points of a series at one timestamp.
+type coalesceKey struct {
+	series string
+	time   int64
+}
+
+// coalescePoints merges the points of a batch that share a series and a
+// timestamp into one point with the fields of all of them. A field that is
+// set by several points takes the value of the last one, as it would if the
+// points were written one after the other. The merged points keep the
+// position of the first point of their series and timestamp.
+//
+// Collectors that send each field of a measurement as its own point at the
+// same moment would otherwise have every field parsed, cached and indexed
+// as a separate point.
+func coalescePoints(points []models.Point) ([]models.Point, error) {
+	idx := make(map[coalesceKey]int, len(points))
+	out := make([]models.Point, 0, len(points))
+	fields := make(map[int]models.Fields)
+
+	for _, p := range points {
+		k := coalesceKey{series: string(p.Key()), time: p.UnixNano()}
+		i, ok := idx[k]
+		if !ok {
+			idx[k] = len(out)
+			out = append(out, p)
+			continue
+		}
+
+		// Collect the fields of the points merged into the first one.
+		f := fields[i]
+		if f == nil {
+			var err error
+			if f, err = out[i].Fields(); err != nil {
+				return nil, err
+			}
+			fields[i] = f
+		}
+		pf, err := p.Fields()
+		if err != nil {
+			return nil, err
+		}
+		for name, v := range pf {
+			f[name] = v
+		}
+	}
+
+	for i, f := range fields {
+		p := out[i]
+		merged, err := models.NewPoint(string(p.Name()), p.Tags(), f, p.Time())
+		if err != nil {
+			return nil, err
+		}
+		out[i] = merged
+	}
+	return out, nil
+}
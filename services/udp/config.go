@@ -3,7 +3,9 @@ package udp
 import (
 	"time"
 
+	"github.com/influxdata/influxdb/models"
 	"github.com/influxdata/influxdb/monitor/diagnostics"
+	"github.com/influxdata/influxdb/pkg/ipfilter"
 	"github.com/influxdata/influxdb/toml"
 )
 
@@ -55,6 +57,19 @@ type Config struct {
 	ReadBuffer      int           `toml:"read-buffer"`
 	BatchTimeout    toml.Duration `toml:"batch-timeout"`
 	Precision       string        `toml:"precision"`
+
+	// SeriesKeyDiagnosticsEnabled counts and logs received points whose
+	// series keys are not in canonical form.
+	SeriesKeyDiagnosticsEnabled bool `toml:"series-key-diagnostics-enabled"`
+
+	// CoalesceFields merges the points of a batch that share a series and
+	// a timestamp into one point before the batch is written.
+	CoalesceFields bool `toml:"coalesce-fields"`
+
+	// AllowedNetworks and DeniedNetworks restrict the addresses that
+	// packets are accepted from. See ipfilter.New.
+	AllowedNetworks []string `toml:"allowed-networks"`
+	DeniedNetworks  []string `toml:"denied-networks"`
 }
 
 // NewConfig returns a new instance of Config with defaults.
@@ -94,6 +109,18 @@ func (c *Config) WithDefaults() *Config {
 	return &d
 }
 
+// Validate returns an error if the precision or the allowed and denied
+// networks of the config are invalid.
+func (c *Config) Validate() error {
+	if err := models.ValidatePrecision(c.Precision); err != nil {
+		return err
+	}
+	if _, err := ipfilter.New(c.AllowedNetworks, c.DeniedNetworks); err != nil {
+		return err
+	}
+	return nil
+}
+
 // Configs wraps a slice of Config to aggregate diagnostics.
 type Configs []Config
 
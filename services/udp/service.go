@@ -2,6 +2,7 @@
 package udp // import "github.com/influxdata/influxdb/services/udp"
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"net"
@@ -10,6 +11,7 @@ import (
 	"time"
 
 	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/pkg/ipfilter"
 	"github.com/influxdata/influxdb/services/meta"
 	"github.com/influxdata/influxdb/tsdb"
 	"github.com/uber-go/zap"
@@ -32,6 +34,8 @@ const (
 	statBatchesTransmitted  = "batchesTx"
 	statPointsTransmitted   = "pointsTx"
 	statBatchesTransmitFail = "batchesTxFail"
+	statNonCanonicalKeys    = "nonCanonicalKeys"
+	statPointsCoalesced     = "pointsCoalesced"
 )
 
 // Service is a UDP service that will listen for incoming packets of line protocol.
@@ -46,6 +50,7 @@ type Service struct {
 
 	parserChan chan []byte
 	batcher    *tsdb.PointBatcher
+	filter     *ipfilter.Filter
 	config     Config
 
 	PointsWriter interface {
@@ -90,6 +95,11 @@ func (s *Service) Open() (err error) {
 		return errors.New("database has to be specified in config")
 	}
 
+	s.filter, err = ipfilter.New(s.config.AllowedNetworks, s.config.DeniedNetworks)
+	if err != nil {
+		return err
+	}
+
 	s.addr, err = net.ResolveUDPAddr("udp", s.config.BindAddress)
 	if err != nil {
 		s.Logger.Info(fmt.Sprintf("Failed to resolve UDP address %s: %s", s.config.BindAddress, err))
@@ -132,6 +142,8 @@ type Statistics struct {
 	BatchesTransmitted  int64
 	PointsTransmitted   int64
 	BatchesTransmitFail int64
+	NonCanonicalKeys    int64
+	PointsCoalesced     int64
 }
 
 // Statistics returns statistics for periodic monitoring.
@@ -147,6 +159,8 @@ func (s *Service) Statistics(tags map[string]string) []models.Statistic {
 			statBatchesTransmitted:  atomic.LoadInt64(&s.stats.BatchesTransmitted),
 			statPointsTransmitted:   atomic.LoadInt64(&s.stats.PointsTransmitted),
 			statBatchesTransmitFail: atomic.LoadInt64(&s.stats.BatchesTransmitFail),
+			statNonCanonicalKeys:    atomic.LoadInt64(&s.stats.NonCanonicalKeys),
+			statPointsCoalesced:     atomic.LoadInt64(&s.stats.PointsCoalesced),
 		},
 	}}
 }
@@ -163,6 +177,16 @@ func (s *Service) writer() {
 				continue
 			}
 
+			if s.config.CoalesceFields {
+				points, err := coalescePoints(batch)
+				if err != nil {
+					s.Logger.Info(fmt.Sprintf("failed to coalesce point batch: %s", err))
+				} else {
+					atomic.AddInt64(&s.stats.PointsCoalesced, int64(len(batch)-len(points)))
+					batch = points
+				}
+			}
+
 			if err := s.PointsWriter.WritePointsPrivileged(s.config.Database, s.config.RetentionPolicy, models.ConsistencyLevelAny, batch); err == nil {
 				atomic.AddInt64(&s.stats.BatchesTransmitted, 1)
 				atomic.AddInt64(&s.stats.PointsTransmitted, int64(len(batch)))
@@ -188,11 +212,14 @@ func (s *Service) serve() {
 			return
 		default:
 			// Keep processing.
-			n, _, err := s.conn.ReadFromUDP(buf)
+			n, addr, err := s.conn.ReadFromUDP(buf)
 			if err != nil {
 				atomic.AddInt64(&s.stats.ReadFail, 1)
 				s.Logger.Info(fmt.Sprintf("Failed to read UDP message: %s", err))
 				continue
+			} else if !s.filter.Allowed(addr.IP) {
+				// Drop packets from outside of the allowed networks.
+				continue
 			}
 			atomic.AddInt64(&s.stats.BytesReceived, int64(n))
 
@@ -218,6 +245,10 @@ func (s *Service) parser() {
 				continue
 			}
 
+			if s.config.SeriesKeyDiagnosticsEnabled {
+				s.checkSeriesKeys(points)
+			}
+
 			for _, point := range points {
 				s.batcher.In() <- point
 			}
@@ -226,6 +257,28 @@ func (s *Service) parser() {
 	}
 }
 
+// checkSeriesKeys counts and logs the points whose series keys are not in
+// canonical form, which differ from the keys of other points of the same
+// series by tag order or escaping.
+func (s *Service) checkSeriesKeys(points []models.Point) {
+	var n int
+	var key, canonical []byte
+	for _, p := range points {
+		if k := models.CanonicalKey(p); !bytes.Equal(p.Key(), k) {
+			if n == 0 {
+				key, canonical = p.Key(), k
+			}
+			n++
+		}
+	}
+	if n == 0 {
+		return
+	}
+
+	atomic.AddInt64(&s.stats.NonCanonicalKeys, int64(n))
+	s.Logger.Info(fmt.Sprintf("%d of %d points received have non-canonical series keys, e.g. %q instead of %q", n, len(points), key, canonical))
+}
+
 // Close closes the service and the underlying listener.
 func (s *Service) Close() error {
 	if wait := func() bool {
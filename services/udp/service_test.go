@@ -3,6 +3,7 @@ package udp
 import (
 	"errors"
 	"os"
+	"reflect"
 	"testing"
 	"time"
 
@@ -158,3 +159,31 @@ func NewTestService(c *Config) *TestService {
 func (s *TestService) WritePointsPrivileged(database, retentionPolicy string, consistencyLevel models.ConsistencyLevel, points []models.Point) error {
 	return s.WritePointsFn(database, retentionPolicy, consistencyLevel, points)
 }
+
+func TestCoalescePoints(t *testing.T) {
+	points, err := models.ParsePointsString(`cpu,host=a user=1 10
+cpu,host=b user=5 10
+cpu,host=a system=2 10
+cpu,host=a user=3 20
+cpu,host=a user=4,idle=6i 10`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := coalescePoints(points)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var lines []string
+	for _, p := range got {
+		lines = append(lines, p.String())
+	}
+	if exp := []string{
+		"cpu,host=a idle=6i,system=2,user=4 10",
+		"cpu,host=b user=5 10",
+		"cpu,host=a user=3 20",
+	}; !reflect.DeepEqual(lines, exp) {
+		t.Fatalf("unexpected points: %q", lines)
+	}
+}
@@ -615,6 +615,7 @@ func (b *BasicClient) BasicWriteHandler(rs <-chan response, wt *Timer) {
 	fail := 0
 
 	s := time.Duration(0)
+	var ds []time.Duration
 
 	for t := range rs {
 
@@ -627,6 +628,7 @@ func (b *BasicClient) BasicWriteHandler(rs <-chan response, wt *Timer) {
 		}
 
 		s += t.Timer.Elapsed()
+		ds = append(ds, t.Timer.Elapsed())
 
 	}
 
@@ -638,6 +640,7 @@ func (b *BasicClient) BasicWriteHandler(rs <-chan response, wt *Timer) {
 	fmt.Printf("	Success: %v\n", success)
 	fmt.Printf("	Fail: %v\n", fail)
 	fmt.Printf("Average Response Time: %v\n", s/time.Duration(n))
+	printPercentiles("Response Time", ds)
 	fmt.Printf("Points Per Second: %v\n\n", int(float64(n)*float64(b.BatchSize)/float64(wt.Elapsed().Seconds())))
 }
 
@@ -645,9 +648,11 @@ func (b *BasicClient) BasicWriteHandler(rs <-chan response, wt *Timer) {
 func (b *BasicQueryClient) BasicReadHandler(r <-chan response, rt *Timer) {
 	n := 0
 	s := time.Duration(0)
+	var ds []time.Duration
 	for t := range r {
 		n++
 		s += t.Timer.Elapsed()
+		ds = append(ds, t.Timer.Elapsed())
 	}
 
 	if n == 0 {
@@ -655,7 +660,9 @@ func (b *BasicQueryClient) BasicReadHandler(r <-chan response, rt *Timer) {
 	}
 
 	fmt.Printf("Total Queries: %v\n", n)
-	fmt.Printf("Average Query Response Time: %v\n\n", s/time.Duration(n))
+	fmt.Printf("Average Query Response Time: %v\n", s/time.Duration(n))
+	printPercentiles("Query Response Time", ds)
+	fmt.Println()
 }
 
 func (o *outputConfig) HTTPHandler(method string) func(r <-chan response, rt *Timer) {
@@ -0,0 +1,83 @@
+package stress
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// Dump writes the points of a PointGenerator to w as line protocol, one
+// point per line, so the same workload can be replayed against several
+// servers or builds with a ReplayPointGenerator. It returns the number of
+// points written.
+func Dump(w io.Writer, pg PointGenerator) (int, error) {
+	ps, err := pg.Generate()
+	if err != nil {
+		return 0, err
+	}
+
+	bw := bufio.NewWriter(w)
+	n := 0
+	for p := range ps {
+		if _, err := bw.Write(p.Line()); err != nil {
+			return n, err
+		} else if err := bw.WriteByte('\n'); err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, bw.Flush()
+}
+
+// ReplayPointGenerator implements the PointGenerator interface by reading
+// line protocol written by Dump from a file.
+type ReplayPointGenerator struct {
+	Path string
+}
+
+// Generate returns a channel of the points in the file. Blank lines and
+// comments are skipped.
+func (g *ReplayPointGenerator) Generate() (<-chan Point, error) {
+	f, err := os.Open(g.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	c := make(chan Point, 15000)
+	go func() {
+		defer close(c)
+		defer f.Close()
+
+		scanner := bufio.NewScanner(f)
+		scanner.Buffer(nil, 1024*1024)
+		for scanner.Scan() {
+			line := bytes.TrimSpace(scanner.Bytes())
+			if len(line) == 0 || line[0] == '#' {
+				continue
+			}
+			c <- linePoint(append([]byte(nil), line...))
+		}
+		if err := scanner.Err(); err != nil {
+			fmt.Println(err)
+		}
+	}()
+	return c, nil
+}
+
+// Time returns the current time. The timestamps of replayed points are not
+// parsed, so queries run relative to the wall clock.
+func (g *ReplayPointGenerator) Time() time.Time {
+	return time.Now()
+}
+
+// linePoint is a point that is already encoded as line protocol. It can
+// only be written with the line protocol.
+type linePoint []byte
+
+func (p linePoint) Line() []byte       { return p }
+func (p linePoint) Graphite() []byte   { return nil }
+func (p linePoint) OpenJSON() []byte   { return nil }
+func (p linePoint) OpenTelnet() []byte { return nil }
@@ -7,6 +7,7 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"os"
 	"regexp"
 	"strings"
 	"testing"
@@ -264,6 +265,60 @@ func TestBasicPointGenerator_Generate(t *testing.T) {
 	}
 }
 
+func TestDump_Replay(t *testing.T) {
+	f, err := ioutil.TempFile("", "influx-stress-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+
+	n, err := Dump(f, basicPG)
+	if err != nil {
+		t.Fatal(err)
+	} else if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if exp := basicPG.PointCount * basicPG.SeriesCount; n != exp {
+		t.Fatalf("Expected %d points got %d", exp, n)
+	}
+
+	rpg := &ReplayPointGenerator{Path: f.Name()}
+	ps, err := rpg.Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	for p := range ps {
+		buf.Write(p.Line())
+		buf.Write([]byte("\n"))
+	}
+
+	points, err := models.ParsePoints(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	} else if len(points) != n {
+		t.Fatalf("Expected %d replayed points got %d", n, len(points))
+	}
+}
+
+func Test_percentile(t *testing.T) {
+	var ds []time.Duration
+	for i := 1; i <= 100; i++ {
+		ds = append(ds, time.Duration(i)*time.Millisecond)
+	}
+
+	if got := percentile(ds, 0.50); got != 51*time.Millisecond {
+		t.Errorf("Expected 51ms got %v", got)
+	}
+	if got := percentile(ds, 0.99); got != 100*time.Millisecond {
+		t.Errorf("Expected 100ms got %v", got)
+	}
+	if got := percentile(nil, 0.99); got != 0 {
+		t.Errorf("Expected 0 got %v", got)
+	}
+}
+
 func Test_post(t *testing.T) {
 	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		content, _ := ioutil.ReadAll(r.Body)
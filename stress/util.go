@@ -1,6 +1,8 @@
 package stress
 
 import (
+	"fmt"
+	"sort"
 	"time"
 )
 
@@ -79,6 +81,27 @@ func (rs ResponseTimes) Swap(i, j int) {
 	rs[i], rs[j] = rs[j], rs[i]
 }
 
+// percentile returns the duration below which the fraction p of the
+// durations fall. ds must be sorted.
+func percentile(ds []time.Duration, p float64) time.Duration {
+	if len(ds) == 0 {
+		return 0
+	}
+	i := int(float64(len(ds)) * p)
+	if i >= len(ds) {
+		i = len(ds) - 1
+	}
+	return ds[i]
+}
+
+// printPercentiles prints the 50th, 95th and 99th percentiles of ds.
+func printPercentiles(name string, ds []time.Duration) {
+	sort.Slice(ds, func(i, j int) bool { return ds[i] < ds[j] })
+	fmt.Printf("50th Percentile %s: %v\n", name, percentile(ds, 0.50))
+	fmt.Printf("95th Percentile %s: %v\n", name, percentile(ds, 0.95))
+	fmt.Printf("99th Percentile %s: %v\n", name, percentile(ds, 0.99))
+}
+
 //////////////////////////////////
 
 // ConcurrencyLimiter is a go routine safe struct that can be used to
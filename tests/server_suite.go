@@ -37,7 +37,7 @@ func init() {
 			&Query{
 				name:    "create database should error with some unquoted names",
 				command: `CREATE DATABASE 0xdb0`,
-				exp:     `{"error":"error parsing query: found 0xdb0, expected identifier at line 1, char 17"}`,
+				exp:     `{"error":"error parsing query: found 0xdb0, expected identifier at line 1, char 17","code":"invalid_query"}`,
 			},
 			&Query{
 				name:    "create database should error with invalid characters",
@@ -47,17 +47,17 @@ func init() {
 			&Query{
 				name:    "create database with retention duration should error with bad retention duration",
 				command: `CREATE DATABASE db0 WITH DURATION xyz`,
-				exp:     `{"error":"error parsing query: found xyz, expected duration at line 1, char 35"}`,
+				exp:     `{"error":"error parsing query: found xyz, expected duration at line 1, char 35","code":"invalid_query"}`,
 			},
 			&Query{
 				name:    "create database with retention replication should error with bad retention replication number",
 				command: `CREATE DATABASE db0 WITH REPLICATION xyz`,
-				exp:     `{"error":"error parsing query: found xyz, expected integer at line 1, char 38"}`,
+				exp:     `{"error":"error parsing query: found xyz, expected integer at line 1, char 38","code":"invalid_query"}`,
 			},
 			&Query{
 				name:    "create database with retention name should error with missing retention name",
 				command: `CREATE DATABASE db0 WITH NAME`,
-				exp:     `{"error":"error parsing query: found EOF, expected identifier at line 1, char 31"}`,
+				exp:     `{"error":"error parsing query: found EOF, expected identifier at line 1, char 31","code":"invalid_query"}`,
 			},
 			&Query{
 				name:    "show database should succeed",
@@ -82,7 +82,7 @@ func init() {
 			&Query{
 				name:    "create database should error with bad retention duration",
 				command: `CREATE DATABASE db1 WITH DURATION xyz`,
-				exp:     `{"error":"error parsing query: found xyz, expected duration at line 1, char 35"}`,
+				exp:     `{"error":"error parsing query: found xyz, expected duration at line 1, char 35","code":"invalid_query"}`,
 			},
 			&Query{
 				name:    "show database should succeed",
@@ -438,7 +438,7 @@ func init() {
 			&Query{
 				name:    "show retention policy should succeed",
 				command: `SHOW RETENTION POLICIES ON db0`,
-				exp:     `{"results":[{"statement_id":0,"series":[{"columns":["name","duration","shardGroupDuration","replicaN","default"],"values":[["rp0","1h0m0s","1h0m0s",1,false]]}]}]}`,
+				exp:     `{"results":[{"statement_id":0,"series":[{"columns":["name","duration","shardGroupDuration","replicaN","default","shardGroupN","shardN","diskBytes"],"values":[["rp0","1h0m0s","1h0m0s",1,false,0,0,0]]}]}]}`,
 			},
 			&Query{
 				name:    "alter retention policy should succeed",
@@ -449,12 +449,12 @@ func init() {
 			&Query{
 				name:    "show retention policy should have new altered information",
 				command: `SHOW RETENTION POLICIES ON db0`,
-				exp:     `{"results":[{"statement_id":0,"series":[{"columns":["name","duration","shardGroupDuration","replicaN","default"],"values":[["rp0","2h0m0s","1h0m0s",3,true]]}]}]}`,
+				exp:     `{"results":[{"statement_id":0,"series":[{"columns":["name","duration","shardGroupDuration","replicaN","default","shardGroupN","shardN","diskBytes"],"values":[["rp0","2h0m0s","1h0m0s",3,true,0,0,0]]}]}]}`,
 			},
 			&Query{
 				name:    "show retention policy should still show policy",
 				command: `SHOW RETENTION POLICIES ON db0`,
-				exp:     `{"results":[{"statement_id":0,"series":[{"columns":["name","duration","shardGroupDuration","replicaN","default"],"values":[["rp0","2h0m0s","1h0m0s",3,true]]}]}]}`,
+				exp:     `{"results":[{"statement_id":0,"series":[{"columns":["name","duration","shardGroupDuration","replicaN","default","shardGroupN","shardN","diskBytes"],"values":[["rp0","2h0m0s","1h0m0s",3,true,0,0,0]]}]}]}`,
 			},
 			&Query{
 				name:    "create a second non-default retention policy",
@@ -465,7 +465,7 @@ func init() {
 			&Query{
 				name:    "show retention policy should show both",
 				command: `SHOW RETENTION POLICIES ON db0`,
-				exp:     `{"results":[{"statement_id":0,"series":[{"columns":["name","duration","shardGroupDuration","replicaN","default"],"values":[["rp0","2h0m0s","1h0m0s",3,true],["rp2","1h0m0s","1h0m0s",1,false]]}]}]}`,
+				exp:     `{"results":[{"statement_id":0,"series":[{"columns":["name","duration","shardGroupDuration","replicaN","default","shardGroupN","shardN","diskBytes"],"values":[["rp0","2h0m0s","1h0m0s",3,true,0,0,0],["rp2","1h0m0s","1h0m0s",1,false,0,0,0]]}]}]}`,
 			},
 			&Query{
 				name:    "dropping non-default retention policy succeed",
@@ -488,7 +488,7 @@ func init() {
 			&Query{
 				name:    "show retention policy should show both with custom shard",
 				command: `SHOW RETENTION POLICIES ON db0`,
-				exp:     `{"results":[{"statement_id":0,"series":[{"columns":["name","duration","shardGroupDuration","replicaN","default"],"values":[["rp0","2h0m0s","1h0m0s",3,true],["rp3","1h0m0s","1h0m0s",1,false]]}]}]}`,
+				exp:     `{"results":[{"statement_id":0,"series":[{"columns":["name","duration","shardGroupDuration","replicaN","default","shardGroupN","shardN","diskBytes"],"values":[["rp0","2h0m0s","1h0m0s",3,true,0,0,0],["rp3","1h0m0s","1h0m0s",1,false,0,0,0]]}]}]}`,
 			},
 			&Query{
 				name:    "dropping non-default custom shard retention policy succeed",
@@ -499,7 +499,7 @@ func init() {
 			&Query{
 				name:    "show retention policy should show just default",
 				command: `SHOW RETENTION POLICIES ON db0`,
-				exp:     `{"results":[{"statement_id":0,"series":[{"columns":["name","duration","shardGroupDuration","replicaN","default"],"values":[["rp0","2h0m0s","1h0m0s",3,true]]}]}]}`,
+				exp:     `{"results":[{"statement_id":0,"series":[{"columns":["name","duration","shardGroupDuration","replicaN","default","shardGroupN","shardN","diskBytes"],"values":[["rp0","2h0m0s","1h0m0s",3,true,0,0,0]]}]}]}`,
 			},
 			&Query{
 				name:    "Ensure retention policy with unacceptable retention cannot be created",
@@ -515,7 +515,7 @@ func init() {
 			&Query{
 				name:    "Ensure retention policy for non existing db is not created",
 				command: `CREATE RETENTION POLICY rp0 ON nodb DURATION 1h REPLICATION 1`,
-				exp:     `{"results":[{"statement_id":0,"error":"database not found: nodb"}]}`,
+				exp:     `{"results":[{"statement_id":0,"error":"database not found: nodb","code":"database_not_found"}]}`,
 				once:    true,
 			},
 			&Query{
@@ -547,7 +547,7 @@ func init() {
 			&Query{
 				name:    "show retention policy: validate normalized shard group durations are working",
 				command: `SHOW RETENTION POLICIES ON db0`,
-				exp:     `{"results":[{"statement_id":0,"series":[{"columns":["name","duration","shardGroupDuration","replicaN","default"],"values":[["rpinf","0s","168h0m0s",1,false],["rpzero","1h0m0s","1h0m0s",1,false],["rponesecond","2h0m0s","1h0m0s",1,false]]}]}]}`,
+				exp:     `{"results":[{"statement_id":0,"series":[{"columns":["name","duration","shardGroupDuration","replicaN","default","shardGroupN","shardN","diskBytes"],"values":[["rpinf","0s","168h0m0s",1,false,0,0,0],["rpzero","1h0m0s","1h0m0s",1,false,0,0,0],["rponesecond","2h0m0s","1h0m0s",1,false,0,0,0]]}]}]}`,
 			},
 		},
 	}
@@ -563,7 +563,7 @@ func init() {
 			&Query{
 				name:    "show retention policies should return auto-created policy",
 				command: `SHOW RETENTION POLICIES ON db0`,
-				exp:     `{"results":[{"statement_id":0,"series":[{"columns":["name","duration","shardGroupDuration","replicaN","default"],"values":[["autogen","0s","168h0m0s",1,true]]}]}]}`,
+				exp:     `{"results":[{"statement_id":0,"series":[{"columns":["name","duration","shardGroupDuration","replicaN","default","shardGroupN","shardN","diskBytes"],"values":[["autogen","0s","168h0m0s",1,true,0,0,0]]}]}]}`,
 			},
 		},
 	}
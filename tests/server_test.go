@@ -515,17 +515,17 @@ func TestServer_UserCommands(t *testing.T) {
 			&Query{
 				name:    "bad create user request",
 				command: `CREATE USER 0xBAD WITH PASSWORD pwd1337`,
-				exp:     `{"error":"error parsing query: found 0xBAD, expected identifier at line 1, char 13"}`,
+				exp:     `{"error":"error parsing query: found 0xBAD, expected identifier at line 1, char 13","code":"invalid_query"}`,
 			},
 			&Query{
 				name:    "bad create user request, no name",
 				command: `CREATE USER WITH PASSWORD pwd1337`,
-				exp:     `{"error":"error parsing query: found WITH, expected identifier at line 1, char 13"}`,
+				exp:     `{"error":"error parsing query: found WITH, expected identifier at line 1, char 13","code":"invalid_query"}`,
 			},
 			&Query{
 				name:    "bad create user request, no password",
 				command: `CREATE USER jdoe`,
-				exp:     `{"error":"error parsing query: found EOF, expected WITH at line 1, char 18"}`,
+				exp:     `{"error":"error parsing query: found EOF, expected WITH at line 1, char 18","code":"invalid_query"}`,
 			},
 			&Query{
 				name:    "drop user",
@@ -790,7 +790,8 @@ func TestServer_Query_DefaultDBAndRP(t *testing.T) {
 		&Query{
 			name:    "default rp exists",
 			command: `show retention policies ON db0`,
-			exp:     `{"results":[{"statement_id":0,"series":[{"columns":["name","duration","shardGroupDuration","replicaN","default"],"values":[["autogen","0s","168h0m0s",1,false],["rp0","0s","168h0m0s",1,true]]}]}]}`,
+			exp:     `^{"results":\[{"statement_id":0,"series":\[{"columns":\["name","duration","shardGroupDuration","replicaN","default","shardGroupN","shardN","diskBytes"\],"values":\[\["autogen","0s","168h0m0s",1,false,0,0,0\],\["rp0","0s","168h0m0s",1,true,1,1,[1-9][0-9]*\]\]}\]}\]}$`,
+			pattern: true,
 		},
 		&Query{
 			name:    "default rp",
@@ -1615,12 +1616,12 @@ func TestServer_Query_Common(t *testing.T) {
 		&Query{
 			name:    "selecting a from a non-existent database should error",
 			command: `SELECT value FROM db1.rp0.cpu`,
-			exp:     `{"results":[{"statement_id":0,"error":"database not found: db1"}]}`,
+			exp:     `{"results":[{"statement_id":0,"error":"database not found: db1","code":"database_not_found"}]}`,
 		},
 		&Query{
 			name:    "selecting a from a non-existent retention policy should error",
 			command: `SELECT value FROM db0.rp1.cpu`,
-			exp:     `{"results":[{"statement_id":0,"error":"retention policy not found: rp1"}]}`,
+			exp:     `{"results":[{"statement_id":0,"error":"retention policy not found: rp1","code":"retention_policy_not_found"}]}`,
 		},
 		&Query{
 			name:    "selecting a valid  measurement and field should succeed",
@@ -8114,6 +8115,70 @@ func TestServer_Query_ShowTagValues(t *testing.T) {
 	}
 }
 
+// Ensure SHOW TAG KEYS and SHOW TAG VALUES use the retention policy in the FROM clause.
+func TestServer_Query_ShowTags_RetentionPolicy(t *testing.T) {
+	t.Parallel()
+	s := OpenServer(NewConfig())
+	defer s.Close()
+
+	if err := s.CreateDatabaseAndRetentionPolicy("db0", newRetentionPolicySpec("rp0", 1, 0), true); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.CreateDatabaseAndRetentionPolicy("db0", newRetentionPolicySpec("rp1", 1, 0), false); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.CreateDatabaseAndRetentionPolicy("db0", newRetentionPolicySpec("rp2", 1, 0), false); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.Write("db0", "rp0", fmt.Sprintf(`cpu,host=server01 value=100 %d`, mustParseTime(time.RFC3339Nano, "2009-11-10T23:00:00Z").UnixNano()), nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Write("db0", "rp1", fmt.Sprintf(`cpu,region=uswest value=100 %d`, mustParseTime(time.RFC3339Nano, "2009-11-10T23:00:00Z").UnixNano()), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	test := NewTest("db0", "rp0")
+
+	test.addQueries([]*Query{
+		&Query{
+			name:    "show tag keys from empty retention policy",
+			command: "SHOW TAG KEYS FROM rp2.cpu",
+			exp:     `{"results":[{"statement_id":0}]}`,
+			params:  url.Values{"db": []string{"db0"}},
+		},
+		&Query{
+			name:    "show tag keys from database and empty retention policy",
+			command: `SHOW TAG KEYS FROM "db0"."rp2"."cpu"`,
+			exp:     `{"results":[{"statement_id":0}]}`,
+		},
+		&Query{
+			name:    "show tag values from retention policy",
+			command: `SHOW TAG VALUES FROM "db0"."rp1"."cpu" WITH KEY = "region"`,
+			exp:     `{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["key","value"],"values":[["region","uswest"]]}]}]}`,
+		},
+		&Query{
+			name:    "show tag keys from multiple retention policies",
+			command: "SHOW TAG KEYS FROM rp1.cpu, rp0.cpu",
+			exp:     `{"results":[{"statement_id":0,"error":"multiple retention policies not supported: rp1, rp0"}]}`,
+			params:  url.Values{"db": []string{"db0"}},
+		},
+	}...)
+
+	for _, query := range test.queries {
+		t.Run(query.name, func(t *testing.T) {
+			if query.skip {
+				t.Skipf("SKIP:: %s", query.name)
+			}
+			if err := query.Execute(s); err != nil {
+				t.Error(query.Error(err))
+			} else if !query.success() {
+				t.Error(query.failureMessage())
+			}
+		})
+	}
+}
+
 func TestServer_Query_ShowTagKeyCardinality(t *testing.T) {
 	t.Parallel()
 	s := OpenServer(NewConfig())
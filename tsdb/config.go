@@ -16,6 +16,10 @@ const (
 	// DefaultIndex is the default index for new shards
 	DefaultIndex = "inmem"
 
+	// InmemEngine is the engine that keeps the data of a shard in memory
+	// only. Its shards have no files and are lost when the store is closed.
+	InmemEngine = "inmem"
+
 	// tsdb/engine/wal configuration options
 
 	// Default settings for TSM
@@ -62,6 +66,19 @@ type Config struct {
 	// General WAL configuration options
 	WALDir string `toml:"wal-dir"`
 
+	// TrashDir is where the shards of dropped databases are kept for
+	// TrashRetention so the databases can be undropped.  It must be on the
+	// same filesystem as Dir.  A TrashRetention of 0 removes dropped
+	// databases immediately.
+	TrashDir       string        `toml:"trash-dir"`
+	TrashRetention toml.Duration `toml:"trash-retention"`
+
+	// InmemDatabases are the databases whose shards use the inmem engine.
+	// Their data is kept in memory only, up to CacheMaxMemorySize per shard,
+	// and is lost when the process exits.  Dropped databases in this list are
+	// never moved to TrashDir.
+	InmemDatabases []string `toml:"inmem-databases"`
+
 	// WALFsyncDelay is the amount of time that a write will wait before fsyncing.  A duration
 	// greater than 0 can be used to batch up multiple fsync calls.  This is useful for slower
 	// disks or when WAL write contention is seen.  A value of 0 fsyncs every write to the WAL.
@@ -76,6 +93,12 @@ type Config struct {
 	CacheSnapshotWriteColdDuration toml.Duration `toml:"cache-snapshot-write-cold-duration"`
 	CompactFullWriteColdDuration   toml.Duration `toml:"compact-full-write-cold-duration"`
 
+	// BlockCacheMaxMemorySize is the maximum size of the cache of decoded TSM
+	// blocks shared by all shards.  Queries that repeatedly read the same
+	// blocks are served from the cache and blocks are read ahead when a query
+	// reads a series sequentially.  A value of 0 disables the cache.
+	BlockCacheMaxMemorySize toml.Size `toml:"block-cache-max-memory-size"`
+
 	// Limits
 
 	// MaxSeriesPerDatabase is the maximum number of series a node can hold per database.
@@ -94,7 +117,43 @@ type Config struct {
 	// not affected by this limit.  A value of 0 limits compactions to runtime.GOMAXPROCS(0).
 	MaxConcurrentCompactions int `toml:"max-concurrent-compactions"`
 
+	// BackgroundThroughput is the maximum number of bytes per second that background
+	// jobs can write.  It is shared by compactions and the results written by batch
+	// queries such as continuous queries.  Snapshots of the cache are not limited, and
+	// neither are shard deletes, as removing a file does not write its size to disk.
+	// A value of 0 disables the limit.
+	BackgroundThroughput toml.Size `toml:"background-throughput"`
+
+	// MaxConcurrentQueriesPerShard is the maximum number of queries that can
+	// read a shard at the same time.  Queries over the limit fail instead of
+	// competing with the writes and compactions of a hot shard.
+	// A value of 0 disables the limit.
+	MaxConcurrentQueriesPerShard int `toml:"max-concurrent-queries-per-shard"`
+
+	// MaxCursorsPerShard is the maximum number of series cursors that queries
+	// can have open on a shard at the same time.  A value of 0 disables the limit.
+	MaxCursorsPerShard int `toml:"max-cursors-per-shard"`
+
+	// DeleteSeriesBatchSize is the number of series deleted from a shard at a
+	// time by DROP SERIES and DELETE.  A value of 0 deletes all matching series
+	// of a shard at once.
+	DeleteSeriesBatchSize int `toml:"delete-series-batch-size"`
+
+	// DeleteSeriesRate is the maximum number of series per second that a
+	// DROP SERIES or DELETE statement deletes.  A value of 0 disables the limit.
+	DeleteSeriesRate int `toml:"delete-series-rate"`
+
+	// IndexMemoryStatsEnabled reports the estimated memory used by the inmem index
+	// for each database, measurement and tag key in the statistics.  Computing the
+	// estimates walks the entire index so it is disabled by default.
+	IndexMemoryStatsEnabled bool `toml:"index-memory-stats-enabled"`
+
 	TraceLoggingEnabled bool `toml:"trace-logging-enabled"`
+
+	// ProfileLabelsEnabled attaches a profiler label to each stage of the write
+	// path (parse, wal, cache, snapshot and compaction) so profiles can be broken
+	// down by stage.
+	ProfileLabelsEnabled bool `toml:"profile-labels-enabled"`
 }
 
 // NewConfig returns the default configuration for tsdb.
@@ -130,6 +189,24 @@ func (c *Config) Validate() error {
 		return errors.New("max-concurrent-compactions must be greater than 0")
 	}
 
+	if c.MaxConcurrentQueriesPerShard < 0 {
+		return errors.New("max-concurrent-queries-per-shard must be greater than or equal to 0")
+	} else if c.MaxCursorsPerShard < 0 {
+		return errors.New("max-cursors-per-shard must be greater than or equal to 0")
+	}
+
+	if c.DeleteSeriesBatchSize < 0 {
+		return errors.New("delete-series-batch-size must be greater than or equal to 0")
+	} else if c.DeleteSeriesRate < 0 {
+		return errors.New("delete-series-rate must be greater than or equal to 0")
+	}
+
+	if c.TrashRetention < 0 {
+		return errors.New("trash-retention must be greater than or equal to 0")
+	} else if c.TrashRetention > 0 && c.TrashDir == "" {
+		return errors.New("Data.TrashDir must be specified when trash-retention is set")
+	}
+
 	valid := false
 	for _, e := range RegisteredEngines() {
 		if e == c.Engine {
@@ -161,12 +238,23 @@ func (c Config) Diagnostics() (*diagnostics.Diagnostics, error) {
 		"dir":                                c.Dir,
 		"wal-dir":                            c.WALDir,
 		"wal-fsync-delay":                    c.WALFsyncDelay,
+		"trash-dir":                          c.TrashDir,
+		"trash-retention":                    c.TrashRetention,
+		"inmem-databases":                    c.InmemDatabases,
 		"cache-max-memory-size":              c.CacheMaxMemorySize,
 		"cache-snapshot-memory-size":         c.CacheSnapshotMemorySize,
 		"cache-snapshot-write-cold-duration": c.CacheSnapshotWriteColdDuration,
 		"compact-full-write-cold-duration":   c.CompactFullWriteColdDuration,
+		"block-cache-max-memory-size":        c.BlockCacheMaxMemorySize,
 		"max-series-per-database":            c.MaxSeriesPerDatabase,
 		"max-values-per-tag":                 c.MaxValuesPerTag,
 		"max-concurrent-compactions":         c.MaxConcurrentCompactions,
+		"background-throughput":              c.BackgroundThroughput,
+		"max-concurrent-queries-per-shard":   c.MaxConcurrentQueriesPerShard,
+		"max-cursors-per-shard":              c.MaxCursorsPerShard,
+		"delete-series-batch-size":           c.DeleteSeriesBatchSize,
+		"delete-series-rate":                 c.DeleteSeriesRate,
+		"profile-labels-enabled":             c.ProfileLabelsEnabled,
+		"index-memory-stats-enabled":         c.IndexMemoryStatsEnabled,
 	}), nil
 }
@@ -0,0 +1,270 @@
+package tsdb
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/influxdata/influxdb/pkg/bytesutil"
+	"github.com/influxdata/influxdb/pkg/limiter"
+	"github.com/influxdata/influxdb/query"
+	"github.com/influxdata/influxql"
+	"github.com/uber-go/zap"
+)
+
+// deleteSeriesLogPrefix is the prefix of the work logs of series deletes in
+// the directory of a database.
+const deleteSeriesLogPrefix = "delete-series-"
+
+// DeleteSeriesProgressFunc is called by DeleteSeriesWithProgress after each
+// batch of series is deleted.
+type DeleteSeriesProgressFunc func(shardsDone, shardsTotal int, series int64)
+
+// deleteSeriesLog is the work log of a series delete. It is written to disk
+// before the delete starts and updated after each shard, so a delete that is
+// interrupted by a restart is resumed when the store is opened.
+type deleteSeriesLog struct {
+	Database  string   `json:"database"`
+	Names     []string `json:"names,omitempty"` // all measurements when empty
+	Condition string   `json:"condition,omitempty"`
+	Min       int64    `json:"min"`
+	Max       int64    `json:"max"`
+	Shards    []uint64 `json:"shards"` // shards left to delete from
+
+	path string // empty if the database is kept in memory
+}
+
+func (l *deleteSeriesLog) save() error {
+	if l.path == "" {
+		return nil
+	}
+
+	buf, err := json.Marshal(l)
+	if err != nil {
+		return err
+	}
+
+	// Write to a temporary file first so a crash does not leave a partial log.
+	tmp := l.path + ".tmp"
+	if err := ioutil.WriteFile(tmp, buf, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, l.path)
+}
+
+// remove removes the log from disk.
+func (l *deleteSeriesLog) remove() error {
+	if l.path == "" {
+		return nil
+	}
+	return os.Remove(l.path)
+}
+
+// isDeleteSeriesLog returns true if name is the file name of a work log.
+func isDeleteSeriesLog(name string) bool {
+	return strings.HasPrefix(name, deleteSeriesLogPrefix)
+}
+
+// DeleteSeriesWithProgress deletes the series data like DeleteSeries. The
+// shards are processed one at a time and the series of each measurement are
+// deleted in batches of the configured delete-series-batch-size, at no more
+// than delete-series-rate series per second. progress, if not nil, is called
+// after each batch. The delete stops with query.ErrQueryInterrupted between
+// batches once interrupt is closed; the batches already deleted are not
+// restored.
+func (s *Store) DeleteSeriesWithProgress(database string, sources []influxql.Source, condition influxql.Expr, progress DeleteSeriesProgressFunc, interrupt <-chan struct{}) error {
+	// Expand regex expressions in the FROM clause.
+	a, err := s.ExpandSources(sources)
+	if err != nil {
+		return err
+	} else if sources != nil && len(sources) != 0 && len(a) == 0 {
+		return nil
+	}
+
+	// Determine deletion time range.
+	condition, min, max, err := deleteTimeRange(condition)
+	if err != nil {
+		return err
+	}
+
+	s.mu.RLock()
+	shards := s.filterShards(byDatabase(database))
+	s.mu.RUnlock()
+	if len(shards) == 0 {
+		return nil
+	}
+
+	// There is nothing to resume after a restart for a database kept in
+	// memory, so its log is not written.
+	l := &deleteSeriesLog{
+		Database: database,
+		Min:      min,
+		Max:      max,
+	}
+	if !s.isInmemDatabase(database) {
+		l.path = filepath.Join(s.path, database, fmt.Sprintf("%s%d.json", deleteSeriesLogPrefix, time.Now().UnixNano()))
+	}
+	for _, source := range a {
+		l.Names = append(l.Names, source.(*influxql.Measurement).Name)
+	}
+	if condition != nil {
+		l.Condition = condition.String()
+	}
+	for _, sh := range shards {
+		l.Shards = append(l.Shards, sh.ID())
+	}
+	sort.Slice(l.Shards, func(i, j int) bool { return l.Shards[i] < l.Shards[j] })
+
+	if err := l.save(); err != nil {
+		return err
+	}
+	return s.deleteSeries(l, condition, progress, interrupt)
+}
+
+// deleteSeries runs the delete of a work log and removes the log. The log is
+// kept if the store is closed during the delete so it can be resumed.
+func (s *Store) deleteSeries(l *deleteSeriesLog, condition influxql.Expr, progress DeleteSeriesProgressFunc, interrupt <-chan struct{}) error {
+	if err := s.deleteSeriesShards(l, condition, progress, interrupt); err == ErrStoreClosed {
+		return err
+	} else if err != nil {
+		l.remove()
+		return err
+	}
+	return l.remove()
+}
+
+func (s *Store) deleteSeriesShards(l *deleteSeriesLog, condition influxql.Expr, progress DeleteSeriesProgressFunc, interrupt <-chan struct{}) error {
+	sources := make([]influxql.Source, 0, len(l.Names))
+	for _, name := range l.Names {
+		sources = append(sources, &influxql.Measurement{Name: name})
+	}
+
+	var rate *limiter.Rate
+	if n := s.EngineOptions.Config.DeleteSeriesRate; n > 0 {
+		rate = limiter.NewRate(n, 0)
+	}
+	batchSize := s.EngineOptions.Config.DeleteSeriesBatchSize
+
+	total := len(l.Shards)
+	var n int64
+	for len(l.Shards) > 0 {
+		// Shards dropped since the delete started have nothing to delete.
+		if sh := s.Shard(l.Shards[0]); sh != nil {
+			err := forEachSeriesToDelete(sh, sources, condition, func(_ string, keys [][]byte) error {
+				if !bytesutil.IsSorted(keys) {
+					bytesutil.Sort(keys)
+				}
+
+				for len(keys) > 0 {
+					batch := keys
+					if batchSize > 0 && len(batch) > batchSize {
+						batch = batch[:batchSize]
+					}
+					keys = keys[len(batch):]
+
+					rate.WaitN(len(batch))
+					if err := s.deleteSeriesStopped(interrupt); err != nil {
+						return err
+					}
+
+					if err := sh.DeleteSeriesRange(batch, l.Min, l.Max); err != nil {
+						return err
+					}
+					n += int64(len(batch))
+					if progress != nil {
+						progress(total-len(l.Shards), total, n)
+					}
+				}
+				return nil
+			})
+			if serr := s.deleteSeriesStopped(interrupt); serr != nil {
+				return serr
+			} else if err != nil && err != ErrEngineClosed {
+				return fmt.Errorf("shard %d: %s", sh.ID(), err)
+			}
+		}
+
+		l.Shards = l.Shards[1:]
+		if progress != nil {
+			progress(total-len(l.Shards), total, n)
+		}
+		if len(l.Shards) > 0 {
+			if err := l.save(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// deleteSeriesStopped returns ErrStoreClosed if the store is being closed or
+// query.ErrQueryInterrupted if interrupt is closed.
+func (s *Store) deleteSeriesStopped(interrupt <-chan struct{}) error {
+	if s.isClosing() {
+		return ErrStoreClosed
+	}
+	select {
+	case <-interrupt:
+		return query.ErrQueryInterrupted
+	default:
+		return nil
+	}
+}
+
+// isClosing returns true if the store is being closed.
+func (s *Store) isClosing() bool {
+	select {
+	case <-s.closing:
+		return true
+	default:
+		return false
+	}
+}
+
+// resumeDeleteSeries restarts the series deletes whose work logs were left
+// by a previous run of the store. The deletes run in the background.
+func (s *Store) resumeDeleteSeries() error {
+	paths, err := filepath.Glob(filepath.Join(s.path, "*", deleteSeriesLogPrefix+"*.json"))
+	if err != nil {
+		return err
+	}
+
+	for _, path := range paths {
+		buf, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		l := &deleteSeriesLog{path: path}
+		if err := json.Unmarshal(buf, l); err != nil {
+			return fmt.Errorf("delete series log %s: %s", path, err)
+		}
+
+		var condition influxql.Expr
+		if l.Condition != "" {
+			if condition, err = influxql.ParseExpr(l.Condition); err != nil {
+				return fmt.Errorf("delete series log %s: %s", path, err)
+			}
+		}
+
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+
+			s.Logger.Info(fmt.Sprintf("Resuming series delete in database %s on %d shards", l.Database, len(l.Shards)))
+			if err := s.deleteSeries(l, condition, nil, nil); err == ErrStoreClosed {
+				return
+			} else if err != nil {
+				s.Logger.Error("Failed to resume series delete", zap.String("database", l.Database), zap.Error(err))
+				return
+			}
+			s.Logger.Info(fmt.Sprintf("Finished series delete in database %s", l.Database))
+		}()
+	}
+	return nil
+}
@@ -1,5 +1,57 @@
 /*
 Package tsdb implements a durable time series database.
 
+# Embedding
+
+A Store can be embedded in another program without the rest of the
+server. The storage engines and indexes register themselves with this
+package when they are imported, so a program must import them for their
+side effects:
+
+	import (
+		"github.com/influxdata/influxdb/tsdb"
+		_ "github.com/influxdata/influxdb/tsdb/engine"
+		_ "github.com/influxdata/influxdb/tsdb/index"
+	)
+
+A store is created with NewStore, configured through its EngineOptions
+field before it is opened and closed when it is no longer used:
+
+	s := tsdb.NewStore("/var/lib/myapp/data")
+	s.EngineOptions.Config.WALDir = "/var/lib/myapp/wal"
+	if err := s.Open(); err != nil {
+		return err
+	}
+	defer s.Close()
+
+Setting EngineOptions.EngineVersion to InmemEngine, or listing databases in
+EngineOptions.Config.InmemDatabases, keeps their shards in memory only.
+Nothing is written to disk for them, which suits tests and ephemeral data.
+
+Data is organized in shards, which belong to a retention policy of a
+database. The caller decides which shard holds which points and creates
+shards with CreateShard before writing to them with WriteToShard. Points
+are read with the iterators of the ShardGroup returned by ShardGroup. To
+execute InfluxQL queries, a query.QueryExecutor is combined with a
+coordinator.StatementExecutor, which maps the shards of a query through a
+meta client. Package tsdbtest wires both together over a temporary store
+for tests.
+
+# Stability
+
+The following API follows semantic versioning and is not changed in a
+backwards incompatible way within a major version:
+
+  - NewStore and the exported methods and fields of Store;
+  - the methods of Shard and ShardGroup used to read and write points;
+  - Config, EngineOptions and NewEngineOptions; new options may be added
+    and their zero values keep the previous behavior;
+  - the exported errors, such as ErrShardNotFound, ErrStoreClosed and
+    PartialWriteError, which may be compared or type asserted.
+
+The Engine and Index interfaces and the packages implementing them are
+internal to the database and may change between minor versions. The
+methods of the store return errors rather than panicking, including
+when no engine or index has been registered for the configured format.
 */
 package tsdb
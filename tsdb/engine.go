@@ -70,6 +70,7 @@ type Engine interface {
 	MeasurementTagKeyValuesByExpr(auth query.Authorizer, name []byte, key []string, expr influxql.Expr, keysSorted bool) ([][]string, error)
 	ForEachMeasurementTagKey(name []byte, fn func(key []byte) error) error
 	TagKeyCardinality(name, key []byte) int
+	MeasurementSeriesN(name []byte) int
 
 	// InfluxQL iterators
 	MeasurementSeriesKeysByExpr(name []byte, condition influxql.Expr) ([][]byte, error)
@@ -81,6 +82,8 @@ type Engine interface {
 	DiskSize() int64
 	IsIdle() bool
 	Free() error
+	Tier(prefix string) (int, error)
+	Clone(path, prefix string) error
 
 	io.WriterTo
 }
@@ -122,7 +125,11 @@ func RegisteredEngines() []string {
 func NewEngine(id uint64, i Index, database, path string, walPath string, options EngineOptions) (Engine, error) {
 	// Create a new engine
 	if _, err := os.Stat(path); os.IsNotExist(err) {
-		return newEngineFuncs[options.EngineVersion](id, i, database, path, walPath, options), nil
+		fn := newEngineFuncs[options.EngineVersion]
+		if fn == nil {
+			return nil, fmt.Errorf("invalid engine format: %q", options.EngineVersion)
+		}
+		return fn(id, i, database, path, walPath, options), nil
 	}
 
 	// If it's a dir then it's a tsm1 engine
@@ -153,9 +160,37 @@ type EngineOptions struct {
 
 	CompactionLimiter limiter.Fixed
 
+	// BackgroundRate limits the throughput of background jobs such as
+	// compactions and continuous queries.
+	BackgroundRate *limiter.Rate
+
+	// ObjectStore stores the data of the files that shards have moved to
+	// object storage. It is nil if tiering is not configured.
+	ObjectStore ObjectStore
+
+	// BlockCache is the cache of decoded blocks shared by the engines of a
+	// store. It is nil if the cache is disabled.
+	BlockCache interface{}
+
 	Config Config
 }
 
+// ObjectStore is an object store that the data files of cold shards are
+// moved to.
+type ObjectStore interface {
+	// Upload stores the content of r as the object key and returns its size.
+	Upload(key string, r io.Reader) (int64, error)
+
+	// ReadAt reads len(p) bytes of the object key starting at off.
+	ReadAt(key string, p []byte, off int64) (int, error)
+
+	// Delete removes the object key.
+	Delete(key string) error
+
+	// Copy copies the object src to dst.
+	Copy(src, dst string) error
+}
+
 // NewEngineOptions returns the default options.
 func NewEngineOptions() EngineOptions {
 	return EngineOptions{
@@ -167,3 +202,7 @@ func NewEngineOptions() EngineOptions {
 
 // NewInmemIndex returns a new "inmem" index type.
 var NewInmemIndex func(name string) (interface{}, error)
+
+// NewBlockCache returns a new block cache of the engine holding up to maxSize
+// bytes.
+var NewBlockCache func(maxSize int64) interface{}
@@ -0,0 +1,289 @@
+package tsm1
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/pkg/metrics"
+	"github.com/influxdata/influxdb/query"
+	"github.com/influxdata/influxdb/tsdb"
+	"github.com/influxdata/influxql"
+)
+
+// batchCallIterator computes count(), sum() or mean() of a single series for
+// each window of a query. Rather than boxing every point and passing it to a
+// reducer, it reads the decoded values of the series a batch at a time from a
+// batch cursor and aggregates them in tight loops over the value slices.
+type batchCallIterator struct {
+	call string
+	opt  query.IteratorOptions
+
+	// Exactly one of the cursors is set.
+	floats   tsdb.FloatBatchCursor
+	integers tsdb.IntegerBatchCursor
+
+	// Current batch and the position of the next value in it.
+	keys   []int64
+	fvals  []float64
+	ivals  []int64
+	pos    int
+	closed bool // no more values within the time range
+
+	// Aggregate of the current window.
+	window struct {
+		start int64
+		n     int64
+		fsum  float64
+		isum  int64
+	}
+
+	fpoint query.FloatPoint
+	ipoint query.IntegerPoint
+	stats  query.IteratorStats
+}
+
+// isBatchCall returns true if call can be computed with a batchCallIterator.
+func isBatchCall(call *influxql.Call) bool {
+	switch call.Name {
+	case "count", "sum", "mean":
+		return true
+	}
+	return false
+}
+
+func newBatchCallIterator(name string, tags query.Tags, call string, opt query.IteratorOptions) *batchCallIterator {
+	if opt.StripName {
+		name = ""
+	}
+	return &batchCallIterator{
+		call:   call,
+		opt:    opt,
+		fpoint: query.FloatPoint{Name: name, Tags: tags},
+		ipoint: query.IntegerPoint{Name: name, Tags: tags},
+		stats:  query.IteratorStats{SeriesN: 1},
+	}
+}
+
+// Stats returns stats on the points processed.
+func (itr *batchCallIterator) Stats() query.IteratorStats { return itr.stats }
+
+// Close closes the iterator.
+func (itr *batchCallIterator) Close() error {
+	if itr.floats != nil {
+		itr.floats.Close()
+		itr.floats = nil
+	}
+	if itr.integers != nil {
+		itr.integers.Close()
+		itr.integers = nil
+	}
+	itr.closed = true
+	return nil
+}
+
+// nextBatch reads the next batch of values from the cursor.
+func (itr *batchCallIterator) nextBatch() error {
+	select {
+	case <-itr.opt.InterruptCh:
+		return query.ErrQueryInterrupted
+	default:
+	}
+
+	if itr.floats != nil {
+		itr.keys, itr.fvals = itr.floats.Next()
+	} else if itr.integers != nil {
+		itr.keys, itr.ivals = itr.integers.Next()
+	} else {
+		itr.keys = nil
+	}
+	itr.pos = 0
+	itr.stats.PointN += len(itr.keys)
+	if len(itr.keys) == 0 {
+		itr.closed = true
+	}
+	return nil
+}
+
+// inRange returns true if t is within the time range of the query.
+func (itr *batchCallIterator) inRange(t int64) bool {
+	return t >= itr.opt.StartTime && t <= itr.opt.EndTime
+}
+
+// nextWindow aggregates the values of the next window. It returns false when
+// there are no more windows.
+func (itr *batchCallIterator) nextWindow() (bool, error) {
+	itr.window.n, itr.window.fsum, itr.window.isum = 0, 0, 0
+
+	var start, end int64
+	for !itr.closed {
+		if itr.pos >= len(itr.keys) {
+			if err := itr.nextBatch(); err != nil {
+				return false, err
+			}
+			continue
+		}
+
+		// Points outside of the time range are skipped until the cursor
+		// has moved past the end of the range.
+		i, keys := itr.pos, itr.keys
+		if !itr.inRange(keys[i]) {
+			if (itr.opt.Ascending && keys[i] > itr.opt.EndTime) || (!itr.opt.Ascending && keys[i] < itr.opt.StartTime) {
+				itr.closed = true
+				break
+			}
+			itr.pos++
+			continue
+		}
+
+		if itr.window.n == 0 {
+			start, end = itr.opt.Window(keys[i])
+			itr.window.start = start
+		}
+
+		// Find the run of values in this batch that belong to the window.
+		j := i
+		for j < len(keys) && keys[j] >= start && keys[j] < end && itr.inRange(keys[j]) {
+			j++
+		}
+
+		if itr.call != "count" {
+			if itr.floats != nil {
+				var sum float64
+				for _, v := range itr.fvals[i:j] {
+					sum += v
+				}
+				itr.window.fsum += sum
+			} else {
+				var sum int64
+				for _, v := range itr.ivals[i:j] {
+					sum += v
+				}
+				itr.window.isum += sum
+			}
+		}
+		itr.window.n += int64(j - i)
+		itr.pos = j
+
+		// The window is complete if it ended within the batch.
+		if j < len(keys) {
+			break
+		}
+	}
+	return itr.window.n > 0, nil
+}
+
+// batchFloatCallIterator returns the windows of a batchCallIterator as float
+// points. It is used for sum() of float fields and mean().
+type batchFloatCallIterator struct {
+	*batchCallIterator
+}
+
+// Next returns the aggregate of the next window.
+func (itr batchFloatCallIterator) Next() (*query.FloatPoint, error) {
+	if ok, err := itr.nextWindow(); err != nil || !ok {
+		return nil, err
+	}
+
+	p := &itr.fpoint
+	p.Time = itr.window.start
+	p.Aggregated = uint32(itr.window.n)
+	switch {
+	case itr.call == "mean" && itr.floats != nil:
+		p.Value = itr.window.fsum / float64(itr.window.n)
+	case itr.call == "mean":
+		p.Value = float64(itr.window.isum) / float64(itr.window.n)
+	default:
+		p.Value = itr.window.fsum
+	}
+	return p, nil
+}
+
+// batchIntegerCallIterator returns the windows of a batchCallIterator as
+// integer points. It is used for count() and sum() of integer fields.
+type batchIntegerCallIterator struct {
+	*batchCallIterator
+}
+
+// Next returns the aggregate of the next window.
+func (itr batchIntegerCallIterator) Next() (*query.IntegerPoint, error) {
+	if ok, err := itr.nextWindow(); err != nil || !ok {
+		return nil, err
+	}
+
+	p := &itr.ipoint
+	p.Time = itr.window.start
+	p.Aggregated = uint32(itr.window.n)
+	if itr.call == "count" {
+		p.Value = itr.window.n
+	} else {
+		p.Value = itr.window.isum
+	}
+	return p, nil
+}
+
+// createBatchCallIterators returns an iterator computing call for each series
+// of t using batch cursors. It returns false if the call cannot be computed
+// that way, such as when the series are filtered by field values, in which
+// case the caller must fall back to reducing the points of each series.
+func (e *Engine) createBatchCallIterators(ctx context.Context, measurement string, call *influxql.Call, ref *influxql.VarRef, t *query.TagSet, opt query.IteratorOptions) ([]query.Iterator, bool, error) {
+	if ref == nil || !isBatchCall(call) || len(opt.Aux) > 0 {
+		return nil, false, nil
+	}
+	switch ref.Val {
+	case "_name", "_tagKey", "_tagValue", "_seriesKey", "_fieldKey":
+		return nil, false, nil
+	}
+	for _, filter := range t.Filters {
+		if filter != nil {
+			return nil, false, nil
+		}
+	}
+
+	mf := e.fieldset.Fields(measurement)
+	if mf == nil {
+		return nil, true, nil
+	}
+	f := mf.Field(ref.Val)
+	if f == nil {
+		return nil, true, nil
+	} else if f.Type != influxql.Float && f.Type != influxql.Integer {
+		return nil, false, nil
+	} else if ref.Type != influxql.Unknown && ref.Type != influxql.AnyField && ref.Type != f.Type {
+		return nil, false, nil
+	}
+
+	var curCounter *metrics.Counter
+	if col := metrics.GroupFromContext(ctx); col != nil {
+		curCounter = col.GetCounter(numberOfRefCursorsCounter)
+	}
+
+	dimensions := opt.GetDimensions()
+	itrs := make([]query.Iterator, 0, len(t.SeriesKeys))
+	for _, seriesKey := range t.SeriesKeys {
+		_, tfs := models.ParseKey([]byte(seriesKey))
+		tags := query.NewTags(tfs.Map())
+		itr := newBatchCallIterator(measurement, tags.Subset(dimensions), call.Name, opt)
+		if f.Type == influxql.Float {
+			itr.floats = e.buildFloatBatchCursor(ctx, measurement, seriesKey, ref.Val, opt)
+		} else {
+			itr.integers = e.buildIntegerBatchCursor(ctx, measurement, seriesKey, ref.Val, opt)
+		}
+		if curCounter != nil {
+			curCounter.Add(1)
+		}
+
+		if call.Name == "count" || (call.Name == "sum" && f.Type == influxql.Integer) {
+			itrs = append(itrs, batchIntegerCallIterator{itr})
+		} else {
+			itrs = append(itrs, batchFloatCallIterator{itr})
+		}
+
+		// Enforce series limit at creation time.
+		if opt.MaxSeriesN > 0 && len(itrs) > opt.MaxSeriesN {
+			query.Iterators(itrs).Close()
+			return nil, false, fmt.Errorf("max-select-series limit exceeded: (%d/%d)", len(itrs), opt.MaxSeriesN)
+		}
+	}
+	return itrs, true, nil
+}
@@ -17,80 +17,80 @@ import (
 // buildFloatCursor creates a cursor for a float field.
 func (e *Engine) buildFloatCursor(ctx context.Context, measurement, seriesKey, field string, opt query.IteratorOptions) floatCursor {
 	key := SeriesFieldKeyBytes(seriesKey, field)
-	cacheValues := e.Cache.Values(key)
-	keyCursor := e.KeyCursor(ctx, key, opt.SeekTime(), opt.Ascending)
+	cacheValues := e.cacheValues(ctx, key)
+	keyCursor := e.KeyCursorRange(ctx, key, opt.SeekTime(), opt.StopTime(), opt.Ascending)
 	return newFloatCursor(opt.SeekTime(), opt.Ascending, cacheValues, keyCursor)
 }
 
 // buildFloatBatchCursor creates a batch cursor for a float field.
 func (e *Engine) buildFloatBatchCursor(ctx context.Context, measurement, seriesKey, field string, opt query.IteratorOptions) tsdb.FloatBatchCursor {
 	key := SeriesFieldKeyBytes(seriesKey, field)
-	cacheValues := e.Cache.Values(key)
-	keyCursor := e.KeyCursor(ctx, key, opt.SeekTime(), opt.Ascending)
+	cacheValues := e.cacheValues(ctx, key)
+	keyCursor := e.KeyCursorRange(ctx, key, opt.SeekTime(), opt.StopTime(), opt.Ascending)
 	return newFloatBatchCursor(seriesKey, opt.SeekTime(), opt.Ascending, cacheValues, keyCursor)
 }
 
 // buildIntegerCursor creates a cursor for a integer field.
 func (e *Engine) buildIntegerCursor(ctx context.Context, measurement, seriesKey, field string, opt query.IteratorOptions) integerCursor {
 	key := SeriesFieldKeyBytes(seriesKey, field)
-	cacheValues := e.Cache.Values(key)
-	keyCursor := e.KeyCursor(ctx, key, opt.SeekTime(), opt.Ascending)
+	cacheValues := e.cacheValues(ctx, key)
+	keyCursor := e.KeyCursorRange(ctx, key, opt.SeekTime(), opt.StopTime(), opt.Ascending)
 	return newIntegerCursor(opt.SeekTime(), opt.Ascending, cacheValues, keyCursor)
 }
 
 // buildIntegerBatchCursor creates a batch cursor for a integer field.
 func (e *Engine) buildIntegerBatchCursor(ctx context.Context, measurement, seriesKey, field string, opt query.IteratorOptions) tsdb.IntegerBatchCursor {
 	key := SeriesFieldKeyBytes(seriesKey, field)
-	cacheValues := e.Cache.Values(key)
-	keyCursor := e.KeyCursor(ctx, key, opt.SeekTime(), opt.Ascending)
+	cacheValues := e.cacheValues(ctx, key)
+	keyCursor := e.KeyCursorRange(ctx, key, opt.SeekTime(), opt.StopTime(), opt.Ascending)
 	return newIntegerBatchCursor(seriesKey, opt.SeekTime(), opt.Ascending, cacheValues, keyCursor)
 }
 
 // buildUnsignedCursor creates a cursor for a unsigned field.
 func (e *Engine) buildUnsignedCursor(ctx context.Context, measurement, seriesKey, field string, opt query.IteratorOptions) unsignedCursor {
 	key := SeriesFieldKeyBytes(seriesKey, field)
-	cacheValues := e.Cache.Values(key)
-	keyCursor := e.KeyCursor(ctx, key, opt.SeekTime(), opt.Ascending)
+	cacheValues := e.cacheValues(ctx, key)
+	keyCursor := e.KeyCursorRange(ctx, key, opt.SeekTime(), opt.StopTime(), opt.Ascending)
 	return newUnsignedCursor(opt.SeekTime(), opt.Ascending, cacheValues, keyCursor)
 }
 
 // buildUnsignedBatchCursor creates a batch cursor for a unsigned field.
 func (e *Engine) buildUnsignedBatchCursor(ctx context.Context, measurement, seriesKey, field string, opt query.IteratorOptions) tsdb.UnsignedBatchCursor {
 	key := SeriesFieldKeyBytes(seriesKey, field)
-	cacheValues := e.Cache.Values(key)
-	keyCursor := e.KeyCursor(ctx, key, opt.SeekTime(), opt.Ascending)
+	cacheValues := e.cacheValues(ctx, key)
+	keyCursor := e.KeyCursorRange(ctx, key, opt.SeekTime(), opt.StopTime(), opt.Ascending)
 	return newUnsignedBatchCursor(seriesKey, opt.SeekTime(), opt.Ascending, cacheValues, keyCursor)
 }
 
 // buildStringCursor creates a cursor for a string field.
 func (e *Engine) buildStringCursor(ctx context.Context, measurement, seriesKey, field string, opt query.IteratorOptions) stringCursor {
 	key := SeriesFieldKeyBytes(seriesKey, field)
-	cacheValues := e.Cache.Values(key)
-	keyCursor := e.KeyCursor(ctx, key, opt.SeekTime(), opt.Ascending)
+	cacheValues := e.cacheValues(ctx, key)
+	keyCursor := e.KeyCursorRange(ctx, key, opt.SeekTime(), opt.StopTime(), opt.Ascending)
 	return newStringCursor(opt.SeekTime(), opt.Ascending, cacheValues, keyCursor)
 }
 
 // buildStringBatchCursor creates a batch cursor for a string field.
 func (e *Engine) buildStringBatchCursor(ctx context.Context, measurement, seriesKey, field string, opt query.IteratorOptions) tsdb.StringBatchCursor {
 	key := SeriesFieldKeyBytes(seriesKey, field)
-	cacheValues := e.Cache.Values(key)
-	keyCursor := e.KeyCursor(ctx, key, opt.SeekTime(), opt.Ascending)
+	cacheValues := e.cacheValues(ctx, key)
+	keyCursor := e.KeyCursorRange(ctx, key, opt.SeekTime(), opt.StopTime(), opt.Ascending)
 	return newStringBatchCursor(seriesKey, opt.SeekTime(), opt.Ascending, cacheValues, keyCursor)
 }
 
 // buildBooleanCursor creates a cursor for a boolean field.
 func (e *Engine) buildBooleanCursor(ctx context.Context, measurement, seriesKey, field string, opt query.IteratorOptions) booleanCursor {
 	key := SeriesFieldKeyBytes(seriesKey, field)
-	cacheValues := e.Cache.Values(key)
-	keyCursor := e.KeyCursor(ctx, key, opt.SeekTime(), opt.Ascending)
+	cacheValues := e.cacheValues(ctx, key)
+	keyCursor := e.KeyCursorRange(ctx, key, opt.SeekTime(), opt.StopTime(), opt.Ascending)
 	return newBooleanCursor(opt.SeekTime(), opt.Ascending, cacheValues, keyCursor)
 }
 
 // buildBooleanBatchCursor creates a batch cursor for a boolean field.
 func (e *Engine) buildBooleanBatchCursor(ctx context.Context, measurement, seriesKey, field string, opt query.IteratorOptions) tsdb.BooleanBatchCursor {
 	key := SeriesFieldKeyBytes(seriesKey, field)
-	cacheValues := e.Cache.Values(key)
-	keyCursor := e.KeyCursor(ctx, key, opt.SeekTime(), opt.Ascending)
+	cacheValues := e.cacheValues(ctx, key)
+	keyCursor := e.KeyCursorRange(ctx, key, opt.SeekTime(), opt.StopTime(), opt.Ascending)
 	return newBooleanBatchCursor(seriesKey, opt.SeekTime(), opt.Ascending, cacheValues, keyCursor)
 }
 
@@ -366,9 +366,10 @@ func (c *floatDescendingBatchCursor) peekCache() (t int64, v float64) {
 
 // nextCache returns the next value from the cache.
 func (c *floatDescendingBatchCursor) nextCache() {
-	if c.cache.pos > 0 {
-		c.cache.pos--
+	if c.cache.pos < 0 {
+		return
 	}
+	c.cache.pos--
 }
 
 // peekTSM returns the current time/value from tsm.
@@ -710,9 +711,10 @@ func (c *integerDescendingBatchCursor) peekCache() (t int64, v int64) {
 
 // nextCache returns the next value from the cache.
 func (c *integerDescendingBatchCursor) nextCache() {
-	if c.cache.pos > 0 {
-		c.cache.pos--
+	if c.cache.pos < 0 {
+		return
 	}
+	c.cache.pos--
 }
 
 // peekTSM returns the current time/value from tsm.
@@ -1054,9 +1056,10 @@ func (c *unsignedDescendingBatchCursor) peekCache() (t int64, v uint64) {
 
 // nextCache returns the next value from the cache.
 func (c *unsignedDescendingBatchCursor) nextCache() {
-	if c.cache.pos > 0 {
-		c.cache.pos--
+	if c.cache.pos < 0 {
+		return
 	}
+	c.cache.pos--
 }
 
 // peekTSM returns the current time/value from tsm.
@@ -1398,9 +1401,10 @@ func (c *stringDescendingBatchCursor) peekCache() (t int64, v string) {
 
 // nextCache returns the next value from the cache.
 func (c *stringDescendingBatchCursor) nextCache() {
-	if c.cache.pos > 0 {
-		c.cache.pos--
+	if c.cache.pos < 0 {
+		return
 	}
+	c.cache.pos--
 }
 
 // peekTSM returns the current time/value from tsm.
@@ -1742,9 +1746,10 @@ func (c *booleanDescendingBatchCursor) peekCache() (t int64, v bool) {
 
 // nextCache returns the next value from the cache.
 func (c *booleanDescendingBatchCursor) nextCache() {
-	if c.cache.pos > 0 {
-		c.cache.pos--
+	if c.cache.pos < 0 {
+		return
 	}
+	c.cache.pos--
 }
 
 // peekTSM returns the current time/value from tsm.
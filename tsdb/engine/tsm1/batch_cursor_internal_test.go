@@ -0,0 +1,50 @@
+package tsm1
+
+import (
+	"reflect"
+	"testing"
+)
+
+// Ensure a descending batch cursor returns the oldest cached value once and
+// then stops when it reaches the end of the cache.
+func TestFloatDescendingBatchCursor_Cache(t *testing.T) {
+	cur := newFloatDescendingBatchCursor("cpu", 30, Values{
+		NewFloatValue(10, 1.1),
+		NewFloatValue(20, 1.2),
+		NewFloatValue(30, 1.3),
+	}, &KeyCursor{})
+	defer cur.Close()
+
+	keys, values := cur.Next()
+	if exp := []int64{30, 20, 10}; !reflect.DeepEqual(keys, exp) {
+		t.Fatalf("unexpected keys: got %v, exp %v", keys, exp)
+	} else if exp := []float64{1.3, 1.2, 1.1}; !reflect.DeepEqual(values, exp) {
+		t.Fatalf("unexpected values: got %v, exp %v", values, exp)
+	}
+
+	if keys, _ := cur.Next(); len(keys) != 0 {
+		t.Fatalf("expected eof, got keys: %v", keys)
+	}
+}
+
+// Ensure a descending batch cursor stops at the end of the cache when it
+// seeks between cached values.
+func TestIntegerDescendingBatchCursor_Cache(t *testing.T) {
+	cur := newIntegerDescendingBatchCursor("cpu", 25, Values{
+		NewIntegerValue(10, 1),
+		NewIntegerValue(20, 2),
+		NewIntegerValue(30, 3),
+	}, &KeyCursor{})
+	defer cur.Close()
+
+	keys, values := cur.Next()
+	if exp := []int64{20, 10}; !reflect.DeepEqual(keys, exp) {
+		t.Fatalf("unexpected keys: got %v, exp %v", keys, exp)
+	} else if exp := []int64{2, 1}; !reflect.DeepEqual(values, exp) {
+		t.Fatalf("unexpected values: got %v, exp %v", values, exp)
+	}
+
+	if keys, _ := cur.Next(); len(keys) != 0 {
+		t.Fatalf("expected eof, got keys: %v", keys)
+	}
+}
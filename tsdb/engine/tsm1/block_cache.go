@@ -0,0 +1,198 @@
+package tsm1
+
+import (
+	"container/list"
+	"sync"
+	"sync/atomic"
+
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/tsdb"
+)
+
+func init() {
+	tsdb.NewBlockCache = func(maxSize int64) interface{} { return NewBlockCache(maxSize) }
+}
+
+// readAheadThreshold is the number of blocks a cursor must read in sequence
+// before the blocks that follow are read ahead into the block cache.
+const readAheadThreshold = 2
+
+// Statistics gathered by the BlockCache.
+const (
+	statBlockCacheHits       = "hits"
+	statBlockCacheMisses     = "misses"
+	statBlockCacheHitRate    = "hitRate"
+	statBlockCacheEvictions  = "evictions"
+	statBlockCacheReadAheads = "readAheads"
+	statBlockCacheSize       = "memBytes"
+	statBlockCacheBlocks     = "blocks"
+)
+
+// BlockCache is an LRU cache of decoded TSM blocks shared by the engines of a
+// store. Queries that repeatedly read the same recent blocks, such as
+// dashboards, get them without decoding them again. Blocks are only cached
+// for queries; compactions read around the cache.
+type BlockCache struct {
+	mu      sync.Mutex
+	maxSize int64
+	size    int64
+	lru     *list.List // front is most recently used
+	blocks  map[blockCacheKey]*list.Element
+
+	stats BlockCacheStatistics
+}
+
+// blockCacheKey identifies a block by its file and offset.
+type blockCacheKey struct {
+	r      *TSMReader
+	offset int64
+}
+
+type blockCacheEntry struct {
+	key    blockCacheKey
+	values interface{} // []FloatValue, []IntegerValue, ...
+	size   int64
+}
+
+// BlockCacheStatistics keeps statistics related to the BlockCache.
+type BlockCacheStatistics struct {
+	Hits       int64
+	Misses     int64
+	Evictions  int64
+	ReadAheads int64
+}
+
+// NewBlockCache returns a block cache that holds up to maxSize bytes of
+// decoded values.
+func NewBlockCache(maxSize int64) *BlockCache {
+	return &BlockCache{
+		maxSize: maxSize,
+		lru:     list.New(),
+		blocks:  make(map[blockCacheKey]*list.Element),
+	}
+}
+
+// get returns the decoded values of the block of r at offset.
+func (c *BlockCache) get(r *TSMReader, offset int64) interface{} {
+	if c == nil {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.blocks[blockCacheKey{r: r, offset: offset}]
+	if !ok {
+		atomic.AddInt64(&c.stats.Misses, 1)
+		return nil
+	}
+	atomic.AddInt64(&c.stats.Hits, 1)
+	c.lru.MoveToFront(e)
+	return e.Value.(*blockCacheEntry).values
+}
+
+// contains returns true if the block of r at offset is cached. It does not
+// change the statistics or the recency of the block.
+func (c *BlockCache) contains(r *TSMReader, offset int64) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, ok := c.blocks[blockCacheKey{r: r, offset: offset}]
+	return ok
+}
+
+// add caches the decoded values of the block of r at offset, evicting the
+// least recently used blocks to stay within the size of the cache. values
+// must not be modified after it is added.
+func (c *BlockCache) add(r *TSMReader, offset int64, values interface{}) {
+	size := blockValuesSize(values)
+	if c == nil || size > c.maxSize {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := blockCacheKey{r: r, offset: offset}
+	if _, ok := c.blocks[key]; ok {
+		return
+	}
+	c.blocks[key] = c.lru.PushFront(&blockCacheEntry{key: key, values: values, size: size})
+	c.size += size
+
+	for c.size > c.maxSize {
+		c.remove(c.lru.Back())
+		atomic.AddInt64(&c.stats.Evictions, 1)
+	}
+}
+
+// removeFile removes the blocks of r from the cache.
+func (c *BlockCache) removeFile(r *TSMReader) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for e := c.lru.Front(); e != nil; {
+		next := e.Next()
+		if e.Value.(*blockCacheEntry).key.r == r {
+			c.remove(e)
+		}
+		e = next
+	}
+}
+
+func (c *BlockCache) remove(e *list.Element) {
+	entry := c.lru.Remove(e).(*blockCacheEntry)
+	delete(c.blocks, entry.key)
+	c.size -= entry.size
+}
+
+// Statistics returns statistics for periodic monitoring.
+func (c *BlockCache) Statistics(tags map[string]string) []models.Statistic {
+	c.mu.Lock()
+	size, blocks := c.size, len(c.blocks)
+	c.mu.Unlock()
+
+	hits, misses := atomic.LoadInt64(&c.stats.Hits), atomic.LoadInt64(&c.stats.Misses)
+	var hitRate float64
+	if hits+misses > 0 {
+		hitRate = float64(hits) / float64(hits+misses)
+	}
+
+	return []models.Statistic{{
+		Name: "tsm1_block_cache",
+		Tags: tags,
+		Values: map[string]interface{}{
+			statBlockCacheHits:       hits,
+			statBlockCacheMisses:     misses,
+			statBlockCacheHitRate:    hitRate,
+			statBlockCacheEvictions:  atomic.LoadInt64(&c.stats.Evictions),
+			statBlockCacheReadAheads: atomic.LoadInt64(&c.stats.ReadAheads),
+			statBlockCacheSize:       size,
+			statBlockCacheBlocks:     int64(blocks),
+		},
+	}}
+}
+
+// blockValuesSize returns the estimated memory used by decoded values.
+func blockValuesSize(values interface{}) int64 {
+	switch a := values.(type) {
+	case []FloatValue:
+		return int64(len(a)) * 16
+	case []IntegerValue:
+		return int64(len(a)) * 16
+	case []UnsignedValue:
+		return int64(len(a)) * 16
+	case []BooleanValue:
+		return int64(len(a)) * 16
+	case []StringValue:
+		n := int64(len(a)) * 24
+		for _, v := range a {
+			n += int64(len(v.value))
+		}
+		return n
+	}
+	return 0
+}
@@ -0,0 +1,125 @@
+package tsm1
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+// mustOpenBlockCacheReader returns a reader of a TSM file with two float
+// blocks of two values for the key "cpu", using the block cache c.
+func mustOpenBlockCacheReader(t *testing.T, dir string, c *BlockCache) *TSMReader {
+	f := mustTempFile(dir)
+	w, err := NewTSMWriter(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := w.Write([]byte("cpu"), []Value{NewValue(0, 1.0), NewValue(1, 2.0)}); err != nil {
+		t.Fatal(err)
+	} else if err := w.Write([]byte("cpu"), []Value{NewValue(2, 3.0), NewValue(3, 4.0)}); err != nil {
+		t.Fatal(err)
+	} else if err := w.WriteIndex(); err != nil {
+		t.Fatal(err)
+	} else if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if f, err = os.Open(f.Name()); err != nil {
+		t.Fatal(err)
+	}
+	r, err := NewTSMReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.blockCache = c
+	return r
+}
+
+func TestBlockCache_ReadFloatBlockAt(t *testing.T) {
+	dir := mustTempDir()
+	defer os.RemoveAll(dir)
+
+	c := NewBlockCache(1 << 20)
+	r := mustOpenBlockCacheReader(t, dir, c)
+
+	entries := r.Entries([]byte("cpu"))
+	if len(entries) != 2 {
+		t.Fatalf("unexpected entries: %d", len(entries))
+	}
+
+	var buf []FloatValue
+	for i := 0; i < 2; i++ {
+		values, err := r.ReadFloatBlockAt(&entries[0], &buf)
+		if err != nil {
+			t.Fatal(err)
+		} else if len(values) != 2 || values[0].value != 1.0 || values[1].value != 2.0 {
+			t.Fatalf("unexpected values: %v", values)
+		}
+
+		// Changing the returned values does not change the cached block.
+		values[0].value = 100
+	}
+
+	if c.stats.Hits != 1 || c.stats.Misses != 1 {
+		t.Fatalf("unexpected stats: %+v", c.stats)
+	}
+
+	// Closing the file removes its blocks.
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	} else if len(c.blocks) != 0 || c.size != 0 {
+		t.Fatalf("unexpected cached blocks: %d (%d bytes)", len(c.blocks), c.size)
+	}
+}
+
+func TestBlockCache_Evict(t *testing.T) {
+	dir := mustTempDir()
+	defer os.RemoveAll(dir)
+
+	// The cache holds a single block of two float values.
+	c := NewBlockCache(32)
+	r := mustOpenBlockCacheReader(t, dir, c)
+	defer r.Close()
+
+	entries := r.Entries([]byte("cpu"))
+	var buf []FloatValue
+	for i := range entries {
+		if _, err := r.ReadFloatBlockAt(&entries[i], &buf); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if c.stats.Evictions != 1 || len(c.blocks) != 1 || c.size != 32 {
+		t.Fatalf("unexpected cache: %+v, %d blocks, %d bytes", c.stats, len(c.blocks), c.size)
+	} else if !c.contains(r, entries[1].Offset) {
+		t.Fatal("expected most recent block to be cached")
+	}
+}
+
+func TestBlockCache_ReadAhead(t *testing.T) {
+	dir := mustTempDir()
+	defer os.RemoveAll(dir)
+
+	c := NewBlockCache(1 << 20)
+	r := mustOpenBlockCacheReader(t, dir, c)
+	defer r.Close()
+
+	entries := r.Entries([]byte("cpu"))
+	r.readAhead([]byte("cpu"), entries[1])
+	for i := 0; !c.contains(r, entries[1].Offset); i++ {
+		if i == 100 {
+			t.Fatal("block was not read ahead")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	var buf []FloatValue
+	if values, err := r.ReadFloatBlockAt(&entries[1], &buf); err != nil {
+		t.Fatal(err)
+	} else if len(values) != 2 || values[0].value != 3.0 {
+		t.Fatalf("unexpected values: %v", values)
+	} else if c.stats.Hits != 1 || c.stats.Misses != 0 || c.stats.ReadAheads != 1 {
+		t.Fatalf("unexpected stats: %+v", c.stats)
+	}
+}
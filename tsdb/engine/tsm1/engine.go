@@ -5,6 +5,7 @@ import (
 	"archive/tar"
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -24,6 +25,7 @@ import (
 	"github.com/influxdata/influxdb/pkg/estimator"
 	"github.com/influxdata/influxdb/pkg/limiter"
 	"github.com/influxdata/influxdb/pkg/metrics"
+	"github.com/influxdata/influxdb/pkg/profiling"
 	"github.com/influxdata/influxdb/pkg/tracing"
 	"github.com/influxdata/influxdb/query"
 	"github.com/influxdata/influxdb/tsdb"
@@ -42,8 +44,13 @@ import (
 
 func init() {
 	tsdb.RegisterEngine("tsm1", NewEngine)
+	tsdb.RegisterEngine(tsdb.InmemEngine, NewEngine)
 }
 
+// ErrInmemEngine is returned by the operations that need the files of an
+// engine when the engine keeps its data only in memory.
+var ErrInmemEngine = errors.New("engine data is only kept in memory")
+
 var (
 	// Ensure Engine implements the interface.
 	_ tsdb.Engine = &Engine{}
@@ -108,6 +115,10 @@ const (
 type Engine struct {
 	mu sync.RWMutex
 
+	// readSnapshotMu is held for writing while a query takes a read
+	// snapshot and for reading while points are written to the cache.
+	readSnapshotMu sync.RWMutex
+
 	// The following group of fields is used to track the state of level compactions within the
 	// Engine. The WaitGroup is used to monitor the compaction goroutines, the 'done' channel is
 	// used to signal those goroutines to shutdown. Every request to disable level compactions will
@@ -133,6 +144,11 @@ type Engine struct {
 	index    tsdb.Index
 	fieldset *tsdb.MeasurementFieldSet
 
+	// inmem is set when the engine was created as the inmem engine. Its data
+	// is kept in the cache only: nothing is written to the WAL and the cache
+	// is never snapshotted to TSM files.
+	inmem bool
+
 	WAL            *WAL
 	Cache          *Cache
 	Compactor      *Compactor
@@ -167,11 +183,16 @@ func NewEngine(id uint64, idx tsdb.Index, database, path string, walPath string,
 	w.syncDelay = time.Duration(opt.Config.WALFsyncDelay)
 
 	fs := NewFileStore(path)
+	fs.objectStore = opt.ObjectStore
+	if c, ok := opt.BlockCache.(*BlockCache); ok {
+		fs.blockCache = c
+	}
 	cache := NewCache(uint64(opt.Config.CacheMaxMemorySize), path)
 
 	c := &Compactor{
 		Dir:       path,
 		FileStore: fs,
+		RateLimit: opt.BackgroundRate,
 	}
 
 	logger := zap.New(zap.NullEncoder())
@@ -186,6 +207,7 @@ func NewEngine(id uint64, idx tsdb.Index, database, path string, walPath string,
 		traceLogging: opt.Config.TraceLoggingEnabled,
 
 		fieldset: tsdb.NewMeasurementFieldSet(),
+		inmem:    opt.EngineVersion == tsdb.InmemEngine,
 
 		WAL:   w,
 		Cache: cache,
@@ -254,6 +276,10 @@ func (e *Engine) enableLevelCompactions(wait bool) {
 		// still waiting on more workers or already enabled
 		e.mu.Unlock()
 		return
+	} else if e.inmem {
+		// There are no files to compact.
+		e.mu.Unlock()
+		return
 	}
 
 	// last one to enable, start things back up
@@ -295,7 +321,7 @@ func (e *Engine) disableLevelCompactions(wait bool) {
 func (e *Engine) enableSnapshotCompactions() {
 	// Check if already enabled under read lock
 	e.mu.RLock()
-	if e.snapDone != nil {
+	if e.snapDone != nil || e.inmem {
 		e.mu.RUnlock()
 		return
 	}
@@ -388,6 +414,10 @@ func (e *Engine) TagKeyCardinality(name, key []byte) int {
 	return e.index.TagKeyCardinality(name, key)
 }
 
+func (e *Engine) MeasurementSeriesN(name []byte) int {
+	return e.index.MeasurementSeriesN(name)
+}
+
 // SeriesN returns the unique number of series in the index.
 func (e *Engine) SeriesN() int64 {
 	return e.index.SeriesN()
@@ -496,6 +526,10 @@ func (e *Engine) DiskSize() int64 {
 
 // Open opens and initializes the engine.
 func (e *Engine) Open() error {
+	if e.inmem {
+		return nil
+	}
+
 	if err := os.MkdirAll(e.path, 0777); err != nil {
 		return err
 	}
@@ -534,6 +568,10 @@ func (e *Engine) Close() error {
 	defer e.mu.Unlock()
 	e.done = nil // Ensures that the channel will not be closed again.
 
+	if e.inmem {
+		return nil
+	}
+
 	if err := e.FileStore.Close(); err != nil {
 		return err
 	}
@@ -613,6 +651,32 @@ func (e *Engine) Free() error {
 	return e.FileStore.Free()
 }
 
+// Tier moves the blocks of the TSM files of the engine to the object store of
+// the engine options, under prefix. Only the index of each file is kept on
+// disk; blocks are fetched from the object store when they are read.
+func (e *Engine) Tier(prefix string) (int, error) {
+	return e.FileStore.Tier(prefix)
+}
+
+// Clone creates a copy of the engine's TSM and index files at path, which
+// must not exist yet. Files are hard-linked from a snapshot, so the copy takes
+// no additional space until either engine compacts. The objects of tiered
+// files are copied to keys under prefix.
+func (e *Engine) Clone(path, prefix string) error {
+	dir, err := e.CreateSnapshot()
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	if err := e.index.SnapshotTo(dir); err != nil {
+		return err
+	} else if err := e.FileStore.cloneStubs(dir, prefix); err != nil {
+		return err
+	}
+	return os.Rename(dir, path)
+}
+
 // Backup writes a tar archive of any TSM files modified since the passed
 // in time to the passed in writer. The basePath will be prepended to the names
 // of the files in the archive. It will force a snapshot of the WAL first
@@ -714,6 +778,10 @@ func (e *Engine) Import(r io.Reader, basePath string) error {
 // If asNew is true, each file will be installed as a new TSM file even if an
 // existing file with the same name in the backup exists.
 func (e *Engine) overlay(r io.Reader, basePath string, asNew bool) error {
+	if e.inmem {
+		return ErrInmemEngine
+	}
+
 	// Copy files from archive while under lock to prevent reopening.
 	newFiles, err := func() ([]string, error) {
 		e.mu.Lock()
@@ -920,13 +988,18 @@ func (e *Engine) WritePoints(points []models.Point) error {
 	e.mu.RLock()
 	defer e.mu.RUnlock()
 
+	// Wait for the read snapshot of a query being taken.
+	e.readSnapshotMu.RLock()
+	defer e.readSnapshotMu.RUnlock()
+
 	// first try to write to the cache
-	err := e.Cache.WriteMulti(values)
-	if err != nil {
+	var err error
+	profiling.Do(profiling.StageCache, func() { err = e.Cache.WriteMulti(values) })
+	if err != nil || e.inmem {
 		return err
 	}
 
-	_, err = e.WAL.WriteMulti(values)
+	profiling.Do(profiling.StageWAL, func() { _, err = e.WAL.WriteMulti(values) })
 	return err
 }
 
@@ -1029,8 +1102,10 @@ func (e *Engine) DeleteSeriesRange(seriesKeys [][]byte, min, max int64) error {
 	e.Cache.DeleteRange(walKeys, min, max)
 
 	// delete from the WAL
-	if _, err := e.WAL.DeleteRange(walKeys, min, max); err != nil {
-		return err
+	if !e.inmem {
+		if _, err := e.WAL.DeleteRange(walKeys, min, max); err != nil {
+			return err
+		}
 	}
 
 	// Have we deleted all points for the series? If so, we need to remove
@@ -1131,6 +1206,10 @@ func (e *Engine) WriteTo(w io.Writer) (n int64, err error) { panic("not implemen
 
 // WriteSnapshot will snapshot the cache and write a new TSM file with its contents, releasing the snapshot when done.
 func (e *Engine) WriteSnapshot() error {
+	if e.inmem {
+		return ErrInmemEngine
+	}
+
 	// Lock and grab the cache snapshot along with all the closed WAL
 	// filenames associated with the snapshot
 
@@ -1247,7 +1326,8 @@ func (e *Engine) compactCache(quit <-chan struct{}) {
 			if e.ShouldCompactCache(e.WAL.LastWriteTime()) {
 				start := time.Now()
 				e.traceLogger.Info(fmt.Sprintf("Compacting cache for %s", e.path))
-				err := e.WriteSnapshot()
+				var err error
+				profiling.Do(profiling.StageSnapshot, func() { err = e.WriteSnapshot() })
 				if err != nil && err != errCompactionsDisabled {
 					e.logger.Info(fmt.Sprintf("error writing snapshot: %v", err))
 					atomic.AddInt64(&e.stats.CacheCompactionErrors, 1)
@@ -1381,7 +1461,7 @@ func (e *Engine) compactHiPriorityLevel(grp CompactionGroup, level int) bool {
 			defer atomic.AddInt64(&e.stats.TSMCompactionsActive[level-1], -1)
 
 			defer e.compactionLimiter.Release()
-			s.Apply()
+			profiling.Do(profiling.StageCompaction, s.Apply)
 			// Release the files in the compaction plan
 			e.CompactionPlan.Release([]CompactionGroup{s.group})
 		}()
@@ -1408,7 +1488,7 @@ func (e *Engine) compactLoPriorityLevel(grp CompactionGroup, level int) bool {
 			defer e.wg.Done()
 			defer atomic.AddInt64(&e.stats.TSMCompactionsActive[level-1], -1)
 			defer e.compactionLimiter.Release()
-			s.Apply()
+			profiling.Do(profiling.StageCompaction, s.Apply)
 			// Release the files in the compaction plan
 			e.CompactionPlan.Release([]CompactionGroup{s.group})
 		}()
@@ -1433,7 +1513,7 @@ func (e *Engine) compactFull(grp CompactionGroup) bool {
 			defer e.wg.Done()
 			defer atomic.AddInt64(&e.stats.TSMFullCompactionsActive, -1)
 			defer e.compactionLimiter.Release()
-			s.Apply()
+			profiling.Do(profiling.StageCompaction, s.Apply)
 			// Release the files in the compaction plan
 			e.CompactionPlan.Release([]CompactionGroup{s.group})
 		}()
@@ -1700,6 +1780,15 @@ func (e *Engine) KeyCursor(ctx context.Context, key []byte, t int64, ascending b
 	return e.FileStore.KeyCursor(ctx, key, t, ascending)
 }
 
+// KeyCursorRange returns a KeyCursor for the given key starting at time t
+// that skips the blocks past stop.
+func (e *Engine) KeyCursorRange(ctx context.Context, key []byte, t, stop int64, ascending bool) *KeyCursor {
+	if snap := e.readSnapshotFromContext(ctx); snap != nil {
+		return snap.files.KeyCursorRange(ctx, key, t, stop, ascending)
+	}
+	return e.FileStore.KeyCursorRange(ctx, key, t, stop, ascending)
+}
+
 // CreateIterator returns an iterator for the measurement based on opt.
 func (e *Engine) CreateIterator(ctx context.Context, measurement string, opt query.IteratorOptions) (query.Iterator, error) {
 	if span := tracing.SpanFromContext(ctx); span != nil {
@@ -1777,6 +1866,10 @@ func (e *Engine) createCallIterator(ctx context.Context, measurement string, cal
 	// Calculate tag sets and apply SLIMIT/SOFFSET.
 	tagSets = query.LimitTagSets(tagSets, opt.SLimit, opt.SOffset)
 
+	// Create the cursors of every series from the same view of the shard.
+	ctx, release := e.readSnapshot(ctx, measurement, tagSets, opt)
+	defer release()
+
 	itrs := make([]query.Iterator, 0, len(tagSets))
 	if err := func() error {
 		for _, t := range tagSets {
@@ -1788,7 +1881,18 @@ func (e *Engine) createCallIterator(ctx context.Context, measurement string, cal
 			default:
 			}
 
-			inputs, err := e.createTagSetIterators(ctx, ref, measurement, t, opt)
+			// Aggregate the batches of each series directly if possible.
+			inputs, ok, err := e.createBatchCallIterators(ctx, measurement, call, ref, t, opt)
+			if err != nil {
+				return err
+			} else if ok {
+				if len(inputs) > 0 {
+					itrs = append(itrs, query.NewParallelMergeIterator(inputs, opt, runtime.GOMAXPROCS(0)))
+				}
+				continue
+			}
+
+			inputs, err = e.createTagSetIterators(ctx, ref, measurement, t, opt)
 			if err != nil {
 				return err
 			} else if len(inputs) == 0 {
@@ -1847,6 +1951,10 @@ func (e *Engine) createVarRefIterator(ctx context.Context, measurement string, o
 	// Calculate tag sets and apply SLIMIT/SOFFSET.
 	tagSets = query.LimitTagSets(tagSets, opt.SLimit, opt.SOffset)
 
+	// Create the cursors of every series from the same view of the shard.
+	ctx, release := e.readSnapshot(ctx, measurement, tagSets, opt)
+	defer release()
+
 	itrs := make([]query.Iterator, 0, len(tagSets))
 	if err := func() error {
 		for _, t := range tagSets {
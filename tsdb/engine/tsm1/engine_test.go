@@ -21,6 +21,7 @@ import (
 
 	"github.com/influxdata/influxdb/models"
 	"github.com/influxdata/influxdb/pkg/deep"
+	"github.com/influxdata/influxdb/pkg/profiling"
 	"github.com/influxdata/influxdb/query"
 	"github.com/influxdata/influxdb/tsdb"
 	"github.com/influxdata/influxdb/tsdb/engine/tsm1"
@@ -576,6 +577,127 @@ func TestEngine_CreateIterator_Condition(t *testing.T) {
 	}
 }
 
+// Ensure engine can aggregate windows of values from both TSM files and the cache.
+func TestEngine_CreateIterator_BatchCall(t *testing.T) {
+	t.Parallel()
+
+	e := MustOpenDefaultEngine()
+	defer e.Close()
+
+	e.MeasurementFields([]byte("cpu")).CreateFieldIfNotExists([]byte("value"), influxql.Float, false)
+	e.MeasurementFields([]byte("cpu")).CreateFieldIfNotExists([]byte("n"), influxql.Integer, false)
+	e.CreateSeriesIfNotExists([]byte("cpu,host=A"), []byte("cpu"), models.NewTags(map[string]string{"host": "A"}))
+	e.SetFieldName([]byte("cpu"), "n")
+
+	if err := e.WritePointsString(
+		`cpu,host=A value=1,n=10i 0`,
+		`cpu,host=A value=2,n=20i 1000000000`,
+		`cpu,host=A value=3,n=30i 2000000000`,
+	); err != nil {
+		t.Fatalf("failed to write points: %s", err.Error())
+	}
+	e.MustWriteSnapshot()
+	if err := e.WritePointsString(
+		`cpu,host=A value=4,n=40i 3000000000`,
+		`cpu,host=A value=5,n=50i 4000000000`,
+		`cpu,host=A value=6,n=60i 5000000000`,
+	); err != nil {
+		t.Fatalf("failed to write points: %s", err.Error())
+	}
+
+	for _, tt := range []struct {
+		expr      string
+		ascending bool
+		points    []query.Point
+	}{
+		{
+			expr:      `mean(value)`,
+			ascending: true,
+			points: []query.Point{
+				&query.FloatPoint{Name: "cpu", Tags: ParseTags("host=A"), Time: 0, Value: 2, Aggregated: 1},
+				&query.FloatPoint{Name: "cpu", Tags: ParseTags("host=A"), Time: 2000000000, Value: 3.5, Aggregated: 2},
+				&query.FloatPoint{Name: "cpu", Tags: ParseTags("host=A"), Time: 4000000000, Value: 5, Aggregated: 1},
+			},
+		},
+		{
+			expr:      `sum(value)`,
+			ascending: false,
+			points: []query.Point{
+				&query.FloatPoint{Name: "cpu", Tags: ParseTags("host=A"), Time: 4000000000, Value: 5, Aggregated: 1},
+				&query.FloatPoint{Name: "cpu", Tags: ParseTags("host=A"), Time: 2000000000, Value: 7, Aggregated: 2},
+				&query.FloatPoint{Name: "cpu", Tags: ParseTags("host=A"), Time: 0, Value: 2, Aggregated: 1},
+			},
+		},
+		{
+			expr:      `sum(n)`,
+			ascending: true,
+			points: []query.Point{
+				&query.IntegerPoint{Name: "cpu", Tags: ParseTags("host=A"), Time: 0, Value: 20, Aggregated: 1},
+				&query.IntegerPoint{Name: "cpu", Tags: ParseTags("host=A"), Time: 2000000000, Value: 70, Aggregated: 2},
+				&query.IntegerPoint{Name: "cpu", Tags: ParseTags("host=A"), Time: 4000000000, Value: 50, Aggregated: 1},
+			},
+		},
+		{
+			expr:      `mean(n)`,
+			ascending: false,
+			points: []query.Point{
+				&query.FloatPoint{Name: "cpu", Tags: ParseTags("host=A"), Time: 4000000000, Value: 50, Aggregated: 1},
+				&query.FloatPoint{Name: "cpu", Tags: ParseTags("host=A"), Time: 2000000000, Value: 35, Aggregated: 2},
+				&query.FloatPoint{Name: "cpu", Tags: ParseTags("host=A"), Time: 0, Value: 20, Aggregated: 1},
+			},
+		},
+		{
+			expr:      `count(value)`,
+			ascending: true,
+			points: []query.Point{
+				&query.IntegerPoint{Name: "cpu", Tags: ParseTags("host=A"), Time: 0, Value: 1, Aggregated: 1},
+				&query.IntegerPoint{Name: "cpu", Tags: ParseTags("host=A"), Time: 2000000000, Value: 2, Aggregated: 2},
+				&query.IntegerPoint{Name: "cpu", Tags: ParseTags("host=A"), Time: 4000000000, Value: 1, Aggregated: 1},
+			},
+		},
+	} {
+		itr, err := e.CreateIterator(context.Background(), "cpu", query.IteratorOptions{
+			Expr:       influxql.MustParseExpr(tt.expr),
+			Dimensions: []string{"host"},
+			Interval:   query.Interval{Duration: 2 * time.Second},
+			StartTime:  1000000000,
+			EndTime:    4000000000,
+			Ascending:  tt.ascending,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var got []query.Point
+		for {
+			var p query.Point
+			switch itr := itr.(type) {
+			case query.FloatIterator:
+				if fp, err := itr.Next(); err != nil {
+					t.Fatalf("%s: unexpected error: %v", tt.expr, err)
+				} else if fp != nil {
+					p = fp.Clone()
+				}
+			case query.IntegerIterator:
+				if ip, err := itr.Next(); err != nil {
+					t.Fatalf("%s: unexpected error: %v", tt.expr, err)
+				} else if ip != nil {
+					p = ip.Clone()
+				}
+			}
+			if p == nil {
+				break
+			}
+			got = append(got, p)
+		}
+		itr.Close()
+
+		if !reflect.DeepEqual(got, tt.points) {
+			t.Fatalf("%s: unexpected points: %v", tt.expr, got)
+		}
+	}
+}
+
 // Ensures that deleting series from TSM files with multiple fields removes all the
 /// series
 func TestEngine_DeleteSeries(t *testing.T) {
@@ -733,6 +855,46 @@ func benchmarkEngineCreateIteratorCount(b *testing.B, pointN int) {
 	}, pointN)
 }
 
+func BenchmarkEngine_CreateIterator_Sum_1K(b *testing.B) {
+	benchmarkEngineCreateIteratorSum(b, 1000)
+}
+func BenchmarkEngine_CreateIterator_Sum_100K(b *testing.B) {
+	benchmarkEngineCreateIteratorSum(b, 100000)
+}
+func BenchmarkEngine_CreateIterator_Sum_1M(b *testing.B) {
+	benchmarkEngineCreateIteratorSum(b, 1000000)
+}
+
+func benchmarkEngineCreateIteratorSum(b *testing.B, pointN int) {
+	benchmarkIterator(b, query.IteratorOptions{
+		Expr:      influxql.MustParseExpr("sum(value)"),
+		Ascending: true,
+		StartTime: influxql.MinTime,
+		EndTime:   influxql.MaxTime,
+	}, pointN)
+}
+
+func BenchmarkEngine_CreateIterator_Mean_1K(b *testing.B) {
+	benchmarkEngineCreateIteratorMean(b, 1000)
+}
+func BenchmarkEngine_CreateIterator_Mean_100K(b *testing.B) {
+	benchmarkEngineCreateIteratorMean(b, 100000)
+}
+func BenchmarkEngine_CreateIterator_Mean_1M(b *testing.B) {
+	benchmarkEngineCreateIteratorMean(b, 1000000)
+}
+
+func benchmarkEngineCreateIteratorMean(b *testing.B, pointN int) {
+	benchmarkIterator(b, query.IteratorOptions{
+		Expr:       influxql.MustParseExpr("mean(value)"),
+		Dimensions: []string{"host"},
+		Interval:   query.Interval{Duration: time.Minute},
+		Ascending:  true,
+		StartTime:  0,
+		EndTime:    influxql.MaxTime,
+	}, pointN)
+}
+
 func BenchmarkEngine_CreateIterator_First_1K(b *testing.B) {
 	benchmarkEngineCreateIteratorFirst(b, 1000)
 }
@@ -858,6 +1020,100 @@ func BenchmarkEngine_WritePoints_Parallel(b *testing.B) {
 	}
 }
 
+// BenchmarkEngine_WritePath measures the whole write path of the engine: parsing
+// line protocol, creating series in the index, writing to the cache and WAL,
+// snapshotting the cache to TSM files and fully compacting those files.
+//
+// Profile labels are enabled so a CPU profile can be broken down by stage:
+//
+//	go test -run=XXX -bench=WritePath -cpuprofile=cpu.out
+//	go tool pprof -tagfocus=stage=compaction cpu.out
+func BenchmarkEngine_WritePath(b *testing.B) {
+	profiling.SetEnabled(true)
+	defer profiling.SetEnabled(false)
+
+	for _, seriesN := range []int{1000, 10000, 100000} {
+		for _, index := range tsdb.RegisteredIndexes() {
+			b.Run(fmt.Sprintf("%s_%d", index, seriesN), func(b *testing.B) {
+				benchmarkEngineWritePath(b, index, seriesN, 4)
+			})
+		}
+	}
+}
+
+// benchmarkEngineWritePath writes batchN batches of one point for each of
+// seriesN series. The series are spread over 10 measurements with host, region
+// and dc tags so the index holds a realistic mix of tag cardinalities.
+func benchmarkEngineWritePath(b *testing.B, index string, seriesN, batchN int) {
+	batches := make([][]byte, batchN)
+	for i := range batches {
+		var buf bytes.Buffer
+		for j := 0; j < seriesN; j++ {
+			fmt.Fprintf(&buf, "m%d,host=host-%d,region=region-%d,dc=dc-%d usage_user=%d,usage_system=%di,idle=true %d\n",
+				j%10, j/10, j%8, j%3, j, j, int64(i)*int64(time.Minute))
+		}
+		batches[i] = buf.Bytes()
+	}
+
+	b.ReportAllocs()
+	b.SetBytes(int64(len(batches[0]) * batchN))
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		// Snapshots and compactions are run by the benchmark rather than in
+		// the background.
+		e := NewEngine(index)
+		e.CompactionPlan = &mockPlanner{}
+		e.CacheFlushMemorySizeThreshold = math.MaxUint64
+		e.CacheFlushWriteColdDuration = time.Duration(math.MaxInt64)
+		if err := e.Open(); err != nil {
+			b.Fatal(err)
+		}
+		b.StartTimer()
+
+		for _, batch := range batches {
+			var points []models.Point
+			var err error
+			profiling.Do(profiling.StageParse, func() { points, err = models.ParsePoints(batch) })
+			if err != nil {
+				b.Fatal(err)
+			}
+
+			keys := make([][]byte, len(points))
+			names := make([][]byte, len(points))
+			tags := make([]models.Tags, len(points))
+			for j, p := range points {
+				keys[j], names[j], tags[j] = p.Key(), p.Name(), p.Tags()
+			}
+			if err := e.CreateSeriesListIfNotExists(keys, names, tags); err != nil {
+				b.Fatal(err)
+			}
+
+			if err := e.WritePoints(points); err != nil {
+				b.Fatal(err)
+			} else if err := e.WriteSnapshot(); err != nil {
+				b.Fatal(err)
+			}
+		}
+
+		var paths []string
+		for _, f := range e.FileStore.Files() {
+			paths = append(paths, f.Path())
+		}
+		profiling.Do(profiling.StageCompaction, func() {
+			files, err := e.Compactor.CompactFull(paths)
+			if err != nil {
+				b.Fatal(err)
+			} else if err := e.FileStore.Replace(paths, files); err != nil {
+				b.Fatal(err)
+			}
+		})
+
+		b.StopTimer()
+		e.Close()
+		b.StartTimer()
+	}
+}
+
 func benchmarkEngineCreateIteratorLimit(b *testing.B, pointN int) {
 	benchmarkIterator(b, query.IteratorOptions{
 		Expr:       influxql.MustParseExpr("value"),
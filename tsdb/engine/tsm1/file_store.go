@@ -18,6 +18,7 @@ import (
 	"github.com/influxdata/influxdb/models"
 	"github.com/influxdata/influxdb/pkg/metrics"
 	"github.com/influxdata/influxdb/query"
+	"github.com/influxdata/influxdb/tsdb"
 	"github.com/uber-go/zap"
 )
 
@@ -155,6 +156,12 @@ type FileStore struct {
 	stats  *FileStoreStatistics
 	purger *purger
 
+	// objectStore holds the blocks of the files that have been tiered.
+	objectStore tsdb.ObjectStore
+
+	// blockCache holds the blocks decoded by queries. It may be nil.
+	blockCache *BlockCache
+
 	currentTempDirID int
 }
 
@@ -421,13 +428,14 @@ func (f *FileStore) Open() error {
 
 		go func(idx int, file *os.File) {
 			start := time.Now()
-			df, err := NewTSMReader(file)
+			df, err := newTSMReader(file, f.objectStore)
 			f.logger.Info(fmt.Sprintf("%s (#%d) opened in %v", file.Name(), idx, time.Since(start)))
 
 			if err != nil {
 				readerC <- &res{r: df, err: fmt.Errorf("error opening memory map for file %s: %v", file.Name(), err)}
 				return
 			}
+			df.blockCache = f.blockCache
 			readerC <- &res{r: df}
 		}(i, file)
 	}
@@ -525,9 +533,54 @@ func (f *FileStore) TSMReader(path string) *TSMReader {
 
 // KeyCursor returns a KeyCursor for key and t across the files in the FileStore.
 func (f *FileStore) KeyCursor(ctx context.Context, key []byte, t int64, ascending bool) *KeyCursor {
+	stop := int64(math.MaxInt64)
+	if !ascending {
+		stop = math.MinInt64
+	}
+	return f.KeyCursorRange(ctx, key, t, stop, ascending)
+}
+
+// KeyCursorRange returns a KeyCursor for key and t across the files in the
+// FileStore that skips the files and blocks that only contain values past
+// stop. stop is the last time the cursor reads: the max time of an ascending
+// cursor or the min time of a descending cursor.
+func (f *FileStore) KeyCursorRange(ctx context.Context, key []byte, t, stop int64, ascending bool) *KeyCursor {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return newKeyCursor(ctx, f.files, key, t, stop, ascending)
+}
+
+// fileSet is a set of TSM files that are referenced so that they are kept
+// until it is released, even if they are replaced by a compaction.
+type fileSet struct {
+	files []TSMFile
+}
+
+// pin returns the current files of the store as a fileSet.
+func (f *FileStore) pin() *fileSet {
 	f.mu.RLock()
 	defer f.mu.RUnlock()
-	return newKeyCursor(ctx, f, key, t, ascending)
+
+	files := make([]TSMFile, len(f.files))
+	copy(files, f.files)
+	for _, fd := range files {
+		fd.Ref()
+	}
+	return &fileSet{files: files}
+}
+
+// KeyCursorRange returns a KeyCursor for key and t across the files of the
+// set that skips the files and blocks that only contain values past stop.
+func (s *fileSet) KeyCursorRange(ctx context.Context, key []byte, t, stop int64, ascending bool) *KeyCursor {
+	return newKeyCursor(ctx, s.files, key, t, stop, ascending)
+}
+
+// release removes the references on the files of the set.
+func (s *fileSet) release() {
+	for _, fd := range s.files {
+		fd.Unref()
+	}
+	s.files = nil
 }
 
 // Stats returns the stats of the underlying files, preferring the cached version if it is still valid.
@@ -604,10 +657,11 @@ func (f *FileStore) replace(oldFiles, newFiles []string, updatedFn func(r []TSMF
 			}
 		}
 
-		tsm, err := NewTSMReader(fd)
+		tsm, err := newTSMReader(fd, f.objectStore)
 		if err != nil {
 			return err
 		}
+		tsm.blockCache = f.blockCache
 		updated = append(updated, tsm)
 	}
 
@@ -825,10 +879,10 @@ func (f *FileStore) cost(key []byte, min, max int64) query.IteratorCost {
 // locations returns the files and index blocks for a key and time.  ascending indicates
 // whether the key will be scan in ascending time order or descenging time order.
 // This function assumes the read-lock has been taken.
-func (f *FileStore) locations(key []byte, t int64, ascending bool) []*location {
+func locations(files []TSMFile, key []byte, t, stop int64, ascending bool) []*location {
 	var cache []IndexEntry
-	locations := make([]*location, 0, len(f.files))
-	for _, fd := range f.files {
+	locations := make([]*location, 0, len(files))
+	for _, fd := range files {
 		minTime, maxTime := fd.TimeRange()
 
 		// If we ascending and the max time of the file is before where we want to start
@@ -840,6 +894,13 @@ func (f *FileStore) locations(key []byte, t int64, ascending bool) []*location {
 		} else if !ascending && minTime > t {
 			continue
 		}
+
+		// Skip files that only have values past where the cursor stops.
+		if ascending && minTime > stop {
+			continue
+		} else if !ascending && maxTime < stop {
+			continue
+		}
 		tombstones := fd.TombstoneRange(key)
 
 		// This file could potential contain points we are looking for so find the blocks for
@@ -866,6 +927,13 @@ func (f *FileStore) locations(key []byte, t int64, ascending bool) []*location {
 				continue
 			}
 
+			// Skip blocks that only have values past where the cursor stops.
+			if ascending && ie.MinTime > stop {
+				continue
+			} else if !ascending && ie.MaxTime < stop {
+				continue
+			}
+
 			location := &location{
 				r:     fd,
 				entry: ie,
@@ -980,6 +1048,9 @@ type KeyCursor struct {
 	// If this is true, we need to scan the duplicate blocks and dedup the points
 	// as query time until they are compacted.
 	duplicates bool
+
+	// sequentialN is the number of blocks read in sequence by Next.
+	sequentialN int
 }
 
 type location struct {
@@ -1029,10 +1100,10 @@ func (a ascLocations) Less(i, j int) bool {
 
 // newKeyCursor returns a new instance of KeyCursor.
 // This function assumes the read-lock has been taken.
-func newKeyCursor(ctx context.Context, fs *FileStore, key []byte, t int64, ascending bool) *KeyCursor {
+func newKeyCursor(ctx context.Context, files []TSMFile, key []byte, t, stop int64, ascending bool) *KeyCursor {
 	c := &KeyCursor{
 		key:       key,
-		seeks:     fs.locations(key, t, ascending),
+		seeks:     locations(files, key, t, stop, ascending),
 		ctx:       ctx,
 		col:       metrics.GroupFromContext(ctx),
 		ascending: ascending,
@@ -1152,6 +1223,27 @@ func (c *KeyCursor) Next() {
 	} else {
 		c.nextDescending()
 	}
+
+	// Read the next block ahead once the cursor is reading blocks in
+	// sequence rather than seeking to a single block.
+	if c.sequentialN++; c.sequentialN >= readAheadThreshold {
+		c.readAhead()
+	}
+}
+
+// readAhead reads the block after the current block into the block cache.
+func (c *KeyCursor) readAhead() {
+	i := c.pos + 1
+	if !c.ascending {
+		i = c.pos - 1
+	}
+	if i < 0 || i >= len(c.seeks) || c.seeks[i].read() {
+		return
+	}
+
+	if r, ok := c.seeks[i].r.(*TSMReader); ok {
+		r.readAhead(c.key, c.seeks[i].entry)
+	}
 }
 
 func (c *KeyCursor) nextAscending() {
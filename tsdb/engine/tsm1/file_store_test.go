@@ -91,6 +91,56 @@ func TestFileStore_SeekToAsc_FromStart(t *testing.T) {
 	}
 }
 
+// Ensure a cursor with a time range skips the files and blocks outside of it.
+func TestFileStore_KeyCursorRange(t *testing.T) {
+	dir := MustTempDir()
+	defer os.RemoveAll(dir)
+	fs := tsm1.NewFileStore(dir)
+
+	// Setup 3 files
+	data := []keyValues{
+		keyValues{"cpu", []tsm1.Value{tsm1.NewValue(0, 1.0)}},
+		keyValues{"cpu", []tsm1.Value{tsm1.NewValue(1, 2.0)}},
+		keyValues{"cpu", []tsm1.Value{tsm1.NewValue(20, 3.0)}},
+	}
+
+	files, err := newFiles(dir, data...)
+	if err != nil {
+		t.Fatalf("unexpected error creating files: %v", err)
+	}
+
+	fs.Replace(nil, files)
+
+	readAll := func(c *tsm1.KeyCursor) []float64 {
+		var a []float64
+		buf := make([]tsm1.FloatValue, 1000)
+		for {
+			values, err := c.ReadFloatBlock(&buf)
+			if err != nil {
+				t.Fatalf("unexpected error reading values: %v", err)
+			} else if len(values) == 0 {
+				return a
+			}
+			for _, v := range values {
+				a = append(a, v.Value().(float64))
+			}
+			c.Next()
+		}
+	}
+
+	c := fs.KeyCursorRange(context.Background(), []byte("cpu"), 0, 5, true)
+	if got, exp := readAll(c), []float64{1, 2}; !reflect.DeepEqual(got, exp) {
+		t.Fatalf("unexpected ascending values: got %v, exp %v", got, exp)
+	}
+	c.Close()
+
+	c = fs.KeyCursorRange(context.Background(), []byte("cpu"), 20, 1, false)
+	if got, exp := readAll(c), []float64{3, 2}; !reflect.DeepEqual(got, exp) {
+		t.Fatalf("unexpected descending values: got %v, exp %v", got, exp)
+	}
+	c.Close()
+}
+
 func TestFileStore_SeekToAsc_Duplicate(t *testing.T) {
 	dir := MustTempDir()
 	defer os.RemoveAll(dir)
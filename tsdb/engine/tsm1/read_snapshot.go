@@ -0,0 +1,86 @@
+package tsm1
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/query"
+	"github.com/influxdata/influxql"
+)
+
+// readSnapshot is a view of the cache and files of an engine taken before
+// the cursors of a query are created. Cursors are created one series at a
+// time, so without it the series of a query could see points written while
+// the query was being planned, and others not.
+type readSnapshot struct {
+	engine *Engine
+	cache  map[string]Values
+	files  *fileSet
+}
+
+type readSnapshotKey struct{}
+
+// readSnapshot takes a read snapshot of the fields of the series of tagSets
+// that are read by opt. It returns a context that the cursors of the series
+// are created with and a function that releases the snapshot once they
+// have been created.
+func (e *Engine) readSnapshot(ctx context.Context, measurement string, tagSets []*query.TagSet, opt query.IteratorOptions) (context.Context, func()) {
+	mf := e.fieldset.Fields(measurement)
+	if mf == nil {
+		return ctx, func() {}
+	}
+
+	// Determine the fields read for every series. The filters of the series
+	// only refer to fields of the condition.
+	names := make(map[string]struct{})
+	addNames := func(refs []influxql.VarRef) {
+		for _, ref := range refs {
+			if mf.Field(ref.Val) != nil {
+				names[ref.Val] = struct{}{}
+			}
+		}
+	}
+	if opt.Expr != nil {
+		addNames(influxql.ExprNames(opt.Expr))
+	}
+	addNames(opt.Aux)
+	if opt.Condition != nil {
+		addNames(influxql.ExprNames(opt.Condition))
+	}
+
+	snap := &readSnapshot{engine: e, cache: make(map[string]Values)}
+
+	// Block writes to the cache until the cached values have been copied
+	// and the files pinned.
+	e.readSnapshotMu.Lock()
+	for _, t := range tagSets {
+		for _, seriesKey := range t.SeriesKeys {
+			for name := range names {
+				key := SeriesFieldKey(seriesKey, name)
+				snap.cache[key] = e.Cache.Values([]byte(key))
+			}
+		}
+	}
+	snap.files = e.FileStore.pin()
+	e.readSnapshotMu.Unlock()
+
+	return context.WithValue(ctx, readSnapshotKey{}, snap), snap.files.release
+}
+
+// readSnapshotFromContext returns the read snapshot of the engine in ctx.
+func (e *Engine) readSnapshotFromContext(ctx context.Context) *readSnapshot {
+	if snap, ok := ctx.Value(readSnapshotKey{}).(*readSnapshot); ok && snap.engine == e {
+		return snap
+	}
+	return nil
+}
+
+// cacheValues returns the cached values of key, as of the read snapshot in
+// ctx if there is one.
+func (e *Engine) cacheValues(ctx context.Context, key []byte) Values {
+	if snap := e.readSnapshotFromContext(ctx); snap != nil {
+		if values, ok := snap.cache[string(key)]; ok {
+			return values
+		}
+	}
+	return e.Cache.Values(key)
+}
@@ -0,0 +1,61 @@
+package tsm1
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/influxdata/influxdb/query"
+	"github.com/influxdata/influxdb/tsdb"
+	"github.com/influxdata/influxql"
+)
+
+// Ensure cursors created from a read snapshot do not see later writes.
+func TestEngine_ReadSnapshot(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tsm1-snapshot")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	e := &Engine{
+		Cache:     NewCache(0, ""),
+		FileStore: NewFileStore(dir),
+		fieldset:  tsdb.NewMeasurementFieldSet(),
+	}
+	e.fieldset.CreateFieldsIfNotExists([]byte("cpu")).CreateFieldIfNotExists([]byte("value"), influxql.Float, false)
+
+	key := []byte(SeriesFieldKey("cpu,host=A", "value"))
+	if err := e.Cache.Write(key, []Value{NewFloatValue(1, 1.1)}); err != nil {
+		t.Fatal(err)
+	}
+
+	opt := query.IteratorOptions{
+		Expr:      influxql.MustParseExpr(`value`),
+		StartTime: influxql.MinTime,
+		EndTime:   influxql.MaxTime,
+		Ascending: true,
+	}
+	tagSets := []*query.TagSet{{SeriesKeys: []string{"cpu,host=A"}, Filters: []influxql.Expr{nil}}}
+
+	ctx, release := e.readSnapshot(context.Background(), "cpu", tagSets, opt)
+	defer release()
+
+	// A point written after the snapshot is only seen without it.
+	if err := e.Cache.Write(key, []Value{NewFloatValue(2, 1.2)}); err != nil {
+		t.Fatal(err)
+	}
+	if got := len(e.cacheValues(ctx, key)); got != 1 {
+		t.Fatalf("unexpected values in snapshot: %d", got)
+	}
+	if got := len(e.cacheValues(context.Background(), key)); got != 2 {
+		t.Fatalf("unexpected values without snapshot: %d", got)
+	}
+
+	// A snapshot is only used by the engine that took it.
+	other := &Engine{Cache: e.Cache}
+	if got := len(other.cacheValues(ctx, key)); got != 2 {
+		t.Fatalf("unexpected values for other engine: %d", got)
+	}
+}
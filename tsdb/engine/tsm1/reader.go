@@ -13,6 +13,7 @@ import (
 	"sync/atomic"
 
 	"github.com/influxdata/influxdb/pkg/bytesutil"
+	"github.com/influxdata/influxdb/tsdb"
 )
 
 // ErrFileInUse is returned when attempting to remove or close a TSM file that is still being used.
@@ -39,6 +40,9 @@ type TSMReader struct {
 
 	// lastModified is the last time this file was modified on disk
 	lastModified int64
+
+	// blockCache holds decoded blocks read by queries. It may be nil.
+	blockCache *BlockCache
 }
 
 // TSMIndex represent the index section of a TSM file.  The index records all
@@ -211,6 +215,12 @@ type blockAccessor interface {
 
 // NewTSMReader returns a new TSMReader from the given file.
 func NewTSMReader(f *os.File) (*TSMReader, error) {
+	return newTSMReader(f, nil)
+}
+
+// newTSMReader returns a new TSMReader from the given file. The blocks of a
+// tiered file are read from store.
+func newTSMReader(f *os.File, store tsdb.ObjectStore) (*TSMReader, error) {
 	t := &TSMReader{}
 
 	stat, err := f.Stat()
@@ -219,8 +229,18 @@ func NewTSMReader(f *os.File) (*TSMReader, error) {
 	}
 	t.size = stat.Size()
 	t.lastModified = stat.ModTime().UnixNano()
-	t.accessor = &mmapAccessor{
-		f: f,
+
+	if ok, err := isStub(f); err != nil {
+		return nil, err
+	} else if ok {
+		if store == nil {
+			return nil, ErrObjectStoreRequired
+		}
+		t.accessor = &remoteAccessor{f: f, store: store}
+	} else {
+		t.accessor = &mmapAccessor{
+			f: f,
+		}
 	}
 
 	index, err := t.accessor.init()
@@ -302,41 +322,81 @@ func (t *TSMReader) ReadAt(entry *IndexEntry, vals []Value) ([]Value, error) {
 
 // ReadFloatBlockAt returns the float values corresponding to the given index entry.
 func (t *TSMReader) ReadFloatBlockAt(entry *IndexEntry, vals *[]FloatValue) ([]FloatValue, error) {
+	if v, ok := t.blockCache.get(t, entry.Offset).([]FloatValue); ok {
+		*vals = append((*vals)[:0], v...)
+		return *vals, nil
+	}
+
 	t.mu.RLock()
 	v, err := t.accessor.readFloatBlock(entry, vals)
 	t.mu.RUnlock()
+	if err == nil && t.blockCache != nil {
+		t.blockCache.add(t, entry.Offset, append([]FloatValue(nil), v...))
+	}
 	return v, err
 }
 
 // ReadIntegerBlockAt returns the integer values corresponding to the given index entry.
 func (t *TSMReader) ReadIntegerBlockAt(entry *IndexEntry, vals *[]IntegerValue) ([]IntegerValue, error) {
+	if v, ok := t.blockCache.get(t, entry.Offset).([]IntegerValue); ok {
+		*vals = append((*vals)[:0], v...)
+		return *vals, nil
+	}
+
 	t.mu.RLock()
 	v, err := t.accessor.readIntegerBlock(entry, vals)
 	t.mu.RUnlock()
+	if err == nil && t.blockCache != nil {
+		t.blockCache.add(t, entry.Offset, append([]IntegerValue(nil), v...))
+	}
 	return v, err
 }
 
 // ReadUnsignedBlockAt returns the unsigned integer values corresponding to the given index entry.
 func (t *TSMReader) ReadUnsignedBlockAt(entry *IndexEntry, vals *[]UnsignedValue) ([]UnsignedValue, error) {
+	if v, ok := t.blockCache.get(t, entry.Offset).([]UnsignedValue); ok {
+		*vals = append((*vals)[:0], v...)
+		return *vals, nil
+	}
+
 	t.mu.RLock()
 	v, err := t.accessor.readUnsignedBlock(entry, vals)
 	t.mu.RUnlock()
+	if err == nil && t.blockCache != nil {
+		t.blockCache.add(t, entry.Offset, append([]UnsignedValue(nil), v...))
+	}
 	return v, err
 }
 
 // ReadStringBlockAt returns the string values corresponding to the given index entry.
 func (t *TSMReader) ReadStringBlockAt(entry *IndexEntry, vals *[]StringValue) ([]StringValue, error) {
+	if v, ok := t.blockCache.get(t, entry.Offset).([]StringValue); ok {
+		*vals = append((*vals)[:0], v...)
+		return *vals, nil
+	}
+
 	t.mu.RLock()
 	v, err := t.accessor.readStringBlock(entry, vals)
 	t.mu.RUnlock()
+	if err == nil && t.blockCache != nil {
+		t.blockCache.add(t, entry.Offset, append([]StringValue(nil), v...))
+	}
 	return v, err
 }
 
 // ReadBooleanBlockAt returns the boolean values corresponding to the given index entry.
 func (t *TSMReader) ReadBooleanBlockAt(entry *IndexEntry, vals *[]BooleanValue) ([]BooleanValue, error) {
+	if v, ok := t.blockCache.get(t, entry.Offset).([]BooleanValue); ok {
+		*vals = append((*vals)[:0], v...)
+		return *vals, nil
+	}
+
 	t.mu.RLock()
 	v, err := t.accessor.readBooleanBlock(entry, vals)
 	t.mu.RUnlock()
+	if err == nil && t.blockCache != nil {
+		t.blockCache.add(t, entry.Offset, append([]BooleanValue(nil), v...))
+	}
 	return v, err
 }
 
@@ -380,10 +440,58 @@ func (t *TSMReader) Close() error {
 	if err := t.accessor.close(); err != nil {
 		return err
 	}
+	t.blockCache.removeFile(t)
 
 	return t.index.Close()
 }
 
+// readAhead reads the block of key at entry into the block cache in the
+// background. The caller must hold a reference to the reader.
+func (t *TSMReader) readAhead(key []byte, entry IndexEntry) {
+	if t.blockCache == nil || t.blockCache.contains(t, entry.Offset) {
+		return
+	}
+
+	t.Ref()
+	go func() {
+		defer t.Unref()
+
+		typ, err := t.Type(key)
+		if err != nil {
+			return
+		}
+
+		t.mu.RLock()
+		var values interface{}
+		switch typ {
+		case BlockFloat64:
+			var a []FloatValue
+			values, err = t.accessor.readFloatBlock(&entry, &a)
+		case BlockInteger:
+			var a []IntegerValue
+			values, err = t.accessor.readIntegerBlock(&entry, &a)
+		case BlockUnsigned:
+			var a []UnsignedValue
+			values, err = t.accessor.readUnsignedBlock(&entry, &a)
+		case BlockString:
+			var a []StringValue
+			values, err = t.accessor.readStringBlock(&entry, &a)
+		case BlockBoolean:
+			var a []BooleanValue
+			values, err = t.accessor.readBooleanBlock(&entry, &a)
+		default:
+			err = fmt.Errorf("unknown block type: %d", typ)
+		}
+		t.mu.RUnlock()
+		if err != nil {
+			return
+		}
+
+		atomic.AddInt64(&t.blockCache.stats.ReadAheads, 1)
+		t.blockCache.add(t, entry.Offset, values)
+	}()
+}
+
 // Ref records a usage of this TSMReader.  If there are active references
 // when the reader is closed or removed, the reader will remain open until
 // there are no more references.
@@ -433,6 +541,11 @@ func (t *TSMReader) remove() error {
 	if err := t.tombstoner.Delete(); err != nil {
 		return err
 	}
+
+	// The blocks of a tiered file are not referenced by any other file.
+	if r, ok := t.accessor.(*remoteAccessor); ok {
+		return r.store.Delete(r.key)
+	}
 	return nil
 }
 
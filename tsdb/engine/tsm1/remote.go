@@ -0,0 +1,529 @@
+package tsm1
+
+/*
+A tiered TSM file is a TSM file whose blocks have been moved to an object
+store. Only a stub that holds the index of the file is kept on disk, under
+the name of the original file:
+
+┌────────┬─────────┬──────────┬──────────┬─────────┬─────────┬─────────┐
+│ Magic  │ Version │   Size   │ Key Len  │   Key   │  Index  │ Offset  │
+│4 bytes │ 1 byte  │ 8 bytes  │ 2 bytes  │ N bytes │         │ 8 bytes │
+└────────┴─────────┴──────────┴──────────┴─────────┴─────────┴─────────┘
+
+Size is the size of the original file and Key is the key of the object that
+holds it. The index is copied from the original file unchanged, so the
+offsets of its entries are offsets in the object. Offset is the position of
+the index in the stub.
+*/
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/influxdata/influxdb/tsdb"
+)
+
+const (
+	// StubMagicNumber is written as the first 4 bytes of the stub of a
+	// tiered TSM file.
+	StubMagicNumber uint32 = 0x16D116D5
+
+	// stubHeaderSize is the size of the header of a stub before the key.
+	stubHeaderSize = 4 + 1 + 8 + 2
+)
+
+// ErrObjectStoreRequired is returned when opening a tiered TSM file without
+// an object store.
+var ErrObjectStoreRequired = errors.New("tsm file is tiered but no object store is configured")
+
+// Tier moves the blocks of the local TSM files to the object store under
+// prefix and replaces each file with a stub that only holds its index. Files
+// with tombstones are skipped until a compaction removes the tombstones. It
+// returns the number of files moved.
+func (f *FileStore) Tier(prefix string) (int, error) {
+	if f.objectStore == nil {
+		return 0, ErrObjectStoreRequired
+	}
+
+	f.mu.RLock()
+	files := make([]TSMFile, len(f.files))
+	copy(files, f.files)
+	f.mu.RUnlock()
+
+	var n int
+	for _, file := range files {
+		r, ok := file.(*TSMReader)
+		if !ok || r.tiered() || r.HasTombstones() {
+			continue
+		}
+
+		if ok, err := f.tierFile(r, prefix); err != nil {
+			return n, err
+		} else if ok {
+			n++
+		}
+	}
+	return n, nil
+}
+
+// tierFile uploads a file and replaces it with a stub. It returns false if
+// the file was replaced by a compaction or received tombstones in the
+// meantime.
+func (f *FileStore) tierFile(r *TSMReader, prefix string) (bool, error) {
+	// Hold a reference so a concurrent compaction does not remove the file.
+	r.Ref()
+	path := r.Path()
+	key := prefix + "/" + filepath.Base(path)
+	stubPath := fmt.Sprintf("%s.stub.%s", path, CompactionTempExtension)
+	err := f.uploadFile(r, key, stubPath)
+	r.Unref()
+	if err != nil {
+		os.Remove(stubPath)
+		return false, err
+	}
+
+	discard := func() {
+		os.Remove(stubPath)
+		f.objectStore.Delete(key)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	i := -1
+	for j, file := range f.files {
+		if file == r {
+			i = j
+			break
+		}
+	}
+	if i < 0 || r.HasTombstones() {
+		discard()
+		return false, nil
+	}
+
+	// Move the file out of the way as if it were replaced by a compaction.
+	// It no longer shares the tombstones of its former path with the stub.
+	tmpPath := fmt.Sprintf("%s.%s", path, CompactionTempExtension)
+	if err := r.Rename(tmpPath); err != nil {
+		discard()
+		return false, err
+	}
+	if err := os.Rename(stubPath, path); err != nil {
+		r.Rename(path)
+		discard()
+		return false, err
+	}
+
+	fd, err := os.Open(path)
+	if err != nil {
+		os.Rename(path, stubPath)
+		r.Rename(path)
+		discard()
+		return false, err
+	}
+	tsm, err := newTSMReader(fd, f.objectStore)
+	if err != nil {
+		fd.Close()
+		os.Rename(path, stubPath)
+		r.Rename(path)
+		discard()
+		return false, err
+	}
+	tsm.blockCache = f.blockCache
+
+	r.mu.Lock()
+	r.tombstoner = &Tombstoner{Path: tmpPath}
+	r.mu.Unlock()
+
+	f.files[i] = tsm
+	if r.InUse() {
+		f.purger.add([]TSMFile{r})
+	} else {
+		if err := r.Close(); err != nil {
+			return false, err
+		}
+		if err := r.Remove(); err != nil {
+			return false, err
+		}
+	}
+
+	if err := syncDir(f.dir); err != nil {
+		return false, err
+	}
+
+	f.lastFileStats = nil
+	if lm := time.Unix(0, tsm.LastModified()).UTC(); lm.After(f.lastModified) {
+		f.lastModified = lm
+	}
+	atomic.AddInt64(&f.stats.DiskBytes, int64(tsm.Size())-int64(r.Size()))
+	return true, nil
+}
+
+// uploadFile uploads the file of r as the object key and writes its stub
+// to stubPath.
+func (f *FileStore) uploadFile(r *TSMReader, key, stubPath string) error {
+	fd, err := os.Open(r.Path())
+	if err != nil {
+		return err
+	}
+	defer fd.Close()
+
+	size, err := f.objectStore.Upload(key, fd)
+	if err != nil {
+		return err
+	} else if size != int64(r.Size()) || size < 8 {
+		return fmt.Errorf("tier: uploaded %d bytes of %s, expected %d", size, r.Path(), r.Size())
+	}
+
+	// Copy the index from the end of the file.
+	var footer [8]byte
+	if _, err := fd.ReadAt(footer[:], size-8); err != nil {
+		return err
+	}
+	indexStart := int64(binary.BigEndian.Uint64(footer[:]))
+	if indexStart >= size-8 {
+		return fmt.Errorf("tier: invalid indexStart in %s", r.Path())
+	}
+	index := make([]byte, size-8-indexStart)
+	if _, err := fd.ReadAt(index, indexStart); err != nil {
+		return err
+	}
+	return writeStub(stubPath, key, size, index)
+}
+
+// writeStub writes the stub of a tiered file to path.
+func writeStub(path, key string, size int64, index []byte) error {
+	if len(key) > maxKeyLength {
+		return ErrMaxKeyLengthExceeded
+	}
+
+	fd, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_TRUNC, 0666)
+	if err != nil {
+		return err
+	}
+	defer fd.Close()
+
+	buf := make([]byte, stubHeaderSize+len(key))
+	binary.BigEndian.PutUint32(buf[0:4], StubMagicNumber)
+	buf[4] = Version
+	binary.BigEndian.PutUint64(buf[5:13], uint64(size))
+	binary.BigEndian.PutUint16(buf[13:15], uint16(len(key)))
+	copy(buf[stubHeaderSize:], key)
+
+	var footer [8]byte
+	binary.BigEndian.PutUint64(footer[:], uint64(len(buf)))
+
+	for _, b := range [][]byte{buf, index, footer[:]} {
+		if _, err := fd.Write(b); err != nil {
+			return err
+		}
+	}
+	if err := fd.Sync(); err != nil {
+		return err
+	}
+	return fd.Close()
+}
+
+// parseStub returns the object key, the size and the index of the file of
+// the stub b.
+func parseStub(b []byte) (key string, size int64, index []byte, err error) {
+	if len(b) < stubHeaderSize+8 {
+		return "", 0, nil, fmt.Errorf("remoteAccessor: byte slice too small for stub")
+	} else if binary.BigEndian.Uint32(b[0:4]) != StubMagicNumber {
+		return "", 0, nil, fmt.Errorf("remoteAccessor: not a stub")
+	} else if b[4] != Version {
+		return "", 0, nil, fmt.Errorf("remoteAccessor: stub is version %b. expected %b", b[4], Version)
+	}
+	size = int64(binary.BigEndian.Uint64(b[5:13]))
+	indexStart := stubHeaderSize + int(binary.BigEndian.Uint16(b[13:15]))
+	indexOfsPos := len(b) - 8
+	if indexStart > indexOfsPos || binary.BigEndian.Uint64(b[indexOfsPos:]) != uint64(indexStart) {
+		return "", 0, nil, fmt.Errorf("remoteAccessor: invalid indexStart")
+	}
+	return string(b[stubHeaderSize:indexStart]), size, b[indexStart:indexOfsPos], nil
+}
+
+// cloneStubs copies the objects of the stubs in dir to keys under prefix and
+// replaces each stub with one for the copy. Stubs in dir are hard links to
+// the stubs of the file store, so they are replaced rather than rewritten.
+func (f *FileStore) cloneStubs(dir, prefix string) error {
+	files, err := filepath.Glob(filepath.Join(dir, "*."+TSMFileExtension))
+	if err != nil {
+		return err
+	}
+
+	for _, path := range files {
+		fd, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		ok, err := isStub(fd)
+		if err != nil || !ok {
+			fd.Close()
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		b, err := ioutil.ReadAll(fd)
+		fd.Close()
+		if err != nil {
+			return err
+		}
+
+		if f.objectStore == nil {
+			return ErrObjectStoreRequired
+		}
+		key, size, index, err := parseStub(b)
+		if err != nil {
+			return err
+		}
+		newKey := prefix + "/" + filepath.Base(path)
+		if err := f.objectStore.Copy(key, newKey); err != nil {
+			return err
+		}
+
+		tmpPath := path + ".stub.tmp"
+		if err := writeStub(tmpPath, newKey, size, index); err != nil {
+			return err
+		} else if err := os.Rename(tmpPath, path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isStub returns true if f is the stub of a tiered file.
+func isStub(f *os.File) (bool, error) {
+	var b [4]byte
+	if _, err := f.ReadAt(b[:], 0); err == io.EOF {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	return binary.BigEndian.Uint32(b[:]) == StubMagicNumber, nil
+}
+
+// tiered returns true if the blocks of the file are in an object store.
+func (t *TSMReader) tiered() bool {
+	_, ok := t.accessor.(*remoteAccessor)
+	return ok
+}
+
+// remoteAccessor is a block accessor for tiered files. The index is read
+// from the stub into memory and blocks are read from the object store.
+type remoteAccessor struct {
+	mu sync.RWMutex
+
+	f     *os.File
+	b     []byte
+	index *indirectIndex
+
+	store tsdb.ObjectStore
+	key   string
+	size  int64
+}
+
+func (m *remoteAccessor) init() (*indirectIndex, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, err := m.f.Seek(0, 0); err != nil {
+		return nil, err
+	}
+	b, err := ioutil.ReadAll(m.f)
+	if err != nil {
+		return nil, err
+	}
+
+	key, size, index, err := parseStub(b)
+	if err != nil {
+		return nil, err
+	}
+
+	m.b, m.key, m.size = b, key, size
+	m.index = NewIndirectIndex()
+	if err := m.index.UnmarshalBinary(index); err != nil {
+		return nil, err
+	}
+	return m.index, nil
+}
+
+// block returns the checksum and the data of the block of entry.
+func (m *remoteAccessor) block(entry *IndexEntry) (uint32, []byte, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if m.b == nil {
+		return 0, nil, ErrTSMClosed
+	} else if entry.Offset+int64(entry.Size) > m.size {
+		return 0, nil, fmt.Errorf("remoteAccessor: block at offset %d is out of range", entry.Offset)
+	}
+
+	b := make([]byte, entry.Size)
+	if _, err := m.store.ReadAt(m.key, b, entry.Offset); err != nil {
+		return 0, nil, err
+	}
+
+	// Unlike local files, check the blocks read over the network.
+	crc := binary.BigEndian.Uint32(b[:4])
+	if crc32.ChecksumIEEE(b[4:]) != crc {
+		return 0, nil, fmt.Errorf("remoteAccessor: checksum mismatch for block of %s at offset %d", m.key, entry.Offset)
+	}
+	return crc, b[4:], nil
+}
+
+func (m *remoteAccessor) read(key []byte, timestamp int64) ([]Value, error) {
+	entry := m.index.Entry(key, timestamp)
+	if entry == nil {
+		return nil, nil
+	}
+
+	return m.readBlock(entry, nil)
+}
+
+func (m *remoteAccessor) readBlock(entry *IndexEntry, values []Value) ([]Value, error) {
+	_, b, err := m.block(entry)
+	if err != nil {
+		return nil, err
+	}
+	return DecodeBlock(b, values)
+}
+
+func (m *remoteAccessor) readFloatBlock(entry *IndexEntry, values *[]FloatValue) ([]FloatValue, error) {
+	_, b, err := m.block(entry)
+	if err != nil {
+		return nil, err
+	}
+	return DecodeFloatBlock(b, values)
+}
+
+func (m *remoteAccessor) readIntegerBlock(entry *IndexEntry, values *[]IntegerValue) ([]IntegerValue, error) {
+	_, b, err := m.block(entry)
+	if err != nil {
+		return nil, err
+	}
+	return DecodeIntegerBlock(b, values)
+}
+
+func (m *remoteAccessor) readUnsignedBlock(entry *IndexEntry, values *[]UnsignedValue) ([]UnsignedValue, error) {
+	_, b, err := m.block(entry)
+	if err != nil {
+		return nil, err
+	}
+	return DecodeUnsignedBlock(b, values)
+}
+
+func (m *remoteAccessor) readStringBlock(entry *IndexEntry, values *[]StringValue) ([]StringValue, error) {
+	_, b, err := m.block(entry)
+	if err != nil {
+		return nil, err
+	}
+	return DecodeStringBlock(b, values)
+}
+
+func (m *remoteAccessor) readBooleanBlock(entry *IndexEntry, values *[]BooleanValue) ([]BooleanValue, error) {
+	_, b, err := m.block(entry)
+	if err != nil {
+		return nil, err
+	}
+	return DecodeBooleanBlock(b, values)
+}
+
+func (m *remoteAccessor) readBytes(entry *IndexEntry, b []byte) (uint32, []byte, error) {
+	return m.block(entry)
+}
+
+// readAll returns all values for a key in all blocks.
+func (m *remoteAccessor) readAll(key []byte) ([]Value, error) {
+	blocks := m.index.Entries(key)
+	if len(blocks) == 0 {
+		return nil, nil
+	}
+
+	tombstones := m.index.TombstoneRange(key)
+
+	var temp []Value
+	var values []Value
+	for _, block := range blocks {
+		var skip bool
+		for _, t := range tombstones {
+			// Should we skip this block because it contains points that have been deleted
+			if t.Min <= block.MinTime && t.Max >= block.MaxTime {
+				skip = true
+				break
+			}
+		}
+
+		if skip {
+			continue
+		}
+
+		_, b, err := m.block(&block)
+		if err != nil {
+			return nil, err
+		}
+		temp, err = DecodeBlock(b, temp[:0])
+		if err != nil {
+			return nil, err
+		}
+
+		// Filter out any values that were deleted
+		for _, t := range tombstones {
+			temp = Values(temp).Exclude(t.Min, t.Max)
+		}
+
+		values = append(values, temp...)
+	}
+
+	return values, nil
+}
+
+func (m *remoteAccessor) rename(path string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.f.Close(); err != nil {
+		return err
+	}
+
+	if err := renameFile(m.f.Name(), path); err != nil {
+		return err
+	}
+
+	var err error
+	m.f, err = os.Open(path)
+	return err
+}
+
+func (m *remoteAccessor) path() string {
+	m.mu.RLock()
+	path := m.f.Name()
+	m.mu.RUnlock()
+	return path
+}
+
+func (m *remoteAccessor) close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.b == nil {
+		return nil
+	}
+
+	m.b = nil
+	return m.f.Close()
+}
+
+// free is a no-op as the index of a stub is held in memory.
+func (m *remoteAccessor) free() error { return nil }
@@ -0,0 +1,203 @@
+package tsm1
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestFileStore_Tier(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tsm1-tier")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "000000001-000000001.tsm")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, err := NewTSMWriter(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var cpu []Value
+	for i := 0; i < 2500; i++ {
+		cpu = append(cpu, NewValue(int64(i), float64(i)))
+	}
+	mem := []Value{NewValue(0, int64(1)), NewValue(1, int64(2))}
+	if err := w.Write([]byte("cpu"), cpu); err != nil {
+		t.Fatal(err)
+	} else if err := w.Write([]byte("mem"), mem); err != nil {
+		t.Fatal(err)
+	} else if err := w.WriteIndex(); err != nil {
+		t.Fatal(err)
+	} else if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	stat, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	store := &memObjectStore{objects: make(map[string][]byte)}
+	fs := NewFileStore(dir)
+	fs.objectStore = store
+	if err := fs.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer fs.Close()
+
+	if n, err := fs.Tier("db/rp/1"); err != nil {
+		t.Fatal(err)
+	} else if n != 1 {
+		t.Fatalf("unexpected number of files tiered: %d", n)
+	}
+	obj := store.objects["db/rp/1/000000001-000000001.tsm"]
+	if int64(len(obj)) != stat.Size() {
+		t.Fatalf("unexpected object size: %d", len(obj))
+	}
+	if fi, err := os.Stat(path); err != nil {
+		t.Fatal(err)
+	} else if fi.Size() >= stat.Size()/10 {
+		t.Fatalf("stub not smaller than file: %d >= %d", fi.Size(), stat.Size())
+	}
+
+	// Tiered files are not tiered again.
+	if n, err := fs.Tier("db/rp/1"); err != nil || n != 0 {
+		t.Fatalf("unexpected result: %d, %v", n, err)
+	}
+
+	// Cloned stubs refer to a copy of the object and leave the original.
+	snapshot, err := fs.CreateSnapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(snapshot)
+	if err := fs.cloneStubs(snapshot, "db/rp/2"); err != nil {
+		t.Fatal(err)
+	}
+	for path, exp := range map[string]string{
+		path: "db/rp/1/000000001-000000001.tsm",
+		filepath.Join(snapshot, filepath.Base(path)): "db/rp/2/000000001-000000001.tsm",
+	} {
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if key, _, _, err := parseStub(b); err != nil {
+			t.Fatal(err)
+		} else if key != exp {
+			t.Fatalf("unexpected key: got %s, exp %s", key, exp)
+		}
+	}
+	if len(store.objects["db/rp/2/000000001-000000001.tsm"]) != len(obj) {
+		t.Fatal("object not copied")
+	}
+
+	readAll := func(fs *FileStore, key string) []Value {
+		values, err := fs.Files()[0].(*TSMReader).ReadAll([]byte(key))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return values
+	}
+	if got := readAll(fs, "cpu"); !reflect.DeepEqual(got, cpu) {
+		t.Fatalf("unexpected cpu values: got %d values", len(got))
+	} else if got := readAll(fs, "mem"); !reflect.DeepEqual(got, mem) {
+		t.Fatalf("unexpected mem values: %v", got)
+	}
+	if err := fs.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Stubs can only be opened with an object store.
+	if err := NewFileStore(dir).Open(); err == nil || !strings.Contains(err.Error(), ErrObjectStoreRequired.Error()) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	fs = NewFileStore(dir)
+	fs.objectStore = store
+	if err := fs.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer fs.Close()
+	if got, err := fs.Read([]byte("mem"), 1); err != nil {
+		t.Fatal(err)
+	} else if fmt.Sprint(got) != fmt.Sprint(mem) {
+		t.Fatalf("unexpected values: %v", got)
+	}
+
+	// Corrupt blocks are detected.
+	corrupt := append([]byte(nil), obj...)
+	corrupt[10] ^= 0xFF
+	store.objects["db/rp/1/000000001-000000001.tsm"] = corrupt
+	if _, err := fs.Files()[0].(*TSMReader).ReadAll([]byte("cpu")); err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Removing a tiered file removes its object.
+	if err := fs.Replace([]string{path}, nil); err != nil {
+		t.Fatal(err)
+	} else if _, ok := store.objects["db/rp/1/000000001-000000001.tsm"]; ok || len(store.objects) != 1 {
+		t.Fatalf("unexpected objects: %d", len(store.objects))
+	}
+}
+
+// memObjectStore is an object store held in memory.
+type memObjectStore struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+func (s *memObjectStore) Upload(key string, r io.Reader) (int64, error) {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return 0, err
+	}
+	s.mu.Lock()
+	s.objects[key] = b
+	s.mu.Unlock()
+	return int64(len(b)), nil
+}
+
+func (s *memObjectStore) ReadAt(key string, p []byte, off int64) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b, ok := s.objects[key]
+	if !ok {
+		return 0, os.ErrNotExist
+	} else if off >= int64(len(b)) {
+		return 0, io.EOF
+	}
+	n := copy(p, b[off:])
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+func (s *memObjectStore) Delete(key string) error {
+	s.mu.Lock()
+	delete(s.objects, key)
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *memObjectStore) Copy(src, dst string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b, ok := s.objects[src]
+	if !ok {
+		return os.ErrNotExist
+	}
+	s.objects[dst] = b
+	return nil
+}
@@ -0,0 +1,70 @@
+package tsdb_test
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/query"
+	"github.com/influxdata/influxdb/tsdb"
+	"github.com/influxdata/influxql"
+)
+
+// This example opens a store, writes points to a shard and reads them back.
+func ExampleStore() {
+	dir, err := ioutil.TempDir("", "influxdb-example-")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(dir)
+
+	s := tsdb.NewStore(filepath.Join(dir, "data"))
+	s.EngineOptions.Config.WALDir = filepath.Join(dir, "wal")
+	if err := s.Open(); err != nil {
+		panic(err)
+	}
+	defer s.Close()
+
+	// Create a shard for the db0.rp0 retention policy and write to it.
+	if err := s.CreateShard("db0", "rp0", 1, true); err != nil {
+		panic(err)
+	}
+	points, err := models.ParsePointsWithPrecision([]byte("cpu,host=serverA value=1 0\ncpu,host=serverA value=2 10"), time.Time{}, "s")
+	if err != nil {
+		panic(err)
+	}
+	if err := s.WriteToShard(1, points); err != nil {
+		panic(err)
+	}
+
+	// Read the values of the points back in time order.
+	itr, err := s.ShardGroup([]uint64{1}).CreateIterator(context.Background(), &influxql.Measurement{Name: "cpu"}, query.IteratorOptions{
+		Expr:      influxql.MustParseExpr(`value`),
+		Ascending: true,
+		StartTime: influxql.MinTime,
+		EndTime:   influxql.MaxTime,
+	})
+	if err != nil {
+		panic(err)
+	}
+	defer itr.Close()
+
+	fitr := itr.(query.FloatIterator)
+	for {
+		p, err := fitr.Next()
+		if err != nil {
+			panic(err)
+		} else if p == nil {
+			break
+		}
+		fmt.Println(time.Unix(0, p.Time).UTC().Format(time.RFC3339), p.Value)
+	}
+
+	// Output:
+	// 1970-01-01T00:00:00Z 1
+	// 1970-01-01T00:00:10Z 2
+}
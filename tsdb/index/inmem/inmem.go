@@ -17,7 +17,8 @@ import (
 	"regexp"
 	"sort"
 	"sync"
-	// "sync/atomic"
+	"sync/atomic"
+	"unsafe"
 
 	"github.com/influxdata/influxdb/models"
 	"github.com/influxdata/influxdb/pkg/bytesutil"
@@ -41,19 +42,31 @@ func init() {
 	})
 }
 
+// seriesPartitionN is the number of partitions of the series in an index.
+const seriesPartitionN = 16
+
 // Index is the in memory index of a collection of measurements, time
 // series, and their tags. Exported functions are goroutine safe while
 // un-exported functions assume the caller will use the appropriate locks.
+//
+// The series are partitioned by a hash of their measurement name and each
+// partition has its own lock, so writes that create or look up series of
+// different measurements do not contend with each other. mu only guards the
+// measurements.
 type Index struct {
+	// Accessed atomically, so they are kept first for 64-bit alignment.
+	seriesN int64  // number of series in all partitions
+	lastID  uint64 // last used series ID. They're in memory only for this shard
+
 	mu sync.RWMutex
 
 	database string
 
 	// In-memory metadata index, built on load and updated when new series come in
 	measurements map[string]*Measurement // measurement name to object and index
-	series       map[string]*Series      // map series key to the Series object
-	lastID       uint64                  // last used series ID. They're in memory only for this shard
+	partitions   [seriesPartitionN]seriesPartition
 
+	sketchMu                                 sync.Mutex // guards the series sketches
 	seriesSketch, seriesTSSketch             *hll.Plus
 	measurementsSketch, measurementsTSSketch *hll.Plus
 
@@ -66,7 +79,9 @@ func NewIndex(database string) *Index {
 	index := &Index{
 		database:     database,
 		measurements: make(map[string]*Measurement),
-		series:       make(map[string]*Series),
+	}
+	for j := range index.partitions {
+		index.partitions[j].series = make(map[string]*Series)
 	}
 
 	index.seriesSketch = hll.NewDefaultPlus()
@@ -83,18 +98,33 @@ func (i *Index) Close() error      { return nil }
 
 func (i *Index) WithLogger(zap.Logger) {}
 
+// seriesPartition holds the series of the measurements whose names hash to it.
+type seriesPartition struct {
+	mu     sync.RWMutex
+	series map[string]*Series // map series key to the Series object
+}
+
+// partition returns the partition of the series key.
+func (i *Index) partition(key []byte) *seriesPartition {
+	name, _ := models.ParseName(key)
+	h := models.NewInlineFNV64a()
+	h.Write(name)
+	return &i.partitions[h.Sum64()%seriesPartitionN]
+}
+
 // Series returns a series by key.
 func (i *Index) Series(key []byte) (*Series, error) {
-	i.mu.RLock()
-	s := i.series[string(key)]
-	i.mu.RUnlock()
+	p := i.partition(key)
+	p.mu.RLock()
+	s := p.series[string(key)]
+	p.mu.RUnlock()
 	return s, nil
 }
 
 // SeriesSketches returns the sketches for the series.
 func (i *Index) SeriesSketches() (estimator.Sketch, estimator.Sketch, error) {
-	i.mu.RLock()
-	defer i.mu.RUnlock()
+	i.sketchMu.Lock()
+	defer i.sketchMu.Unlock()
 	return i.seriesSketch.Clone(), i.seriesTSSketch.Clone(), nil
 }
 
@@ -102,10 +132,79 @@ func (i *Index) SeriesSketches() (estimator.Sketch, estimator.Sketch, error) {
 // Since indexes are not shared across shards, the count returned by SeriesN
 // cannot be combined with other shards' counts.
 func (i *Index) SeriesN() int64 {
+	return atomic.LoadInt64(&i.seriesN)
+}
+
+// Statistics keys for index memory usage.
+const (
+	statIndexMeasurements = "measurements" // Number of measurements in the index.
+	statIndexSeries       = "series"       // Number of series in the index.
+	statIndexTagKeys      = "tagKeys"      // Number of tag keys of a measurement.
+	statIndexTagValues    = "tagValues"    // Number of values of a tag key.
+	statIndexMemBytes     = "memBytes"     // Estimated number of bytes of memory used.
+)
+
+// MemoryStatistics returns estimates of the memory used by the index for the
+// database as a whole, for each measurement and for each tag key.
+//
+// The estimates walk the entire index so they can be expensive to compute
+// for databases with a large number of series.
+func (i *Index) MemoryStatistics(tags map[string]string) []models.Statistic {
 	i.mu.RLock()
-	n := int64(len(i.series))
-	i.mu.RUnlock()
-	return n
+	defer i.mu.RUnlock()
+
+	dbTags := models.StatisticTags{"database": i.database}.Merge(tags)
+	statistics := make([]models.Statistic, 0, len(i.measurements)+1)
+
+	// The series maps hold a copy of each series key.
+	var total int
+	for j := range i.partitions {
+		p := &i.partitions[j]
+		p.mu.RLock()
+		total += len(p.series) * 16
+		for k := range p.series {
+			total += int(unsafe.Sizeof(k)) + len(k)
+		}
+		p.mu.RUnlock()
+	}
+
+	for name, m := range i.measurements {
+		n, tagKeys := m.memoryStats()
+		total += int(unsafe.Sizeof(name)) + len(name) + n
+
+		mTags := models.StatisticTags{"database": i.database, "measurement": name}.Merge(tags)
+		statistics = append(statistics, models.Statistic{
+			Name: "index_measurement",
+			Tags: mTags,
+			Values: map[string]interface{}{
+				statIndexSeries:   len(m.SeriesIDs()),
+				statIndexTagKeys:  len(tagKeys),
+				statIndexMemBytes: int64(n),
+			},
+		})
+
+		for k, kn := range tagKeys {
+			statistics = append(statistics, models.Statistic{
+				Name: "index_tag_key",
+				Tags: models.StatisticTags{"database": i.database, "measurement": name, "tagKey": k}.Merge(tags),
+				Values: map[string]interface{}{
+					statIndexTagValues: m.Cardinality(k),
+					statIndexMemBytes:  int64(kn),
+				},
+			})
+		}
+	}
+
+	statistics = append(statistics, models.Statistic{
+		Name: "index",
+		Tags: dbTags,
+		Values: map[string]interface{}{
+			statIndexMeasurements: len(i.measurements),
+			statIndexSeries:       int(i.SeriesN()),
+			statIndexMemBytes:     int64(total),
+		},
+	})
+	return statistics
 }
 
 // Measurement returns the measurement object from the index by the name
@@ -146,10 +245,12 @@ func (i *Index) MeasurementsByName(names [][]byte) ([]*Measurement, error) {
 // CreateSeriesIfNotExists adds the series for the given measurement to the
 // index and sets its ID or returns the existing series object
 func (i *Index) CreateSeriesIfNotExists(shardID uint64, key, name []byte, tags models.Tags, opt *tsdb.EngineOptions, ignoreLimits bool) error {
-	i.mu.RLock()
+	p := i.partition(key)
+
+	p.mu.RLock()
 	// if there is a series for this id, it's already been added
-	ss := i.series[string(key)]
-	i.mu.RUnlock()
+	ss := p.series[string(key)]
+	p.mu.RUnlock()
 
 	if ss != nil {
 		ss.AssignShard(shardID)
@@ -159,37 +260,41 @@ func (i *Index) CreateSeriesIfNotExists(shardID uint64, key, name []byte, tags m
 	// get or create the measurement index
 	m := i.CreateMeasurementIndexIfNotExists(name)
 
-	i.mu.Lock()
-	defer i.mu.Unlock()
+	p.mu.Lock()
+	defer p.mu.Unlock()
 
 	// Check for the series again under a write lock
-	ss = i.series[string(key)]
+	ss = p.series[string(key)]
 	if ss != nil {
 		ss.AssignShard(shardID)
 		return nil
 	}
 
 	// Verify that the series will not exceed limit.
+	n := atomic.AddInt64(&i.seriesN, 1)
 	if !ignoreLimits {
-		if max := opt.Config.MaxSeriesPerDatabase; max > 0 && len(i.series)+1 > max {
+		if max := opt.Config.MaxSeriesPerDatabase; max > 0 && n > int64(max) {
+			atomic.AddInt64(&i.seriesN, -1)
 			return errMaxSeriesPerDatabaseExceeded
 		}
 	}
 
 	// set the in memory ID for query processing on this shard
-	// The series key and tags are clone to prevent a memory leak
-	series := NewSeries([]byte(string(key)), tags.Clone())
-	series.ID = i.lastID + 1
-	i.lastID++
+	// The series key and tags are cloned to prevent a memory leak. The key is
+	// shared by the series and the partition map.
+	series := NewSeries(key, cloneTags(tags))
+	series.ID = atomic.AddUint64(&i.lastID, 1)
 
 	series.SetMeasurement(m)
-	i.series[string(key)] = series
+	p.series[series.Key] = series
 
 	m.AddSeries(series)
 	series.AssignShard(shardID)
 
 	// Add the series to the series sketch.
+	i.sketchMu.Lock()
 	i.seriesSketch.Add(key)
+	i.sketchMu.Unlock()
 
 	return nil
 }
@@ -378,12 +483,21 @@ func (i *Index) TagKeyCardinality(name, key []byte) int {
 	return mm.CardinalityBytes(key)
 }
 
-// TagsForSeries returns the tag map for the passed in series
-func (i *Index) TagsForSeries(key string) (models.Tags, error) {
+// MeasurementSeriesN returns the number of series of a measurement.
+func (i *Index) MeasurementSeriesN(name []byte) int {
 	i.mu.RLock()
-	ss := i.series[key]
+	mm := i.measurements[string(name)]
 	i.mu.RUnlock()
 
+	if mm == nil {
+		return 0
+	}
+	return mm.SeriesN()
+}
+
+// TagsForSeries returns the tag map for the passed in series
+func (i *Index) TagsForSeries(key string) (models.Tags, error) {
+	ss, _ := i.Series([]byte(key))
 	if ss == nil {
 		return nil, nil
 	}
@@ -584,8 +698,17 @@ func (i *Index) dropMeasurement(name string) error {
 
 	delete(i.measurements, name)
 	for _, s := range m.SeriesByIDMap() {
-		delete(i.series, s.Key)
+		p := i.partition([]byte(s.Key))
+		p.mu.Lock()
+		if _, ok := p.series[s.Key]; ok {
+			delete(p.series, s.Key)
+			atomic.AddInt64(&i.seriesN, -1)
+		}
+		p.mu.Unlock()
+
+		i.sketchMu.Lock()
 		i.seriesTSSketch.Add([]byte(s.Key))
+		i.sketchMu.Unlock()
 	}
 	return nil
 }
@@ -596,31 +719,46 @@ func (i *Index) DropSeries(key []byte) error {
 		return nil
 	}
 
-	i.mu.Lock()
-	defer i.mu.Unlock()
-
 	k := string(key)
-	series := i.series[k]
+	p := i.partition(key)
+	p.mu.Lock()
+	series := p.series[k]
 	if series == nil {
+		p.mu.Unlock()
 		return nil
 	}
 
+	// Remove from the index.
+	delete(p.series, k)
+	atomic.AddInt64(&i.seriesN, -1)
+	p.mu.Unlock()
+
 	// Update the tombstone sketch.
+	i.sketchMu.Lock()
 	i.seriesTSSketch.Add([]byte(k))
-
-	// Remove from the index.
-	delete(i.series, k)
+	i.sketchMu.Unlock()
 
 	// Remove the measurement's reference.
-	series.Measurement().DropSeries(series)
+	m := series.Measurement()
+	m.DropSeries(series)
 
 	// Mark the series as deleted.
 	series.Delete()
 
-	// If the measurement no longer has any series, remove it as well.
-	if !series.Measurement().HasSeries() {
-		i.dropMeasurement(series.Measurement().Name)
+	// If the measurement no longer has any series, remove it as well. A
+	// rebuild may have replaced the measurement since the series was added,
+	// so the series is also dropped from the current one. This is done under
+	// the lock as a rebuild installs its measurement under it too.
+	i.mu.Lock()
+	if cur := i.measurements[m.Name]; cur != nil {
+		if cur != m {
+			cur.DropSeries(series)
+		}
+		if !cur.HasSeries() {
+			i.dropMeasurement(m.Name)
+		}
 	}
+	i.mu.Unlock()
 
 	return nil
 }
@@ -644,12 +782,15 @@ func (i *Index) TagSets(shardID uint64, name []byte, opt query.IteratorOptions)
 }
 
 func (i *Index) SeriesKeys() []string {
-	i.mu.RLock()
-	s := make([]string, 0, len(i.series))
-	for k := range i.series {
-		s = append(s, k)
+	s := make([]string, 0, i.SeriesN())
+	for j := range i.partitions {
+		p := &i.partitions[j]
+		p.mu.RLock()
+		for k := range p.series {
+			s = append(s, k)
+		}
+		p.mu.RUnlock()
 	}
-	i.mu.RUnlock()
 	return s
 }
 
@@ -783,8 +924,23 @@ func (i *Index) Rebuild() {
 
 		nm := m.Rebuild()
 		i.mu.Lock()
+		defer i.mu.Unlock()
+
+		// The measurement may have been dropped while it was rebuilt, and
+		// series dropped from it since may have been copied to the new one.
+		if i.measurements[string(name)] != m {
+			return nil
+		}
+		for _, id := range nm.SeriesIDs() {
+			if s := nm.SeriesByID(id); s != nil && s.Deleted() {
+				nm.DropSeries(s)
+			}
+		}
+		if !nm.HasSeries() {
+			i.dropMeasurement(string(name))
+			return nil
+		}
 		i.measurements[string(name)] = nm
-		i.mu.Unlock()
 		return nil
 	})
 }
@@ -801,10 +957,9 @@ func (i *Index) RemoveShard(shardID uint64) {
 // assignExistingSeries assigns the existings series to shardID and returns the series, names and tags that
 // do not exists yet.
 func (i *Index) assignExistingSeries(shardID uint64, keys, names [][]byte, tagsSlice []models.Tags) ([][]byte, [][]byte, []models.Tags) {
-	i.mu.RLock()
 	var n int
 	for j, key := range keys {
-		if ss := i.series[string(key)]; ss == nil {
+		if ss, _ := i.Series(key); ss == nil {
 			keys[n] = keys[j]
 			names[n] = names[j]
 			tagsSlice[n] = tagsSlice[j]
@@ -813,7 +968,6 @@ func (i *Index) assignExistingSeries(shardID uint64, keys, names [][]byte, tagsS
 			ss.AssignShard(shardID)
 		}
 	}
-	i.mu.RUnlock()
 	return keys[:n], names[:n], tagsSlice[:n]
 }
 
@@ -0,0 +1,223 @@
+package inmem_test
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/tsdb"
+	"github.com/influxdata/influxdb/tsdb/index/inmem"
+)
+
+// Ensure the index reports memory statistics by database, measurement and tag key.
+func TestIndex_MemoryStatistics(t *testing.T) {
+	idx := inmem.NewIndex("db0")
+	opt := tsdb.NewEngineOptions()
+	for _, key := range []string{
+		"cpu,host=serverA,region=west",
+		"cpu,host=serverB,region=west",
+		"mem,host=serverA",
+	} {
+		name, tags := models.ParseKey([]byte(key))
+		if err := idx.CreateSeriesIfNotExists(1, []byte(key), []byte(name), tags, &opt, false); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	stats := make(map[string]models.Statistic)
+	for _, s := range idx.MemoryStatistics(map[string]string{"id": "1"}) {
+		if s.Tags["id"] != "1" || s.Tags["database"] != "db0" {
+			t.Fatalf("unexpected tags: %v", s.Tags)
+		}
+		stats[s.Name+"/"+s.Tags["measurement"]+"/"+s.Tags["tagKey"]] = s
+	}
+	if len(stats) != 6 {
+		t.Fatalf("unexpected number of statistics: %d", len(stats))
+	}
+
+	for _, tt := range []struct {
+		key    string
+		values map[string]interface{}
+	}{
+		{key: "index//", values: map[string]interface{}{"measurements": 2, "series": 3}},
+		{key: "index_measurement/cpu/", values: map[string]interface{}{"series": 2, "tagKeys": 2}},
+		{key: "index_measurement/mem/", values: map[string]interface{}{"series": 1, "tagKeys": 1}},
+		{key: "index_tag_key/cpu/host", values: map[string]interface{}{"tagValues": 2}},
+		{key: "index_tag_key/cpu/region", values: map[string]interface{}{"tagValues": 1}},
+		{key: "index_tag_key/mem/host", values: map[string]interface{}{"tagValues": 1}},
+	} {
+		s, ok := stats[tt.key]
+		if !ok {
+			t.Fatalf("%s: missing statistic", tt.key)
+		}
+		for k, v := range tt.values {
+			if s.Values[k] != v {
+				t.Fatalf("%s: unexpected %s: %v", tt.key, k, s.Values[k])
+			}
+		}
+		if n, _ := s.Values["memBytes"].(int64); n <= 0 {
+			t.Fatalf("%s: unexpected memBytes: %v", tt.key, s.Values["memBytes"])
+		}
+	}
+
+	// The database total includes every measurement.
+	total := stats["index//"].Values["memBytes"].(int64)
+	if cpu := stats["index_measurement/cpu/"].Values["memBytes"].(int64); total <= cpu {
+		t.Fatalf("database bytes %d not greater than measurement bytes %d", total, cpu)
+	}
+}
+
+// Ensure series of several measurements can be created and dropped concurrently.
+func TestIndex_CreateSeriesIfNotExists_Concurrent(t *testing.T) {
+	idx := inmem.NewIndex("db0")
+	opt := tsdb.NewEngineOptions()
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 8)
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				key := fmt.Sprintf("m%d,host=h%d", g%4, j)
+				name, tags := models.ParseKey([]byte(key))
+				if err := idx.CreateSeriesIfNotExists(uint64(g), []byte(key), []byte(name), tags, &opt, false); err != nil {
+					errs <- err
+					return
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatal(err)
+	}
+
+	if n := idx.SeriesN(); n != 400 {
+		t.Fatalf("unexpected series count: %d", n)
+	}
+
+	// Every series has a distinct ID.
+	ids := make(map[uint64]struct{})
+	for _, key := range idx.SeriesKeys() {
+		s, _ := idx.Series([]byte(key))
+		ids[s.ID] = struct{}{}
+	}
+	if len(ids) != 400 {
+		t.Fatalf("unexpected number of series IDs: %d", len(ids))
+	}
+
+	// Dropping the last series of a measurement drops the measurement.
+	for j := 0; j < 100; j++ {
+		if err := idx.DropSeries([]byte(fmt.Sprintf("m0,host=h%d", j))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := idx.SeriesN(); n != 300 {
+		t.Fatalf("unexpected series count: %d", n)
+	} else if ok, _ := idx.MeasurementExists([]byte("m0")); ok {
+		t.Fatal("expected measurement m0 to be dropped")
+	}
+}
+
+// Ensure a measurement is dropped with its last series while the index is
+// being rebuilt concurrently.
+func TestIndex_DropSeries_ConcurrentRebuild(t *testing.T) {
+	opt := tsdb.NewEngineOptions()
+	for iter := 0; iter < 50; iter++ {
+		idx := inmem.NewIndex("db0")
+		for j := 0; j < 100; j++ {
+			key := fmt.Sprintf("cpu,host=h%d", j)
+			name, tags := models.ParseKey([]byte(key))
+			if err := idx.CreateSeriesIfNotExists(1, []byte(key), []byte(name), tags, &opt, false); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		// Dropping a series marks the measurement dirty so it is rebuilt.
+		if err := idx.DropSeries([]byte("cpu,host=h0")); err != nil {
+			t.Fatal(err)
+		}
+
+		done, started := make(chan struct{}), make(chan struct{})
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			idx.Rebuild()
+			close(started)
+			for {
+				select {
+				case <-done:
+					return
+				default:
+					idx.Rebuild()
+				}
+			}
+		}()
+
+		<-started
+		for j := 1; j < 100; j++ {
+			if err := idx.DropSeries([]byte(fmt.Sprintf("cpu,host=h%d", j))); err != nil {
+				t.Fatal(err)
+			}
+		}
+		close(done)
+		wg.Wait()
+
+		if ok, _ := idx.MeasurementExists([]byte("cpu")); ok {
+			t.Fatalf("iteration %d: expected measurement cpu to be dropped", iter)
+		} else if n := idx.SeriesN(); n != 0 {
+			t.Fatalf("iteration %d: unexpected series count: %d", iter, n)
+		}
+	}
+}
+
+func BenchmarkIndex_CreateSeriesIfNotExists_Parallel(b *testing.B) {
+	idx := inmem.NewIndex("db0")
+	opt := tsdb.NewEngineOptions()
+
+	keys := make([][]byte, 100000)
+	for j := range keys {
+		keys[j] = []byte(fmt.Sprintf("m%d,host=h%d", j%50, j))
+	}
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		var j int
+		for pb.Next() {
+			key := keys[j%len(keys)]
+			name, tags := models.ParseKey(key)
+			if err := idx.CreateSeriesIfNotExists(1, key, []byte(name), tags, &opt, false); err != nil {
+				b.Fatal(err)
+			}
+			j++
+		}
+	})
+}
+
+// BenchmarkIndex_CreateSeriesIfNotExists creates 100K series in an empty index.
+func BenchmarkIndex_CreateSeriesIfNotExists(b *testing.B) {
+	opt := tsdb.NewEngineOptions()
+
+	keys := make([][]byte, 100000)
+	for j := range keys {
+		keys[j] = []byte(fmt.Sprintf("m%d,host=h%d,region=r%d", j%50, j, j%10))
+	}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		idx := inmem.NewIndex("db0")
+		b.StartTimer()
+
+		for _, key := range keys {
+			name, tags := models.ParseKey(key)
+			if err := idx.CreateSeriesIfNotExists(1, key, []byte(name), tags, &opt, false); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
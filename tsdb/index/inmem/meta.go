@@ -6,6 +6,7 @@ import (
 	"regexp"
 	"sort"
 	"sync"
+	"unsafe"
 
 	"github.com/influxdata/influxdb/models"
 	"github.com/influxdata/influxdb/query"
@@ -37,6 +38,30 @@ type Measurement struct {
 	dirty bool
 }
 
+// memoryStats estimates the memory used by the measurement. It returns the
+// total number of bytes and the number of bytes used by the series of each
+// tag key.
+func (m *Measurement) memoryStats() (int, map[string]int) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	b := int(unsafe.Sizeof(*m)) + len(m.Name) + len(m.name) + len(m.sortedSeriesIDs)*8
+	for name := range m.fieldNames {
+		b += int(unsafe.Sizeof(name)) + len(name)
+	}
+	for _, s := range m.seriesByID {
+		b += 16 + s.bytes()
+	}
+
+	tagKeys := make(map[string]int, len(m.seriesByTagKeyValue))
+	for k, tkv := range m.seriesByTagKeyValue {
+		n := int(unsafe.Sizeof(k)) + len(k) + tkv.bytes()
+		tagKeys[k] = n
+		b += n
+	}
+	return b, tagKeys
+}
+
 // NewMeasurement allocates and initializes a new Measurement.
 func NewMeasurement(database, name string) *Measurement {
 	return &Measurement{
@@ -109,6 +134,20 @@ func (m *Measurement) SeriesKeysByID(ids SeriesIDs) [][]byte {
 	return keys
 }
 
+// SeriesN returns the number of series in this measurement that are not
+// deleted.
+func (m *Measurement) SeriesN() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var n int
+	for _, s := range m.seriesByID {
+		if !s.Deleted() {
+			n++
+		}
+	}
+	return n
+}
+
 // SeriesKeys returns the keys of every series in this measurement
 func (m *Measurement) SeriesKeys() [][]byte {
 	m.mu.RLock()
@@ -1135,44 +1174,101 @@ type Series struct {
 	tags        models.Tags
 	ID          uint64
 	measurement *Measurement
-	shardIDs    map[uint64]struct{} // shards that have this series defined
+	shardIDs    []uint64 // sorted IDs of the shards that have this series defined
 	deleted     bool
 }
 
+// bytes estimates the number of bytes of memory used by the series.
+func (s *Series) bytes() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	b := int(unsafe.Sizeof(*s)) + len(s.Key)
+	for _, t := range s.tags {
+		b += int(unsafe.Sizeof(t)) + len(t.Key) + len(t.Value)
+	}
+	return b + cap(s.shardIDs)*8
+}
+
 // NewSeries returns an initialized series struct
 func NewSeries(key []byte, tags models.Tags) *Series {
 	return &Series{
-		Key:      string(key),
-		tags:     tags,
-		shardIDs: make(map[uint64]struct{}),
+		Key:  string(key),
+		tags: tags,
 	}
 }
 
+// cloneTags returns a copy of tags whose keys and values are allocated in a
+// single block. The index holds the tags of every series for as long as the
+// series exists, so keeping them in one allocation instead of one per key and
+// value greatly reduces the number of objects the garbage collector scans.
+func cloneTags(tags models.Tags) models.Tags {
+	if len(tags) == 0 {
+		return nil
+	}
+
+	var n int
+	for _, t := range tags {
+		n += len(t.Key) + len(t.Value)
+	}
+	buf := make([]byte, n)
+
+	other := make(models.Tags, len(tags))
+	for i, t := range tags {
+		n = copy(buf, t.Key)
+		other[i].Key, buf = buf[:n:n], buf[n:]
+
+		n = copy(buf, t.Value)
+		other[i].Value, buf = buf[:n:n], buf[n:]
+	}
+	return other
+}
+
 func (s *Series) AssignShard(shardID uint64) {
 	if s.Assigned(shardID) {
 		return
 	}
 
 	s.mu.Lock()
-	// Skip the existence check under the write lock because we're just storing
-	// and empty struct.
-	s.shardIDs[shardID] = struct{}{}
-	s.mu.Unlock()
+	defer s.mu.Unlock()
+
+	// Shards are usually created in increasing ID order, so the ID is
+	// normally appended.
+	i := s.shardIndex(shardID)
+	if i < len(s.shardIDs) && s.shardIDs[i] == shardID {
+		return
+	}
+	s.shardIDs = append(s.shardIDs, 0)
+	copy(s.shardIDs[i+1:], s.shardIDs[i:])
+	s.shardIDs[i] = shardID
 }
 
 func (s *Series) UnassignShard(shardID uint64) {
 	s.mu.Lock()
-	delete(s.shardIDs, shardID)
-	s.mu.Unlock()
+	defer s.mu.Unlock()
+
+	i := s.shardIndex(shardID)
+	if i < len(s.shardIDs) && s.shardIDs[i] == shardID {
+		s.shardIDs = append(s.shardIDs[:i], s.shardIDs[i+1:]...)
+	}
 }
 
 func (s *Series) Assigned(shardID uint64) bool {
 	s.mu.RLock()
-	_, ok := s.shardIDs[shardID]
+	i := s.shardIndex(shardID)
+	ok := i < len(s.shardIDs) && s.shardIDs[i] == shardID
 	s.mu.RUnlock()
 	return ok
 }
 
+// shardIndex returns the position of shardID in the sorted shard IDs of the
+// series. The caller must hold the lock.
+func (s *Series) shardIndex(shardID uint64) int {
+	if n := len(s.shardIDs); n == 0 || s.shardIDs[n-1] < shardID {
+		return n
+	}
+	return sort.Search(len(s.shardIDs), func(i int) bool { return s.shardIDs[i] >= shardID })
+}
+
 func (s *Series) ShardN() int {
 	s.mu.RLock()
 	n := len(s.shardIDs)
@@ -1210,7 +1306,7 @@ func (s *Series) Tags() models.Tags {
 func (s *Series) CopyTags() {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	s.tags = s.tags.Clone()
+	s.tags = cloneTags(s.tags)
 }
 
 // GetTagString returns a tag value under lock.
@@ -1244,6 +1340,21 @@ type TagKeyValue struct {
 	valueIDs map[string]SeriesIDs
 }
 
+// bytes estimates the number of bytes of memory used by the TagKeyValue.
+func (t *TagKeyValue) bytes() int {
+	if t == nil {
+		return 0
+	}
+
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	b := int(unsafe.Sizeof(*t))
+	for v, ids := range t.valueIDs {
+		b += int(unsafe.Sizeof(v)) + len(v) + int(unsafe.Sizeof(ids)) + len(ids)*8
+	}
+	return b
+}
+
 // NewTagKeyValue initialises a new TagKeyValue.
 func NewTagKeyValue() *TagKeyValue {
 	return &TagKeyValue{valueIDs: make(map[string]SeriesIDs)}
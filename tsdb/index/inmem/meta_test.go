@@ -145,6 +145,24 @@ func TestMeasurement_TagsSet_Deadlock(t *testing.T) {
 	}
 }
 
+func TestSeries_AssignShard(t *testing.T) {
+	s := inmem.NewSeries([]byte("cpu,host=foo"), models.Tags{models.NewTag([]byte("host"), []byte("foo"))})
+	for _, id := range []uint64{3, 1, 2, 3, 5} {
+		s.AssignShard(id)
+	}
+	if got, exp := s.ShardN(), 4; got != exp {
+		t.Fatalf("shard count mismatch: got %v, exp %v", got, exp)
+	}
+
+	s.UnassignShard(2)
+	s.UnassignShard(4)
+	for id, exp := range map[uint64]bool{1: true, 2: false, 3: true, 4: false, 5: true} {
+		if got := s.Assigned(id); got != exp {
+			t.Fatalf("shard %d assigned mismatch: got %v, exp %v", id, got, exp)
+		}
+	}
+}
+
 func BenchmarkMeasurement_SeriesIDForExp_EQRegex(b *testing.B) {
 	m := inmem.NewMeasurement("foo", "cpu")
 	for i := 0; i < 100000; i++ {
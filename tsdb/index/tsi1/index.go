@@ -732,6 +732,25 @@ func (i *Index) TagKeyCardinality(name, key []byte) int {
 	return 0
 }
 
+// MeasurementSeriesN returns the number of non-tombstoned series of a
+// measurement in the index. Like SeriesN, the count cannot be combined with
+// other shards' counts without counting shared series more than once.
+func (i *Index) MeasurementSeriesN(name []byte) int {
+	fs := i.RetainFileSet()
+	defer fs.Release()
+
+	itr := fs.MeasurementSeriesIterator(name)
+	if itr == nil {
+		return 0
+	}
+
+	var n int
+	for e := itr.Next(); e != nil; e = itr.Next() {
+		n++
+	}
+	return n
+}
+
 // MeasurementSeriesKeysByExpr returns a list of series keys matching expr.
 func (i *Index) MeasurementSeriesKeysByExpr(name []byte, expr influxql.Expr) ([][]byte, error) {
 	fs := i.RetainFileSet()
@@ -0,0 +1,187 @@
+package tsdb
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/pkg/limiter"
+)
+
+// quotaUsageTTL is how long the usage of a database is reused when enforcing
+// its quota. Counting series merges the sketches of every shard of the
+// database, which is too expensive to do for every write, so a database can
+// exceed its storage and series quotas by the writes of one interval.
+const quotaUsageTTL = 10 * time.Second
+
+// Quota limits the resources a database can use on the store. A zero limit is
+// unlimited.
+type Quota struct {
+	MaxBytes           int64
+	MaxSeries          int64
+	MaxWritesPerSecond int64 // points per second
+}
+
+// DatabaseUsage is the resources a database uses on the store.
+type DatabaseUsage struct {
+	Bytes  int64 `json:"bytes"`
+	Series int64 `json:"series"`
+}
+
+// QuotaExceededError is returned when a write exceeds the quota of a database.
+// For the writes per second quota, Usage is the number of points written.
+type QuotaExceededError struct {
+	Database string
+	Resource string
+	Usage    int64
+	Limit    int64
+}
+
+func (e *QuotaExceededError) Error() string {
+	return fmt.Sprintf("%s quota exceeded for database %s: (%d/%d)", e.Resource, e.Database, e.Usage, e.Limit)
+}
+
+// ErrorCode returns the code of the error for clients.
+func (e *QuotaExceededError) ErrorCode() influxdb.ErrorCode { return influxdb.ErrorCodeQuotaExceeded }
+
+// quotas tracks the usage of databases for quota enforcement.
+type quotas struct {
+	mu      sync.Mutex
+	usage   map[string]cachedUsage
+	refresh map[string]*usageRefresh
+	rates   map[string]*quotaRate
+}
+
+type cachedUsage struct {
+	DatabaseUsage
+	at time.Time
+}
+
+// usageRefresh is a computation of the usage of a database in progress.
+type usageRefresh struct {
+	done  chan struct{}
+	usage DatabaseUsage
+	err   error
+}
+
+type quotaRate struct {
+	limit int64
+	rate  *limiter.Rate
+}
+
+// Usage returns the disk space and the estimated number of series used by a
+// database.
+func (s *Store) Usage(database string) (DatabaseUsage, error) {
+	var u DatabaseUsage
+
+	s.mu.RLock()
+	shards := s.filterShards(byDatabase(database))
+	s.mu.RUnlock()
+
+	for _, sh := range shards {
+		sz, err := sh.DiskSize()
+		if err != nil {
+			return u, err
+		}
+		u.Bytes += sz
+	}
+
+	if len(shards) > 0 {
+		n, err := s.SeriesCardinality(database)
+		if err != nil {
+			return u, err
+		}
+		u.Series = n
+	}
+	return u, nil
+}
+
+// EnforceQuota returns a *QuotaExceededError if writing n points to database
+// exceeds q. Writes are refused once the database uses as much disk space or
+// as many series as q allows, or when the points written in the last second
+// exceed its rate.
+func (s *Store) EnforceQuota(database string, q Quota, n int) error {
+	if q.MaxBytes > 0 || q.MaxSeries > 0 {
+		u, err := s.cachedUsage(database)
+		if err != nil {
+			return err
+		}
+		if q.MaxBytes > 0 && u.Bytes >= q.MaxBytes {
+			return &QuotaExceededError{Database: database, Resource: "bytes", Usage: u.Bytes, Limit: q.MaxBytes}
+		} else if q.MaxSeries > 0 && u.Series >= q.MaxSeries {
+			return &QuotaExceededError{Database: database, Resource: "series", Usage: u.Series, Limit: q.MaxSeries}
+		}
+	}
+
+	if q.MaxWritesPerSecond > 0 && !s.quotaRate(database, q.MaxWritesPerSecond).AllowN(n) {
+		return &QuotaExceededError{Database: database, Resource: "writes per second", Usage: int64(n), Limit: q.MaxWritesPerSecond}
+	}
+	return nil
+}
+
+// cachedUsage returns the usage of database computed in the last
+// quotaUsageTTL. Only one write refreshes an expired usage at a time; the
+// others keep using the expired usage, or wait for the refresh if there is
+// none yet.
+func (s *Store) cachedUsage(database string) (DatabaseUsage, error) {
+	s.quotas.mu.Lock()
+	cached, ok := s.quotas.usage[database]
+	if ok && time.Since(cached.at) < quotaUsageTTL {
+		s.quotas.mu.Unlock()
+		return cached.DatabaseUsage, nil
+	}
+	if r := s.quotas.refresh[database]; r != nil {
+		s.quotas.mu.Unlock()
+		if ok {
+			return cached.DatabaseUsage, nil
+		}
+		<-r.done
+		return r.usage, r.err
+	}
+	r := &usageRefresh{done: make(chan struct{})}
+	if s.quotas.refresh == nil {
+		s.quotas.refresh = make(map[string]*usageRefresh)
+	}
+	s.quotas.refresh[database] = r
+	s.quotas.mu.Unlock()
+
+	r.usage, r.err = s.Usage(database)
+
+	s.quotas.mu.Lock()
+	delete(s.quotas.refresh, database)
+	if r.err == nil {
+		if s.quotas.usage == nil {
+			s.quotas.usage = make(map[string]cachedUsage)
+		}
+		s.quotas.usage[database] = cachedUsage{DatabaseUsage: r.usage, at: time.Now()}
+	}
+	s.quotas.mu.Unlock()
+	close(r.done)
+	return r.usage, r.err
+}
+
+// quotaRate returns the limiter of the writes to database. The limiter is
+// replaced when the limit of the quota changes.
+func (s *Store) quotaRate(database string, limit int64) *limiter.Rate {
+	s.quotas.mu.Lock()
+	defer s.quotas.mu.Unlock()
+
+	if r, ok := s.quotas.rates[database]; ok && r.limit == limit {
+		return r.rate
+	}
+	if s.quotas.rates == nil {
+		s.quotas.rates = make(map[string]*quotaRate)
+	}
+	r := &quotaRate{limit: limit, rate: limiter.NewRate(int(limit), 0)}
+	s.quotas.rates[database] = r
+	return r.rate
+}
+
+// resetQuota discards the tracked usage of database.
+func (s *Store) resetQuota(database string) {
+	s.quotas.mu.Lock()
+	delete(s.quotas.usage, database)
+	delete(s.quotas.rates, database)
+	s.quotas.mu.Unlock()
+}
@@ -0,0 +1,54 @@
+package tsdb_test
+
+import (
+	"testing"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/tsdb"
+)
+
+func TestStore_EnforceQuota(t *testing.T) {
+	test := func(index string) {
+		s := MustOpenStore(index)
+		defer s.Close()
+
+		s.MustCreateShardWithData("db0", "rp0", 0,
+			`cpu,host=serverA value=1 0`,
+			`cpu,host=serverB value=2 10`,
+		)
+
+		u, err := s.Usage("db0")
+		if err != nil {
+			t.Fatal(err)
+		} else if u.Series != 2 {
+			t.Fatalf("unexpected series: %d", u.Series)
+		}
+
+		if err := s.EnforceQuota("db0", tsdb.Quota{MaxSeries: 3}, 1); err != nil {
+			t.Fatal(err)
+		}
+		err = s.EnforceQuota("db0", tsdb.Quota{MaxSeries: 2}, 1)
+		if e, ok := err.(*tsdb.QuotaExceededError); !ok || e.Resource != "series" || e.Usage != 2 {
+			t.Fatalf("unexpected error: %v", err)
+		} else if influxdb.ErrorCodeOf(err) != influxdb.ErrorCodeQuotaExceeded {
+			t.Fatalf("unexpected error code: %s", influxdb.ErrorCodeOf(err))
+		}
+
+		// Writes beyond the rate of the quota are refused.
+		q := tsdb.Quota{MaxWritesPerSecond: 10}
+		if err := s.EnforceQuota("db0", q, 10); err != nil {
+			t.Fatal(err)
+		} else if err := s.EnforceQuota("db0", q, 5); err == nil {
+			t.Fatal("expected writes per second quota to be exceeded")
+		}
+
+		// Other databases are not limited by db0's writes.
+		if err := s.EnforceQuota("db1", q, 10); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, index := range tsdb.RegisteredIndexes() {
+		t.Run(index, func(t *testing.T) { test(index) })
+	}
+}
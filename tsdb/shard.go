@@ -126,6 +126,8 @@ type Shard struct {
 	closing chan struct{}
 	enabled bool
 
+	queryLimits shardQueryLimits
+
 	// expvar-based stats.
 	stats       *ShardStatistics
 	defaultTags models.StatisticTags
@@ -427,6 +429,33 @@ func (s *Shard) IsIdle() bool {
 	return engine.IsIdle()
 }
 
+// Tier moves the data files of the shard to the object store of the engine
+// options and returns the number of files moved. Only the index of each file
+// is kept on disk.
+func (s *Shard) Tier() (int, error) {
+	engine, err := s.engine()
+	if err != nil {
+		return 0, err
+	}
+	return engine.Tier(objectPrefix(s.database, s.retentionPolicy, s.id))
+}
+
+// Clone creates a copy of the data and index files of the shard at path for
+// the shard id of database and retention policy rp.
+func (s *Shard) Clone(path, database, rp string, id uint64) error {
+	engine, err := s.engine()
+	if err != nil {
+		return err
+	}
+	return engine.Clone(path, objectPrefix(database, rp, id))
+}
+
+// objectPrefix returns the prefix of the keys of the objects of a shard's
+// tiered files.
+func objectPrefix(database, rp string, id uint64) string {
+	return fmt.Sprintf("%s/%s/%d", database, rp, id)
+}
+
 func (s *Shard) Free() error {
 	engine, err := s.engine()
 	if err != nil {
@@ -519,9 +548,9 @@ func (s *Shard) validateSeriesAndFields(points []models.Point) ([]models.Point,
 	)
 
 	// Create all series against the index in bulk.
-	keys := make([][]byte, len(points))
-	names := make([][]byte, len(points))
-	tagsSlice := make([]models.Tags, len(points))
+	list := getSeriesList(len(points))
+	defer putSeriesList(list)
+	keys, names, tagsSlice := list.keys, list.names, list.tagsSlice
 
 	// Drop any series w/ a "time" tag, these are illegal
 	var j int
@@ -663,6 +692,41 @@ func (s *Shard) validateSeriesAndFields(points []models.Point) ([]models.Point,
 	return points, fieldsToCreate, err
 }
 
+// seriesList holds the keys, names and tags of the series of a write while
+// they are created in the index.
+type seriesList struct {
+	keys      [][]byte
+	names     [][]byte
+	tagsSlice []models.Tags
+}
+
+// seriesListPool reuses seriesLists between writes. The index copies what it
+// keeps, so the slices are garbage as soon as the series are created.
+var seriesListPool sync.Pool
+
+// getSeriesList returns a seriesList with slices of length n.
+func getSeriesList(n int) *seriesList {
+	l, _ := seriesListPool.Get().(*seriesList)
+	if l == nil || cap(l.keys) < n {
+		return &seriesList{
+			keys:      make([][]byte, n),
+			names:     make([][]byte, n),
+			tagsSlice: make([]models.Tags, n),
+		}
+	}
+	l.keys, l.names, l.tagsSlice = l.keys[:n], l.names[:n], l.tagsSlice[:n]
+	return l
+}
+
+// putSeriesList returns l to the pool. The references to the points of the
+// write are cleared so the pool does not keep them alive.
+func putSeriesList(l *seriesList) {
+	for i := range l.keys {
+		l.keys[i], l.names[i], l.tagsSlice[i] = nil, nil, nil
+	}
+	seriesListPool.Put(l)
+}
+
 func (s *Shard) createFieldsAndMeasurements(fieldsToCreate []*FieldCreate) error {
 	if len(fieldsToCreate) == 0 {
 		return nil
@@ -824,15 +888,21 @@ func (s *Shard) CreateIterator(ctx context.Context, m *influxql.Measurement, opt
 		return nil, err
 	}
 
+	var itr query.Iterator
 	switch m.SystemIterator {
 	case "_fieldKeys":
-		return NewFieldKeysIterator(engine, opt)
+		itr, err = NewFieldKeysIterator(engine, opt)
 	case "_series":
-		return s.createSeriesIterator(opt)
+		itr, err = s.createSeriesIterator(opt)
 	case "_tagKeys":
-		return NewTagKeysIterator(engine, opt)
+		itr, err = NewTagKeysIterator(engine, opt)
+	default:
+		itr, err = engine.CreateIterator(ctx, m.Name, opt)
 	}
-	return engine.CreateIterator(ctx, m.Name, opt)
+	if err != nil {
+		return nil, err
+	}
+	return s.limitIterator(itr, opt)
 }
 
 func (s *Shard) CreateCursor(ctx context.Context, r *CursorRequest) (Cursor, error) {
@@ -1129,6 +1199,16 @@ func (s *Shard) TagKeyCardinality(name, key []byte) int {
 	return engine.TagKeyCardinality(name, key)
 }
 
+// MeasurementSeriesN returns the number of series of a measurement in the
+// index of the shard.
+func (s *Shard) MeasurementSeriesN(name []byte) int {
+	engine, err := s.engine()
+	if err != nil {
+		return 0
+	}
+	return engine.MeasurementSeriesN(name)
+}
+
 // engine safely (under an RLock) returns a reference to the shard's Engine, or
 // an error if the Engine is closed, or the shard is currently disabled.
 //
@@ -0,0 +1,153 @@
+package tsdb
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/influxdata/influxdb/query"
+)
+
+// shardQueryLimits tracks the queries and series cursors open on a shard to
+// enforce the max-concurrent-queries-per-shard and max-cursors-per-shard
+// limits.
+type shardQueryLimits struct {
+	mu sync.Mutex
+
+	// Iterators open for each query, keyed by its interrupt channel. The
+	// iterators of queries without an interrupt channel are counted as
+	// separate queries in anonymous.
+	queries   map[<-chan struct{}]int
+	anonymous int
+
+	cursors int
+}
+
+// acquire registers an iterator of the query identified by interrupt that
+// opens n series cursors on the shard. It returns an error if the query
+// would exceed the limits.
+func (l *shardQueryLimits) acquire(id uint64, interrupt <-chan struct{}, n, maxQueries, maxCursors int) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if maxQueries > 0 && (interrupt == nil || l.queries[interrupt] == 0) {
+		if queriesN := len(l.queries) + l.anonymous; queriesN >= maxQueries {
+			return fmt.Errorf("max-concurrent-queries-per-shard limit exceeded for shard %d: (%d/%d)", id, queriesN+1, maxQueries)
+		}
+	}
+	if maxCursors > 0 && l.cursors+n > maxCursors {
+		return fmt.Errorf("max-cursors-per-shard limit exceeded for shard %d: (%d/%d)", id, l.cursors+n, maxCursors)
+	}
+
+	if interrupt == nil {
+		l.anonymous++
+	} else {
+		if l.queries == nil {
+			l.queries = make(map[<-chan struct{}]int)
+		}
+		l.queries[interrupt]++
+	}
+	l.cursors += n
+	return nil
+}
+
+// release unregisters an iterator registered with acquire.
+func (l *shardQueryLimits) release(interrupt <-chan struct{}, n int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if interrupt == nil {
+		l.anonymous--
+	} else if l.queries[interrupt]--; l.queries[interrupt] == 0 {
+		delete(l.queries, interrupt)
+	}
+	l.cursors -= n
+}
+
+// limitIterator registers itr, created on sh for a query with options opt,
+// with the query limits of sh. The returned iterator releases itr from the
+// limits when it is closed. If the limits are exceeded, itr is closed and an
+// error is returned.
+func (s *Shard) limitIterator(itr query.Iterator, opt query.IteratorOptions) (query.Iterator, error) {
+	maxQueries, maxCursors := s.options.Config.MaxConcurrentQueriesPerShard, s.options.Config.MaxCursorsPerShard
+	if itr == nil || (maxQueries == 0 && maxCursors == 0) {
+		return itr, nil
+	}
+
+	n := itr.Stats().SeriesN
+	if err := s.queryLimits.acquire(s.id, opt.InterruptCh, n, maxQueries, maxCursors); err != nil {
+		itr.Close()
+		return nil, err
+	}
+
+	var once sync.Once
+	release := func() {
+		once.Do(func() { s.queryLimits.release(opt.InterruptCh, n) })
+	}
+
+	switch itr := itr.(type) {
+	case query.FloatIterator:
+		return &floatReleaseIterator{FloatIterator: itr, release: release}, nil
+	case query.IntegerIterator:
+		return &integerReleaseIterator{IntegerIterator: itr, release: release}, nil
+	case query.UnsignedIterator:
+		return &unsignedReleaseIterator{UnsignedIterator: itr, release: release}, nil
+	case query.StringIterator:
+		return &stringReleaseIterator{StringIterator: itr, release: release}, nil
+	case query.BooleanIterator:
+		return &booleanReleaseIterator{BooleanIterator: itr, release: release}, nil
+	default:
+		release()
+		itr.Close()
+		return nil, fmt.Errorf("unsupported iterator type for shard limits: %T", itr)
+	}
+}
+
+type floatReleaseIterator struct {
+	query.FloatIterator
+	release func()
+}
+
+func (itr *floatReleaseIterator) Close() error {
+	defer itr.release()
+	return itr.FloatIterator.Close()
+}
+
+type integerReleaseIterator struct {
+	query.IntegerIterator
+	release func()
+}
+
+func (itr *integerReleaseIterator) Close() error {
+	defer itr.release()
+	return itr.IntegerIterator.Close()
+}
+
+type unsignedReleaseIterator struct {
+	query.UnsignedIterator
+	release func()
+}
+
+func (itr *unsignedReleaseIterator) Close() error {
+	defer itr.release()
+	return itr.UnsignedIterator.Close()
+}
+
+type stringReleaseIterator struct {
+	query.StringIterator
+	release func()
+}
+
+func (itr *stringReleaseIterator) Close() error {
+	defer itr.release()
+	return itr.StringIterator.Close()
+}
+
+type booleanReleaseIterator struct {
+	query.BooleanIterator
+	release func()
+}
+
+func (itr *booleanReleaseIterator) Close() error {
+	defer itr.release()
+	return itr.BooleanIterator.Close()
+}
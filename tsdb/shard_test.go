@@ -558,6 +558,86 @@ func TestShard_WritePoints_FieldConflictConcurrentQuery(t *testing.T) {
 	wg.Wait()
 }
 
+// Ensure the queries and cursors open on a shard are limited.
+func TestShard_CreateIterator_Limits(t *testing.T) {
+	test := func(index string) {
+		dir, err := ioutil.TempDir("", "influxdb-tsdb-")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		opt := tsdb.NewEngineOptions()
+		opt.IndexVersion = index
+		opt.Config.WALDir = filepath.Join(dir, "wal")
+		opt.Config.MaxConcurrentQueriesPerShard = 1
+		opt.Config.MaxCursorsPerShard = 3
+		if index == "inmem" {
+			opt.InmemIndex = inmem.NewIndex(path.Base(dir))
+		}
+
+		sh := &Shard{
+			Shard: tsdb.NewShard(1, filepath.Join(dir, "data", "db0", "rp0", "1"), filepath.Join(dir, "wal", "db0", "rp0", "1"), opt),
+			path:  dir,
+		}
+		defer sh.Close()
+		if err := sh.Open(); err != nil {
+			t.Fatal(err)
+		}
+
+		sh.MustWritePointsString(`
+cpu,host=serverA value=1 0
+cpu,host=serverB value=2 0
+`)
+
+		createIterator := func(interrupt chan struct{}, cond string) (query.Iterator, error) {
+			opt := query.IteratorOptions{
+				Expr:        influxql.MustParseExpr(`value`),
+				Dimensions:  []string{"host"},
+				Ascending:   true,
+				StartTime:   influxql.MinTime,
+				EndTime:     influxql.MaxTime,
+				InterruptCh: interrupt,
+			}
+			if cond != "" {
+				opt.Condition = influxql.MustParseExpr(cond)
+			}
+			return sh.CreateIterator(context.Background(), &influxql.Measurement{Name: "cpu"}, opt)
+		}
+
+		q1, q2 := make(chan struct{}), make(chan struct{})
+		itr1, err := createIterator(q1, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// A second query is refused while the first is running.
+		if _, err := createIterator(q2, ""); err == nil || !strings.Contains(err.Error(), "max-concurrent-queries-per-shard") {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		// The first query can open more iterators up to the cursor limit.
+		itr2, err := createIterator(q1, `host = 'serverA'`)
+		if err != nil {
+			t.Fatal(err)
+		} else if _, err := createIterator(q1, ""); err == nil || !strings.Contains(err.Error(), "max-cursors-per-shard") {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		// Closing the iterators of the first query releases the shard.
+		itr1.Close()
+		itr2.Close()
+		itr3, err := createIterator(q2, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		itr3.Close()
+	}
+
+	for _, index := range tsdb.RegisteredIndexes() {
+		t.Run(index, func(t *testing.T) { test(index) })
+	}
+}
+
 // Ensures that when a shard is closed, it removes any series meta-data
 // from the index.
 func TestShard_Close_RemoveIndex(t *testing.T) {
@@ -2,6 +2,7 @@ package tsdb // import "github.com/influxdata/influxdb/tsdb"
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"io"
@@ -51,6 +52,12 @@ type Store struct {
 	// shards is a map of shard IDs to the associated Shard.
 	shards map[uint64]*Shard
 
+	// quotas tracks the usage of databases with a quota.
+	quotas quotas
+
+	// trashMu serializes changes to the trash.
+	trashMu sync.Mutex
+
 	EngineOptions EngineOptions
 
 	baseLogger zap.Logger
@@ -120,6 +127,38 @@ func (s *Store) Statistics(tags map[string]string) []models.Statistic {
 	for _, shard := range shards {
 		statistics = append(statistics, shard.Statistics(tags)...)
 	}
+
+	if s.EngineOptions.Config.IndexMemoryStatsEnabled {
+		statistics = append(statistics, s.indexMemoryStatistics(tags)...)
+	}
+
+	// The block cache is shared by the shards so it is reported once.
+	if c, ok := s.EngineOptions.BlockCache.(interface {
+		Statistics(tags map[string]string) []models.Statistic
+	}); ok {
+		statistics = append(statistics, c.Statistics(tags)...)
+	}
+	return statistics
+}
+
+// indexMemoryStatistics returns the memory statistics of each database index
+// that supports reporting them.
+func (s *Store) indexMemoryStatistics(tags map[string]string) []models.Statistic {
+	s.mu.RLock()
+	indexes := make([]interface{}, 0, len(s.indexes))
+	for _, idx := range s.indexes {
+		indexes = append(indexes, idx)
+	}
+	s.mu.RUnlock()
+
+	var statistics []models.Statistic
+	for _, idx := range indexes {
+		if idx, ok := idx.(interface {
+			MemoryStatistics(tags map[string]string) []models.Statistic
+		}); ok {
+			statistics = append(statistics, idx.MemoryStatistics(tags)...)
+		}
+	}
 	return statistics
 }
 
@@ -147,7 +186,11 @@ func (s *Store) Open() error {
 		return err
 	}
 
-	if err := s.loadShards(); err != nil {
+	if err := s.checkTrashDir(); err != nil {
+		return err
+	} else if err := s.loadShards(); err != nil {
+		return err
+	} else if err := s.resumeDeleteSeries(); err != nil {
 		return err
 	}
 
@@ -181,6 +224,14 @@ func (s *Store) loadShards() error {
 
 	s.EngineOptions.CompactionLimiter = limiter.NewFixed(lim)
 
+	if n := int(s.EngineOptions.Config.BackgroundThroughput); n > 0 && s.EngineOptions.BackgroundRate == nil {
+		s.EngineOptions.BackgroundRate = limiter.NewRate(n, 0)
+	}
+
+	if n := int64(s.EngineOptions.Config.BlockCacheMaxMemorySize); n > 0 && NewBlockCache != nil {
+		s.EngineOptions.BlockCache = NewBlockCache(n)
+	}
+
 	t := limiter.NewFixed(runtime.GOMAXPROCS(0))
 	resC := make(chan *res)
 	var n int
@@ -210,7 +261,9 @@ func (s *Store) loadShards() error {
 		}
 
 		for _, rp := range rpDirs {
-			if !rp.IsDir() {
+			if isDeleteSeriesLog(rp.Name()) {
+				continue
+			} else if !rp.IsDir() {
 				s.Logger.Info(fmt.Sprintf("Skipping retention policy dir: %s. Not a directory", rp.Name()))
 				continue
 			}
@@ -326,6 +379,10 @@ func (s *Store) createIndexIfNotExists(name string) (interface{}, error) {
 		return idx, nil
 	}
 
+	if NewInmemIndex == nil {
+		return nil, errors.New("inmem index is not registered")
+	}
+
 	idx, err := NewInmemIndex(name)
 	if err != nil {
 		return nil, err
@@ -389,17 +446,6 @@ func (s *Store) CreateShard(database, retentionPolicy string, shardID uint64, en
 		return nil
 	}
 
-	// Create the db and retention policy directories if they don't exist.
-	if err := os.MkdirAll(filepath.Join(s.path, database, retentionPolicy), 0700); err != nil {
-		return err
-	}
-
-	// Create the WAL directory.
-	walPath := filepath.Join(s.EngineOptions.Config.WALDir, database, retentionPolicy, fmt.Sprintf("%d", shardID))
-	if err := os.MkdirAll(walPath, 0700); err != nil {
-		return err
-	}
-
 	// Retrieve shared index, if needed.
 	idx, err := s.createIndexIfNotExists(database)
 	if err != nil {
@@ -410,6 +456,26 @@ func (s *Store) CreateShard(database, retentionPolicy string, shardID uint64, en
 	opt := s.EngineOptions
 	opt.InmemIndex = idx
 
+	// Shards of the inmem engine have no files, so they cannot use a disk
+	// based index either.
+	if s.isInmemDatabase(database) {
+		opt.EngineVersion = InmemEngine
+		opt.IndexVersion = "inmem"
+	}
+
+	walPath := filepath.Join(s.EngineOptions.Config.WALDir, database, retentionPolicy, fmt.Sprintf("%d", shardID))
+	if opt.EngineVersion != InmemEngine {
+		// Create the db and retention policy directories if they don't exist.
+		if err := os.MkdirAll(filepath.Join(s.path, database, retentionPolicy), 0700); err != nil {
+			return err
+		}
+
+		// Create the WAL directory.
+		if err := os.MkdirAll(walPath, 0700); err != nil {
+			return err
+		}
+	}
+
 	path := filepath.Join(s.path, database, retentionPolicy, strconv.FormatUint(shardID, 10))
 	shard := NewShard(shardID, path, walPath, opt)
 	shard.WithLogger(s.baseLogger)
@@ -425,6 +491,57 @@ func (s *Store) CreateShard(database, retentionPolicy string, shardID uint64, en
 	return nil
 }
 
+// isInmemDatabase returns true if the shards of database use the inmem engine.
+func (s *Store) isInmemDatabase(database string) bool {
+	if s.EngineOptions.EngineVersion == InmemEngine {
+		return true
+	}
+	for _, name := range s.EngineOptions.Config.InmemDatabases {
+		if name == database {
+			return true
+		}
+	}
+	return false
+}
+
+// CloneShard creates the shard dstID of database and retention policy rp from
+// the data and index files of the shard srcID. Files are hard-linked, so the
+// clone shares disk space with the source until either shard compacts.
+func (s *Store) CloneShard(srcID uint64, database, rp string, dstID uint64) error {
+	src := s.Shard(srcID)
+	if src == nil {
+		return ErrShardNotFound
+	} else if s.Shard(dstID) != nil {
+		return fmt.Errorf("shard %d already exists", dstID)
+	} else if s.isInmemDatabase(database) {
+		return fmt.Errorf("cannot clone shard into inmem database %s", database)
+	}
+
+	if err := os.MkdirAll(filepath.Join(s.path, database, rp), 0700); err != nil {
+		return err
+	}
+	path := filepath.Join(s.path, database, rp, strconv.FormatUint(dstID, 10))
+	if _, err := os.Stat(path); err == nil {
+		return fmt.Errorf("shard path already exists: %s", path)
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+
+	if err := src.Clone(path, database, rp, dstID); err != nil {
+		os.RemoveAll(path)
+		return err
+	} else if err := os.Chmod(path, 0700); err != nil {
+		os.RemoveAll(path)
+		return err
+	}
+
+	if err := s.CreateShard(database, rp, dstID, true); err != nil {
+		os.RemoveAll(path)
+		return err
+	}
+	return nil
+}
+
 // CreateShardSnapShot will create a hard link to the underlying shard and return a path.
 // The caller is responsible for cleaning up (removing) the file path returned.
 func (s *Store) CreateShardSnapshot(id uint64) (string, error) {
@@ -479,6 +596,12 @@ func (s *Store) DeleteShard(shardID uint64) error {
 
 // DeleteDatabase will close all shards associated with a database and remove the directory and files from disk.
 func (s *Store) DeleteDatabase(name string) error {
+	return s.deleteDatabase(name, os.RemoveAll)
+}
+
+// deleteDatabase closes the shards of a database and calls remove with the
+// directory of the database before removing its WAL directory.
+func (s *Store) deleteDatabase(name string, remove func(dbPath string) error) error {
 	s.mu.RLock()
 	if _, ok := s.databases[name]; !ok {
 		s.mu.RUnlock()
@@ -508,7 +631,7 @@ func (s *Store) DeleteDatabase(name string) error {
 		return fmt.Errorf("invalid database directory location for database '%s': %s", name, dbPath)
 	}
 
-	if err := os.RemoveAll(dbPath); err != nil {
+	if err := remove(dbPath); err != nil {
 		return err
 	}
 	if err := os.RemoveAll(filepath.Join(s.EngineOptions.Config.WALDir, name)); err != nil {
@@ -527,6 +650,8 @@ func (s *Store) DeleteDatabase(name string) error {
 	delete(s.indexes, name)
 	s.mu.Unlock()
 
+	s.resetQuota(name)
+
 	return nil
 }
 
@@ -721,6 +846,16 @@ func (s *Store) DiskSize() (int64, error) {
 	return size, nil
 }
 
+// ShardDiskSize returns the size on disk of a shard in bytes, or 0 if the
+// shard is not stored locally.
+func (s *Store) ShardDiskSize(id uint64) (int64, error) {
+	sh := s.Shard(id)
+	if sh == nil {
+		return 0, nil
+	}
+	return sh.DiskSize()
+}
+
 func (s *Store) estimateCardinality(dbName string, getSketches func(*Shard) (estimator.Sketch, estimator.Sketch, error)) (int64, error) {
 	var (
 		ss estimator.Sketch // Sketch estimating number of items.
@@ -774,6 +909,132 @@ func (s *Store) MeasurementsCardinality(database string) (int64, error) {
 	})
 }
 
+// MeasurementCardinality holds the number of series of a measurement and the
+// number of values of its tag keys.
+type MeasurementCardinality struct {
+	Name    string
+	SeriesN int
+	TagKeys []TagKeyCardinality
+}
+
+// TagKeyCardinality holds the number of distinct values of a tag key.
+type TagKeyCardinality struct {
+	Key     string
+	ValuesN int
+}
+
+// MeasurementCardinalities returns the n measurements of a database with the
+// most series, ordered by descending series count. Each measurement includes
+// its n tag keys with the most distinct values. A value of n less than 1
+// returns every measurement and tag key.
+//
+// The counts are computed from the index and do not read any data. With the
+// tsi1 index each shard counts its own series, so a series written to several
+// shards of the database is counted once per shard.
+func (s *Store) MeasurementCardinalities(database string, n int) ([]MeasurementCardinality, error) {
+	s.mu.RLock()
+	shards := s.filterShards(byDatabase(database))
+	s.mu.RUnlock()
+
+	// The inmem index is shared by all shards of a database so the first
+	// shard holds the whole index.
+	if len(shards) > 0 && shards[0].IndexType() == "inmem" {
+		shards = shards[:1]
+	}
+
+	// Add up the series counts the index of each shard keeps per measurement.
+	series := make(map[string]int)
+	for _, sh := range shards {
+		if err := sh.ForEachMeasurementName(func(name []byte) error {
+			series[string(name)] += sh.MeasurementSeriesN(name)
+			return nil
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	a := make([]MeasurementCardinality, 0, len(series))
+	for name, n := range series {
+		a = append(a, MeasurementCardinality{Name: name, SeriesN: n})
+	}
+	sort.Slice(a, func(i, j int) bool {
+		if a[i].SeriesN != a[j].SeriesN {
+			return a[i].SeriesN > a[j].SeriesN
+		}
+		return a[i].Name < a[j].Name
+	})
+	if n > 0 && len(a) > n {
+		a = a[:n]
+	}
+
+	for i := range a {
+		tagKeys, err := measurementTagKeyCardinalities(shards, []byte(a[i].Name))
+		if err != nil {
+			return nil, err
+		}
+		if n > 0 && len(tagKeys) > n {
+			tagKeys = tagKeys[:n]
+		}
+		a[i].TagKeys = tagKeys
+	}
+	return a, nil
+}
+
+// measurementTagKeyCardinalities returns the number of distinct values of
+// each tag key of a measurement across shards, ordered by descending count.
+func measurementTagKeyCardinalities(shards []*Shard, name []byte) ([]TagKeyCardinality, error) {
+	var a []TagKeyCardinality
+	if len(shards) == 1 {
+		sh := shards[0]
+		if err := sh.ForEachMeasurementTagKey(name, func(key []byte) error {
+			a = append(a, TagKeyCardinality{Key: string(key), ValuesN: sh.TagKeyCardinality(name, key)})
+			return nil
+		}); err != nil {
+			return nil, err
+		}
+	} else {
+		values := make(map[string]map[string]struct{})
+		for _, sh := range shards {
+			var keys []string
+			if err := sh.ForEachMeasurementTagKey(name, func(key []byte) error {
+				keys = append(keys, string(key))
+				return nil
+			}); err != nil {
+				return nil, err
+			}
+
+			vals, err := sh.MeasurementTagKeyValuesByExpr(nil, name, keys, nil, true)
+			if err != nil {
+				return nil, err
+			}
+			for i, key := range keys {
+				set := values[key]
+				if set == nil {
+					set = make(map[string]struct{})
+					values[key] = set
+				}
+				if i < len(vals) {
+					for _, v := range vals[i] {
+						set[v] = struct{}{}
+					}
+				}
+			}
+		}
+
+		for key, set := range values {
+			a = append(a, TagKeyCardinality{Key: key, ValuesN: len(set)})
+		}
+	}
+
+	sort.Slice(a, func(i, j int) bool {
+		if a[i].ValuesN != a[j].ValuesN {
+			return a[i].ValuesN > a[j].ValuesN
+		}
+		return a[i].Key < a[j].Key
+	})
+	return a, nil
+}
+
 // BackupShard will get the shard and have the engine backup since the passed in
 // time to the writer.
 func (s *Store) BackupShard(id uint64, since time.Time, w io.Writer) error {
@@ -837,85 +1098,176 @@ func (s *Store) ShardRelativePath(id uint64) (string, error) {
 // DeleteSeries loops through the local shards and deletes the series data for
 // the passed in series keys.
 func (s *Store) DeleteSeries(database string, sources []influxql.Source, condition influxql.Expr) error {
+	return s.DeleteSeriesWithProgress(database, sources, condition, nil, nil)
+}
+
+// DeleteStats is the data a delete would remove from the store.
+type DeleteStats struct {
+	Shards int64 // shards with matching values
+	Series int64 // distinct matching series
+	Values int64 // field values of the matching series in the time range
+}
+
+// DeleteSeriesDryRun returns the data that DeleteSeries would remove for
+// the same arguments without removing it. Every matching value is read, so
+// this is as expensive as querying the data.
+func (s *Store) DeleteSeriesDryRun(database string, sources []influxql.Source, condition influxql.Expr) (DeleteStats, error) {
+	var stats DeleteStats
+
 	// Expand regex expressions in the FROM clause.
 	a, err := s.ExpandSources(sources)
 	if err != nil {
-		return err
+		return stats, err
 	} else if sources != nil && len(sources) != 0 && len(a) == 0 {
-		return nil
+		return stats, nil
 	}
 	sources = a
 
-	// Determine deletion time range.
+	condition, min, max, err := deleteTimeRange(condition)
+	if err != nil {
+		return stats, err
+	}
+
+	s.mu.RLock()
+	shards := s.filterShards(byDatabase(database))
+	s.mu.RUnlock()
+
+	series := make(map[string]struct{})
+	for _, sh := range shards {
+		var values int64
+		if err := forEachSeriesToDelete(sh, sources, condition, func(name string, keys [][]byte) error {
+			for _, key := range keys {
+				series[string(key)] = struct{}{}
+
+				n, err := countValues(sh, name, string(key), min, max)
+				if err != nil {
+					return err
+				}
+				values += n
+			}
+			return nil
+		}); err != nil {
+			return stats, err
+		}
+
+		if values > 0 {
+			stats.Shards++
+			stats.Values += values
+		}
+	}
+	stats.Series = int64(len(series))
+	return stats, nil
+}
+
+// DeleteMeasurementDryRun returns the data that DeleteMeasurement would
+// remove without removing it.
+func (s *Store) DeleteMeasurementDryRun(database, name string) (DeleteStats, error) {
+	return s.DeleteSeriesDryRun(database, []influxql.Source{&influxql.Measurement{Name: name}}, nil)
+}
+
+// deleteTimeRange removes the time range from the condition of a delete and
+// returns the range in nanoseconds.
+func deleteTimeRange(condition influxql.Expr) (influxql.Expr, int64, int64, error) {
 	condition, timeRange, err := influxql.ConditionExpr(condition, nil)
 	if err != nil {
-		return err
+		return nil, 0, 0, err
 	}
 
-	var min, max int64
+	min, max := int64(influxql.MinTime), int64(influxql.MaxTime)
 	if !timeRange.Min.IsZero() {
 		min = timeRange.Min.UnixNano()
-	} else {
-		min = influxql.MinTime
 	}
 	if !timeRange.Max.IsZero() {
 		max = timeRange.Max.UnixNano()
-	} else {
-		max = influxql.MaxTime
 	}
+	return condition, min, max, nil
+}
 
-	s.mu.RLock()
-	shards := s.filterShards(byDatabase(database))
-	s.mu.RUnlock()
-
-	s.mu.RLock()
-	defer s.mu.RUnlock()
+// forEachSeriesToDelete calls fn with the keys of the series in each
+// measurement of sh that match the sources and condition of a delete. All
+// measurements match when there are no sources.
+func forEachSeriesToDelete(sh *Shard, sources []influxql.Source, condition influxql.Expr, fn func(name string, keys [][]byte) error) error {
+	var names []string
+	if len(sources) > 0 {
+		for _, source := range sources {
+			names = append(names, source.(*influxql.Measurement).Name)
+		}
+	} else {
+		if err := sh.ForEachMeasurementName(func(name []byte) error {
+			names = append(names, string(name))
+			return nil
+		}); err != nil {
+			return err
+		}
+	}
+	sort.Strings(names)
 
-	// Limit to 1 delete for each shard since expanding the measurement into the list
-	// of series keys can be very memory intensive if run concurrently.
-	limit := limiter.NewFixed(1)
+	for _, name := range names {
+		keys, err := sh.MeasurementSeriesKeysByExpr([]byte(name), condition)
+		if err != nil {
+			return err
+		} else if len(keys) == 0 {
+			continue
+		}
 
-	return s.walkShards(shards, func(sh *Shard) error {
-		// Determine list of measurements from sources.
-		// Use all measurements if no FROM clause was provided.
-		var names []string
-		if len(sources) > 0 {
-			for _, source := range sources {
-				names = append(names, source.(*influxql.Measurement).Name)
-			}
-		} else {
-			if err := sh.ForEachMeasurementName(func(name []byte) error {
-				names = append(names, string(name))
-				return nil
-			}); err != nil {
-				return err
-			}
+		if err := fn(name, keys); err != nil {
+			return err
 		}
-		sort.Strings(names)
+	}
+	return nil
+}
 
-		limit.Take()
-		defer limit.Release()
+// countValues returns the number of field values of a series in sh between
+// min and max, inclusive.
+func countValues(sh *Shard, name, key string, min, max int64) (int64, error) {
+	mf := sh.MeasurementFields([]byte(name))
+	if mf == nil {
+		return 0, nil
+	}
 
-		// Find matching series keys for each measurement.
-		var keys [][]byte
-		for _, name := range names {
-			a, err := sh.MeasurementSeriesKeysByExpr([]byte(name), condition)
-			if err != nil {
-				return err
-			}
-			keys = append(keys, a...)
+	var n int64
+	for _, field := range mf.FieldKeys() {
+		cur, err := sh.CreateCursor(context.Background(), &CursorRequest{
+			Measurement: name,
+			Series:      key,
+			Field:       field,
+			Ascending:   true,
+			StartTime:   min,
+			EndTime:     max,
+		})
+		if err != nil {
+			return 0, err
+		} else if cur == nil {
+			continue
 		}
 
-		if !bytesutil.IsSorted(keys) {
-			bytesutil.Sort(keys)
+		for {
+			var keys []int64
+			switch cur := cur.(type) {
+			case FloatBatchCursor:
+				keys, _ = cur.Next()
+			case IntegerBatchCursor:
+				keys, _ = cur.Next()
+			case UnsignedBatchCursor:
+				keys, _ = cur.Next()
+			case StringBatchCursor:
+				keys, _ = cur.Next()
+			case BooleanBatchCursor:
+				keys, _ = cur.Next()
+			}
+			if len(keys) == 0 {
+				break
+			}
+			n += int64(len(keys))
 		}
 
-		// Delete all matching keys.
-		if err := sh.DeleteSeriesRange(keys, min, max); err != nil {
-			return err
+		err = cur.Err()
+		cur.Close()
+		if err != nil {
+			return 0, err
 		}
-		return nil
-	})
+	}
+	return n, nil
 }
 
 // ExpandSources expands sources against all local shards.
@@ -1475,6 +1827,10 @@ func (s *Store) monitorShards() {
 			}
 			s.mu.RUnlock()
 		case <-t2.C:
+			if err := s.purgeTrash(); err != nil {
+				s.Logger.Warn("error purging trash:", zap.Error(err))
+			}
+
 			if s.EngineOptions.Config.MaxValuesPerTag == 0 {
 				continue
 			}
@@ -20,6 +20,7 @@ import (
 	"github.com/influxdata/influxdb/models"
 	"github.com/influxdata/influxdb/pkg/deep"
 	"github.com/influxdata/influxdb/query"
+	"github.com/influxdata/influxdb/toml"
 	"github.com/influxdata/influxdb/tsdb"
 	"github.com/influxdata/influxql"
 	"github.com/uber-go/zap"
@@ -492,6 +493,442 @@ func TestStore_BackupRestoreShard(t *testing.T) {
 	}
 }
 
+// Ensure the store can clone a shard into another database.
+func TestStore_CloneShard(t *testing.T) {
+	test := func(index string) {
+		s := MustOpenStore(index)
+		defer s.Close()
+
+		s.MustCreateShardWithData("db0", "rp0", 100,
+			`cpu,host=serverA value=1 0`,
+			`cpu,host=serverB value=2 10`,
+		)
+
+		if err := s.CloneShard(100, "db1", "rp0", 200); err != nil {
+			t.Fatal(err)
+		} else if err := s.CloneShard(100, "db1", "rp0", 200); err == nil {
+			t.Fatal("expected error cloning into existing shard")
+		} else if err := s.CloneShard(300, "db1", "rp0", 400); err != tsdb.ErrShardNotFound {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		// Writes to the clone do not change the original.
+		s.MustWriteToShardString(200, `mem value=1 0`)
+
+		if names, err := s.MeasurementNames("db1", nil); err != nil {
+			t.Fatal(err)
+		} else if got, exp := fmt.Sprintf("%s", names), "[cpu mem]"; got != exp {
+			t.Fatalf("unexpected measurements: got %s, exp %s", got, exp)
+		}
+		if names, err := s.MeasurementNames("db0", nil); err != nil {
+			t.Fatal(err)
+		} else if got, exp := fmt.Sprintf("%s", names), "[cpu]"; got != exp {
+			t.Fatalf("unexpected measurements: got %s, exp %s", got, exp)
+		}
+
+		m := &influxql.Measurement{Name: "cpu"}
+		itr, err := s.Shard(200).CreateIterator(context.Background(), m, query.IteratorOptions{
+			Expr:      influxql.MustParseExpr(`value`),
+			Ascending: true,
+			StartTime: influxql.MinTime,
+			EndTime:   influxql.MaxTime,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer itr.Close()
+		fitr := itr.(query.FloatIterator)
+
+		var values []float64
+		for {
+			p, err := fitr.Next()
+			if err != nil {
+				t.Fatal(err)
+			} else if p == nil {
+				break
+			}
+			values = append(values, p.Value)
+		}
+		if !reflect.DeepEqual(values, []float64{1, 2}) {
+			t.Fatalf("unexpected values: %v", values)
+		}
+	}
+
+	for _, index := range tsdb.RegisteredIndexes() {
+		t.Run(index, func(t *testing.T) { test(index) })
+	}
+}
+
+// Ensure the shards of an inmem database are kept in memory only.
+func TestStore_InmemDatabase(t *testing.T) {
+	s := NewStore()
+	s.EngineOptions.Config.InmemDatabases = []string{"db0"}
+	if err := s.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	s.MustCreateShardWithData("db0", "rp0", 1,
+		`cpu,host=serverA value=1 0`,
+		`cpu,host=serverA value=2 10`,
+		`cpu,host=serverB value=3 20`,
+	)
+	if err := s.DeleteSeries("db0", []influxql.Source{&influxql.Measurement{Name: "cpu"}}, influxql.MustParseExpr(`host = 'serverB'`)); err != nil {
+		t.Fatal(err)
+	}
+
+	m := &influxql.Measurement{Name: "cpu"}
+	itr, err := s.Shard(1).CreateIterator(context.Background(), m, query.IteratorOptions{
+		Expr:      influxql.MustParseExpr(`value`),
+		Ascending: true,
+		StartTime: influxql.MinTime,
+		EndTime:   influxql.MaxTime,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var values []float64
+	fitr := itr.(query.FloatIterator)
+	for {
+		p, err := fitr.Next()
+		if err != nil {
+			t.Fatal(err)
+		} else if p == nil {
+			break
+		}
+		values = append(values, p.Value)
+	}
+	itr.Close()
+	if !reflect.DeepEqual(values, []float64{1, 2}) {
+		t.Fatalf("unexpected values: %v", values)
+	}
+
+	if dirExists(filepath.Join(s.Path(), "db0")) {
+		t.Fatal("expected no database directory")
+	} else if dirExists(filepath.Join(s.EngineOptions.Config.WALDir, "db0")) {
+		t.Fatal("expected no WAL directory")
+	} else if err := s.BackupShard(1, time.Time{}, ioutil.Discard); err == nil {
+		t.Fatal("expected error backing up inmem shard")
+	}
+
+	// The shard is gone once the store is reopened.
+	if err := s.Reopen(); err != nil {
+		t.Fatal(err)
+	} else if s.Shard(1) != nil {
+		t.Fatal("expected shard to be lost on reopen")
+	}
+}
+
+func TestStore_DeleteSeriesDryRun(t *testing.T) {
+	test := func(index string) {
+		s := MustOpenStore(index)
+		defer s.Close()
+
+		s.MustCreateShardWithData("db0", "rp0", 1,
+			`cpu,host=serverA value=1,idle=2 0`,
+			`cpu,host=serverA value=2 10`,
+			`cpu,host=serverB value=3 20`,
+			`mem value=1 0`,
+		)
+		s.MustCreateShardWithData("db0", "rp0", 2, `cpu,host=serverA value=4 100000`)
+
+		for _, tt := range []struct {
+			cond string
+			exp  tsdb.DeleteStats
+		}{
+			{cond: `host = 'serverA'`, exp: tsdb.DeleteStats{Shards: 2, Series: 1, Values: 4}},
+			{cond: `host = 'serverA' AND time < 15s`, exp: tsdb.DeleteStats{Shards: 1, Series: 1, Values: 3}},
+			{cond: `host = 'serverC'`, exp: tsdb.DeleteStats{}},
+		} {
+			stats, err := s.DeleteSeriesDryRun("db0", []influxql.Source{&influxql.Measurement{Name: "cpu"}}, influxql.MustParseExpr(tt.cond))
+			if err != nil {
+				t.Fatal(err)
+			} else if stats != tt.exp {
+				t.Errorf("%s: unexpected stats: got %+v, exp %+v", tt.cond, stats, tt.exp)
+			}
+		}
+
+		if stats, err := s.DeleteMeasurementDryRun("db0", "mem"); err != nil {
+			t.Fatal(err)
+		} else if exp := (tsdb.DeleteStats{Shards: 1, Series: 1, Values: 1}); stats != exp {
+			t.Fatalf("unexpected stats: got %+v, exp %+v", stats, exp)
+		}
+
+		// Nothing was deleted.
+		if names, err := s.MeasurementNames("db0", nil); err != nil {
+			t.Fatal(err)
+		} else if got, exp := fmt.Sprintf("%s", names), "[cpu mem]"; got != exp {
+			t.Fatalf("unexpected measurements: got %s, exp %s", got, exp)
+		}
+		if stats, err := s.DeleteSeriesDryRun("db0", nil, nil); err != nil {
+			t.Fatal(err)
+		} else if exp := (tsdb.DeleteStats{Shards: 2, Series: 3, Values: 6}); stats != exp {
+			t.Fatalf("unexpected stats: got %+v, exp %+v", stats, exp)
+		}
+	}
+
+	for _, index := range tsdb.RegisteredIndexes() {
+		t.Run(index, func(t *testing.T) { test(index) })
+	}
+}
+
+func TestStore_DeleteSeriesWithProgress(t *testing.T) {
+	test := func(index string) {
+		s := MustOpenStore(index)
+		defer s.Close()
+		s.EngineOptions.Config.DeleteSeriesBatchSize = 2
+
+		s.MustCreateShardWithData("db0", "rp0", 1,
+			`cpu,host=serverA value=1 0`,
+			`cpu,host=serverB value=2 0`,
+			`cpu,host=serverC value=3 0`,
+		)
+		s.MustCreateShardWithData("db0", "rp0", 2, `cpu,host=serverA value=4 100000`)
+
+		var progress []string
+		if err := s.DeleteSeriesWithProgress("db0", []influxql.Source{&influxql.Measurement{Name: "cpu"}}, nil, func(shardsDone, shardsTotal int, series int64) {
+			progress = append(progress, fmt.Sprintf("%d/%d:%d", shardsDone, shardsTotal, series))
+		}, nil); err != nil {
+			t.Fatal(err)
+		}
+
+		if got, exp := strings.Join(progress, " "), "0/2:2 0/2:3 1/2:3 1/2:4 2/2:4"; got != exp {
+			t.Fatalf("unexpected progress: got %s, exp %s", got, exp)
+		}
+		if names, err := s.MeasurementNames("db0", nil); err != nil {
+			t.Fatal(err)
+		} else if len(names) != 0 {
+			t.Fatalf("unexpected measurements: %s", names)
+		}
+
+		// The work log is removed once the delete is done.
+		if paths, err := filepath.Glob(filepath.Join(s.Path(), "db0", "delete-series-*")); err != nil {
+			t.Fatal(err)
+		} else if len(paths) != 0 {
+			t.Fatalf("unexpected work logs: %v", paths)
+		}
+	}
+
+	for _, index := range tsdb.RegisteredIndexes() {
+		t.Run(index, func(t *testing.T) { test(index) })
+	}
+}
+
+// Ensure a series delete stops between batches once it is interrupted.
+func TestStore_DeleteSeriesWithProgress_Interrupt(t *testing.T) {
+	test := func(index string) {
+		s := MustOpenStore(index)
+		defer s.Close()
+		s.EngineOptions.Config.DeleteSeriesBatchSize = 1
+
+		s.MustCreateShardWithData("db0", "rp0", 1,
+			`cpu,host=serverA value=1 0`,
+			`cpu,host=serverB value=2 0`,
+		)
+		s.MustCreateShardWithData("db0", "rp0", 2, `cpu,host=serverA value=3 100000`)
+
+		// Interrupt the delete after the first batch.
+		interrupt := make(chan struct{})
+		if err := s.DeleteSeriesWithProgress("db0", nil, nil, func(shardsDone, shardsTotal int, series int64) {
+			if series == 1 {
+				close(interrupt)
+			}
+		}, interrupt); err != query.ErrQueryInterrupted {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if stats, err := s.DeleteSeriesDryRun("db0", nil, nil); err != nil {
+			t.Fatal(err)
+		} else if exp := (tsdb.DeleteStats{Shards: 2, Series: 2, Values: 2}); stats != exp {
+			t.Fatalf("unexpected stats: got %+v, exp %+v", stats, exp)
+		}
+
+		// An interrupted delete is not resumed.
+		if paths, err := filepath.Glob(filepath.Join(s.Path(), "db0", "delete-series-*")); err != nil {
+			t.Fatal(err)
+		} else if len(paths) != 0 {
+			t.Fatalf("unexpected work logs: %v", paths)
+		}
+	}
+
+	for _, index := range tsdb.RegisteredIndexes() {
+		t.Run(index, func(t *testing.T) { test(index) })
+	}
+}
+
+// Ensure a series delete interrupted by a restart is resumed when the store
+// is opened.
+func TestStore_DeleteSeries_Resume(t *testing.T) {
+	test := func(index string) {
+		s := MustOpenStore(index)
+		defer s.Close()
+
+		s.MustCreateShardWithData("db0", "rp0", 1, `cpu,host=serverA value=1 0`, `cpu,host=serverB value=2 0`)
+		s.MustCreateShardWithData("db0", "rp0", 2, `cpu,host=serverA value=3 100000`)
+
+		// Shard 1 is done, shard 2 is left.
+		log := `{"database":"db0","names":["cpu"],"condition":"host = 'serverA'","min":-9223372036854775806,"max":9223372036854775806,"shards":[2]}`
+		path := filepath.Join(s.Path(), "db0", "delete-series-1.json")
+		if err := ioutil.WriteFile(path, []byte(log), 0600); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := s.Reopen(); err != nil {
+			t.Fatal(err)
+		}
+
+		for i := 0; ; i++ {
+			if _, err := os.Stat(path); os.IsNotExist(err) {
+				break
+			} else if i == 100 {
+				t.Fatal("delete was not resumed")
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+
+		// Only the values of serverA in shard 1 are left.
+		if stats, err := s.DeleteSeriesDryRun("db0", nil, influxql.MustParseExpr(`host = 'serverA'`)); err != nil {
+			t.Fatal(err)
+		} else if exp := (tsdb.DeleteStats{Shards: 1, Series: 1, Values: 1}); stats != exp {
+			t.Fatalf("unexpected stats: got %+v, exp %+v", stats, exp)
+		}
+	}
+
+	for _, index := range tsdb.RegisteredIndexes() {
+		t.Run(index, func(t *testing.T) { test(index) })
+	}
+}
+
+// Ensure a store does not open with a trash dir it cannot move databases to.
+func TestStore_Open_InvalidTrashDir(t *testing.T) {
+	s := NewStore()
+	defer s.Close()
+
+	f, err := ioutil.TempFile("", "influxdb-trash-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	defer os.Remove(f.Name())
+
+	s.EngineOptions.Config.TrashDir = f.Name()
+	s.EngineOptions.Config.TrashRetention = toml.Duration(time.Hour)
+	if err := s.Open(); err == nil {
+		t.Fatal("expected error")
+	}
+
+	// A valid trash dir is created and left empty.
+	s.EngineOptions.Config.TrashDir = filepath.Join(s.Path(), "trash")
+	if err := s.Open(); err != nil {
+		t.Fatal(err)
+	} else if fis, err := ioutil.ReadDir(s.EngineOptions.Config.TrashDir); err != nil {
+		t.Fatal(err)
+	} else if len(fis) != 0 {
+		t.Fatalf("unexpected files in trash dir: %d", len(fis))
+	}
+}
+
+func TestStore_TrashDatabase(t *testing.T) {
+	test := func(index string) {
+		s := MustOpenStore(index)
+		defer s.Close()
+
+		trashDir, err := ioutil.TempDir("", "influxdb-trash-")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.RemoveAll(trashDir)
+		s.EngineOptions.Config.TrashDir = trashDir
+		s.EngineOptions.Config.TrashRetention = toml.Duration(time.Hour)
+
+		// The points are only in the cache and WAL when the database is dropped.
+		s.MustCreateShardWithData("db0", "rp0", 1,
+			`cpu,host=serverA value=1 0`,
+			`cpu,host=serverB value=2 10`,
+		)
+
+		if err := s.TrashDatabase("db0", []byte("info")); err != nil {
+			t.Fatal(err)
+		} else if s.Shard(1) != nil {
+			t.Fatal("expected shard to be closed")
+		} else if dirExists(filepath.Join(s.Path(), "db0")) {
+			t.Fatal("expected database directory to be removed")
+		}
+
+		a, err := s.TrashedDatabases()
+		if err != nil {
+			t.Fatal(err)
+		} else if len(a) != 1 {
+			t.Fatalf("unexpected trash: %+v", a)
+		} else if a[0].Name != "db0" || string(a[0].Info) != "info" || a[0].ExpiresAt.Sub(a[0].DroppedAt) != time.Hour {
+			t.Fatalf("unexpected trashed database: %+v", a[0])
+		}
+
+		if err := s.RestoreTrashedShard(a[0].ID, "rp0", 1, "db0", 5); err != nil {
+			t.Fatal(err)
+		} else if err := s.RestoreTrashedShard(a[0].ID, "rp0", 2, "db0", 6); err != tsdb.ErrShardNotFound {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if stats, err := s.DeleteSeriesDryRun("db0", nil, nil); err != nil {
+			t.Fatal(err)
+		} else if exp := (tsdb.DeleteStats{Shards: 1, Series: 2, Values: 2}); stats != exp {
+			t.Fatalf("unexpected stats: got %+v, exp %+v", stats, exp)
+		}
+
+		if err := s.DeleteTrash(a[0].ID); err != nil {
+			t.Fatal(err)
+		} else if a, err := s.TrashedDatabases(); err != nil {
+			t.Fatal(err)
+		} else if len(a) != 0 {
+			t.Fatalf("unexpected trash: %+v", a)
+		} else if s.Shard(5) == nil {
+			t.Fatal("expected restored shard to remain")
+		}
+	}
+
+	for _, index := range tsdb.RegisteredIndexes() {
+		t.Run(index, func(t *testing.T) { test(index) })
+	}
+}
+
+// Ensure the store only reports index memory statistics when enabled.
+func TestStore_Statistics_IndexMemory(t *testing.T) {
+	t.Parallel()
+
+	test := func(enabled bool) {
+		s := NewStore()
+		s.EngineOptions.IndexVersion = "inmem"
+		s.EngineOptions.Config.IndexMemoryStatsEnabled = enabled
+		if err := s.Open(); err != nil {
+			t.Fatal(err)
+		}
+		defer s.Close()
+
+		s.MustCreateShardWithData("db0", "rp0", 1, `cpu,host=serverA value=1 0`)
+
+		var n int
+		for _, stat := range s.Statistics(nil) {
+			switch stat.Name {
+			case "index", "index_measurement", "index_tag_key":
+				if stat.Tags["database"] != "db0" {
+					t.Fatalf("unexpected tags: %v", stat.Tags)
+				}
+				n++
+			}
+		}
+
+		if enabled && n != 3 {
+			t.Fatalf("unexpected number of index statistics: %d", n)
+		} else if !enabled && n != 0 {
+			t.Fatalf("unexpected index statistics when disabled: %d", n)
+		}
+	}
+
+	t.Run("enabled", func(t *testing.T) { test(true) })
+	t.Run("disabled", func(t *testing.T) { test(false) })
+}
+
 func TestStore_MeasurementNames_Deduplicate(t *testing.T) {
 	t.Parallel()
 
@@ -532,6 +969,57 @@ func TestStore_MeasurementNames_Deduplicate(t *testing.T) {
 	}
 }
 
+// Ensure the store reports the measurements with the most series across shards.
+func TestStore_MeasurementCardinalities(t *testing.T) {
+	t.Parallel()
+
+	test := func(index string) {
+		s := MustOpenStore(index)
+		defer s.Close()
+
+		s.MustCreateShardWithData("db0", "rp0", 1,
+			`cpu,host=serverA,region=west value=1 0`,
+			`cpu,host=serverB,region=west value=1 0`,
+			`mem,host=serverA value=1 0`,
+			`disk,host=serverA,path=/ value=1 0`,
+		)
+		s.MustCreateShardWithData("db0", "rp0", 2,
+			`cpu,host=serverA,region=west value=1 10`,
+			`cpu,host=serverC,region=east value=1 10`,
+			`disk,host=serverA,path=/home value=1 10`,
+		)
+
+		a, err := s.MeasurementCardinalities("db0", 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// Each tsi1 shard counts cpu,host=serverA,region=west.
+		cpuN := 3
+		if index == "tsi1" {
+			cpuN = 4
+		}
+
+		exp := []tsdb.MeasurementCardinality{
+			{Name: "cpu", SeriesN: cpuN, TagKeys: []tsdb.TagKeyCardinality{
+				{Key: "host", ValuesN: 3},
+				{Key: "region", ValuesN: 2},
+			}},
+			{Name: "disk", SeriesN: 2, TagKeys: []tsdb.TagKeyCardinality{
+				{Key: "path", ValuesN: 2},
+				{Key: "host", ValuesN: 1},
+			}},
+		}
+		if !reflect.DeepEqual(a, exp) {
+			t.Fatalf("unexpected cardinalities:\n\texp=%+v\n\tgot=%+v", exp, a)
+		}
+	}
+
+	for _, index := range tsdb.RegisteredIndexes() {
+		t.Run(index, func(t *testing.T) { test(index) })
+	}
+}
+
 func testStoreCardinalityTombstoning(t *testing.T, store *Store) {
 	// Generate point data to write to the shards.
 	series := genTestSeries(10, 2, 4) // 160 series
@@ -0,0 +1,277 @@
+package tsdb
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// trashManifestFile is the file in each trash entry that describes the
+// dropped database.
+const trashManifestFile = "manifest.json"
+
+// TrashedDatabase is a dropped database whose shards are kept in the trash.
+type TrashedDatabase struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	DroppedAt time.Time `json:"dropped_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+
+	// Info is the description of the database passed to TrashDatabase.
+	Info []byte `json:"-"`
+}
+
+// trashManifest is the on-disk form of a TrashedDatabase.
+type trashManifest struct {
+	Name      string    `json:"name"`
+	DroppedAt time.Time `json:"dropped_at"`
+	Info      []byte    `json:"info"`
+}
+
+// TrashDatabase drops a database like DeleteDatabase, but moves its shards
+// to the trash directory where they are kept for the trash retention. info
+// describes the database so it can be recreated when it is undropped. If the
+// trash is disabled or the database is kept in memory, the database is deleted.
+func (s *Store) TrashDatabase(name string, info []byte) error {
+	if s.EngineOptions.Config.TrashRetention <= 0 || s.isInmemDatabase(name) {
+		return s.DeleteDatabase(name)
+	}
+
+	// Write the cache of each shard to TSM files so the WAL can be removed.
+	s.mu.RLock()
+	shards := s.filterShards(byDatabase(name))
+	s.mu.RUnlock()
+	for _, sh := range shards {
+		dir, err := sh.CreateSnapshot()
+		if err == ErrEngineClosed || err == ErrShardDisabled {
+			continue
+		} else if err != nil {
+			return err
+		}
+		if err := os.RemoveAll(dir); err != nil {
+			return err
+		}
+	}
+
+	s.trashMu.Lock()
+	defer s.trashMu.Unlock()
+
+	dbPath := filepath.Clean(filepath.Join(s.path, name))
+	if filepath.Clean(s.path) != filepath.Dir(dbPath) {
+		return fmt.Errorf("invalid database directory location for database '%s': %s", name, dbPath)
+	}
+
+	if err := os.MkdirAll(s.EngineOptions.Config.TrashDir, 0700); err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+	path := filepath.Join(s.EngineOptions.Config.TrashDir, strconv.FormatInt(now.UnixNano(), 10))
+	if err := os.Mkdir(path, 0700); err != nil {
+		return err
+	}
+
+	buf, err := json.Marshal(trashManifest{Name: name, DroppedAt: now, Info: info})
+	if err != nil {
+		os.RemoveAll(path)
+		return err
+	} else if err := ioutil.WriteFile(filepath.Join(path, trashManifestFile), buf, 0600); err != nil {
+		os.RemoveAll(path)
+		return err
+	}
+
+	// Move the shards while they are still open so the database is left as
+	// it was if the move fails. Open files are not affected by the rename.
+	if err := os.Rename(dbPath, filepath.Join(path, "data")); err != nil && !os.IsNotExist(err) {
+		os.RemoveAll(path)
+		return err
+	}
+
+	// The shards are in the trash now, so the entry is kept even if closing
+	// them fails.
+	if err := s.deleteDatabase(name, os.RemoveAll); err != nil {
+		return err
+	}
+
+	s.Logger.Info(fmt.Sprintf("Moved database %s to trash %s", name, path))
+	return nil
+}
+
+// checkTrashDir creates the trash directory and checks that directories can
+// be moved to it from the data directory. Databases cannot be moved to the
+// trash if it is on another file system.
+func (s *Store) checkTrashDir() error {
+	dir := s.EngineOptions.Config.TrashDir
+	if s.EngineOptions.Config.TrashRetention <= 0 || dir == "" {
+		return nil
+	}
+
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("create trash dir: %s", err)
+	}
+
+	src, err := ioutil.TempDir(s.path, ".trash-check-")
+	if err != nil {
+		return err
+	}
+	dst := filepath.Join(dir, filepath.Base(src))
+	if err := os.Rename(src, dst); err != nil {
+		os.RemoveAll(src)
+		return fmt.Errorf("trash dir %s must be on the same file system as the data dir: %s", dir, err)
+	}
+	return os.RemoveAll(dst)
+}
+
+// TrashedDatabases returns the databases in the trash, oldest first.
+func (s *Store) TrashedDatabases() ([]TrashedDatabase, error) {
+	dir := s.EngineOptions.Config.TrashDir
+	if dir == "" {
+		return nil, nil
+	}
+
+	fis, err := ioutil.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var a []TrashedDatabase
+	for _, fi := range fis {
+		if !fi.IsDir() {
+			continue
+		}
+
+		buf, err := ioutil.ReadFile(filepath.Join(dir, fi.Name(), trashManifestFile))
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+
+		var m trashManifest
+		if err := json.Unmarshal(buf, &m); err != nil {
+			return nil, fmt.Errorf("trash %s: %s", fi.Name(), err)
+		}
+		a = append(a, TrashedDatabase{
+			ID:        fi.Name(),
+			Name:      m.Name,
+			DroppedAt: m.DroppedAt,
+			ExpiresAt: m.DroppedAt.Add(time.Duration(s.EngineOptions.Config.TrashRetention)),
+			Info:      m.Info,
+		})
+	}
+	sort.Slice(a, func(i, j int) bool { return a[i].DroppedAt.Before(a[j].DroppedAt) })
+	return a, nil
+}
+
+// RestoreTrashedShard creates the shard dstID of database and retention
+// policy rp from the shard srcID of the trash entry id. The files are
+// hard-linked, so the trash entry is unchanged until it is deleted. It
+// returns ErrShardNotFound if the entry does not have the shard.
+func (s *Store) RestoreTrashedShard(id, rp string, srcID uint64, database string, dstID uint64) error {
+	s.trashMu.Lock()
+	defer s.trashMu.Unlock()
+
+	src := filepath.Join(s.EngineOptions.Config.TrashDir, id, "data", rp, strconv.FormatUint(srcID, 10))
+	if _, err := os.Stat(src); os.IsNotExist(err) {
+		return ErrShardNotFound
+	} else if err != nil {
+		return err
+	}
+
+	if s.Shard(dstID) != nil {
+		return fmt.Errorf("shard %d already exists", dstID)
+	} else if s.isInmemDatabase(database) {
+		return fmt.Errorf("cannot restore shard into inmem database %s", database)
+	} else if err := os.MkdirAll(filepath.Join(s.path, database, rp), 0700); err != nil {
+		return err
+	}
+	path := filepath.Join(s.path, database, rp, strconv.FormatUint(dstID, 10))
+	if _, err := os.Stat(path); err == nil {
+		return fmt.Errorf("shard path already exists: %s", path)
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+
+	if err := linkDir(src, path); err != nil {
+		os.RemoveAll(path)
+		return err
+	} else if err := s.CreateShard(database, rp, dstID, true); err != nil {
+		os.RemoveAll(path)
+		return err
+	}
+	return nil
+}
+
+// DeleteTrash removes the trash entry id.
+func (s *Store) DeleteTrash(id string) error {
+	s.trashMu.Lock()
+	defer s.trashMu.Unlock()
+	return s.deleteTrash(id)
+}
+
+func (s *Store) deleteTrash(id string) error {
+	if s.EngineOptions.Config.TrashDir == "" {
+		return nil
+	}
+
+	dir := filepath.Clean(s.EngineOptions.Config.TrashDir)
+	path := filepath.Join(dir, id)
+	if filepath.Dir(path) != dir {
+		return fmt.Errorf("invalid trash id: %s", id)
+	}
+	return os.RemoveAll(path)
+}
+
+// purgeTrash removes the trash entries older than the trash retention.
+func (s *Store) purgeTrash() error {
+	if s.EngineOptions.Config.TrashRetention <= 0 {
+		return nil
+	}
+
+	s.trashMu.Lock()
+	defer s.trashMu.Unlock()
+
+	a, err := s.TrashedDatabases()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, td := range a {
+		if now.Before(td.ExpiresAt) {
+			continue
+		}
+		if err := s.deleteTrash(td.ID); err != nil {
+			return err
+		}
+		s.Logger.Info(fmt.Sprintf("Removed database %s dropped at %s from trash", td.Name, td.DroppedAt))
+	}
+	return nil
+}
+
+// linkDir recreates the directory tree of src at dst with files hard-linked
+// to the files in src.
+func linkDir(src, dst string) error {
+	return filepath.Walk(src, func(path string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+
+		if fi.IsDir() {
+			return os.MkdirAll(target, 0700)
+		}
+		return os.Link(path, target)
+	})
+}
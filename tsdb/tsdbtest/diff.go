@@ -0,0 +1,56 @@
+package tsdbtest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/influxdata/influxdb/query"
+)
+
+// QueryDiff is a query whose results differ between two executors. The
+// results are encoded as they are returned by the HTTP API.
+type QueryDiff struct {
+	Query string
+	A, B  []byte
+}
+
+// String returns the query and both results.
+func (d QueryDiff) String() string {
+	return fmt.Sprintf("%s\n  a: %s\n  b: %s", d.Query, d.A, d.B)
+}
+
+// DiffQueries runs every query of corpus against database on both a and b
+// and returns the queries whose results differ. It is used to check that a
+// new engine, index or iterator returns the same results as the one it
+// replaces, so the stores of a and b should hold the same points.
+func DiffQueries(a, b *QueryExecutor, database string, corpus []string) ([]QueryDiff, error) {
+	var diffs []QueryDiff
+	for _, q := range corpus {
+		ra, err := encodeResults(a, q, database)
+		if err != nil {
+			return nil, err
+		}
+		rb, err := encodeResults(b, q, database)
+		if err != nil {
+			return nil, err
+		}
+
+		if !bytes.Equal(ra, rb) {
+			diffs = append(diffs, QueryDiff{Query: q, A: ra, B: rb})
+		}
+	}
+	return diffs, nil
+}
+
+// encodeResults executes q and encodes its results to JSON.
+func encodeResults(e *QueryExecutor, q, database string) ([]byte, error) {
+	results, err := e.ExecuteQuery(q, database)
+	if err != nil {
+		return nil, err
+	}
+	if results == nil {
+		results = []*query.Result{}
+	}
+	return json.Marshal(results)
+}
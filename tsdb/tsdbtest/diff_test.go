@@ -0,0 +1,80 @@
+package tsdbtest_test
+
+import (
+	"testing"
+
+	"github.com/influxdata/influxdb/tsdb"
+	"github.com/influxdata/influxdb/tsdb/tsdbtest"
+)
+
+// queryCorpus are the queries run against every engine and index.
+var queryCorpus = []string{
+	`SELECT * FROM m0`,
+	`SELECT * FROM m1 WHERE tagKey0 = 'tagValue1'`,
+	`SELECT count(value), sum(value), mean(value) FROM /m.*/ GROUP BY *`,
+	`SELECT max(value) FROM m0 WHERE time >= 10s AND time < 40s GROUP BY time(10s), tagKey1 fill(none)`,
+	`SELECT derivative(value, 20s) FROM m0 WHERE tagKey0 = 'tagValue0' GROUP BY tagKey1`,
+	`SELECT top(value, tagKey0, 2) FROM m1`,
+	`SELECT value FROM m0 WHERE tagKey0 =~ /[12]$/ ORDER BY time DESC LIMIT 3 OFFSET 1`,
+	`SELECT mean(max) FROM (SELECT max(value) FROM m0 GROUP BY tagKey0)`,
+	`SHOW MEASUREMENTS`,
+	`SHOW SERIES FROM m0`,
+	`SHOW TAG KEYS`,
+	`SHOW TAG VALUES WITH KEY = tagKey1`,
+	`SHOW FIELD KEYS`,
+	`SELECT * FROM nonexistent`,
+}
+
+func mustOpenQueryExecutor(engine, index string) *tsdbtest.QueryExecutor {
+	s := tsdbtest.NewStore(index)
+	s.EngineOptions.EngineVersion = engine
+	if err := s.Open(); err != nil {
+		panic(err)
+	}
+	return tsdbtest.NewQueryExecutor(s)
+}
+
+func TestDiffQueries(t *testing.T) {
+	g := tsdbtest.NewPointGenerator(2, 2, 3)
+	g.PointsPerSeries = 6
+
+	base := mustOpenQueryExecutor(tsdb.DefaultEngine, "inmem")
+	defer base.Close()
+	base.MustWritePoints("db0", "", g.Points())
+
+	for _, tt := range []struct {
+		engine, index string
+	}{
+		{tsdb.DefaultEngine, "tsi1"},
+		{tsdb.InmemEngine, "inmem"},
+	} {
+		t.Run(tt.engine+"/"+tt.index, func(t *testing.T) {
+			e := mustOpenQueryExecutor(tt.engine, tt.index)
+			defer e.Close()
+			e.MustWritePoints("db0", "", g.Points())
+
+			diffs, err := tsdbtest.DiffQueries(base, e, "db0", queryCorpus)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, d := range diffs {
+				t.Errorf("results differ: %s", d)
+			}
+		})
+	}
+
+	// A point that is only written to one of the stores is reported.
+	t.Run("Different", func(t *testing.T) {
+		e := mustOpenQueryExecutor(tsdb.DefaultEngine, "inmem")
+		defer e.Close()
+		e.MustWritePoints("db0", "", g.Points())
+		e.MustWriteString("db0", "", `m0,tagKey0=tagValue0,tagKey1=tagValue0 value=100 1000`)
+
+		diffs, err := tsdbtest.DiffQueries(base, e, "db0", queryCorpus[:1])
+		if err != nil {
+			t.Fatal(err)
+		} else if len(diffs) != 1 {
+			t.Fatalf("unexpected diffs: %v", diffs)
+		}
+	})
+}
@@ -0,0 +1,87 @@
+package tsdbtest
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/influxdata/influxdb/models"
+)
+
+// PointGenerator generates points with a controlled series cardinality.
+//
+// Each of the Measurements measurements, named m0, m1 and so on, has a series
+// for every combination of the values of its TagKeys tag keys, named tagKey0,
+// tagKey1 and so on, each with TagValues values named tagValue0, tagValue1
+// and so on. Every series gets PointsPerSeries points with a float field
+// named value, the first at Start and the next ones Interval apart.
+type PointGenerator struct {
+	Measurements    int
+	TagKeys         int
+	TagValues       int
+	PointsPerSeries int
+
+	Start    time.Time
+	Interval time.Duration
+}
+
+// NewPointGenerator returns a PointGenerator for the given cardinality with
+// one point per series at the Unix epoch and an interval of 10 seconds.
+func NewPointGenerator(measurements, tagKeys, tagValues int) *PointGenerator {
+	return &PointGenerator{
+		Measurements:    measurements,
+		TagKeys:         tagKeys,
+		TagValues:       tagValues,
+		PointsPerSeries: 1,
+		Start:           time.Unix(0, 0).UTC(),
+		Interval:        10 * time.Second,
+	}
+}
+
+// SeriesN returns the number of series generated.
+func (g *PointGenerator) SeriesN() int {
+	n := g.Measurements
+	for i := 0; i < g.TagKeys; i++ {
+		n *= g.TagValues
+	}
+	return n
+}
+
+// Points returns the generated points, ordered by time and then by series.
+func (g *PointGenerator) Points() []models.Point {
+	tagSets := g.tagSets()
+	points := make([]models.Point, 0, g.SeriesN()*g.PointsPerSeries)
+	for i := 0; i < g.PointsPerSeries; i++ {
+		t := g.Start.Add(time.Duration(i) * g.Interval)
+		for m := 0; m < g.Measurements; m++ {
+			name := fmt.Sprintf("m%d", m)
+			for _, tags := range tagSets {
+				p, err := models.NewPoint(name, tags, models.Fields{"value": float64(i)}, t)
+				if err != nil {
+					panic(err)
+				}
+				points = append(points, p)
+			}
+		}
+	}
+	return points
+}
+
+// tagSets returns every combination of the tag values.
+func (g *PointGenerator) tagSets() []models.Tags {
+	sets := []models.Tags{nil}
+	for k := 0; k < g.TagKeys; k++ {
+		key := []byte(fmt.Sprintf("tagKey%d", k))
+
+		next := make([]models.Tags, 0, len(sets)*g.TagValues)
+		for _, tags := range sets {
+			for v := 0; v < g.TagValues; v++ {
+				t := make(models.Tags, len(tags), len(tags)+1)
+				copy(t, tags)
+				t = append(t, models.NewTag(key, []byte(fmt.Sprintf("tagValue%d", v))))
+				next = append(next, t)
+			}
+		}
+		sets = next
+	}
+	return sets
+}
@@ -0,0 +1,248 @@
+package tsdbtest
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/influxdata/influxdb/coordinator"
+	"github.com/influxdata/influxdb/services/meta"
+	"github.com/influxdata/influxql"
+)
+
+var _ coordinator.MetaClient = (*MetaClient)(nil)
+
+// MetaClient is an in-memory meta client. It keeps the meta data of a single
+// node without persisting it, and is used by QueryExecutor to map the shards
+// of queries. Passwords are stored as given.
+type MetaClient struct {
+	mu   sync.RWMutex
+	data meta.Data
+}
+
+// NewMetaClient returns a new, empty MetaClient.
+func NewMetaClient() *MetaClient {
+	return &MetaClient{}
+}
+
+// Data returns a copy of the meta data.
+func (c *MetaClient) Data() *meta.Data {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.data.Clone()
+}
+
+// CreateShardGroup creates the shard group of the given retention policy
+// that contains t, if it does not exist yet, and returns it.
+func (c *MetaClient) CreateShardGroup(database, policy string, t time.Time) (*meta.ShardGroupInfo, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.data.CreateShardGroup(database, policy, t); err != nil {
+		return nil, err
+	}
+	return c.data.ShardGroupByTimestamp(database, policy, t)
+}
+
+// CreateContinuousQuery creates a continuous query.
+func (c *MetaClient) CreateContinuousQuery(database, name, query string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.data.CreateContinuousQuery(database, name, query)
+}
+
+// CreateDatabase creates a database with the default retention policy. It
+// returns the existing database if there is one.
+func (c *MetaClient) CreateDatabase(name string) (*meta.DatabaseInfo, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if db := c.data.Database(name); db != nil {
+		return db, nil
+	}
+	if err := c.data.CreateDatabase(name); err != nil {
+		return nil, err
+	} else if err := c.data.CreateRetentionPolicy(name, meta.DefaultRetentionPolicyInfo(), true); err != nil {
+		return nil, err
+	}
+	return c.data.Database(name), nil
+}
+
+// CreateDatabaseWithRetentionPolicy creates a database with spec as its
+// default retention policy.
+func (c *MetaClient) CreateDatabaseWithRetentionPolicy(name string, spec *meta.RetentionPolicySpec) (*meta.DatabaseInfo, error) {
+	if spec == nil {
+		return nil, errors.New("CreateDatabaseWithRetentionPolicy called with nil spec")
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	db := c.data.Database(name)
+	if db == nil {
+		if err := c.data.CreateDatabase(name); err != nil {
+			return nil, err
+		}
+		db = c.data.Database(name)
+	}
+
+	rpi := spec.NewRetentionPolicyInfo()
+	if len(db.RetentionPolicies) == 0 {
+		if err := c.data.CreateRetentionPolicy(name, rpi, true); err != nil {
+			return nil, err
+		}
+	} else if !spec.Matches(db.RetentionPolicy(rpi.Name)) {
+		return nil, meta.ErrRetentionPolicyConflict
+	}
+	if db.DefaultRetentionPolicy != rpi.Name {
+		return nil, meta.ErrRetentionPolicyConflict
+	}
+	return c.data.Database(name), nil
+}
+
+// CreateRetentionPolicy creates a retention policy on a database.
+func (c *MetaClient) CreateRetentionPolicy(database string, spec *meta.RetentionPolicySpec, makeDefault bool) (*meta.RetentionPolicyInfo, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	rpi := spec.NewRetentionPolicyInfo()
+	if err := c.data.CreateRetentionPolicy(database, rpi, makeDefault); err != nil {
+		return nil, err
+	}
+	return c.data.RetentionPolicy(database, rpi.Name)
+}
+
+// CreateSubscription creates a subscription on a retention policy.
+func (c *MetaClient) CreateSubscription(database, rp, name, mode string, destinations []string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.data.CreateSubscription(database, rp, name, mode, destinations)
+}
+
+// CreateUser creates a user.
+func (c *MetaClient) CreateUser(name, password string, admin bool) (meta.User, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.data.CreateUser(name, password, admin); err != nil {
+		return nil, err
+	}
+	return c.data.User(name), nil
+}
+
+// Database returns a database by name, or nil if it does not exist.
+func (c *MetaClient) Database(name string) *meta.DatabaseInfo {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.data.Database(name)
+}
+
+// Databases returns all databases.
+func (c *MetaClient) Databases() []meta.DatabaseInfo {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.data.CloneDatabases()
+}
+
+// DropShard removes a shard from the meta data.
+func (c *MetaClient) DropShard(id uint64) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.data.DropShard(id)
+	return nil
+}
+
+// DropContinuousQuery removes a continuous query.
+func (c *MetaClient) DropContinuousQuery(database, name string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.data.DropContinuousQuery(database, name)
+}
+
+// DropDatabase removes a database.
+func (c *MetaClient) DropDatabase(name string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.data.DropDatabase(name)
+}
+
+// DropRetentionPolicy removes a retention policy.
+func (c *MetaClient) DropRetentionPolicy(database, name string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.data.DropRetentionPolicy(database, name)
+}
+
+// DropSubscription removes a subscription.
+func (c *MetaClient) DropSubscription(database, rp, name string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.data.DropSubscription(database, rp, name)
+}
+
+// DropUser removes a user.
+func (c *MetaClient) DropUser(name string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.data.DropUser(name)
+}
+
+// RetentionPolicy returns a retention policy of a database, or nil if it
+// does not exist.
+func (c *MetaClient) RetentionPolicy(database, name string) (*meta.RetentionPolicyInfo, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.data.RetentionPolicy(database, name)
+}
+
+// SetAdminPrivilege sets or unsets the admin privilege of a user.
+func (c *MetaClient) SetAdminPrivilege(username string, admin bool) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.data.SetAdminPrivilege(username, admin)
+}
+
+// SetPrivilege sets the privilege of a user on a database.
+func (c *MetaClient) SetPrivilege(username, database string, p influxql.Privilege) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.data.SetPrivilege(username, database, p)
+}
+
+// ShardGroupsByTimeRange returns the shard groups of a retention policy
+// that overlap the time range.
+func (c *MetaClient) ShardGroupsByTimeRange(database, policy string, min, max time.Time) ([]meta.ShardGroupInfo, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.data.ShardGroupsByTimeRange(database, policy, min, max)
+}
+
+// UpdateRetentionPolicy updates a retention policy.
+func (c *MetaClient) UpdateRetentionPolicy(database, name string, rpu *meta.RetentionPolicyUpdate, makeDefault bool) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.data.UpdateRetentionPolicy(database, name, rpu, makeDefault)
+}
+
+// UpdateUser changes the password of a user.
+func (c *MetaClient) UpdateUser(name, password string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.data.UpdateUser(name, password)
+}
+
+// UserPrivilege returns the privilege of a user on a database.
+func (c *MetaClient) UserPrivilege(username, database string) (*influxql.Privilege, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.data.UserPrivilege(username, database)
+}
+
+// UserPrivileges returns the privileges of a user on all databases.
+func (c *MetaClient) UserPrivileges(username string) (map[string]influxql.Privilege, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.data.UserPrivileges(username)
+}
+
+// Users returns all users.
+func (c *MetaClient) Users() []meta.UserInfo {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.data.CloneUsers()
+}
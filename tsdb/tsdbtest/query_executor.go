@@ -0,0 +1,127 @@
+package tsdbtest
+
+import (
+	"time"
+
+	"github.com/influxdata/influxdb/coordinator"
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/query"
+	"github.com/influxdata/influxdb/services/meta"
+	"github.com/influxdata/influxql"
+)
+
+// QueryExecutor executes queries against a Store. The shards of the store
+// are mapped through an in-memory MetaClient, so points have to be written
+// with the methods of the executor for queries to find them.
+type QueryExecutor struct {
+	*query.QueryExecutor
+
+	Store             *Store
+	MetaClient        *MetaClient
+	StatementExecutor *coordinator.StatementExecutor
+}
+
+// NewQueryExecutor returns a QueryExecutor for s with an empty MetaClient.
+func NewQueryExecutor(s *Store) *QueryExecutor {
+	e := &QueryExecutor{
+		QueryExecutor: query.NewQueryExecutor(),
+		Store:         s,
+		MetaClient:    NewMetaClient(),
+	}
+
+	store := coordinator.LocalTSDBStore{Store: s.Store}
+	e.StatementExecutor = &coordinator.StatementExecutor{
+		MetaClient: e.MetaClient,
+		TSDBStore:  store,
+		ShardMapper: &coordinator.LocalShardMapper{
+			MetaClient: e.MetaClient,
+			TSDBStore:  store,
+		},
+	}
+	e.QueryExecutor.StatementExecutor = e.StatementExecutor
+	return e
+}
+
+// MustOpenQueryExecutor returns a QueryExecutor for a new, open Store using
+// the specified index. Closing the executor closes the store.
+func MustOpenQueryExecutor(index string) *QueryExecutor {
+	return NewQueryExecutor(MustOpenStore(index))
+}
+
+// Close closes the executor and its store.
+func (e *QueryExecutor) Close() error {
+	if err := e.QueryExecutor.Close(); err != nil {
+		e.Store.Close()
+		return err
+	}
+	return e.Store.Close()
+}
+
+// MustCreateShard creates the shard of db and rp that holds the points at t,
+// creating the database and retention policy if needed, and returns its ID.
+// An empty rp is the default retention policy of the database.
+func (e *QueryExecutor) MustCreateShard(db, rp string, t time.Time) uint64 {
+	if e.MetaClient.Database(db) == nil {
+		if _, err := e.MetaClient.CreateDatabase(db); err != nil {
+			panic(err)
+		}
+	}
+	if rp == "" {
+		rp = e.MetaClient.Database(db).DefaultRetentionPolicy
+	} else if rpi, err := e.MetaClient.RetentionPolicy(db, rp); err != nil {
+		panic(err)
+	} else if rpi == nil {
+		if _, err := e.MetaClient.CreateRetentionPolicy(db, &meta.RetentionPolicySpec{Name: rp}, false); err != nil {
+			panic(err)
+		}
+	}
+
+	sgi, err := e.MetaClient.CreateShardGroup(db, rp, t)
+	if err != nil {
+		panic(err)
+	}
+	id := sgi.Shards[0].ID
+	if err := e.Store.CreateShard(db, rp, id, true); err != nil {
+		panic(err)
+	}
+	return id
+}
+
+// MustWritePoints writes points to the shards of db and rp that hold them,
+// creating the shards as needed.
+func (e *QueryExecutor) MustWritePoints(db, rp string, points []models.Point) {
+	byShard := make(map[uint64][]models.Point)
+	for _, p := range points {
+		id := e.MustCreateShard(db, rp, p.Time())
+		byShard[id] = append(byShard[id], p)
+	}
+	for id, a := range byShard {
+		if err := e.Store.WriteToShard(id, a); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// MustWriteString parses the line protocol (with second precision) and
+// writes the points to db and rp like MustWritePoints.
+func (e *QueryExecutor) MustWriteString(db, rp string, data ...string) {
+	e.MustWritePoints(db, rp, MustParsePointsString(data...))
+}
+
+// ExecuteQuery parses and executes q against database and returns the
+// results.
+func (e *QueryExecutor) ExecuteQuery(q, database string) ([]*query.Result, error) {
+	parsed, err := influxql.ParseQuery(q)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []*query.Result
+	for r := range e.QueryExecutor.ExecuteQuery(parsed, query.ExecutionOptions{
+		Database:   database,
+		Authorizer: query.OpenAuthorizer{},
+	}, make(chan struct{})) {
+		results = append(results, r)
+	}
+	return results, nil
+}
@@ -0,0 +1,97 @@
+// Package tsdbtest provides a temporary store, a query executor and point
+// generators for tests that need a working database without running the
+// server.
+//
+// The helpers panic instead of returning errors, like the helpers of the
+// tests in this repository, so they should only be used from tests.
+package tsdbtest
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/tsdb"
+	_ "github.com/influxdata/influxdb/tsdb/engine"
+	_ "github.com/influxdata/influxdb/tsdb/index"
+)
+
+// Store is a tsdb.Store at a temporary path which is removed when the store
+// is closed.
+type Store struct {
+	*tsdb.Store
+}
+
+// NewStore returns a new instance of Store with a temporary path. The store
+// uses the index given by index, or the default index if index is empty.
+func NewStore(index string) *Store {
+	path, err := ioutil.TempDir("", "influxdb-tsdbtest-")
+	if err != nil {
+		panic(err)
+	}
+
+	s := &Store{Store: tsdb.NewStore(path)}
+	s.EngineOptions.Config.WALDir = filepath.Join(path, "wal")
+	if index != "" {
+		s.EngineOptions.IndexVersion = index
+	}
+	return s
+}
+
+// MustOpenStore returns a new, open Store using the specified index.
+func MustOpenStore(index string) *Store {
+	s := NewStore(index)
+	if err := s.Open(); err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// Reopen closes and reopens the store as a new store with the same options.
+func (s *Store) Reopen() error {
+	if err := s.Store.Close(); err != nil {
+		return err
+	}
+	opt := s.EngineOptions
+	s.Store = tsdb.NewStore(s.Path())
+	s.EngineOptions = opt
+	return s.Open()
+}
+
+// Close closes the store and removes the underlying data.
+func (s *Store) Close() error {
+	defer os.RemoveAll(s.Path())
+	return s.Store.Close()
+}
+
+// MustCreateShardWithData creates a shard and writes line protocol data to it.
+func (s *Store) MustCreateShardWithData(db, rp string, shardID int, data ...string) {
+	if err := s.CreateShard(db, rp, uint64(shardID), true); err != nil {
+		panic(err)
+	}
+	s.MustWriteToShardString(shardID, data...)
+}
+
+// MustWriteToShardString parses the line protocol (with second precision) and
+// inserts the resulting points into a shard.
+func (s *Store) MustWriteToShardString(shardID int, data ...string) {
+	if err := s.WriteToShard(uint64(shardID), MustParsePointsString(data...)); err != nil {
+		panic(err)
+	}
+}
+
+// MustParsePointsString parses line protocol with second precision.
+func MustParsePointsString(data ...string) []models.Point {
+	var points []models.Point
+	for i := range data {
+		a, err := models.ParsePointsWithPrecision([]byte(strings.TrimSpace(data[i])), time.Time{}, "s")
+		if err != nil {
+			panic(err)
+		}
+		points = append(points, a...)
+	}
+	return points
+}
@@ -0,0 +1,73 @@
+package tsdbtest_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/tsdb/tsdbtest"
+)
+
+func TestPointGenerator(t *testing.T) {
+	g := tsdbtest.NewPointGenerator(2, 2, 3)
+	g.PointsPerSeries = 2
+
+	if got, exp := g.SeriesN(), 18; got != exp {
+		t.Fatalf("unexpected series count: got=%d exp=%d", got, exp)
+	}
+
+	points := g.Points()
+	if got, exp := len(points), 36; got != exp {
+		t.Fatalf("unexpected point count: got=%d exp=%d", got, exp)
+	}
+
+	keys := make(map[string]struct{})
+	for _, p := range points {
+		keys[string(p.Key())] = struct{}{}
+	}
+	if got, exp := len(keys), 18; got != exp {
+		t.Fatalf("unexpected distinct series: got=%d exp=%d", got, exp)
+	}
+
+	if got, exp := points[0].String(), "m0,tagKey0=tagValue0,tagKey1=tagValue0 value=0 0"; got != exp {
+		t.Fatalf("unexpected first point: got=%q exp=%q", got, exp)
+	}
+	if got, exp := points[len(points)-1].Time(), time.Unix(10, 0).UTC(); !got.Equal(exp) {
+		t.Fatalf("unexpected last time: got=%s exp=%s", got, exp)
+	}
+}
+
+func TestQueryExecutor(t *testing.T) {
+	e := tsdbtest.MustOpenQueryExecutor("inmem")
+	defer e.Close()
+
+	g := tsdbtest.NewPointGenerator(1, 1, 4)
+	g.PointsPerSeries = 3
+	g.Interval = 7 * 24 * time.Hour
+	e.MustWritePoints("db0", "", g.Points())
+	e.MustWriteString("db0", "rp0", `cpu,host=serverA value=1 0`)
+
+	if got, exp := len(e.Store.ShardIDs()), 4; got != exp {
+		t.Fatalf("unexpected shard count: got=%d exp=%d", got, exp)
+	}
+
+	results, err := e.ExecuteQuery(`SELECT count(value) FROM m0; SELECT value FROM rp0.cpu`, "db0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, exp := len(results), 2; got != exp {
+		t.Fatalf("unexpected result count: got=%d exp=%d", got, exp)
+	}
+	for _, r := range results {
+		if r.Err != nil {
+			t.Fatal(r.Err)
+		}
+	}
+
+	if got, exp := results[0].Series[0].Values, [][]interface{}{{time.Unix(0, 0).UTC(), int64(12)}}; !reflect.DeepEqual(got, exp) {
+		t.Fatalf("unexpected count: got=%v exp=%v", got, exp)
+	}
+	if got, exp := results[1].Series[0].Values, [][]interface{}{{time.Unix(0, 0).UTC(), float64(1)}}; !reflect.DeepEqual(got, exp) {
+		t.Fatalf("unexpected values: got=%v exp=%v", got, exp)
+	}
+}